@@ -0,0 +1,50 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/ingressnginx"
+	"github.com/spf13/cobra"
+)
+
+var (
+	annotationsOutput string
+	annotationsStatus string
+)
+
+var supportedAnnotationsCmd = &cobra.Command{
+	Use:   "supported-annotations",
+	Short: "List the documented ingress-nginx annotations and how they are converted",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := cmd.ParseFlags(args); err != nil {
+			fmt.Printf("Error parsing flags: %v", err)
+		}
+
+		i2gw.RunSupportedAnnotations(i2gw.AnnotationsFormat(annotationsOutput), annotationsStatus)
+	},
+}
+
+func init() {
+	supportedAnnotationsCmd.Flags().StringVarP(&annotationsOutput, "output", "o", string(i2gw.AnnotationsTable),
+		fmt.Sprintf(`Output format. One of: %s.`, strings.Join(i2gw.AnnotationsFormats(), ", ")))
+	supportedAnnotationsCmd.Flags().StringVar(&annotationsStatus, "status", "",
+		fmt.Sprintf(`Only list the annotations of this status. One of: %s.`, strings.Join(ingressnginx.AnnotationStatuses(), ", ")))
+	rootCmd.AddCommand(supportedAnnotationsCmd)
+}
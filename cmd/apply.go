@@ -0,0 +1,58 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/spf13/cobra"
+)
+
+var (
+	applyOpts    i2gw.ApplyOptions
+	applyTimeout time.Duration
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Apply the output of a conversion to the cluster with Server-Side Apply",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := cmd.ParseFlags(args); err != nil {
+			fmt.Printf("Error parsing flags: %v", err)
+		}
+
+		i2gw.RunApply(applyOpts, applyTimeout)
+	},
+}
+
+func init() {
+	applyCmd.Flags().StringVarP(&applyOpts.InputFile, "filename", "f", "",
+		`Path to the manifest file to apply, such as the output of a conversion with --output=ssa.`)
+	applyCmd.MarkFlagRequired("filename")
+	applyCmd.Flags().StringVar(&applyOpts.FieldManager, "field-manager", i2gw.FieldManager,
+		`Field manager of the applied fields. Use the same field manager for every run so that later runs update
+and remove the fields set by earlier ones.`)
+	applyCmd.Flags().BoolVar(&applyOpts.Force, "force-conflicts", false,
+		`Take the ownership of the fields set by other field managers instead of failing with a conflict.`)
+	applyCmd.Flags().DurationVar(&applyOpts.Wait, "wait", 0,
+		`How long to wait for the applied Gateways to be programmed and the routes to be accepted, with resolved
+references, reporting the ones the target implementation rejected.`)
+	applyCmd.Flags().DurationVar(&applyTimeout, "timeout", 0,
+		`Cancel the apply, and the wait, if it takes longer than this, such as 5m.`)
+	rootCmd.AddCommand(applyCmd)
+}
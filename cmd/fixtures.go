@@ -0,0 +1,51 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/spf13/cobra"
+)
+
+var recordFixturesOpts i2gw.RecordFixturesOptions
+
+var recordFixturesCmd = &cobra.Command{
+	Use:   "record-fixtures",
+	Short: "Snapshot the Ingresses of the cluster, without secrets, to a fixtures directory",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := cmd.ParseFlags(args); err != nil {
+			fmt.Printf("Error parsing flags: %v", err)
+		}
+
+		i2gw.RunRecordFixtures(recordFixturesOpts)
+	},
+}
+
+func init() {
+	recordFixturesCmd.Flags().StringVar(&recordFixturesOpts.Dir, "fixtures-dir", "testdata",
+		`Directory to write the input/<name>.yaml and output/<name>.yaml files of the fixture to.`)
+	recordFixturesCmd.Flags().StringVar(&recordFixturesOpts.Name, "name", i2gw.DefaultFixtureName,
+		`Name of the fixture files.`)
+	recordFixturesCmd.Flags().StringVar(&recordFixturesOpts.Namespace, "namespace", "",
+		`Namespace to record the Ingresses of. All namespaces are recorded if empty.`)
+	recordFixturesCmd.Flags().StringVar(&recordFixturesOpts.TargetImplementation, "target-implementation", "",
+		fmt.Sprintf(`Gateway API implementation to record the input objects of and convert the expected output for.
+One of: %s.`, strings.Join(i2gw.TargetImplementations(), ", ")))
+	rootCmd.AddCommand(recordFixturesCmd)
+}
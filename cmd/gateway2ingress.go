@@ -0,0 +1,43 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/spf13/cobra"
+)
+
+var reverseInputFile string
+
+var gateway2ingressCmd = &cobra.Command{
+	Use:   "gateway2ingress",
+	Short: "Convert simple Gateway API manifests back to Ingress manifests",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := cmd.ParseFlags(args); err != nil {
+			fmt.Printf("Error parsing flags: %v", err)
+		}
+
+		i2gw.RunReverse(reverseInputFile)
+	},
+}
+
+func init() {
+	gateway2ingressCmd.Flags().StringVar(&reverseInputFile, "input-file", "",
+		`Path to a manifest file to read Gateways and HTTPRoutes from instead of the cluster.`)
+	rootCmd.AddCommand(gateway2ingressCmd)
+}
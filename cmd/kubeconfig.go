@@ -0,0 +1,69 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+)
+
+// kubeConfigFlags are the standard kubectl flags selecting the cluster and
+// the identity the commands use.
+var kubeConfigFlags = newKubeConfigFlags()
+
+// newKubeConfigFlags returns the kubectl flags except the ones overriding
+// the connection and credentials of the kubeconfig, and the namespace,
+// which the commands don't use.
+func newKubeConfigFlags() *genericclioptions.ConfigFlags {
+	flags := genericclioptions.NewConfigFlags(false)
+	flags.CacheDir = nil
+	flags.Namespace = nil
+	flags.APIServer = nil
+	flags.TLSServerName = nil
+	flags.Insecure = nil
+	flags.CertFile = nil
+	flags.KeyFile = nil
+	flags.CAFile = nil
+	flags.BearerToken = nil
+	flags.Username = nil
+	flags.Password = nil
+	flags.DisableCompression = nil
+	return flags
+}
+
+// restConfig returns the config of the kubeconfig context, or of the
+// --context if kubeContext is empty, honoring the other kubeconfig flags.
+func restConfig(kubeContext string) (*rest.Config, error) {
+	flags := kubeConfigFlags
+	if kubeContext != "" {
+		flags = newKubeConfigFlags()
+		flags.KubeConfig = kubeConfigFlags.KubeConfig
+		flags.ClusterName = kubeConfigFlags.ClusterName
+		flags.AuthInfoName = kubeConfigFlags.AuthInfoName
+		flags.Impersonate = kubeConfigFlags.Impersonate
+		flags.ImpersonateUID = kubeConfigFlags.ImpersonateUID
+		flags.ImpersonateGroup = kubeConfigFlags.ImpersonateGroup
+		flags.Timeout = kubeConfigFlags.Timeout
+		flags.Context = &kubeContext
+	}
+	return flags.ToRESTConfig()
+}
+
+func init() {
+	kubeConfigFlags.AddFlags(rootCmd.PersistentFlags())
+	i2gw.RESTConfig = restConfig
+}
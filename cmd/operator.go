@@ -0,0 +1,48 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/operator"
+	"github.com/spf13/cobra"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var operatorOpts operator.Options
+
+var operatorCmd = &cobra.Command{
+	Use:   "operator",
+	Short: "Convert and apply the Ingresses declared by the IngressMigrations of the cluster",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := cmd.ParseFlags(args); err != nil {
+			fmt.Printf("Error parsing flags: %v", err)
+		}
+
+		if err := operator.Run(ctrl.SetupSignalHandler(), operatorOpts); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	operatorCmd.Flags().StringVar(&operatorOpts.MetricsBindAddress, "metrics-bind-address", ":8080",
+		`Address the Prometheus metrics are served on. Set it to "0" to disable them.`)
+	rootCmd.AddCommand(operatorCmd)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	profileCPU string
+	profileMem string
+	// cpuProfile is the file the CPU profile of the command is written to.
+	cpuProfile *os.File
+)
+
+// startProfiling starts writing the CPU profile of the command, if
+// requested.
+func startProfiling(*cobra.Command, []string) error {
+	if profileCPU == "" {
+		return nil
+	}
+	f, err := os.Create(profileCPU)
+	if err != nil {
+		return fmt.Errorf("failed to create CPU profile: %w", err)
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to start CPU profile: %w", err)
+	}
+	cpuProfile = f
+	return nil
+}
+
+// stopProfiling completes the CPU profile and writes the heap profile of the
+// command, if requested.
+func stopProfiling(*cobra.Command, []string) error {
+	if cpuProfile != nil {
+		pprof.StopCPUProfile()
+		if err := cpuProfile.Close(); err != nil {
+			return fmt.Errorf("failed to write CPU profile: %w", err)
+		}
+		cpuProfile = nil
+	}
+	if profileMem == "" {
+		return nil
+	}
+	f, err := os.Create(profileMem)
+	if err != nil {
+		return fmt.Errorf("failed to create memory profile: %w", err)
+	}
+	// The heap profile reports the allocations of the last garbage
+	// collection.
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write memory profile: %w", err)
+	}
+	return f.Close()
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&profileCPU, "profile-cpu", "",
+		`Path to write a pprof CPU profile of the command to, when it completes.`)
+	rootCmd.PersistentFlags().StringVar(&profileMem, "profile-mem", "",
+		`Path to write a pprof heap profile of the command to, when it completes.`)
+	// The flags are meant for reporting performance problems, not for
+	// everyday use.
+	_ = rootCmd.PersistentFlags().MarkHidden("profile-cpu")
+	_ = rootCmd.PersistentFlags().MarkHidden("profile-mem")
+	rootCmd.PersistentPreRunE = startProfiling
+	rootCmd.PersistentPostRunE = stopProfiling
+}
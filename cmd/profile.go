@@ -0,0 +1,73 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/spf13/cobra"
+)
+
+// applyProfile sets the flags of the options of the profile, except the
+// ones set on the command line, which take precedence.
+func applyProfile(cmd *cobra.Command, name string) error {
+	p, err := i2gw.LookupProfile(name)
+	if err != nil {
+		return err
+	}
+	values := map[string]string{}
+	if p.TargetImplementation != "" {
+		values["target-implementation"] = p.TargetImplementation
+	}
+	if p.Experimental {
+		values["experimental"] = strconv.FormatBool(p.Experimental)
+	}
+	if p.ListenerStrategy != "" {
+		values["listener-strategy"] = string(p.ListenerStrategy)
+	}
+	if p.AttachToListeners {
+		values["attach-to-listeners"] = strconv.FormatBool(p.AttachToListeners)
+	}
+	if p.HTTPSOnly != "" {
+		values["https-only"] = string(p.HTTPSOnly)
+	}
+	if p.Mode != "" {
+		values["mode"] = string(p.Mode)
+	}
+	if len(p.DisabledFeatures) > 0 {
+		values["disable-feature"] = strings.Join(p.DisabledFeatures, ",")
+	}
+	for flag, value := range values {
+		if cmd.Flags().Changed(flag) {
+			continue
+		}
+		if err := cmd.Flags().Set(flag, value); err != nil {
+			return fmt.Errorf("failed to set --%s of profile %s: %w", flag, p.Name, err)
+		}
+	}
+	return nil
+}
+
+func profileNames() []string {
+	var names []string
+	for _, p := range i2gw.Profiles() {
+		names = append(names, p.Name)
+	}
+	return names
+}
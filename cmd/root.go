@@ -18,9 +18,90 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/execplugin"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+var (
+	inputFile             string
+	helmChart             string
+	helmRelease           string
+	helmNamespace         string
+	helmValuesFiles       []string
+	helmValues            []string
+	stream                bool
+	targetImplementation  string
+	experimental          bool
+	experimentalFeatures  []string
+	listenerStrategy      string
+	httpPort              int32
+	httpsPort             int32
+	classListenerPorts    map[string]string
+	httpsOnly             string
+	attachToListeners     bool
+	normalizeWeights      int32
+	consolidateRules      bool
+	pathCollisions        string
+	includeMigrated       bool
+	disabledFeatures      []string
+	excludeFile           string
+	checkpointFile        string
+	profile               string
+	gatewayNamespace      string
+	annotate              bool
+	output                string
+	outputLayout          string
+	outputDir             string
+	argoCD                bool
+	summary               bool
+	mode                  string
+	routeNaming           string
+	routeStrategy         string
+	maxSupportLevel       string
+	externalDNS           string
+	progressiveDelivery   string
+	hostHeaders           map[string]string
+	tlsModes              map[string]string
+	namespaceMap          map[string]string
+	gatewayLabels         map[string]string
+	gatewayAnnotations    map[string]string
+	gatewayInfrastructure bool
+	sourceChecksums       bool
+	confidenceAnnotations bool
+	clean                 bool
+	templateFile          string
+	capacityReport        bool
+	weightReport          bool
+	singleGateway         string
+	defaultCertificate    string
+	externalNameBackends  bool
+	findings              string
+	findingsFile          string
+	hostMapping           string
+	hostMappingFile       string
+	stats                 bool
+	statsFile             string
+	providerPlugins       []string
+	sourceContext         string
+	fleetContexts         []string
+	targetContext         string
+	targetDryRun          bool
+	targetWait            time.Duration
+	markMigrated          bool
+	cleanupFile           string
+	cleanupVerify         bool
+	discoverCapabilities  bool
+	checkGatewayClasses   bool
+	gatewayClassCtrl      string
+	timeout               time.Duration
+	cacheDir              string
 )
 
 var rootCmd = &cobra.Command{
@@ -31,10 +112,396 @@ var rootCmd = &cobra.Command{
 			fmt.Printf("Error parsing flags: %v", err)
 		}
 
-		i2gw.Run()
+		if profile != "" {
+			if err := applyProfile(cmd, profile); err != nil {
+				fmt.Printf("Invalid --profile: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		ports := map[string]i2gw.ListenerPorts{}
+		for class, value := range classListenerPorts {
+			p, err := i2gw.ParseListenerPorts(value)
+			if err != nil {
+				fmt.Printf("Invalid --class-listener-ports for IngressClass %s: %v\n", class, err)
+				os.Exit(1)
+			}
+			ports[class] = p
+		}
+
+		var defaultCert types.NamespacedName
+		if defaultCertificate != "" {
+			var err error
+			defaultCert, err = i2gw.ParseDefaultCertificate(defaultCertificate)
+			if err != nil {
+				fmt.Printf("Invalid --default-certificate: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		modes := map[string]i2gw.HostHeaderMode{}
+		for provider, mode := range hostHeaders {
+			modes[provider] = i2gw.HostHeaderMode(mode)
+		}
+
+		hostTLSModes := map[string]gatewayv1.TLSModeType{}
+		for host, mode := range tlsModes {
+			hostTLSModes[host] = gatewayv1.TLSModeType(mode)
+		}
+
+		var exclusions []string
+		if excludeFile != "" {
+			var err error
+			exclusions, err = i2gw.ReadExclusionFile(excludeFile)
+			if err != nil {
+				fmt.Printf("Invalid --exclude-file: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		var providers []i2gw.Provider
+		for _, value := range providerPlugins {
+			p, err := execplugin.ParseProviderPlugin(value)
+			if err != nil {
+				fmt.Printf("Invalid --provider-plugin: %v\n", err)
+				os.Exit(1)
+			}
+			providers = append(providers, p)
+		}
+
+		// SOURCE_DATE_EPOCH fixes the time reports are generated at, for
+		// reproducible output.
+		var clock i2gw.Clock
+		if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+			seconds, err := strconv.ParseInt(epoch, 10, 64)
+			if err != nil {
+				fmt.Printf("Invalid SOURCE_DATE_EPOCH: %v\n", err)
+				os.Exit(1)
+			}
+			clock = i2gw.FixedClock(time.Unix(seconds, 0))
+		}
+
+		chart := i2gw.HelmChartOptions{
+			Chart:       helmChart,
+			Release:     helmRelease,
+			Namespace:   helmNamespace,
+			ValuesFiles: helmValuesFiles,
+			Values:      helmValues,
+		}
+
+		i2gw.Run(i2gw.RunOptions{
+			InputFile:              inputFile,
+			HelmChart:              chart,
+			Stream:                 stream,
+			CheckpointFile:         checkpointFile,
+			TargetImplementation:   targetImplementation,
+			Experimental:           experimental,
+			ExperimentalFeatures:   experimentalFeatures,
+			ListenerStrategy:       i2gw.ListenerStrategy(listenerStrategy),
+			ListenerPorts:          i2gw.ListenerPorts{HTTP: httpPort, HTTPS: httpsPort},
+			ClassListenerPorts:     ports,
+			HTTPSOnly:              i2gw.HTTPSOnlyMode(httpsOnly),
+			AttachToListeners:      attachToListeners,
+			NormalizeWeights:       normalizeWeights,
+			ConsolidateRules:       consolidateRules,
+			PathCollisions:         i2gw.PathCollisionPolicy(pathCollisions),
+			IncludeMigrated:        includeMigrated,
+			DisabledFeatures:       disabledFeatures,
+			Exclusions:             exclusions,
+			GatewayNamespace:       gatewayNamespace,
+			Annotate:               annotate,
+			Output:                 i2gw.OutputFormat(output),
+			OutputLayout:           i2gw.OutputLayout(outputLayout),
+			OutputDir:              outputDir,
+			ArgoCD:                 argoCD,
+			Summary:                summary,
+			Mode:                   i2gw.ConversionMode(mode),
+			RouteNaming:            i2gw.RouteNaming(routeNaming),
+			RouteStrategy:          i2gw.RouteStrategy(routeStrategy),
+			MaxSupportLevel:        i2gw.SupportLevel(maxSupportLevel),
+			ExternalDNS:            i2gw.ExternalDNSMode(externalDNS),
+			ProgressiveDelivery:    i2gw.ProgressiveDeliveryMode(progressiveDelivery),
+			HostHeaders:            modes,
+			TLSModes:               hostTLSModes,
+			NamespaceMap:           namespaceMap,
+			GatewayLabels:          gatewayLabels,
+			GatewayAnnotations:     gatewayAnnotations,
+			GatewayInfrastructure:  gatewayInfrastructure,
+			SourceChecksums:        sourceChecksums,
+			ConfidenceAnnotations:  confidenceAnnotations,
+			Clean:                  clean,
+			Template:               templateFile,
+			CapacityReport:         capacityReport,
+			WeightReport:           weightReport,
+			SingleGateway:          singleGateway,
+			DefaultCertificate:     defaultCert,
+			ExternalNameBackends:   externalNameBackends,
+			Findings:               i2gw.FindingsFormat(findings),
+			FindingsFile:           findingsFile,
+			HostMapping:            i2gw.HostMappingFormat(hostMapping),
+			HostMappingFile:        hostMappingFile,
+			Stats:                  stats,
+			StatsFile:              statsFile,
+			Clock:                  clock,
+			Providers:              providers,
+			SourceContext:          sourceContext,
+			FleetContexts:          fleetContexts,
+			TargetContext:          targetContext,
+			TargetDryRun:           targetDryRun,
+			TargetWait:             targetWait,
+			MarkMigrated:           markMigrated,
+			CleanupFile:            cleanupFile,
+			CleanupVerify:          cleanupVerify,
+			DiscoverCapabilities:   discoverCapabilities,
+			CheckGatewayClasses:    checkGatewayClasses,
+			GatewayClassController: gatewayClassCtrl,
+			Timeout:                timeout,
+			CacheDir:               cacheDir,
+		})
 	},
 }
 
+func init() {
+	rootCmd.Flags().StringVar(&inputFile, "input-file", "",
+		`Path to a manifest file to read Ingresses from instead of the cluster. Ingresses using the deprecated
+networking.k8s.io/v1beta1 and extensions/v1beta1 APIs are upgraded to networking.k8s.io/v1.`)
+	rootCmd.Flags().StringVar(&helmChart, "helm-chart", "",
+		`Helm chart to render with helm template and convert the Ingresses of instead of the cluster, as a path, a
+URL or a repository/name reference. Deployed Helm release Secrets and ConfigMaps of the input file, such as
+the ones of kubectl get secrets -l owner=helm,status=deployed, are converted as the objects of their
+manifest.`)
+	rootCmd.Flags().StringVar(&helmRelease, "helm-release", "",
+		`Name of the release of the --helm-chart. release-name if not set.`)
+	rootCmd.Flags().StringVar(&helmNamespace, "helm-namespace", "",
+		`Namespace of the release of the --helm-chart, set on the objects that don't set theirs. default if not set.`)
+	rootCmd.Flags().StringArrayVar(&helmValuesFiles, "helm-values", nil,
+		`Values file of the --helm-chart. Can be repeated, the last file taking precedence.`)
+	rootCmd.Flags().StringArrayVar(&helmValues, "helm-set", nil,
+		`key=value override of the values of the --helm-chart, as for helm --set. Can be repeated.`)
+	rootCmd.Flags().BoolVar(&stream, "stream", false,
+		`Convert the input file one namespace at a time, writing the output of each namespace as soon as it is
+converted. Ingresses must be grouped by namespace in the input file.`)
+	rootCmd.Flags().StringVar(&checkpointFile, "checkpoint-file", "",
+		`Path to a file recording the Ingresses converted with --stream, created if it doesn't exist, so that an
+interrupted conversion resumes where it stopped: the Ingresses it records are skipped.`)
+	rootCmd.Flags().StringVar(&profile, "profile", "",
+		fmt.Sprintf(`Preset of the target implementation, listener strategy, experimental fields and policy settings of a
+Gateway API implementation, instead of setting their flags one by one. The flags set on the command line
+take precedence over the profile. The strict variants only convert the fully supported Ingresses, redirect
+HTTP to HTTPS and leave NGINX snippets out. One of: %s.`, strings.Join(profileNames(), ", ")))
+	rootCmd.Flags().StringVar(&targetImplementation, "target-implementation", "",
+		fmt.Sprintf(`Gateway API implementation to tailor the output for, emitting its policies alongside the Gateway
+API resources. One of: %s.`, strings.Join(i2gw.TargetImplementations(), ", ")))
+	rootCmd.Flags().BoolVar(&experimental, "experimental", false,
+		`Use fields of the experimental channel of Gateway API, such as HTTPRoute retries and listener
+client certificate validation. The experimental CRDs must be installed in the cluster.`)
+	rootCmd.Flags().StringSliceVar(&experimentalFeatures, "experimental-features", nil,
+		fmt.Sprintf(`Features outside of the standard channel of Gateway API to enable, without --experimental enabling
+all of them. The others are converted to standard resources or reported. Any of: %s.`, strings.Join(i2gw.ExperimentalFeatures(), ", ")))
+	rootCmd.Flags().StringVar(&listenerStrategy, "listener-strategy", string(i2gw.ListenerPerHost),
+		fmt.Sprintf(`How Gateway listeners are generated: %q generates listeners for every host, %q a single
+listener for the hosts covered by the wildcard host of a TLS certificate.`, i2gw.ListenerPerHost, i2gw.ListenerPerCertificate))
+	rootCmd.Flags().Int32Var(&httpPort, "http-port", 80,
+		`Port of the HTTP listeners of Gateways, for Gateways listening on a non-standard port behind an external
+load balancer.`)
+	rootCmd.Flags().Int32Var(&httpsPort, "https-port", 443,
+		`Port of the HTTPS listeners of Gateways.`)
+	rootCmd.Flags().StringToStringVar(&classListenerPorts, "class-listener-ports", nil,
+		`Ports of the listeners of the Gateways of IngressClasses, overriding --http-port and --https-port, as
+IngressClass=HTTP:HTTPS pairs, such as internal=8080:8443. Either port may be omitted.`)
+	rootCmd.Flags().StringVar(&httpsOnly, "https-only", "",
+		fmt.Sprintf(`Stop serving plain HTTP requests to the hosts served over HTTPS: %q omits their HTTP listeners,
+%q redirects their HTTP requests to HTTPS. --https-only alone omits them.`, i2gw.HTTPSOnlyOmit, i2gw.HTTPSOnlyRedirect))
+	rootCmd.Flags().Lookup("https-only").NoOptDefVal = string(i2gw.HTTPSOnlyOmit)
+	rootCmd.Flags().BoolVar(&attachToListeners, "attach-to-listeners", false,
+		`Attach every HTTPRoute to the listeners generated for its host and protocol, setting the sectionName
+and port of its parentRefs, instead of the whole Gateway.`)
+	rootCmd.Flags().Int32Var(&normalizeWeights, "normalize-weights", 0,
+		`Total the backend weights of weighted HTTPRoute rules add up to, such as 100 or 1000, instead of the
+weight totals of the canaries. Exact shares are rounded down and the weight left goes to the backends
+with the largest remainders, the first ones on ties.`)
+	rootCmd.Flags().BoolVar(&consolidateRules, "consolidate-rules", false,
+		`Merge the backendRefs of a rule to the same backend, adding up their weights, and the rules of an
+HTTPRoute differing only by their matches, such as the ones of Ingresses generated from a template.`)
+	rootCmd.Flags().StringVar(&pathCollisions, "path-collisions", string(i2gw.PathCollisionMergeWeighted),
+		fmt.Sprintf(`How the paths of several Ingresses matching the same requests of a host with different backends
+are converted: %s splits the requests between the backends of every Ingress, %s only keeps the
+backends of the oldest Ingress, and %s also reports an error for the other Ingresses.`, i2gw.PathCollisionMergeWeighted, i2gw.PathCollisionFirstWins, i2gw.PathCollisionError))
+	rootCmd.Flags().StringSliceVar(&disabledFeatures, "disable-feature", nil,
+		fmt.Sprintf(`Features of Ingresses to leave out of the conversion, to handle them manually. Their annotations are
+reported as intentionally not converted. Any of: %s.`, strings.Join(i2gw.ConversionFeatures(), ", ")))
+	rootCmd.Flags().StringVar(&excludeFile, "exclude-file", "",
+		`Path to a file of namespace/name glob patterns, one per line, such as legacy/* or */internal-*, of the
+Ingresses to leave out of the conversion entirely, such as the workloads that will never migrate or are
+handled separately. Lines starting with # are comments. Excluded Ingresses are listed in the report.`)
+	rootCmd.Flags().StringVar(&gatewayNamespace, "gateway-namespace", "",
+		`Namespace of every Gateway, such as an infrastructure namespace, instead of the namespaces of the
+Ingresses. HTTPRoutes reference their Gateway across namespaces and listeners allow the routes of the
+namespaces attaching to them. Not supported with --stream.`)
+	rootCmd.Flags().StringVar(&singleGateway, "single-gateway", "",
+		`Merge the Gateways of every IngressClass into a single Gateway of this GatewayClass, which every
+HTTPRoute attaches to, when consolidating several Ingress controllers. Requires --gateway-namespace.`)
+	rootCmd.Flags().StringVar(&defaultCertificate, "default-certificate", "",
+		`Secret of the default certificate of the Ingress controller, formatted as namespace/name, referenced
+by the listeners of hosts whose Ingress TLS entries have no secretName.`)
+	rootCmd.Flags().BoolVar(&externalNameBackends, "external-name-backends", false,
+		`Route the backends of ExternalName Services, which many Gateway implementations reject as
+backendRefs, to backends of the target implementation for their external host instead, such as
+Envoy Gateway Backends. Requires --target-implementation=envoy-gateway.`)
+	rootCmd.Flags().BoolVar(&annotate, "annotate", false,
+		`Precede every Gateway and HTTPRoute with comments about the Ingresses, and the annotations of the
+Ingresses, it was converted from, to ease the review of large conversions.`)
+	rootCmd.Flags().StringVarP(&output, "output", "o", string(i2gw.OutputYAML),
+		fmt.Sprintf(`Format of the output: %q writes YAML documents, %q YAML documents without status and server
+populated metadata, to be applied with Server-Side Apply by the apply command, %q a single v1 List of the
+resources. %q and %q write the traffic layout of the conversion, from Ingresses to listeners, HTTPRoutes
+and backends, as a Mermaid or Graphviz graph instead, with the report on stderr.`, i2gw.OutputYAML, i2gw.OutputServerSideApply, i2gw.OutputList, i2gw.OutputMermaid, i2gw.OutputDOT))
+	rootCmd.Flags().StringVar(&outputLayout, "output-layout", string(i2gw.LayoutStdout),
+		fmt.Sprintf(`Where the resources are written: %q writes them to stdout, %q to a file per resource in the
+namespaces/<namespace>/<kind>/ directories of --output-dir, with kustomization files, %q to a
+<namespace>.yaml file per namespace of --output-dir, with the findings about the namespace and an index.yaml
+file listing the Ingresses, resources and findings of every namespace. The report is always written to
+stdout.`, i2gw.LayoutStdout, i2gw.LayoutGitOps, i2gw.LayoutNamespaces))
+	rootCmd.Flags().StringVar(&outputDir, "output-dir", "",
+		`Directory the gitops and namespaces output layouts are written to.`)
+	rootCmd.Flags().BoolVar(&argoCD, "argocd", false,
+		`Annotate the resources of the gitops output layout with Argo CD sync waves, syncing Gateways and
+ReferenceGrants before HTTPRoutes, and policies last.`)
+	rootCmd.Flags().StringVar(&mode, "mode", string(i2gw.ModeBestEffort),
+		fmt.Sprintf(`What is generated for the Ingresses that can't be fully converted: %q converts what it can and
+drops the rest, %q generates no HTTPRoute rule for them, nor for the Ingresses sharing rules with them,
+so that none of their traffic is partially migrated.`, i2gw.ModeBestEffort, i2gw.ModeStrict))
+	rootCmd.Flags().StringVar(&routeNaming, "route-naming", string(i2gw.RouteNamingHost),
+		fmt.Sprintf(`What HTTPRoute names are derived from: %q names them after their host, %q after the oldest
+Ingress they are converted from followed by their host.`, i2gw.RouteNamingHost, i2gw.RouteNamingIngress))
+	rootCmd.Flags().StringVar(&routeStrategy, "route-strategy", string(i2gw.RoutePerHost),
+		fmt.Sprintf(`How the rules of Ingresses are grouped into HTTPRoutes: %q merges the Ingresses of a host into one
+HTTPRoute, %q generates the HTTPRoutes of every Ingress on their own, named after it, keeping its ownership
+boundaries. Canary Ingresses stay in the HTTPRoutes of their primary Ingress.`, i2gw.RoutePerHost, i2gw.RoutePerIngress))
+	rootCmd.Flags().StringVar(&maxSupportLevel, "max-support-level", "",
+		fmt.Sprintf(`If set, the highest Gateway API support level of the generated features, one of: %s. Features above it
+are dropped with warnings, such as the extended URLRewrite filters and method matches with %q, for implementations
+only supporting the core features.`, strings.Join(i2gw.SupportLevels(), ", "), i2gw.SupportCore))
+	rootCmd.Flags().StringVar(&externalDNS, "external-dns", string(i2gw.ExternalDNSCarry),
+		fmt.Sprintf(`How the external-dns annotations of Ingresses are propagated: %q sets their target annotation on
+their Gateways and the other ones on their HTTPRoutes, %q also sets the target of Gateways to the load
+balancer addresses of their Ingresses, for the records to keep pointing to the Ingress controller until
+the target annotation is removed, %q propagates none.`, i2gw.ExternalDNSCarry, i2gw.ExternalDNSKeepTargets, i2gw.ExternalDNSNone))
+	rootCmd.Flags().StringVar(&progressiveDelivery, "progressive-delivery", string(i2gw.ProgressiveDeliveryFreeze),
+		fmt.Sprintf(`How the Ingresses whose canary weights Argo Rollouts or Flagger change at runtime are converted: %q
+keeps the weights at conversion time, %q sends no traffic to their canaries and reports how to configure
+the Rollouts and Canaries to shift the traffic of the generated resources instead.`, i2gw.ProgressiveDeliveryFreeze, i2gw.ProgressiveDeliveryHints))
+	rootCmd.Flags().StringToStringVar(&gatewayLabels, "gateway-labels", nil,
+		`Labels set on every Gateway, as key=value pairs, such as the ones the provisioning automation of the
+Gateway implementation relies on.`)
+	rootCmd.Flags().StringToStringVar(&gatewayAnnotations, "gateway-annotations", nil,
+		`Annotations set on every Gateway, as key=value pairs, such as the load balancer naming or firewall
+tagging annotations of the Gateway implementation.`)
+	rootCmd.Flags().BoolVar(&gatewayInfrastructure, "gateway-infrastructure", false,
+		`Also set the --gateway-labels and --gateway-annotations in the spec.infrastructure of Gateways, which
+implementations set on the resources they provision for them, such as load balancer Services. At most 8
+labels and 8 annotations are allowed.`)
+	rootCmd.Flags().StringToStringVar(&hostHeaders, "host-header", nil,
+		fmt.Sprintf(`How the Ingress controllers of providers set the Host header of the requests sent to backends for the
+Ingresses that don't configure it, as provider=mode pairs, such as ingress-nginx=%s: %q sends the Host
+header of the request, like Gateway API implementations, %q the DNS name of the Service of the backend,
+which the HTTPRoutes rewrite the Host header to. Providers default to %q.`, i2gw.HostHeaderRewrite, i2gw.HostHeaderPreserve, i2gw.HostHeaderRewrite, i2gw.HostHeaderPreserve))
+	rootCmd.Flags().StringToStringVar(&tlsModes, "tls-mode", nil,
+		fmt.Sprintf(`TLS mode of the listeners of hosts, as host=mode pairs where host may be a wildcard hostname such as
+*.example.com: %q passes their TLS connections through to the backend of their "/" path with TLSRoutes, which
+needs --experimental-features=tlsroute, %q terminates them on HTTPS listeners and converts their requests to
+HTTPRoutes. Hosts default to the behavior of their Ingress controller, such as the ssl-passthrough annotation of
+ingress-nginx, and the %s annotation of Ingresses takes precedence.`, gatewayv1.TLSModePassthrough, gatewayv1.TLSModeTerminate, i2gw.TLSModeAnnotation))
+	rootCmd.Flags().StringToStringVar(&namespaceMap, "namespace-map", nil,
+		`Namespaces the resources generated for the Ingresses of namespaces are moved to, as old=new pairs, such as
+when consolidating applications. Their backendRefs and certificateRefs keep pointing at the Services and Secrets of
+the old namespaces, which the generated ReferenceGrants allow, and the Gateways moved to the same namespace are
+merged.`)
+	rootCmd.Flags().BoolVar(&sourceChecksums, "source-checksums", false,
+		`Annotate every Gateway and HTTPRoute with the Ingresses it is converted from and their checksum, for the
+verify command to detect the Ingresses changed since the conversion.`)
+	rootCmd.Flags().BoolVar(&confidenceAnnotations, "confidence-annotations", false,
+		fmt.Sprintf(`Annotate every Gateway and HTTPRoute with %s: %q, %q or %q, depending on whether
+the features of its Ingresses are all mapped, some approximated, or some not converted.`, i2gw.ConfidenceAnnotation, i2gw.ConfidenceExact, i2gw.ConfidenceApproximated, i2gw.ConfidencePartial))
+	rootCmd.Flags().BoolVar(&clean, "clean", false,
+		`Write the resources without status and the metadata populated by the API server, such as null
+creationTimestamps, to keep the diffs of committed manifests clean. Always set with --output=ssa.`)
+	rootCmd.Flags().StringVar(&templateFile, "template", "",
+		`Render the conversion through the Go text/template in this file instead of the output format, e.g. to
+generate Terraform or Pulumi code. See the README for the data and functions templates can use.`)
+	rootCmd.Flags().BoolVar(&capacityReport, "capacity-report", false,
+		`Write the numbers of Gateways, listeners, HTTPRoutes, rules and cross-namespace references generated by
+namespace and GatewayClass to stderr, to check them against the quotas of the Gateway implementation.`)
+	rootCmd.Flags().BoolVar(&weightReport, "weight-report", false,
+		`Write the share of the requests of every backend of the HTTPRoute rules splitting them between several
+backends, such as canaries, to stderr after the output, flagging the canary weights that aren't preserved.`)
+	rootCmd.Flags().BoolVar(&summary, "summary", false,
+		`Write a summary of the conversion to stderr after the output: the Ingresses converted and skipped, the
+resources generated, the notifications by severity and the Ingresses using each unsupported annotation.
+Colored on terminals unless NO_COLOR is set.`)
+	rootCmd.Flags().StringVar(&findings, "findings", "",
+		fmt.Sprintf(`Write the errors, notifications, unsupported annotations and skipped Ingresses of the conversion
+with their severity, rule ID and objects, for CI systems to gate on. One of: %s.`, strings.Join(i2gw.FindingsFormats(), ", ")))
+	rootCmd.Flags().StringVar(&findingsFile, "findings-file", "",
+		`Path of the file --findings are written to, instead of stderr.`)
+	rootCmd.Flags().StringVar(&hostMapping, "host-mapping", "",
+		fmt.Sprintf(`Write the hostname, Gateway, listener and certificate Secrets of every generated listener, with the
+Ingresses of each hostname, for DNS and certificate teams to plan the migration. One of: %s.`, strings.Join(i2gw.HostMappingFormats(), ", ")))
+	rootCmd.Flags().StringVar(&hostMappingFile, "host-mapping-file", "",
+		`Path of the file --host-mapping is written to, instead of stderr.`)
+	rootCmd.Flags().BoolVar(&stats, "stats", false,
+		`Write the numbers of Ingresses converted, partially converted, blocked, skipped and excluded, in total and
+by namespace, with the numbers of Ingresses using each unsupported annotation, as a JSON document for
+migration dashboards.`)
+	rootCmd.Flags().StringVar(&statsFile, "stats-file", "",
+		`Path of the file --stats are written to, instead of stderr.`)
+	rootCmd.Flags().StringArrayVar(&providerPlugins, "provider-plugin", nil,
+		`Out-of-tree provider converting the Ingresses of an Ingress controller, as controller=command. The
+command receives each Ingress as JSON on stdin and writes its features as JSON on stdout, see the README.
+May be repeated.`)
+	rootCmd.Flags().StringVar(&sourceContext, "source-context", "",
+		`Kubeconfig context of the cluster to read Ingresses from, instead of the current context.`)
+	rootCmd.Flags().StringSliceVar(&fleetContexts, "fleet-contexts", nil,
+		fmt.Sprintf(`Kubeconfig contexts of clusters to convert one after the other, each to a directory of --output-dir
+named after its context, with a consolidated report of every cluster in %s.`, i2gw.FleetReportFile))
+	rootCmd.Flags().StringVar(&targetContext, "target-context", "",
+		`Kubeconfig context of the cluster to apply the generated resources to with Server-Side Apply, after
+writing the output, such as the green cluster of a blue/green migration.`)
+	rootCmd.Flags().BoolVar(&targetDryRun, "target-dry-run", false,
+		`Only validate the generated resources with the API server of --target-context, without persisting them.`)
+	rootCmd.Flags().DurationVar(&targetWait, "target-wait", 0,
+		`How long to wait for the Gateways applied to --target-context to be programmed and the routes to be
+accepted, with resolved references, reporting the ones the target implementation rejected as errors.`)
+	rootCmd.Flags().BoolVar(&markMigrated, "mark-migrated", false,
+		fmt.Sprintf(`Annotate the converted Ingresses of the source cluster with %s, listing the routes they were
+converted to, after writing the output and applying it to --target-context, for incremental cutovers.
+Annotated Ingresses are skipped by later conversions.`, i2gw.MigratedToAnnotation))
+	rootCmd.Flags().StringVar(&cleanupFile, "cleanup-file", "",
+		`Path of a shell script deleting the source Ingresses converted in full, written after the output. The
+Ingresses that failed to convert in part are listed with the reason they are kept.`)
+	rootCmd.Flags().BoolVar(&cleanupVerify, "cleanup-verify", false,
+		`Only delete the Ingresses whose routes are accepted by their Gateways in the cluster of --target-context,
+or the source cluster, in the --cleanup-file.`)
+	rootCmd.Flags().BoolVar(&includeMigrated, "include-migrated", false,
+		fmt.Sprintf(`Convert the Ingresses annotated with %s by an earlier --mark-migrated conversion too.`, i2gw.MigratedToAnnotation))
+	rootCmd.Flags().BoolVar(&discoverCapabilities, "discover-capabilities", false,
+		`Tailor the output to the Gateway API CRDs installed in the cluster of --target-context, or the source
+cluster, skipping the resources and fields it doesn't support.`)
+	rootCmd.Flags().BoolVar(&checkGatewayClasses, "check-gatewayclasses", false,
+		`Check that the GatewayClasses of the generated Gateways exist in the cluster of --target-context, or the
+source cluster, reporting an error for each missing one without --gatewayclass-controller.`)
+	rootCmd.Flags().StringVar(&gatewayClassCtrl, "gatewayclass-controller", "",
+		`Generate the missing GatewayClasses, or all of them without --check-gatewayclasses, with this
+controller name, such as example.net/gateway-controller.`)
+	rootCmd.Flags().DurationVar(&timeout, "timeout", 0,
+		`Cancel the run, from reading the Ingresses to applying the resources, if it takes longer than this,
+such as 5m. A run is cancelled on interrupt regardless.`)
+	rootCmd.Flags().StringVar(&cacheDir, "cache-dir", "",
+		`Directory caching the output of the conversions of an --input-file, by digest of the file, the options
+and the binary, so that repeated runs over unchanged input, such as in CI, replay the same output without
+converting it again. Only supported for output to stdout, without reports or cluster access.`)
+}
+
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
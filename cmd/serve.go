@@ -0,0 +1,60 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/serve"
+	"github.com/spf13/cobra"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var serveOpts serve.Options
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the conversion as an HTTP API",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := cmd.ParseFlags(args); err != nil {
+			fmt.Printf("Error parsing flags: %v", err)
+		}
+
+		if err := serve.Run(ctrl.SetupSignalHandler(), serveOpts); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveOpts.Address, "address", ":8080",
+		`Address the API is served on.`)
+	serveCmd.Flags().Int64Var(&serveOpts.MaxRequestBytes, "max-request-bytes", serve.DefaultMaxRequestBytes,
+		`Largest request body accepted.`)
+	serveCmd.Flags().StringVar(&serveOpts.ConvertOptions.TargetImplementation, "target-implementation", "",
+		fmt.Sprintf(`Default Gateway API implementation to tailor the output for, overridden by the targetImplementation
+query parameter. One of: %s.`, strings.Join(i2gw.TargetImplementations(), ", ")))
+	serveCmd.Flags().StringVar(&serveOpts.ConvertOptions.GatewayNamespace, "gateway-namespace", "",
+		`Default namespace of every Gateway, overridden by the gatewayNamespace query parameter.`)
+	serveCmd.Flags().BoolVar(&serveOpts.ConvertOptions.Experimental, "experimental", false,
+		`Use fields of the experimental channel of Gateway API by default, overridden by the experimental query
+parameter.`)
+	rootCmd.AddCommand(serveCmd)
+}
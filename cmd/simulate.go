@@ -0,0 +1,57 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/spf13/cobra"
+)
+
+var (
+	simulateOpts    i2gw.SimulateOptions
+	simulateMethod  string
+	simulateHeaders []string
+)
+
+var simulateCmd = &cobra.Command{
+	Use:   "simulate URL",
+	Short: "Show which backend a request reaches through the HTTPRoutes the Ingresses are converted to",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := cmd.ParseFlags(args); err != nil {
+			fmt.Printf("Error parsing flags: %v", err)
+		}
+
+		i2gw.RunSimulate(simulateOpts, simulateMethod, args[0], simulateHeaders)
+	},
+}
+
+func init() {
+	simulateCmd.Flags().StringVarP(&simulateMethod, "method", "X", "GET",
+		`Method of the request.`)
+	simulateCmd.Flags().StringArrayVarP(&simulateHeaders, "header", "H", nil,
+		`Header of the request as Name:Value. Can be repeated.`)
+	simulateCmd.Flags().StringVar(&simulateOpts.ConvertOptions.InputFile, "input-file", "",
+		`Path to a manifest file to read the Ingresses from instead of the cluster.`)
+	simulateCmd.Flags().StringVarP(&simulateOpts.ManifestFile, "filename", "f", "",
+		`Path to the output of a conversion to route the request with instead of converting the Ingresses.`)
+	simulateCmd.Flags().StringVar(&simulateOpts.ConvertOptions.TargetImplementation, "target-implementation", "",
+		fmt.Sprintf(`Gateway API implementation to convert the Ingresses for. One of: %s.`, strings.Join(i2gw.TargetImplementations(), ", ")))
+	rootCmd.AddCommand(simulateCmd)
+}
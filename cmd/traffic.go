@@ -0,0 +1,55 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/spf13/cobra"
+)
+
+var (
+	trafficOpts         i2gw.TrafficOptions
+	trafficRequestsFile string
+)
+
+var verifyTrafficCmd = &cobra.Command{
+	Use:   "verify-traffic",
+	Short: "Check that sample requests are routed the same by the Ingresses and their conversion",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := cmd.ParseFlags(args); err != nil {
+			fmt.Printf("Error parsing flags: %v", err)
+		}
+
+		i2gw.RunVerifyTraffic(trafficOpts, trafficRequestsFile)
+	},
+}
+
+func init() {
+	verifyTrafficCmd.Flags().StringVar(&trafficRequestsFile, "requests", "",
+		`Path to a file of sample requests, one per line as an optional method, an absolute URL and Name:Value
+headers, such as "POST https://example.com/api X-Canary:always".`)
+	verifyTrafficCmd.MarkFlagRequired("requests")
+	verifyTrafficCmd.Flags().StringVar(&trafficOpts.ConvertOptions.InputFile, "input-file", "",
+		`Path to a manifest file to read the Ingresses from instead of the cluster.`)
+	verifyTrafficCmd.Flags().StringVarP(&trafficOpts.ManifestFile, "filename", "f", "",
+		`Path to the output of a conversion to compare with the Ingresses instead of converting them.`)
+	verifyTrafficCmd.Flags().StringVar(&trafficOpts.ConvertOptions.TargetImplementation, "target-implementation", "",
+		fmt.Sprintf(`Gateway API implementation to convert the Ingresses for. One of: %s.`, strings.Join(i2gw.TargetImplementations(), ", ")))
+	rootCmd.AddCommand(verifyTrafficCmd)
+}
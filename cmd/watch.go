@@ -0,0 +1,50 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/watch"
+	"github.com/spf13/cobra"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var watchOpts watch.Options
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Convert the Ingresses of the cluster again whenever they change",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := cmd.ParseFlags(args); err != nil {
+			fmt.Printf("Error parsing flags: %v", err)
+		}
+
+		if err := watch.Run(ctrl.SetupSignalHandler(), watchOpts); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	watchCmd.Flags().StringVar(&watchOpts.OutputFile, "output-file", "",
+		`Path of the file the output of every conversion is written to. If empty, the output is written to stdout.`)
+	watchCmd.Flags().StringVar(&watchOpts.MetricsBindAddress, "metrics-bind-address", ":8080",
+		`Address the Prometheus metrics are served on. Set it to "0" to disable them.`)
+	rootCmd.AddCommand(watchCmd)
+}
@@ -0,0 +1,57 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/preview"
+	"github.com/spf13/cobra"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var previewOpts preview.Options
+
+var webhookCmd = &cobra.Command{
+	Use:   "webhook",
+	Short: "Preview the conversion of Ingresses as a validating admission webhook that never rejects them",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := cmd.ParseFlags(args); err != nil {
+			fmt.Printf("Error parsing flags: %v", err)
+		}
+
+		if err := preview.Run(ctrl.SetupSignalHandler(), previewOpts); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	webhookCmd.Flags().IntVar(&previewOpts.Port, "port", 9443,
+		fmt.Sprintf(`Port the webhook is served on with TLS, at the %s path.`, preview.Path))
+	webhookCmd.Flags().StringVar(&previewOpts.CertDir, "cert-dir", "",
+		`Directory holding the tls.crt and tls.key files of the serving certificate. Defaults to
+<temp-dir>/k8s-webhook-server/serving-certs.`)
+	webhookCmd.Flags().StringVar(&previewOpts.ConvertOptions.TargetImplementation, "target-implementation", "",
+		fmt.Sprintf(`Gateway API implementation to tailor the preview for. One of: %s.`, strings.Join(i2gw.TargetImplementations(), ", ")))
+	webhookCmd.Flags().StringVar(&previewOpts.ConvertOptions.GatewayNamespace, "gateway-namespace", "",
+		`Namespace of every Gateway.`)
+	rootCmd.AddCommand(webhookCmd)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto copies the IngressMigration into out.
+func (in *IngressMigration) DeepCopyInto(out *IngressMigration) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy returns a copy of the IngressMigration.
+func (in *IngressMigration) DeepCopy() *IngressMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *IngressMigration) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto copies the IngressMigrationSpec into out.
+func (in *IngressMigrationSpec) DeepCopyInto(out *IngressMigrationSpec) {
+	*out = *in
+	out.Namespaces = append([]string(nil), in.Namespaces...)
+	out.IngressClasses = append([]string(nil), in.IngressClasses...)
+}
+
+// DeepCopyInto copies the IngressMigrationStatus into out.
+func (in *IngressMigrationStatus) DeepCopyInto(out *IngressMigrationStatus) {
+	*out = *in
+	out.Conditions = deepCopyConditions(in.Conditions)
+	if in.Ingresses != nil {
+		out.Ingresses = make([]IngressStatus, len(in.Ingresses))
+		for i := range in.Ingresses {
+			in.Ingresses[i].DeepCopyInto(&out.Ingresses[i])
+		}
+	}
+	out.Resources = append([]ResourceRef(nil), in.Resources...)
+}
+
+// DeepCopyInto copies the IngressStatus into out.
+func (in *IngressStatus) DeepCopyInto(out *IngressStatus) {
+	*out = *in
+	out.Conditions = deepCopyConditions(in.Conditions)
+}
+
+func deepCopyConditions(in []metav1.Condition) []metav1.Condition {
+	if in == nil {
+		return nil
+	}
+	out := make([]metav1.Condition, len(in))
+	for i := range in {
+		in[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
+
+// DeepCopyInto copies the IngressMigrationList into out.
+func (in *IngressMigrationList) DeepCopyInto(out *IngressMigrationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]IngressMigration, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+// DeepCopy returns a copy of the IngressMigrationList.
+func (in *IngressMigrationList) DeepCopy() *IngressMigrationList {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressMigrationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *IngressMigrationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package v1alpha1 contains the IngressMigration API, which declares the
+// Ingresses the operator converts and keeps converted.
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+// GroupVersion is the group and version of the IngressMigration API.
+var GroupVersion = schema.GroupVersion{Group: "ingress2gateway.kubernetes.io", Version: "v1alpha1"}
+
+var (
+	// SchemeBuilder registers the types of the API.
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+	// AddToScheme adds the types of the API to a scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
+
+func init() {
+	SchemeBuilder.Register(&IngressMigration{}, &IngressMigrationList{})
+}
+
+// Condition types and reasons of IngressMigrations and of the Ingresses
+// they convert.
+const (
+	// ConditionReady tells whether the last conversion of the migration
+	// succeeded and, unless DryRun is set, was applied.
+	ConditionReady = "Ready"
+	// ConditionConverted tells whether an Ingress is converted.
+	ConditionConverted = "Converted"
+
+	ReasonConverted             = "Converted"
+	ReasonConvertedWithWarnings = "ConvertedWithWarnings"
+	ReasonBlocked               = "Blocked"
+	ReasonSkipped               = "Skipped"
+	ReasonConversionFailed      = "ConversionFailed"
+	ReasonApplyFailed           = "ApplyFailed"
+)
+
+// IngressMigration declares Ingresses to convert to Gateway API. The
+// operator converts them again whenever they change, applies the generated
+// resources with Server-Side Apply and reports the conversion of every
+// Ingress in the status.
+type IngressMigration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IngressMigrationSpec   `json:"spec,omitempty"`
+	Status IngressMigrationStatus `json:"status,omitempty"`
+}
+
+// IngressMigrationSpec is the scope of the migration and how its Gateways
+// are generated.
+type IngressMigrationSpec struct {
+	// Namespaces are the namespaces of the Ingresses converted. Ingresses of
+	// every namespace are converted if empty.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// IngressClasses are the IngressClasses of the Ingresses converted.
+	// Ingresses of every IngressClass are converted if empty.
+	IngressClasses []string `json:"ingressClasses,omitempty"`
+	// GatewayNamespace is the namespace of every Gateway, shared by the
+	// HTTPRoutes of all namespaces. Otherwise, each namespace gets its own
+	// Gateways.
+	GatewayNamespace string `json:"gatewayNamespace,omitempty"`
+	// SingleGateway merges the Gateways of every IngressClass into a single
+	// Gateway of this GatewayClass. It requires GatewayNamespace.
+	SingleGateway string `json:"singleGateway,omitempty"`
+	// ListenerStrategy selects how the listeners of Gateways are generated,
+	// "host" or "certificate".
+	ListenerStrategy string `json:"listenerStrategy,omitempty"`
+	// DryRun only reports the conversion in the status, without applying
+	// the generated resources.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// IngressMigrationStatus is the result of the last conversion.
+type IngressMigrationStatus struct {
+	// ObservedGeneration is the generation of the spec last converted.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions are the Ready condition of the migration.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Ingresses are the Ingresses converted, each with its Converted
+	// condition.
+	Ingresses []IngressStatus `json:"ingresses,omitempty"`
+	// Resources are the resources generated.
+	Resources []ResourceRef `json:"resources,omitempty"`
+}
+
+// IngressStatus is the conversion of an Ingress.
+type IngressStatus struct {
+	Namespace  string             `json:"namespace"`
+	Name       string             `json:"name"`
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ResourceRef is a resource generated by the conversion.
+type ResourceRef struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+// IngressMigrationList is a list of IngressMigrations.
+type IngressMigrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IngressMigration `json:"items"`
+}
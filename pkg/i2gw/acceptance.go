@@ -0,0 +1,150 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// acceptancePollInterval is how often the status of the applied resources
+// is read while waiting for their acceptance.
+var acceptancePollInterval = 2 * time.Second
+
+// acceptedKinds are the kinds whose status tells whether the target
+// implementation accepted them.
+var acceptedKinds = map[string]schema.GroupVersionKind{
+	gatewayGVK.Kind:   gatewayGVK,
+	httpRouteGVK.Kind: httpRouteGVK,
+	tlsRouteGVK.Kind:  tlsRouteGVK,
+	tcpRouteGVK.Kind:  tcpRouteGVK,
+}
+
+// RejectedResource is an applied resource the target implementation didn't
+// accept.
+type RejectedResource struct {
+	Ref    ObjectRef
+	Reason string
+}
+
+func (r RejectedResource) Error() string {
+	return fmt.Sprintf("%s %s/%s is not accepted: %s", r.Ref.Kind, r.Ref.Namespace, r.Ref.Name, r.Reason)
+}
+
+// WaitForAcceptance waits up to timeout for the Gateways to be programmed
+// and the routes of refs to be accepted, with resolved references, by
+// every parent, and returns the ones that still aren't. The other kinds of
+// refs are ignored.
+func WaitForAcceptance(ctx context.Context, cl client.Client, refs []ObjectRef, timeout time.Duration) ([]RejectedResource, error) {
+	var rejected []RejectedResource
+	err := wait.PollUntilContextTimeout(ctx, acceptancePollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		rejected = nil
+		for _, ref := range refs {
+			if _, ok := acceptedKinds[ref.Kind]; !ok {
+				continue
+			}
+			reason, err := resourceAcceptance(ctx, cl, ref)
+			if err != nil {
+				return false, err
+			}
+			if reason != "" {
+				rejected = append(rejected, RejectedResource{Ref: ref, Reason: reason})
+			}
+		}
+		return len(rejected) == 0, nil
+	})
+	if err != nil && !wait.Interrupted(err) {
+		return rejected, err
+	}
+	return rejected, nil
+}
+
+// resourceAcceptance returns why the Gateway isn't programmed or the route
+// isn't accepted, or an empty string if it is.
+func resourceAcceptance(ctx context.Context, cl client.Client, ref ObjectRef) (string, error) {
+	gvk, ok := acceptedKinds[ref.Kind]
+	if !ok {
+		return "", fmt.Errorf("unknown kind %s", ref.Kind)
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	if err := cl.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, obj); err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			return "it doesn't exist", nil
+		}
+		return "", fmt.Errorf("failed to get %s %s/%s: %w", ref.Kind, ref.Namespace, ref.Name, err)
+	}
+
+	if ref.Kind == gatewayGVK.Kind {
+		conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+		return conditionReason(conditions, "Programmed"), nil
+	}
+	parents, _, _ := unstructured.NestedSlice(obj.Object, "status", "parents")
+	if len(parents) == 0 {
+		return "no parent reported its status yet", nil
+	}
+	for _, parent := range parents {
+		parent, _ := parent.(map[string]any)
+		conditions, _, _ := unstructured.NestedSlice(parent, "conditions")
+		for _, conditionType := range []string{"Accepted", "ResolvedRefs"} {
+			if reason := conditionReason(conditions, conditionType); reason != "" {
+				parentName, _, _ := unstructured.NestedString(parent, "parentRef", "name")
+				return fmt.Sprintf("parent %s: %s", parentName, reason), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// conditionReason returns why the condition of this type isn't True, or an
+// empty string if it is.
+func conditionReason(conditions []any, conditionType string) string {
+	for _, condition := range conditions {
+		condition, _ := condition.(map[string]any)
+		if condition["type"] != conditionType {
+			continue
+		}
+		if condition["status"] == "True" {
+			return ""
+		}
+		reason := fmt.Sprintf("%s condition is %v", conditionType, condition["status"])
+		if r, _ := condition["reason"].(string); r != "" {
+			reason += ": " + r
+		}
+		if message, _ := condition["message"].(string); message != "" {
+			reason += ": " + message
+		}
+		return reason
+	}
+	return fmt.Sprintf("no %s condition yet", conditionType)
+}
+
+// rejectedError returns an error listing the rejected resources, if any.
+func rejectedError(rejected []RejectedResource) error {
+	errs := make([]error, 0, len(rejected))
+	for _, r := range rejected {
+		errs = append(errs, r)
+	}
+	return errors.Join(errs...)
+}
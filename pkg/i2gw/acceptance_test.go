@@ -0,0 +1,100 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func Test_WaitForAcceptance(t *testing.T) {
+	acceptancePollInterval = time.Millisecond
+	defer func() { acceptancePollInterval = 2 * time.Second }()
+
+	object := func(gvk schema.GroupVersionKind, name string, status map[string]any) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(gvk)
+		u.SetNamespace("default")
+		u.SetName(name)
+		if status != nil {
+			u.Object["status"] = status
+		}
+		return u
+	}
+	parents := func(conditions ...any) map[string]any {
+		return map[string]any{"parents": []any{map[string]any{
+			"parentRef":  map[string]any{"name": "nginx"},
+			"conditions": conditions,
+		}}}
+	}
+	condition := func(conditionType, status, reason string) map[string]any {
+		return map[string]any{"type": conditionType, "status": status, "reason": reason}
+	}
+
+	cl := fake.NewClientBuilder().WithObjects(
+		object(gatewayGVK, "nginx", map[string]any{"conditions": []any{condition("Programmed", "True", "Programmed")}}),
+		object(gatewayGVK, "internal", map[string]any{"conditions": []any{condition("Programmed", "False", "AddressNotAssigned")}}),
+		object(httpRouteGVK, "web", parents(condition("Accepted", "True", "Accepted"), condition("ResolvedRefs", "True", "ResolvedRefs"))),
+		object(httpRouteGVK, "api", parents(condition("Accepted", "True", "Accepted"), condition("ResolvedRefs", "False", "BackendNotFound"))),
+		object(tlsRouteGVK, "db", nil),
+	).WithInterceptorFuncs(interceptor.Funcs{
+		Patch: func(context.Context, client.WithWatch, client.Object, client.Patch, ...client.PatchOption) error {
+			return nil
+		},
+	}).Build()
+
+	refs := []ObjectRef{
+		{Kind: "Gateway", Namespace: "default", Name: "nginx"},
+		{Kind: "Gateway", Namespace: "default", Name: "internal"},
+		{Kind: "HTTPRoute", Namespace: "default", Name: "web"},
+		{Kind: "HTTPRoute", Namespace: "default", Name: "api"},
+		{Kind: "TLSRoute", Namespace: "default", Name: "db"},
+		{Kind: "TCPRoute", Namespace: "default", Name: "missing"},
+		{Kind: "ReferenceGrant", Namespace: "default", Name: "ignored"},
+	}
+	rejected, err := WaitForAcceptance(context.Background(), cl, refs, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []RejectedResource{
+		{Ref: refs[1], Reason: "Programmed condition is False: AddressNotAssigned"},
+		{Ref: refs[3], Reason: "parent nginx: ResolvedRefs condition is False: BackendNotFound"},
+		{Ref: refs[4], Reason: "no parent reported its status yet"},
+		{Ref: refs[5], Reason: "it doesn't exist"},
+	}
+	if diff := cmp.Diff(expected, rejected); diff != "" {
+		t.Errorf("Unexpected rejected resources, diff (-want +got): %s", diff)
+	}
+
+	gateway := *object(gatewayGVK, "internal", nil)
+	_, err = Apply(context.Background(), ApplyOptions{Client: cl, Objects: []unstructured.Unstructured{gateway}, Wait: 10 * time.Millisecond})
+	var r RejectedResource
+	if !errors.As(err, &r) || r.Ref != refs[1] {
+		t.Errorf("Expected Gateway default/internal to be rejected, got %v", err)
+	}
+	if _, err := Apply(context.Background(), ApplyOptions{Client: cl, Objects: []unstructured.Unstructured{gateway}, Wait: 10 * time.Millisecond, DryRun: true}); err != nil {
+		t.Errorf("Expected a dry run not to wait, got %v", err)
+	}
+}
@@ -17,36 +17,110 @@ package i2gw
 
 import (
 	"fmt"
-	"regexp"
+	"maps"
+	"math/big"
+	"reflect"
+	"runtime"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime/schema"
-	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
-)
-
-var (
-	gatewayGVK = schema.GroupVersionKind{
-		Group:   "gateway.networking.k8s.io",
-		Version: "v1beta1",
-		Kind:    "Gateway",
-	}
-
-	httpRouteGVK = schema.GroupVersionKind{
-		Group:   "gateway.networking.k8s.io",
-		Version: "v1beta1",
-		Kind:    "HTTPRoute",
-	}
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
 type ruleGroupKey string
 
+// unsupportedAnnotationsMessage is part of the notification listing the
+// unsupported annotations of an Ingress, which findings report one by one.
+const unsupportedAnnotationsMessage = "uses annotations that are not converted"
+
 type ingressAggregator struct {
-	ruleGroups      map[ruleGroupKey]*ingressRuleGroup
-	defaultBackends []ingressDefaultBackend
+	providers           []Provider
+	workers             int
+	listenerStrategy    ListenerStrategy
+	listenerPorts       ListenerPorts
+	classListenerPorts  map[string]ListenerPorts
+	httpsOnly           HTTPSOnlyMode
+	attachToListeners   bool
+	normalizeWeights    int32
+	pathCollisions      PathCollisionPolicy
+	includeMigrated     bool
+	disabledFeatures    []conversionFeature
+	gatewayNamespace    string
+	routeNaming         RouteNaming
+	routeStrategy       RouteStrategy
+	externalDNSMode     ExternalDNSMode
+	progressiveDelivery ProgressiveDeliveryMode
+	hostHeaders         map[string]HostHeaderMode
+	tlsModes            map[string]gatewayv1.TLSModeType
+	defaultCertificate  types.NamespacedName
+	ruleGroups          map[ruleGroupKey]*ingressRuleGroup
+	defaultBackends     []ingressDefaultBackend
+	streams             []ingressStream
+	experimental        map[string]bool
+	ingressClasses      map[string]networkingv1.IngressClass
+	defaultIngressClass string
+	notifications       []Notification
+	unsupported         []UnsupportedAnnotation
+	disabled            []DisabledAnnotation
+	policies            []ingressPolicy
+	// servicePorts are the first ports of the Services of the input.
+	servicePorts map[types.NamespacedName]int32
+	// serviceSpecPorts are the ports of the Services of the input.
+	serviceSpecPorts map[types.NamespacedName][]corev1.ServicePort
+	// annotations are the annotations the providers converted, with their
+	// values, by Ingress.
+	annotations map[types.NamespacedName][]string
+	// certManager are the cert-manager annotations of the Ingresses with
+	// TLS entries.
+	certManager map[types.NamespacedName]map[string]string
+	// externalDNS are the external-dns annotations of the Ingresses,
+	// including the targets set with ExternalDNSKeepTargets.
+	externalDNS map[types.NamespacedName]map[string]string
+	// deliveryControllers are the Rollouts and Canaries of the input, by
+	// the Ingresses they route the traffic of.
+	deliveryControllers map[types.NamespacedName]deliveryController
+	// managedIngresses are the Ingresses managed by a progressive delivery
+	// controller.
+	managedIngresses map[types.NamespacedName]deliveryController
+	// canaries are the canary settings of the canary Ingresses.
+	canaries map[types.NamespacedName]*ir.Canary
+	// routeNames are the HTTPRoute names set by the annotations of the
+	// Ingresses.
+	routeNames map[types.NamespacedName]ingressRouteName
+	// backendKinds are the resource backend kinds the target
+	// implementation routes to, nil without a target implementation.
+	backendKinds backendKinds
+	// backendTranslations are the backend kind translations of the
+	// providers.
+	backendTranslations backendKindTranslations
+	// serviceImports are the kinds of the ServiceImports of the input.
+	serviceImports map[types.NamespacedName]backendKind
+	// externalNames are the external hosts of the ExternalName Services of
+	// the input.
+	externalNames map[types.NamespacedName]string
+	// externalNameBackends routes the backends of ExternalName Services to
+	// the externalNameKind backends of the target implementation.
+	externalNameBackends bool
+	externalNameKind     backendKind
+	// targetAnnotations are the annotations of Ingresses the target
+	// implementation converts.
+	targetAnnotations []string
+}
+
+type ingressPolicy struct {
+	ingress types.NamespacedName
+	policy  ir.Policy
 }
 
 type pathMatchKey string
@@ -54,366 +128,1451 @@ type pathMatchKey string
 type ingressRuleGroup struct {
 	namespace    string
 	ingressClass string
-	host         string
-	tls          []networkingv1.IngressTLS
-	rules        []ingressRule
+	// gateway is the name of the Gateway of the rule group, the group of
+	// its Ingresses or their class.
+	gateway string
+	host    string
+	tls     []networkingv1.IngressTLS
+	rules   []ingressRule
 }
 
 type ingressRule struct {
-	rule  networkingv1.IngressRule
-	extra *extra
+	ingressName string
+	rule        networkingv1.IngressRule
+	features    *ir.IngressFeatures
+	// defaultBackend is the default backend of the Ingress, for the rules
+	// of a host.
+	defaultBackend *networkingv1.IngressBackend
 }
 
 type ingressDefaultBackend struct {
 	name         string
 	namespace    string
 	ingressClass string
+	gateway      string
 	backend      networkingv1.IngressBackend
+	features     *ir.IngressFeatures
 }
 
 type ingressPath struct {
-	path  networkingv1.HTTPIngressPath
-	extra *extra
-}
-
-type extra struct {
-	canary *canary
+	ingressName string
+	path        networkingv1.HTTPIngressPath
+	features    *ir.IngressFeatures
+	// canaryMatch is the header match of the canary rule the path belongs
+	// to, if any.
+	canaryMatch *gatewayv1.HTTPHeaderMatch
+	// rewrite is the path rewrite of the requests to the path, if any.
+	rewrite *gatewayv1.HTTPPathModifier
+	// regex is the regular expression the path is matched as, if any.
+	regex *ir.RegexPath
+	// requestMatches restrict the requests to the path beyond the path,
+	// any of them matching.
+	requestMatches []ir.RequestMatch
+	// override are the features of the path overriding the ones of the
+	// Ingress, if any.
+	override *ir.PathOverride
 }
 
-type canary struct {
-	enable           bool
-	headerKey        string
-	headerValue      string
-	headerRegexMatch bool
-	weight           int
-	weightTotal      int
+func newIngressAggregator(providers []Provider) *ingressAggregator {
+	return &ingressAggregator{
+		providers:           providers,
+		backendTranslations: providerBackendKinds(providers),
+		workers:             runtime.GOMAXPROCS(0),
+		ruleGroups:          map[ruleGroupKey]*ingressRuleGroup{},
+	}
 }
 
 func (a *ingressAggregator) addIngress(ingress networkingv1.Ingress) {
+	overrides, overrideNotes := parseOverrides(ingress)
+	a.notifications = append(a.notifications, overrideNotes...)
+	if overrides.skip {
+		a.notifications = append(a.notifications, notifications.NewInfo("Ingress %s/%s is skipped as requested by its %s annotation", ingress.Namespace, ingress.Name, SkipAnnotation))
+		return
+	}
+	if migrated, ok := ingress.Annotations[MigratedToAnnotation]; ok && !a.includeMigrated {
+		a.notifications = append(a.notifications, notifications.NewInfo("Ingress %s/%s is skipped since it is already migrated to %s", ingress.Namespace, ingress.Name, migrated))
+		return
+	}
 	var ingressClass string
 	if ingress.Spec.IngressClassName != nil && *ingress.Spec.IngressClassName != "" {
 		ingressClass = *ingress.Spec.IngressClassName
 	} else if _, ok := ingress.Annotations[networkingv1beta1.AnnotationIngressClass]; ok {
 		ingressClass = ingress.Annotations[networkingv1beta1.AnnotationIngressClass]
+	} else if a.defaultIngressClass != "" {
+		ingressClass = a.defaultIngressClass
 	} else {
 		ingressClass = ingress.Name
 	}
-	e := getExtra(ingress)
+	features := a.parseIngressFeatures(ingressClass, ingress)
+	a.resolveErrorBackend(ingress, features)
+	a.addCertManagerAnnotations(ingress)
+	a.addExternalDNSAnnotations(ingress)
+	a.markDeliveryManaged(ingress, features)
+	if features.Canary != nil {
+		if a.canaries == nil {
+			a.canaries = map[types.NamespacedName]*ir.Canary{}
+		}
+		a.canaries[types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}] = features.Canary
+	}
+	gateway := ingressClass
+	if features.Group != "" {
+		gateway = features.Group
+	}
+	if overrides.gatewayName != "" {
+		gateway = overrides.gatewayName
+	}
+	if overrides.routeName != "" {
+		hosts := map[string]struct{}{}
+		for _, rule := range ingress.Spec.Rules {
+			hosts[rule.Host] = struct{}{}
+		}
+		if a.routeNames == nil {
+			a.routeNames = map[types.NamespacedName]ingressRouteName{}
+		}
+		a.routeNames[types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}] = ingressRouteName{name: overrides.routeName, hosts: len(hosts)}
+	}
+	ingress, features, streamed := a.addTLSModes(ingress, ingressClass, gateway, features, overrides.tlsMode)
+	if streamed {
+		return
+	}
+	a.checkBackendProtocol(ingress, features)
 	for _, rule := range ingress.Spec.Rules {
-		a.addIngressRule(ingress.Namespace, ingressClass, rule, ingress.Spec, e)
+		if rule.HTTP == nil {
+			a.notifications = append(a.notifications, notifications.NewWarning("Ingress %s/%s has a rule for host %q without HTTP paths, only a Gateway listener will be generated for it", ingress.Namespace, ingress.Name, rule.Host))
+		}
+		a.addIngressRule(ingress.Name, ingress.Namespace, ingressClass, gateway, rule, ingress.Spec, features)
 	}
 	if ingress.Spec.DefaultBackend != nil {
 		a.defaultBackends = append(a.defaultBackends, ingressDefaultBackend{
 			name:         ingress.Name,
 			namespace:    ingress.Namespace,
 			ingressClass: ingressClass,
+			gateway:      gateway,
 			backend:      *ingress.Spec.DefaultBackend,
+			features:     features,
+		})
+	}
+}
+
+// addServices records the ports of the Services of the input, the hosts of
+// ExternalName Services and the ServiceImports of multi-cluster Services.
+func (a *ingressAggregator) addServices(objects []unstructured.Unstructured) {
+	for _, obj := range objects {
+		if kind := (backendKind{group: obj.GroupVersionKind().Group, kind: obj.GetKind()}); slices.Contains(serviceImportBackendKinds, kind) {
+			if a.serviceImports == nil {
+				a.serviceImports = map[types.NamespacedName]backendKind{}
+			}
+			a.serviceImports[types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}] = kind
+			continue
+		}
+		if obj.GetAPIVersion() != "v1" || obj.GetKind() != "Service" {
+			continue
+		}
+		service := corev1.Service{}
+		if err := k8sruntime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &service); err != nil {
+			continue
+		}
+		if service.Spec.Type == corev1.ServiceTypeExternalName {
+			if a.externalNames == nil {
+				a.externalNames = map[types.NamespacedName]string{}
+			}
+			a.externalNames[types.NamespacedName{Namespace: service.Namespace, Name: service.Name}] = service.Spec.ExternalName
+		}
+		if len(service.Spec.Ports) == 0 {
+			continue
+		}
+		if a.servicePorts == nil {
+			a.servicePorts = map[types.NamespacedName]int32{}
+			a.serviceSpecPorts = map[types.NamespacedName][]corev1.ServicePort{}
+		}
+		a.servicePorts[types.NamespacedName{Namespace: service.Namespace, Name: service.Name}] = service.Spec.Ports[0].Port
+		a.serviceSpecPorts[types.NamespacedName{Namespace: service.Namespace, Name: service.Name}] = service.Spec.Ports
+	}
+}
+
+// resolveErrorBackend sets the port of the error backend of the Ingress to
+// the first port of its Service, as ingress-nginx does.
+func (a *ingressAggregator) resolveErrorBackend(ingress networkingv1.Ingress, features *ir.IngressFeatures) {
+	if features.Policy == nil || features.Policy.CustomErrors == nil || features.Policy.CustomErrors.Backend == nil {
+		return
+	}
+	backend := features.Policy.CustomErrors.Backend
+	port, ok := a.servicePorts[types.NamespacedName{Namespace: ingress.Namespace, Name: backend.Name}]
+	if !ok {
+		a.notifications = append(a.notifications, notifications.NewWarning("Service %s/%s, the default backend of Ingress %s/%s, is not part of the input, so no route to it is generated for the requests no path matches", ingress.Namespace, backend.Name, ingress.Namespace, ingress.Name))
+		return
+	}
+	backend.Port.Number = port
+}
+
+func (a *ingressAggregator) readControllerConfig(objects []unstructured.Unstructured) {
+	for _, p := range a.providers {
+		if reader, ok := p.(ControllerConfigReader); ok {
+			a.notifications = append(a.notifications, reader.ReadControllerConfig(objects)...)
+		}
+	}
+}
+
+// parseIngressFeatures runs the Ingress through the providers serving its
+// class. When several providers apply, the first one to set a feature wins.
+func (a *ingressAggregator) parseIngressFeatures(ingressClass string, ingress networkingv1.Ingress) *ir.IngressFeatures {
+	features, notes := a.parseProviderFeatures(ingressClass, ingress)
+	a.notifications = append(a.notifications, notes...)
+	a.defaultHostHeader(ingressClass, features)
+	if unhandled := a.unhandledAnnotations(ingress, features); len(unhandled) > 0 {
+		features.UnsupportedAnnotations = append(features.UnsupportedAnnotations, unhandled...)
+		sort.Strings(features.UnsupportedAnnotations)
+	}
+	a.disableFeatures(ingressClass, ingress, features)
+	for _, annotation := range features.ConvertedAnnotations {
+		if a.annotations == nil {
+			a.annotations = map[types.NamespacedName][]string{}
+		}
+		source := types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}
+		a.annotations[source] = append(a.annotations[source], annotation+"="+ingress.Annotations[annotation])
+	}
+	if !features.Policy.IsEmpty() {
+		a.policies = append(a.policies, ingressPolicy{
+			ingress: types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name},
+			policy:  *features.Policy,
 		})
 	}
+	if len(features.UnsupportedAnnotations) > 0 {
+		a.notifications = append(a.notifications, notifications.NewWarning("Ingress %s/%s "+unsupportedAnnotationsMessage+": %s", ingress.Namespace, ingress.Name, strings.Join(features.UnsupportedAnnotations, ", ")))
+		for _, annotation := range features.UnsupportedAnnotations {
+			a.unsupported = append(a.unsupported, UnsupportedAnnotation{
+				Ingress:    types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name},
+				Annotation: annotation,
+			})
+		}
+	}
+	return features
+}
+
+// parseProviderFeatures merges the features the providers of the
+// IngressClass extract from the Ingress, the first provider setting a
+// feature taking precedence.
+func (a *ingressAggregator) parseProviderFeatures(ingressClass string, ingress networkingv1.Ingress) (*ir.IngressFeatures, []Notification) {
+	features := &ir.IngressFeatures{}
+	var notes []Notification
+	for _, p := range a.providersFor(ingressClass) {
+		f, providerNotes := p.ParseIngress(ingress)
+		notes = append(notes, providerNotes...)
+		if features.Canary == nil {
+			features.Canary = f.Canary
+		}
+		if features.Policy == nil {
+			features.Policy = f.Policy
+		}
+		if features.Rewrites == nil {
+			features.Rewrites = f.Rewrites
+		}
+		if features.HostHeader == nil {
+			features.HostHeader = f.HostHeader
+		}
+		if features.Headers == nil {
+			features.Headers = f.Headers
+		}
+		if features.PathOverrides == nil {
+			features.PathOverrides = f.PathOverrides
+		}
+		if features.RegexPaths == nil {
+			features.RegexPaths = f.RegexPaths
+		}
+		if features.Group == "" {
+			features.Group = f.Group
+		}
+		if features.ListenPorts == nil {
+			features.ListenPorts = f.ListenPorts
+		}
+		if features.Matches == nil {
+			features.Matches = f.Matches
+		}
+		if features.Stream == nil {
+			features.Stream = f.Stream
+		}
+		if features.BackendProtocol == nil {
+			features.BackendProtocol = f.BackendProtocol
+		}
+		features.UnsupportedAnnotations = append(features.UnsupportedAnnotations, f.UnsupportedAnnotations...)
+		features.ConvertedAnnotations = append(features.ConvertedAnnotations, f.ConvertedAnnotations...)
+	}
+	return features, notes
 }
 
-func (a *ingressAggregator) addIngressRule(namespace, ingressClass string, rule networkingv1.IngressRule, iSpec networkingv1.IngressSpec, e *extra) {
-	rgKey := ruleGroupKey(fmt.Sprintf("%s/%s/%s", namespace, ingressClass, rule.Host))
+func (a *ingressAggregator) addIngressRule(name, namespace, ingressClass, gateway string, rule networkingv1.IngressRule, iSpec networkingv1.IngressSpec, features *ir.IngressFeatures) {
+	rgKey := ruleGroupKey(namespace + "/" + gateway + "/" + rule.Host)
 	rg, ok := a.ruleGroups[rgKey]
 	if !ok {
 		rg = &ingressRuleGroup{
 			namespace:    namespace,
 			ingressClass: ingressClass,
+			gateway:      gateway,
 			host:         rule.Host,
 		}
 		a.ruleGroups[rgKey] = rg
 	}
-	if len(iSpec.TLS) > 0 {
-		rg.tls = append(rg.tls, iSpec.TLS...)
+	for _, tls := range iSpec.TLS {
+		if rule.Host == "" || tlsCoversHost(tls, rule.Host) {
+			rg.tls = append(rg.tls, tls)
+		}
+	}
+	r := ingressRule{ingressName: name, rule: rule, features: features}
+	// The default backend of hostname-less rules is the one of the
+	// catch-all listener.
+	if rule.Host != "" {
+		r.defaultBackend = iSpec.DefaultBackend
 	}
-	rg.rules = append(rg.rules, ingressRule{rule: rule, extra: e})
+	rg.rules = append(rg.rules, r)
 }
 
-func (a *ingressAggregator) toHTTPRoutesAndGateways() ([]gatewayv1beta1.HTTPRoute, []gatewayv1beta1.Gateway, []error) {
-	var httpRoutes []gatewayv1beta1.HTTPRoute
+// listenerRuleGroups returns the rule groups the listeners of a rule group
+// are built from. Hostname-less rule groups whose TLS entries list hosts get
+// a listener for every host, serving the certificates of its entries, and
+// the all-hosts HTTP listener still serving the other hosts, the other rule
+// groups a single listener.
+func (rg *ingressRuleGroup) listenerRuleGroups() []*ingressRuleGroup {
+	if rg.host != "" {
+		return []*ingressRuleGroup{rg}
+	}
+	var hosts []string
+	for _, tls := range rg.tls {
+		for _, h := range tls.Hosts {
+			if !slices.Contains(hosts, h) {
+				hosts = append(hosts, h)
+			}
+		}
+	}
+	if len(hosts) == 0 {
+		return []*ingressRuleGroup{rg}
+	}
+	groups := make([]*ingressRuleGroup, 0, len(hosts))
+	for _, h := range hosts {
+		group := *rg
+		group.host = h
+		group.tls = nil
+		for _, tls := range rg.tls {
+			if len(tls.Hosts) == 0 || slices.Contains(tls.Hosts, h) {
+				group.tls = append(group.tls, tls)
+			}
+		}
+		groups = append(groups, &group)
+	}
+	catchAll := *rg
+	catchAll.tls = nil
+	return append(groups, &catchAll)
+}
+
+// toHTTPRoutesAndGateways converts the aggregated Ingresses and emits them as
+// Gateway API resources.
+func (a *ingressAggregator) toHTTPRoutesAndGateways() ([]gatewayv1.HTTPRoute, []gatewayv1.Gateway, []error) {
+	result, errors := a.toIR()
+	httpRoutes, gateways := emitGatewayAPI(result)
+	return httpRoutes, gateways, errors
+}
+
+// toIR groups the aggregated Ingresses into one Gateway per namespace and
+// class, and one HTTPRoute per host.
+func (a *ingressAggregator) toIR() (ir.IR, []error) {
+	var result ir.IR
 	var errors []error
-	listenersByNamespacedGateway := map[string][]gatewayv1beta1.Listener{}
+	if a.routeStrategy == RoutePerIngress {
+		a.splitRuleGroupsByIngress()
+	}
+	a.notifications = append(a.notifications, a.unroutedServiceImportNotifications()...)
+	externalBackends, externalNameNotes := a.externalBackends()
+	result.ExternalBackends = externalBackends
+	a.notifications = append(a.notifications, externalNameNotes...)
+	gatewaysByKey := map[string]*ir.Gateway{}
+	// gatewayIngresses are the Ingresses of the Gateways, for them to be
+	// added once without scanning the ones of large Gateways.
+	gatewayIngresses := map[string]namespacedNameSet{}
+	var gwKeys []string
 
-	for _, rg := range a.ruleGroups {
-		listener := gatewayv1beta1.Listener{}
-		if rg.host != "" {
-			listener.Hostname = (*gatewayv1beta1.Hostname)(&rg.host)
-		} else if len(rg.tls) == 1 && len(rg.tls[0].Hosts) == 1 {
-			listener.Hostname = (*gatewayv1beta1.Hostname)(&rg.tls[0].Hosts[0])
+	// allowNamespace lets the routes of a namespace attach to a listener
+	// of a Gateway living in another namespace.
+	allowNamespace := func(gw *ir.Gateway, i int, namespace string) {
+		if namespace != gw.Namespace && !slices.Contains(gw.Listeners[i].AllowedNamespaces, namespace) {
+			gw.Listeners[i].AllowedNamespaces = append(gw.Listeners[i].AllowedNamespaces, namespace)
 		}
-		if len(rg.tls) > 0 {
-			listener.TLS = &gatewayv1beta1.GatewayTLSConfig{}
+	}
+
+	// gatewayFor returns the Gateway the routes of a namespace attach to,
+	// adding it if needed.
+	gatewayFor := func(namespace, gateway, ingressClass string) *ir.Gateway {
+		gwNamespace := a.parentNamespace(namespace)
+		gwKey := fmt.Sprintf("%s/%s", gwNamespace, gateway)
+		gw, ok := gatewaysByKey[gwKey]
+		if !ok {
+			ports := a.gatewayListenerPorts(ingressClass)
+			gw = &ir.Gateway{
+				Namespace:        gwNamespace,
+				Name:             gateway,
+				GatewayClassName: ingressClass,
+				HTTPPort:         ports.HTTP,
+				HTTPSPort:        ports.HTTPS,
+			}
+			gatewaysByKey[gwKey] = gw
+			gatewayIngresses[gwKey] = namespacedNameSet{}
+			gwKeys = append(gwKeys, gwKey)
 		}
-		for _, tls := range rg.tls {
-			listener.TLS.CertificateRefs = append(listener.TLS.CertificateRefs,
-				gatewayv1beta1.SecretObjectReference{Name: gatewayv1beta1.ObjectName(tls.SecretName)})
+		return gw
+	}
+
+	// addListener adds the listener of a rule group to its Gateway, unless
+	// it shares the listener of a wildcard certificate or of the same host
+	// in another namespace, and returns the listener of the Gateway serving
+	// the rule group and whether it is new or newly serves HTTPS.
+	addListener := func(namespace, gateway, ingressClass string, listener ir.Listener, wildcard string, ingressNames []string) (ir.Listener, bool) {
+		gw := gatewayFor(namespace, gateway, ingressClass)
+		gwKey := fmt.Sprintf("%s/%s", gw.Namespace, gw.Name)
+		for _, name := range ingressNames {
+			gw.Ingresses = gatewayIngresses[gwKey].add(gw.Ingresses, types.NamespacedName{Namespace: namespace, Name: name})
+		}
+		hostname := listener.Hostname
+		if wildcard != "" {
+			hostname = wildcard
+		}
+		switch i := listenerIndex(gw.Listeners, hostname); {
+		case i < 0:
+			if wildcard != "" {
+				listener.Name, listener.Hostname = nameFromHost(wildcard), wildcard
+			}
+		case sameListenerTLS(gw.Listeners[i], listener):
+			allowNamespace(gw, i, namespace)
+			gw.Listeners[i].ExtraPorts = mergeListenerPorts(gw.Listeners[i].ExtraPorts, listener.ExtraPorts)
+			return gw.Listeners[i], false
+		case wildcard != "":
+			a.notifications = append(a.notifications, notifications.NewWarning("Host %q in namespace %s can't share listener %s of Gateway %s with the other hosts of its TLS certificate, their TLS settings differ", listener.Hostname, namespace, gw.Listeners[i].Name, gwKey))
+		case len(gw.Listeners[i].CertificateRefs) == 0:
+			// Like Ingress controllers, serve the host over HTTPS if any
+			// of its Ingresses configures TLS.
+			listener.Name, listener.AllowedNamespaces = gw.Listeners[i].Name, gw.Listeners[i].AllowedNamespaces
+			listener.ExtraPorts = mergeListenerPorts(gw.Listeners[i].ExtraPorts, listener.ExtraPorts)
+			gw.Listeners[i] = listener
+			allowNamespace(gw, i, namespace)
+			return gw.Listeners[i], true
+		default:
+			a.notifications = append(a.notifications, notifications.NewWarning("Host %q in namespace %s shares listener %s of Gateway %s with Ingresses configuring other TLS settings, the ones of the listener are kept", listener.Hostname, namespace, gw.Listeners[i].Name, gwKey))
+			allowNamespace(gw, i, namespace)
+			gw.Listeners[i].ExtraPorts = mergeListenerPorts(gw.Listeners[i].ExtraPorts, listener.ExtraPorts)
+			return gw.Listeners[i], false
 		}
-		gwKey := fmt.Sprintf("%s/%s", rg.namespace, rg.ingressClass)
-		listenersByNamespacedGateway[gwKey] = append(listenersByNamespacedGateway[gwKey], listener)
-		httpRoute, errors := rg.toHTTPRoute()
-		httpRoutes = append(httpRoutes, httpRoute)
-		errors = append(errors, errors...)
+		gw.Listeners = append(gw.Listeners, listener)
+		allowNamespace(gw, len(gw.Listeners)-1, namespace)
+		return gw.Listeners[len(gw.Listeners)-1], true
 	}
 
-	for _, db := range a.defaultBackends {
-		httpRoute := gatewayv1beta1.HTTPRoute{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      fmt.Sprintf("%s-default-backend", db.name),
-				Namespace: db.namespace,
-			},
-			Spec: gatewayv1beta1.HTTPRouteSpec{
-				CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{
-					ParentRefs: []gatewayv1beta1.ParentReference{{
-						Name: gatewayv1beta1.ObjectName(db.ingressClass),
-					}},
-				},
-			},
-			Status: gatewayv1beta1.HTTPRouteStatus{
-				RouteStatus: gatewayv1beta1.RouteStatus{
-					Parents: []gatewayv1beta1.RouteParentStatus{},
-				},
-			},
+	// catchAllRoutes indexes the routes of the hostname-less rule groups in
+	// result.HTTPRoutes, by namespace and Gateway.
+	catchAllRoutes := map[string]int{}
+	rgKeys := a.sortedRuleGroupKeys()
+	httpRoutes, rgNotes, rgErrors := a.convertRuleGroups(rgKeys)
+	for i, rgKey := range rgKeys {
+		rg := a.ruleGroups[rgKey]
+		a.notifications = append(a.notifications, rgNotes[i]...)
+		if a.routeNaming == RouteNamingIngress || a.routeStrategy == RoutePerIngress {
+			name := rg.rules[0].ingressName + "-" + httpRoutes[i].Name
+			httpRoutes[i].Name = truncateName(name, name, maxObjectNameLength)
+		}
+		if name, ok := a.routeNameOverride(rg); ok {
+			httpRoutes[i].Name = name
+		}
+		var ingressNames []string
+		for _, rule := range rg.rules {
+			ingressNames = append(ingressNames, rule.ingressName)
+		}
+		gwNamespace := a.parentNamespace(rg.namespace)
+		gwKey := fmt.Sprintf("%s/%s", gwNamespace, rg.gateway)
+		if gwNamespace != rg.namespace {
+			httpRoutes[i].GatewayNamespace = gwNamespace
+		}
+
+		var listeners []ir.Listener
+		for _, lrg := range rg.listenerRuleGroups() {
+			listener := ir.Listener{Hostname: lrg.host}
+			listener.Name = nameFromHost(listener.Hostname)
+			if len(lrg.tls) > 0 {
+				listener.CertificateRefs = a.certificateRefs(lrg)
+				listener.TLSOptions = a.tlsOptions(lrg)
+				listener.ClientValidation = a.clientValidation(lrg)
+				listener.HTTP = a.httpMode(lrg)
+			}
+			listener.ExtraPorts = a.extraListenerPorts(lrg, listener)
+			var wildcard string
+			if a.listenerStrategy == ListenerPerCertificate && len(listener.CertificateRefs) == 1 {
+				wildcard = wildcardTLSHost(lrg)
+			}
+			listener, isNew := addListener(rg.namespace, rg.gateway, rg.ingressClass, listener, wildcard, ingressNames)
+			listeners = append(listeners, listener)
+			if isNew && len(listener.CertificateRefs) > 0 && listener.HTTP == ir.HTTPRedirect {
+				redirect := httpsRedirectRoute(rg.namespace, rg.gateway, listener)
+				redirect.GatewayNamespace = httpRoutes[i].GatewayNamespace
+				a.setSectionPorts(&redirect, gatewaysByKey[gwKey], listener)
+				result.HTTPRoutes = append(result.HTTPRoutes, redirect)
+			}
+		}
+
+		errors = append(errors, rgErrors[i]...)
+		if len(httpRoutes[i].Rules) == 0 {
+			continue
+		}
+		// Hostname-less routes only serve the requests no other listener
+		// accepts, like the default server of an Ingress controller.
+		listener := listeners[0]
+		if a.attachToListeners || rg.host == "" || len(listener.CertificateRefs) > 0 && listener.HTTP != ir.HTTPServe {
+			httpRoutes[i].SectionNames = nil
+			for _, l := range listeners {
+				httpRoutes[i].SectionNames = append(httpRoutes[i].SectionNames, routeSectionNames(l)...)
+			}
+			a.setSectionPorts(&httpRoutes[i], gatewaysByKey[gwKey], listeners...)
 		}
-		httpRoute.SetGroupVersionKind(httpRouteGVK)
+		if slices.ContainsFunc(listeners, func(l ir.Listener) bool { return l.Hostname == "" }) {
+			catchAllRoutes[fmt.Sprintf("%s/%s", rg.namespace, gwKey)] = len(result.HTTPRoutes)
+		}
+		result.HTTPRoutes = append(result.HTTPRoutes, httpRoutes[i])
+	}
 
-		backendRef, err := toBackendRef(db.backend)
+	// Requests no rule matches are sent to the default backend of the first
+	// Ingress setting one for the Gateway, through its catch-all listener.
+	defaultBackendSources := map[string]types.NamespacedName{}
+	for _, db := range a.defaultBackends {
+		gwNamespace := a.parentNamespace(db.namespace)
+		gwKey := fmt.Sprintf("%s/%s", gwNamespace, db.gateway)
+		source := types.NamespacedName{Namespace: db.namespace, Name: db.name}
+		if first, ok := defaultBackendSources[gwKey]; ok {
+			a.notifications = append(a.notifications, notifications.NewWarning("Ingresses %s and %s both set a default backend for Gateway %s, only the one of %s is converted", first, source, gwKey, first))
+			continue
+		}
+		defaultBackendSources[gwKey] = source
+		backendRef, err := a.backendResolver().toBackendRef(db.namespace, db.backend)
 		if err != nil {
-			errors = append(errors, err)
+			errors = append(errors, newIngressError(source, err))
+			continue
+		}
+		// The explicit "/" prefix match has the lowest precedence, so the
+		// rule only serves the requests no other rule matches.
+		rule := ir.HTTPRouteRule{
+			Matches:  []gatewayv1.HTTPRouteMatch{catchAllMatch()},
+			Filters:  toHTTPRouteFilters(db.features),
+			Timeouts: toHTTPRouteTimeouts(db.features),
+			Backends: []ir.Backend{{BackendRef: *backendRef, Source: source}},
+		}
+
+		if i, ok := catchAllRoutes[fmt.Sprintf("%s/%s", db.namespace, gwKey)]; ok {
+			route := &result.HTTPRoutes[i]
+			if slices.ContainsFunc(route.Rules, isCatchAllRule) {
+				a.notifications = append(a.notifications, notifications.NewInfo("The default backend of Ingress %s is not converted, HTTPRoute %s/%s already has a \"/\" path matching every request of Gateway %s", source, route.Namespace, route.Name, gwKey))
+				continue
+			}
+			route.Rules = append(route.Rules, rule)
+			if !db.features.Policy.IsEmpty() {
+				if route.Policies == nil {
+					route.Policies = map[types.NamespacedName]ir.Policy{}
+				}
+				route.Policies[source] = *db.features.Policy
+			}
+			continue
+		}
+
+		var listener ir.Listener
+		if gw, ok := gatewaysByKey[gwKey]; ok && listenerIndex(gw.Listeners, "") >= 0 {
+			allowNamespace(gw, listenerIndex(gw.Listeners, ""), db.namespace)
+			listener = gw.Listeners[listenerIndex(gw.Listeners, "")]
+			gw.Ingresses = gatewayIngresses[gwKey].add(gw.Ingresses, source)
 		} else {
-			httpRoute.Spec.Rules = append(httpRoute.Spec.Rules, gatewayv1beta1.HTTPRouteRule{
-				BackendRefs: []gatewayv1beta1.HTTPBackendRef{{BackendRef: *backendRef}},
-			})
+			listener, _ = addListener(db.namespace, db.gateway, db.ingressClass, ir.Listener{Name: nameFromHost("")}, "", []string{db.name})
+		}
+		httpRoute := ir.HTTPRoute{
+			Namespace:    db.namespace,
+			Name:         truncateName(fmt.Sprintf("%s-default-backend", db.name), db.name, maxObjectNameLength),
+			GatewayName:  db.gateway,
+			SectionNames: routeSectionNames(listener),
+			Rules:        []ir.HTTPRouteRule{rule},
+		}
+		if gwNamespace != db.namespace {
+			httpRoute.GatewayNamespace = gwNamespace
+		}
+		if !db.features.Policy.IsEmpty() {
+			httpRoute.Policies = map[types.NamespacedName]ir.Policy{source: *db.features.Policy}
+		}
+		a.setSectionPorts(&httpRoute, gatewaysByKey[gwKey], listener)
+		result.HTTPRoutes = append(result.HTTPRoutes, httpRoute)
+	}
+
+	errors = append(errors, a.addStreamRoutes(&result, gatewayFor, allowNamespace)...)
+
+	sort.Strings(gwKeys)
+	for _, gwKey := range gwKeys {
+		result.Gateways = append(result.Gateways, *gatewaysByKey[gwKey])
+	}
+
+	return result, errors
+}
+
+// catchAllMatch returns the match of every request.
+func catchAllMatch() gatewayv1.HTTPRouteMatch {
+	pathType := gatewayv1.PathMatchPathPrefix
+	value := "/"
+	return gatewayv1.HTTPRouteMatch{Path: &gatewayv1.HTTPPathMatch{Type: &pathType, Value: &value}}
+}
+
+// isCatchAllRule returns whether a rule matches every request.
+func isCatchAllRule(rule ir.HTTPRouteRule) bool {
+	if len(rule.Matches) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(rule.Matches, func(m gatewayv1.HTTPRouteMatch) bool {
+		return reflect.DeepEqual(m, catchAllMatch())
+	})
+}
+
+// parentNamespace returns the namespace of the Gateway of the Ingresses of a
+// namespace.
+func (a *ingressAggregator) parentNamespace(namespace string) string {
+	if a.gatewayNamespace != "" {
+		return a.gatewayNamespace
+	}
+	return namespace
+}
+
+// httpMode returns how the listener of a rule group handles plain HTTP
+// requests, as configured for every host or redirecting them when an
+// Ingress of the group forces it.
+func (a *ingressAggregator) httpMode(rg *ingressRuleGroup) ir.HTTPMode {
+	switch a.httpsOnly {
+	case HTTPSOnlyOmit:
+		return ir.HTTPOmit
+	case HTTPSOnlyRedirect:
+		return ir.HTTPRedirect
+	}
+	for _, rule := range rg.rules {
+		if rule.features != nil && rule.features.Canary == nil && rule.features.Policy != nil && rule.features.Policy.SSLRedirect {
+			return ir.HTTPRedirect
 		}
+	}
+	return ir.HTTPServe
+}
+
+// httpsRedirectRoute returns an HTTPRoute redirecting the plain HTTP
+// requests of a listener to HTTPS.
+func httpsRedirectRoute(namespace, gatewayName string, listener ir.Listener) ir.HTTPRoute {
+	scheme := "https"
+	statusCode := 301
+	return ir.HTTPRoute{
+		Namespace:    namespace,
+		Name:         truncateName(nameFromHost(listener.Hostname)+"-https-redirect", listener.Hostname, maxObjectNameLength),
+		GatewayName:  gatewayName,
+		SectionNames: []string{listener.SectionName("http")},
+		Hostname:     listener.Hostname,
+		Rules: []ir.HTTPRouteRule{{
+			Filters: []gatewayv1.HTTPRouteFilter{{
+				Type:            gatewayv1.HTTPRouteFilterRequestRedirect,
+				RequestRedirect: &gatewayv1.HTTPRequestRedirectFilter{Scheme: &scheme, StatusCode: &statusCode},
+			}},
+		}},
+	}
+}
+
+// gatewayListenerPorts returns the listener ports of the Gateways of an
+// IngressClass, falling back to the ports of every Gateway and then to the
+// well-known HTTP and HTTPS ports.
+func (a *ingressAggregator) gatewayListenerPorts(ingressClass string) ListenerPorts {
+	ports := a.classListenerPorts[ingressClass]
+	if ports.HTTP == 0 {
+		ports.HTTP = a.listenerPorts.HTTP
+	}
+	if ports.HTTPS == 0 {
+		ports.HTTPS = a.listenerPorts.HTTPS
+	}
+	if ports.HTTP == 0 {
+		ports.HTTP = defaultHTTPPort
+	}
+	if ports.HTTPS == 0 {
+		ports.HTTPS = defaultHTTPSPort
+	}
+	return ports
+}
+
+// namespacedNameSet is the set of the names of a slice, for distinct names
+// to be appended to it without scanning it.
+type namespacedNameSet map[types.NamespacedName]bool
 
-		httpRoutes = append(httpRoutes, httpRoute)
+// add appends name to names, unless it is in the set already.
+func (s namespacedNameSet) add(names []types.NamespacedName, name types.NamespacedName) []types.NamespacedName {
+	if s[name] {
+		return names
 	}
+	s[name] = true
+	return append(names, name)
+}
 
-	gatewaysByKey := map[string]*gatewayv1beta1.Gateway{}
-	for gwKey, listeners := range listenersByNamespacedGateway {
-		parts := strings.Split(gwKey, "/")
-		if len(parts) != 2 {
-			errors = append(errors, fmt.Errorf("Error generating Gateway listeners for key: %s", gwKey))
+func containsNamespacedName(names []types.NamespacedName, name types.NamespacedName) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// convertRuleGroups converts the rule groups with the given keys to
+// HTTPRoutes using a pool of a.workers goroutines. Rule groups are
+// independent of each other, and results are returned in the order of keys
+// so that the output doesn't depend on scheduling.
+func (a *ingressAggregator) convertRuleGroups(keys []ruleGroupKey) ([]ir.HTTPRoute, [][]Notification, [][]error) {
+	httpRoutes := make([]ir.HTTPRoute, len(keys))
+	notes := make([][]Notification, len(keys))
+	errors := make([][]error, len(keys))
+
+	workers := a.workers
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(keys) {
+		workers = len(keys)
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				httpRoutes[i], notes[i], errors[i] = a.ruleGroups[keys[i]].toHTTPRoute(a.normalizeWeights, a.pathCollisions, a.backendResolver())
+			}
+		}()
+	}
+	for i := range keys {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return httpRoutes, notes, errors
+}
+
+// certificateRefs returns the deduplicated TLS secrets of a rule group,
+// warning when different secrets are configured for the same hostname.
+func (a *ingressAggregator) certificateRefs(rg *ingressRuleGroup) []gatewayv1.SecretObjectReference {
+	var refs []gatewayv1.SecretObjectReference
+	var secretNames []string
+	seen := map[string]struct{}{}
+	for _, tls := range rg.tls {
+		secret := types.NamespacedName{Namespace: rg.namespace, Name: tls.SecretName}
+		if tls.SecretName == "" {
+			// Ingress controllers serve their default certificate for TLS
+			// entries without secretName.
+			if a.defaultCertificate.Name == "" {
+				a.notifications = append(a.notifications, notifications.NewWarning("TLS entry for host %q in namespace %s has no secretName and relies on the default certificate of the Ingress controller, set --default-certificate to reference it from the listener", rg.host, rg.namespace))
+				continue
+			}
+			secret = a.defaultCertificate
+		}
+		if _, ok := seen[secret.String()]; ok {
 			continue
 		}
-		gateway := gatewaysByKey[gwKey]
-		if gateway == nil {
-			gateway = &gatewayv1beta1.Gateway{
-				ObjectMeta: metav1.ObjectMeta{
-					Namespace: parts[0],
-					Name:      parts[1],
-				},
-				Spec: gatewayv1beta1.GatewaySpec{
-					GatewayClassName: gatewayv1beta1.ObjectName(parts[1]),
-				},
+		seen[secret.String()] = struct{}{}
+		secretNames = append(secretNames, secret.Name)
+		ref := gatewayv1.SecretObjectReference{Name: gatewayv1.ObjectName(secret.Name)}
+		if a.parentNamespace(rg.namespace) != secret.Namespace {
+			ns := gatewayv1.Namespace(secret.Namespace)
+			ref.Namespace = &ns
+		}
+		refs = append(refs, ref)
+	}
+	if len(refs) > 1 && rg.host != "" {
+		a.notifications = append(a.notifications, notifications.NewWarning("Conflicting TLS secrets %s configured for host %q in namespace %s, all of them will be referenced by the listener", strings.Join(secretNames, ", "), rg.host, rg.namespace))
+	}
+	return refs
+}
+
+// Keys of the listener TLS options. Gateway API leaves them to
+// implementations, so they are named after the ingress-nginx settings.
+const (
+	tlsCiphersOption             gatewayv1.AnnotationKey = "nginx.ingress.kubernetes.io/ssl-ciphers"
+	tlsPreferServerCiphersOption gatewayv1.AnnotationKey = "nginx.ingress.kubernetes.io/ssl-prefer-server-ciphers"
+	tlsProtocolsOption           gatewayv1.AnnotationKey = "nginx.ingress.kubernetes.io/ssl-protocols"
+)
+
+// tlsOptions returns the listener TLS options converted from the TLS
+// settings of the first Ingress of the rule group configuring some.
+func (a *ingressAggregator) tlsOptions(rg *ingressRuleGroup) map[gatewayv1.AnnotationKey]gatewayv1.AnnotationValue {
+	var options map[gatewayv1.AnnotationKey]gatewayv1.AnnotationValue
+	var source string
+	for _, rule := range rg.rules {
+		if rule.features == nil || rule.features.Canary != nil {
+			continue
+		}
+		ruleOptions := toTLSOptions(rule.features.Policy)
+		if len(ruleOptions) == 0 {
+			continue
+		}
+		if options == nil {
+			options, source = ruleOptions, rule.ingressName
+			continue
+		}
+		if !maps.Equal(ruleOptions, options) {
+			a.notifications = append(a.notifications, notifications.NewWarning("Ingress %s/%s configures different TLS settings than Ingress %s/%s for host %q, using the ones of %s/%s", rg.namespace, rule.ingressName, rg.namespace, source, rg.host, rg.namespace, source))
+		}
+	}
+	if options != nil {
+		a.notifications = append(a.notifications, notifications.NewInfo("TLS settings of host %q in namespace %s are converted to listener TLS options, which only some implementations honor", rg.host, rg.namespace))
+	}
+	return options
+}
+
+func toTLSOptions(policy *ir.Policy) map[gatewayv1.AnnotationKey]gatewayv1.AnnotationValue {
+	if policy == nil {
+		return nil
+	}
+	options := map[gatewayv1.AnnotationKey]gatewayv1.AnnotationValue{}
+	if len(policy.TLSCiphers) > 0 {
+		options[tlsCiphersOption] = gatewayv1.AnnotationValue(strings.Join(policy.TLSCiphers, ":"))
+	}
+	if policy.TLSPreferServerCiphers != nil {
+		options[tlsPreferServerCiphersOption] = gatewayv1.AnnotationValue(strconv.FormatBool(*policy.TLSPreferServerCiphers))
+	}
+	if len(policy.TLSProtocols) > 0 {
+		options[tlsProtocolsOption] = gatewayv1.AnnotationValue(strings.Join(policy.TLSProtocols, " "))
+	}
+	return options
+}
+
+// clientValidation returns the client certificate authentication of the
+// first Ingress of the rule group configuring one. Canary Ingresses are
+// ignored as they share the server of the Ingress they shadow.
+func (a *ingressAggregator) clientValidation(rg *ingressRuleGroup) *ir.ClientValidation {
+	var validation *ir.ClientValidation
+	var source string
+	for _, rule := range rg.rules {
+		if rule.features == nil || rule.features.Canary != nil || rule.features.Policy == nil || rule.features.Policy.ClientValidation == nil {
+			continue
+		}
+		if validation == nil {
+			validation, source = rule.features.Policy.ClientValidation, rule.ingressName
+			continue
+		}
+		if *rule.features.Policy.ClientValidation != *validation {
+			a.notifications = append(a.notifications, notifications.NewWarning("Ingress %s/%s configures a different client certificate authentication than Ingress %s/%s for host %q, using the one of %s/%s", rg.namespace, rule.ingressName, rg.namespace, source, rg.host, rg.namespace, source))
+		}
+	}
+	return validation
+}
+
+// wildcardTLSHost returns the wildcard host of the TLS certificate of a rule
+// group covering the host of the group, if any.
+func wildcardTLSHost(rg *ingressRuleGroup) string {
+	if rg.host == "" {
+		return ""
+	}
+	for _, tls := range rg.tls {
+		for _, h := range tls.Hosts {
+			if strings.HasPrefix(h, "*.") && tlsCoversHost(networkingv1.IngressTLS{Hosts: []string{h}}, rg.host) {
+				return h
 			}
-			gateway.SetGroupVersionKind(gatewayGVK)
-			gatewaysByKey[gwKey] = gateway
 		}
-		for _, listener := range listeners {
-			var listenerNamePrefix string
-			if listener.Hostname != nil && *listener.Hostname != "" {
-				listenerNamePrefix = fmt.Sprintf("%s-", nameFromHost(string(*listener.Hostname)))
+	}
+	return ""
+}
+
+// routeSectionNames returns the sections of a listener a route attaches to,
+// leaving out the HTTP section when the listener only serves HTTPS, followed
+// by the sections of its extra ports.
+func routeSectionNames(l ir.Listener) []string {
+	var sectionNames []string
+	switch {
+	case len(l.CertificateRefs) == 0:
+		sectionNames = []string{l.SectionName("http")}
+	case l.HTTP != ir.HTTPServe:
+		sectionNames = []string{l.SectionName("https")}
+	default:
+		sectionNames = []string{l.SectionName("http"), l.SectionName("https")}
+	}
+	for _, p := range l.ExtraPorts {
+		sectionNames = append(sectionNames, l.PortSectionName(p))
+	}
+	return sectionNames
+}
+
+// extraListenerPorts returns the listen ports of the Ingresses of a rule
+// group other than the HTTP and HTTPS ports of their Gateway. HTTPS ports
+// are only served by listeners with certificates.
+func (a *ingressAggregator) extraListenerPorts(rg *ingressRuleGroup, listener ir.Listener) []ir.ListenerPort {
+	gatewayPorts := a.gatewayListenerPorts(rg.ingressClass)
+	var ports []ir.ListenerPort
+	for _, rule := range rg.rules {
+		if rule.features == nil {
+			continue
+		}
+		for _, p := range rule.features.ListenPorts {
+			switch {
+			case p.Protocol == "http" && p.Port == gatewayPorts.HTTP, p.Protocol == "https" && p.Port == gatewayPorts.HTTPS:
+			case p.Protocol == "https" && len(listener.CertificateRefs) == 0:
+				a.notifications = append(a.notifications, notifications.NewWarning("Ingress %s/%s listens for HTTPS on port %d but host %q has no TLS certificate, the port is not converted", rg.namespace, rule.ingressName, p.Port, rg.host))
+			default:
+				ports = mergeListenerPorts(ports, []ir.ListenerPort{p})
 			}
+		}
+	}
+	return ports
+}
 
-			gateway.Spec.Listeners = append(gateway.Spec.Listeners, gatewayv1beta1.Listener{
-				Name:     gatewayv1beta1.SectionName(fmt.Sprintf("%shttp", listenerNamePrefix)),
-				Hostname: listener.Hostname,
-				Port:     80,
-				Protocol: gatewayv1beta1.HTTPProtocolType,
-			})
-			if listener.TLS != nil {
-				gateway.Spec.Listeners = append(gateway.Spec.Listeners, gatewayv1beta1.Listener{
-					Name:     gatewayv1beta1.SectionName(fmt.Sprintf("%shttps", listenerNamePrefix)),
-					Hostname: listener.Hostname,
-					Port:     443,
-					Protocol: gatewayv1beta1.HTTPSProtocolType,
-					TLS:      listener.TLS,
-				})
+func mergeListenerPorts(ports, other []ir.ListenerPort) []ir.ListenerPort {
+	for _, p := range other {
+		if !slices.Contains(ports, p) {
+			ports = append(ports, p)
+		}
+	}
+	return ports
+}
+
+// setSectionPorts sets the ports of the sections a route attaches to when
+// routes are attached to specific listeners.
+func (a *ingressAggregator) setSectionPorts(route *ir.HTTPRoute, gw *ir.Gateway, listeners ...ir.Listener) {
+	if !a.attachToListeners {
+		return
+	}
+	route.SectionPorts = map[string]int32{}
+	for _, sectionName := range route.SectionNames {
+		route.SectionPorts[sectionName] = gw.HTTPPort
+		for _, l := range listeners {
+			if sectionName == l.SectionName("https") {
+				route.SectionPorts[sectionName] = gw.HTTPSPort
+			}
+			for _, p := range l.ExtraPorts {
+				if sectionName == l.PortSectionName(p) {
+					route.SectionPorts[sectionName] = p.Port
+				}
 			}
 		}
 	}
+}
 
-	var gateways []gatewayv1beta1.Gateway
-	for _, gw := range gatewaysByKey {
-		gateways = append(gateways, *gw)
+func listenerIndex(listeners []ir.Listener, hostname string) int {
+	for i, l := range listeners {
+		if l.Hostname == hostname {
+			return i
+		}
 	}
+	return -1
+}
 
-	return httpRoutes, gateways, errors
+// sameListenerTLS reports whether two listeners serve the same certificates
+// with the same TLS settings, so that their hosts can share a listener.
+func sameListenerTLS(a, b ir.Listener) bool {
+	return reflect.DeepEqual(a.CertificateRefs, b.CertificateRefs) && maps.Equal(a.TLSOptions, b.TLSOptions) && reflect.DeepEqual(a.ClientValidation, b.ClientValidation) && a.HTTP == b.HTTP
+}
+
+func tlsCoversHost(tls networkingv1.IngressTLS, host string) bool {
+	if len(tls.Hosts) == 0 {
+		return true
+	}
+	for _, h := range tls.Hosts {
+		if h == host {
+			return true
+		}
+		if strings.HasPrefix(h, "*.") {
+			if i := strings.Index(host, "."); i > 0 && host[i:] == h[1:] {
+				return true
+			}
+		}
+	}
+	return false
 }
 
-func (rg *ingressRuleGroup) toHTTPRoute() (gatewayv1beta1.HTTPRoute, []error) {
-	pathsByMatchGroup := map[pathMatchKey][]ingressPath{}
+func (rg *ingressRuleGroup) toHTTPRoute(normalizeWeights int32, collisions PathCollisionPolicy, backends backendResolver) (ir.HTTPRoute, []Notification, []error) {
+	var pathCount int
+	for _, rule := range rg.rules {
+		if rule.rule.HTTP != nil {
+			pathCount += len(rule.rule.HTTP.Paths)
+		}
+	}
+	pathsByMatchGroup := make(map[pathMatchKey][]ingressPath, pathCount)
+	pmKeys := make([]pathMatchKey, 0, pathCount)
+	var notes []Notification
 	errors := []error{}
 
-	for _, ir := range rg.rules {
-		for _, path := range ir.rule.HTTP.Paths {
-			ip := ingressPath{path: path, extra: ir.extra}
-			pmKey := getPathMatchKey(ip)
-			pathsByMatchGroup[pmKey] = append(pathsByMatchGroup[pmKey], ip)
+	addPath := func(pmKey pathMatchKey, ip ingressPath) {
+		if _, ok := pathsByMatchGroup[pmKey]; !ok {
+			pmKeys = append(pmKeys, pmKey)
 		}
+		pathsByMatchGroup[pmKey] = append(pathsByMatchGroup[pmKey], ip)
 	}
 
-	httpRoute := gatewayv1beta1.HTTPRoute{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      nameFromHost(rg.host),
-			Namespace: rg.namespace,
-		},
-		Spec: gatewayv1beta1.HTTPRouteSpec{},
-		Status: gatewayv1beta1.HTTPRouteStatus{
-			RouteStatus: gatewayv1beta1.RouteStatus{
-				Parents: []gatewayv1beta1.RouteParentStatus{},
-			},
-		},
+	var canaryPaths []ingressPath
+	for _, rule := range rg.rules {
+		if rule.rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.rule.HTTP.Paths {
+			ip := newIngressPath(rule.ingressName, path, rule.features)
+			if ip.isCanary() {
+				canaryPaths = append(canaryPaths, ip)
+				continue
+			}
+			addPath(getPathMatchKey(ip), ip)
+		}
+	}
+	for _, pmKey := range pmKeys {
+		paths, collisionNotes, collisionErrors := rg.resolvePathCollision(pathsByMatchGroup[pmKey], collisions)
+		pathsByMatchGroup[pmKey] = paths
+		notes = append(notes, collisionNotes...)
+		errors = append(errors, collisionErrors...)
 	}
-	httpRoute.SetGroupVersionKind(httpRouteGVK)
 
-	if rg.ingressClass != "" {
-		httpRoute.Spec.ParentRefs = []gatewayv1beta1.ParentReference{{Name: gatewayv1beta1.ObjectName(rg.ingressClass)}}
+	// Canary Ingresses only take effect alongside a primary Ingress with the
+	// same host and path, so pair each canary path with its primary: the
+	// canary backend joins the primary rule as a weighted backend and each
+	// of its matches gets a dedicated rule, sending requests to either the
+	// canary or the primary backends. Rules are emitted in the order of the
+	// matches, which takes precedence between rules matching one header.
+	for _, ip := range canaryPaths {
+		primaryKey := getPrimaryPathMatchKey(ip)
+		primaryPaths, ok := pathsByMatchGroup[primaryKey]
+		if !ok {
+			errors = append(errors, ingressError{
+				ingress: types.NamespacedName{Namespace: rg.namespace, Name: ip.ingressName},
+				err:     fmt.Errorf("canary Ingress %s/%s has no primary Ingress for host %q and path %q", rg.namespace, ip.ingressName, rg.host, ip.path.Path),
+			})
+			continue
+		}
+		canary := ip.features.Canary
+		for i := range canary.Matches {
+			match := canary.Matches[i]
+			targets := []ingressPath{ip}
+			if match.Primary {
+				targets = nil
+				for _, primaryPath := range primaryPaths {
+					if !primaryPath.isCanary() {
+						targets = append(targets, primaryPath)
+					}
+				}
+			}
+			for _, target := range targets {
+				target.canaryMatch = &match.Header
+				addPath(getPathMatchKey(target), target)
+			}
+		}
+		if canary.Weighted || canary.Managed {
+			addPath(primaryKey, ip)
+		}
 	}
-	if rg.host != "" {
-		httpRoute.Spec.Hostnames = []gatewayv1beta1.Hostname{gatewayv1beta1.Hostname(rg.host)}
+
+	// Requests no path matches are sent to the default backend of the
+	// Ingress, when it sets one, with the error backend of its annotations
+	// taking precedence over its spec.defaultBackend, as a "/" prefix path
+	// which has the lowest precedence.
+	hasRootPath := func(root ingressPath) bool {
+		_, hasPrefixRoot := pathsByMatchGroup[getPathMatchKey(root)]
+		specificType := networkingv1.PathTypeImplementationSpecific
+		specificRoot := root
+		specificRoot.path.PathType = &specificType
+		_, hasSpecificRoot := pathsByMatchGroup[getPathMatchKey(specificRoot)]
+		return hasPrefixRoot || hasSpecificRoot
+	}
+	for _, defaultPath := range []func() (ingressPath, bool){rg.errorBackendPath, rg.defaultBackendPath} {
+		if root, ok := defaultPath(); ok && !hasRootPath(root) {
+			addPath(getPathMatchKey(root), root)
+		}
 	}
 
-	for _, paths := range pathsByMatchGroup {
-		match, err := toHTTPRouteMatch(paths[0])
+	httpRoute := ir.HTTPRoute{
+		Namespace:   rg.namespace,
+		Name:        nameFromHost(rg.host),
+		GatewayName: rg.gateway,
+		Hostname:    rg.host,
+		Rules:       make([]ir.HTTPRouteRule, 0, len(pmKeys)),
+	}
+
+	for _, rule := range rg.rules {
+		if rule.features == nil || rule.features.Policy.IsEmpty() {
+			continue
+		}
+		if httpRoute.Policies == nil {
+			httpRoute.Policies = map[types.NamespacedName]ir.Policy{}
+		}
+		httpRoute.Policies[types.NamespacedName{Namespace: rg.namespace, Name: rule.ingressName}] = *rule.features.Policy
+	}
+
+	for _, pmKey := range pmKeys {
+		paths := pathsByMatchGroup[pmKey]
+		matches, err := toHTTPRouteMatches(paths[0])
 		if err != nil {
-			errors = append(errors, err)
+			errors = append(errors, newIngressError(types.NamespacedName{Namespace: rg.namespace, Name: paths[0].ingressName}, err))
 			continue
 		}
-		hrRule := gatewayv1beta1.HTTPRouteRule{
-			Matches: []gatewayv1beta1.HTTPRouteMatch{*match},
+		features := pathFeatures(paths[0].features, paths[0].override)
+		hrRule := ir.HTTPRouteRule{
+			Matches:  matches,
+			Filters:  toHTTPRouteFilters(features),
+			Timeouts: toHTTPRouteTimeouts(features),
+		}
+		if paths[0].rewrite != nil {
+			hrRule.Filters = append(hrRule.Filters, toURLRewriteFilter(*paths[0].rewrite))
 		}
 
-		var numWeightedBackends, totalWeightSet int32
+		var canaries []*ir.Canary
 		for _, path := range paths {
-			backendRef, err := toBackendRef(path.path.Backend)
+			backendRef, err := backends.toBackendRef(rg.namespace, path.path.Backend)
 			if err != nil {
-				errors = append(errors, err)
+				errors = append(errors, newIngressError(types.NamespacedName{Namespace: rg.namespace, Name: path.ingressName}, err))
 				continue
 			}
-			if path.extra != nil && path.extra.canary != nil && path.extra.canary.weight != 0 {
-				weight := int32(path.extra.canary.weight)
-				backendRef.Weight = &weight
-				totalWeightSet += weight
-				numWeightedBackends++
+			var c *ir.Canary
+			if path.isCanary() && path.canaryMatch == nil && (path.features.Canary.Weighted || path.features.Canary.Managed) {
+				c = path.features.Canary
 			}
-			hrRule.BackendRefs = append(hrRule.BackendRefs, gatewayv1beta1.HTTPBackendRef{BackendRef: *backendRef})
-		}
-		if numWeightedBackends > 0 && numWeightedBackends < int32(len(hrRule.BackendRefs)) {
-			weightToSet := (int32(100) - totalWeightSet) / (int32(len(hrRule.BackendRefs)) - numWeightedBackends)
-			for i, br := range hrRule.BackendRefs {
-				if br.Weight == nil {
-					br.Weight = &weightToSet
-					hrRule.BackendRefs[i] = br
-				}
+			canaries = append(canaries, c)
+			hrRule.Backends = append(hrRule.Backends, ir.Backend{
+				BackendRef: *backendRef,
+				Source:     types.NamespacedName{Namespace: rg.namespace, Name: path.ingressName},
+			})
+		}
+		if normalizeWeights > 0 {
+			normalizeBackendWeights(hrRule.Backends, canaries, int64(normalizeWeights))
+		} else {
+			setBackendWeights(hrRule.Backends, canaries)
+		}
+		if paths[0].features != nil {
+			if err := setHostRewrite(&hrRule, rg.namespace, paths[0].features.HostHeader); err != nil {
+				errors = append(errors, newIngressError(types.NamespacedName{Namespace: rg.namespace, Name: paths[0].ingressName}, err))
 			}
 		}
-		httpRoute.Spec.Rules = append(httpRoute.Spec.Rules, hrRule)
+		httpRoute.Rules = append(httpRoute.Rules, hrRule)
 	}
 
-	return httpRoute, errors
+	return httpRoute, notes, errors
 }
 
-func getPathMatchKey(ip ingressPath) pathMatchKey {
-	var pathType string
-	if ip.path.PathType != nil {
-		pathType = string(*ip.path.PathType)
+// maxBackendWeight is the largest weight Gateway API accepts on a BackendRef.
+const maxBackendWeight = 1000000
+
+// setBackendWeights applies canary weights to backendRefs, scaling every
+// canary against its configured weight total. Canaries are given
+// weight/total of the traffic and the remainder is split across the
+// non-canary backends, with any indivisible remainder going to the first
+// ones so that weights always add up to the common total.
+func setBackendWeights(backendRefs []ir.Backend, canaries []*ir.Canary) {
+	total := int64(1)
+	for _, c := range canaries {
+		if c != nil {
+			total = lcm(total, int64(canaryWeightTotal(c)))
+		}
+	}
+	if total == 1 {
+		return
+	}
+	if total > maxBackendWeight {
+		total = 0
+		for _, c := range canaries {
+			if c != nil && int64(canaryWeightTotal(c)) > total {
+				total = int64(canaryWeightTotal(c))
+			}
+		}
+	}
+
+	var weighted, canaryWeight int64
+	for i, c := range canaries {
+		if c == nil {
+			continue
+		}
+		w := max(min(int64(c.Weight), int64(canaryWeightTotal(c))), 0)
+		w = w * total / int64(canaryWeightTotal(c))
+		weight := int32(w)
+		backendRefs[i].Weight = &weight
+		canaryWeight += w
+		weighted++
+	}
+
+	unweighted := int64(len(backendRefs)) - weighted
+	if unweighted == 0 {
+		return
 	}
-	var canaryHeaderKey string
-	if ip.extra != nil && ip.extra.canary != nil && ip.extra.canary.headerKey != "" {
-		canaryHeaderKey = ip.extra.canary.headerKey
+	remaining := total - canaryWeight
+	if remaining < 0 {
+		remaining = 0
+	}
+	share, extra := remaining/unweighted, remaining%unweighted
+	for i := range backendRefs {
+		if backendRefs[i].Weight != nil {
+			continue
+		}
+		weight := int32(share)
+		if extra > 0 {
+			weight++
+			extra--
+		}
+		backendRefs[i].Weight = &weight
 	}
-	return pathMatchKey(fmt.Sprintf("%s/%s/%s", pathType, ip.path.Path, canaryHeaderKey))
 }
 
-func toHTTPRouteMatch(ip ingressPath) (*gatewayv1beta1.HTTPRouteMatch, error) {
-	pmPrefix := gatewayv1beta1.PathMatchPathPrefix
-	pmExact := gatewayv1beta1.PathMatchExact
-	hmExact := gatewayv1beta1.HeaderMatchExact
-	hmRegex := gatewayv1beta1.HeaderMatchRegularExpression
+// normalizeBackendWeights applies canary weights to backendRefs like
+// setBackendWeights, but with weights adding up to total. The exact share of
+// every backend is rounded down and the weight left is given, one by one, to
+// the backends with the largest remainders, the first ones on ties. Canaries
+// adding up to more than all the traffic, or being the only backends, are
+// scaled together.
+func normalizeBackendWeights(backendRefs []ir.Backend, canaries []*ir.Canary, total int64) {
+	if !slices.ContainsFunc(canaries, func(c *ir.Canary) bool { return c != nil }) {
+		return
+	}
 
-	match := &gatewayv1beta1.HTTPRouteMatch{Path: &gatewayv1beta1.HTTPPathMatch{Value: &ip.path.Path}}
-	switch *ip.path.PathType {
-	case networkingv1.PathTypePrefix:
-		match.Path.Type = &pmPrefix
-	case networkingv1.PathTypeExact:
-		match.Path.Type = &pmExact
-	default:
-		return nil, fmt.Errorf("Unsupported path match type: %s", *ip.path.PathType)
+	shares := make([]*big.Rat, len(backendRefs))
+	canaryShare := new(big.Rat)
+	unweighted := int64(len(backendRefs))
+	for i, c := range canaries {
+		if c == nil {
+			continue
+		}
+		w := min(c.Weight, canaryWeightTotal(c))
+		shares[i] = big.NewRat(int64(max(w, 0)), int64(canaryWeightTotal(c)))
+		canaryShare.Add(canaryShare, shares[i])
+		unweighted--
+	}
+	one := big.NewRat(1, 1)
+	remaining := new(big.Rat)
+	if canaryShare.Cmp(one) > 0 || unweighted == 0 && canaryShare.Sign() > 0 {
+		for _, share := range shares {
+			if share != nil {
+				share.Quo(share, canaryShare)
+			}
+		}
+	} else {
+		remaining.Sub(one, canaryShare)
+	}
+	for i := range shares {
+		if shares[i] == nil {
+			shares[i] = new(big.Rat).Quo(remaining, big.NewRat(unweighted, 1))
+		}
 	}
 
-	if ip.extra != nil && ip.extra.canary != nil && ip.extra.canary.headerKey != "" {
-		headerMatch := gatewayv1beta1.HTTPHeaderMatch{
-			Name:  gatewayv1beta1.HTTPHeaderName(ip.extra.canary.headerKey),
-			Value: ip.extra.canary.headerValue,
-			Type:  &hmExact,
+	weights := make([]int64, len(shares))
+	remainders := make([]*big.Rat, len(shares))
+	left := total
+	for i, share := range shares {
+		exact := new(big.Rat).Mul(share, big.NewRat(total, 1))
+		weights[i] = new(big.Int).Quo(exact.Num(), exact.Denom()).Int64()
+		remainders[i] = exact.Sub(exact, big.NewRat(weights[i], 1))
+		left -= weights[i]
+	}
+	// Canaries of weight 0 without other backends get no traffic.
+	if canaryShare.Sign() > 0 || unweighted > 0 {
+		order := make([]int, len(shares))
+		for i := range order {
+			order[i] = i
 		}
-		if ip.extra.canary.headerRegexMatch {
-			headerMatch.Type = &hmRegex
+		sort.SliceStable(order, func(i, j int) bool { return remainders[order[i]].Cmp(remainders[order[j]]) > 0 })
+		for i := 0; left > 0; i = (i + 1) % len(order) {
+			weights[order[i]]++
+			left--
 		}
-		match.Headers = []gatewayv1beta1.HTTPHeaderMatch{headerMatch}
 	}
+	for i := range backendRefs {
+		weight := int32(weights[i])
+		backendRefs[i].Weight = &weight
+	}
+}
 
-	return match, nil
+func canaryWeightTotal(c *ir.Canary) int {
+	if c.WeightTotal <= 0 {
+		return 100
+	}
+	return c.WeightTotal
 }
 
-func toBackendRef(ib networkingv1.IngressBackend) (*gatewayv1beta1.BackendRef, error) {
-	if ib.Service != nil {
-		if ib.Service.Port.Name != "" {
-			return nil, fmt.Errorf("Named ports not supported: %s", ib.Service.Port.Name)
+func lcm(a, b int64) int64 {
+	x, y := a, b
+	for y != 0 {
+		x, y = y, x%y
+	}
+	return a / x * b
+}
+
+func (a *ingressAggregator) sortedRuleGroupKeys() []ruleGroupKey {
+	keys := make([]ruleGroupKey, 0, len(a.ruleGroups))
+	for key := range a.ruleGroups {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// newIngressPath returns the path of an Ingress rule, with its request
+// matches and the match of its rewrite applied.
+func newIngressPath(ingressName string, path networkingv1.HTTPIngressPath, features *ir.IngressFeatures) ingressPath {
+	ip := ingressPath{ingressName: ingressName, path: path, features: features}
+	if features == nil {
+		return ip
+	}
+	ip.requestMatches = features.Matches[ir.PathBackend{Path: path.Path}]
+	if path.Backend.Service != nil {
+		if matches, ok := features.Matches[ir.PathBackend{Path: path.Path, Service: path.Backend.Service.Name}]; ok {
+			ip.requestMatches = matches
 		}
-		return &gatewayv1beta1.BackendRef{
-			BackendObjectReference: gatewayv1beta1.BackendObjectReference{
-				Name: gatewayv1beta1.ObjectName(ib.Service.Name),
-				Port: (*gatewayv1beta1.PortNumber)(&ib.Service.Port.Number),
-			},
-		}, nil
 	}
-	return &gatewayv1beta1.BackendRef{
-		BackendObjectReference: gatewayv1beta1.BackendObjectReference{
-			Group: (*gatewayv1beta1.Group)(ib.Resource.APIGroup),
-			Kind:  (*gatewayv1beta1.Kind)(&ib.Resource.Kind),
-			Name:  gatewayv1beta1.ObjectName(ib.Resource.Name),
-		},
-	}, nil
+	if override, ok := features.PathOverrides[path.Path]; ok {
+		ip.override = &override
+	}
+	if regex, ok := features.RegexPaths[path.Path]; ok {
+		ip.regex = &regex
+	}
+	rewrite, ok := features.Rewrites[path.Path]
+	if !ok {
+		return ip
+	}
+	if rewrite.Prefix != "" {
+		pathType := networkingv1.PathTypePrefix
+		ip.path.Path = rewrite.Prefix
+		ip.path.PathType = &pathType
+		ip.regex = nil
+	}
+	ip.rewrite = &rewrite.Path
+	return ip
+}
+
+func (ip ingressPath) isCanary() bool {
+	return ip.features != nil && ip.features.Canary != nil
 }
 
-func nameFromHost(host string) string {
-	// replace all special chars with -
-	reg, _ := regexp.Compile("[^a-zA-Z0-9]+")
-	step1 := reg.ReplaceAllString(host, "-")
-	// remove all - at start of string
-	reg2, _ := regexp.Compile("^[^a-zA-Z0-9]+")
-	step2 := reg2.ReplaceAllString(step1, "")
-	// if nothing left, return "all-hosts"
-	if len(host) == 0 {
-		return "all-hosts"
+func getPrimaryPathMatchKey(ip ingressPath) pathMatchKey {
+	var pathType string
+	if ip.path.PathType != nil {
+		pathType = string(*ip.path.PathType)
 	}
-	return step2
+	return pathMatchKey(fmt.Sprintf("%s/%s/", pathType, ip.path.Path) + requestMatchesKey(ip.requestMatches))
 }
 
-func getExtra(ingress networkingv1.Ingress) *extra {
-	e := &extra{}
-	if c := ingress.Annotations["nginx.ingress.kubernetes.io/canary"]; c == "true" {
-		e.canary = &canary{enable: true}
-		if cHeader := ingress.Annotations["nginx.ingress.kubernetes.io/canary-by-header"]; cHeader != "" {
-			e.canary.headerKey = cHeader
-			e.canary.headerValue = "always"
+// errorBackendPath returns a "/" prefix path to the error backend of the
+// first Ingress of the rule group setting one.
+func (rg *ingressRuleGroup) errorBackendPath() (ingressPath, bool) {
+	for _, rule := range rg.rules {
+		if rule.features == nil || rule.features.Canary != nil || rule.features.Policy == nil {
+			continue
 		}
-		if cHeaderVal := ingress.Annotations["nginx.ingress.kubernetes.io/canary-by-header-value"]; cHeaderVal != "" {
-			e.canary.headerValue = cHeaderVal
+		customErrors := rule.features.Policy.CustomErrors
+		if customErrors == nil || customErrors.Backend == nil || customErrors.Backend.Port.Number == 0 {
+			continue
 		}
-		if cHeaderRegex := ingress.Annotations["nginx.ingress.kubernetes.io/canary-by-header-pattern"]; cHeaderRegex != "" {
-			e.canary.headerValue = cHeaderRegex
-			e.canary.headerRegexMatch = true
+		pathType := networkingv1.PathTypePrefix
+		return ingressPath{
+			ingressName: rule.ingressName,
+			path: networkingv1.HTTPIngressPath{
+				Path:     "/",
+				PathType: &pathType,
+				Backend:  networkingv1.IngressBackend{Service: customErrors.Backend},
+			},
+			features: rule.features,
+		}, true
+	}
+	return ingressPath{}, false
+}
+
+// defaultBackendPath returns a "/" prefix path to the spec.defaultBackend of
+// the first Ingress of the rule group setting one, which ingress-nginx sends
+// the requests to the host no path of the Ingresses matches to.
+func (rg *ingressRuleGroup) defaultBackendPath() (ingressPath, bool) {
+	for _, rule := range rg.rules {
+		if rule.defaultBackend == nil || rule.features != nil && rule.features.Canary != nil {
+			continue
+		}
+		pathType := networkingv1.PathTypePrefix
+		return ingressPath{
+			ingressName: rule.ingressName,
+			path: networkingv1.HTTPIngressPath{
+				Path:     "/",
+				PathType: &pathType,
+				Backend:  *rule.defaultBackend,
+			},
+			features: rule.features,
+		}, true
+	}
+	return ingressPath{}, false
+}
+
+func getPathMatchKey(ip ingressPath) pathMatchKey {
+	var pathType string
+	if ip.path.PathType != nil {
+		pathType = string(*ip.path.PathType)
+	}
+	var canaryMatchKey string
+	if m := ip.canaryMatch; m != nil {
+		canaryMatchKey = string(*m.Type) + "/" + string(m.Name) + "=" + m.Value
+	}
+	return pathMatchKey(pathType + "/" + ip.path.Path + "/" + canaryMatchKey + requestMatchesKey(ip.requestMatches))
+}
+
+// requestMatchesKey distinguishes the paths with request matches from the
+// same paths without.
+func requestMatchesKey(matches []ir.RequestMatch) string {
+	if len(matches) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, m := range matches {
+		b.WriteString("|")
+		if m.Method != nil {
+			b.WriteString(string(*m.Method))
 		}
-		if cHeaderWeight := ingress.Annotations["nginx.ingress.kubernetes.io/canary-weight"]; cHeaderWeight != "" {
-			e.canary.weight, _ = strconv.Atoi(cHeaderWeight)
-			e.canary.weightTotal = 100
+		for _, h := range m.Headers {
+			fmt.Fprintf(&b, "/h:%s=%s", h.Name, h.Value)
 		}
-		if cHeaderWeightTotal := ingress.Annotations["nginx.ingress.kubernetes.io/canary-weight-total"]; cHeaderWeightTotal != "" {
-			e.canary.weightTotal, _ = strconv.Atoi(cHeaderWeightTotal)
+		for _, q := range m.QueryParams {
+			fmt.Fprintf(&b, "/q:%s=%s", q.Name, q.Value)
 		}
 	}
-	return e
+	return b.String()
+}
+
+// toHTTPRouteMatches returns the matches of a path, one for each of its
+// request matches.
+func toHTTPRouteMatches(ip ingressPath) ([]gatewayv1.HTTPRouteMatch, error) {
+	match, err := toHTTPRouteMatch(ip)
+	if err != nil {
+		return nil, err
+	}
+	if len(ip.requestMatches) == 0 {
+		return []gatewayv1.HTTPRouteMatch{*match}, nil
+	}
+	var matches []gatewayv1.HTTPRouteMatch
+	for _, rm := range ip.requestMatches {
+		m := *match
+		m.Method = rm.Method
+		m.Headers = append(slices.Clone(match.Headers), rm.Headers...)
+		m.QueryParams = rm.QueryParams
+		matches = append(matches, m)
+	}
+	return matches, nil
+}
+
+func toHTTPRouteMatch(ip ingressPath) (*gatewayv1.HTTPRouteMatch, error) {
+	pmPrefix := gatewayv1.PathMatchPathPrefix
+	pmExact := gatewayv1.PathMatchExact
+
+	if ip.path.PathType == nil {
+		return nil, &UnsupportedPathTypeError{Path: ip.path.Path}
+	}
+
+	match := &gatewayv1.HTTPRouteMatch{Path: &gatewayv1.HTTPPathMatch{Value: &ip.path.Path}}
+	switch {
+	case ip.regex != nil && ip.regex.Error != "":
+		return nil, fmt.Errorf("path %q can't be translated to an RE2 regular expression: %s", ip.path.Path, ip.regex.Error)
+	case ip.regex != nil:
+		pmRegex := gatewayv1.PathMatchRegularExpression
+		pattern := ip.regex.Pattern
+		match.Path = &gatewayv1.HTTPPathMatch{Type: &pmRegex, Value: &pattern}
+	case *ip.path.PathType == networkingv1.PathTypePrefix:
+		match.Path.Type = &pmPrefix
+	case *ip.path.PathType == networkingv1.PathTypeExact:
+		match.Path.Type = &pmExact
+	default:
+		return nil, &UnsupportedPathTypeError{Path: ip.path.Path, PathType: string(*ip.path.PathType)}
+	}
+
+	if ip.canaryMatch != nil {
+		match.Headers = []gatewayv1.HTTPHeaderMatch{*ip.canaryMatch}
+	}
+
+	return match, nil
 }
@@ -16,33 +16,37 @@ limitations under the License.
 package i2gw
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
 func Test_ingresses2GatewaysAndHttpRoutes(t *testing.T) {
 	iPrefix := networkingv1.PathTypePrefix
 	iExact := networkingv1.PathTypeExact
-	gPathPrefix := gatewayv1beta1.PathMatchPathPrefix
-	gExact := gatewayv1beta1.PathMatchExact
+	gPathPrefix := gatewayv1.PathMatchPathPrefix
+	gExact := gatewayv1.PathMatchExact
 
 	testCases := []struct {
 		name             string
 		ingresses        []networkingv1.Ingress
-		expectGateways   []gatewayv1beta1.Gateway
-		expectHttpRoutes []gatewayv1beta1.HTTPRoute
+		expectGateways   []gatewayv1.Gateway
+		expectHttpRoutes []gatewayv1.HTTPRoute
 		expectErrors     []error
 	}{{
 		name:             "empty",
 		ingresses:        []networkingv1.Ingress{},
-		expectGateways:   []gatewayv1beta1.Gateway{},
-		expectHttpRoutes: []gatewayv1beta1.HTTPRoute{},
+		expectGateways:   []gatewayv1.Gateway{},
+		expectHttpRoutes: []gatewayv1.HTTPRoute{},
 		expectErrors:     []error{},
 	}, {
 		name: "simple ingress",
@@ -70,37 +74,37 @@ func Test_ingresses2GatewaysAndHttpRoutes(t *testing.T) {
 				}},
 			},
 		}},
-		expectGateways: []gatewayv1beta1.Gateway{{
+		expectGateways: []gatewayv1.Gateway{{
 			ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test"},
-			Spec: gatewayv1beta1.GatewaySpec{
+			Spec: gatewayv1.GatewaySpec{
 				GatewayClassName: "example",
-				Listeners: []gatewayv1beta1.Listener{{
+				Listeners: []gatewayv1.Listener{{
 					Name:     "example-com-http",
 					Port:     80,
-					Protocol: gatewayv1beta1.HTTPProtocolType,
+					Protocol: gatewayv1.HTTPProtocolType,
 					Hostname: gatewayHostnamePtr("example.com"),
 				}},
 			},
 		}},
-		expectHttpRoutes: []gatewayv1beta1.HTTPRoute{{
+		expectHttpRoutes: []gatewayv1.HTTPRoute{{
 			ObjectMeta: metav1.ObjectMeta{Name: "example-com", Namespace: "test"},
-			Spec: gatewayv1beta1.HTTPRouteSpec{
-				CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{
-					ParentRefs: []gatewayv1beta1.ParentReference{{
+			Spec: gatewayv1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1.CommonRouteSpec{
+					ParentRefs: []gatewayv1.ParentReference{{
 						Name: "example",
 					}},
 				},
-				Hostnames: []gatewayv1beta1.Hostname{"example.com"},
-				Rules: []gatewayv1beta1.HTTPRouteRule{{
-					Matches: []gatewayv1beta1.HTTPRouteMatch{{
-						Path: &gatewayv1beta1.HTTPPathMatch{
+				Hostnames: []gatewayv1.Hostname{"example.com"},
+				Rules: []gatewayv1.HTTPRouteRule{{
+					Matches: []gatewayv1.HTTPRouteMatch{{
+						Path: &gatewayv1.HTTPPathMatch{
 							Type:  &gPathPrefix,
 							Value: stringPtr("/foo"),
 						},
 					}},
-					BackendRefs: []gatewayv1beta1.HTTPBackendRef{{
-						BackendRef: gatewayv1beta1.BackendRef{
-							BackendObjectReference: gatewayv1beta1.BackendObjectReference{
+					BackendRefs: []gatewayv1.HTTPBackendRef{{
+						BackendRef: gatewayv1.BackendRef{
+							BackendObjectReference: gatewayv1.BackendObjectReference{
 								Name: "example",
 								Port: portNumberPtr(3000),
 							},
@@ -140,47 +144,47 @@ func Test_ingresses2GatewaysAndHttpRoutes(t *testing.T) {
 				}},
 			},
 		}},
-		expectGateways: []gatewayv1beta1.Gateway{{
+		expectGateways: []gatewayv1.Gateway{{
 			ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test"},
-			Spec: gatewayv1beta1.GatewaySpec{
+			Spec: gatewayv1.GatewaySpec{
 				GatewayClassName: "example",
-				Listeners: []gatewayv1beta1.Listener{{
+				Listeners: []gatewayv1.Listener{{
 					Name:     "example-com-http",
 					Port:     80,
-					Protocol: gatewayv1beta1.HTTPProtocolType,
+					Protocol: gatewayv1.HTTPProtocolType,
 					Hostname: gatewayHostnamePtr("example.com"),
 				}, {
 					Name:     "example-com-https",
 					Port:     443,
-					Protocol: gatewayv1beta1.HTTPSProtocolType,
+					Protocol: gatewayv1.HTTPSProtocolType,
 					Hostname: gatewayHostnamePtr("example.com"),
-					TLS: &gatewayv1beta1.GatewayTLSConfig{
-						CertificateRefs: []gatewayv1beta1.SecretObjectReference{{
+					TLS: &gatewayv1.GatewayTLSConfig{
+						CertificateRefs: []gatewayv1.SecretObjectReference{{
 							Name: "example-cert",
 						}},
 					},
 				}},
 			},
 		}},
-		expectHttpRoutes: []gatewayv1beta1.HTTPRoute{{
+		expectHttpRoutes: []gatewayv1.HTTPRoute{{
 			ObjectMeta: metav1.ObjectMeta{Name: "example-com", Namespace: "test"},
-			Spec: gatewayv1beta1.HTTPRouteSpec{
-				CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{
-					ParentRefs: []gatewayv1beta1.ParentReference{{
+			Spec: gatewayv1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1.CommonRouteSpec{
+					ParentRefs: []gatewayv1.ParentReference{{
 						Name: "example",
 					}},
 				},
-				Hostnames: []gatewayv1beta1.Hostname{"example.com"},
-				Rules: []gatewayv1beta1.HTTPRouteRule{{
-					Matches: []gatewayv1beta1.HTTPRouteMatch{{
-						Path: &gatewayv1beta1.HTTPPathMatch{
+				Hostnames: []gatewayv1.Hostname{"example.com"},
+				Rules: []gatewayv1.HTTPRouteRule{{
+					Matches: []gatewayv1.HTTPRouteMatch{{
+						Path: &gatewayv1.HTTPPathMatch{
 							Type:  &gPathPrefix,
 							Value: stringPtr("/foo"),
 						},
 					}},
-					BackendRefs: []gatewayv1beta1.HTTPBackendRef{{
-						BackendRef: gatewayv1beta1.BackendRef{
-							BackendObjectReference: gatewayv1beta1.BackendObjectReference{
+					BackendRefs: []gatewayv1.HTTPBackendRef{{
+						BackendRef: gatewayv1.BackendRef{
+							BackendObjectReference: gatewayv1.BackendObjectReference{
 								Name: "example",
 								Port: portNumberPtr(3000),
 							},
@@ -224,57 +228,83 @@ func Test_ingresses2GatewaysAndHttpRoutes(t *testing.T) {
 				},
 			},
 		}},
-		expectGateways: []gatewayv1beta1.Gateway{{
+		expectGateways: []gatewayv1.Gateway{{
 			ObjectMeta: metav1.ObjectMeta{Name: "example-proxy", Namespace: "different"},
-			Spec: gatewayv1beta1.GatewaySpec{
+			Spec: gatewayv1.GatewaySpec{
 				GatewayClassName: "example-proxy",
-				Listeners: []gatewayv1beta1.Listener{{
+				Listeners: []gatewayv1.Listener{{
 					Name:     "example-net-http",
 					Port:     80,
-					Protocol: gatewayv1beta1.HTTPProtocolType,
+					Protocol: gatewayv1.HTTPProtocolType,
 					Hostname: gatewayHostnamePtr("example.net"),
+				}, {
+					Name:     "all-hosts-http",
+					Port:     80,
+					Protocol: gatewayv1.HTTPProtocolType,
 				}},
 			},
 		}},
-		expectHttpRoutes: []gatewayv1beta1.HTTPRoute{{
+		expectHttpRoutes: []gatewayv1.HTTPRoute{{
 			ObjectMeta: metav1.ObjectMeta{Name: "example-net", Namespace: "different"},
-			Spec: gatewayv1beta1.HTTPRouteSpec{
-				CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{
-					ParentRefs: []gatewayv1beta1.ParentReference{{
+			Spec: gatewayv1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1.CommonRouteSpec{
+					ParentRefs: []gatewayv1.ParentReference{{
 						Name: "example-proxy",
 					}},
 				},
-				Hostnames: []gatewayv1beta1.Hostname{"example.net"},
-				Rules: []gatewayv1beta1.HTTPRouteRule{{
-					Matches: []gatewayv1beta1.HTTPRouteMatch{{
-						Path: &gatewayv1beta1.HTTPPathMatch{
+				Hostnames: []gatewayv1.Hostname{"example.net"},
+				Rules: []gatewayv1.HTTPRouteRule{{
+					Matches: []gatewayv1.HTTPRouteMatch{{
+						Path: &gatewayv1.HTTPPathMatch{
 							Type:  &gExact,
 							Value: stringPtr("/bar"),
 						},
 					}},
-					BackendRefs: []gatewayv1beta1.HTTPBackendRef{{
-						BackendRef: gatewayv1beta1.BackendRef{
-							BackendObjectReference: gatewayv1beta1.BackendObjectReference{
+					BackendRefs: []gatewayv1.HTTPBackendRef{{
+						BackendRef: gatewayv1.BackendRef{
+							BackendObjectReference: gatewayv1.BackendObjectReference{
 								Name:  "custom",
 								Group: apiGroupPtr("vendor.example.com"),
 								Kind:  apiKindPtr("StorageBucket"),
 							},
 						},
 					}},
+				}, {
+					Matches: []gatewayv1.HTTPRouteMatch{{
+						Path: &gatewayv1.HTTPPathMatch{
+							Type:  &gPathPrefix,
+							Value: stringPtr("/"),
+						},
+					}},
+					BackendRefs: []gatewayv1.HTTPBackendRef{{
+						BackendRef: gatewayv1.BackendRef{
+							BackendObjectReference: gatewayv1.BackendObjectReference{
+								Name: "default",
+								Port: portNumberPtr(8080),
+							},
+						},
+					}},
 				}},
 			},
 		}, {
 			ObjectMeta: metav1.ObjectMeta{Name: "net-default-backend", Namespace: "different"},
-			Spec: gatewayv1beta1.HTTPRouteSpec{
-				CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{
-					ParentRefs: []gatewayv1beta1.ParentReference{{
-						Name: "example-proxy",
+			Spec: gatewayv1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1.CommonRouteSpec{
+					ParentRefs: []gatewayv1.ParentReference{{
+						Name:        "example-proxy",
+						SectionName: sectionNamePtr("all-hosts-http"),
 					}},
 				},
-				Rules: []gatewayv1beta1.HTTPRouteRule{{
-					BackendRefs: []gatewayv1beta1.HTTPBackendRef{{
-						BackendRef: gatewayv1beta1.BackendRef{
-							BackendObjectReference: gatewayv1beta1.BackendObjectReference{
+				Rules: []gatewayv1.HTTPRouteRule{{
+					Matches: []gatewayv1.HTTPRouteMatch{{
+						Path: &gatewayv1.HTTPPathMatch{
+							Type:  &gPathPrefix,
+							Value: stringPtr("/"),
+						},
+					}},
+					BackendRefs: []gatewayv1.HTTPBackendRef{{
+						BackendRef: gatewayv1.BackendRef{
+							BackendObjectReference: gatewayv1.BackendObjectReference{
 								Name: "default",
 								Port: portNumberPtr(8080),
 							},
@@ -287,7 +317,7 @@ func Test_ingresses2GatewaysAndHttpRoutes(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			aggregator := ingressAggregator{ruleGroups: map[ruleGroupKey]*ingressRuleGroup{}}
+			aggregator := newIngressAggregator(builtinProviders())
 
 			for _, ingress := range tc.ingresses {
 				aggregator.addIngress(ingress)
@@ -332,27 +362,1178 @@ func Test_ingresses2GatewaysAndHttpRoutes(t *testing.T) {
 	}
 }
 
+func Test_ruleWithoutHTTP(t *testing.T) {
+	aggregator := newIngressAggregator(builtinProviders())
+	aggregator.addIngress(networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "tls-only", Namespace: "test"},
+		Spec: networkingv1.IngressSpec{
+			TLS:   []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-cert"}},
+			Rules: []networkingv1.IngressRule{{Host: "example.com"}},
+		},
+	})
+
+	httpRoutes, gateways, errors := aggregator.toHTTPRoutesAndGateways()
+	if len(errors) != 0 {
+		t.Errorf("Expected no errors, got %+v", errors)
+	}
+	if len(httpRoutes) != 0 {
+		t.Errorf("Expected no HTTPRoutes, got %+v", httpRoutes)
+	}
+	if len(gateways) != 1 || len(gateways[0].Spec.Listeners) != 2 {
+		t.Fatalf("Expected 1 Gateway with HTTP and HTTPS listeners, got %+v", gateways)
+	}
+	expectNotifications := []string{`WARNING: Ingress test/tls-only has a rule for host "example.com" without HTTP paths, only a Gateway listener will be generated for it`}
+	var gotNotifications []string
+	for _, n := range aggregator.notifications {
+		gotNotifications = append(gotNotifications, n.String())
+	}
+	if diff := cmp.Diff(expectNotifications, gotNotifications); diff != "" {
+		t.Errorf("Unexpected notifications, diff (-want +got): %s", diff)
+	}
+}
+
+func Test_certificateRefs(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+
+	withTLS := func(name string, tls ...networkingv1.IngressTLS) networkingv1.Ingress {
+		ingress := ingressWithPath(name, "/"+name, &iPrefix, serviceBackend(name, 80), nil)
+		ingress.Spec.TLS = tls
+		return ingress
+	}
+
+	testCases := []struct {
+		name                string
+		ingresses           []networkingv1.Ingress
+		defaultCertificate  types.NamespacedName
+		expectRefs          []string
+		expectNotifications []string
+	}{{
+		name: "same secret repeated across Ingresses",
+		ingresses: []networkingv1.Ingress{
+			withTLS("a", networkingv1.IngressTLS{Hosts: []string{"example.com"}, SecretName: "example-cert"}),
+			withTLS("b", networkingv1.IngressTLS{Hosts: []string{"example.com"}, SecretName: "example-cert"}),
+		},
+		expectRefs: []string{"example-cert"},
+	}, {
+		name: "TLS entries for other hosts are ignored",
+		ingresses: []networkingv1.Ingress{
+			withTLS("a",
+				networkingv1.IngressTLS{Hosts: []string{"example.com"}, SecretName: "example-cert"},
+				networkingv1.IngressTLS{Hosts: []string{"example.net"}, SecretName: "other-cert"}),
+		},
+		expectRefs: []string{"example-cert"},
+	}, {
+		name: "wildcard TLS host covers rule host",
+		ingresses: []networkingv1.Ingress{
+			withTLS("a", networkingv1.IngressTLS{Hosts: []string{"*.com"}, SecretName: "wildcard-cert"}),
+		},
+		expectRefs: []string{"wildcard-cert"},
+	}, {
+		name: "conflicting secrets",
+		ingresses: []networkingv1.Ingress{
+			withTLS("a", networkingv1.IngressTLS{Hosts: []string{"example.com"}, SecretName: "cert-a"}),
+			withTLS("b", networkingv1.IngressTLS{Hosts: []string{"example.com"}, SecretName: "cert-b"}),
+		},
+		expectRefs:          []string{"cert-a", "cert-b"},
+		expectNotifications: []string{`WARNING: Conflicting TLS secrets cert-a, cert-b configured for host "example.com" in namespace test, all of them will be referenced by the listener`},
+	}, {
+		name: "secret without name and no default certificate",
+		ingresses: []networkingv1.Ingress{
+			withTLS("a", networkingv1.IngressTLS{Hosts: []string{"example.com"}}),
+		},
+		expectNotifications: []string{`WARNING: TLS entry for host "example.com" in namespace test has no secretName and relies on the default certificate of the Ingress controller, set --default-certificate to reference it from the listener`},
+	}, {
+		name: "secret without name references the default certificate",
+		ingresses: []networkingv1.Ingress{
+			withTLS("a", networkingv1.IngressTLS{Hosts: []string{"example.com"}}),
+			withTLS("b", networkingv1.IngressTLS{Hosts: []string{"example.com"}}),
+		},
+		defaultCertificate: types.NamespacedName{Namespace: "ingress-nginx", Name: "default-cert"},
+		expectRefs:         []string{"ingress-nginx/default-cert"},
+	}, {
+		name: "default certificate in the namespace of the Gateway",
+		ingresses: []networkingv1.Ingress{
+			withTLS("a", networkingv1.IngressTLS{Hosts: []string{"example.com"}}),
+		},
+		defaultCertificate: types.NamespacedName{Namespace: "test", Name: "default-cert"},
+		expectRefs:         []string{"default-cert"},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			aggregator := newIngressAggregator(builtinProviders())
+			aggregator.defaultCertificate = tc.defaultCertificate
+			for _, ingress := range tc.ingresses {
+				aggregator.addIngress(ingress)
+			}
+			_, gateways, _ := aggregator.toHTTPRoutesAndGateways()
+			expectListeners := 2
+			if len(tc.expectRefs) == 0 {
+				expectListeners = 1
+			}
+			if len(gateways) != 1 || len(gateways[0].Spec.Listeners) != expectListeners {
+				t.Fatalf("Expected 1 Gateway with %d listeners, got %+v", expectListeners, gateways)
+			}
+
+			var gotRefs []string
+			if tls := gateways[0].Spec.Listeners[expectListeners-1].TLS; tls != nil {
+				for _, ref := range tls.CertificateRefs {
+					name := string(ref.Name)
+					if ref.Namespace != nil {
+						name = string(*ref.Namespace) + "/" + name
+					}
+					gotRefs = append(gotRefs, name)
+				}
+			}
+			if diff := cmp.Diff(tc.expectRefs, gotRefs); diff != "" {
+				t.Errorf("Unexpected certificateRefs, diff (-want +got): %s", diff)
+			}
+
+			var gotNotifications []string
+			for _, n := range aggregator.notifications {
+				gotNotifications = append(gotNotifications, n.String())
+			}
+			if diff := cmp.Diff(tc.expectNotifications, gotNotifications); diff != "" {
+				t.Errorf("Unexpected notifications, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func Test_listenerPerCertificate(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+
+	withHost := func(name, host string, tls ...networkingv1.IngressTLS) networkingv1.Ingress {
+		ingress := ingressWithPath(name, "/", &iPrefix, serviceBackend(name, 80), nil)
+		ingress.Spec.Rules[0].Host = host
+		ingress.Spec.TLS = tls
+		return ingress
+	}
+	wildcardTLS := networkingv1.IngressTLS{Hosts: []string{"*.example.com"}, SecretName: "wildcard-cert"}
+
+	testCases := []struct {
+		name                string
+		ingresses           []networkingv1.Ingress
+		expectListeners     []string
+		expectNotifications []string
+	}{{
+		name: "hosts covered by a wildcard certificate share a listener",
+		ingresses: []networkingv1.Ingress{
+			withHost("a", "a.example.com", wildcardTLS),
+			withHost("b", "b.example.com", wildcardTLS),
+		},
+		expectListeners: []string{"wildcard-example-com-http", "wildcard-example-com-https"},
+	}, {
+		name: "hosts of certificates without wildcard keep their listeners",
+		ingresses: []networkingv1.Ingress{
+			withHost("a", "a.example.com", networkingv1.IngressTLS{Hosts: []string{"a.example.com", "b.example.com"}, SecretName: "cert"}),
+			withHost("b", "b.example.com", networkingv1.IngressTLS{Hosts: []string{"a.example.com", "b.example.com"}, SecretName: "cert"}),
+		},
+		expectListeners: []string{"a-example-com-http", "a-example-com-https", "b-example-com-http", "b-example-com-https"},
+	}, {
+		name: "hosts without TLS keep their listeners",
+		ingresses: []networkingv1.Ingress{
+			withHost("a", "a.example.com", wildcardTLS),
+			withHost("b", "b.example.com"),
+		},
+		expectListeners: []string{"wildcard-example-com-http", "wildcard-example-com-https", "b-example-com-http"},
+	}, {
+		name: "different certificates for the same wildcard",
+		ingresses: []networkingv1.Ingress{
+			withHost("a", "a.example.com", wildcardTLS),
+			withHost("b", "b.example.com", networkingv1.IngressTLS{Hosts: []string{"*.example.com"}, SecretName: "other-cert"}),
+		},
+		expectListeners:     []string{"wildcard-example-com-http", "wildcard-example-com-https", "b-example-com-http", "b-example-com-https"},
+		expectNotifications: []string{`WARNING: Host "b.example.com" in namespace test can't share listener wildcard-example-com of Gateway test/nginx with the other hosts of its TLS certificate, their TLS settings differ`},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			aggregator := newIngressAggregator(builtinProviders())
+			aggregator.listenerStrategy = ListenerPerCertificate
+			for _, ingress := range tc.ingresses {
+				aggregator.addIngress(ingress)
+			}
+			_, gateways, _ := aggregator.toHTTPRoutesAndGateways()
+			if len(gateways) != 1 {
+				t.Fatalf("Expected 1 Gateway, got %+v", gateways)
+			}
+
+			var gotListeners []string
+			for _, listener := range gateways[0].Spec.Listeners {
+				gotListeners = append(gotListeners, string(listener.Name))
+			}
+			if diff := cmp.Diff(tc.expectListeners, gotListeners); diff != "" {
+				t.Errorf("Unexpected listeners, diff (-want +got): %s", diff)
+			}
+
+			var gotNotifications []string
+			for _, n := range aggregator.notifications {
+				gotNotifications = append(gotNotifications, n.String())
+			}
+			if diff := cmp.Diff(tc.expectNotifications, gotNotifications); diff != "" {
+				t.Errorf("Unexpected notifications, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func Test_httpsOnly(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	secure := ingressWithPath("secure", "/", &iPrefix, serviceBackend("secure", 80), nil)
+	secure.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-cert"}}
+	plain := ingressWithPath("plain", "/", &iPrefix, serviceBackend("plain", 80), nil)
+	plain.Spec.Rules[0].Host = "plain.example.com"
+
+	testCases := []struct {
+		name            string
+		mode            HTTPSOnlyMode
+		expectListeners []string
+		expectRoutes    map[string][]string
+	}{{
+		name:            "HTTP requests redirected by the ssl-redirect default of ingress-nginx",
+		expectListeners: []string{"example-com-http", "example-com-https", "plain-example-com-http"},
+		expectRoutes: map[string][]string{
+			"example-com":                {"example-com-https"},
+			"example-com-https-redirect": {"example-com-http"},
+			"plain-example-com":          {""},
+		},
+	}, {
+		name:            "HTTP listeners omitted",
+		mode:            HTTPSOnlyOmit,
+		expectListeners: []string{"example-com-https", "plain-example-com-http"},
+		expectRoutes:    map[string][]string{"example-com": {"example-com-https"}, "plain-example-com": {""}},
+	}, {
+		name:            "HTTP requests redirected",
+		mode:            HTTPSOnlyRedirect,
+		expectListeners: []string{"example-com-http", "example-com-https", "plain-example-com-http"},
+		expectRoutes: map[string][]string{
+			"example-com":                {"example-com-https"},
+			"example-com-https-redirect": {"example-com-http"},
+			"plain-example-com":          {""},
+		},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			aggregator := newIngressAggregator(builtinProviders())
+			aggregator.httpsOnly = tc.mode
+			aggregator.addIngress(secure)
+			aggregator.addIngress(plain)
+			httpRoutes, gateways, errs := aggregator.toHTTPRoutesAndGateways()
+			if len(errs) > 0 || len(gateways) != 1 {
+				t.Fatalf("Expected 1 Gateway and no errors, got %+v, %v", gateways, errs)
+			}
+
+			var gotListeners []string
+			for _, listener := range gateways[0].Spec.Listeners {
+				gotListeners = append(gotListeners, string(listener.Name))
+			}
+			if diff := cmp.Diff(tc.expectListeners, gotListeners); diff != "" {
+				t.Errorf("Unexpected listeners, diff (-want +got): %s", diff)
+			}
+
+			gotRoutes := map[string][]string{}
+			for _, route := range httpRoutes {
+				for _, parentRef := range route.Spec.ParentRefs {
+					var sectionName string
+					if parentRef.SectionName != nil {
+						sectionName = string(*parentRef.SectionName)
+					}
+					gotRoutes[route.Name] = append(gotRoutes[route.Name], sectionName)
+				}
+			}
+			if diff := cmp.Diff(tc.expectRoutes, gotRoutes); diff != "" {
+				t.Errorf("Unexpected HTTPRoute sections, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func Test_attachToListeners(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	secure := ingressWithPath("secure", "/", &iPrefix, serviceBackend("secure", 80), nil)
+	secure.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-cert"}}
+	plain := ingressWithPath("plain", "/", &iPrefix, serviceBackend("plain", 80), nil)
+	plain.Spec.Rules[0].Host = "plain.example.com"
+	plain.Spec.DefaultBackend = &networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "fallback", Port: networkingv1.ServiceBackendPort{Number: 80}}}
+
+	testCases := []struct {
+		name         string
+		mode         HTTPSOnlyMode
+		expectRoutes map[string][]string
+	}{{
+		name: "HTTP requests redirected by the ssl-redirect default of ingress-nginx",
+		expectRoutes: map[string][]string{
+			"example-com":                {"example-com-https:8443"},
+			"example-com-https-redirect": {"example-com-http:8080"},
+			"plain-example-com":          {"plain-example-com-http:8080"},
+			"plain-default-backend":      {"all-hosts-http:8080"},
+		},
+	}, {
+		name: "HTTP requests redirected",
+		mode: HTTPSOnlyRedirect,
+		expectRoutes: map[string][]string{
+			"example-com":                {"example-com-https:8443"},
+			"example-com-https-redirect": {"example-com-http:8080"},
+			"plain-example-com":          {"plain-example-com-http:8080"},
+			"plain-default-backend":      {"all-hosts-http:8080"},
+		},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			aggregator := newIngressAggregator(builtinProviders())
+			aggregator.attachToListeners = true
+			aggregator.httpsOnly = tc.mode
+			aggregator.listenerPorts = ListenerPorts{HTTP: 8080, HTTPS: 8443}
+			aggregator.addIngress(secure)
+			aggregator.addIngress(plain)
+			httpRoutes, gateways, errs := aggregator.toHTTPRoutesAndGateways()
+			if len(errs) > 0 || len(gateways) != 1 {
+				t.Fatalf("Expected 1 Gateway and no errors, got %+v, %v", gateways, errs)
+			}
+
+			gotRoutes := map[string][]string{}
+			for _, route := range httpRoutes {
+				for _, parentRef := range route.Spec.ParentRefs {
+					if parentRef.SectionName == nil || parentRef.Port == nil {
+						t.Fatalf("Expected HTTPRoute %s to attach to a section and port, got %+v", route.Name, parentRef)
+					}
+					gotRoutes[route.Name] = append(gotRoutes[route.Name], fmt.Sprintf("%s:%d", *parentRef.SectionName, *parentRef.Port))
+				}
+			}
+			if diff := cmp.Diff(tc.expectRoutes, gotRoutes); diff != "" {
+				t.Errorf("Unexpected HTTPRoute parentRefs, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func Test_extraListenerPorts(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	ingress := ingressWithPath("web", "/", &iPrefix, serviceBackend("web", 80), map[string]string{
+		"alb.ingress.kubernetes.io/listen-ports": `[{"HTTP": 80}, {"HTTP": 8080}, {"HTTPS": 8443}]`,
+	})
+	ingress.Spec.IngressClassName = stringPtr("alb")
+	ingress.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-cert"}}
+
+	aggregator := newIngressAggregator(builtinProviders())
+	aggregator.attachToListeners = true
+	aggregator.addIngressClasses([]networkingv1.IngressClass{{
+		ObjectMeta: metav1.ObjectMeta{Name: "alb"},
+		Spec:       networkingv1.IngressClassSpec{Controller: "ingress.k8s.aws/alb"},
+	}})
+	aggregator.addIngress(ingress)
+	httpRoutes, gateways, errs := aggregator.toHTTPRoutesAndGateways()
+	if len(errs) > 0 || len(gateways) != 1 || len(httpRoutes) != 1 {
+		t.Fatalf("Expected 1 Gateway, 1 HTTPRoute and no errors, got %+v, %+v, %v", gateways, httpRoutes, errs)
+	}
+
+	var gotListeners []string
+	for _, listener := range gateways[0].Spec.Listeners {
+		gotListeners = append(gotListeners, fmt.Sprintf("%s:%d/%s", listener.Name, listener.Port, listener.Protocol))
+	}
+	expectListeners := []string{"example-com-http:80/HTTP", "example-com-https:443/HTTPS", "example-com-http-8080:8080/HTTP", "example-com-https-8443:8443/HTTPS"}
+	if diff := cmp.Diff(expectListeners, gotListeners); diff != "" {
+		t.Errorf("Unexpected listeners, diff (-want +got): %s", diff)
+	}
+
+	var gotParentRefs []string
+	for _, parentRef := range httpRoutes[0].Spec.ParentRefs {
+		gotParentRefs = append(gotParentRefs, fmt.Sprintf("%s:%d", *parentRef.SectionName, *parentRef.Port))
+	}
+	expectParentRefs := []string{"example-com-http:80", "example-com-https:443", "example-com-http-8080:8080", "example-com-https-8443:8443"}
+	if diff := cmp.Diff(expectParentRefs, gotParentRefs); diff != "" {
+		t.Errorf("Unexpected HTTPRoute parentRefs, diff (-want +got): %s", diff)
+	}
+}
+
+func Test_toHTTPRouteMatches(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	path := networkingv1.HTTPIngressPath{Path: "/api", PathType: &iPrefix, Backend: serviceBackend("api", 80)}
+	pathMatch := &gatewayv1.HTTPPathMatch{Type: ptrTo(gatewayv1.PathMatchPathPrefix), Value: ptrTo("/api")}
+	canaryMatch := gatewayv1.HTTPHeaderMatch{Name: "X-Canary", Value: "always"}
+	versionParam := gatewayv1.HTTPQueryParamMatch{Name: "version", Value: "2"}
+
+	testCases := []struct {
+		name        string
+		matches     []ir.RequestMatch
+		pathOnly    bool
+		canaryMatch *gatewayv1.HTTPHeaderMatch
+		expected    []gatewayv1.HTTPRouteMatch
+	}{{
+		name:     "path only",
+		expected: []gatewayv1.HTTPRouteMatch{{Path: pathMatch}},
+	}, {
+		name:    "method and query parameters",
+		matches: []ir.RequestMatch{{Method: ptrTo(gatewayv1.HTTPMethodGet), QueryParams: []gatewayv1.HTTPQueryParamMatch{versionParam}}},
+		expected: []gatewayv1.HTTPRouteMatch{{
+			Path:        pathMatch,
+			Method:      ptrTo(gatewayv1.HTTPMethodGet),
+			QueryParams: []gatewayv1.HTTPQueryParamMatch{versionParam},
+		}},
+	}, {
+		name:     "matches of the path without a Service",
+		matches:  []ir.RequestMatch{{Method: ptrTo(gatewayv1.HTTPMethodGet)}},
+		pathOnly: true,
+		expected: []gatewayv1.HTTPRouteMatch{{Path: pathMatch, Method: ptrTo(gatewayv1.HTTPMethodGet)}},
+	}, {
+		name:        "one match per request match, with the canary header",
+		matches:     []ir.RequestMatch{{Method: ptrTo(gatewayv1.HTTPMethodGet)}, {Method: ptrTo(gatewayv1.HTTPMethodHead)}},
+		canaryMatch: &canaryMatch,
+		expected: []gatewayv1.HTTPRouteMatch{{
+			Path:    pathMatch,
+			Method:  ptrTo(gatewayv1.HTTPMethodGet),
+			Headers: []gatewayv1.HTTPHeaderMatch{canaryMatch},
+		}, {
+			Path:    pathMatch,
+			Method:  ptrTo(gatewayv1.HTTPMethodHead),
+			Headers: []gatewayv1.HTTPHeaderMatch{canaryMatch},
+		}},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			backend := ir.PathBackend{Path: "/api", Service: "api"}
+			if tc.pathOnly {
+				backend.Service = ""
+			}
+			features := &ir.IngressFeatures{Matches: map[ir.PathBackend][]ir.RequestMatch{backend: tc.matches}}
+			ip := newIngressPath("api", path, features)
+			ip.canaryMatch = tc.canaryMatch
+			got, err := toHTTPRouteMatches(ip)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Errorf("Unexpected matches, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func Test_gatewayNamespace(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	shop := ingressWithPath("shop", "/", &iPrefix, serviceBackend("shop", 80), nil)
+	shop.Namespace = "shop"
+	shop.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-cert"}}
+	blog := ingressWithPath("blog", "/blog", &iPrefix, serviceBackend("blog", 80), nil)
+	blog.Namespace = "blog"
+	blog.Spec.TLS = shop.Spec.TLS
+	infra := ingressWithPath("status", "/status", &iPrefix, serviceBackend("status", 80), nil)
+	infra.Namespace = "infra"
+
+	aggregator := newIngressAggregator(builtinProviders())
+	aggregator.gatewayNamespace = "infra"
+	aggregator.addIngress(shop)
+	aggregator.addIngress(blog)
+	aggregator.addIngress(infra)
+	httpRoutes, gateways, errs := aggregator.toHTTPRoutesAndGateways()
+	if len(errs) > 0 || len(gateways) != 1 || gateways[0].Namespace != "infra" || len(gateways[0].Spec.Listeners) != 2 {
+		t.Fatalf("Expected 1 Gateway in namespace infra with HTTP and HTTPS listeners and no errors, got %+v, %v", gateways, errs)
+	}
+
+	for _, listener := range gateways[0].Spec.Listeners {
+		expectAllowedRoutes := &gatewayv1.AllowedRoutes{
+			Namespaces: &gatewayv1.RouteNamespaces{
+				From: ptrTo(gatewayv1.NamespacesFromSelector),
+				Selector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{
+						Key:      "kubernetes.io/metadata.name",
+						Operator: metav1.LabelSelectorOpIn,
+						Values:   []string{"infra", "blog", "shop"},
+					}},
+				},
+			},
+		}
+		if diff := cmp.Diff(expectAllowedRoutes, listener.AllowedRoutes); diff != "" {
+			t.Errorf("Unexpected allowedRoutes of listener %s, diff (-want +got): %s", listener.Name, diff)
+		}
+	}
+	certNamespace := gateways[0].Spec.Listeners[1].TLS.CertificateRefs[0].Namespace
+	if certNamespace == nil || *certNamespace != "blog" {
+		t.Errorf("Expected the certificate of namespace blog to be referenced with its namespace, got %v", certNamespace)
+	}
+
+	gotParents := map[string]string{}
+	for _, route := range httpRoutes {
+		var namespace string
+		if ns := route.Spec.ParentRefs[0].Namespace; ns != nil {
+			namespace = string(*ns)
+		}
+		gotParents[route.Namespace+"/"+route.Name] = namespace
+	}
+	expectParents := map[string]string{"blog/example-com": "infra", "blog/example-com-https-redirect": "infra", "infra/example-com": "", "shop/example-com": "infra"}
+	if diff := cmp.Diff(expectParents, gotParents); diff != "" {
+		t.Errorf("Unexpected parentRef namespaces, diff (-want +got): %s", diff)
+	}
+}
+
+func Test_catchAll(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	hosted := ingressWithPath("hosted", "/", &iPrefix, serviceBackend("hosted", 80), nil)
+	catchAll := ingressWithPath("catch-all", "/api", &iPrefix, serviceBackend("api", 80), nil)
+	catchAll.Spec.Rules[0].Host = ""
+	fallback := ingressWithPath("fallback", "/", &iPrefix, serviceBackend("hosted", 80), nil)
+	fallback.Spec.DefaultBackend = &networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "fallback", Port: networkingv1.ServiceBackendPort{Number: 80}}}
+	other := *fallback.DeepCopy()
+	other.Name = "other"
+	other.Spec.DefaultBackend.Service.Name = "other"
+	catchAllRoot := ingressWithPath("catch-all-root", "/", &iPrefix, serviceBackend("root", 80), nil)
+	catchAllRoot.Spec.Rules[0].Host = ""
+	catchAllTLS := *catchAll.DeepCopy()
+	catchAllTLS.Spec.TLS = []networkingv1.IngressTLS{
+		{Hosts: []string{"a.example.com", "b.example.com"}, SecretName: "ab-cert"},
+		{Hosts: []string{"c.example.com"}, SecretName: "c-cert"},
+	}
+
+	testCases := []struct {
+		name                string
+		ingresses           []networkingv1.Ingress
+		expectListeners     []string
+		expectCertificates  map[string][]string
+		expectRoutes        map[string][]string
+		expectNotifications []string
+	}{{
+		name:            "hostname-less rules",
+		ingresses:       []networkingv1.Ingress{hosted, catchAll},
+		expectListeners: []string{"all-hosts-http", "example-com-http"},
+		expectRoutes:    map[string][]string{"example-com": {""}, "all-hosts": {"all-hosts-http"}},
+	}, {
+		name:            "hostname-less rules with TLS hosts get a listener per host and keep serving the other hosts",
+		ingresses:       []networkingv1.Ingress{catchAllTLS},
+		expectListeners: []string{"a-example-com-http", "a-example-com-https", "b-example-com-http", "b-example-com-https", "c-example-com-http", "c-example-com-https", "all-hosts-http"},
+		expectCertificates: map[string][]string{
+			"a-example-com-https": {"ab-cert"},
+			"b-example-com-https": {"ab-cert"},
+			"c-example-com-https": {"c-cert"},
+		},
+		expectRoutes: map[string][]string{
+			"all-hosts":                    {"a-example-com-https", "b-example-com-https", "c-example-com-https", "all-hosts-http"},
+			"a-example-com-https-redirect": {"a-example-com-http"},
+			"b-example-com-https-redirect": {"b-example-com-http"},
+			"c-example-com-https-redirect": {"c-example-com-http"},
+		},
+	}, {
+		name:            "default backend merged into the hostname-less route with TLS hosts",
+		ingresses:       []networkingv1.Ingress{catchAllTLS, fallback},
+		expectListeners: []string{"a-example-com-http", "a-example-com-https", "b-example-com-http", "b-example-com-https", "c-example-com-http", "c-example-com-https", "all-hosts-http", "example-com-http"},
+		expectCertificates: map[string][]string{
+			"a-example-com-https": {"ab-cert"},
+			"b-example-com-https": {"ab-cert"},
+			"c-example-com-https": {"c-cert"},
+		},
+		expectRoutes: map[string][]string{
+			"example-com":                  {""},
+			"all-hosts":                    {"a-example-com-https", "b-example-com-https", "c-example-com-https", "all-hosts-http"},
+			"a-example-com-https-redirect": {"a-example-com-http"},
+			"b-example-com-https-redirect": {"b-example-com-http"},
+			"c-example-com-https-redirect": {"c-example-com-http"},
+		},
+	}, {
+		name:            "default backend without hostname-less rules",
+		ingresses:       []networkingv1.Ingress{fallback},
+		expectListeners: []string{"example-com-http", "all-hosts-http"},
+		expectRoutes:    map[string][]string{"example-com": {""}, "fallback-default-backend": {"all-hosts-http"}},
+	}, {
+		name:            "default backend merged into the hostname-less route",
+		ingresses:       []networkingv1.Ingress{catchAll, fallback},
+		expectListeners: []string{"all-hosts-http", "example-com-http"},
+		expectRoutes:    map[string][]string{"example-com": {""}, "all-hosts": {"all-hosts-http"}},
+	}, {
+		name:                "conflicting default backends",
+		ingresses:           []networkingv1.Ingress{fallback, other},
+		expectListeners:     []string{"example-com-http", "all-hosts-http"},
+		expectRoutes:        map[string][]string{"example-com": {""}, "fallback-default-backend": {"all-hosts-http"}},
+		expectNotifications: []string{"WARNING: Ingresses test/fallback and test/other both set a default backend for Gateway test/nginx, only the one of test/fallback is converted"},
+	}, {
+		name:                "default backend shadowed by a hostname-less root path",
+		ingresses:           []networkingv1.Ingress{catchAllRoot, fallback},
+		expectListeners:     []string{"all-hosts-http", "example-com-http"},
+		expectRoutes:        map[string][]string{"example-com": {""}, "all-hosts": {"all-hosts-http"}},
+		expectNotifications: []string{`INFO: The default backend of Ingress test/fallback is not converted, HTTPRoute test/all-hosts already has a "/" path matching every request of Gateway test/nginx`},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			aggregator := newIngressAggregator(builtinProviders())
+			for _, ingress := range tc.ingresses {
+				aggregator.addIngress(ingress)
+			}
+			httpRoutes, gateways, errs := aggregator.toHTTPRoutesAndGateways()
+			if len(errs) > 0 || len(gateways) != 1 {
+				t.Fatalf("Expected 1 Gateway and no errors, got %+v, %v", gateways, errs)
+			}
+
+			var gotListeners []string
+			for _, listener := range gateways[0].Spec.Listeners {
+				gotListeners = append(gotListeners, string(listener.Name))
+			}
+			if diff := cmp.Diff(tc.expectListeners, gotListeners); diff != "" {
+				t.Errorf("Unexpected listeners, diff (-want +got): %s", diff)
+			}
+
+			var gotCertificates map[string][]string
+			for _, listener := range gateways[0].Spec.Listeners {
+				if listener.TLS == nil {
+					continue
+				}
+				if gotCertificates == nil {
+					gotCertificates = map[string][]string{}
+				}
+				for _, ref := range listener.TLS.CertificateRefs {
+					gotCertificates[string(listener.Name)] = append(gotCertificates[string(listener.Name)], string(ref.Name))
+				}
+			}
+			if diff := cmp.Diff(tc.expectCertificates, gotCertificates); diff != "" {
+				t.Errorf("Unexpected listener certificates, diff (-want +got): %s", diff)
+			}
+
+			gotRoutes := map[string][]string{}
+			for _, route := range httpRoutes {
+				for _, parentRef := range route.Spec.ParentRefs {
+					var sectionName string
+					if parentRef.SectionName != nil {
+						sectionName = string(*parentRef.SectionName)
+					}
+					gotRoutes[route.Name] = append(gotRoutes[route.Name], sectionName)
+				}
+			}
+			if diff := cmp.Diff(tc.expectRoutes, gotRoutes); diff != "" {
+				t.Errorf("Unexpected HTTPRoute sections, diff (-want +got): %s", diff)
+			}
+
+			var gotNotifications []string
+			for _, n := range aggregator.notifications {
+				gotNotifications = append(gotNotifications, n.String())
+			}
+			if diff := cmp.Diff(tc.expectNotifications, gotNotifications); diff != "" {
+				t.Errorf("Unexpected notifications, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func Test_hostDefaultBackend(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	defaultBackend := serviceBackend("fallback", 80)
+
+	testCases := []struct {
+		name         string
+		path         string
+		expectRoutes map[string][]string
+	}{{
+		name: "lowest precedence rule for the paths no rule matches",
+		path: "/api",
+		expectRoutes: map[string][]string{
+			"example-com":         {"PathPrefix /api -> api", "PathPrefix / -> fallback"},
+			"web-default-backend": {"PathPrefix / -> fallback"},
+		},
+	}, {
+		name: "no rule when the root path is routed",
+		path: "/",
+		expectRoutes: map[string][]string{
+			"example-com":         {"PathPrefix / -> api"},
+			"web-default-backend": {"PathPrefix / -> fallback"},
+		},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ingress := ingressWithPath("web", tc.path, &iPrefix, serviceBackend("api", 80), nil)
+			ingress.Spec.DefaultBackend = &defaultBackend
+			aggregator := newIngressAggregator(builtinProviders())
+			aggregator.addIngress(ingress)
+			httpRoutes, _, errs := aggregator.toHTTPRoutesAndGateways()
+			if len(errs) > 0 {
+				t.Fatalf("Unexpected errors: %v", errs)
+			}
+
+			gotRoutes := map[string][]string{}
+			for _, route := range httpRoutes {
+				for _, rule := range route.Spec.Rules {
+					match := rule.Matches[0].Path
+					gotRoutes[route.Name] = append(gotRoutes[route.Name], fmt.Sprintf("%s %s -> %s", *match.Type, *match.Value, rule.BackendRefs[0].Name))
+				}
+			}
+			if diff := cmp.Diff(tc.expectRoutes, gotRoutes); diff != "" {
+				t.Errorf("Unexpected HTTPRoute rules, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func Test_httpRouteRulePrecedence(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	iExact := networkingv1.PathTypeExact
+
+	testCases := []struct {
+		name        string
+		paths       []networkingv1.HTTPIngressPath
+		expectPaths []string
+	}{{
+		name: "exact before prefix",
+		paths: []networkingv1.HTTPIngressPath{
+			{Path: "/foo", PathType: &iPrefix, Backend: serviceBackend("prefix", 80)},
+			{Path: "/foo", PathType: &iExact, Backend: serviceBackend("exact", 80)},
+		},
+		expectPaths: []string{"Exact /foo", "PathPrefix /foo"},
+	}, {
+		name: "longest prefix first",
+		paths: []networkingv1.HTTPIngressPath{
+			{Path: "/", PathType: &iPrefix, Backend: serviceBackend("root", 80)},
+			{Path: "/foo/bar", PathType: &iPrefix, Backend: serviceBackend("foobar", 80)},
+			{Path: "/foo", PathType: &iPrefix, Backend: serviceBackend("foo", 80)},
+		},
+		expectPaths: []string{"PathPrefix /foo/bar", "PathPrefix /foo", "PathPrefix /"},
+	}, {
+		name: "exact paths of any length before prefixes",
+		paths: []networkingv1.HTTPIngressPath{
+			{Path: "/very/long/prefix", PathType: &iPrefix, Backend: serviceBackend("prefix", 80)},
+			{Path: "/a", PathType: &iExact, Backend: serviceBackend("short-exact", 80)},
+			{Path: "/ab", PathType: &iExact, Backend: serviceBackend("long-exact", 80)},
+		},
+		expectPaths: []string{"Exact /ab", "Exact /a", "PathPrefix /very/long/prefix"},
+	}, {
+		name: "equal length paths sorted lexically",
+		paths: []networkingv1.HTTPIngressPath{
+			{Path: "/bbb", PathType: &iPrefix, Backend: serviceBackend("b", 80)},
+			{Path: "/aaa", PathType: &iPrefix, Backend: serviceBackend("a", 80)},
+		},
+		expectPaths: []string{"PathPrefix /aaa", "PathPrefix /bbb"},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			aggregator := newIngressAggregator(builtinProviders())
+			aggregator.addIngress(networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test"},
+				Spec: networkingv1.IngressSpec{
+					Rules: []networkingv1.IngressRule{{
+						Host: "example.com",
+						IngressRuleValue: networkingv1.IngressRuleValue{
+							HTTP: &networkingv1.HTTPIngressRuleValue{Paths: tc.paths},
+						},
+					}},
+				},
+			})
+
+			httpRoutes, _, errors := aggregator.toHTTPRoutesAndGateways()
+			if len(errors) != 0 {
+				t.Fatalf("Expected no errors, got %+v", errors)
+			}
+			if len(httpRoutes) != 1 {
+				t.Fatalf("Expected 1 HTTPRoute, got %d", len(httpRoutes))
+			}
+
+			var gotPaths []string
+			for _, rule := range httpRoutes[0].Spec.Rules {
+				gotPaths = append(gotPaths, fmt.Sprintf("%s %s", *rule.Matches[0].Path.Type, *rule.Matches[0].Path.Value))
+			}
+			if diff := cmp.Diff(tc.expectPaths, gotPaths); diff != "" {
+				t.Errorf("Unexpected rule order, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func Test_sortHTTPRouteRules(t *testing.T) {
+	gPathPrefix := gatewayv1.PathMatchPathPrefix
+	gExact := gatewayv1.PathMatchExact
+	get := gatewayv1.HTTPMethodGet
+
+	prefix := func(path string) *gatewayv1.HTTPPathMatch {
+		return &gatewayv1.HTTPPathMatch{Type: &gPathPrefix, Value: stringPtr(path)}
+	}
+	// describe lists the matches of the rule, each as its path followed by
+	// the method, headers and query parameters it matches.
+	describe := func(rule gatewayv1.HTTPRouteRule) string {
+		var matches []string
+		for _, m := range rule.Matches {
+			desc := *m.Path.Value
+			if m.Method != nil {
+				desc += " " + string(*m.Method)
+			}
+			for _, h := range m.Headers {
+				desc += " " + string(h.Name)
+			}
+			for _, q := range m.QueryParams {
+				desc += " ?" + string(q.Name)
+			}
+			matches = append(matches, desc)
+		}
+		return strings.Join(matches, ", ")
+	}
+
+	testCases := []struct {
+		name        string
+		rules       []gatewayv1.HTTPRouteRule
+		expectRules []string
+	}{{
+		name: "multi-match rule kept whole at its highest-precedence match",
+		rules: []gatewayv1.HTTPRouteRule{
+			{Matches: []gatewayv1.HTTPRouteMatch{{Path: prefix("/api")}}},
+			{Matches: []gatewayv1.HTTPRouteMatch{{Path: prefix("/")}, {Path: &gatewayv1.HTTPPathMatch{Type: &gExact, Value: stringPtr("/login")}}}},
+		},
+		expectRules: []string{"/, /login", "/api"},
+	}, {
+		name: "method before headers",
+		rules: []gatewayv1.HTTPRouteRule{
+			{Matches: []gatewayv1.HTTPRouteMatch{{Path: prefix("/api")}}},
+			{Matches: []gatewayv1.HTTPRouteMatch{{Path: prefix("/api"), Headers: []gatewayv1.HTTPHeaderMatch{{Name: "X-Canary", Value: "always"}}}}},
+			{Matches: []gatewayv1.HTTPRouteMatch{{Path: prefix("/api"), Method: &get}}},
+		},
+		expectRules: []string{"/api GET", "/api X-Canary", "/api"},
+	}, {
+		name: "headers before query parameters",
+		rules: []gatewayv1.HTTPRouteRule{
+			{Matches: []gatewayv1.HTTPRouteMatch{{Path: prefix("/api"), QueryParams: []gatewayv1.HTTPQueryParamMatch{{Name: "beta", Value: "true"}}}}},
+			{Matches: []gatewayv1.HTTPRouteMatch{{Path: prefix("/api"), Headers: []gatewayv1.HTTPHeaderMatch{{Name: "X-Canary", Value: "always"}}}}},
+			{Matches: []gatewayv1.HTTPRouteMatch{{Path: prefix("/api")}}},
+		},
+		expectRules: []string{"/api X-Canary", "/api ?beta", "/api"},
+	}, {
+		name: "rule ordered by its header match",
+		rules: []gatewayv1.HTTPRouteRule{
+			{Matches: []gatewayv1.HTTPRouteMatch{{Path: prefix("/api"), Method: &get}}},
+			{Matches: []gatewayv1.HTTPRouteMatch{{Path: prefix("/")}, {Path: prefix("/api"), Method: &get, Headers: []gatewayv1.HTTPHeaderMatch{{Name: "X-Canary", Value: "always"}}}}},
+		},
+		expectRules: []string{"/, /api GET X-Canary", "/api GET"},
+	}, {
+		name: "rules without matches last",
+		rules: []gatewayv1.HTTPRouteRule{
+			{},
+			{Matches: []gatewayv1.HTTPRouteMatch{{Path: prefix("/")}}},
+		},
+		expectRules: []string{"/", ""},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotRules []string
+			for _, rule := range sortHTTPRouteRules(tc.rules) {
+				gotRules = append(gotRules, describe(rule))
+			}
+			if diff := cmp.Diff(tc.expectRules, gotRules); diff != "" {
+				t.Errorf("Unexpected rule order, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func Test_canaryIngressPairing(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+
+	primary := ingressWithPath("primary", "/", &iPrefix, serviceBackend("stable", 80), nil)
+	weightCanary := ingressWithPath("canary", "/", &iPrefix, serviceBackend("canary", 80), map[string]string{
+		"nginx.ingress.kubernetes.io/canary":        "true",
+		"nginx.ingress.kubernetes.io/canary-weight": "20",
+	})
+	zeroWeightCanary := ingressWithPath("canary", "/", &iPrefix, serviceBackend("canary", 80), map[string]string{
+		"nginx.ingress.kubernetes.io/canary":        "true",
+		"nginx.ingress.kubernetes.io/canary-weight": "0",
+	})
+	headerCanary := ingressWithPath("canary", "/", &iPrefix, serviceBackend("canary", 80), map[string]string{
+		"nginx.ingress.kubernetes.io/canary":           "true",
+		"nginx.ingress.kubernetes.io/canary-by-header": "X-Canary",
+		"nginx.ingress.kubernetes.io/canary-weight":    "20",
+	})
+	combinedCanary := ingressWithPath("canary", "/", &iPrefix, serviceBackend("canary", 80), map[string]string{
+		"nginx.ingress.kubernetes.io/canary":                 "true",
+		"nginx.ingress.kubernetes.io/canary-by-header":       "X-Canary",
+		"nginx.ingress.kubernetes.io/canary-by-header-value": "yes",
+		"nginx.ingress.kubernetes.io/canary-by-cookie":       "canary",
+		"nginx.ingress.kubernetes.io/canary-weight":          "20",
+	})
+	orphanCanary := ingressWithPath("canary", "/other", &iPrefix, serviceBackend("canary", 80), map[string]string{
+		"nginx.ingress.kubernetes.io/canary":        "true",
+		"nginx.ingress.kubernetes.io/canary-weight": "20",
+	})
+
+	testCases := []struct {
+		name         string
+		ingresses    []networkingv1.Ingress
+		expectRules  []string
+		expectErrors []string
+	}{{
+		name:        "weighted canary joins primary rule",
+		ingresses:   []networkingv1.Ingress{primary, weightCanary},
+		expectRules: []string{"/ [stable=80 canary=20]"},
+	}, {
+		name:        "canary processed before primary",
+		ingresses:   []networkingv1.Ingress{weightCanary, primary},
+		expectRules: []string{"/ [stable=80 canary=20]"},
+	}, {
+		name:        "zero weight canary gets no requests",
+		ingresses:   []networkingv1.Ingress{primary, zeroWeightCanary},
+		expectRules: []string{"/ [stable=100 canary=0]"},
+	}, {
+		name:        "header canary gets its own rule and a weighted backend",
+		ingresses:   []networkingv1.Ingress{primary, headerCanary},
+		expectRules: []string{"/ X-Canary [canary]", "/ X-Canary [stable]", "/ [stable=80 canary=20]"},
+	}, {
+		name:        "header before cookie before weight",
+		ingresses:   []networkingv1.Ingress{primary, combinedCanary},
+		expectRules: []string{"/ X-Canary [canary]", "/ Cookie [canary]", "/ Cookie [stable]", "/ [stable=80 canary=20]"},
+	}, {
+		name:         "canary without primary",
+		ingresses:    []networkingv1.Ingress{primary, orphanCanary},
+		expectRules:  []string{"/ [stable]"},
+		expectErrors: []string{`canary Ingress test/canary has no primary Ingress for host "example.com" and path "/other"`},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			aggregator := newIngressAggregator(builtinProviders())
+			for _, ingress := range tc.ingresses {
+				aggregator.addIngress(ingress)
+			}
+
+			httpRoutes, _, errors := aggregator.toHTTPRoutesAndGateways()
+			if len(httpRoutes) != 1 {
+				t.Fatalf("Expected 1 HTTPRoute, got %d", len(httpRoutes))
+			}
+
+			var gotRules []string
+			for _, rule := range httpRoutes[0].Spec.Rules {
+				gotRules = append(gotRules, describeRule(rule))
+			}
+			if diff := cmp.Diff(tc.expectRules, gotRules); diff != "" {
+				t.Errorf("Unexpected rules, diff (-want +got): %s", diff)
+			}
+
+			var gotErrors []string
+			for _, err := range errors {
+				gotErrors = append(gotErrors, err.Error())
+			}
+			if diff := cmp.Diff(tc.expectErrors, gotErrors); diff != "" {
+				t.Errorf("Unexpected errors, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+// describeRule summarizes a rule as "<path> [<headers>] [<backend>=<weight> ...]".
+func describeRule(rule gatewayv1.HTTPRouteRule) string {
+	desc := *rule.Matches[0].Path.Value
+	for _, h := range rule.Matches[0].Headers {
+		desc += " " + string(h.Name)
+	}
+	var backends []string
+	for _, br := range rule.BackendRefs {
+		if br.Weight != nil {
+			backends = append(backends, fmt.Sprintf("%s=%d", br.Name, *br.Weight))
+		} else {
+			backends = append(backends, string(br.Name))
+		}
+	}
+	return fmt.Sprintf("%s %v", desc, backends)
+}
+
+func ingressWithPath(name, path string, pathType *networkingv1.PathType, backend networkingv1.IngressBackend, annotations map[string]string) networkingv1.Ingress {
+	return networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test", Annotations: annotations},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: stringPtr("nginx"),
+			Rules: []networkingv1.IngressRule{{
+				Host: "example.com",
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{Path: path, PathType: pathType, Backend: backend}},
+					},
+				},
+			}},
+		},
+	}
+}
+
+func Test_setBackendWeights(t *testing.T) {
+	testCases := []struct {
+		name          string
+		canaries      []*ir.Canary
+		expectWeights []int32
+	}{{
+		name:          "no canaries leaves weights unset",
+		canaries:      []*ir.Canary{nil, nil},
+		expectWeights: []int32{-1, -1},
+	}, {
+		name:          "default total of 100",
+		canaries:      []*ir.Canary{nil, {Weight: 20, WeightTotal: 100}},
+		expectWeights: []int32{80, 20},
+	}, {
+		name:          "custom total",
+		canaries:      []*ir.Canary{{Weight: 333, WeightTotal: 1000}, nil},
+		expectWeights: []int32{333, 667},
+	}, {
+		name:          "remainder is not truncated",
+		canaries:      []*ir.Canary{nil, nil, {Weight: 25, WeightTotal: 100}},
+		expectWeights: []int32{38, 37, 25},
+	}, {
+		name:          "canaries with different totals",
+		canaries:      []*ir.Canary{nil, {Weight: 10, WeightTotal: 100}, {Weight: 1, WeightTotal: 3}},
+		expectWeights: []int32{170, 30, 100},
+	}, {
+		name:          "weight above total is capped",
+		canaries:      []*ir.Canary{nil, {Weight: 150, WeightTotal: 100}},
+		expectWeights: []int32{0, 100},
+	}, {
+		name:          "negative weight is raised to zero",
+		canaries:      []*ir.Canary{nil, {Weight: -5, WeightTotal: 100}},
+		expectWeights: []int32{100, 0},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			backendRefs := make([]ir.Backend, len(tc.canaries))
+			setBackendWeights(backendRefs, tc.canaries)
+
+			var gotWeights []int32
+			for _, br := range backendRefs {
+				if br.Weight == nil {
+					gotWeights = append(gotWeights, -1)
+				} else {
+					gotWeights = append(gotWeights, *br.Weight)
+				}
+			}
+			if diff := cmp.Diff(tc.expectWeights, gotWeights); diff != "" {
+				t.Errorf("Unexpected weights, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func Test_normalizeBackendWeights(t *testing.T) {
+	testCases := []struct {
+		name          string
+		canaries      []*ir.Canary
+		total         int64
+		expectWeights []int32
+	}{{
+		name:          "no canaries leaves weights unset",
+		canaries:      []*ir.Canary{nil, nil},
+		total:         100,
+		expectWeights: []int32{-1, -1},
+	}, {
+		name:          "canary of another total",
+		canaries:      []*ir.Canary{nil, {Weight: 20, WeightTotal: 100}},
+		total:         1000,
+		expectWeights: []int32{800, 200},
+	}, {
+		name:          "largest remainder gets the weight left",
+		canaries:      []*ir.Canary{nil, {Weight: 1, WeightTotal: 3}},
+		total:         1000,
+		expectWeights: []int32{667, 333},
+	}, {
+		name:          "first backend wins ties",
+		canaries:      []*ir.Canary{nil, nil, {Weight: 1, WeightTotal: 3}},
+		total:         100,
+		expectWeights: []int32{34, 33, 33},
+	}, {
+		name:          "canaries above the total are scaled down",
+		canaries:      []*ir.Canary{nil, {Weight: 80, WeightTotal: 100}, {Weight: 60, WeightTotal: 100}},
+		total:         100,
+		expectWeights: []int32{0, 57, 43},
+	}, {
+		name:          "canaries alone are scaled up",
+		canaries:      []*ir.Canary{{Weight: 10, WeightTotal: 100}, {Weight: 30, WeightTotal: 100}},
+		total:         100,
+		expectWeights: []int32{25, 75},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			backendRefs := make([]ir.Backend, len(tc.canaries))
+			normalizeBackendWeights(backendRefs, tc.canaries, tc.total)
+
+			var gotWeights []int32
+			for _, br := range backendRefs {
+				if br.Weight == nil {
+					gotWeights = append(gotWeights, -1)
+				} else {
+					gotWeights = append(gotWeights, *br.Weight)
+				}
+			}
+			if diff := cmp.Diff(tc.expectWeights, gotWeights); diff != "" {
+				t.Errorf("Unexpected weights, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func serviceBackend(name string, port int32) networkingv1.IngressBackend {
+	return networkingv1.IngressBackend{
+		Service: &networkingv1.IngressServiceBackend{
+			Name: name,
+			Port: networkingv1.ServiceBackendPort{Number: port},
+		},
+	}
+}
+
 func stringPtr(s string) *string {
 	return &s
 }
 
 // TODO: Replace these with Gateway API util funcs.
-func apiGroupPtr(s string) *gatewayv1beta1.Group {
-	g := gatewayv1beta1.Group(s)
+func apiGroupPtr(s string) *gatewayv1.Group {
+	g := gatewayv1.Group(s)
 	return &g
 }
 
-func apiKindPtr(s string) *gatewayv1beta1.Kind {
-	k := gatewayv1beta1.Kind(s)
+func apiKindPtr(s string) *gatewayv1.Kind {
+	k := gatewayv1.Kind(s)
 	return &k
 }
 
-func portNumberPtr(p int) *gatewayv1beta1.PortNumber {
-	pn := gatewayv1beta1.PortNumber(p)
+func portNumberPtr(p int) *gatewayv1.PortNumber {
+	pn := gatewayv1.PortNumber(p)
 	return &pn
 }
 
-func gatewayHostnamePtr(s string) *gatewayv1beta1.Hostname {
-	h := gatewayv1beta1.Hostname(s)
+func gatewayHostnamePtr(s string) *gatewayv1.Hostname {
+	h := gatewayv1.Hostname(s)
 	return &h
 }
+
+func ptrTo[T any](v T) *T {
+	return &v
+}
+
+func sectionNamePtr(s string) *gatewayv1.SectionName {
+	n := gatewayv1.SectionName(s)
+	return &n
+}
+
+func Test_toIR_deterministicWithWorkers(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	var ingresses []networkingv1.Ingress
+	for i := 0; i < 200; i++ {
+		ingress := ingressWithPath(fmt.Sprintf("ingress-%d", i), fmt.Sprintf("/%d", i%7), &iPrefix, serviceBackend(fmt.Sprintf("svc-%d", i), 80), nil)
+		ingress.Namespace = fmt.Sprintf("ns-%d", i%5)
+		ingress.Spec.Rules[0].Host = fmt.Sprintf("host-%d.example.com", i%13)
+		ingresses = append(ingresses, ingress)
+	}
+
+	convert := func(workers int) ([]gatewayv1.HTTPRoute, []gatewayv1.Gateway, []error) {
+		aggregator := newIngressAggregator(builtinProviders())
+		aggregator.workers = workers
+		for _, ingress := range ingresses {
+			aggregator.addIngress(ingress)
+		}
+		return aggregator.toHTTPRoutesAndGateways()
+	}
+
+	wantRoutes, wantGateways, wantErrors := convert(1)
+	for _, workers := range []int{2, 8, 64} {
+		gotRoutes, gotGateways, gotErrors := convert(workers)
+		if !apiequality.Semantic.DeepEqual(wantRoutes, gotRoutes) {
+			t.Errorf("HTTPRoutes differ with %d workers", workers)
+		}
+		if !apiequality.Semantic.DeepEqual(wantGateways, gotGateways) {
+			t.Errorf("Gateways differ with %d workers", workers)
+		}
+		if len(wantErrors) != len(gotErrors) {
+			t.Errorf("Errors differ with %d workers: %v", workers, gotErrors)
+		}
+	}
+}
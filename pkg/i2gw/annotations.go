@@ -0,0 +1,168 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/ingressnginx"
+	networkingv1 "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+)
+
+// controllerAnnotationPrefixes are the prefixes of the annotations of Ingress
+// controllers, which are reported when no provider handles them, such as
+// ingress-nginx annotations of Ingresses served by another controller.
+var controllerAnnotationPrefixes = []string{
+	"nginx.ingress.kubernetes.io/",
+	"nginx.org/",
+	"alb.ingress.kubernetes.io/",
+	"traefik.ingress.kubernetes.io/",
+	"haproxy.org/",
+	"haproxy.router.openshift.io/",
+	"konghq.com/",
+	"projectcontour.io/",
+	"ingress.kubernetes.io/",
+	"kubernetes.io/ingress.",
+	"ingress.gcp.kubernetes.io/",
+	"networking.gke.io/",
+	"appgw.ingress.kubernetes.io/",
+	"zalando.org/",
+	"getambassador.io/",
+	"ingress2gateway.kubernetes.io/",
+}
+
+// handledAnnotations are the annotations with a controller prefix that the
+// conversion handles outside of providers.
+var handledAnnotations = map[string]bool{
+	networkingv1beta1.AnnotationIngressClass: true,
+	SkipAnnotation:                           true,
+	GatewayNameAnnotation:                    true,
+	RouteNameAnnotation:                      true,
+	MigratedToAnnotation:                     true,
+	SourcesAnnotation:                        true,
+	SourceChecksumAnnotation:                 true,
+	ConfidenceAnnotation:                     true,
+}
+
+// unhandledAnnotations returns the annotations of the Ingress with a
+// controller prefix that neither the providers nor the target
+// implementation handled, sorted.
+func (a *ingressAggregator) unhandledAnnotations(ingress networkingv1.Ingress, features *ir.IngressFeatures) []string {
+	handled := map[string]bool{}
+	for _, annotations := range [][]string{features.ConvertedAnnotations, features.UnsupportedAnnotations, a.targetAnnotations} {
+		for _, annotation := range annotations {
+			handled[annotation] = true
+		}
+	}
+	var unhandled []string
+	for annotation := range ingress.Annotations {
+		if handled[annotation] || handledAnnotations[annotation] || !hasControllerPrefix(annotation) {
+			continue
+		}
+		unhandled = append(unhandled, annotation)
+	}
+	sort.Strings(unhandled)
+	return unhandled
+}
+
+func hasControllerPrefix(annotation string) bool {
+	for _, prefix := range controllerAnnotationPrefixes {
+		if strings.HasPrefix(annotation, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// AnnotationsFormat selects how the supported annotations are written.
+type AnnotationsFormat string
+
+const (
+	// AnnotationsTable writes the annotations as a table followed by the
+	// number of annotations of each status.
+	AnnotationsTable AnnotationsFormat = "table"
+	// AnnotationsJSON writes the annotations as a JSON list.
+	AnnotationsJSON AnnotationsFormat = "json"
+	// AnnotationsMarkdown writes the annotations as a Markdown table, for
+	// migration plans.
+	AnnotationsMarkdown AnnotationsFormat = "markdown"
+)
+
+func AnnotationsFormats() []string {
+	return []string{string(AnnotationsTable), string(AnnotationsJSON), string(AnnotationsMarkdown)}
+}
+
+// WriteSupportedAnnotations writes the documented ingress-nginx annotations
+// with how they are converted, only the ones of status if it is set.
+func WriteSupportedAnnotations(w io.Writer, format AnnotationsFormat, status string) error {
+	if status != "" && !slices.Contains(ingressnginx.AnnotationStatuses(), status) {
+		return fmt.Errorf("unknown annotation status %q, supported ones are: %s", status, strings.Join(ingressnginx.AnnotationStatuses(), ", "))
+	}
+	var annotations []ingressnginx.Annotation
+	for _, a := range ingressnginx.Annotations() {
+		if status == "" || string(a.Status) == status {
+			annotations = append(annotations, a)
+		}
+	}
+
+	switch format {
+	case "", AnnotationsTable:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "ANNOTATION\tSTATUS\tCONVERSION")
+		counts := map[ingressnginx.AnnotationStatus]int{}
+		for _, a := range annotations {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", a.Name, a.Status, a.Conversion)
+			counts[a.Status]++
+		}
+		fmt.Fprintln(tw)
+		for _, s := range ingressnginx.AnnotationStatuses() {
+			if counts[ingressnginx.AnnotationStatus(s)] > 0 {
+				fmt.Fprintf(tw, "%s\t%d\n", s, counts[ingressnginx.AnnotationStatus(s)])
+			}
+		}
+		return tw.Flush()
+	case AnnotationsJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(annotations)
+	case AnnotationsMarkdown:
+		fmt.Fprintln(w, "| Annotation | Status | Conversion |")
+		fmt.Fprintln(w, "| --- | --- | --- |")
+		for _, a := range annotations {
+			fmt.Fprintf(w, "| `%s` | %s | %s |\n", a.Name, a.Status, a.Conversion)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown annotations format %q, supported ones are: %s", format, strings.Join(AnnotationsFormats(), ", "))
+	}
+}
+
+// RunSupportedAnnotations prints the supported annotations to stdout.
+func RunSupportedAnnotations(format AnnotationsFormat, status string) {
+	if err := WriteSupportedAnnotations(os.Stdout, format, status); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/ingressnginx"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/targets/gke"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+func Test_unhandledAnnotations(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	testCases := []struct {
+		name              string
+		annotations       map[string]string
+		targetAnnotations []string
+		expectUnsupported []string
+	}{{
+		name: "annotations of other controllers",
+		annotations: map[string]string{
+			"traefik.ingress.kubernetes.io/router.middlewares": "default-auth@kubernetescrd",
+			"konghq.com/strip-path":                            "true",
+			"example.com/team":                                 "shop",
+		},
+		expectUnsupported: []string{"konghq.com/strip-path", "traefik.ingress.kubernetes.io/router.middlewares"},
+	}, {
+		name: "annotations handled by the conversion",
+		annotations: map[string]string{
+			"kubernetes.io/ingress.class":             "nginx",
+			RouteNameAnnotation:                       "shop",
+			"nginx.ingress.kubernetes.io/ssl-ciphers": "ECDHE-RSA-AES256-GCM-SHA384",
+		},
+	}, {
+		name: "misspelled override annotation",
+		annotations: map[string]string{
+			"ingress2gateway.kubernetes.io/skp": "true",
+		},
+		expectUnsupported: []string{"ingress2gateway.kubernetes.io/skp"},
+	}, {
+		name: "annotations converted by the target implementation",
+		annotations: map[string]string{
+			"kubernetes.io/ingress.global-static-ip-name": "shop-ip",
+			"kubernetes.io/ingress.allow-http":            "false",
+		},
+		targetAnnotations: gke.IngressAnnotations,
+		expectUnsupported: []string{"kubernetes.io/ingress.allow-http"},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			aggregator := newIngressAggregator(builtinProviders())
+			aggregator.targetAnnotations = tc.targetAnnotations
+			aggregator.addIngress(ingressWithPath("shop", "/", &iPrefix, serviceBackend("shop", 80), tc.annotations))
+
+			var gotUnsupported []string
+			for _, u := range aggregator.unsupported {
+				gotUnsupported = append(gotUnsupported, u.Annotation)
+			}
+			if diff := cmp.Diff(tc.expectUnsupported, gotUnsupported); diff != "" {
+				t.Errorf("Unexpected unsupported annotations, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func Test_WriteSupportedAnnotations(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSupportedAnnotations(&buf, AnnotationsJSON, string(ingressnginx.AnnotationPolicy)); err != nil {
+		t.Fatalf("WriteSupportedAnnotations failed: %v", err)
+	}
+	var annotations []ingressnginx.Annotation
+	if err := json.Unmarshal(buf.Bytes(), &annotations); err != nil {
+		t.Fatalf("Failed to decode annotations: %v", err)
+	}
+	if len(annotations) == 0 {
+		t.Fatalf("Expected policy-emitted annotations")
+	}
+	for _, a := range annotations {
+		if a.Status != ingressnginx.AnnotationPolicy {
+			t.Errorf("Expected only policy-emitted annotations, got %+v", a)
+		}
+	}
+
+	buf.Reset()
+	if err := WriteSupportedAnnotations(&buf, AnnotationsTable, ""); err != nil {
+		t.Fatalf("WriteSupportedAnnotations failed: %v", err)
+	}
+	for _, want := range []string{"ANNOTATION", "nginx.ingress.kubernetes.io/canary ", "unsupported"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected the table to contain %q, got:\n%s", want, buf.String())
+		}
+	}
+
+	if err := WriteSupportedAnnotations(&buf, AnnotationsTable, "partial"); err == nil {
+		t.Errorf("Expected an error for an unknown status")
+	}
+	if err := WriteSupportedAnnotations(&buf, "csv", ""); err == nil {
+		t.Errorf("Expected an error for an unknown format")
+	}
+}
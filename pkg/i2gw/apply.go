@@ -0,0 +1,151 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ApplyOptions configures Apply.
+type ApplyOptions struct {
+	// Client is used to apply the objects to a cluster.
+	Client client.Client
+	// InputFile is the path of the manifest file to apply, usually the
+	// output of a conversion.
+	InputFile string
+	// Objects are applied after the objects of the InputFile, if any.
+	Objects []unstructured.Unstructured
+	// FieldManager is the field manager of the applied fields. It defaults
+	// to FieldManager.
+	FieldManager string
+	// Force takes the ownership of the fields set by other field managers,
+	// such as kubectl edit, instead of failing with a conflict.
+	Force bool
+	// DryRun validates the objects with the API server without persisting
+	// them.
+	DryRun bool
+	// Wait, if set, is how long to wait for the Gateways applied to be
+	// programmed and the routes to be accepted by the target
+	// implementation. It is ignored with DryRun.
+	Wait time.Duration
+}
+
+// Apply applies the objects of the input file, and Objects, with Server-Side
+// Apply and returns the objects applied. Applying the output of a conversion
+// again after the Ingresses changed updates the objects in place and removes
+// the fields no longer generated, since they are owned by the same field
+// manager. It stops at the first object failing to apply. With Wait, the
+// Gateways and routes applied that the target implementation rejected are
+// returned as RejectedResource errors.
+func Apply(ctx context.Context, opts ApplyOptions) ([]ObjectRef, error) {
+	fieldManager := opts.FieldManager
+	if fieldManager == "" {
+		fieldManager = FieldManager
+	}
+
+	var objects []unstructured.Unstructured
+	if opts.InputFile != "" {
+		f, err := os.Open(opts.InputFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open input file: %w", err)
+		}
+		defer f.Close()
+		err = decodeObjects(f, func(obj unstructured.Unstructured) error {
+			objects = append(objects, obj)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	objects = append(objects, opts.Objects...)
+
+	patchOpts := []client.PatchOption{client.FieldOwner(fieldManager)}
+	if opts.Force {
+		patchOpts = append(patchOpts, client.ForceOwnership)
+	}
+	if opts.DryRun {
+		patchOpts = append(patchOpts, client.DryRunAll)
+	}
+	var applied []ObjectRef
+	for i := range objects {
+		obj, err := cleanObject(&objects[i])
+		if err != nil {
+			return applied, err
+		}
+		ref := ObjectRef{Kind: obj.GetKind(), Namespace: obj.GetNamespace(), Name: obj.GetName()}
+		if err := opts.Client.Patch(ctx, obj, client.Apply, patchOpts...); err != nil {
+			return applied, fmt.Errorf("failed to apply %s %s/%s: %w", ref.Kind, ref.Namespace, ref.Name, err)
+		}
+		applied = append(applied, ref)
+	}
+	if opts.Wait > 0 && !opts.DryRun {
+		rejected, err := WaitForAcceptance(ctx, opts.Client, applied, opts.Wait)
+		if err != nil {
+			return applied, err
+		}
+		return applied, rejectedError(rejected)
+	}
+	return applied, nil
+}
+
+// RunApply applies the input file to the cluster of the current kubeconfig,
+// as the apply command does, cancelling it after timeout, if set.
+func RunApply(opts ApplyOptions, timeout time.Duration) {
+	cl, err := newClient("", client.Options{})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	opts.Client = cl
+
+	ctx, cancel := runContext(timeout)
+	defer cancel()
+	applied, err := Apply(ctx, opts)
+	for _, ref := range applied {
+		fmt.Printf("%s %s/%s serverside-applied\n", ref.Kind, ref.Namespace, ref.Name)
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// applyToContext applies the resources to the cluster of the kubeconfig
+// context, writing the objects applied to w.
+func applyToContext(ctx context.Context, w io.Writer, kubeContext string, resources Resources, dryRun bool, timeout time.Duration) error {
+	cl, err := newClient(kubeContext, client.Options{})
+	if err != nil {
+		return fmt.Errorf("kubeconfig context %s: %w", kubeContext, err)
+	}
+	sink := NewApplySink(ApplyOptions{Client: cl, DryRun: dryRun, Wait: timeout})
+	err = sink.Write(ctx, resources, Report{})
+	suffix := ""
+	if dryRun {
+		suffix = " (server dry run)"
+	}
+	for _, ref := range sink.Applied {
+		fmt.Fprintf(w, "%s %s/%s serverside-applied to %s%s\n", ref.Kind, ref.Namespace, ref.Name, kubeContext, suffix)
+	}
+	return err
+}
@@ -0,0 +1,141 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func Test_Apply(t *testing.T) {
+	className := "nginx"
+	ingress := networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: &className,
+			Rules: []networkingv1.IngressRule{{
+				Host: "example.com",
+				IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{{
+						Path:     "/",
+						PathType: ptrTo(networkingv1.PathTypePrefix),
+						Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+							Name: "web",
+							Port: networkingv1.ServiceBackendPort{Number: 80},
+						}},
+					}},
+				}},
+			}},
+		},
+	}
+	resources, report, err := Convert(context.Background(), ConvertOptions{Ingresses: []networkingv1.Ingress{ingress}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	WriteApplyResult(&buf, resources, report)
+	for _, field := range []string{"status:", "creationTimestamp:"} {
+		if strings.Contains(buf.String(), field) {
+			t.Errorf("Expected the output not to contain %s, got:\n%s", field, buf.String())
+		}
+	}
+	inputFile := filepath.Join(t.TempDir(), "output.yaml")
+	if err := os.WriteFile(inputFile, buf.Bytes(), 0o600); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	var patched []ObjectRef
+	var patchOpts []client.PatchOptions
+	cl := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Patch: func(_ context.Context, _ client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			if patch.Type() != types.ApplyPatchType {
+				t.Errorf("Expected an apply patch, got %s", patch.Type())
+			}
+			u := obj.(*unstructured.Unstructured)
+			patched = append(patched, ObjectRef{Kind: u.GetKind(), Namespace: u.GetNamespace(), Name: u.GetName()})
+			patchOpts = append(patchOpts, *(&client.PatchOptions{}).ApplyOptions(opts))
+			return nil
+		},
+	}).Build()
+
+	applied, err := Apply(context.Background(), ApplyOptions{Client: cl, InputFile: inputFile, Force: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []ObjectRef{
+		{Kind: "Gateway", Namespace: "default", Name: "nginx"},
+		{Kind: "HTTPRoute", Namespace: "default", Name: "example-com"},
+	}
+	if diff := cmp.Diff(expected, applied); diff != "" {
+		t.Errorf("Unexpected applied objects, diff (-want +got): %s", diff)
+	}
+	if diff := cmp.Diff(expected, patched); diff != "" {
+		t.Errorf("Unexpected patched objects, diff (-want +got): %s", diff)
+	}
+	for _, opts := range patchOpts {
+		if opts.FieldManager != FieldManager || opts.Force == nil || !*opts.Force {
+			t.Errorf("Expected a forced apply by %s, got field manager %q and force %v", FieldManager, opts.FieldManager, opts.Force)
+		}
+	}
+}
+
+func Test_Apply_objectsDryRun(t *testing.T) {
+	gateway := unstructured.Unstructured{}
+	gateway.SetAPIVersion("gateway.networking.k8s.io/v1")
+	gateway.SetKind("Gateway")
+	gateway.SetNamespace("default")
+	gateway.SetName("nginx")
+	gateway.SetResourceVersion("42")
+
+	var patchOpts []client.PatchOptions
+	var resourceVersions []string
+	cl := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Patch: func(_ context.Context, _ client.WithWatch, obj client.Object, _ client.Patch, opts ...client.PatchOption) error {
+			resourceVersions = append(resourceVersions, obj.GetResourceVersion())
+			patchOpts = append(patchOpts, *(&client.PatchOptions{}).ApplyOptions(opts))
+			return nil
+		},
+	}).Build()
+
+	applied, err := Apply(context.Background(), ApplyOptions{Client: cl, Objects: []unstructured.Unstructured{gateway}, DryRun: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]ObjectRef{{Kind: "Gateway", Namespace: "default", Name: "nginx"}}, applied); diff != "" {
+		t.Errorf("Unexpected applied objects, diff (-want +got): %s", diff)
+	}
+	if diff := cmp.Diff([]string{""}, resourceVersions); diff != "" {
+		t.Errorf("Expected the objects to be cleaned, diff (-want +got): %s", diff)
+	}
+	for _, opts := range patchOpts {
+		if diff := cmp.Diff([]string{metav1.DryRunAll}, opts.DryRun); diff != "" {
+			t.Errorf("Expected a server dry run, diff (-want +got): %s", diff)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+var backendLBPolicyGVK = schema.GroupVersionKind{
+	Group:   "gateway.networking.k8s.io",
+	Version: "v1alpha2",
+	Kind:    "BackendLBPolicy",
+}
+
+// setBackendLBPolicies converts the session affinity of Ingresses to the
+// session persistence of their backend Services. Only cookies and headers
+// identify sessions in Gateway API. The first Ingress using a Service
+// configures its policy.
+func setBackendLBPolicies(result *ir.IR) []Notification {
+	var notes []Notification
+	index := map[types.NamespacedName]int{}
+	sources := map[types.NamespacedName]types.NamespacedName{}
+	unconverted := map[types.NamespacedName]bool{}
+
+	for _, route := range result.HTTPRoutes {
+		for _, rule := range route.Rules {
+			for _, backend := range rule.Backends {
+				hash := route.Policies[backend.Source].ConsistentHash
+				if hash == nil || backend.Kind != nil && *backend.Kind != "Service" {
+					continue
+				}
+				persistence, err := toSessionPersistence(hash)
+				if err != nil {
+					if !unconverted[backend.Source] {
+						unconverted[backend.Source] = true
+						notes = append(notes, notifications.NewWarning("Session affinity of Ingress %s is not converted: %v", backend.Source, err))
+					}
+					continue
+				}
+
+				service := types.NamespacedName{Namespace: route.Namespace, Name: string(backend.Name)}
+				if backend.Namespace != nil {
+					service.Namespace = string(*backend.Namespace)
+				}
+				i, ok := index[service]
+				if !ok {
+					index[service] = len(result.BackendLBPolicies)
+					sources[service] = backend.Source
+					result.BackendLBPolicies = append(result.BackendLBPolicies, ir.BackendLBPolicy{
+						Namespace:          service.Namespace,
+						Service:            service.Name,
+						SessionPersistence: *persistence,
+					})
+					continue
+				}
+				if !reflect.DeepEqual(result.BackendLBPolicies[i].SessionPersistence, *persistence) && sources[service] != backend.Source {
+					notes = append(notes, notifications.NewWarning("Ingresses %s and %s configure different session affinity for Service %s, the BackendLBPolicy uses the one of %s", sources[service], backend.Source, service, sources[service]))
+				}
+			}
+		}
+	}
+	return notes
+}
+
+func toSessionPersistence(hash *ir.ConsistentHash) (*gatewayv1.SessionPersistence, error) {
+	switch {
+	case hash.Cookie != nil:
+		if hash.Cookie.Path != "" {
+			return nil, fmt.Errorf("the path of session cookies can't be set")
+		}
+		name := hash.Cookie.Name
+		persistenceType := gatewayv1.CookieBasedSessionPersistence
+		lifetimeType := gatewayv1.SessionCookieLifetimeType
+		persistence := &gatewayv1.SessionPersistence{
+			SessionName:  &name,
+			Type:         &persistenceType,
+			CookieConfig: &gatewayv1.CookieConfig{LifetimeType: &lifetimeType},
+		}
+		if hash.Cookie.TTL != 0 {
+			timeout := formatDuration(hash.Cookie.TTL)
+			persistence.AbsoluteTimeout = &timeout
+			lifetimeType = gatewayv1.PermanentCookieLifetimeType
+		}
+		return persistence, nil
+	case hash.Header != "":
+		name := hash.Header
+		persistenceType := gatewayv1.HeaderBasedSessionPersistence
+		return &gatewayv1.SessionPersistence{SessionName: &name, Type: &persistenceType}, nil
+	case hash.QueryParameter != "":
+		return nil, fmt.Errorf("sessions can't be identified by query parameters")
+	default:
+		return nil, fmt.Errorf("sessions can't be identified by client IP addresses")
+	}
+}
+
+func emitBackendLBPolicies(result ir.IR) []gatewayv1alpha2.BackendLBPolicy {
+	var policies []gatewayv1alpha2.BackendLBPolicy
+	for _, p := range result.BackendLBPolicies {
+		persistence := p.SessionPersistence
+		policy := gatewayv1alpha2.BackendLBPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: p.Namespace, Name: p.Service},
+			Spec: gatewayv1alpha2.BackendLBPolicySpec{
+				TargetRefs: []gatewayv1alpha2.LocalPolicyTargetReference{{
+					Group: "",
+					Kind:  "Service",
+					Name:  gatewayv1.ObjectName(p.Service),
+				}},
+				SessionPersistence: &persistence,
+			},
+		}
+		policy.SetGroupVersionKind(backendLBPolicyGVK)
+		policies = append(policies, policy)
+	}
+	return policies
+}
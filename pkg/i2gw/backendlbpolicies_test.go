@@ -0,0 +1,106 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_setBackendLBPolicies(t *testing.T) {
+	source := types.NamespacedName{Namespace: "default", Name: "web"}
+	sessionName := "route"
+	cookieType := gatewayv1.CookieBasedSessionPersistence
+	headerType := gatewayv1.HeaderBasedSessionPersistence
+	permanent := gatewayv1.PermanentCookieLifetimeType
+	session := gatewayv1.SessionCookieLifetimeType
+	timeout := gatewayv1.Duration("3600s")
+
+	testCases := []struct {
+		name          string
+		hash          *ir.ConsistentHash
+		expected      []ir.BackendLBPolicy
+		expectedNotes int
+	}{{
+		name: "no session affinity",
+	}, {
+		name: "cookie with max age",
+		hash: &ir.ConsistentHash{Cookie: &ir.HashCookie{Name: "route", TTL: time.Hour}},
+		expected: []ir.BackendLBPolicy{{
+			Namespace: "default",
+			Service:   "web",
+			SessionPersistence: gatewayv1.SessionPersistence{
+				SessionName:     &sessionName,
+				Type:            &cookieType,
+				AbsoluteTimeout: &timeout,
+				CookieConfig:    &gatewayv1.CookieConfig{LifetimeType: &permanent},
+			},
+		}},
+	}, {
+		name: "session cookie",
+		hash: &ir.ConsistentHash{Cookie: &ir.HashCookie{Name: "route"}},
+		expected: []ir.BackendLBPolicy{{
+			Namespace: "default",
+			Service:   "web",
+			SessionPersistence: gatewayv1.SessionPersistence{
+				SessionName:  &sessionName,
+				Type:         &cookieType,
+				CookieConfig: &gatewayv1.CookieConfig{LifetimeType: &session},
+			},
+		}},
+	}, {
+		name: "header",
+		hash: &ir.ConsistentHash{Header: "route"},
+		expected: []ir.BackendLBPolicy{{
+			Namespace:          "default",
+			Service:            "web",
+			SessionPersistence: gatewayv1.SessionPersistence{SessionName: &sessionName, Type: &headerType},
+		}},
+	}, {
+		name:          "client IP address",
+		hash:          &ir.ConsistentHash{SourceIP: true},
+		expectedNotes: 1,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := ir.IR{HTTPRoutes: []ir.HTTPRoute{{
+				Namespace: "default",
+				Name:      "web-example-com",
+				Rules: []ir.HTTPRouteRule{{
+					Backends: []ir.Backend{{
+						BackendRef: gatewayv1.BackendRef{BackendObjectReference: gatewayv1.BackendObjectReference{Name: "web"}},
+						Source:     source,
+					}},
+				}},
+				Policies: map[types.NamespacedName]ir.Policy{source: {ConsistentHash: tc.hash}},
+			}}}
+			notes := setBackendLBPolicies(&result)
+
+			if diff := cmp.Diff(tc.expected, result.BackendLBPolicies); diff != "" {
+				t.Errorf("Unexpected BackendLBPolicies, diff (-want +got): %s", diff)
+			}
+			if len(notes) != tc.expectedNotes {
+				t.Errorf("Expected %d notifications, got %d: %v", tc.expectedNotes, len(notes), notes)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// checkBackendProtocol reports the Service ports of the backends of an
+// Ingress whose appProtocol makes Gateway API implementations connect to them
+// with another protocol than its controller: the ports of the backends of
+// Ingresses connecting with HTTP/2, such as for gRPC, that are not marked for
+// it, and the ports marked for HTTP/2 that the controller connects to with
+// HTTP/1.1.
+func (a *ingressAggregator) checkBackendProtocol(ingress networkingv1.Ingress, features *ir.IngressFeatures) {
+	var want, protocol string
+	if features.BackendProtocol != nil {
+		want, protocol = features.BackendProtocol.AppProtocol, features.BackendProtocol.Name
+		if want == "" {
+			return
+		}
+	}
+
+	var unknown []string
+	seen := map[string]bool{}
+	check := func(backend *networkingv1.IngressBackend) {
+		if backend == nil || backend.Service == nil {
+			return
+		}
+		service := types.NamespacedName{Namespace: ingress.Namespace, Name: backend.Service.Name}
+		port := servicePortName(backend.Service.Port)
+		if seen[service.String()+":"+port] {
+			return
+		}
+		seen[service.String()+":"+port] = true
+		ports, ok := a.serviceSpecPorts[service]
+		if !ok {
+			if want != "" {
+				unknown = append(unknown, service.String()+":"+port)
+			}
+			return
+		}
+		servicePort, ok := findServicePort(ports, backend.Service.Port)
+		if !ok {
+			return
+		}
+		appProtocol := "no appProtocol"
+		if servicePort.AppProtocol != nil {
+			appProtocol = "appProtocol " + *servicePort.AppProtocol
+		}
+		switch {
+		case want != "" && appProtocol != "appProtocol "+want:
+			a.notifications = append(a.notifications, notifications.NewWarning("Ingress %s/%s connects to Service %s with %s, but port %s of the Service has %s, set it to %s for Gateway API implementations to do so too", ingress.Namespace, ingress.Name, service, protocol, port, appProtocol, want))
+		case want == "" && appProtocol == "appProtocol "+ir.AppProtocolH2C:
+			a.notifications = append(a.notifications, notifications.NewWarning("Port %s of Service %s has %s, so Gateway API implementations connect to it with HTTP/2 while the controller of Ingress %s/%s uses HTTP/1.1", port, service, appProtocol, ingress.Namespace, ingress.Name))
+		}
+	}
+	check(ingress.Spec.DefaultBackend)
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for i := range rule.HTTP.Paths {
+			check(&rule.HTTP.Paths[i].Backend)
+		}
+	}
+	if len(unknown) > 0 {
+		a.notifications = append(a.notifications, notifications.NewInfo("Ingress %s/%s connects to its backends with %s, set the appProtocol of their Service ports to %s for Gateway API implementations to do so too: %s", ingress.Namespace, ingress.Name, protocol, want, strings.Join(unknown, ", ")))
+	}
+}
+
+func servicePortName(port networkingv1.ServiceBackendPort) string {
+	if port.Name != "" {
+		return port.Name
+	}
+	return fmt.Sprint(port.Number)
+}
+
+func findServicePort(ports []corev1.ServicePort, port networkingv1.ServiceBackendPort) (corev1.ServicePort, bool) {
+	for _, p := range ports {
+		if (port.Name != "" && p.Name == port.Name) || (port.Name == "" && p.Port == port.Number) {
+			return p, true
+		}
+	}
+	return corev1.ServicePort{}, false
+}
@@ -0,0 +1,284 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// backendKind is the group and kind of the resource of an Ingress backend
+// or of a backendRef.
+type backendKind struct {
+	group string
+	kind  string
+}
+
+func (k backendKind) String() string {
+	if k.group == "" {
+		return k.kind
+	}
+	return fmt.Sprintf("%s %s", k.kind, k.group)
+}
+
+// backendKinds maps the kinds of the resource backends a target
+// implementation routes to to the kinds of their backendRefs.
+type backendKinds map[backendKind]backendKind
+
+// backendKindTranslations are the kinds of the backendRefs the resource
+// backends of a kind can be routed with instead, by kind, in order of
+// preference.
+type backendKindTranslations map[backendKind][]backendKind
+
+// providerBackendKinds returns the backend kind translations of the
+// providers, the ones of the first providers being preferred.
+func providerBackendKinds(providers []Provider) backendKindTranslations {
+	translations := backendKindTranslations{}
+	for _, p := range providers {
+		translator, ok := p.(BackendKindTranslator)
+		if !ok {
+			continue
+		}
+		for from, to := range translator.BackendKinds() {
+			kind := backendKind{group: from.Group, kind: from.Kind}
+			for _, t := range to {
+				translations[kind] = append(translations[kind], backendKind{group: t.Group, kind: t.Kind})
+			}
+		}
+	}
+	return translations
+}
+
+// route returns the kind of the backendRefs of the resource backends of a
+// kind the target implementation routes to, directly or through one of
+// their translations.
+func (kinds backendKinds) route(kind backendKind, translations backendKindTranslations) (backendKind, bool) {
+	if mapped, ok := kinds[kind]; ok {
+		return mapped, true
+	}
+	for _, t := range translations[kind] {
+		if mapped, ok := kinds[t]; ok {
+			return mapped, true
+		}
+	}
+	return backendKind{}, false
+}
+
+var (
+	serviceBackendKind       = backendKind{kind: "Service"}
+	serviceImportBackendKind = backendKind{group: "multicluster.x-k8s.io", kind: "ServiceImport"}
+
+	gkeServiceImportBackendKind = backendKind{group: "net.gke.io", kind: "ServiceImport"}
+	envoyBackendKind            = backendKind{group: "gateway.envoyproxy.io", kind: "Backend"}
+)
+
+var (
+	serviceGVK          = schema.GroupVersionKind{Version: "v1", Kind: "Service"}
+	serviceImportGVK    = schema.GroupVersionKind{Group: "multicluster.x-k8s.io", Version: "v1alpha1", Kind: "ServiceImport"}
+	gkeServiceImportGVK = schema.GroupVersionKind{Group: "net.gke.io", Version: "v1", Kind: "ServiceImport"}
+)
+
+// serviceImportBackendKinds are the kinds of the ServiceImports of
+// multi-cluster Services.
+var serviceImportBackendKinds = []backendKind{serviceImportBackendKind, gkeServiceImportBackendKind}
+
+// targetBackendKinds returns the backend kinds of a target implementation,
+// or nil without one, in which case resource backends are passed through.
+func targetBackendKinds(name string) backendKinds {
+	if name == "" {
+		return nil
+	}
+	kinds := targetImplementations[name].backendKinds
+	if kinds == nil {
+		return backendKinds{}
+	}
+	return kinds
+}
+
+// toBackendRef returns the backendRef of an Ingress backend. Without a
+// target implementation, whose kinds are nil, the kinds of resource
+// backends are kept as is.
+func toBackendRef(ib networkingv1.IngressBackend, kinds backendKinds, translations backendKindTranslations) (*gatewayv1.BackendRef, error) {
+	if ib.Service != nil {
+		if ib.Service.Port.Name != "" {
+			return nil, &NamedPortUnresolvedError{Service: ib.Service.Name, Port: ib.Service.Port.Name}
+		}
+		return &gatewayv1.BackendRef{
+			BackendObjectReference: gatewayv1.BackendObjectReference{
+				Name: gatewayv1.ObjectName(ib.Service.Name),
+				Port: (*gatewayv1.PortNumber)(&ib.Service.Port.Number),
+			},
+		}, nil
+	}
+
+	kind := backendKind{kind: ib.Resource.Kind}
+	if ib.Resource.APIGroup != nil {
+		kind.group = *ib.Resource.APIGroup
+	}
+	if kind == serviceBackendKind {
+		// backendRefs of Services need a port, which resource backends
+		// don't have.
+		return nil, &InvalidBackendError{Kind: kind.String(), Name: ib.Resource.Name, Reason: "has no port, use a service backend instead"}
+	}
+	if kinds != nil {
+		mapped, ok := kinds.route(kind, translations)
+		if !ok {
+			return nil, &InvalidBackendError{Kind: kind.String(), Name: ib.Resource.Name, Reason: "isn't routable by the target implementation, " + kinds.supported()}
+		}
+		kind = mapped
+	}
+	group, k := gatewayv1.Group(kind.group), gatewayv1.Kind(kind.kind)
+	return &gatewayv1.BackendRef{
+		BackendObjectReference: gatewayv1.BackendObjectReference{
+			Group: &group,
+			Kind:  &k,
+			Name:  gatewayv1.ObjectName(ib.Resource.Name),
+		},
+	}, nil
+}
+
+// backendResolver resolves the backends of Ingresses to backendRefs, using
+// the Services and ServiceImports of the input.
+type backendResolver struct {
+	kinds        backendKinds
+	translations backendKindTranslations
+	// servicePorts are the first ports of the Services of the input.
+	servicePorts map[types.NamespacedName]int32
+	// serviceImports are the kinds of the ServiceImports of the input.
+	serviceImports map[types.NamespacedName]backendKind
+	// externalNames are the external hosts of the ExternalName Services of
+	// the input, routed to the externalNameKind backends when it is set.
+	externalNames    map[types.NamespacedName]string
+	externalNameKind backendKind
+}
+
+// toBackendRef returns the backendRef of an Ingress backend of namespace.
+// Service backends without a Service in the input are routed to the
+// ServiceImport of the same name, if any and the target implementation
+// routes to it, since the multi-cluster Service has no local Service.
+// Backends of ExternalName Services are routed to the external backends
+// of the target implementation, when enabled.
+func (r backendResolver) toBackendRef(namespace string, ib networkingv1.IngressBackend) (*gatewayv1.BackendRef, error) {
+	backendRef, err := toBackendRef(ib, r.kinds, r.translations)
+	if err != nil || ib.Service == nil {
+		return backendRef, err
+	}
+	service := types.NamespacedName{Namespace: namespace, Name: ib.Service.Name}
+	if kind, ok := r.serviceImport(service); ok {
+		if mapped, ok := r.kinds.route(kind, r.translations); ok {
+			group, k := gatewayv1.Group(mapped.group), gatewayv1.Kind(mapped.kind)
+			backendRef.Group, backendRef.Kind = &group, &k
+		}
+	}
+	if _, ok := r.externalNames[service]; ok && r.externalNameKind != (backendKind{}) {
+		group, k := gatewayv1.Group(r.externalNameKind.group), gatewayv1.Kind(r.externalNameKind.kind)
+		backendRef.Group, backendRef.Kind = &group, &k
+		backendRef.Name = gatewayv1.ObjectName(externalBackendName(ib.Service.Name, ib.Service.Port.Number))
+	}
+	return backendRef, nil
+}
+
+// serviceImport returns the kind of the ServiceImport of a Service that
+// isn't part of the input.
+func (r backendResolver) serviceImport(service types.NamespacedName) (backendKind, bool) {
+	if _, ok := r.servicePorts[service]; ok {
+		return backendKind{}, false
+	}
+	kind, ok := r.serviceImports[service]
+	return kind, ok
+}
+
+// unroutedServiceImportNotifications warns about the Service backends of
+// Ingresses routed to Services that don't exist, when their ServiceImports
+// can't be routed to instead.
+func (a *ingressAggregator) unroutedServiceImportNotifications() []Notification {
+	r := a.backendResolver()
+	var notes []Notification
+	seen := map[types.NamespacedName]bool{}
+	check := func(namespace, ingressName string, ib *networkingv1.IngressBackend) {
+		if ib == nil || ib.Service == nil {
+			return
+		}
+		service := types.NamespacedName{Namespace: namespace, Name: ib.Service.Name}
+		kind, ok := r.serviceImport(service)
+		if !ok || seen[service] {
+			return
+		}
+		if _, ok := r.kinds.route(kind, r.translations); ok {
+			return
+		}
+		seen[service] = true
+		var routing []string
+		for _, name := range TargetImplementations() {
+			if _, ok := targetImplementations[name].backendKinds.route(kind, r.translations); ok {
+				routing = append(routing, name)
+			}
+		}
+		msg := fmt.Sprintf("Service %s, a backend of Ingress %s/%s, isn't part of the input but %s %s is, the backendRef points to the Service", service, namespace, ingressName, kind, service)
+		if len(routing) > 0 {
+			msg += fmt.Sprintf(", use --target-implementation=%s to route to the ServiceImport", strings.Join(routing, "|"))
+		}
+		notes = append(notes, notifications.NewWarning("%s", msg))
+	}
+	a.forEachBackend(check)
+	return notes
+}
+
+// forEachBackend calls fn with the backends of the paths of the rule groups
+// and the default backends of the Ingresses.
+func (a *ingressAggregator) forEachBackend(fn func(namespace, ingressName string, ib *networkingv1.IngressBackend)) {
+	for _, key := range a.sortedRuleGroupKeys() {
+		rg := a.ruleGroups[key]
+		for _, rule := range rg.rules {
+			if rule.rule.HTTP == nil {
+				continue
+			}
+			for i := range rule.rule.HTTP.Paths {
+				fn(rg.namespace, rule.ingressName, &rule.rule.HTTP.Paths[i].Backend)
+			}
+		}
+	}
+	for _, db := range a.defaultBackends {
+		fn(db.namespace, db.name, &db.backend)
+	}
+}
+
+func (a *ingressAggregator) backendResolver() backendResolver {
+	r := backendResolver{kinds: a.backendKinds, translations: a.backendTranslations, servicePorts: a.servicePorts, serviceImports: a.serviceImports, externalNames: a.externalNames}
+	if a.externalNameBackends {
+		r.externalNameKind = a.externalNameKind
+	}
+	return r
+}
+
+func (kinds backendKinds) supported() string {
+	if len(kinds) == 0 {
+		return "which only routes to Services"
+	}
+	var names []string
+	for kind := range kinds {
+		names = append(names, kind.String())
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("which routes to Services and: %s", strings.Join(names, ", "))
+}
@@ -0,0 +1,252 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/targets/envoygateway"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/targets/gke"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/targets/nginxgatewayfabric"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_toBackendRef(t *testing.T) {
+	resourceBackend := func(group, kind string) networkingv1.IngressBackend {
+		ref := &corev1.TypedLocalObjectReference{Kind: kind, Name: "backend"}
+		if group != "" {
+			ref.APIGroup = &group
+		}
+		return networkingv1.IngressBackend{Resource: ref}
+	}
+	backendRef := func(group, kind string) *gatewayv1.BackendRef {
+		return &gatewayv1.BackendRef{BackendObjectReference: gatewayv1.BackendObjectReference{
+			Group: ptrTo(gatewayv1.Group(group)),
+			Kind:  ptrTo(gatewayv1.Kind(kind)),
+			Name:  "backend",
+		}}
+	}
+
+	storageBucket := backendKind{group: "vendor.example.com", kind: "StorageBucket"}
+	translations := backendKindTranslations{storageBucket: {gkeServiceImportBackendKind, envoyBackendKind}}
+
+	testCases := []struct {
+		name         string
+		target       string
+		translations backendKindTranslations
+		backend      networkingv1.IngressBackend
+		expected     *gatewayv1.BackendRef
+		expectedErr  string
+	}{{
+		name:    "service backend",
+		target:  nginxgatewayfabric.Name,
+		backend: serviceBackend("web", 80),
+		expected: &gatewayv1.BackendRef{BackendObjectReference: gatewayv1.BackendObjectReference{
+			Name: "web",
+			Port: portNumberPtr(80),
+		}},
+	}, {
+		name:     "any resource backend without target implementation",
+		backend:  resourceBackend("vendor.example.com", "StorageBucket"),
+		expected: backendRef("vendor.example.com", "StorageBucket"),
+	}, {
+		name:     "routable resource backend",
+		target:   envoygateway.Name,
+		backend:  resourceBackend("gateway.envoyproxy.io", "Backend"),
+		expected: backendRef("gateway.envoyproxy.io", "Backend"),
+	}, {
+		name:     "mapped resource backend",
+		target:   gke.Name,
+		backend:  resourceBackend("multicluster.x-k8s.io", "ServiceImport"),
+		expected: backendRef("net.gke.io", "ServiceImport"),
+	}, {
+		name:        "unroutable resource backend",
+		target:      gke.Name,
+		backend:     resourceBackend("vendor.example.com", "StorageBucket"),
+		expectedErr: "resource backend StorageBucket vendor.example.com backend isn't routable by the target implementation, which routes to Services and: ServiceImport multicluster.x-k8s.io, ServiceImport net.gke.io",
+	}, {
+		name:         "translated resource backend",
+		target:       envoygateway.Name,
+		translations: translations,
+		backend:      resourceBackend("vendor.example.com", "StorageBucket"),
+		expected:     backendRef("gateway.envoyproxy.io", "Backend"),
+	}, {
+		name:         "translated resource backend without target implementation",
+		translations: translations,
+		backend:      resourceBackend("vendor.example.com", "StorageBucket"),
+		expected:     backendRef("vendor.example.com", "StorageBucket"),
+	}, {
+		name:         "translation the target implementation doesn't route",
+		target:       nginxgatewayfabric.Name,
+		translations: translations,
+		backend:      resourceBackend("vendor.example.com", "StorageBucket"),
+		expectedErr:  "resource backend StorageBucket vendor.example.com backend isn't routable by the target implementation, which only routes to Services",
+	}, {
+		name:        "target implementation without resource backends",
+		target:      nginxgatewayfabric.Name,
+		backend:     resourceBackend("gateway.envoyproxy.io", "Backend"),
+		expectedErr: "resource backend Backend gateway.envoyproxy.io backend isn't routable by the target implementation, which only routes to Services",
+	}, {
+		name:        "service resource backend",
+		backend:     resourceBackend("", "Service"),
+		expectedErr: "resource backend Service backend has no port, use a service backend instead",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := toBackendRef(tc.backend, targetBackendKinds(tc.target), tc.translations)
+			var gotErr string
+			if err != nil {
+				gotErr = err.Error()
+			}
+			if gotErr != tc.expectedErr {
+				t.Fatalf("Expected error %q, got %q", tc.expectedErr, gotErr)
+			}
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Errorf("Unexpected backendRef, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func Test_serviceImportBackends(t *testing.T) {
+	serviceImport := func(apiVersion, name string) unstructured.Unstructured {
+		obj := unstructured.Unstructured{}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind("ServiceImport")
+		obj.SetNamespace("test")
+		obj.SetName(name)
+		return obj
+	}
+	service := unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   map[string]interface{}{"namespace": "test", "name": "local"},
+		"spec":       map[string]interface{}{"ports": []interface{}{map[string]interface{}{"port": int64(80)}}},
+	}}
+	objects := []unstructured.Unstructured{service, serviceImport("multicluster.x-k8s.io/v1alpha1", "global"), serviceImport("multicluster.x-k8s.io/v1alpha1", "local")}
+
+	testCases := []struct {
+		name                string
+		target              string
+		backend             string
+		expectedGroup       string
+		expectedKind        string
+		expectNotifications []string
+	}{{
+		name:          "ServiceImport without local Service",
+		target:        gke.Name,
+		backend:       "global",
+		expectedGroup: "net.gke.io",
+		expectedKind:  "ServiceImport",
+	}, {
+		name:    "local Service",
+		target:  gke.Name,
+		backend: "local",
+	}, {
+		name:    "target implementation not routing to ServiceImports",
+		target:  nginxgatewayfabric.Name,
+		backend: "global",
+		expectNotifications: []string{
+			"WARNING: Service test/global, a backend of Ingress test/web, isn't part of the input but ServiceImport multicluster.x-k8s.io test/global is, the backendRef points to the Service, use --target-implementation=envoy-gateway|gke to route to the ServiceImport",
+		},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			iPrefix := networkingv1.PathTypePrefix
+			aggregator := newIngressAggregator(builtinProviders())
+			aggregator.backendKinds = targetBackendKinds(tc.target)
+			aggregator.addServices(objects)
+			aggregator.addIngress(ingressWithPath("web", "/", &iPrefix, serviceBackend(tc.backend, 80), nil))
+			httpRoutes, _, errs := aggregator.toHTTPRoutesAndGateways()
+			if len(errs) > 0 || len(httpRoutes) != 1 {
+				t.Fatalf("Expected 1 HTTPRoute and no errors, got %+v, %v", httpRoutes, errs)
+			}
+
+			ref := httpRoutes[0].Spec.Rules[0].BackendRefs[0].BackendObjectReference
+			var gotGroup, gotKind string
+			if ref.Group != nil {
+				gotGroup = string(*ref.Group)
+			}
+			if ref.Kind != nil {
+				gotKind = string(*ref.Kind)
+			}
+			if gotGroup != tc.expectedGroup || gotKind != tc.expectedKind || *ref.Port != 80 {
+				t.Errorf("Expected backendRef of kind %q of group %q on port 80, got %+v", tc.expectedKind, tc.expectedGroup, ref)
+			}
+
+			var gotNotifications []string
+			for _, n := range aggregator.notifications {
+				gotNotifications = append(gotNotifications, n.String())
+			}
+			if diff := cmp.Diff(tc.expectNotifications, gotNotifications); diff != "" {
+				t.Errorf("Unexpected notifications, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+type backendKindProvider struct{}
+
+func (backendKindProvider) Name() string       { return "backend-kinds" }
+func (backendKindProvider) Controller() string { return "example.com/backend-kinds" }
+func (backendKindProvider) ParseIngress(networkingv1.Ingress) (ir.IngressFeatures, []notifications.Notification) {
+	return ir.IngressFeatures{}, nil
+}
+func (backendKindProvider) BackendKinds() map[BackendKind][]BackendKind {
+	return map[BackendKind][]BackendKind{
+		{Group: "vendor.example.com", Kind: "StorageBucket"}: {{Group: "gateway.envoyproxy.io", Kind: "Backend"}},
+	}
+}
+
+func Test_providerBackendKinds(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	group := "vendor.example.com"
+	ingress := ingressWithPath("bucket", "/", &iPrefix, networkingv1.IngressBackend{
+		Resource: &corev1.TypedLocalObjectReference{APIGroup: &group, Kind: "StorageBucket", Name: "assets"},
+	}, nil)
+
+	resources, report := convertInput(inputResources{ingresses: []networkingv1.Ingress{ingress}}, ConvertOptions{
+		TargetImplementation: envoygateway.Name,
+		Providers:            []Provider{backendKindProvider{}},
+	})
+	if len(report.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", report.Errors)
+	}
+	var got []gatewayv1.BackendObjectReference
+	for _, route := range resources.HTTPRoutes {
+		for _, rule := range route.Spec.Rules {
+			for _, br := range rule.BackendRefs {
+				got = append(got, br.BackendObjectReference)
+			}
+		}
+	}
+	want := []gatewayv1.BackendObjectReference{{
+		Group: ptrTo(gatewayv1.Group("gateway.envoyproxy.io")),
+		Kind:  ptrTo(gatewayv1.Kind("Backend")),
+		Name:  "assets",
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected backendRefs (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1alpha3 "sigs.k8s.io/gateway-api/apis/v1alpha3"
+)
+
+var backendTLSPolicyGVK = schema.GroupVersionKind{
+	Group:   "gateway.networking.k8s.io",
+	Version: "v1alpha3",
+	Kind:    "BackendTLSPolicy",
+}
+
+// setBackendTLSPolicies converts the verification of backend certificates
+// of Ingresses to policies of their backend Services. BackendTLSPolicies
+// can only reference CA certificates in the namespace of the Service. The
+// first Ingress using a Service configures its policy.
+func setBackendTLSPolicies(result *ir.IR) []Notification {
+	var notes []Notification
+	index := map[types.NamespacedName]int{}
+	sources := map[types.NamespacedName]types.NamespacedName{}
+	unconverted := map[types.NamespacedName]bool{}
+
+	for _, route := range result.HTTPRoutes {
+		for _, rule := range route.Rules {
+			for _, backend := range rule.Backends {
+				backendTLS := route.Policies[backend.Source].BackendTLS
+				if backendTLS == nil || backend.Kind != nil && *backend.Kind != "Service" {
+					continue
+				}
+				service := types.NamespacedName{Namespace: route.Namespace, Name: string(backend.Name)}
+				if backend.Namespace != nil {
+					service.Namespace = string(*backend.Namespace)
+				}
+				if backendTLS.CACertificates.Namespace != service.Namespace {
+					if !unconverted[backend.Source] {
+						unconverted[backend.Source] = true
+						notes = append(notes, notifications.NewWarning("Ingress %s verifies the certificates of Service %s with CA certificates of Secret %s, which a BackendTLSPolicy can only reference in namespace %s", backend.Source, service, backendTLS.CACertificates, service.Namespace))
+					}
+					continue
+				}
+				policy := ir.BackendTLSPolicy{
+					Namespace:      service.Namespace,
+					Service:        service.Name,
+					CACertificates: backendTLS.CACertificates.Name,
+					Hostname:       backendTLS.Hostname,
+				}
+				if policy.Hostname == "" {
+					policy.Hostname = fmt.Sprintf("%s.%s.svc", service.Name, service.Namespace)
+				}
+
+				i, ok := index[service]
+				if !ok {
+					index[service] = len(result.BackendTLSPolicies)
+					sources[service] = backend.Source
+					result.BackendTLSPolicies = append(result.BackendTLSPolicies, policy)
+					if backendTLS.Hostname == "" {
+						notes = append(notes, notifications.NewInfo("BackendTLSPolicy %s/%s requires the certificates of Service %s to be valid for %s, the server name of Ingress %s isn't set", service.Namespace, service.Name, service, policy.Hostname, backend.Source))
+					}
+					notes = append(notes, notifications.NewInfo("BackendTLSPolicy %s/%s reads CA certificates from Secret %s, which only some implementations support instead of a ConfigMap", service.Namespace, service.Name, backendTLS.CACertificates))
+					continue
+				}
+				if result.BackendTLSPolicies[i] != policy && sources[service] != backend.Source {
+					notes = append(notes, notifications.NewWarning("Ingresses %s and %s verify the certificates of Service %s differently, the BackendTLSPolicy uses the settings of %s", sources[service], backend.Source, service, sources[service]))
+				}
+			}
+		}
+	}
+	return notes
+}
+
+func emitBackendTLSPolicies(result ir.IR) []gatewayv1alpha3.BackendTLSPolicy {
+	var policies []gatewayv1alpha3.BackendTLSPolicy
+	for _, p := range result.BackendTLSPolicies {
+		policy := gatewayv1alpha3.BackendTLSPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: p.Namespace, Name: p.Service},
+			Spec: gatewayv1alpha3.BackendTLSPolicySpec{
+				TargetRefs: []gatewayv1alpha2.LocalPolicyTargetReferenceWithSectionName{{
+					LocalPolicyTargetReference: gatewayv1alpha2.LocalPolicyTargetReference{
+						Group: "",
+						Kind:  "Service",
+						Name:  gatewayv1.ObjectName(p.Service),
+					},
+				}},
+				Validation: gatewayv1alpha3.BackendTLSPolicyValidation{
+					CACertificateRefs: []gatewayv1.LocalObjectReference{{
+						Group: "",
+						Kind:  "Secret",
+						Name:  gatewayv1.ObjectName(p.CACertificates),
+					}},
+					Hostname: gatewayv1.PreciseHostname(p.Hostname),
+				},
+			},
+		}
+		policy.SetGroupVersionKind(backendTLSPolicyGVK)
+		policies = append(policies, policy)
+	}
+	return policies
+}
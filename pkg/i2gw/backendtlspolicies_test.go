@@ -0,0 +1,89 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_setBackendTLSPolicies(t *testing.T) {
+	source := types.NamespacedName{Namespace: "default", Name: "web"}
+
+	testCases := []struct {
+		name          string
+		backendTLS    *ir.BackendTLS
+		expected      []ir.BackendTLSPolicy
+		expectedNotes int
+	}{{
+		name: "no backend certificate verification",
+	}, {
+		name:       "hostname",
+		backendTLS: &ir.BackendTLS{CACertificates: types.NamespacedName{Namespace: "default", Name: "web-ca"}, Hostname: "web.internal.example.com"},
+		expected: []ir.BackendTLSPolicy{{
+			Namespace:      "default",
+			Service:        "web",
+			CACertificates: "web-ca",
+			Hostname:       "web.internal.example.com",
+		}},
+		expectedNotes: 1,
+	}, {
+		name:       "DNS name of the Service",
+		backendTLS: &ir.BackendTLS{CACertificates: types.NamespacedName{Namespace: "default", Name: "web-ca"}},
+		expected: []ir.BackendTLSPolicy{{
+			Namespace:      "default",
+			Service:        "web",
+			CACertificates: "web-ca",
+			Hostname:       "web.default.svc",
+		}},
+		expectedNotes: 2,
+	}, {
+		name:          "CA certificates in another namespace",
+		backendTLS:    &ir.BackendTLS{CACertificates: types.NamespacedName{Namespace: "certs", Name: "web-ca"}},
+		expectedNotes: 1,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := ir.IR{HTTPRoutes: []ir.HTTPRoute{{
+				Namespace: "default",
+				Name:      "web-example-com",
+				Rules: []ir.HTTPRouteRule{{
+					Backends: []ir.Backend{{
+						BackendRef: gatewayv1.BackendRef{BackendObjectReference: gatewayv1.BackendObjectReference{Name: "web"}},
+						Source:     source,
+					}, {
+						BackendRef: gatewayv1.BackendRef{BackendObjectReference: gatewayv1.BackendObjectReference{Name: "web"}},
+						Source:     source,
+					}},
+				}},
+				Policies: map[types.NamespacedName]ir.Policy{source: {BackendTLS: tc.backendTLS}},
+			}}}
+			notes := setBackendTLSPolicies(&result)
+
+			if diff := cmp.Diff(tc.expected, result.BackendTLSPolicies); diff != "" {
+				t.Errorf("Unexpected BackendTLSPolicies, diff (-want +got): %s", diff)
+			}
+			if len(notes) != tc.expectedNotes {
+				t.Errorf("Expected %d notifications, got %d: %v", tc.expectedNotes, len(notes), notes)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+// benchmarkIngresses returns Ingresses with ten paths each, their hosts
+// shared by ingressesPerHost Ingresses.
+func benchmarkIngresses(paths, ingressesPerHost int) []networkingv1.Ingress {
+	prefix := networkingv1.PathTypePrefix
+	ingresses := make([]networkingv1.Ingress, 0, paths/10)
+	for i := 0; i < paths/10; i++ {
+		ingress := ingressWithPath(fmt.Sprintf("ingress-%d", i), "/", &prefix, serviceBackend(fmt.Sprintf("svc-%d", i), 80), nil)
+		ingress.Spec.Rules[0].Host = fmt.Sprintf("host-%d.example.com", i/ingressesPerHost)
+		path := ingress.Spec.Rules[0].HTTP.Paths[0]
+		ingress.Spec.Rules[0].HTTP.Paths = nil
+		for j := 0; j < 10; j++ {
+			path.Path = fmt.Sprintf("/app-%d/path-%d", i, j)
+			ingress.Spec.Rules[0].HTTP.Paths = append(ingress.Spec.Rules[0].HTTP.Paths, path)
+		}
+		ingresses = append(ingresses, ingress)
+	}
+	return ingresses
+}
+
+func BenchmarkConvert(b *testing.B) {
+	for _, paths := range []int{1000, 10000, 50000} {
+		for _, ingressesPerHost := range []int{1, 100} {
+			ingresses := benchmarkIngresses(paths, ingressesPerHost)
+			b.Run(fmt.Sprintf("paths=%d/ingresses-per-host=%d", paths, ingressesPerHost), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, _, err := Convert(context.Background(), ConvertOptions{Ingresses: ingresses}); err != nil {
+						b.Fatalf("Unexpected error: %v", err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkNameFromHost(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		nameFromHost("*.Shop-eu.Example.com")
+	}
+}
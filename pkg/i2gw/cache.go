@@ -0,0 +1,169 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ConversionCache holds the conversion results of the Ingresses of every
+// namespace, so that converting the cluster again only converts the
+// namespaces whose Ingresses changed. Ingresses of the same namespace share
+// Gateways and HTTPRoutes, so a namespace is the smallest unit that can be
+// converted on its own. Every namespace is converted again when an
+// IngressClass or another input object changes. A cache must always be used
+// with the same ConvertOptions.
+type ConversionCache struct {
+	// sharedKey identifies the IngressClasses and other objects the cached
+	// conversions used.
+	sharedKey  string
+	namespaces map[string]cachedConversion
+}
+
+type cachedConversion struct {
+	key       string
+	resources Resources
+	report    Report
+}
+
+// NewConversionCache returns an empty ConversionCache.
+func NewConversionCache() *ConversionCache {
+	return &ConversionCache{namespaces: map[string]cachedConversion{}}
+}
+
+// convert converts input one namespace at a time, reusing the results of
+// the namespaces whose Ingresses are unchanged since the last conversion.
+func (c *ConversionCache) convert(input inputResources, opts ConvertOptions) (Resources, Report) {
+	var sharedKeys []string
+	for _, ic := range input.ingressClasses {
+		sharedKeys = append(sharedKeys, objectKey("IngressClass", ic.Namespace, ic.Name, ic.ResourceVersion, ic))
+	}
+	for _, obj := range input.objects {
+		sharedKeys = append(sharedKeys, unstructuredKey(obj))
+	}
+	if sharedKey := hashKeys(sharedKeys); sharedKey != c.sharedKey {
+		c.sharedKey = sharedKey
+		c.namespaces = map[string]cachedConversion{}
+	}
+
+	byNamespace := map[string][]networkingv1.Ingress{}
+	for _, ingress := range input.ingresses {
+		byNamespace[ingress.Namespace] = append(byNamespace[ingress.Namespace], ingress)
+	}
+	var namespaces []string
+	for namespace := range byNamespace {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	conversions := map[string]cachedConversion{}
+	for _, namespace := range namespaces {
+		ingresses := byNamespace[namespace]
+		var keys []string
+		for _, ingress := range ingresses {
+			keys = append(keys, objectKey("Ingress", ingress.Namespace, ingress.Name, ingress.ResourceVersion, ingress))
+		}
+		key := hashKeys(keys)
+		conversion, ok := c.namespaces[namespace]
+		if !ok || conversion.key != key {
+			nsInput := inputResources{ingresses: ingresses, ingressClasses: input.ingressClasses, objects: input.objects}
+			resources, report := convertInput(nsInput, opts)
+			conversion = cachedConversion{key: key, resources: resources, report: report}
+		}
+		conversions[namespace] = conversion
+	}
+	// Namespaces without Ingresses left are dropped.
+	c.namespaces = conversions
+
+	resources := Resources{Sources: map[ObjectRef][]IngressSource{}}
+	report := Report{Confidence: map[ObjectRef]ResourceConfidence{}, SupportLevels: map[ObjectRef][]FeatureSupport{}}
+	written := map[string]bool{}
+	gatewayClasses := map[string]bool{}
+	for _, namespace := range namespaces {
+		conversion := c.namespaces[namespace]
+		// GatewayClasses are generated by the conversion of every namespace
+		// with their Gateways.
+		for _, gatewayClass := range conversion.resources.GatewayClasses {
+			if !gatewayClasses[gatewayClass.Name] {
+				gatewayClasses[gatewayClass.Name] = true
+				resources.GatewayClasses = append(resources.GatewayClasses, gatewayClass)
+			}
+		}
+		resources.Gateways = append(resources.Gateways, conversion.resources.Gateways...)
+		resources.HTTPRoutes = append(resources.HTTPRoutes, conversion.resources.HTTPRoutes...)
+		resources.TLSRoutes = append(resources.TLSRoutes, conversion.resources.TLSRoutes...)
+		resources.TCPRoutes = append(resources.TCPRoutes, conversion.resources.TCPRoutes...)
+		resources.ReferenceGrants = append(resources.ReferenceGrants, conversion.resources.ReferenceGrants...)
+		resources.BackendLBPolicies = append(resources.BackendLBPolicies, conversion.resources.BackendLBPolicies...)
+		resources.BackendTLSPolicies = append(resources.BackendTLSPolicies, conversion.resources.BackendTLSPolicies...)
+		resources.CustomResources = append(resources.CustomResources, conversion.resources.CustomResources...)
+		for ref, sources := range conversion.resources.Sources {
+			resources.Sources[ref] = sources
+		}
+		// Notifications about IngressClasses and other shared objects are
+		// reported by the conversion of every namespace.
+		for _, n := range conversion.report.Notifications {
+			if !written[n.String()] {
+				written[n.String()] = true
+				report.Notifications = append(report.Notifications, n)
+			}
+		}
+		report.Errors = append(report.Errors, conversion.report.Errors...)
+		report.UnsupportedAnnotations = append(report.UnsupportedAnnotations, conversion.report.UnsupportedAnnotations...)
+		report.DisabledAnnotations = append(report.DisabledAnnotations, conversion.report.DisabledAnnotations...)
+		report.SkippedIngresses = append(report.SkippedIngresses, conversion.report.SkippedIngresses...)
+		report.ExcludedIngresses = append(report.ExcludedIngresses, conversion.report.ExcludedIngresses...)
+		for ref, confidence := range conversion.report.Confidence {
+			report.Confidence[ref] = confidence
+		}
+		for ref, features := range conversion.report.SupportLevels {
+			report.SupportLevels[ref] = features
+		}
+		report.WeightSplits = append(report.WeightSplits, conversion.report.WeightSplits...)
+	}
+	return resources, report
+}
+
+// objectKey identifies a version of an object by its resourceVersion, or by
+// the hash of its content for objects that weren't read from a cluster.
+func objectKey(kind, namespace, name, resourceVersion string, obj any) string {
+	if resourceVersion != "" {
+		return kind + "/" + namespace + "/" + name + "@" + resourceVersion
+	}
+	// Kubernetes objects always marshal to JSON.
+	data, _ := json.Marshal(obj)
+	sum := sha256.Sum256(data)
+	return kind + "/" + namespace + "/" + name + "#" + hex.EncodeToString(sum[:])
+}
+
+func unstructuredKey(obj unstructured.Unstructured) string {
+	return objectKey(obj.GetKind(), obj.GetNamespace(), obj.GetName(), obj.GetResourceVersion(), obj.Object)
+}
+
+func hashKeys(keys []string) string {
+	h := sha256.New()
+	for _, key := range keys {
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,108 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_ConversionCache(t *testing.T) {
+	className := "nginx"
+	ingress := func(namespace, name, path, resourceVersion string) networkingv1.Ingress {
+		return networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, ResourceVersion: resourceVersion},
+			Spec: networkingv1.IngressSpec{
+				IngressClassName: &className,
+				Rules: []networkingv1.IngressRule{{
+					Host: "example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     path,
+							PathType: ptrTo(networkingv1.PathTypePrefix),
+							Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+								Name: name,
+								Port: networkingv1.ServiceBackendPort{Number: 80},
+							}},
+						}},
+					}},
+				}},
+			},
+		}
+	}
+	// paths returns the paths of the HTTPRoutes, by namespace.
+	paths := func(resources Resources) map[string][]string {
+		got := map[string][]string{}
+		for _, route := range resources.HTTPRoutes {
+			for _, rule := range route.Spec.Rules {
+				got[route.Namespace] = append(got[route.Namespace], *rule.Matches[0].Path.Value)
+			}
+		}
+		return got
+	}
+
+	cache := NewConversionCache()
+	convert := func(ingresses ...networkingv1.Ingress) (Resources, map[string]string) {
+		resources, _, err := Convert(context.Background(), ConvertOptions{Ingresses: ingresses, Cache: cache})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		keys := map[string]string{}
+		for namespace, conversion := range cache.namespaces {
+			keys[namespace] = conversion.key
+		}
+		return resources, keys
+	}
+
+	resources, keys := convert(ingress("a", "one", "/one", "1"), ingress("b", "two", "/two", "1"))
+	if diff := cmp.Diff(map[string][]string{"a": {"/one"}, "b": {"/two"}}, paths(resources)); diff != "" {
+		t.Errorf("Unexpected paths, diff (-want +got): %s", diff)
+	}
+
+	// Mark the cached conversion of namespace b to tell whether it is
+	// reused.
+	cached := cache.namespaces["b"]
+	cached.report.Notifications = append(cached.report.Notifications, notifications.NewInfo("cached"))
+	cache.namespaces["b"] = cached
+
+	resources, changedKeys := convert(ingress("a", "one", "/updated", "2"), ingress("b", "two", "/two", "1"))
+	if diff := cmp.Diff(map[string][]string{"a": {"/updated"}, "b": {"/two"}}, paths(resources)); diff != "" {
+		t.Errorf("Unexpected paths after an update, diff (-want +got): %s", diff)
+	}
+	if changedKeys["a"] == keys["a"] {
+		t.Errorf("Expected namespace a to be converted again")
+	}
+	reused := false
+	for _, n := range cache.namespaces["b"].report.Notifications {
+		reused = reused || n.Message == "cached"
+	}
+	if !reused {
+		t.Errorf("Expected the conversion of namespace b to be reused")
+	}
+
+	resources, keys = convert(ingress("a", "one", "/updated", "2"))
+	if diff := cmp.Diff(map[string][]string{"a": {"/updated"}}, paths(resources)); diff != "" {
+		t.Errorf("Unexpected paths after a deletion, diff (-want +got): %s", diff)
+	}
+	if _, ok := keys["b"]; ok {
+		t.Errorf("Expected the conversion of namespace b to be dropped")
+	}
+}
@@ -0,0 +1,231 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	gatewayAPIGroup = "gateway.networking.k8s.io"
+
+	// The annotations of the Gateway API CRDs with their release.
+	gatewayAPIChannelAnnotation       = "gateway.networking.k8s.io/channel"
+	gatewayAPIBundleVersionAnnotation = "gateway.networking.k8s.io/bundle-version"
+)
+
+// The release channels of Gateway API.
+const (
+	ChannelStandard     = "standard"
+	ChannelExperimental = "experimental"
+)
+
+// httpRouteTimeoutsVersion is the first Gateway API release with HTTPRoute
+// rule timeouts in the standard channel.
+var httpRouteTimeoutsVersion = version.MustParseSemantic("v1.2.0")
+
+// Capabilities are the resources a cluster serves and the release of its
+// Gateway API CRDs, which the output is tailored to.
+type Capabilities struct {
+	// Served are the kinds and versions the API server serves.
+	Served map[schema.GroupVersionKind]bool
+	// Channel and BundleVersion are the release channel and version of the
+	// Gateway API CRDs. They are empty if unknown.
+	Channel       string
+	BundleVersion string
+}
+
+// DiscoverCapabilities queries the API server for the resources it serves
+// and reads the release of the Gateway API CRDs from the annotations of the
+// HTTPRoute CRD.
+func DiscoverCapabilities(ctx context.Context, dc discovery.DiscoveryInterface, c client.Reader) (*Capabilities, error) {
+	_, lists, err := dc.ServerGroupsAndResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, fmt.Errorf("failed to discover the resources of the cluster: %w", err)
+	}
+	caps := &Capabilities{Served: map[schema.GroupVersionKind]bool{}}
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range list.APIResources {
+			// Subresources, such as status, are not kinds of their own.
+			if strings.Contains(resource.Name, "/") {
+				continue
+			}
+			caps.Served[gv.WithKind(resource.Kind)] = true
+		}
+	}
+
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"})
+	err = c.Get(ctx, types.NamespacedName{Name: "httproutes." + gatewayAPIGroup}, crd)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to read the HTTPRoute CRD: %w", err)
+	}
+	caps.Channel = crd.GetAnnotations()[gatewayAPIChannelAnnotation]
+	caps.BundleVersion = crd.GetAnnotations()[gatewayAPIBundleVersionAnnotation]
+	return caps, nil
+}
+
+// discoverCapabilities discovers the capabilities of the cluster of the
+// kubeconfig context.
+func discoverCapabilities(ctx context.Context, kubeContext string) (*Capabilities, error) {
+	cfg, err := RESTConfig(kubeContext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	dc, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery client: %w", err)
+	}
+	cl, err := client.New(cfg, client.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	return DiscoverCapabilities(ctx, dc, cl)
+}
+
+// targetCapabilities discovers the capabilities of the cluster the output of
+// the command line is applied to.
+func targetCapabilities(ctx context.Context, runOpts RunOptions) (*Capabilities, error) {
+	return discoverCapabilities(ctx, targetKubeContext(runOpts))
+}
+
+// targetKubeContext is the kubeconfig context of the cluster the output of
+// the command line is applied to, the source cluster without TargetContext.
+func targetKubeContext(runOpts RunOptions) string {
+	if runOpts.TargetContext != "" {
+		return runOpts.TargetContext
+	}
+	return runOpts.SourceContext
+}
+
+// restrictOptions disables the experimental fields of Gateway API if the
+// CRDs are of the standard channel.
+func (c *Capabilities) restrictOptions(opts *ConvertOptions) []Notification {
+	if c == nil || !opts.Experimental && len(opts.ExperimentalFeatures) == 0 || c.Channel != ChannelStandard {
+		return nil
+	}
+	opts.Experimental = false
+	opts.ExperimentalFeatures = nil
+	return []Notification{notifications.NewWarning("The Gateway API CRDs of the cluster are of the standard channel, experimental fields and resources are not generated")}
+}
+
+// tailor drops the resources whose kind or version the cluster doesn't
+// serve and the HTTPRoute fields its Gateway API CRDs don't support.
+func (c *Capabilities) tailor(resources *Resources) []Notification {
+	if c == nil {
+		return nil
+	}
+	var notes []Notification
+	for _, gvk := range []schema.GroupVersionKind{gatewayGVK, httpRouteGVK} {
+		if !c.Served[gvk] {
+			notes = append(notes, notifications.NewBlocking("The cluster doesn't serve %s, install the Gateway API CRDs before applying the output", gvkString(gvk)))
+		}
+	}
+
+	if len(resources.ReferenceGrants) > 0 && !c.Served[referenceGrantGVK] {
+		notes = append(notes, c.dropped(referenceGrantGVK, len(resources.ReferenceGrants)))
+		resources.ReferenceGrants = nil
+	}
+	if len(resources.TLSRoutes) > 0 && !c.Served[tlsRouteGVK] {
+		notes = append(notes, c.dropped(tlsRouteGVK, len(resources.TLSRoutes)))
+		resources.TLSRoutes = nil
+	}
+	if len(resources.TCPRoutes) > 0 && !c.Served[tcpRouteGVK] {
+		notes = append(notes, c.dropped(tcpRouteGVK, len(resources.TCPRoutes)))
+		resources.TCPRoutes = nil
+	}
+	if len(resources.BackendLBPolicies) > 0 && !c.Served[backendLBPolicyGVK] {
+		notes = append(notes, c.dropped(backendLBPolicyGVK, len(resources.BackendLBPolicies)))
+		resources.BackendLBPolicies = nil
+	}
+	if len(resources.BackendTLSPolicies) > 0 && !c.Served[backendTLSPolicyGVK] {
+		notes = append(notes, c.dropped(backendTLSPolicyGVK, len(resources.BackendTLSPolicies)))
+		resources.BackendTLSPolicies = nil
+	}
+
+	var customResources []unstructured.Unstructured
+	dropped := map[schema.GroupVersionKind]int{}
+	var droppedKinds []schema.GroupVersionKind
+	for _, obj := range resources.CustomResources {
+		gvk := obj.GroupVersionKind()
+		if c.Served[gvk] {
+			customResources = append(customResources, obj)
+			continue
+		}
+		if dropped[gvk] == 0 {
+			droppedKinds = append(droppedKinds, gvk)
+		}
+		dropped[gvk]++
+	}
+	for _, gvk := range droppedKinds {
+		notes = append(notes, c.dropped(gvk, dropped[gvk]))
+	}
+	resources.CustomResources = customResources
+
+	if c.supportsHTTPRouteTimeouts() {
+		return notes
+	}
+	for i := range resources.HTTPRoutes {
+		route := &resources.HTTPRoutes[i]
+		removed := false
+		for j := range route.Spec.Rules {
+			if route.Spec.Rules[j].Timeouts != nil {
+				route.Spec.Rules[j].Timeouts = nil
+				removed = true
+			}
+		}
+		if removed {
+			notes = append(notes, notifications.NewWarning("The Gateway API CRDs of the cluster are of %s, which doesn't support HTTPRoute rule timeouts, the timeouts of HTTPRoute %s/%s are not generated", c.BundleVersion, route.Namespace, route.Name))
+		}
+	}
+	return notes
+}
+
+func (c *Capabilities) dropped(gvk schema.GroupVersionKind, count int) Notification {
+	return notifications.NewWarning("The cluster doesn't serve %s, %d %s resources are not generated", gvkString(gvk), count, gvk.Kind)
+}
+
+// supportsHTTPRouteTimeouts reports whether the Gateway API CRDs support
+// HTTPRoute rule timeouts, assuming they do if their release is unknown.
+func (c *Capabilities) supportsHTTPRouteTimeouts() bool {
+	if c.Channel == ChannelExperimental || c.BundleVersion == "" {
+		return true
+	}
+	v, err := version.ParseSemantic(c.BundleVersion)
+	if err != nil {
+		return true
+	}
+	return v.AtLeast(httpRouteTimeoutsVersion)
+}
+
+func gvkString(gvk schema.GroupVersionKind) string {
+	return gvk.Kind + " " + gvk.GroupVersion().String()
+}
@@ -0,0 +1,206 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha3 "sigs.k8s.io/gateway-api/apis/v1alpha3"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+func Test_DiscoverCapabilities(t *testing.T) {
+	dc := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{
+		{
+			GroupVersion: "gateway.networking.k8s.io/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "gateways", Kind: "Gateway"},
+				{Name: "gateways/status", Kind: "Gateway"},
+				{Name: "httproutes", Kind: "HTTPRoute"},
+			},
+		},
+		{
+			GroupVersion: "gateway.networking.k8s.io/v1beta1",
+			APIResources: []metav1.APIResource{{Name: "referencegrants", Kind: "ReferenceGrant"}},
+		},
+	}}}
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"})
+	crd.SetName("httproutes.gateway.networking.k8s.io")
+	crd.SetAnnotations(map[string]string{
+		"gateway.networking.k8s.io/channel":        "standard",
+		"gateway.networking.k8s.io/bundle-version": "v1.1.0",
+	})
+
+	caps, err := DiscoverCapabilities(context.Background(), dc, fake.NewClientBuilder().WithObjects(crd).Build())
+	if err != nil {
+		t.Fatalf("DiscoverCapabilities failed: %v", err)
+	}
+	want := &Capabilities{
+		Served: map[schema.GroupVersionKind]bool{
+			gatewayGVK:   true,
+			httpRouteGVK: true,
+			{Group: "gateway.networking.k8s.io", Version: "v1beta1", Kind: "ReferenceGrant"}: true,
+		},
+		Channel:       ChannelStandard,
+		BundleVersion: "v1.1.0",
+	}
+	if diff := cmp.Diff(want, caps); diff != "" {
+		t.Errorf("Unexpected capabilities (-want +got):\n%s", diff)
+	}
+
+	caps, err = DiscoverCapabilities(context.Background(), dc, fake.NewClientBuilder().Build())
+	if err != nil {
+		t.Fatalf("DiscoverCapabilities failed: %v", err)
+	}
+	if caps.Channel != "" || caps.BundleVersion != "" {
+		t.Errorf("Expected an unknown release without the HTTPRoute CRD, got %q %q", caps.Channel, caps.BundleVersion)
+	}
+}
+
+func Test_Capabilities_restrictOptions(t *testing.T) {
+	testCases := []struct {
+		name             string
+		caps             *Capabilities
+		wantExperimental bool
+		wantNotes        int
+	}{
+		{name: "no capabilities", wantExperimental: true},
+		{name: "experimental channel", caps: &Capabilities{Channel: ChannelExperimental}, wantExperimental: true},
+		{name: "unknown channel", caps: &Capabilities{}, wantExperimental: true},
+		{name: "standard channel", caps: &Capabilities{Channel: ChannelStandard}, wantNotes: 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := ConvertOptions{Experimental: true}
+			notes := tc.caps.restrictOptions(&opts)
+			if opts.Experimental != tc.wantExperimental {
+				t.Errorf("Expected Experimental %t, got %t", tc.wantExperimental, opts.Experimental)
+			}
+			if len(notes) != tc.wantNotes {
+				t.Errorf("Expected %d notifications, got %v", tc.wantNotes, notes)
+			}
+		})
+	}
+}
+
+func Test_Capabilities_tailor(t *testing.T) {
+	timeout := gatewayv1.Duration("30s")
+	newResources := func() Resources {
+		grant := gatewayv1beta1.ReferenceGrant{ObjectMeta: metav1.ObjectMeta{Namespace: "backend", Name: "from-web"}}
+		grant.SetGroupVersionKind(referenceGrantGVK)
+		policy := unstructured.Unstructured{}
+		policy.SetAPIVersion("example.com/v1")
+		policy.SetKind("Policy")
+		return Resources{
+			Gateways: []gatewayv1.Gateway{{ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "nginx"}}},
+			HTTPRoutes: []gatewayv1.HTTPRoute{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "example-com"},
+				Spec: gatewayv1.HTTPRouteSpec{Rules: []gatewayv1.HTTPRouteRule{
+					{Timeouts: &gatewayv1.HTTPRouteTimeouts{Request: &timeout}},
+				}},
+			}},
+			ReferenceGrants:    []gatewayv1beta1.ReferenceGrant{grant},
+			BackendTLSPolicies: []gatewayv1alpha3.BackendTLSPolicy{{ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "api"}}},
+			CustomResources:    []unstructured.Unstructured{policy, policy},
+		}
+	}
+	policyGVK := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Policy"}
+
+	testCases := []struct {
+		name                string
+		caps                *Capabilities
+		wantReferenceGrants int
+		wantTLSPolicies     int
+		wantCustomResources int
+		wantTimeouts        bool
+		wantNotes           []Notification
+	}{
+		{
+			name: "every resource served",
+			caps: &Capabilities{
+				Served: map[schema.GroupVersionKind]bool{
+					gatewayGVK: true, httpRouteGVK: true, referenceGrantGVK: true, backendTLSPolicyGVK: true, policyGVK: true,
+				},
+				Channel:       ChannelExperimental,
+				BundleVersion: "v1.2.1",
+			},
+			wantReferenceGrants: 1,
+			wantTLSPolicies:     1,
+			wantCustomResources: 2,
+			wantTimeouts:        true,
+		},
+		{
+			name: "standard channel of v1.1",
+			caps: &Capabilities{
+				Served: map[schema.GroupVersionKind]bool{
+					gatewayGVK: true, httpRouteGVK: true, referenceGrantGVK: true,
+				},
+				Channel:       ChannelStandard,
+				BundleVersion: "v1.1.0",
+			},
+			wantReferenceGrants: 1,
+			wantNotes: []Notification{
+				notifications.NewWarning("The cluster doesn't serve BackendTLSPolicy gateway.networking.k8s.io/v1alpha3, 1 BackendTLSPolicy resources are not generated"),
+				notifications.NewWarning("The cluster doesn't serve Policy example.com/v1, 2 Policy resources are not generated"),
+				notifications.NewWarning("The Gateway API CRDs of the cluster are of v1.1.0, which doesn't support HTTPRoute rule timeouts, the timeouts of HTTPRoute web/example-com are not generated"),
+			},
+		},
+		{
+			name: "no Gateway API CRDs",
+			caps: &Capabilities{Served: map[schema.GroupVersionKind]bool{policyGVK: true}},
+			wantNotes: []Notification{
+				notifications.NewBlocking("The cluster doesn't serve Gateway gateway.networking.k8s.io/v1, install the Gateway API CRDs before applying the output"),
+				notifications.NewBlocking("The cluster doesn't serve HTTPRoute gateway.networking.k8s.io/v1, install the Gateway API CRDs before applying the output"),
+				notifications.NewWarning("The cluster doesn't serve ReferenceGrant gateway.networking.k8s.io/v1beta1, 1 ReferenceGrant resources are not generated"),
+				notifications.NewWarning("The cluster doesn't serve BackendTLSPolicy gateway.networking.k8s.io/v1alpha3, 1 BackendTLSPolicy resources are not generated"),
+			},
+			wantCustomResources: 2,
+			wantTimeouts:        true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resources := newResources()
+			notes := tc.caps.tailor(&resources)
+			if diff := cmp.Diff(tc.wantNotes, notes); diff != "" {
+				t.Errorf("Unexpected notifications (-want +got):\n%s", diff)
+			}
+			if len(resources.ReferenceGrants) != tc.wantReferenceGrants {
+				t.Errorf("Expected %d ReferenceGrants, got %d", tc.wantReferenceGrants, len(resources.ReferenceGrants))
+			}
+			if len(resources.BackendTLSPolicies) != tc.wantTLSPolicies {
+				t.Errorf("Expected %d BackendTLSPolicies, got %d", tc.wantTLSPolicies, len(resources.BackendTLSPolicies))
+			}
+			if len(resources.CustomResources) != tc.wantCustomResources {
+				t.Errorf("Expected %d custom resources, got %d", tc.wantCustomResources, len(resources.CustomResources))
+			}
+			if gotTimeouts := resources.HTTPRoutes[0].Spec.Rules[0].Timeouts != nil; gotTimeouts != tc.wantTimeouts {
+				t.Errorf("Expected timeouts %t, got %t", tc.wantTimeouts, gotTimeouts)
+			}
+		})
+	}
+}
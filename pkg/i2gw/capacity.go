@@ -0,0 +1,169 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// capacityKey groups the resources of a namespace attached to the Gateways
+// of a GatewayClass.
+type capacityKey struct {
+	namespace string
+	class     string
+}
+
+type capacityUsage struct {
+	gateways   int
+	listeners  int
+	httpRoutes int
+	rules      int
+	// crossNamespaceRefs are the references to objects of other
+	// namespaces, which need a ReferenceGrant or an allowedRoutes
+	// namespace selector.
+	crossNamespaceRefs int
+}
+
+func (u *capacityUsage) addUsage(other capacityUsage) {
+	u.gateways += other.gateways
+	u.listeners += other.listeners
+	u.httpRoutes += other.httpRoutes
+	u.rules += other.rules
+	u.crossNamespaceRefs += other.crossNamespaceRefs
+}
+
+// capacityReport counts the resources a conversion, or the conversions of
+// every namespace of a stream, produces by namespace and GatewayClass, for
+// platform teams to check them against the quotas of their implementation.
+type capacityReport struct {
+	usage map[capacityKey]*capacityUsage
+	// gatewayClasses are the GatewayClasses of the Gateways seen so far,
+	// which the HTTPRoutes attached to them are counted against.
+	gatewayClasses map[types.NamespacedName]string
+}
+
+func newCapacityReport() *capacityReport {
+	return &capacityReport{
+		usage:          map[capacityKey]*capacityUsage{},
+		gatewayClasses: map[types.NamespacedName]string{},
+	}
+}
+
+func (c *capacityReport) row(namespace, class string) *capacityUsage {
+	key := capacityKey{namespace: namespace, class: class}
+	if c.usage[key] == nil {
+		c.usage[key] = &capacityUsage{}
+	}
+	return c.usage[key]
+}
+
+func (c *capacityReport) add(resources Resources) {
+	for _, gateway := range resources.Gateways {
+		c.gatewayClasses[types.NamespacedName{Namespace: gateway.Namespace, Name: gateway.Name}] = string(gateway.Spec.GatewayClassName)
+		usage := c.row(gateway.Namespace, string(gateway.Spec.GatewayClassName))
+		usage.gateways++
+		usage.listeners += len(gateway.Spec.Listeners)
+		for _, listener := range gateway.Spec.Listeners {
+			if listener.TLS == nil {
+				continue
+			}
+			for _, ref := range listener.TLS.CertificateRefs {
+				if isCrossNamespace(ref.Namespace, gateway.Namespace) {
+					usage.crossNamespaceRefs++
+				}
+			}
+		}
+	}
+
+	for _, route := range resources.HTTPRoutes {
+		var class string
+		for _, parentRef := range route.Spec.ParentRefs {
+			namespace := route.Namespace
+			if parentRef.Namespace != nil {
+				namespace = string(*parentRef.Namespace)
+			}
+			if gatewayClass, ok := c.gatewayClasses[types.NamespacedName{Namespace: namespace, Name: string(parentRef.Name)}]; ok {
+				class = gatewayClass
+				break
+			}
+		}
+		usage := c.row(route.Namespace, class)
+		usage.httpRoutes++
+		usage.rules += len(route.Spec.Rules)
+		for _, parentRef := range route.Spec.ParentRefs {
+			if isCrossNamespace(parentRef.Namespace, route.Namespace) {
+				usage.crossNamespaceRefs++
+			}
+		}
+		for _, rule := range route.Spec.Rules {
+			for _, backendRef := range rule.BackendRefs {
+				if isCrossNamespace(backendRef.Namespace, route.Namespace) {
+					usage.crossNamespaceRefs++
+				}
+			}
+		}
+	}
+}
+
+func isCrossNamespace(namespace *gatewayv1.Namespace, local string) bool {
+	return namespace != nil && string(*namespace) != local
+}
+
+// write writes the usage as a table sorted by namespace and class, with
+// the totals last. HTTPRoutes attached to no Gateway of the output have no
+// class.
+func (c *capacityReport) write(w io.Writer) {
+	var keys []capacityKey
+	for key := range c.usage {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].namespace != keys[j].namespace {
+			return keys[i].namespace < keys[j].namespace
+		}
+		return keys[i].class < keys[j].class
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "Namespace\tClass\tGateways\tListeners\tHTTPRoutes\tRules\tCross-namespace references")
+	var total capacityUsage
+	for _, key := range keys {
+		usage := c.usage[key]
+		class := key.class
+		if class == "" {
+			class = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%d\n", key.namespace, class, usage.gateways, usage.listeners, usage.httpRoutes, usage.rules, usage.crossNamespaceRefs)
+		total.addUsage(*usage)
+	}
+	fmt.Fprintf(tw, "Total\t\t%d\t%d\t%d\t%d\t%d\n", total.gateways, total.listeners, total.httpRoutes, total.rules, total.crossNamespaceRefs)
+	tw.Flush()
+}
+
+// WriteCapacityReport writes the numbers of Gateways, listeners, HTTPRoutes,
+// rules and cross-namespace references of the resources by namespace and
+// GatewayClass.
+func WriteCapacityReport(w io.Writer, resources Resources) {
+	c := newCapacityReport()
+	c.add(resources)
+	c.write(w)
+}
@@ -0,0 +1,73 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_WriteCapacityReport(t *testing.T) {
+	resources := Resources{
+		Gateways: []gatewayv1.Gateway{{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "infra", Name: "nginx"},
+			Spec: gatewayv1.GatewaySpec{
+				GatewayClassName: "nginx",
+				Listeners: []gatewayv1.Listener{{Name: "http"}, {
+					Name: "https",
+					TLS: &gatewayv1.GatewayTLSConfig{CertificateRefs: []gatewayv1.SecretObjectReference{
+						{Name: "cert", Namespace: ptrTo(gatewayv1.Namespace("default"))},
+					}},
+				}},
+			},
+		}},
+		HTTPRoutes: []gatewayv1.HTTPRoute{{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example-com"},
+			Spec: gatewayv1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1.CommonRouteSpec{ParentRefs: []gatewayv1.ParentReference{
+					{Name: "nginx", Namespace: ptrTo(gatewayv1.Namespace("infra"))},
+				}},
+				Rules: []gatewayv1.HTTPRouteRule{{}, {
+					BackendRefs: []gatewayv1.HTTPBackendRef{{BackendRef: gatewayv1.BackendRef{BackendObjectReference: gatewayv1.BackendObjectReference{
+						Name: "web",
+					}}}},
+				}},
+			},
+		}, {
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "orphan"},
+			Spec: gatewayv1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1.CommonRouteSpec{ParentRefs: []gatewayv1.ParentReference{{Name: "missing"}}},
+				Rules:           []gatewayv1.HTTPRouteRule{{}},
+			},
+		}},
+	}
+
+	var buf bytes.Buffer
+	WriteCapacityReport(&buf, resources)
+	expected := `Namespace  Class  Gateways  Listeners  HTTPRoutes  Rules  Cross-namespace references
+default    -      0         0          1           1      0
+default    nginx  0         0          1           2      1
+infra      nginx  1         2          0           0      1
+Total             1         2          2           3      2
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("Unexpected capacity report, diff (-want +got): %s", diff)
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// checkCertificateRefs warns about the listeners of the Gateways whose
+// certificateRefs point at Secrets missing from the cluster, or not of type
+// kubernetes.io/tls, which would come up without their certificates. The
+// Secrets of the Gateways annotated for cert-manager may be missing, as
+// cert-manager creates them once the Gateway is applied.
+func checkCertificateRefs(ctx context.Context, cl client.Client, gateways []gatewayv1.Gateway) []Notification {
+	var notes []Notification
+	// problems are why the Secrets can't serve certificates, empty for the
+	// valid ones.
+	problems := map[types.NamespacedName]string{}
+	for _, gw := range gateways {
+		certManaged := false
+		for annotation := range gw.Annotations {
+			certManaged = certManaged || strings.HasPrefix(annotation, certManagerAnnotationPrefix)
+		}
+		for _, listener := range gw.Spec.Listeners {
+			if listener.TLS == nil {
+				continue
+			}
+			for _, ref := range listener.TLS.CertificateRefs {
+				if (ref.Group != nil && *ref.Group != "") || (ref.Kind != nil && *ref.Kind != "Secret") {
+					continue
+				}
+				secret := types.NamespacedName{Namespace: gw.Namespace, Name: string(ref.Name)}
+				if ref.Namespace != nil {
+					secret.Namespace = string(*ref.Namespace)
+				}
+				problem, ok := problems[secret]
+				if !ok {
+					var err error
+					problem, err = secretProblem(ctx, cl, secret)
+					if apierrors.IsForbidden(err) {
+						return append(notes, notifications.NewWarning("Failed to get the TLS certificate Secrets of the Gateways, continuing without checking them: %v", err))
+					}
+					if err != nil {
+						notes = append(notes, notifications.NewWarning("Failed to get Secret %s, continuing without checking it: %v", secret, err))
+					}
+					problems[secret] = problem
+				}
+				if problem == "" || (problem == secretMissing && certManaged && secret.Namespace == gw.Namespace) {
+					continue
+				}
+				notes = append(notes, notifications.NewWarning("Listener %s of Gateway %s/%s references Secret %s, which %s, the listener won't serve its certificate", listener.Name, gw.Namespace, gw.Name, secret, problem))
+			}
+		}
+	}
+	return notes
+}
+
+const secretMissing = "doesn't exist"
+
+// secretProblem returns why a Secret can't serve the certificate of a
+// listener, or an empty string if it can.
+func secretProblem(ctx context.Context, cl client.Client, name types.NamespacedName) (string, error) {
+	secret := &corev1.Secret{}
+	if err := cl.Get(ctx, name, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return secretMissing, nil
+		}
+		return "", err
+	}
+	switch secret.Type {
+	case corev1.SecretTypeTLS:
+		return "", nil
+	case "":
+		secret.Type = corev1.SecretTypeOpaque
+	}
+	return fmt.Sprintf("is of type %s instead of %s", secret.Type, corev1.SecretTypeTLS), nil
+}
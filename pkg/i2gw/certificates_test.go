@@ -0,0 +1,97 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_checkCertificateRefs(t *testing.T) {
+	secret := func(name string, secretType corev1.SecretType) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name}, Type: secretType}
+	}
+	gateway := func(annotations map[string]string, refs ...gatewayv1.SecretObjectReference) gatewayv1.Gateway {
+		return gatewayv1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "nginx", Annotations: annotations},
+			Spec: gatewayv1.GatewaySpec{Listeners: []gatewayv1.Listener{
+				{Name: "http", Protocol: gatewayv1.HTTPProtocolType},
+				{Name: "https", Protocol: gatewayv1.HTTPSProtocolType, TLS: &gatewayv1.GatewayTLSConfig{CertificateRefs: refs}},
+			}},
+		}
+	}
+	ref := func(name string) gatewayv1.SecretObjectReference {
+		return gatewayv1.SecretObjectReference{Name: gatewayv1.ObjectName(name)}
+	}
+	cl := fake.NewClientBuilder().WithObjects(
+		secret("web-tls", corev1.SecretTypeTLS),
+		secret("opaque", corev1.SecretTypeOpaque),
+	).Build()
+
+	testCases := []struct {
+		name     string
+		client   client.Client
+		gateways []gatewayv1.Gateway
+		want     []Notification
+	}{{
+		name:     "TLS Secret",
+		client:   cl,
+		gateways: []gatewayv1.Gateway{gateway(nil, ref("web-tls"))},
+	}, {
+		name:     "missing and Opaque Secrets",
+		client:   cl,
+		gateways: []gatewayv1.Gateway{gateway(nil, ref("missing"), ref("opaque"))},
+		want: []Notification{
+			{Type: WarningNotification, Message: "Listener https of Gateway test/nginx references Secret test/missing, which doesn't exist, the listener won't serve its certificate"},
+			{Type: WarningNotification, Message: "Listener https of Gateway test/nginx references Secret test/opaque, which is of type Opaque instead of kubernetes.io/tls, the listener won't serve its certificate"},
+		},
+	}, {
+		name:     "Secret issued by cert-manager",
+		client:   cl,
+		gateways: []gatewayv1.Gateway{gateway(map[string]string{"cert-manager.io/cluster-issuer": "letsencrypt"}, ref("missing"))},
+	}, {
+		name: "forbidden",
+		client: fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(context.Context, client.WithWatch, client.ObjectKey, client.Object, ...client.GetOption) error {
+				return apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "web-tls", errors.New("access denied"))
+			},
+		}).Build(),
+		gateways: []gatewayv1.Gateway{gateway(nil, ref("web-tls"), ref("missing"))},
+		want: []Notification{
+			{Type: WarningNotification, Message: `Failed to get the TLS certificate Secrets of the Gateways, continuing without checking them: secrets "web-tls" is forbidden: access denied`},
+		},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := checkCertificateRefs(context.Background(), tc.client, tc.gateways)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected notifications (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// certManagerAnnotationPrefix is the prefix of the cert-manager
+	// annotations requesting certificates, which cert-manager supports on
+	// Gateways as well as on Ingresses.
+	certManagerAnnotationPrefix = "cert-manager.io/"
+	// certManagerACMEAnnotationPrefix is the prefix of the annotations
+	// configuring the ACME solvers of Ingresses, which don't apply to
+	// Gateways.
+	certManagerACMEAnnotationPrefix = "acme.cert-manager.io/"
+	// tlsACMEAnnotation requests certificates from the default issuer of
+	// cert-manager, which only Ingresses support.
+	tlsACMEAnnotation = "kubernetes.io/tls-acme"
+)
+
+// addCertManagerAnnotations records the cert-manager annotations of an
+// Ingress with TLS entries, which are carried to its Gateway.
+func (a *ingressAggregator) addCertManagerAnnotations(ingress networkingv1.Ingress) {
+	if len(ingress.Spec.TLS) == 0 {
+		return
+	}
+	annotations := map[string]string{}
+	var ignored []string
+	for annotation, value := range ingress.Annotations {
+		switch {
+		case strings.HasPrefix(annotation, certManagerAnnotationPrefix):
+			annotations[annotation] = value
+		case strings.HasPrefix(annotation, certManagerACMEAnnotationPrefix), annotation == tlsACMEAnnotation:
+			ignored = append(ignored, annotation)
+		}
+	}
+	if len(ignored) > 0 {
+		sort.Strings(ignored)
+		a.notifications = append(a.notifications, notifications.NewWarning("Ingress %s/%s uses cert-manager annotations that Gateways don't support: %s. Set an issuer with the %sissuer or %scluster-issuer annotation, and configure the HTTP-01 solvers of the issuer for Gateways", ingress.Namespace, ingress.Name, strings.Join(ignored, ", "), certManagerAnnotationPrefix, certManagerAnnotationPrefix))
+	}
+	if a.certManager == nil {
+		a.certManager = map[types.NamespacedName]map[string]string{}
+	}
+	a.certManager[types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}] = annotations
+}
+
+// setCertManagerAnnotations sets the cert-manager annotations of the
+// Ingresses of every Gateway on it, so that cert-manager issues the
+// certificates of its listeners. cert-manager issues the certificates of
+// every listener of an annotated Gateway, in its namespace only.
+func (a *ingressAggregator) setCertManagerAnnotations(result *ir.IR) []Notification {
+	var notes []Notification
+	for i := range result.Gateways {
+		gw := &result.Gateways[i]
+		var source types.NamespacedName
+		var unmanaged []string
+		for _, ingress := range sortedNamespacedNames(gw.Ingresses) {
+			annotations, ok := a.certManager[ingress]
+			if !ok {
+				continue
+			}
+			if len(annotations) == 0 {
+				unmanaged = append(unmanaged, ingress.String())
+				continue
+			}
+			if source.Name == "" {
+				source = ingress
+			}
+			var names []string
+			for annotation := range annotations {
+				names = append(names, annotation)
+			}
+			sort.Strings(names)
+			for _, annotation := range names {
+				value, ok := gw.Annotations[annotation]
+				switch {
+				case !ok:
+					if gw.Annotations == nil {
+						gw.Annotations = map[string]string{}
+					}
+					gw.Annotations[annotation] = annotations[annotation]
+				case value != annotations[annotation]:
+					notes = append(notes, notifications.NewWarning("Ingresses %s and %s set the %s annotation differently, Gateway %s/%s gets the value of %s", source, ingress, annotation, gw.Namespace, gw.Name, source))
+				}
+			}
+			if ingress.Namespace != gw.Namespace {
+				notes = append(notes, notifications.NewWarning("cert-manager only issues the certificates of Gateway %s/%s stored in its namespace, the certificates of Ingress %s are not issued", gw.Namespace, gw.Name, ingress))
+			}
+		}
+		if source.Name != "" && len(unmanaged) > 0 {
+			notes = append(notes, notifications.NewWarning("Gateway %s/%s has the cert-manager annotations of Ingress %s, so cert-manager also issues the certificates of Ingresses %s, which don't use cert-manager", gw.Namespace, gw.Name, source, strings.Join(unmanaged, ", ")))
+		}
+	}
+	return notes
+}
@@ -0,0 +1,115 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+func Test_certManagerAnnotations(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	tlsIngress := func(name, host string, annotations map[string]string) networkingv1.Ingress {
+		ingress := ingressWithPath(name, "/", &iPrefix, serviceBackend(name, 80), annotations)
+		ingress.Spec.Rules[0].Host = host
+		ingress.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{host}, SecretName: name + "-tls"}}
+		return ingress
+	}
+
+	testCases := []struct {
+		name             string
+		ingresses        []networkingv1.Ingress
+		gatewayNamespace string
+		wantAnnotations  map[string]string
+		wantWarnings     []string
+	}{
+		{
+			name: "issuer annotations",
+			ingresses: []networkingv1.Ingress{tlsIngress("web", "example.com", map[string]string{
+				"cert-manager.io/cluster-issuer": "letsencrypt",
+				"cert-manager.io/duration":       "2160h",
+			})},
+			wantAnnotations: map[string]string{
+				"cert-manager.io/cluster-issuer": "letsencrypt",
+				"cert-manager.io/duration":       "2160h",
+			},
+		},
+		{
+			name: "Ingress without TLS",
+			ingresses: []networkingv1.Ingress{ingressWithPath("web", "/", &iPrefix, serviceBackend("web", 80), map[string]string{
+				"cert-manager.io/cluster-issuer": "letsencrypt",
+			})},
+		},
+		{
+			name: "Ingress-only annotations",
+			ingresses: []networkingv1.Ingress{tlsIngress("web", "example.com", map[string]string{
+				"kubernetes.io/tls-acme":                    "true",
+				"acme.cert-manager.io/http01-edit-in-place": "true",
+			})},
+			wantWarnings: []string{
+				"Ingress test/web uses cert-manager annotations that Gateways don't support: acme.cert-manager.io/http01-edit-in-place, kubernetes.io/tls-acme. Set an issuer with the cert-manager.io/issuer or cert-manager.io/cluster-issuer annotation, and configure the HTTP-01 solvers of the issuer for Gateways",
+			},
+		},
+		{
+			name: "conflicting issuers and unmanaged certificates",
+			ingresses: []networkingv1.Ingress{
+				tlsIngress("api", "api.example.com", map[string]string{"cert-manager.io/cluster-issuer": "letsencrypt"}),
+				tlsIngress("shop", "shop.example.com", map[string]string{"cert-manager.io/cluster-issuer": "internal"}),
+				tlsIngress("web", "example.com", nil),
+			},
+			wantAnnotations: map[string]string{"cert-manager.io/cluster-issuer": "letsencrypt"},
+			wantWarnings: []string{
+				"Ingresses test/api and test/shop set the cert-manager.io/cluster-issuer annotation differently, Gateway test/nginx gets the value of test/api",
+				"Gateway test/nginx has the cert-manager annotations of Ingress test/api, so cert-manager also issues the certificates of Ingresses test/web, which don't use cert-manager",
+			},
+		},
+		{
+			name: "Gateway in another namespace",
+			ingresses: []networkingv1.Ingress{tlsIngress("web", "example.com", map[string]string{
+				"cert-manager.io/cluster-issuer": "letsencrypt",
+			})},
+			gatewayNamespace: "infra",
+			wantAnnotations:  map[string]string{"cert-manager.io/cluster-issuer": "letsencrypt"},
+			wantWarnings: []string{
+				"cert-manager only issues the certificates of Gateway infra/nginx stored in its namespace, the certificates of Ingress test/web are not issued",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resources, report := convertInput(inputResources{ingresses: tc.ingresses}, ConvertOptions{GatewayNamespace: tc.gatewayNamespace})
+			if len(resources.Gateways) != 1 {
+				t.Fatalf("Expected 1 Gateway, got %d", len(resources.Gateways))
+			}
+			if diff := cmp.Diff(tc.wantAnnotations, resources.Gateways[0].Annotations); diff != "" {
+				t.Errorf("Unexpected Gateway annotations, diff (-want +got): %s", diff)
+			}
+			var warnings []string
+			for _, n := range report.Notifications {
+				if n.Type == WarningNotification && strings.Contains(n.Message, "cert-manager") {
+					warnings = append(warnings, n.Message)
+				}
+			}
+			if diff := cmp.Diff(tc.wantWarnings, warnings); diff != "" {
+				t.Errorf("Unexpected warnings, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
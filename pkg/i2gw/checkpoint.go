@@ -0,0 +1,96 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+// Checkpoint records the Ingresses a streamed conversion has processed, one
+// per line of a file, so that an interrupted conversion resumes where it
+// stopped instead of converting everything again. Ingresses are identified
+// by their UID, or by their namespace/name when they have none, such as in
+// manifests that weren't read from a cluster. The Ingresses of a namespace
+// are recorded once their output is written.
+type Checkpoint struct {
+	file      *os.File
+	processed map[string]bool
+}
+
+// OpenCheckpoint opens the checkpoint file of the path, creating it if it
+// doesn't exist.
+func OpenCheckpoint(path string) (*Checkpoint, error) {
+	c := &Checkpoint{processed: map[string]bool{}}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read checkpoint file: %w", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if key := strings.TrimSpace(line); key != "" {
+			c.processed[key] = true
+		}
+	}
+	c.file, err = os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint file: %w", err)
+	}
+	return c, nil
+}
+
+// Close closes the checkpoint file.
+func (c *Checkpoint) Close() error {
+	return c.file.Close()
+}
+
+func (c *Checkpoint) isProcessed(ingress networkingv1.Ingress) bool {
+	return c.processed[checkpointKey(ingress)]
+}
+
+// record appends the Ingresses to the checkpoint file in a single write,
+// synced before returning, so that an interruption loses at most the
+// Ingresses of a namespace.
+func (c *Checkpoint) record(ingresses []networkingv1.Ingress) error {
+	var b strings.Builder
+	for _, ingress := range ingresses {
+		key := checkpointKey(ingress)
+		if !c.processed[key] {
+			c.processed[key] = true
+			b.WriteString(key + "\n")
+		}
+	}
+	if b.Len() == 0 {
+		return nil
+	}
+	if _, err := c.file.WriteString(b.String()); err != nil {
+		return fmt.Errorf("failed to write checkpoint file: %w", err)
+	}
+	if err := c.file.Sync(); err != nil {
+		return fmt.Errorf("failed to write checkpoint file: %w", err)
+	}
+	return nil
+}
+
+func checkpointKey(ingress networkingv1.Ingress) string {
+	if ingress.UID != "" {
+		return string(ingress.UID)
+	}
+	return ingress.Namespace + "/" + ingress.Name
+}
@@ -0,0 +1,111 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_ConvertStreamCheckpoint(t *testing.T) {
+	ingress := func(namespace, name, uid string) string {
+		return fmt.Sprintf(`apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: %s
+  namespace: %s
+  uid: %s
+spec:
+  ingressClassName: nginx
+  rules:
+  - host: example.com
+    http:
+      paths:
+      - path: /%s
+        pathType: Prefix
+        backend:
+          service:
+            name: %s
+            port:
+              number: 80
+`, name, namespace, uid, name, name)
+	}
+	input := strings.Join([]string{
+		ingress("a", "one", "uid-1"),
+		ingress("a", "two", "uid-2"),
+		ingress("b", "three", "uid-3"),
+		ingress("c", "four", ""),
+	}, "---\n")
+	path := filepath.Join(t.TempDir(), "checkpoint")
+
+	convert := func(failNamespace string) ([]string, []string, error) {
+		checkpoint, err := OpenCheckpoint(path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer checkpoint.Close()
+		var namespaces, notes []string
+		err = ConvertStream(context.Background(), strings.NewReader(input), ConvertOptions{Checkpoint: checkpoint}, func(resources Resources, report Report) error {
+			for _, n := range report.Notifications {
+				notes = append(notes, n.String())
+			}
+			for _, route := range resources.HTTPRoutes {
+				if route.Namespace == failNamespace {
+					return errors.New("interrupted")
+				}
+				namespaces = append(namespaces, route.Namespace)
+			}
+			return nil
+		})
+		return namespaces, notes, err
+	}
+
+	// The first run is interrupted while writing namespace b.
+	gotNamespaces, _, err := convert("b")
+	if err == nil {
+		t.Fatalf("Expected the interruption error")
+	}
+	if diff := cmp.Diff([]string{"a"}, gotNamespaces); diff != "" {
+		t.Errorf("Unexpected namespaces of the first run, diff (-want +got): %s", diff)
+	}
+
+	gotNamespaces, gotNotes, err := convert("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"b", "c"}, gotNamespaces); diff != "" {
+		t.Errorf("Unexpected namespaces of the resumed run, diff (-want +got): %s", diff)
+	}
+	wantNotes := []string{"INFO: Ingresses of namespace a were converted by a previous run recorded in the checkpoint, skipping them"}
+	if diff := cmp.Diff(wantNotes, gotNotes); diff != "" {
+		t.Errorf("Unexpected notifications of the resumed run, diff (-want +got): %s", diff)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("uid-1\nuid-2\nuid-3\nc/four\n", string(data)); diff != "" {
+		t.Errorf("Unexpected checkpoint file, diff (-want +got): %s", diff)
+	}
+}
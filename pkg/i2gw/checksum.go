@@ -0,0 +1,203 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// SourcesAnnotation lists the Ingresses a resource was converted from,
+	// as comma-separated namespace/name pairs.
+	SourcesAnnotation = "ingress2gateway.kubernetes.io/sources"
+	// SourceChecksumAnnotation is the checksum of the annotations and
+	// specs of the Ingresses a resource was converted from.
+	SourceChecksumAnnotation = "ingress2gateway.kubernetes.io/source-checksum"
+)
+
+// ignoredChecksumAnnotations don't take part in the conversion, and change
+// without the Ingress changing.
+var ignoredChecksumAnnotations = map[string]bool{
+	"kubectl.kubernetes.io/last-applied-configuration": true,
+}
+
+// sourceChecksum returns the checksum of the annotations and specs of the
+// Ingresses, in the order of names.
+func sourceChecksum(names []types.NamespacedName, ingresses map[types.NamespacedName]networkingv1.Ingress) string {
+	h := sha256.New()
+	for _, name := range names {
+		ingress := ingresses[name]
+		annotations := map[string]string{}
+		for k, v := range ingress.Annotations {
+			if !ignoredChecksumAnnotations[k] {
+				annotations[k] = v
+			}
+		}
+		// Maps are marshaled with sorted keys, so the checksum is stable.
+		data, _ := json.Marshal(struct {
+			Name        string                   `json:"name"`
+			Annotations map[string]string        `json:"annotations"`
+			Spec        networkingv1.IngressSpec `json:"spec"`
+		}{name.String(), annotations, ingress.Spec})
+		h.Write(data)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// stampSourceChecksums annotates the Gateways and HTTPRoutes with the
+// Ingresses they were converted from and the checksum of these Ingresses.
+func stampSourceChecksums(resources *Resources, ingresses []networkingv1.Ingress) {
+	byName := map[types.NamespacedName]networkingv1.Ingress{}
+	for _, ingress := range ingresses {
+		byName[types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}] = ingress
+	}
+	for _, obj := range resourceObjects(*resources) {
+		ref := ObjectRef{Kind: obj.GetObjectKind().GroupVersionKind().Kind, Namespace: obj.GetNamespace(), Name: obj.GetName()}
+		sources := resources.Sources[ref]
+		if len(sources) == 0 {
+			continue
+		}
+		var names []types.NamespacedName
+		var values []string
+		for _, source := range sources {
+			names = append(names, source.Ingress)
+			values = append(values, source.Ingress.String())
+		}
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[SourcesAnnotation] = strings.Join(values, ",")
+		annotations[SourceChecksumAnnotation] = sourceChecksum(names, byName)
+		obj.SetAnnotations(annotations)
+	}
+}
+
+// VerifyOptions configures Verify.
+type VerifyOptions struct {
+	// ManifestFile is the path of the output of a conversion with source
+	// checksums.
+	ManifestFile string
+	// Client, if set, is used to list the current Ingresses of a cluster.
+	Client client.Client
+	// InputFile, if set, is the path of a manifest file to read the current
+	// Ingresses from.
+	InputFile string
+}
+
+// Drift is a resource whose source Ingresses changed since it was
+// generated.
+type Drift struct {
+	Object ObjectRef
+	// Reason tells what changed.
+	Reason string
+}
+
+// Verify compares the source checksums of the resources of the manifest
+// file with the checksums of the current Ingresses, and returns the
+// resources whose Ingresses changed or were deleted. Resources without
+// source checksum are ignored.
+func Verify(ctx context.Context, opts VerifyOptions) ([]Drift, error) {
+	manifest, err := readInputFromFile(opts.ManifestFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var ingresses []networkingv1.Ingress
+	if opts.InputFile != "" {
+		input, err := readInputFromFile(opts.InputFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input from %s: %w", opts.InputFile, err)
+		}
+		ingresses = append(ingresses, input.ingresses...)
+	}
+	if opts.Client != nil {
+		ingressList := &networkingv1.IngressList{}
+		if err := opts.Client.List(ctx, ingressList); err != nil {
+			return nil, fmt.Errorf("failed to list ingresses: %w", err)
+		}
+		ingresses = append(ingresses, ingressList.Items...)
+	}
+	byName := map[types.NamespacedName]networkingv1.Ingress{}
+	for _, ingress := range ingresses {
+		byName[types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}] = ingress
+	}
+
+	var drifts []Drift
+	for _, obj := range manifest.objects {
+		checksum, ok := obj.GetAnnotations()[SourceChecksumAnnotation]
+		if !ok {
+			continue
+		}
+		ref := ObjectRef{Kind: obj.GetKind(), Namespace: obj.GetNamespace(), Name: obj.GetName()}
+		var names []types.NamespacedName
+		var missing []string
+		for _, value := range strings.Split(obj.GetAnnotations()[SourcesAnnotation], ",") {
+			namespace, name, _ := strings.Cut(value, "/")
+			source := types.NamespacedName{Namespace: namespace, Name: name}
+			if _, ok := byName[source]; !ok {
+				missing = append(missing, value)
+			}
+			names = append(names, source)
+		}
+		switch {
+		case len(missing) > 0:
+			drifts = append(drifts, Drift{Object: ref, Reason: fmt.Sprintf("Ingress %s no longer exists", strings.Join(missing, ", "))})
+		case sourceChecksum(names, byName) != checksum:
+			drifts = append(drifts, Drift{Object: ref, Reason: fmt.Sprintf("Ingress %s changed", obj.GetAnnotations()[SourcesAnnotation])})
+		}
+	}
+	return drifts, nil
+}
+
+// RunVerify verifies the manifest file against the Ingresses of the input
+// file, or of the cluster of the current kubeconfig if it is empty, as the
+// verify command does, exiting with an error if any source changed.
+func RunVerify(opts VerifyOptions) {
+	if opts.InputFile == "" {
+		cl, err := newClient("", client.Options{})
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		opts.Client = cl
+	}
+
+	ctx, cancel := runContext(0)
+	defer cancel()
+	drifts, err := Verify(ctx, opts)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	for _, drift := range drifts {
+		fmt.Printf("%s %s/%s is out of date: %s\n", drift.Object.Kind, drift.Object.Namespace, drift.Object.Name, drift.Reason)
+	}
+	if len(drifts) > 0 {
+		os.Exit(1)
+	}
+	fmt.Println("All resources are up to date with their Ingresses")
+}
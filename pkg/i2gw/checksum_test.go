@@ -0,0 +1,103 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Verify(t *testing.T) {
+	ingress := func(name, path string) string {
+		return `apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: ` + name + `
+  namespace: default
+spec:
+  ingressClassName: nginx
+  rules:
+  - host: ` + name + `.example.com
+    http:
+      paths:
+      - path: ` + path + `
+        pathType: Prefix
+        backend:
+          service:
+            name: web
+            port:
+              number: 80
+`
+	}
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	inputFile := writeFile("input.yaml", ingress("web", "/")+"---\n"+ingress("api", "/"))
+	resources, report, err := Convert(context.Background(), ConvertOptions{InputFile: inputFile, SourceChecksums: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	WriteResult(&buf, resources, report)
+	if !strings.Contains(buf.String(), SourcesAnnotation+": default/web") {
+		t.Errorf("Expected the output to be annotated with its sources, got:\n%s", buf.String())
+	}
+	manifestFile := writeFile("output.yaml", buf.String())
+
+	testCases := []struct {
+		name           string
+		input          string
+		expectedDrifts []Drift
+	}{
+		{
+			name:  "unchanged",
+			input: ingress("web", "/") + "---\n" + ingress("api", "/"),
+		},
+		{
+			name:  "changed and deleted",
+			input: ingress("web", "/changed"),
+			expectedDrifts: []Drift{
+				{Object: ObjectRef{Kind: "Gateway", Namespace: "default", Name: "nginx"}, Reason: "Ingress default/api no longer exists"},
+				{Object: ObjectRef{Kind: "HTTPRoute", Namespace: "default", Name: "api-example-com"}, Reason: "Ingress default/api no longer exists"},
+				{Object: ObjectRef{Kind: "HTTPRoute", Namespace: "default", Name: "web-example-com"}, Reason: "Ingress default/web changed"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			drifts, err := Verify(context.Background(), VerifyOptions{ManifestFile: manifestFile, InputFile: writeFile("current.yaml", tc.input)})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedDrifts, drifts); diff != "" {
+				t.Errorf("Unexpected drifts, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
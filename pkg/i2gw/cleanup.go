@@ -0,0 +1,174 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// IngressCleanup is a source Ingress of a conversion, and whether it can be
+// deleted once the routes it was converted to serve its traffic.
+type IngressCleanup struct {
+	Ingress types.NamespacedName
+	// Routes are the routes the Ingress was converted to.
+	Routes []ObjectRef
+	// Reason, if set, is why the Ingress must be kept, such as a part of
+	// it that failed to convert or a route that isn't accepted.
+	Reason string
+}
+
+// Deletable tells whether the Ingress can be deleted.
+func (c IngressCleanup) Deletable() bool {
+	return c.Reason == ""
+}
+
+// Cleanup returns the Ingresses converted to routes, sorted, keeping the
+// ones that failed to convert in part, use annotations that are not
+// supported, or whose routes were only partially converted.
+func Cleanup(resources Resources, report Report) []IngressCleanup {
+	reasons := map[types.NamespacedName]string{}
+	keep := func(ingress types.NamespacedName, reason string) {
+		if _, ok := reasons[ingress]; !ok {
+			reasons[ingress] = reason
+		}
+	}
+	for _, err := range report.Errors {
+		if ingress, ok := ErrorIngress(err); ok {
+			keep(ingress, fmt.Sprintf("failed to convert: %v", err))
+		}
+	}
+	for _, u := range report.UnsupportedAnnotations {
+		keep(u.Ingress, fmt.Sprintf("uses annotation %s, which is not supported", u.Annotation))
+	}
+
+	routes := map[types.NamespacedName][]ObjectRef{}
+	for ref, sources := range resources.Sources {
+		if ref.Kind == "Gateway" {
+			continue
+		}
+		for _, source := range sources {
+			if report.Confidence[ref].Level == ConfidencePartial {
+				keep(source.Ingress, fmt.Sprintf("%s %s/%s is partially converted", ref.Kind, ref.Namespace, ref.Name))
+			}
+			routes[source.Ingress] = append(routes[source.Ingress], ref)
+		}
+	}
+	var cleanups []IngressCleanup
+	for ingress, ingressRoutes := range routes {
+		sortObjectRefs(ingressRoutes)
+		cleanups = append(cleanups, IngressCleanup{Ingress: ingress, Routes: ingressRoutes, Reason: reasons[ingress]})
+	}
+	sort.Slice(cleanups, func(i, j int) bool {
+		return cleanups[i].Ingress.String() < cleanups[j].Ingress.String()
+	})
+	return cleanups
+}
+
+func sortObjectRefs(refs []ObjectRef) {
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Kind != refs[j].Kind {
+			return refs[i].Kind < refs[j].Kind
+		}
+		if refs[i].Namespace != refs[j].Namespace {
+			return refs[i].Namespace < refs[j].Namespace
+		}
+		return refs[i].Name < refs[j].Name
+	})
+}
+
+// VerifyCleanup keeps the deletable Ingresses of cleanups with a route
+// that isn't accepted, with resolved references, by every parent in the
+// cluster of cl, or doesn't exist.
+func VerifyCleanup(ctx context.Context, cl client.Client, cleanups []IngressCleanup) error {
+	accepted := map[ObjectRef]string{}
+	for i := range cleanups {
+		if !cleanups[i].Deletable() {
+			continue
+		}
+		for _, ref := range cleanups[i].Routes {
+			reason, ok := accepted[ref]
+			if !ok {
+				var err error
+				if reason, err = resourceAcceptance(ctx, cl, ref); err != nil {
+					return err
+				}
+				if reason != "" {
+					reason = RejectedResource{Ref: ref, Reason: reason}.Error()
+				}
+				accepted[ref] = reason
+			}
+			if reason != "" {
+				cleanups[i].Reason = reason
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// WriteCleanupScript writes a shell script deleting the deletable Ingresses
+// of cleanups with kubectl. The Ingresses to keep are listed with their
+// reason, their commands commented out.
+func WriteCleanupScript(w io.Writer, cleanups []IngressCleanup) error {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	b.WriteString("# Deletes the Ingresses migrated to Gateway API. Review it before running it.\n")
+	b.WriteString("set -e\n")
+	for _, cleanup := range cleanups {
+		var routes []string
+		for _, ref := range cleanup.Routes {
+			routes = append(routes, fmt.Sprintf("%s %s/%s", ref.Kind, ref.Namespace, ref.Name))
+		}
+		fmt.Fprintf(&b, "\n# Ingress %s is migrated to %s\n", cleanup.Ingress, strings.Join(routes, ", "))
+		command := fmt.Sprintf("kubectl delete ingress --namespace %s %s\n", cleanup.Ingress.Namespace, cleanup.Ingress.Name)
+		if cleanup.Deletable() {
+			b.WriteString(command)
+			continue
+		}
+		fmt.Fprintf(&b, "# Kept: %s\n", cleanup.Reason)
+		b.WriteString("# " + command)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeCleanupFile writes the cleanup script of the conversion, verifying
+// the routes are accepted in the cluster of cl first, if set.
+func writeCleanupFile(ctx context.Context, path string, cl client.Client, resources Resources, report Report) error {
+	cleanups := Cleanup(resources, report)
+	if cl != nil {
+		if err := VerifyCleanup(ctx, cl, cleanups); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o755)
+	if err != nil {
+		return fmt.Errorf("failed to create cleanup file: %w", err)
+	}
+	if err := WriteCleanupScript(f, cleanups); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
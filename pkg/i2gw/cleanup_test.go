@@ -0,0 +1,107 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_Cleanup(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	web := ingressWithPath("web", "/", &prefix, serviceBackend("web", 80), nil)
+	api := ingressWithPath("api", "/", &prefix, serviceBackend("api", 80), nil)
+	api.Spec.Rules[0].Host = "api.example.com"
+	shop := ingressWithPath("shop", "/", &prefix, serviceBackend("shop", 80), map[string]string{"nginx.ingress.kubernetes.io/unknown": "true"})
+	shop.Spec.Rules[0].Host = "shop.example.com"
+
+	resources, report, err := Convert(context.Background(), ConvertOptions{Ingresses: []networkingv1.Ingress{web, api, shop}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	cleanups := Cleanup(resources, report)
+	expected := []IngressCleanup{
+		{
+			Ingress: types.NamespacedName{Namespace: "test", Name: "api"},
+			Routes:  []ObjectRef{{Kind: "HTTPRoute", Namespace: "test", Name: "api-example-com"}},
+		},
+		{
+			Ingress: types.NamespacedName{Namespace: "test", Name: "shop"},
+			Routes:  []ObjectRef{{Kind: "HTTPRoute", Namespace: "test", Name: "shop-example-com"}},
+			Reason:  "uses annotation nginx.ingress.kubernetes.io/unknown, which is not supported",
+		},
+		{
+			Ingress: types.NamespacedName{Namespace: "test", Name: "web"},
+			Routes:  []ObjectRef{{Kind: "HTTPRoute", Namespace: "test", Name: "example-com"}},
+		},
+	}
+	if diff := cmp.Diff(expected, cleanups); diff != "" {
+		t.Fatalf("Unexpected cleanups, diff (-want +got): %s", diff)
+	}
+
+	route := func(name, acceptedStatus string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(httpRouteGVK)
+		u.SetNamespace("test")
+		u.SetName(name)
+		if acceptedStatus != "" {
+			u.Object["status"] = map[string]any{
+				"parents": []any{map[string]any{
+					"parentRef": map[string]any{"name": "nginx"},
+					"conditions": []any{
+						map[string]any{"type": "Accepted", "status": acceptedStatus, "reason": "NotAllowedByListeners"},
+						map[string]any{"type": "ResolvedRefs", "status": "True"},
+					},
+				}},
+			}
+		}
+		return u
+	}
+	cl := fake.NewClientBuilder().WithObjects(route("example-com", "True"), route("api-example-com", "False")).Build()
+	if err := VerifyCleanup(context.Background(), cl, cleanups); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCleanupScript(&buf, cleanups); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectedScript := `#!/bin/sh
+# Deletes the Ingresses migrated to Gateway API. Review it before running it.
+set -e
+
+# Ingress test/api is migrated to HTTPRoute test/api-example-com
+# Kept: HTTPRoute test/api-example-com is not accepted: parent nginx: Accepted condition is False: NotAllowedByListeners
+# kubectl delete ingress --namespace test api
+
+# Ingress test/shop is migrated to HTTPRoute test/shop-example-com
+# Kept: uses annotation nginx.ingress.kubernetes.io/unknown, which is not supported
+# kubectl delete ingress --namespace test shop
+
+# Ingress test/web is migrated to HTTPRoute test/example-com
+kubectl delete ingress --namespace test web
+`
+	if diff := cmp.Diff(expectedScript, buf.String()); diff != "" {
+		t.Errorf("Unexpected cleanup script, diff (-want +got): %s", diff)
+	}
+}
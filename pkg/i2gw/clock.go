@@ -0,0 +1,46 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import "time"
+
+// Clock tells the time the reports of a run, such as the statistics and the
+// fleet report, are generated at.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to a Clock.
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// FixedClock returns a Clock always telling t, for reproducible reports
+// such as golden files.
+func FixedClock(t time.Time) Clock {
+	return ClockFunc(func() time.Time { return t })
+}
+
+// runClock returns the clock of the run options, the system clock if none
+// is set.
+func runClock(runOpts RunOptions) Clock {
+	if runOpts.Clock != nil {
+		return runOpts.Clock
+	}
+	return ClockFunc(time.Now)
+}
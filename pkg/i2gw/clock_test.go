@@ -0,0 +1,52 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_runClock(t *testing.T) {
+	fixed := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if got := runClock(RunOptions{Clock: FixedClock(fixed)}).Now(); !got.Equal(fixed) {
+		t.Errorf("runClock() with a fixed clock = %v, want %v", got, fixed)
+	}
+
+	before := time.Now()
+	got := runClock(RunOptions{}).Now()
+	if got.Before(before) || got.After(time.Now()) {
+		t.Errorf("runClock() without a clock = %v, want the current time", got)
+	}
+}
+
+func Test_writeStatsFile_clock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.json")
+	runOpts := RunOptions{StatsFile: path, Clock: FixedClock(time.Date(2024, 5, 1, 14, 0, 0, 0, time.FixedZone("CEST", 2*60*60)))}
+	if err := writeStatsFile(runOpts, newStatsReport()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"generatedAt": "2024-05-01T12:00:00Z"`) {
+		t.Errorf("Expected the time of the clock in UTC, got %s", data)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// PathCollisionPolicy selects how the paths of several Ingresses matching
+// the same requests of a host with different backends are converted.
+type PathCollisionPolicy string
+
+const (
+	// PathCollisionMergeWeighted sends the requests of the path to the
+	// backends of every Ingress, split evenly by the rule.
+	PathCollisionMergeWeighted PathCollisionPolicy = "merge-weighted"
+	// PathCollisionFirstWins only converts the path of the Ingress sorted
+	// first, the oldest one.
+	PathCollisionFirstWins PathCollisionPolicy = "first-wins"
+	// PathCollisionError only converts the path of the Ingress sorted first
+	// and reports an error for the other Ingresses, which strict mode
+	// leaves out of the conversion.
+	PathCollisionError PathCollisionPolicy = "error"
+)
+
+// PathCollisionPolicies returns the names of the supported path collision
+// policies.
+func PathCollisionPolicies() []string {
+	return []string{string(PathCollisionMergeWeighted), string(PathCollisionFirstWins), string(PathCollisionError)}
+}
+
+func validatePathCollisionPolicy(policy PathCollisionPolicy) error {
+	switch policy {
+	case "", PathCollisionMergeWeighted, PathCollisionFirstWins, PathCollisionError:
+		return nil
+	default:
+		return fmt.Errorf("unknown path collision policy %q, supported ones are: %s", policy, strings.Join(PathCollisionPolicies(), ", "))
+	}
+}
+
+// resolvePathCollision applies the policy to the paths of a rule group
+// matching the same requests, in the order of their Ingresses, and returns
+// the paths to convert. Paths of the same Ingress and the ones with the
+// backend of the first path don't collide.
+func (rg *ingressRuleGroup) resolvePathCollision(paths []ingressPath, policy PathCollisionPolicy) ([]ingressPath, []Notification, []error) {
+	if len(paths) == 1 {
+		return paths, nil, nil
+	}
+	first := paths[0]
+	var colliding []string
+	kept := []ingressPath{first}
+	var errors []error
+	for _, path := range paths[1:] {
+		if path.ingressName == first.ingressName || reflect.DeepEqual(path.path.Backend, first.path.Backend) {
+			kept = append(kept, path)
+			continue
+		}
+		if !slices.Contains(colliding, path.ingressName) {
+			colliding = append(colliding, path.ingressName)
+		}
+		switch policy {
+		case PathCollisionFirstWins:
+		case PathCollisionError:
+			errors = append(errors, ingressError{
+				ingress: types.NamespacedName{Namespace: rg.namespace, Name: path.ingressName},
+				err:     fmt.Errorf("path %q of host %q is already routed to other backends by Ingress %s/%s", first.path.Path, rg.host, rg.namespace, first.ingressName),
+			})
+		default:
+			kept = append(kept, path)
+		}
+	}
+	if len(colliding) == 0 {
+		return paths, nil, nil
+	}
+
+	var notes []Notification
+	others := fmt.Sprintf("%s/%s", rg.namespace, strings.Join(colliding, ", "+rg.namespace+"/"))
+	switch policy {
+	case PathCollisionFirstWins, PathCollisionError:
+		notes = append(notes, notifications.NewWarning("Ingresses %s/%s and %s route path %q of host %q to different backends, only the backends of %s/%s are kept", rg.namespace, first.ingressName, others, first.path.Path, rg.host, rg.namespace, first.ingressName))
+	default:
+		notes = append(notes, notifications.NewWarning("Ingresses %s/%s and %s route path %q of host %q to different backends, its requests are split between their backends, use --path-collisions=first-wins to keep the backends of %s/%s only", rg.namespace, first.ingressName, others, first.path.Path, rg.host, rg.namespace, first.ingressName))
+	}
+	return kept, notes, errors
+}
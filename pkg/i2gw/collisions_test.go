@@ -0,0 +1,112 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func Test_resolvePathCollision(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	web := ingressWithPath("web", "/", &iPrefix, serviceBackend("web", 80), nil)
+	web.CreationTimestamp = metav1.Unix(1, 0)
+	legacy := ingressWithPath("legacy", "/", &iPrefix, serviceBackend("legacy", 80), nil)
+	legacy.CreationTimestamp = metav1.Unix(2, 0)
+	webCopy := ingressWithPath("web-copy", "/", &iPrefix, serviceBackend("web", 80), nil)
+	webCopy.CreationTimestamp = metav1.Unix(3, 0)
+
+	testCases := []struct {
+		name         string
+		policy       PathCollisionPolicy
+		wantBackends []string
+		wantNote     string
+		wantErrors   int
+	}{
+		{
+			name:         "merge-weighted by default",
+			wantBackends: []string{"web", "legacy", "web"},
+			wantNote:     `WARNING: Ingresses test/web and test/legacy route path "/" of host "example.com" to different backends, its requests are split between their backends, use --path-collisions=first-wins to keep the backends of test/web only`,
+		},
+		{
+			name:         "first-wins",
+			policy:       PathCollisionFirstWins,
+			wantBackends: []string{"web", "web"},
+			wantNote:     `WARNING: Ingresses test/web and test/legacy route path "/" of host "example.com" to different backends, only the backends of test/web are kept`,
+		},
+		{
+			name:         "error",
+			policy:       PathCollisionError,
+			wantBackends: []string{"web", "web"},
+			wantNote:     `WARNING: Ingresses test/web and test/legacy route path "/" of host "example.com" to different backends, only the backends of test/web are kept`,
+			wantErrors:   1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resources, report := convertInput(inputResources{ingresses: []networkingv1.Ingress{webCopy, legacy, web}}, ConvertOptions{PathCollisions: tc.policy})
+			if len(resources.HTTPRoutes) != 1 || len(resources.HTTPRoutes[0].Spec.Rules) != 1 {
+				t.Fatalf("expected a single HTTPRoute with a single rule, got %v", resources.HTTPRoutes)
+			}
+			var gotBackends []string
+			for _, ref := range resources.HTTPRoutes[0].Spec.Rules[0].BackendRefs {
+				gotBackends = append(gotBackends, string(ref.Name))
+			}
+			if diff := cmp.Diff(tc.wantBackends, gotBackends); diff != "" {
+				t.Errorf("unexpected backends (-want +got):\n%s", diff)
+			}
+			found := false
+			for _, n := range report.Notifications {
+				found = found || n.String() == tc.wantNote
+			}
+			if !found {
+				t.Errorf("expected notification %q, got %v", tc.wantNote, report.Notifications)
+			}
+			if len(report.Errors) != tc.wantErrors {
+				t.Fatalf("expected %d errors, got %v", tc.wantErrors, report.Errors)
+			}
+			if tc.wantErrors > 0 {
+				want := `path "/" of host "example.com" is already routed to other backends by Ingress test/web`
+				if report.Errors[0].Error() != want {
+					t.Errorf("expected error %q, got %q", want, report.Errors[0])
+				}
+			}
+		})
+	}
+
+	resources, _ := convertInput(inputResources{ingresses: []networkingv1.Ingress{legacy, web}}, ConvertOptions{PathCollisions: PathCollisionError, Mode: ModeStrict})
+	if diff := cmp.Diff([]types.NamespacedName{{Namespace: "test", Name: "web"}}, routeIngresses(resources)); diff != "" {
+		t.Errorf("unexpected Ingresses of the HTTPRoutes in strict mode (-want +got):\n%s", diff)
+	}
+}
+
+func routeIngresses(resources Resources) []types.NamespacedName {
+	var ingresses []types.NamespacedName
+	for ref, sources := range resources.Sources {
+		if ref.Kind != "HTTPRoute" {
+			continue
+		}
+		for _, source := range sources {
+			ingresses = append(ingresses, source.Ingress)
+		}
+	}
+	return ingresses
+}
@@ -0,0 +1,133 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"slices"
+)
+
+// Confidence tells how faithfully a generated resource reproduces the
+// behavior of the Ingresses it was converted from.
+type Confidence string
+
+const (
+	// ConfidenceExact resources map every feature of their Ingresses.
+	ConfidenceExact Confidence = "exact"
+	// ConfidenceApproximated resources map some features of their
+	// Ingresses with a different behavior, as reported by warnings.
+	ConfidenceApproximated Confidence = "approximated"
+	// ConfidencePartial resources miss features of their Ingresses, which
+	// failed to convert or have no equivalent, such as unsupported
+	// annotations.
+	ConfidencePartial Confidence = "partial"
+)
+
+// ConfidenceAnnotation is the confidence of a Gateway or HTTPRoute,
+// annotated with ConvertOptions.ConfidenceAnnotations.
+const ConfidenceAnnotation = "ingress2gateway.kubernetes.io/confidence"
+
+// ResourceConfidence is the confidence of a generated resource and the
+// findings it is based on.
+type ResourceConfidence struct {
+	Level Confidence
+	// Reasons are the messages of the errors, blocking notifications,
+	// unsupported annotations and warnings about the resource or the
+	// Ingresses it was converted from.
+	Reasons []string
+}
+
+// confidenceRank orders the levels from the most to the least confident.
+func confidenceRank(level Confidence) int {
+	switch level {
+	case ConfidencePartial:
+		return 2
+	case ConfidenceApproximated:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// findingConfidence returns the confidence a finding leaves its objects
+// with. Infos don't lower the confidence.
+func findingConfidence(finding Finding) Confidence {
+	switch {
+	case finding.Severity == FindingSeverityError,
+		finding.Severity == string(BlockingNotification),
+		finding.RuleID == RuleUnsupportedAnnotation:
+		return ConfidencePartial
+	case finding.Severity == string(WarningNotification) && finding.RuleID != RuleSkippedIngress:
+		return ConfidenceApproximated
+	default:
+		return ConfidenceExact
+	}
+}
+
+// resourceConfidences returns the confidence of every Gateway and HTTPRoute
+// converted from Ingresses: the lowest confidence left by the findings
+// about the resource or any of its Ingresses.
+func resourceConfidences(resources Resources, report Report) map[ObjectRef]ResourceConfidence {
+	byObject := map[FindingObject][]Finding{}
+	for _, finding := range Findings(report) {
+		level := findingConfidence(finding)
+		if level == ConfidenceExact {
+			continue
+		}
+		for _, obj := range finding.Objects {
+			byObject[obj] = append(byObject[obj], finding)
+		}
+	}
+
+	confidences := map[ObjectRef]ResourceConfidence{}
+	for ref, sources := range resources.Sources {
+		objects := []FindingObject{{Kind: ref.Kind, Namespace: ref.Namespace, Name: ref.Name}}
+		for _, source := range sources {
+			objects = append(objects, FindingObject{Kind: "Ingress", Namespace: source.Ingress.Namespace, Name: source.Ingress.Name})
+		}
+		confidence := ResourceConfidence{Level: ConfidenceExact}
+		for _, obj := range objects {
+			for _, finding := range byObject[obj] {
+				if slices.Contains(confidence.Reasons, finding.Message) {
+					continue
+				}
+				confidence.Reasons = append(confidence.Reasons, finding.Message)
+				if level := findingConfidence(finding); confidenceRank(level) > confidenceRank(confidence.Level) {
+					confidence.Level = level
+				}
+			}
+		}
+		confidences[ref] = confidence
+	}
+	return confidences
+}
+
+// stampConfidences annotates the Gateways and HTTPRoutes with their
+// confidence.
+func stampConfidences(resources *Resources, confidences map[ObjectRef]ResourceConfidence) {
+	for _, obj := range resourceObjects(*resources) {
+		ref := ObjectRef{Kind: obj.GetObjectKind().GroupVersionKind().Kind, Namespace: obj.GetNamespace(), Name: obj.GetName()}
+		confidence, ok := confidences[ref]
+		if !ok {
+			continue
+		}
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[ConfidenceAnnotation] = string(confidence.Level)
+		obj.SetAnnotations(annotations)
+	}
+}
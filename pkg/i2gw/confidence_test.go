@@ -0,0 +1,124 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func Test_resourceConfidences(t *testing.T) {
+	web := types.NamespacedName{Namespace: "default", Name: "web"}
+	api := types.NamespacedName{Namespace: "default", Name: "api"}
+	webRoute := ObjectRef{Kind: "HTTPRoute", Namespace: "default", Name: "web-example-com"}
+	apiRoute := ObjectRef{Kind: "HTTPRoute", Namespace: "default", Name: "api-example-com"}
+	gateway := ObjectRef{Kind: "Gateway", Namespace: "default", Name: "nginx"}
+	resources := Resources{
+		Sources: map[ObjectRef][]IngressSource{
+			webRoute: {{Ingress: web}},
+			apiRoute: {{Ingress: api}},
+			gateway:  {{Ingress: api}, {Ingress: web}},
+		},
+	}
+
+	testCases := []struct {
+		name   string
+		report Report
+		want   map[ObjectRef]ResourceConfidence
+	}{
+		{
+			name: "no findings",
+			report: Report{
+				Notifications: []Notification{notifications.NewInfo("Ingress default/web is converted")},
+			},
+			want: map[ObjectRef]ResourceConfidence{
+				webRoute: {Level: ConfidenceExact},
+				apiRoute: {Level: ConfidenceExact},
+				gateway:  {Level: ConfidenceExact},
+			},
+		},
+		{
+			name: "warnings about an Ingress approximate its resources",
+			report: Report{
+				Notifications: []Notification{notifications.NewWarning("Ingress default/web uses a regex path")},
+			},
+			want: map[ObjectRef]ResourceConfidence{
+				webRoute: {Level: ConfidenceApproximated, Reasons: []string{"Ingress default/web uses a regex path"}},
+				apiRoute: {Level: ConfidenceExact},
+				gateway:  {Level: ConfidenceApproximated, Reasons: []string{"Ingress default/web uses a regex path"}},
+			},
+		},
+		{
+			name: "unsupported annotations and errors make resources partial",
+			report: Report{
+				Notifications: []Notification{notifications.NewWarning("Ingress default/api uses a regex path")},
+				Errors:        []error{errors.New("HTTPRoute default/api-example-com is invalid: too many rules")},
+				UnsupportedAnnotations: []UnsupportedAnnotation{
+					{Ingress: web, Annotation: "nginx.ingress.kubernetes.io/server-snippet"},
+				},
+			},
+			want: map[ObjectRef]ResourceConfidence{
+				webRoute: {Level: ConfidencePartial, Reasons: []string{
+					"Ingress default/web uses annotation nginx.ingress.kubernetes.io/server-snippet, which is not converted",
+				}},
+				apiRoute: {Level: ConfidencePartial, Reasons: []string{
+					"HTTPRoute default/api-example-com is invalid: too many rules",
+					"Ingress default/api uses a regex path",
+				}},
+				gateway: {Level: ConfidencePartial, Reasons: []string{
+					"Ingress default/api uses a regex path",
+					"Ingress default/web uses annotation nginx.ingress.kubernetes.io/server-snippet, which is not converted",
+				}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := resourceConfidences(resources, tc.report)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected confidences, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func Test_confidenceAnnotations(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	input := inputResources{ingresses: []networkingv1.Ingress{
+		ingressWithPath("web", "/", &iPrefix, serviceBackend("web", 80), nil),
+	}}
+
+	resources, report := convertInput(input, ConvertOptions{ConfidenceAnnotations: true})
+	if len(resources.HTTPRoutes) != 1 || len(resources.Gateways) != 1 {
+		t.Fatalf("Expected a Gateway and an HTTPRoute, got %d and %d", len(resources.Gateways), len(resources.HTTPRoutes))
+	}
+	for _, obj := range resourceObjects(resources) {
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		if got := obj.GetAnnotations()[ConfidenceAnnotation]; got != string(ConfidenceExact) {
+			t.Errorf("Expected %s %s to be annotated as %s, got %q", kind, obj.GetName(), ConfidenceExact, got)
+		}
+		ref := ObjectRef{Kind: kind, Namespace: obj.GetNamespace(), Name: obj.GetName()}
+		if got := report.Confidence[ref].Level; got != ConfidenceExact {
+			t.Errorf("Expected %s %s to be reported as %s, got %q", kind, obj.GetName(), ConfidenceExact, got)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"reflect"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// consolidateHTTPRoutes collapses the identical parts of the rules of the
+// HTTPRoutes, typical of Ingresses generated from the same template: the
+// backendRefs of a rule to the same backend are merged, adding up their
+// weights, and the rules differing only by their matches are merged into
+// the first of them, up to the matches a rule allows. Requests are routed
+// the same, Gateway API ordering the matches of all the rules of a route by
+// precedence regardless of their rule.
+func consolidateHTTPRoutes(httpRoutes []gatewayv1.HTTPRoute) []Notification {
+	var notes []Notification
+	for i := range httpRoutes {
+		route := &httpRoutes[i]
+		backends := 0
+		for j := range route.Spec.Rules {
+			backends += consolidateBackendRefs(&route.Spec.Rules[j])
+		}
+		rules := len(route.Spec.Rules)
+		route.Spec.Rules = consolidateRules(route.Spec.Rules)
+		if merged := rules - len(route.Spec.Rules); merged > 0 || backends > 0 {
+			notes = append(notes, notifications.NewInfo("HTTPRoute %s/%s is consolidated: %d rules are merged into rules with the same backends and filters, %d duplicate backendRefs are merged", route.Namespace, route.Name, merged, backends))
+		}
+	}
+	return notes
+}
+
+// consolidateBackendRefs merges the backendRefs of a rule to the same
+// backend and returns how many were merged. Weights default to 1, so the
+// merged backendRefs get explicit weights when the rule keeps several.
+func consolidateBackendRefs(rule *gatewayv1.HTTPRouteRule) int {
+	var refs []gatewayv1.HTTPBackendRef
+	var weights []int32
+	weighted := false
+	for _, ref := range rule.BackendRefs {
+		weight := int32(1)
+		if ref.Weight != nil {
+			weight, weighted = *ref.Weight, true
+		}
+		i := indexBackendRef(refs, ref)
+		if i < 0 {
+			refs = append(refs, ref)
+			weights = append(weights, weight)
+			continue
+		}
+		weights[i] += weight
+	}
+	merged := len(rule.BackendRefs) - len(refs)
+	if merged == 0 {
+		return 0
+	}
+	for i := range refs {
+		if weighted || len(refs) > 1 {
+			refs[i].Weight = &weights[i]
+		} else {
+			refs[i].Weight = nil
+		}
+	}
+	rule.BackendRefs = refs
+	return merged
+}
+
+func indexBackendRef(refs []gatewayv1.HTTPBackendRef, ref gatewayv1.HTTPBackendRef) int {
+	ref.Weight = nil
+	for i, r := range refs {
+		r.Weight = nil
+		if reflect.DeepEqual(r, ref) {
+			return i
+		}
+	}
+	return -1
+}
+
+// consolidateRules merges the rules differing only by their matches. Rules
+// without matches match every request and are kept as is.
+func consolidateRules(rules []gatewayv1.HTTPRouteRule) []gatewayv1.HTTPRouteRule {
+	var consolidated []gatewayv1.HTTPRouteRule
+	for _, rule := range rules {
+		i := -1
+		if len(rule.Matches) > 0 {
+			i = indexSameRule(consolidated, rule)
+		}
+		if i < 0 {
+			consolidated = append(consolidated, rule)
+			continue
+		}
+		for _, match := range rule.Matches {
+			if !containsMatch(consolidated[i].Matches, match) {
+				consolidated[i].Matches = append(consolidated[i].Matches, match)
+			}
+		}
+	}
+	return consolidated
+}
+
+// indexSameRule returns the index of the rule of rules with the same
+// backends and filters as rule, with room for its matches.
+func indexSameRule(rules []gatewayv1.HTTPRouteRule, rule gatewayv1.HTTPRouteRule) int {
+	withoutMatches := rule
+	withoutMatches.Matches = nil
+	for i, r := range rules {
+		if len(r.Matches) == 0 {
+			continue
+		}
+		added := 0
+		for _, match := range rule.Matches {
+			if !containsMatch(r.Matches, match) {
+				added++
+			}
+		}
+		if len(r.Matches)+added > maxHTTPRouteMatches {
+			continue
+		}
+		r.Matches = nil
+		if reflect.DeepEqual(r, withoutMatches) {
+			return i
+		}
+	}
+	return -1
+}
+
+func containsMatch(matches []gatewayv1.HTTPRouteMatch, match gatewayv1.HTTPRouteMatch) bool {
+	for _, m := range matches {
+		if reflect.DeepEqual(m, match) {
+			return true
+		}
+	}
+	return false
+}
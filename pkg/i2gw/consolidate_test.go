@@ -0,0 +1,133 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_consolidateHTTPRoutes(t *testing.T) {
+	backendRef := func(name string, weight *int32) gatewayv1.HTTPBackendRef {
+		return gatewayv1.HTTPBackendRef{BackendRef: gatewayv1.BackendRef{
+			BackendObjectReference: gatewayv1.BackendObjectReference{Name: gatewayv1.ObjectName(name), Port: ptrTo(gatewayv1.PortNumber(80))},
+			Weight:                 weight,
+		}}
+	}
+	match := func(path string) gatewayv1.HTTPRouteMatch {
+		return gatewayv1.HTTPRouteMatch{Path: &gatewayv1.HTTPPathMatch{Type: ptrTo(gatewayv1.PathMatchPathPrefix), Value: ptrTo(path)}}
+	}
+
+	testCases := []struct {
+		name      string
+		rules     []gatewayv1.HTTPRouteRule
+		wantRules []gatewayv1.HTTPRouteRule
+		wantNotes int
+	}{
+		{
+			name: "duplicate backendRefs are merged",
+			rules: []gatewayv1.HTTPRouteRule{{
+				Matches:     []gatewayv1.HTTPRouteMatch{match("/")},
+				BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("web", nil), backendRef("web", nil)},
+			}},
+			wantRules: []gatewayv1.HTTPRouteRule{{
+				Matches:     []gatewayv1.HTTPRouteMatch{match("/")},
+				BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("web", nil)},
+			}},
+			wantNotes: 1,
+		},
+		{
+			name: "merged backendRefs keep their share of the traffic",
+			rules: []gatewayv1.HTTPRouteRule{{
+				Matches:     []gatewayv1.HTTPRouteMatch{match("/")},
+				BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("web", nil), backendRef("canary", nil), backendRef("web", nil)},
+			}},
+			wantRules: []gatewayv1.HTTPRouteRule{{
+				Matches:     []gatewayv1.HTTPRouteMatch{match("/")},
+				BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("web", ptrTo[int32](2)), backendRef("canary", ptrTo[int32](1))},
+			}},
+			wantNotes: 1,
+		},
+		{
+			name: "rules differing by their matches are merged",
+			rules: []gatewayv1.HTTPRouteRule{
+				{Matches: []gatewayv1.HTTPRouteMatch{match("/a")}, BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("web", nil)}},
+				{Matches: []gatewayv1.HTTPRouteMatch{match("/b")}, BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("api", nil)}},
+				{Matches: []gatewayv1.HTTPRouteMatch{match("/c"), match("/a")}, BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("web", nil)}},
+			},
+			wantRules: []gatewayv1.HTTPRouteRule{
+				{Matches: []gatewayv1.HTTPRouteMatch{match("/a"), match("/c")}, BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("web", nil)}},
+				{Matches: []gatewayv1.HTTPRouteMatch{match("/b")}, BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("api", nil)}},
+			},
+			wantNotes: 1,
+		},
+		{
+			name: "rules without matches are kept",
+			rules: []gatewayv1.HTTPRouteRule{
+				{Matches: []gatewayv1.HTTPRouteMatch{match("/a")}, BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("web", nil)}},
+				{BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("web", nil)}},
+			},
+			wantRules: []gatewayv1.HTTPRouteRule{
+				{Matches: []gatewayv1.HTTPRouteMatch{match("/a")}, BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("web", nil)}},
+				{BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("web", nil)}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			routes := []gatewayv1.HTTPRoute{{Spec: gatewayv1.HTTPRouteSpec{Rules: tc.rules}}}
+			notes := consolidateHTTPRoutes(routes)
+			if diff := cmp.Diff(tc.wantRules, routes[0].Spec.Rules); diff != "" {
+				t.Errorf("unexpected rules (-want +got):\n%s", diff)
+			}
+			if len(notes) != tc.wantNotes {
+				t.Errorf("expected %d notifications, got %v", tc.wantNotes, notes)
+			}
+		})
+	}
+}
+
+func Test_consolidateRulesOfIngresses(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	var ingresses []networkingv1.Ingress
+	for _, path := range []string{"/a", "/b", "/c"} {
+		ingress := ingressWithPath("web"+path[1:], path, &iPrefix, serviceBackend("web", 80), nil)
+		ingresses = append(ingresses, ingress)
+	}
+
+	resources, report := convertInput(inputResources{ingresses: ingresses}, ConvertOptions{ConsolidateRules: true})
+	if len(resources.HTTPRoutes) != 1 || len(resources.HTTPRoutes[0].Spec.Rules) != 1 {
+		t.Fatalf("expected a single HTTPRoute with a single rule, got %v", resources.HTTPRoutes)
+	}
+	if got := len(resources.HTTPRoutes[0].Spec.Rules[0].Matches); got != 3 {
+		t.Errorf("expected 3 matches, got %d", got)
+	}
+	if len(report.SkippedIngresses) != 0 {
+		t.Errorf("expected no skipped Ingresses, got %v", report.SkippedIngresses)
+	}
+	want := "INFO: HTTPRoute test/example-com is consolidated: 2 rules are merged into rules with the same backends and filters, 0 duplicate backendRefs are merged"
+	found := false
+	for _, n := range report.Notifications {
+		found = found || n.String() == want
+	}
+	if !found {
+		t.Errorf("expected notification %q, got %v", want, report.Notifications)
+	}
+}
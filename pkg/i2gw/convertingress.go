@@ -0,0 +1,40 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+// ConvertIngress converts a single Ingress on its own to the HTTPRoutes and
+// other resources it would get, for fast previews of an object, such as in
+// editors and admission webhooks. The Ingresses and inputs of opts are
+// ignored, only the IngressClasses and Objects of opts are read, and the
+// Gateways only have the listeners of the Ingress, while converting it with
+// the other Ingresses would merge them with theirs. An error is only
+// returned for invalid options.
+func ConvertIngress(ingress networkingv1.Ingress, opts ConvertOptions) (Resources, Report, error) {
+	if err := validateConvertOptions(opts); err != nil {
+		return Resources{}, Report{}, err
+	}
+	input := inputResources{
+		ingresses:      []networkingv1.Ingress{ingress},
+		ingressClasses: opts.IngressClasses,
+		objects:        opts.Objects,
+	}
+	resources, report := convertInput(input, opts)
+	return resources, report, nil
+}
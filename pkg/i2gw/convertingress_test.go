@@ -0,0 +1,82 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+func Test_ConvertIngress(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	web := ingressWithPath("web", "/", &iPrefix, serviceBackend("web", 80), nil)
+	api := ingressWithPath("api", "/", &iPrefix, serviceBackend("api", 80), nil)
+	api.Spec.Rules[0].Host = "api.example.com"
+
+	testCases := []struct {
+		name          string
+		opts          ConvertOptions
+		wantRoutes    []string
+		wantListeners []string
+		expectError   string
+	}{{
+		name:          "single Ingress",
+		wantRoutes:    []string{"test/example-com"},
+		wantListeners: []string{"example-com-http"},
+	}, {
+		name:          "other Ingresses of the options ignored",
+		opts:          ConvertOptions{Ingresses: []networkingv1.Ingress{api}},
+		wantRoutes:    []string{"test/example-com"},
+		wantListeners: []string{"example-com-http"},
+	}, {
+		name:        "invalid options",
+		opts:        ConvertOptions{ListenerStrategy: "unknown"},
+		expectError: `unknown listener strategy "unknown", supported ones are: host, certificate`,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resources, report, err := ConvertIngress(web, tc.opts)
+			var gotError string
+			if err != nil {
+				gotError = err.Error()
+			}
+			if diff := cmp.Diff(tc.expectError, gotError); diff != "" {
+				t.Fatalf("unexpected error (-want +got):\n%s", diff)
+			}
+			if len(report.Errors) > 0 {
+				t.Fatalf("unexpected errors: %v", report.Errors)
+			}
+			var gotRoutes, gotListeners []string
+			for _, route := range resources.HTTPRoutes {
+				gotRoutes = append(gotRoutes, route.Namespace+"/"+route.Name)
+			}
+			for _, gw := range resources.Gateways {
+				for _, l := range gw.Spec.Listeners {
+					gotListeners = append(gotListeners, string(l.Name))
+				}
+			}
+			if diff := cmp.Diff(tc.wantRoutes, gotRoutes); diff != "" {
+				t.Errorf("unexpected HTTPRoutes (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantListeners, gotListeners); diff != "" {
+				t.Errorf("unexpected listeners (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
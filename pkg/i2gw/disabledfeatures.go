@@ -0,0 +1,211 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// conversionFeature is a part of the features of Ingresses that can be left
+// out of the conversion, for users to handle it manually.
+type conversionFeature struct {
+	name string
+	// get returns the value of the feature, the zero value if unset.
+	get func(f *ir.IngressFeatures) any
+	// clear unsets the feature.
+	clear func(f *ir.IngressFeatures)
+}
+
+// policyField returns a conversionFeature of a field of the Policy of the
+// features.
+func policyField(name string, get func(p *ir.Policy) any, clear func(p *ir.Policy)) conversionFeature {
+	return conversionFeature{
+		name: name,
+		get: func(f *ir.IngressFeatures) any {
+			if f.Policy == nil {
+				return nil
+			}
+			return get(f.Policy)
+		},
+		clear: func(f *ir.IngressFeatures) {
+			if f.Policy == nil {
+				return
+			}
+			// The Policy may be shared with the provider.
+			policy := *f.Policy
+			clear(&policy)
+			f.Policy = &policy
+			if policy.IsEmpty() {
+				f.Policy = nil
+			}
+		},
+	}
+}
+
+var conversionFeatures = []conversionFeature{
+	{
+		name:  "canary",
+		get:   func(f *ir.IngressFeatures) any { return f.Canary },
+		clear: func(f *ir.IngressFeatures) { f.Canary = nil },
+	},
+	{
+		name:  "rewrites",
+		get:   func(f *ir.IngressFeatures) any { return f.Rewrites },
+		clear: func(f *ir.IngressFeatures) { f.Rewrites = nil },
+	},
+	{
+		name:  "headers",
+		get:   func(f *ir.IngressFeatures) any { return f.Headers },
+		clear: func(f *ir.IngressFeatures) { f.Headers = nil },
+	},
+	{
+		name:  "path-overrides",
+		get:   func(f *ir.IngressFeatures) any { return f.PathOverrides },
+		clear: func(f *ir.IngressFeatures) { f.PathOverrides = nil },
+	},
+	{
+		name:  "matches",
+		get:   func(f *ir.IngressFeatures) any { return f.Matches },
+		clear: func(f *ir.IngressFeatures) { f.Matches = nil },
+	},
+	{
+		name:  "groups",
+		get:   func(f *ir.IngressFeatures) any { return f.Group },
+		clear: func(f *ir.IngressFeatures) { f.Group = "" },
+	},
+	{
+		name:  "listen-ports",
+		get:   func(f *ir.IngressFeatures) any { return f.ListenPorts },
+		clear: func(f *ir.IngressFeatures) { f.ListenPorts = nil },
+	},
+	policyField("timeouts", func(p *ir.Policy) any { return p.Timeouts }, func(p *ir.Policy) { p.Timeouts = nil }),
+	policyField("retries", func(p *ir.Policy) any { return p.Retry }, func(p *ir.Policy) { p.Retry = nil }),
+	policyField("rate-limits", func(p *ir.Policy) any { return p.RateLimits }, func(p *ir.Policy) { p.RateLimits = nil }),
+	policyField("ip-allowlist", func(p *ir.Policy) any { return p.IPAllowList }, func(p *ir.Policy) { p.IPAllowList = nil }),
+	policyField("ip-denylist", func(p *ir.Policy) any { return p.IPDenyList }, func(p *ir.Policy) { p.IPDenyList = nil }),
+	policyField("ext-auth", func(p *ir.Policy) any { return p.ExtAuth }, func(p *ir.Policy) { p.ExtAuth = nil }),
+	policyField("oidc", func(p *ir.Policy) any { return p.OIDC }, func(p *ir.Policy) { p.OIDC = nil }),
+	policyField("tls-options", func(p *ir.Policy) any {
+		return struct {
+			ciphers, protocols  []string
+			preferServerCiphers *bool
+		}{p.TLSCiphers, p.TLSProtocols, p.TLSPreferServerCiphers}
+	}, func(p *ir.Policy) {
+		p.TLSCiphers, p.TLSProtocols, p.TLSPreferServerCiphers = nil, nil, nil
+	}),
+	policyField("client-validation", func(p *ir.Policy) any { return p.ClientValidation }, func(p *ir.Policy) { p.ClientValidation = nil }),
+	policyField("ssl-redirect", func(p *ir.Policy) any { return p.SSLRedirect }, func(p *ir.Policy) { p.SSLRedirect = false }),
+	policyField("backend-tls", func(p *ir.Policy) any { return p.BackendTLS }, func(p *ir.Policy) { p.BackendTLS = nil }),
+	policyField("hsts", func(p *ir.Policy) any { return p.HSTS }, func(p *ir.Policy) { p.HSTS = nil }),
+	policyField("custom-errors", func(p *ir.Policy) any { return p.CustomErrors }, func(p *ir.Policy) { p.CustomErrors = nil }),
+	policyField("session-affinity", func(p *ir.Policy) any { return p.ConsistentHash }, func(p *ir.Policy) { p.ConsistentHash = nil }),
+	policyField("load-balancing", func(p *ir.Policy) any { return p.LoadBalance }, func(p *ir.Policy) { p.LoadBalance = "" }),
+	policyField("waf", func(p *ir.Policy) any { return p.WAF }, func(p *ir.Policy) { p.WAF = nil }),
+	policyField("tracing", func(p *ir.Policy) any { return p.Tracing }, func(p *ir.Policy) { p.Tracing = nil }),
+	policyField("compression", func(p *ir.Policy) any { return p.Compression }, func(p *ir.Policy) { p.Compression = nil }),
+	policyField("body-size", func(p *ir.Policy) any { return p.MaxRequestBodySize }, func(p *ir.Policy) { p.MaxRequestBodySize = nil }),
+	policyField("keep-alive", func(p *ir.Policy) any { return p.KeepAliveTimeout }, func(p *ir.Policy) { p.KeepAliveTimeout = 0 }),
+	policyField("http2", func(p *ir.Policy) any { return p.HTTP2 }, func(p *ir.Policy) { p.HTTP2 = nil }),
+	policyField("proxy-protocol", func(p *ir.Policy) any { return p.ProxyProtocol }, func(p *ir.Policy) { p.ProxyProtocol = false }),
+	policyField("client-ip", func(p *ir.Policy) any { return p.ClientIP }, func(p *ir.Policy) { p.ClientIP = nil }),
+	policyField("snippets", func(p *ir.Policy) any { return p.Snippets }, func(p *ir.Policy) { p.Snippets = nil }),
+	policyField("proxy-buffers", func(p *ir.Policy) any { return p.ProxyBuffers }, func(p *ir.Policy) { p.ProxyBuffers = nil }),
+}
+
+// ConversionFeatures returns the names of the features that can be
+// disabled with ConvertOptions.DisabledFeatures.
+func ConversionFeatures() []string {
+	var names []string
+	for _, f := range conversionFeatures {
+		names = append(names, f.name)
+	}
+	return names
+}
+
+func validateDisabledFeatures(names []string) error {
+	for _, name := range names {
+		if !slices.Contains(ConversionFeatures(), name) {
+			return fmt.Errorf("unknown feature %q, supported ones are: %s", name, strings.Join(ConversionFeatures(), ", "))
+		}
+	}
+	return nil
+}
+
+// disabledConversionFeatures returns the conversionFeatures of names.
+func disabledConversionFeatures(names []string) []conversionFeature {
+	var features []conversionFeature
+	for _, f := range conversionFeatures {
+		if slices.Contains(names, f.name) {
+			features = append(features, f)
+		}
+	}
+	return features
+}
+
+// DisabledAnnotation is an annotation of an Ingress that is intentionally
+// not converted, as it configures a disabled feature.
+type DisabledAnnotation struct {
+	Ingress    types.NamespacedName
+	Annotation string
+	Feature    string
+}
+
+func (f conversionFeature) isSet(features *ir.IngressFeatures) bool {
+	v := reflect.ValueOf(f.get(features))
+	return v.IsValid() && !v.IsZero()
+}
+
+// disableFeatures clears the disabled features the providers extracted from
+// the Ingress, and removes the annotations configuring them from the
+// converted annotations. The annotations of a feature are the ones without
+// which the providers extract a different value of the feature.
+func (a *ingressAggregator) disableFeatures(ingressClass string, ingress networkingv1.Ingress, features *ir.IngressFeatures) {
+	source := types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}
+	for _, feature := range a.disabledFeatures {
+		if !feature.isSet(features) {
+			continue
+		}
+		var annotations []string
+		for _, annotation := range features.ConvertedAnnotations {
+			without := *ingress.DeepCopy()
+			delete(without.Annotations, annotation)
+			parsed, _ := a.parseProviderFeatures(ingressClass, without)
+			if !reflect.DeepEqual(feature.get(parsed), feature.get(features)) {
+				annotations = append(annotations, annotation)
+			}
+		}
+		feature.clear(features)
+		features.ConvertedAnnotations = slices.DeleteFunc(features.ConvertedAnnotations, func(annotation string) bool {
+			return slices.Contains(annotations, annotation)
+		})
+		for _, annotation := range annotations {
+			a.disabled = append(a.disabled, DisabledAnnotation{Ingress: source, Annotation: annotation, Feature: feature.name})
+		}
+		if len(annotations) > 0 {
+			a.notifications = append(a.notifications, notifications.NewInfo("Ingress %s/%s is converted without the disabled %s feature, annotations %s are intentionally not converted", ingress.Namespace, ingress.Name, feature.name, strings.Join(annotations, ", ")))
+		} else {
+			a.notifications = append(a.notifications, notifications.NewInfo("Ingress %s/%s is converted without the disabled %s feature", ingress.Namespace, ingress.Name, feature.name))
+		}
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func Test_disabledFeatures(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	web := types.NamespacedName{Namespace: "test", Name: "web"}
+	input := inputResources{ingresses: []networkingv1.Ingress{
+		ingressWithPath("web", "/app", &iPrefix, serviceBackend("web", 80), map[string]string{
+			"nginx.ingress.kubernetes.io/rewrite-target": "/",
+			"nginx.ingress.kubernetes.io/limit-rps":      "10",
+		}),
+	}}
+
+	testCases := []struct {
+		name             string
+		disabledFeatures []string
+		wantDisabled     []DisabledAnnotation
+		wantRewrite      bool
+		wantAnnotations  []string
+	}{
+		{
+			name:            "no disabled feature",
+			wantRewrite:     true,
+			wantAnnotations: []string{"nginx.ingress.kubernetes.io/limit-rps=10", "nginx.ingress.kubernetes.io/rewrite-target=/"},
+		},
+		{
+			name:             "disabled rewrites",
+			disabledFeatures: []string{"rewrites"},
+			wantDisabled:     []DisabledAnnotation{{Ingress: web, Annotation: "nginx.ingress.kubernetes.io/rewrite-target", Feature: "rewrites"}},
+			wantAnnotations:  []string{"nginx.ingress.kubernetes.io/limit-rps=10"},
+		},
+		{
+			name:             "disabled feature the Ingress doesn't use",
+			disabledFeatures: []string{"canary"},
+			wantRewrite:      true,
+			wantAnnotations:  []string{"nginx.ingress.kubernetes.io/limit-rps=10", "nginx.ingress.kubernetes.io/rewrite-target=/"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resources, report := convertInput(input, ConvertOptions{DisabledFeatures: tc.disabledFeatures})
+			if diff := cmp.Diff(tc.wantDisabled, report.DisabledAnnotations); diff != "" {
+				t.Errorf("Unexpected disabled annotations, diff (-want +got): %s", diff)
+			}
+			for _, u := range report.UnsupportedAnnotations {
+				t.Errorf("Unexpected unsupported annotation %s", u.Annotation)
+			}
+
+			if len(resources.HTTPRoutes) != 1 {
+				t.Fatalf("Expected 1 HTTPRoute, got %d", len(resources.HTTPRoutes))
+			}
+			rewrite := false
+			for _, rule := range resources.HTTPRoutes[0].Spec.Rules {
+				for _, filter := range rule.Filters {
+					rewrite = rewrite || filter.URLRewrite != nil
+				}
+			}
+			if rewrite != tc.wantRewrite {
+				t.Errorf("Expected URLRewrite filter %t, got %t", tc.wantRewrite, rewrite)
+			}
+
+			var annotations []string
+			for _, sources := range resources.Sources {
+				for _, source := range sources {
+					annotations = append(annotations, source.Annotations...)
+				}
+				if len(annotations) > 0 {
+					break
+				}
+			}
+			if diff := cmp.Diff(tc.wantAnnotations, annotations); diff != "" {
+				t.Errorf("Unexpected converted annotations, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func Test_validateDisabledFeatures(t *testing.T) {
+	if err := validateDisabledFeatures([]string{"canary", "rate-limits"}); err != nil {
+		t.Errorf("Expected known features to be valid, got %v", err)
+	}
+	if err := validateDisabledFeatures([]string{"canaries"}); err == nil {
+		t.Errorf("Expected an error for an unknown feature")
+	}
+}
@@ -0,0 +1,326 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+var (
+	gatewayGVK = schema.GroupVersionKind{
+		Group:   "gateway.networking.k8s.io",
+		Version: "v1",
+		Kind:    "Gateway",
+	}
+
+	httpRouteGVK = schema.GroupVersionKind{
+		Group:   "gateway.networking.k8s.io",
+		Version: "v1",
+		Kind:    "HTTPRoute",
+	}
+)
+
+// Emitter produces resources of additional kinds, such as policies of a
+// specific Gateway API implementation, from the IR of a conversion. Emitters
+// run after the Gateways and HTTPRoutes have been emitted.
+type Emitter interface {
+	// Name identifies the emitter in user-facing messages.
+	Name() string
+	// Emit returns the resources derived from the IR along with
+	// notifications and errors about the parts it could not convert.
+	Emit(result ir.IR) ([]unstructured.Unstructured, []Notification, []error)
+}
+
+// IRTransformer is implemented by emitters that need to change the IR before
+// the Gateways and HTTPRoutes are emitted, for instance to reference the
+// resources they emit from HTTPRoute filters.
+type IRTransformer interface {
+	TransformIR(result *ir.IR) []Notification
+}
+
+func transformIR(emitters []Emitter, result *ir.IR) []Notification {
+	var notes []Notification
+	for _, e := range emitters {
+		if t, ok := e.(IRTransformer); ok {
+			notes = append(notes, t.TransformIR(result)...)
+		}
+	}
+	return notes
+}
+
+func runEmitters(emitters []Emitter, result ir.IR) ([]unstructured.Unstructured, []Notification, []error) {
+	var objects []unstructured.Unstructured
+	var notes []Notification
+	var errors []error
+	for _, e := range emitters {
+		emitted, emitterNotes, emitterErrors := e.Emit(result)
+		notes = append(notes, emitterNotes...)
+		errors = append(errors, emitterErrors...)
+		for _, obj := range emitted {
+			if obj.GetAPIVersion() == "" || obj.GetKind() == "" {
+				errors = append(errors, fmt.Errorf("emitter %s produced %s without apiVersion or kind", e.Name(), obj.GetName()))
+				continue
+			}
+			objects = append(objects, obj)
+		}
+	}
+	return objects, notes, errors
+}
+
+// emitGatewayAPI turns the IR into Gateways and HTTPRoutes. Every listener
+// accepts HTTP on the HTTP port of its Gateway, unless it is omitted, and
+// HTTPS on the HTTPS port when it has certificates.
+func emitGatewayAPI(result ir.IR) ([]gatewayv1.HTTPRoute, []gatewayv1.Gateway) {
+	var httpRoutes []gatewayv1.HTTPRoute
+	var gateways []gatewayv1.Gateway
+
+	for _, route := range result.HTTPRoutes {
+		httpRoutes = append(httpRoutes, emitHTTPRoute(route))
+	}
+	for _, gw := range result.Gateways {
+		gateways = append(gateways, emitGateway(gw))
+	}
+	return httpRoutes, gateways
+}
+
+func emitGateway(gw ir.Gateway) gatewayv1.Gateway {
+	gateway := gatewayv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   gw.Namespace,
+			Name:        gw.Name,
+			Annotations: maps.Clone(gw.Annotations),
+		},
+		Spec: gatewayv1.GatewaySpec{
+			GatewayClassName: gatewayv1.ObjectName(gw.GatewayClassName),
+		},
+	}
+	gateway.SetGroupVersionKind(gatewayGVK)
+
+	for _, listener := range gw.Listeners {
+		var hostname *gatewayv1.Hostname
+		if listener.Hostname != "" {
+			h := gatewayv1.Hostname(listener.Hostname)
+			hostname = &h
+		}
+
+		allowedRoutes := emitAllowedRoutes(gw.Namespace, listener.AllowedNamespaces)
+		if !listener.Stream && (len(listener.CertificateRefs) == 0 || listener.HTTP != ir.HTTPOmit) {
+			gateway.Spec.Listeners = append(gateway.Spec.Listeners, gatewayv1.Listener{
+				Name:          gatewayv1.SectionName(listener.SectionName("http")),
+				Hostname:      hostname,
+				Port:          gatewayv1.PortNumber(gw.HTTPPort),
+				Protocol:      gatewayv1.HTTPProtocolType,
+				AllowedRoutes: allowedRoutes,
+			})
+		}
+		if len(listener.CertificateRefs) > 0 {
+			gateway.Spec.Listeners = append(gateway.Spec.Listeners, gatewayv1.Listener{
+				Name:          gatewayv1.SectionName(listener.SectionName("https")),
+				Hostname:      hostname,
+				Port:          gatewayv1.PortNumber(gw.HTTPSPort),
+				Protocol:      gatewayv1.HTTPSProtocolType,
+				AllowedRoutes: allowedRoutes,
+				TLS: &gatewayv1.GatewayTLSConfig{
+					CertificateRefs:    listener.CertificateRefs,
+					FrontendValidation: listener.FrontendValidation,
+					Options:            listener.TLSOptions,
+				},
+			})
+		}
+		for _, p := range listener.ExtraPorts {
+			extra := gatewayv1.Listener{
+				Name:          gatewayv1.SectionName(listener.PortSectionName(p)),
+				Hostname:      hostname,
+				Port:          gatewayv1.PortNumber(p.Port),
+				Protocol:      gatewayv1.HTTPProtocolType,
+				AllowedRoutes: allowedRoutes,
+			}
+			switch p.Protocol {
+			case "https":
+				extra.Protocol = gatewayv1.HTTPSProtocolType
+				extra.TLS = &gatewayv1.GatewayTLSConfig{
+					CertificateRefs:    listener.CertificateRefs,
+					FrontendValidation: listener.FrontendValidation,
+					Options:            listener.TLSOptions,
+				}
+			case streamProtocolTLS:
+				mode := gatewayv1.TLSModePassthrough
+				extra.Protocol = gatewayv1.TLSProtocolType
+				extra.TLS = &gatewayv1.GatewayTLSConfig{Mode: &mode}
+			case streamProtocolTCP:
+				extra.Protocol = gatewayv1.TCPProtocolType
+				extra.Hostname = nil
+			}
+			gateway.Spec.Listeners = append(gateway.Spec.Listeners, extra)
+		}
+	}
+	return gateway
+}
+
+// emitAllowedRoutes selects the namespaces of the routes of a listener by
+// their name label, or leaves the default of the Gateway namespace only.
+func emitAllowedRoutes(gatewayNamespace string, namespaces []string) *gatewayv1.AllowedRoutes {
+	values := []string{gatewayNamespace}
+	for _, ns := range namespaces {
+		if ns != gatewayNamespace {
+			values = append(values, ns)
+		}
+	}
+	if len(values) == 1 {
+		return nil
+	}
+	sort.Strings(values[1:])
+	from := gatewayv1.NamespacesFromSelector
+	return &gatewayv1.AllowedRoutes{
+		Namespaces: &gatewayv1.RouteNamespaces{
+			From: &from,
+			Selector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      corev1.LabelMetadataName,
+					Operator: metav1.LabelSelectorOpIn,
+					Values:   values,
+				}},
+			},
+		},
+	}
+}
+
+func emitHTTPRoute(route ir.HTTPRoute) gatewayv1.HTTPRoute {
+	httpRoute := gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   route.Namespace,
+			Name:        route.Name,
+			Annotations: maps.Clone(route.Annotations),
+		},
+		Spec: gatewayv1.HTTPRouteSpec{},
+		Status: gatewayv1.HTTPRouteStatus{
+			RouteStatus: gatewayv1.RouteStatus{
+				Parents: []gatewayv1.RouteParentStatus{},
+			},
+		},
+	}
+	httpRoute.SetGroupVersionKind(httpRouteGVK)
+
+	var gatewayNamespace *gatewayv1.Namespace
+	if route.GatewayNamespace != "" && route.GatewayNamespace != route.Namespace {
+		ns := gatewayv1.Namespace(route.GatewayNamespace)
+		gatewayNamespace = &ns
+	}
+	if route.GatewayName != "" && len(route.SectionNames) == 0 {
+		httpRoute.Spec.ParentRefs = []gatewayv1.ParentReference{{Namespace: gatewayNamespace, Name: gatewayv1.ObjectName(route.GatewayName)}}
+	}
+	for _, sectionName := range route.SectionNames {
+		name := gatewayv1.SectionName(sectionName)
+		parentRef := gatewayv1.ParentReference{
+			Namespace:   gatewayNamespace,
+			Name:        gatewayv1.ObjectName(route.GatewayName),
+			SectionName: &name,
+		}
+		if port, ok := route.SectionPorts[sectionName]; ok {
+			port := gatewayv1.PortNumber(port)
+			parentRef.Port = &port
+		}
+		httpRoute.Spec.ParentRefs = append(httpRoute.Spec.ParentRefs, parentRef)
+	}
+	if route.Hostname != "" {
+		httpRoute.Spec.Hostnames = []gatewayv1.Hostname{gatewayv1.Hostname(route.Hostname)}
+	}
+
+	for _, rule := range route.Rules {
+		hrRule := gatewayv1.HTTPRouteRule{
+			Matches:  rule.Matches,
+			Filters:  rule.Filters,
+			Timeouts: rule.Timeouts,
+			Retry:    rule.Retry,
+		}
+		for _, backend := range rule.Backends {
+			hrRule.BackendRefs = append(hrRule.BackendRefs, gatewayv1.HTTPBackendRef{BackendRef: backend.BackendRef})
+		}
+		httpRoute.Spec.Rules = append(httpRoute.Spec.Rules, hrRule)
+	}
+	httpRoute.Spec.Rules = sortHTTPRouteRules(httpRoute.Spec.Rules)
+
+	return httpRoute
+}
+
+// sortHTTPRouteRules orders rules the way Gateway API implementations
+// evaluate matches: Exact matches before Prefix matches, longer paths before
+// shorter ones, then matches of a method, and matches with more header and
+// query parameter matches (e.g. canaries) before those with fewer. A rule
+// with several matches is ordered by its highest-precedence one. Sorting is
+// stable so ties keep Ingress processing order.
+func sortHTTPRouteRules(rules []gatewayv1.HTTPRouteRule) []gatewayv1.HTTPRouteRule {
+	sort.SliceStable(rules, func(i, j int) bool {
+		return matchPrecedes(topMatch(rules[i].Matches), topMatch(rules[j].Matches))
+	})
+	return rules
+}
+
+// topMatch returns the highest-precedence of matches, nil if there are none.
+func topMatch(matches []gatewayv1.HTTPRouteMatch) *gatewayv1.HTTPRouteMatch {
+	var top *gatewayv1.HTTPRouteMatch
+	for i := range matches {
+		if top == nil || matchPrecedes(&matches[i], top) {
+			top = &matches[i]
+		}
+	}
+	return top
+}
+
+func matchPrecedes(a, b *gatewayv1.HTTPRouteMatch) bool {
+	// Rules without matches are catch-alls and always sort last.
+	if a == nil || b == nil {
+		return a != nil && b == nil
+	}
+	aExact, bExact := isExactPathMatch(a), isExactPathMatch(b)
+	if aExact != bExact {
+		return aExact
+	}
+	aPath, bPath := pathMatchValue(a), pathMatchValue(b)
+	if len(aPath) != len(bPath) {
+		return len(aPath) > len(bPath)
+	}
+	if (a.Method != nil) != (b.Method != nil) {
+		return a.Method != nil
+	}
+	if len(a.Headers) != len(b.Headers) {
+		return len(a.Headers) > len(b.Headers)
+	}
+	if len(a.QueryParams) != len(b.QueryParams) {
+		return len(a.QueryParams) > len(b.QueryParams)
+	}
+	return aPath < bPath
+}
+
+func isExactPathMatch(m *gatewayv1.HTTPRouteMatch) bool {
+	return m.Path != nil && m.Path.Type != nil && *m.Path.Type == gatewayv1.PathMatchExact
+}
+
+func pathMatchValue(m *gatewayv1.HTTPRouteMatch) string {
+	if m.Path == nil || m.Path.Value == nil {
+		return ""
+	}
+	return *m.Path.Value
+}
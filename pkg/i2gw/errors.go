@@ -0,0 +1,110 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// The errors of Report.Errors wrap one of these typed errors when the
+// failure has a category, for library consumers to branch on it with
+// errors.As. Each carries the Ingress it was converted from.
+
+// UnsupportedPathTypeError is a path of an Ingress without a pathType, or
+// with one that has no HTTPRoute path match equivalent.
+type UnsupportedPathTypeError struct {
+	Ingress types.NamespacedName
+	Path    string
+	// PathType is empty for paths without a pathType.
+	PathType string
+}
+
+func (e *UnsupportedPathTypeError) Error() string {
+	if e.PathType == "" {
+		return fmt.Sprintf("Missing path match type for path: %s", e.Path)
+	}
+	return fmt.Sprintf("Unsupported path match type: %s", e.PathType)
+}
+
+// NamedPortUnresolvedError is a Service backend of an Ingress referring to
+// its port by a name that can't be resolved to a number.
+type NamedPortUnresolvedError struct {
+	Ingress types.NamespacedName
+	Service string
+	Port    string
+}
+
+func (e *NamedPortUnresolvedError) Error() string {
+	return fmt.Sprintf("Named ports not supported: %s", e.Port)
+}
+
+// InvalidBackendError is a resource backend of an Ingress that can't be
+// converted to a backendRef.
+type InvalidBackendError struct {
+	Ingress types.NamespacedName
+	// Kind is the kind of the resource, followed by its API group if any.
+	Kind   string
+	Name   string
+	Reason string
+}
+
+func (e *InvalidBackendError) Error() string {
+	return fmt.Sprintf("resource backend %s %s %s", e.Kind, e.Name, e.Reason)
+}
+
+// Error makes UnsupportedAnnotation a typed error, for consumers to handle
+// the unsupported annotations of Report.UnsupportedAnnotations like the
+// other failures of the conversion.
+func (u UnsupportedAnnotation) Error() string {
+	return fmt.Sprintf("Ingress %s uses annotation %s, which is not supported", u.Ingress, u.Annotation)
+}
+
+// sourcedError is a typed error whose Ingress is set once known.
+type sourcedError interface {
+	error
+	setIngress(types.NamespacedName)
+}
+
+func (e *UnsupportedPathTypeError) setIngress(ingress types.NamespacedName) { e.Ingress = ingress }
+func (e *NamedPortUnresolvedError) setIngress(ingress types.NamespacedName) { e.Ingress = ingress }
+func (e *InvalidBackendError) setIngress(ingress types.NamespacedName)      { e.Ingress = ingress }
+
+// newIngressError returns the error converting a part of an Ingress,
+// setting the Ingress of the typed error it wraps, if any.
+func newIngressError(ingress types.NamespacedName, err error) error {
+	var sourced sourcedError
+	if errors.As(err, &sourced) {
+		sourced.setIngress(ingress)
+	}
+	return ingressError{ingress: ingress, err: err}
+}
+
+// ErrorIngress returns the Ingress a conversion error of Report.Errors is
+// about, if any.
+func ErrorIngress(err error) (types.NamespacedName, bool) {
+	var ingressErr ingressError
+	if errors.As(err, &ingressErr) {
+		return ingressErr.ingress, true
+	}
+	var unsupported UnsupportedAnnotation
+	if errors.As(err, &unsupported) {
+		return unsupported.Ingress, true
+	}
+	return types.NamespacedName{}, false
+}
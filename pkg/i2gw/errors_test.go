@@ -0,0 +1,93 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func Test_typedErrors(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	implementationSpecific := networkingv1.PathTypeImplementationSpecific
+	namedPort := serviceBackend("web", 0)
+	namedPort.Service.Port.Name = "http"
+	resourceBackend := networkingv1.IngressBackend{Resource: &corev1.TypedLocalObjectReference{Kind: "Service", Name: "web"}}
+
+	testCases := []struct {
+		name     string
+		ingress  networkingv1.Ingress
+		expected error
+	}{
+		{
+			name:     "unsupported path type",
+			ingress:  ingressWithPath("legacy", "/", &implementationSpecific, serviceBackend("web", 80), nil),
+			expected: &UnsupportedPathTypeError{Ingress: types.NamespacedName{Namespace: "test", Name: "legacy"}, Path: "/", PathType: "ImplementationSpecific"},
+		},
+		{
+			name:     "named port",
+			ingress:  ingressWithPath("named", "/", &prefix, namedPort, nil),
+			expected: &NamedPortUnresolvedError{Ingress: types.NamespacedName{Namespace: "test", Name: "named"}, Service: "web", Port: "http"},
+		},
+		{
+			name:     "invalid backend",
+			ingress:  ingressWithPath("resource", "/", &prefix, resourceBackend, nil),
+			expected: &InvalidBackendError{Ingress: types.NamespacedName{Namespace: "test", Name: "resource"}, Kind: "Service", Name: "web", Reason: "has no port, use a service backend instead"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, report, err := Convert(context.Background(), ConvertOptions{Ingresses: []networkingv1.Ingress{tc.ingress}})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(report.Errors) != 1 {
+				t.Fatalf("Expected 1 error, got %v", report.Errors)
+			}
+			err = report.Errors[0]
+			var got error
+			switch tc.expected.(type) {
+			case *UnsupportedPathTypeError:
+				var target *UnsupportedPathTypeError
+				if errors.As(err, &target) {
+					got = target
+				}
+			case *NamedPortUnresolvedError:
+				var target *NamedPortUnresolvedError
+				if errors.As(err, &target) {
+					got = target
+				}
+			case *InvalidBackendError:
+				var target *InvalidBackendError
+				if errors.As(err, &target) {
+					got = target
+				}
+			}
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Errorf("Unexpected typed error of %v, diff (-want +got): %s", err, diff)
+			}
+			if ingress, ok := ErrorIngress(err); !ok || ingress.Name != tc.ingress.Name {
+				t.Errorf("Expected the error to be about Ingress %s, got %v", tc.ingress.Name, ingress)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ReadExclusionFile reads the Ingresses to exclude from the conversion from
+// a file of namespace/name glob patterns, see ParseExclusions.
+func ReadExclusionFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open exclusion file: %w", err)
+	}
+	defer f.Close()
+
+	patterns, err := ParseExclusions(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exclusions from %s: %w", path, err)
+	}
+	return patterns, nil
+}
+
+// ParseExclusions parses one namespace/name pattern per line, such as
+// "legacy/*" or "*/internal-*", in the syntax of path.Match. Blank lines and
+// the lines starting with # are ignored.
+func ParseExclusions(r io.Reader) ([]string, error) {
+	var patterns []string
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		pattern := strings.TrimSpace(scanner.Text())
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+		if err := validateExclusion(pattern); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, scanner.Err()
+}
+
+func validateExclusions(patterns []string) error {
+	for _, pattern := range patterns {
+		if err := validateExclusion(pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateExclusion(pattern string) error {
+	if strings.Count(pattern, "/") != 1 {
+		return fmt.Errorf("invalid exclusion %q, expected a namespace/name pattern", pattern)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid exclusion %q: %w", pattern, err)
+	}
+	return nil
+}
+
+// excludeIngresses removes the Ingresses matching any of the patterns,
+// returning the remaining ones and the excluded ones.
+func excludeIngresses(ingresses []networkingv1.Ingress, patterns []string) ([]networkingv1.Ingress, []types.NamespacedName, []Notification) {
+	if len(patterns) == 0 {
+		return ingresses, nil, nil
+	}
+	var kept []networkingv1.Ingress
+	var excluded []types.NamespacedName
+	var notes []Notification
+	for _, ingress := range ingresses {
+		pattern, ok := matchExclusion(ingress, patterns)
+		if !ok {
+			kept = append(kept, ingress)
+			continue
+		}
+		excluded = append(excluded, types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name})
+		notes = append(notes, notifications.NewInfo("Ingress %s/%s is excluded from the conversion by the exclusion %q", ingress.Namespace, ingress.Name, pattern))
+	}
+	return kept, excluded, notes
+}
+
+func matchExclusion(ingress networkingv1.Ingress, patterns []string) (string, bool) {
+	name := ingress.Namespace + "/" + ingress.Name
+	for _, pattern := range patterns {
+		// The patterns are validated.
+		if ok, _ := path.Match(pattern, name); ok {
+			return pattern, true
+		}
+	}
+	return "", false
+}
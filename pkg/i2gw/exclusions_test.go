@@ -0,0 +1,102 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestParseExclusions(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:  "patterns and comments",
+			input: "# never migrating\nlegacy/*\n\n  */internal-*  \n",
+			want:  []string{"legacy/*", "*/internal-*"},
+		},
+		{
+			name:    "missing namespace",
+			input:   "legacy/*\nweb\n",
+			wantErr: `line 2: invalid exclusion "web", expected a namespace/name pattern`,
+		},
+		{
+			name:    "malformed pattern",
+			input:   "legacy/[web\n",
+			wantErr: `line 1: invalid exclusion "legacy/[web": syntax error in pattern`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseExclusions(strings.NewReader(tc.input))
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("expected error %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected patterns (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_excludeIngresses(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	web := ingressWithPath("web", "/", &iPrefix, serviceBackend("web", 80), nil)
+	internal := ingressWithPath("internal-admin", "/admin", &iPrefix, serviceBackend("admin", 80), nil)
+
+	resources, report := convertInput(inputResources{ingresses: []networkingv1.Ingress{web, internal}}, ConvertOptions{
+		Exclusions: []string{"legacy/*", "*/internal-*"},
+	})
+
+	if len(resources.HTTPRoutes) != 1 {
+		t.Fatalf("expected a single HTTPRoute, got %d", len(resources.HTTPRoutes))
+	}
+	var gotPaths []string
+	for _, rule := range resources.HTTPRoutes[0].Spec.Rules {
+		for _, match := range rule.Matches {
+			gotPaths = append(gotPaths, *match.Path.Value)
+		}
+	}
+	if diff := cmp.Diff([]string{"/"}, gotPaths); diff != "" {
+		t.Errorf("unexpected paths (-want +got):\n%s", diff)
+	}
+	wantExcluded := []types.NamespacedName{{Namespace: "test", Name: "internal-admin"}}
+	if diff := cmp.Diff(wantExcluded, report.ExcludedIngresses); diff != "" {
+		t.Errorf("unexpected excluded Ingresses (-want +got):\n%s", diff)
+	}
+	if len(report.SkippedIngresses) != 0 {
+		t.Errorf("expected no skipped Ingresses, got %v", report.SkippedIngresses)
+	}
+	want := `INFO: Ingress test/internal-admin is excluded from the conversion by the exclusion "*/internal-*"`
+	if len(report.Notifications) == 0 || report.Notifications[0].String() != want {
+		t.Errorf("expected notification %q, got %v", want, report.Notifications)
+	}
+}
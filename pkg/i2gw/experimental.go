@@ -0,0 +1,119 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// The names of the features outside of the standard channel of Gateway API,
+// see ConvertOptions.ExperimentalFeatures.
+const (
+	ExperimentalTLSRoute           = "tlsroute"
+	ExperimentalTCPRoute           = "tcproute"
+	ExperimentalRetries            = "retries"
+	ExperimentalFrontendValidation = "frontend-validation"
+	ExperimentalSessionPersistence = "session-persistence"
+	ExperimentalBackendTLS         = "backend-tls"
+)
+
+// experimentalFeatures are the policy features converted to fields of the
+// experimental channel of Gateway API once enabled, unless the target
+// implementation converts them to policies.
+var experimentalFeatures = []struct {
+	name    string
+	feature policyFeature
+	convert func(*ir.IR) []Notification
+}{
+	{ExperimentalRetries, retryFeature, func(result *ir.IR) []Notification {
+		setHTTPRouteRetries(result)
+		return nil
+	}},
+	{ExperimentalFrontendValidation, clientValidationFeature, setFrontendValidation},
+	{ExperimentalSessionPersistence, affinityFeature, setBackendLBPolicies},
+	{ExperimentalBackendTLS, backendTLSFeature, setBackendTLSPolicies},
+}
+
+// ExperimentalFeatures returns the names of the features that can be
+// enabled with ConvertOptions.ExperimentalFeatures.
+func ExperimentalFeatures() []string {
+	names := []string{ExperimentalTLSRoute, ExperimentalTCPRoute}
+	for _, f := range experimentalFeatures {
+		names = append(names, f.name)
+	}
+	return names
+}
+
+func validateExperimentalFeatures(names []string) error {
+	for _, name := range names {
+		if !slices.Contains(ExperimentalFeatures(), name) {
+			return fmt.Errorf("unknown experimental feature %q, supported ones are: %s", name, strings.Join(ExperimentalFeatures(), ", "))
+		}
+	}
+	return nil
+}
+
+// enabledExperimentalFeatures returns the set of experimental features of
+// the options, all of them with Experimental.
+func enabledExperimentalFeatures(opts ConvertOptions) map[string]bool {
+	enabled := map[string]bool{}
+	for _, name := range ExperimentalFeatures() {
+		if opts.Experimental || slices.Contains(opts.ExperimentalFeatures, name) {
+			enabled[name] = true
+		}
+	}
+	return enabled
+}
+
+// setFrontendValidation sets the experimental frontend validation of the
+// HTTPS listeners of hostnames that authenticate client certificates.
+// ingress-nginx reads CA certificates from Secrets, which Gateway API only
+// supports as an implementation-specific kind of reference.
+func setFrontendValidation(result *ir.IR) []Notification {
+	var notes []Notification
+	for i := range result.Gateways {
+		gw := &result.Gateways[i]
+		for j := range gw.Listeners {
+			listener := &gw.Listeners[j]
+			validation := listener.ClientValidation
+			if validation == nil || len(listener.CertificateRefs) == 0 {
+				continue
+			}
+			ref := gatewayv1.ObjectReference{
+				Group: "",
+				Kind:  "Secret",
+				Name:  gatewayv1.ObjectName(validation.CACertificates.Name),
+			}
+			if validation.CACertificates.Namespace != gw.Namespace {
+				ns := gatewayv1.Namespace(validation.CACertificates.Namespace)
+				ref.Namespace = &ns
+				notes = append(notes, notifications.NewInfo("Listener %s of Gateway %s/%s references CA certificates in namespace %s, a ReferenceGrant allowing it must be created there", listener.SectionName("https"), gw.Namespace, gw.Name, ns))
+			}
+			listener.FrontendValidation = &gatewayv1.FrontendTLSValidation{CACertificateRefs: []gatewayv1.ObjectReference{ref}}
+			notes = append(notes, notifications.NewInfo("Listener %s of Gateway %s/%s reads CA certificates from Secret %s, which only some implementations support instead of a ConfigMap", listener.SectionName("https"), gw.Namespace, gw.Name, validation.CACertificates))
+			if validation.Optional {
+				notes = append(notes, notifications.NewWarning("Listener %s of Gateway %s/%s can't let clients without certificate through, Gateway API requires them to present one", listener.SectionName("https"), gw.Namespace, gw.Name))
+			}
+		}
+	}
+	return notes
+}
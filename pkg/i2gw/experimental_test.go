@@ -0,0 +1,122 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_setFrontendValidation(t *testing.T) {
+	otherNamespace := gatewayv1.Namespace("security")
+
+	testCases := []struct {
+		name               string
+		listener           ir.Listener
+		expectedValidation *gatewayv1.FrontendTLSValidation
+		expectedNotes      int
+	}{{
+		name:     "no client validation",
+		listener: ir.Listener{Name: "example-com", Hostname: "example.com", CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "cert"}}},
+	}, {
+		name: "client validation without TLS",
+		listener: ir.Listener{
+			Name:             "example-com",
+			Hostname:         "example.com",
+			ClientValidation: &ir.ClientValidation{CACertificates: types.NamespacedName{Namespace: "default", Name: "ca"}},
+		},
+	}, {
+		name: "CA certificates in the namespace of the Gateway",
+		listener: ir.Listener{
+			Name:             "example-com",
+			Hostname:         "example.com",
+			CertificateRefs:  []gatewayv1.SecretObjectReference{{Name: "cert"}},
+			ClientValidation: &ir.ClientValidation{CACertificates: types.NamespacedName{Namespace: "default", Name: "ca"}},
+		},
+		expectedValidation: &gatewayv1.FrontendTLSValidation{
+			CACertificateRefs: []gatewayv1.ObjectReference{{Group: "", Kind: "Secret", Name: "ca"}},
+		},
+		expectedNotes: 1,
+	}, {
+		name: "optional validation with CA certificates in another namespace",
+		listener: ir.Listener{
+			Name:             "example-com",
+			Hostname:         "example.com",
+			CertificateRefs:  []gatewayv1.SecretObjectReference{{Name: "cert"}},
+			ClientValidation: &ir.ClientValidation{CACertificates: types.NamespacedName{Namespace: "security", Name: "ca"}, Optional: true},
+		},
+		expectedValidation: &gatewayv1.FrontendTLSValidation{
+			CACertificateRefs: []gatewayv1.ObjectReference{{Group: "", Kind: "Secret", Name: "ca", Namespace: &otherNamespace}},
+		},
+		expectedNotes: 3,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := ir.IR{Gateways: []ir.Gateway{{Namespace: "default", Name: "nginx", Listeners: []ir.Listener{tc.listener}}}}
+			notes := setFrontendValidation(&result)
+
+			if diff := cmp.Diff(tc.expectedValidation, result.Gateways[0].Listeners[0].FrontendValidation); diff != "" {
+				t.Errorf("Unexpected frontend validation, diff (-want +got): %s", diff)
+			}
+			if len(notes) != tc.expectedNotes {
+				t.Errorf("Expected %d notifications, got %d: %v", tc.expectedNotes, len(notes), notes)
+			}
+		})
+	}
+}
+
+func Test_enabledExperimentalFeatures(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     ConvertOptions
+		expected map[string]bool
+	}{{
+		name:     "none",
+		expected: map[string]bool{},
+	}, {
+		name:     "listed features",
+		opts:     ConvertOptions{ExperimentalFeatures: []string{ExperimentalTCPRoute, ExperimentalRetries}},
+		expected: map[string]bool{ExperimentalTCPRoute: true, ExperimentalRetries: true},
+	}, {
+		name: "every feature with experimental",
+		opts: ConvertOptions{Experimental: true, ExperimentalFeatures: []string{ExperimentalRetries}},
+		expected: map[string]bool{
+			ExperimentalTLSRoute:           true,
+			ExperimentalTCPRoute:           true,
+			ExperimentalRetries:            true,
+			ExperimentalFrontendValidation: true,
+			ExperimentalSessionPersistence: true,
+			ExperimentalBackendTLS:         true,
+		},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, enabledExperimentalFeatures(tc.opts)); diff != "" {
+				t.Errorf("Unexpected features, diff (-want +got): %s", diff)
+			}
+		})
+	}
+
+	if err := validateExperimentalFeatures([]string{"httproute"}); err == nil {
+		t.Errorf("Expected an error for an unknown experimental feature")
+	}
+}
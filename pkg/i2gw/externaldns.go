@@ -0,0 +1,163 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ExternalDNSMode selects how the external-dns annotations of Ingresses are
+// propagated to the generated resources.
+type ExternalDNSMode string
+
+const (
+	// ExternalDNSCarry carries the external-dns annotations of Ingresses to
+	// their Gateways and HTTPRoutes, so that external-dns points the
+	// records of their hosts to the Gateways.
+	ExternalDNSCarry ExternalDNSMode = "carry"
+	// ExternalDNSKeepTargets carries the annotations and sets the target
+	// of Gateways to the load balancer addresses of their Ingresses, so
+	// that the records keep pointing to the Ingress controller until the
+	// target annotation is removed to cut over.
+	ExternalDNSKeepTargets ExternalDNSMode = "keep-targets"
+	// ExternalDNSNone propagates no external-dns annotation.
+	ExternalDNSNone ExternalDNSMode = "none"
+)
+
+// ExternalDNSModes returns the names of the supported external-dns modes.
+func ExternalDNSModes() []string {
+	return []string{string(ExternalDNSCarry), string(ExternalDNSKeepTargets), string(ExternalDNSNone)}
+}
+
+func validateExternalDNSMode(mode ExternalDNSMode) error {
+	switch mode {
+	case "", ExternalDNSCarry, ExternalDNSKeepTargets, ExternalDNSNone:
+		return nil
+	default:
+		return fmt.Errorf("unknown external-dns mode %q, supported ones are: %s", mode, strings.Join(ExternalDNSModes(), ", "))
+	}
+}
+
+const (
+	externalDNSAnnotationPrefix = "external-dns.alpha.kubernetes.io/"
+	// externalDNSTargetAnnotation overrides the addresses the records
+	// point to. external-dns reads it from Gateways, the other annotations
+	// from routes.
+	externalDNSTargetAnnotation = externalDNSAnnotationPrefix + "target"
+	// externalDNSHostnameSourceAnnotation selects the hosts of an Ingress
+	// records are created for, which only applies to Ingresses.
+	externalDNSHostnameSourceAnnotation = externalDNSAnnotationPrefix + "ingress-hostname-source"
+)
+
+// addExternalDNSAnnotations records the external-dns annotations of an
+// Ingress, and the load balancer addresses of its status as its target
+// with ExternalDNSKeepTargets.
+func (a *ingressAggregator) addExternalDNSAnnotations(ingress networkingv1.Ingress) {
+	if a.externalDNSMode == ExternalDNSNone {
+		return
+	}
+	annotations := map[string]string{}
+	for annotation, value := range ingress.Annotations {
+		if !strings.HasPrefix(annotation, externalDNSAnnotationPrefix) {
+			continue
+		}
+		if annotation == externalDNSHostnameSourceAnnotation {
+			a.notifications = append(a.notifications, notifications.NewWarning("Ingress %s/%s uses the %s annotation, which only applies to Ingresses: external-dns creates records for the hostnames of every HTTPRoute", ingress.Namespace, ingress.Name, annotation))
+			continue
+		}
+		annotations[annotation] = value
+	}
+	if _, ok := annotations[externalDNSTargetAnnotation]; !ok && a.externalDNSMode == ExternalDNSKeepTargets {
+		var targets []string
+		for _, lb := range ingress.Status.LoadBalancer.Ingress {
+			if lb.Hostname != "" {
+				targets = append(targets, lb.Hostname)
+			} else if lb.IP != "" {
+				targets = append(targets, lb.IP)
+			}
+		}
+		if len(targets) > 0 {
+			annotations[externalDNSTargetAnnotation] = strings.Join(targets, ",")
+		} else {
+			a.notifications = append(a.notifications, notifications.NewWarning("Ingress %s/%s has no load balancer address in its status, the records of its hosts point to its Gateway", ingress.Namespace, ingress.Name))
+		}
+	}
+	if len(annotations) == 0 {
+		return
+	}
+	if a.externalDNS == nil {
+		a.externalDNS = map[types.NamespacedName]map[string]string{}
+	}
+	a.externalDNS[types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}] = annotations
+}
+
+// setExternalDNSAnnotations sets the target annotation of the Ingresses of
+// every Gateway on it, and their other external-dns annotations on the
+// HTTPRoutes converted from them.
+func (a *ingressAggregator) setExternalDNSAnnotations(result *ir.IR) []Notification {
+	var notes []Notification
+	for i := range result.Gateways {
+		gw := &result.Gateways[i]
+		kind := fmt.Sprintf("Gateway %s/%s", gw.Namespace, gw.Name)
+		notes = append(notes, a.mergeExternalDNSAnnotations(&gw.Annotations, kind, gw.Ingresses, true)...)
+	}
+	for i := range result.HTTPRoutes {
+		route := &result.HTTPRoutes[i]
+		ingresses := backendSources(*route, namespacedNameSet{})
+		kind := fmt.Sprintf("HTTPRoute %s/%s", route.Namespace, route.Name)
+		notes = append(notes, a.mergeExternalDNSAnnotations(&route.Annotations, kind, ingresses, false)...)
+	}
+	return notes
+}
+
+// mergeExternalDNSAnnotations sets the target annotation of the Ingresses
+// on the annotations of a Gateway, or their other annotations on the ones
+// of an HTTPRoute, keeping the value of the first Ingress setting each.
+func (a *ingressAggregator) mergeExternalDNSAnnotations(annotations *map[string]string, object string, ingresses []types.NamespacedName, target bool) []Notification {
+	var notes []Notification
+	sources := map[string]types.NamespacedName{}
+	for _, ingress := range sortedNamespacedNames(ingresses) {
+		var names []string
+		for annotation := range a.externalDNS[ingress] {
+			if (annotation == externalDNSTargetAnnotation) == target {
+				names = append(names, annotation)
+			}
+		}
+		sort.Strings(names)
+		for _, annotation := range names {
+			value := a.externalDNS[ingress][annotation]
+			source, ok := sources[annotation]
+			switch {
+			case !ok:
+				if *annotations == nil {
+					*annotations = map[string]string{}
+				}
+				(*annotations)[annotation] = value
+				sources[annotation] = ingress
+			case (*annotations)[annotation] != value:
+				notes = append(notes, notifications.NewWarning("Ingresses %s and %s set the %s annotation differently, %s gets the value of %s", source, ingress, annotation, object, source))
+			}
+		}
+	}
+	return notes
+}
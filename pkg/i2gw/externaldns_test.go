@@ -0,0 +1,97 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+func Test_externalDNSAnnotations(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	web := ingressWithPath("web", "/", &iPrefix, serviceBackend("web", 80), map[string]string{
+		"external-dns.alpha.kubernetes.io/hostname": "www.example.com",
+		"external-dns.alpha.kubernetes.io/ttl":      "60",
+	})
+	web.Status.LoadBalancer.Ingress = []networkingv1.IngressLoadBalancerIngress{{IP: "203.0.113.10"}}
+	api := ingressWithPath("api", "/api", &iPrefix, serviceBackend("api", 80), map[string]string{
+		"external-dns.alpha.kubernetes.io/ttl":    "300",
+		"external-dns.alpha.kubernetes.io/target": "lb.example.com",
+	})
+
+	testCases := []struct {
+		name          string
+		mode          ExternalDNSMode
+		ingresses     []networkingv1.Ingress
+		wantGateway   map[string]string
+		wantHTTPRoute map[string]string
+		wantWarnings  []string
+	}{
+		{
+			name:          "carry",
+			ingresses:     []networkingv1.Ingress{web},
+			wantHTTPRoute: map[string]string{"external-dns.alpha.kubernetes.io/hostname": "www.example.com", "external-dns.alpha.kubernetes.io/ttl": "60"},
+		},
+		{
+			name:          "conflicting annotations",
+			ingresses:     []networkingv1.Ingress{web, api},
+			wantGateway:   map[string]string{"external-dns.alpha.kubernetes.io/target": "lb.example.com"},
+			wantHTTPRoute: map[string]string{"external-dns.alpha.kubernetes.io/hostname": "www.example.com", "external-dns.alpha.kubernetes.io/ttl": "300"},
+			wantWarnings: []string{
+				"Ingresses test/api and test/web set the external-dns.alpha.kubernetes.io/ttl annotation differently, HTTPRoute test/example-com gets the value of test/api",
+			},
+		},
+		{
+			name:          "keep targets",
+			mode:          ExternalDNSKeepTargets,
+			ingresses:     []networkingv1.Ingress{web},
+			wantGateway:   map[string]string{"external-dns.alpha.kubernetes.io/target": "203.0.113.10"},
+			wantHTTPRoute: map[string]string{"external-dns.alpha.kubernetes.io/hostname": "www.example.com", "external-dns.alpha.kubernetes.io/ttl": "60"},
+		},
+		{
+			name:      "none",
+			mode:      ExternalDNSNone,
+			ingresses: []networkingv1.Ingress{web, api},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resources, report := convertInput(inputResources{ingresses: tc.ingresses}, ConvertOptions{ExternalDNS: tc.mode})
+			if len(resources.Gateways) != 1 || len(resources.HTTPRoutes) != 1 {
+				t.Fatalf("Expected 1 Gateway and 1 HTTPRoute, got %d and %d", len(resources.Gateways), len(resources.HTTPRoutes))
+			}
+			if diff := cmp.Diff(tc.wantGateway, resources.Gateways[0].Annotations); diff != "" {
+				t.Errorf("Unexpected Gateway annotations, diff (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(tc.wantHTTPRoute, resources.HTTPRoutes[0].Annotations); diff != "" {
+				t.Errorf("Unexpected HTTPRoute annotations, diff (-want +got): %s", diff)
+			}
+			var warnings []string
+			for _, n := range report.Notifications {
+				if n.Type == WarningNotification && strings.Contains(n.Message, "external-dns") {
+					warnings = append(warnings, n.Message)
+				}
+			}
+			if diff := cmp.Diff(tc.wantWarnings, warnings); diff != "" {
+				t.Errorf("Unexpected warnings, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
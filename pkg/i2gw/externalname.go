@@ -0,0 +1,101 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// externalNameTargets returns the target implementations emitting backends
+// for ExternalName Services.
+func externalNameTargets() []string {
+	var names []string
+	for _, name := range TargetImplementations() {
+		if targetImplementations[name].externalNameKind != (backendKind{}) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func validateExternalNameBackends(enabled bool, target string) error {
+	if enabled && targetImplementations[target].externalNameKind == (backendKind{}) {
+		return fmt.Errorf("routing ExternalName Services to external backends requires one of the target implementations: %s", strings.Join(externalNameTargets(), ", "))
+	}
+	return nil
+}
+
+// externalBackendName returns the name of the external backend of a port of
+// an ExternalName Service.
+func externalBackendName(service string, port int32) string {
+	name := fmt.Sprintf("%s-%d", service, port)
+	return truncateName(name, name, maxObjectNameLength)
+}
+
+// externalBackends returns the external backends the backendRefs of
+// ExternalName Services point to, when enabled, and otherwise warns about
+// these backendRefs, which many Gateway implementations reject.
+func (a *ingressAggregator) externalBackends() ([]ir.ExternalBackend, []Notification) {
+	r := a.backendResolver()
+	var backends []ir.ExternalBackend
+	var notes []Notification
+	seen := map[types.NamespacedName]bool{}
+	a.forEachBackend(func(namespace, ingressName string, ib *networkingv1.IngressBackend) {
+		if ib.Service == nil {
+			return
+		}
+		service := types.NamespacedName{Namespace: namespace, Name: ib.Service.Name}
+		hostname, ok := r.externalNames[service]
+		if !ok {
+			return
+		}
+		if r.externalNameKind == (backendKind{}) {
+			if seen[service] {
+				return
+			}
+			seen[service] = true
+			msg := fmt.Sprintf("Service %s, a backend of Ingress %s/%s, is an ExternalName Service for %s, which many Gateway implementations reject as a backendRef", service, namespace, ingressName, hostname)
+			if targets := externalNameTargets(); len(targets) > 0 {
+				msg += fmt.Sprintf(", use --external-name-backends with --target-implementation=%s to route to an external backend instead", strings.Join(targets, "|"))
+			}
+			notes = append(notes, notifications.NewWarning("%s", msg))
+			return
+		}
+		if ib.Service.Port.Number == 0 {
+			return
+		}
+		backend := types.NamespacedName{Namespace: namespace, Name: externalBackendName(ib.Service.Name, ib.Service.Port.Number)}
+		if seen[backend] {
+			return
+		}
+		seen[backend] = true
+		backends = append(backends, ir.ExternalBackend{
+			Namespace: namespace,
+			Name:      backend.Name,
+			Service:   ib.Service.Name,
+			Hostname:  hostname,
+			Port:      ib.Service.Port.Number,
+		})
+		notes = append(notes, notifications.NewInfo("Service %s, a backend of Ingress %s/%s, is an ExternalName Service for %s, the backendRef points to %s %s instead", service, namespace, ingressName, hostname, r.externalNameKind, backend))
+	})
+	return backends, notes
+}
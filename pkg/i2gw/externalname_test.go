@@ -0,0 +1,96 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/targets/envoygateway"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_externalBackends(t *testing.T) {
+	service := unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   map[string]interface{}{"namespace": "test", "name": "external"},
+		"spec":       map[string]interface{}{"type": "ExternalName", "externalName": "api.example.net"},
+	}}
+
+	testCases := []struct {
+		name                 string
+		target               string
+		externalNameBackends bool
+		expectedBackendRef   string
+		expectedBackends     []ir.ExternalBackend
+		expectNotifications  []string
+	}{{
+		name:               "ExternalName Service kept as backendRef",
+		target:             envoygateway.Name,
+		expectedBackendRef: "Service external",
+		expectNotifications: []string{
+			"WARNING: Service test/external, a backend of Ingress test/web, is an ExternalName Service for api.example.net, which many Gateway implementations reject as a backendRef, use --external-name-backends with --target-implementation=envoy-gateway to route to an external backend instead",
+		},
+	}, {
+		name:                 "ExternalName Service routed to a Backend",
+		target:               envoygateway.Name,
+		externalNameBackends: true,
+		expectedBackendRef:   "gateway.envoyproxy.io Backend external-80",
+		expectedBackends:     []ir.ExternalBackend{{Namespace: "test", Name: "external-80", Service: "external", Hostname: "api.example.net", Port: 80}},
+		expectNotifications: []string{
+			"INFO: Service test/external, a backend of Ingress test/web, is an ExternalName Service for api.example.net, the backendRef points to Backend gateway.envoyproxy.io test/external-80 instead",
+		},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			iPrefix := networkingv1.PathTypePrefix
+			aggregator := newIngressAggregator(builtinProviders())
+			aggregator.backendKinds = targetBackendKinds(tc.target)
+			aggregator.externalNameBackends = tc.externalNameBackends
+			aggregator.externalNameKind = targetImplementations[tc.target].externalNameKind
+			aggregator.addServices([]unstructured.Unstructured{service})
+			aggregator.addIngress(ingressWithPath("web", "/", &iPrefix, serviceBackend("external", 80), nil))
+			result, errs := aggregator.toIR()
+			if len(errs) > 0 || len(result.HTTPRoutes) != 1 {
+				t.Fatalf("Expected 1 HTTPRoute and no errors, got %+v, %v", result.HTTPRoutes, errs)
+			}
+
+			ref := result.HTTPRoutes[0].Rules[0].Backends[0].BackendObjectReference
+			gotBackendRef := "Service " + string(ref.Name)
+			if ref.Kind != nil {
+				gotBackendRef = string(*ref.Group) + " " + string(*ref.Kind) + " " + string(ref.Name)
+			}
+			if gotBackendRef != tc.expectedBackendRef || *ref.Port != 80 {
+				t.Errorf("Expected backendRef %s on port 80, got %+v", tc.expectedBackendRef, ref)
+			}
+			if diff := cmp.Diff(tc.expectedBackends, result.ExternalBackends); diff != "" {
+				t.Errorf("Unexpected external backends, diff (-want +got): %s", diff)
+			}
+
+			var gotNotifications []string
+			for _, n := range aggregator.notifications {
+				gotNotifications = append(gotNotifications, n.String())
+			}
+			if diff := cmp.Diff(tc.expectNotifications, gotNotifications); diff != "" {
+				t.Errorf("Unexpected notifications, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const hstsHeader = "Strict-Transport-Security"
+
+// toHTTPRouteFilters converts the header changes of an Ingress and the
+// response headers set by its policy. User agents ignore the HSTS header of
+// plain HTTP responses, so it is set for both listeners.
+func toHTTPRouteFilters(features *ir.IngressFeatures) []gatewayv1.HTTPRouteFilter {
+	if features == nil {
+		return nil
+	}
+	var filters []gatewayv1.HTTPRouteFilter
+	if features.Headers != nil && !isEmptyHeaderFilter(features.Headers.Request) {
+		filters = append(filters, gatewayv1.HTTPRouteFilter{
+			Type:                  gatewayv1.HTTPRouteFilterRequestHeaderModifier,
+			RequestHeaderModifier: features.Headers.Request.DeepCopy(),
+		})
+	}
+	response := &gatewayv1.HTTPHeaderFilter{}
+	if features.Headers != nil && features.Headers.Response != nil {
+		response = features.Headers.Response.DeepCopy()
+	}
+	if features.Policy != nil && features.Policy.HSTS != nil && headerIndex(response.Set, hstsHeader) < 0 {
+		response.Set = append(response.Set, gatewayv1.HTTPHeader{Name: hstsHeader, Value: hstsValue(features.Policy.HSTS)})
+	}
+	if !isEmptyHeaderFilter(response) {
+		filters = append(filters, gatewayv1.HTTPRouteFilter{
+			Type:                   gatewayv1.HTTPRouteFilterResponseHeaderModifier,
+			ResponseHeaderModifier: response,
+		})
+	}
+	return filters
+}
+
+func isEmptyHeaderFilter(filter *gatewayv1.HTTPHeaderFilter) bool {
+	return filter == nil || len(filter.Set) == 0 && len(filter.Add) == 0 && len(filter.Remove) == 0
+}
+
+func toURLRewriteFilter(path gatewayv1.HTTPPathModifier) gatewayv1.HTTPRouteFilter {
+	return gatewayv1.HTTPRouteFilter{
+		Type:       gatewayv1.HTTPRouteFilterURLRewrite,
+		URLRewrite: &gatewayv1.HTTPURLRewriteFilter{Path: &path},
+	}
+}
+
+func hstsValue(hsts *ir.HSTS) string {
+	value := fmt.Sprintf("max-age=%d", int64(hsts.MaxAge.Seconds()))
+	if hsts.IncludeSubdomains {
+		value += "; includeSubDomains"
+	}
+	if hsts.Preload {
+		value += "; preload"
+	}
+	return value
+}
+
+// filterOrder is the order filters are emitted in: the filters changing
+// where requests go first, then header modifiers, so that mirrors and
+// implementation-specific filters see the modified requests.
+var filterOrder = map[gatewayv1.HTTPRouteFilterType]int{
+	gatewayv1.HTTPRouteFilterRequestRedirect:        0,
+	gatewayv1.HTTPRouteFilterURLRewrite:             1,
+	gatewayv1.HTTPRouteFilterRequestHeaderModifier:  2,
+	gatewayv1.HTTPRouteFilterResponseHeaderModifier: 3,
+	gatewayv1.HTTPRouteFilterRequestMirror:          4,
+	gatewayv1.HTTPRouteFilterExtensionRef:           5,
+}
+
+// normalizeFilters makes the filters of every rule valid once all the
+// features adding filters ran: identical filters are deduplicated, header
+// modifiers are merged, only the first redirect or rewrite is kept, rules
+// redirecting their requests lose their backends, and filters are ordered
+// by type.
+func normalizeFilters(result *ir.IR) []Notification {
+	var notes []Notification
+	for i := range result.HTTPRoutes {
+		route := &result.HTTPRoutes[i]
+		for j := range route.Rules {
+			rule := &route.Rules[j]
+			if len(rule.Filters) == 0 {
+				continue
+			}
+			filters, ruleNotes := normalizeRuleFilters(*route, rule.Filters)
+			notes = append(notes, ruleNotes...)
+			rule.Filters = filters
+			if len(rule.Backends) > 0 && hasFilter(filters, gatewayv1.HTTPRouteFilterRequestRedirect) {
+				notes = append(notes, notifications.NewWarning("%s redirects its requests, its backends are dropped", ruleDescription(*route, *rule)))
+				rule.Backends = nil
+			}
+		}
+	}
+	return notes
+}
+
+func normalizeRuleFilters(route ir.HTTPRoute, filters []gatewayv1.HTTPRouteFilter) ([]gatewayv1.HTTPRouteFilter, []Notification) {
+	var notes []Notification
+	var normalized []gatewayv1.HTTPRouteFilter
+	byType := map[gatewayv1.HTTPRouteFilterType]int{}
+	for _, filter := range filters {
+		if containsFilter(normalized, filter) {
+			continue
+		}
+		i, seen := byType[filter.Type]
+		switch {
+		case !seen:
+			byType[filter.Type] = len(normalized)
+			normalized = append(normalized, *filter.DeepCopy())
+		case filter.Type == gatewayv1.HTTPRouteFilterRequestHeaderModifier:
+			notes = append(notes, mergeHeaderFilter(normalized[i].RequestHeaderModifier, filter.RequestHeaderModifier, route, "request")...)
+		case filter.Type == gatewayv1.HTTPRouteFilterResponseHeaderModifier:
+			notes = append(notes, mergeHeaderFilter(normalized[i].ResponseHeaderModifier, filter.ResponseHeaderModifier, route, "response")...)
+		case filter.Type == gatewayv1.HTTPRouteFilterRequestRedirect || filter.Type == gatewayv1.HTTPRouteFilterURLRewrite:
+			notes = append(notes, notifications.NewWarning("Ingresses of HTTPRoute %s/%s configure conflicting %s filters for the same rule, only the first is kept", route.Namespace, route.Name, filter.Type))
+		default:
+			// RequestMirror and ExtensionRef filters can be repeated.
+			normalized = append(normalized, *filter.DeepCopy())
+		}
+	}
+
+	if _, ok := byType[gatewayv1.HTTPRouteFilterRequestRedirect]; ok {
+		if i, ok := byType[gatewayv1.HTTPRouteFilterURLRewrite]; ok {
+			notes = append(notes, notifications.NewWarning("Ingresses of HTTPRoute %s/%s both redirect and rewrite the requests of the same rule, the rewrite is dropped", route.Namespace, route.Name))
+			normalized = append(normalized[:i], normalized[i+1:]...)
+		}
+	}
+	sort.SliceStable(normalized, func(i, j int) bool {
+		return filterOrder[normalized[i].Type] < filterOrder[normalized[j].Type]
+	})
+	return normalized, notes
+}
+
+// mergeHeaderFilter merges the header changes of from into into. Header
+// names are case-insensitive, and the first value set for a header wins.
+func mergeHeaderFilter(into, from *gatewayv1.HTTPHeaderFilter, route ir.HTTPRoute, direction string) []Notification {
+	var notes []Notification
+	merge := func(headers []gatewayv1.HTTPHeader, add []gatewayv1.HTTPHeader, verb string) []gatewayv1.HTTPHeader {
+		for _, header := range add {
+			i := headerIndex(headers, header.Name)
+			switch {
+			case i < 0:
+				headers = append(headers, header)
+			case headers[i].Value != header.Value:
+				notes = append(notes, notifications.NewWarning("Ingresses of HTTPRoute %s/%s %s %s header %s to different values for the same rule, only %q is kept", route.Namespace, route.Name, verb, direction, header.Name, headers[i].Value))
+			}
+		}
+		return headers
+	}
+	into.Set = merge(into.Set, from.Set, "set")
+	into.Add = merge(into.Add, from.Add, "add")
+	for _, name := range from.Remove {
+		if !containsFold(into.Remove, name) {
+			into.Remove = append(into.Remove, name)
+		}
+	}
+	return notes
+}
+
+func headerIndex(headers []gatewayv1.HTTPHeader, name gatewayv1.HTTPHeaderName) int {
+	for i, header := range headers {
+		if strings.EqualFold(string(header.Name), string(name)) {
+			return i
+		}
+	}
+	return -1
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFilter(filters []gatewayv1.HTTPRouteFilter, filter gatewayv1.HTTPRouteFilter) bool {
+	for _, f := range filters {
+		if reflect.DeepEqual(f, filter) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasFilter(filters []gatewayv1.HTTPRouteFilter, filterType gatewayv1.HTTPRouteFilterType) bool {
+	for _, f := range filters {
+		if f.Type == filterType {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleDescription names a rule of a route in notifications, by its first
+// path match since rules are reordered when they are emitted.
+func ruleDescription(route ir.HTTPRoute, rule ir.HTTPRouteRule) string {
+	for _, match := range rule.Matches {
+		if match.Path != nil && match.Path.Value != nil {
+			return fmt.Sprintf("The rule of HTTPRoute %s/%s matching path %s", route.Namespace, route.Name, *match.Path.Value)
+		}
+	}
+	return fmt.Sprintf("The rule of HTTPRoute %s/%s matching every path", route.Namespace, route.Name)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_normalizeFilters(t *testing.T) {
+	responseHeaders := func(headers ...gatewayv1.HTTPHeader) gatewayv1.HTTPRouteFilter {
+		return gatewayv1.HTTPRouteFilter{
+			Type:                   gatewayv1.HTTPRouteFilterResponseHeaderModifier,
+			ResponseHeaderModifier: &gatewayv1.HTTPHeaderFilter{Set: headers},
+		}
+	}
+	redirect := func(scheme string) gatewayv1.HTTPRouteFilter {
+		return gatewayv1.HTTPRouteFilter{
+			Type:            gatewayv1.HTTPRouteFilterRequestRedirect,
+			RequestRedirect: &gatewayv1.HTTPRequestRedirectFilter{Scheme: &scheme},
+		}
+	}
+	rewrite := gatewayv1.HTTPRouteFilter{
+		Type:       gatewayv1.HTTPRouteFilterURLRewrite,
+		URLRewrite: &gatewayv1.HTTPURLRewriteFilter{Hostname: ptrTo(gatewayv1.PreciseHostname("backend.example.com"))},
+	}
+	extensionRef := gatewayv1.HTTPRouteFilter{
+		Type:         gatewayv1.HTTPRouteFilterExtensionRef,
+		ExtensionRef: &gatewayv1.LocalObjectReference{Group: "example.com", Kind: "Filter", Name: "snippets"},
+	}
+	backends := []ir.Backend{{BackendRef: gatewayv1.BackendRef{BackendObjectReference: gatewayv1.BackendObjectReference{Name: "svc"}}}}
+
+	testCases := []struct {
+		name                string
+		rule                ir.HTTPRouteRule
+		expectFilters       []gatewayv1.HTTPRouteFilter
+		expectBackends      bool
+		expectNotifications []string
+	}{{
+		name: "identical filters deduplicated and ordered by type",
+		rule: ir.HTTPRouteRule{
+			Filters:  []gatewayv1.HTTPRouteFilter{extensionRef, responseHeaders(gatewayv1.HTTPHeader{Name: "X-A", Value: "a"}), extensionRef},
+			Backends: backends,
+		},
+		expectFilters:  []gatewayv1.HTTPRouteFilter{responseHeaders(gatewayv1.HTTPHeader{Name: "X-A", Value: "a"}), extensionRef},
+		expectBackends: true,
+	}, {
+		name: "header modifiers merged",
+		rule: ir.HTTPRouteRule{
+			Filters: []gatewayv1.HTTPRouteFilter{
+				responseHeaders(gatewayv1.HTTPHeader{Name: "X-A", Value: "a"}),
+				responseHeaders(gatewayv1.HTTPHeader{Name: "x-a", Value: "other"}, gatewayv1.HTTPHeader{Name: "X-B", Value: "b"}),
+			},
+			Backends: backends,
+		},
+		expectFilters:       []gatewayv1.HTTPRouteFilter{responseHeaders(gatewayv1.HTTPHeader{Name: "X-A", Value: "a"}, gatewayv1.HTTPHeader{Name: "X-B", Value: "b"})},
+		expectBackends:      true,
+		expectNotifications: []string{`WARNING: Ingresses of HTTPRoute test/example-com set response header x-a to different values for the same rule, only "a" is kept`},
+	}, {
+		name: "redirect excludes rewrites and backends",
+		rule: ir.HTTPRouteRule{
+			Filters:  []gatewayv1.HTTPRouteFilter{rewrite, redirect("https"), redirect("http")},
+			Matches:  []gatewayv1.HTTPRouteMatch{{Path: &gatewayv1.HTTPPathMatch{Value: stringPtr("/old")}}},
+			Backends: backends,
+		},
+		expectFilters: []gatewayv1.HTTPRouteFilter{redirect("https")},
+		expectNotifications: []string{
+			"WARNING: Ingresses of HTTPRoute test/example-com configure conflicting RequestRedirect filters for the same rule, only the first is kept",
+			"WARNING: Ingresses of HTTPRoute test/example-com both redirect and rewrite the requests of the same rule, the rewrite is dropped",
+			"WARNING: The rule of HTTPRoute test/example-com matching path /old redirects its requests, its backends are dropped",
+		},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := ir.IR{HTTPRoutes: []ir.HTTPRoute{{Namespace: "test", Name: "example-com", Rules: []ir.HTTPRouteRule{tc.rule}}}}
+			var gotNotifications []string
+			for _, n := range normalizeFilters(&result) {
+				gotNotifications = append(gotNotifications, n.String())
+			}
+			rule := result.HTTPRoutes[0].Rules[0]
+			if diff := cmp.Diff(tc.expectFilters, rule.Filters); diff != "" {
+				t.Errorf("Unexpected filters, diff (-want +got): %s", diff)
+			}
+			if tc.expectBackends != (len(rule.Backends) > 0) {
+				t.Errorf("Expected backends to be kept: %t, got %+v", tc.expectBackends, rule.Backends)
+			}
+			if diff := cmp.Diff(tc.expectNotifications, gotNotifications); diff != "" {
+				t.Errorf("Unexpected notifications, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,340 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// FindingsFormat selects how the findings of a conversion are serialized.
+type FindingsFormat string
+
+const (
+	// FindingsJSON writes the findings as a JSON document.
+	FindingsJSON FindingsFormat = "json"
+	// FindingsSARIF writes the findings as a SARIF 2.1.0 log, as consumed by
+	// code scanning tools.
+	FindingsSARIF FindingsFormat = "sarif"
+)
+
+// FindingsFormats returns the names of the supported findings formats.
+func FindingsFormats() []string {
+	return []string{string(FindingsJSON), string(FindingsSARIF)}
+}
+
+func validateFindingsFormat(format FindingsFormat) error {
+	switch format {
+	case "", FindingsJSON, FindingsSARIF:
+		return nil
+	default:
+		return fmt.Errorf("unknown findings format %q, supported ones are: %s", format, strings.Join(FindingsFormats(), ", "))
+	}
+}
+
+// Rule IDs of the findings that don't come from notifications.
+const (
+	RuleConversionError       = "conversion-error"
+	RuleInvalidResource       = "invalid-resource"
+	RuleUnsupportedAnnotation = "unsupported-annotation"
+	RuleSkippedIngress        = "skipped-ingress"
+)
+
+// FindingSeverityError is the severity of the findings reporting errors,
+// the other findings have the severity of their notification.
+const FindingSeverityError = "ERROR"
+
+// Finding is a notification, error, unsupported annotation or skipped
+// Ingress of a Report, in a form CI systems can gate on.
+type Finding struct {
+	// RuleID identifies what kind of finding this is. The IDs of findings
+	// reported from notifications are derived from their message with the
+	// names and values it contains left out, so that the same check gets
+	// the same ID across conversions.
+	RuleID   string          `json:"ruleId"`
+	Severity string          `json:"severity"`
+	Message  string          `json:"message"`
+	Objects  []FindingObject `json:"objects,omitempty"`
+	// Annotation is the annotation the finding is about, if any.
+	Annotation string `json:"annotation,omitempty"`
+}
+
+// FindingObject is a namespaced object of the input or output a Finding
+// refers to.
+type FindingObject struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+func (o FindingObject) String() string {
+	return o.Kind + "/" + o.Namespace + "/" + o.Name
+}
+
+var (
+	// findingObjectRegexp matches the namespaced objects named in
+	// notification messages, such as "Ingress default/web".
+	findingObjectRegexp = regexp.MustCompile(`\b(Ingress|Service|Secret|ConfigMap|Gateway|HTTPRoute) ([a-z0-9][-a-z0-9.]*)/([a-z0-9][-a-z0-9.]*[a-z0-9])`)
+	// findingValueRegexp matches the values interpolated in notification
+	// messages: quoted strings, paths and names, and numbers.
+	findingValueRegexp = regexp.MustCompile(`"(?:[^"\\]|\\.)*"|[^\s,()]*/[^\s,()]*|\b[0-9][0-9a-z.]*\b`)
+)
+
+// Findings returns the findings of the report: its errors, notifications,
+// unsupported annotations and skipped Ingresses.
+func Findings(report Report) []Finding {
+	var findings []Finding
+	for _, err := range report.Errors {
+		rule := RuleConversionError
+		if strings.Contains(err.Error(), " is invalid: ") {
+			rule = RuleInvalidResource
+		}
+		findings = append(findings, Finding{
+			RuleID:   rule,
+			Severity: FindingSeverityError,
+			Message:  err.Error(),
+			Objects:  findingObjects(err.Error()),
+		})
+	}
+	for _, n := range report.Notifications {
+		// Unsupported annotations are reported one by one below.
+		if strings.Contains(n.Message, unsupportedAnnotationsMessage) {
+			continue
+		}
+		findings = append(findings, Finding{
+			RuleID:   notificationRuleID(n),
+			Severity: string(n.Type),
+			Message:  n.Message,
+			Objects:  findingObjects(n.Message),
+		})
+	}
+	for _, u := range report.UnsupportedAnnotations {
+		findings = append(findings, Finding{
+			RuleID:     RuleUnsupportedAnnotation,
+			Severity:   string(WarningNotification),
+			Message:    fmt.Sprintf("Ingress %s uses annotation %s, which is not converted", u.Ingress, u.Annotation),
+			Objects:    []FindingObject{{Kind: "Ingress", Namespace: u.Ingress.Namespace, Name: u.Ingress.Name}},
+			Annotation: u.Annotation,
+		})
+	}
+	for _, ingress := range report.SkippedIngresses {
+		findings = append(findings, Finding{
+			RuleID:   RuleSkippedIngress,
+			Severity: string(WarningNotification),
+			Message:  fmt.Sprintf("Ingress %s is not converted to any Gateway or HTTPRoute", ingress),
+			Objects:  []FindingObject{{Kind: "Ingress", Namespace: ingress.Namespace, Name: ingress.Name}},
+		})
+	}
+	return findings
+}
+
+// findingObjects returns the objects named in a message, in order and
+// without duplicates.
+func findingObjects(message string) []FindingObject {
+	var objects []FindingObject
+	seen := map[FindingObject]bool{}
+	for _, m := range findingObjectRegexp.FindAllStringSubmatch(message, -1) {
+		obj := FindingObject{Kind: m[1], Namespace: m[2], Name: m[3]}
+		if !seen[obj] {
+			seen[obj] = true
+			objects = append(objects, obj)
+		}
+	}
+	return objects
+}
+
+// notificationTemplate returns the message of a notification without the
+// names and values it contains.
+func notificationTemplate(message string) string {
+	return findingValueRegexp.ReplaceAllString(message, "…")
+}
+
+func notificationRuleID(n Notification) string {
+	h := fnv.New32a()
+	h.Write([]byte(notificationTemplate(n.Message)))
+	return fmt.Sprintf("%s-%08x", strings.ToLower(string(n.Type)), h.Sum32())
+}
+
+// findingsReport collects the findings of a conversion, or of the
+// conversions of every namespace of a stream.
+type findingsReport struct {
+	findings []Finding
+}
+
+func (f *findingsReport) add(report Report) {
+	f.findings = append(f.findings, Findings(report)...)
+}
+
+// WriteFindings writes the findings of the report in the format. inputFile
+// is the manifest the findings are located in by the SARIF format, if any.
+func WriteFindings(w io.Writer, report Report, format FindingsFormat, inputFile string) error {
+	f := &findingsReport{}
+	f.add(report)
+	return f.write(w, format, inputFile)
+}
+
+func (f *findingsReport) write(w io.Writer, format FindingsFormat, inputFile string) error {
+	if err := validateFindingsFormat(format); err != nil {
+		return err
+	}
+	var doc interface{}
+	if format == FindingsSARIF {
+		doc = sarifLog(f.findings, inputFile)
+	} else {
+		findings := f.findings
+		if findings == nil {
+			findings = []Finding{}
+		}
+		doc = struct {
+			Findings []Finding `json:"findings"`
+		}{findings}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to write findings: %w", err)
+	}
+	return nil
+}
+
+// The subset of the SARIF 2.1.0 schema the findings are written with.
+type (
+	sarifDocument struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		ID               string       `json:"id"`
+		ShortDescription sarifMessage `json:"shortDescription"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifResult struct {
+		RuleID     string            `json:"ruleId"`
+		Level      string            `json:"level"`
+		Message    sarifMessage      `json:"message"`
+		Locations  []sarifLocation   `json:"locations,omitempty"`
+		Properties map[string]string `json:"properties,omitempty"`
+	}
+	sarifLocation struct {
+		PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+		LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	}
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+	sarifLogicalLocation struct {
+		Name               string `json:"name"`
+		FullyQualifiedName string `json:"fullyQualifiedName"`
+		Kind               string `json:"kind"`
+	}
+)
+
+var sarifRuleDescriptions = map[string]string{
+	RuleConversionError:       "A part of an Ingress failed to convert",
+	RuleInvalidResource:       "A generated resource is rejected by the Gateway API validation rules",
+	RuleUnsupportedAnnotation: "An Ingress uses an annotation that is not converted",
+	RuleSkippedIngress:        "An Ingress is not converted to any Gateway or HTTPRoute",
+}
+
+func sarifLevel(severity string) string {
+	switch severity {
+	case FindingSeverityError, string(BlockingNotification):
+		return "error"
+	case string(WarningNotification):
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+func sarifLog(findings []Finding, inputFile string) sarifDocument {
+	rules := map[string]string{}
+	results := []sarifResult{}
+	for _, f := range findings {
+		if _, ok := rules[f.RuleID]; !ok {
+			description, ok := sarifRuleDescriptions[f.RuleID]
+			if !ok {
+				description = notificationTemplate(f.Message)
+			}
+			rules[f.RuleID] = description
+		}
+		result := sarifResult{
+			RuleID:  f.RuleID,
+			Level:   sarifLevel(f.Severity),
+			Message: sarifMessage{Text: f.Message},
+		}
+		if f.Annotation != "" {
+			result.Properties = map[string]string{"annotation": f.Annotation}
+		}
+		var location sarifLocation
+		if inputFile != "" {
+			location.PhysicalLocation = &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: inputFile}}
+		}
+		for _, obj := range f.Objects {
+			location.LogicalLocations = append(location.LogicalLocations, sarifLogicalLocation{
+				Name:               obj.Namespace + "/" + obj.Name,
+				FullyQualifiedName: obj.String(),
+				Kind:               "resource",
+			})
+		}
+		if location.PhysicalLocation != nil || len(location.LogicalLocations) > 0 {
+			result.Locations = []sarifLocation{location}
+		}
+		results = append(results, result)
+	}
+
+	ids := make([]string, 0, len(rules))
+	for id := range rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	driver := sarifDriver{
+		Name:           "ingress2gateway",
+		InformationURI: "https://github.com/kubernetes-sigs/ingress2gateway",
+		Rules:          []sarifRule{},
+	}
+	for _, id := range ids {
+		driver.Rules = append(driver.Rules, sarifRule{ID: id, ShortDescription: sarifMessage{Text: rules[id]}})
+	}
+	return sarifDocument{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+}
@@ -0,0 +1,192 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func Test_Findings(t *testing.T) {
+	web := types.NamespacedName{Namespace: "default", Name: "web"}
+	report := Report{
+		Errors: []error{
+			errors.New("HTTPRoute default/web-example-com is invalid: spec.hostnames[0]: Invalid value"),
+			errors.New("Ingress default/api has no rules"),
+		},
+		Notifications: []Notification{
+			notifications.NewWarning("Ingress default/web rewrites path %q to %q, which can't be converted to a Gateway API rewrite", "/api", "/"),
+			notifications.NewWarning("Ingress default/web %s: nginx.ingress.kubernetes.io/foo", unsupportedAnnotationsMessage),
+			notifications.NewInfo("Gateway infra/nginx serves Ingress default/web and Ingress default/web"),
+		},
+		UnsupportedAnnotations: []UnsupportedAnnotation{{Ingress: web, Annotation: "nginx.ingress.kubernetes.io/foo"}},
+		SkippedIngresses:       []types.NamespacedName{{Namespace: "default", Name: "empty"}},
+	}
+	rewrite := report.Notifications[0]
+	served := report.Notifications[2]
+
+	expected := []Finding{{
+		RuleID:   RuleInvalidResource,
+		Severity: FindingSeverityError,
+		Message:  "HTTPRoute default/web-example-com is invalid: spec.hostnames[0]: Invalid value",
+		Objects:  []FindingObject{{Kind: "HTTPRoute", Namespace: "default", Name: "web-example-com"}},
+	}, {
+		RuleID:   RuleConversionError,
+		Severity: FindingSeverityError,
+		Message:  "Ingress default/api has no rules",
+		Objects:  []FindingObject{{Kind: "Ingress", Namespace: "default", Name: "api"}},
+	}, {
+		RuleID:   notificationRuleID(rewrite),
+		Severity: "WARNING",
+		Message:  rewrite.Message,
+		Objects:  []FindingObject{{Kind: "Ingress", Namespace: "default", Name: "web"}},
+	}, {
+		RuleID:   notificationRuleID(served),
+		Severity: "INFO",
+		Message:  served.Message,
+		Objects: []FindingObject{
+			{Kind: "Gateway", Namespace: "infra", Name: "nginx"},
+			{Kind: "Ingress", Namespace: "default", Name: "web"},
+		},
+	}, {
+		RuleID:     RuleUnsupportedAnnotation,
+		Severity:   "WARNING",
+		Message:    "Ingress default/web uses annotation nginx.ingress.kubernetes.io/foo, which is not converted",
+		Objects:    []FindingObject{{Kind: "Ingress", Namespace: "default", Name: "web"}},
+		Annotation: "nginx.ingress.kubernetes.io/foo",
+	}, {
+		RuleID:   RuleSkippedIngress,
+		Severity: "WARNING",
+		Message:  "Ingress default/empty is not converted to any Gateway or HTTPRoute",
+		Objects:  []FindingObject{{Kind: "Ingress", Namespace: "default", Name: "empty"}},
+	}}
+	if diff := cmp.Diff(expected, Findings(report)); diff != "" {
+		t.Errorf("Findings() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_notificationRuleID(t *testing.T) {
+	testCases := []struct {
+		name       string
+		a, b       Notification
+		expectSame bool
+	}{{
+		name:       "same check on other objects and values",
+		a:          notifications.NewWarning("Ingress default/web has an invalid %s annotation %q, ignoring it", "nginx.ingress.kubernetes.io/proxy-read-timeout", "abc"),
+		b:          notifications.NewWarning("Ingress prod/api has an invalid %s annotation %q, ignoring it", "nginx.ingress.kubernetes.io/proxy-send-timeout", "10x"),
+		expectSame: true,
+	}, {
+		name:       "same message with another severity",
+		a:          notifications.NewWarning("Ingress default/web disables access logs"),
+		b:          notifications.NewInfo("Ingress default/web disables access logs"),
+		expectSame: false,
+	}, {
+		name:       "different checks",
+		a:          notifications.NewWarning("Ingress default/web disables access logs"),
+		b:          notifications.NewWarning("Ingress default/web retries requests on %s, which can't be converted", "http_503"),
+		expectSame: false,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a, b := notificationRuleID(tc.a), notificationRuleID(tc.b)
+			if (a == b) != tc.expectSame {
+				t.Errorf("notificationRuleID() = %s and %s, expected them to be the same: %v", a, b, tc.expectSame)
+			}
+		})
+	}
+}
+
+func Test_WriteFindings(t *testing.T) {
+	report := Report{
+		Notifications: []Notification{
+			notifications.NewBlocking("Ingress default/web proxies requests to its backends with %s, which Gateway API doesn't support", "GRPC"),
+		},
+		UnsupportedAnnotations: []UnsupportedAnnotation{{
+			Ingress:    types.NamespacedName{Namespace: "default", Name: "web"},
+			Annotation: "nginx.ingress.kubernetes.io/foo",
+		}},
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteFindings(&buf, Report{}, FindingsJSON, ""); err != nil {
+			t.Fatalf("WriteFindings() failed: %v", err)
+		}
+		if diff := cmp.Diff("{\n  \"findings\": []\n}\n", buf.String()); diff != "" {
+			t.Errorf("WriteFindings() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("sarif", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteFindings(&buf, report, FindingsSARIF, "ingresses.yaml"); err != nil {
+			t.Fatalf("WriteFindings() failed: %v", err)
+		}
+		var doc sarifDocument
+		if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("failed to decode SARIF log: %v", err)
+		}
+		blocking := notificationRuleID(report.Notifications[0])
+		expected := sarifDocument{
+			Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+			Version: "2.1.0",
+			Runs: []sarifRun{{
+				Tool: sarifTool{Driver: sarifDriver{
+					Name:           "ingress2gateway",
+					InformationURI: "https://github.com/kubernetes-sigs/ingress2gateway",
+					Rules: []sarifRule{
+						{ID: blocking, ShortDescription: sarifMessage{Text: "Ingress … proxies requests to its backends with GRPC, which Gateway API doesn't support"}},
+						{ID: RuleUnsupportedAnnotation, ShortDescription: sarifMessage{Text: "An Ingress uses an annotation that is not converted"}},
+					},
+				}},
+				Results: []sarifResult{{
+					RuleID:  blocking,
+					Level:   "error",
+					Message: sarifMessage{Text: report.Notifications[0].Message},
+					Locations: []sarifLocation{{
+						PhysicalLocation: &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: "ingresses.yaml"}},
+						LogicalLocations: []sarifLogicalLocation{{Name: "default/web", FullyQualifiedName: "Ingress/default/web", Kind: "resource"}},
+					}},
+				}, {
+					RuleID:  RuleUnsupportedAnnotation,
+					Level:   "warning",
+					Message: sarifMessage{Text: "Ingress default/web uses annotation nginx.ingress.kubernetes.io/foo, which is not converted"},
+					Locations: []sarifLocation{{
+						PhysicalLocation: &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: "ingresses.yaml"}},
+						LogicalLocations: []sarifLogicalLocation{{Name: "default/web", FullyQualifiedName: "Ingress/default/web", Kind: "resource"}},
+					}},
+					Properties: map[string]string{"annotation": "nginx.ingress.kubernetes.io/foo"},
+				}},
+			}},
+		}
+		if diff := cmp.Diff(expected, doc); diff != "" {
+			t.Errorf("WriteFindings() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		if err := WriteFindings(&bytes.Buffer{}, report, "xml", ""); err == nil {
+			t.Errorf("WriteFindings() succeeded, expected an error")
+		}
+	})
+}
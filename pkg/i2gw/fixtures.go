@@ -0,0 +1,228 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/printers"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultFixtureName is the name of the fixture files when none is
+	// given.
+	DefaultFixtureName = "cluster"
+	// redactedValue replaces the sensitive values of fixtures.
+	redactedValue = "REDACTED"
+	// lastAppliedAnnotation holds a copy of the object as last applied by
+	// kubectl, which may include the values redacted from the object.
+	lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+)
+
+// RecordFixturesOptions configures RecordFixtures.
+type RecordFixturesOptions struct {
+	// Client is the client of the cluster to record.
+	Client client.Client
+	// Dir is the fixtures directory, written in the layout of the
+	// i2gwtest golden files.
+	Dir string
+	// Name is the name of the fixture files, DefaultFixtureName if empty.
+	Name string
+	// Namespace restricts the recorded Ingresses and objects to a
+	// namespace. All namespaces are recorded if empty.
+	Namespace string
+	// TargetImplementation is the Gateway API implementation the objects
+	// of its input kinds are recorded for, and the expected output is
+	// converted for.
+	TargetImplementation string
+}
+
+// RecordFixtures snapshots the Ingresses, IngressClasses and objects of the
+// input kinds of the target implementation of the cluster to
+// Dir/input/<Name>.yaml, without status, the metadata populated by the API
+// server and the values of Secrets and credentials, and writes their
+// conversion to Dir/output/<Name>.yaml, so that the fixture can be run with
+// i2gwtest.Run or attached to bug reports. It returns the warnings about
+// the objects that couldn't be listed.
+func RecordFixtures(ctx context.Context, opts RecordFixturesOptions) ([]notifications.Notification, error) {
+	target, err := lookupTargetImplementation(opts.TargetImplementation)
+	if err != nil {
+		return nil, err
+	}
+	name := opts.Name
+	if name == "" {
+		name = DefaultFixtureName
+	}
+
+	input, warnings, err := readInputFromCluster(ctx, opts.Client, target, opts.Namespace)
+	if err != nil {
+		return warnings, err
+	}
+	objects, err := fixtureObjects(input)
+	if err != nil {
+		return warnings, err
+	}
+	var buf bytes.Buffer
+	printer := &printers.YAMLPrinter{}
+	for _, obj := range objects {
+		if err := printer.PrintObj(obj, &buf); err != nil {
+			return warnings, fmt.Errorf("failed to print %s %s/%s: %w", obj.GetKind(), obj.GetNamespace(), obj.GetName(), err)
+		}
+	}
+	inputFile := filepath.Join(opts.Dir, "input", name+".yaml")
+	if err := writeLayoutFile(inputFile, buf.Bytes()); err != nil {
+		return warnings, err
+	}
+
+	// The expected output is converted from the file rather than from the
+	// objects, for it to be what running the fixture outputs.
+	resources, report, err := Convert(ctx, ConvertOptions{InputFile: inputFile, TargetImplementation: opts.TargetImplementation})
+	if err != nil {
+		return warnings, err
+	}
+	buf.Reset()
+	WriteResult(&buf, resources, report)
+	return warnings, writeLayoutFile(filepath.Join(opts.Dir, "output", name+".yaml"), buf.Bytes())
+}
+
+// fixtureObjects returns the sanitized objects of the input, IngressClasses
+// first, then Ingresses and the other objects by namespace and name.
+func fixtureObjects(input inputResources) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	for i := range input.ingressClasses {
+		ingressClass := input.ingressClasses[i].DeepCopy()
+		ingressClass.SetGroupVersionKind(networkingv1.SchemeGroupVersion.WithKind("IngressClass"))
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(ingressClass)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert IngressClass %s: %w", ingressClass.Name, err)
+		}
+		objects = append(objects, &unstructured.Unstructured{Object: u})
+	}
+	var namespaced []*unstructured.Unstructured
+	for i := range input.ingresses {
+		ingress := input.ingresses[i].DeepCopy()
+		ingress.SetGroupVersionKind(networkingv1.SchemeGroupVersion.WithKind("Ingress"))
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(ingress)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert Ingress %s/%s: %w", ingress.Namespace, ingress.Name, err)
+		}
+		namespaced = append(namespaced, &unstructured.Unstructured{Object: u})
+	}
+	for i := range input.objects {
+		namespaced = append(namespaced, input.objects[i].DeepCopy())
+	}
+	sort.SliceStable(objects, func(i, j int) bool { return objects[i].GetName() < objects[j].GetName() })
+	sort.SliceStable(namespaced, func(i, j int) bool {
+		if namespaced[i].GetNamespace() != namespaced[j].GetNamespace() {
+			return namespaced[i].GetNamespace() < namespaced[j].GetNamespace()
+		}
+		return namespaced[i].GetName() < namespaced[j].GetName()
+	})
+	objects = append(objects, namespaced...)
+	for _, obj := range objects {
+		sanitizeFixture(obj)
+	}
+	return objects, nil
+}
+
+// sanitizeFixture removes the status and the metadata populated by the API
+// server from obj, and redacts the data of Secrets and the passwords of the
+// URLs of annotations.
+func sanitizeFixture(obj *unstructured.Unstructured) {
+	unstructured.RemoveNestedField(obj.Object, "status")
+	for _, field := range []string{"managedFields", "uid", "resourceVersion", "generation", "creationTimestamp", "selfLink"} {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+
+	if annotations := obj.GetAnnotations(); annotations != nil {
+		delete(annotations, lastAppliedAnnotation)
+		for key, value := range annotations {
+			annotations[key] = redactURLPassword(value)
+		}
+		if len(annotations) == 0 {
+			annotations = nil
+		}
+		obj.SetAnnotations(annotations)
+	}
+
+	if obj.GetKind() == "Secret" {
+		for _, field := range []string{"data", "stringData"} {
+			data, ok := obj.Object[field].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for key := range data {
+				data[key] = redactedValue
+			}
+		}
+	}
+}
+
+// redactURLPassword redacts the password of value if it's a URL with one,
+// such as those of external authentication annotations.
+func redactURLPassword(value string) string {
+	if !strings.Contains(value, "://") {
+		return value
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return value
+	}
+	if _, ok := u.User.Password(); !ok {
+		return value
+	}
+	u.User = url.UserPassword(u.User.Username(), redactedValue)
+	return u.String()
+}
+
+// RunRecordFixtures records the fixtures of the cluster of the current
+// kubeconfig as the record-fixtures command does.
+func RunRecordFixtures(opts RecordFixturesOptions) {
+	cl, err := newClient("", client.Options{})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	opts.Client = cl
+
+	ctx, cancel := runContext(0)
+	defer cancel()
+	warnings, err := RecordFixtures(ctx, opts)
+	for _, n := range warnings {
+		fmt.Println(n)
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	name := opts.Name
+	if name == "" {
+		name = DefaultFixtureName
+	}
+	fmt.Printf("Recorded %s and %s\n", filepath.Join(opts.Dir, "input", name+".yaml"), filepath.Join(opts.Dir, "output", name+".yaml"))
+}
@@ -0,0 +1,220 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"text/template"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FleetReportFile is the consolidated report of a fleet conversion, written
+// to the output directory.
+const FleetReportFile = "fleet-report.json"
+
+// FleetReport is the consolidated report of the conversions of the clusters
+// of a fleet.
+type FleetReport struct {
+	// GeneratedAt is when the conversions ran, in RFC 3339 format.
+	GeneratedAt string `json:"generatedAt,omitempty"`
+	// Total are the numbers of Ingresses of every cluster by status.
+	Total NamespaceStats `json:"total"`
+	// Clusters are in the order of their contexts.
+	Clusters []ClusterReport `json:"clusters"`
+}
+
+// ClusterReport is the result of the conversion of a cluster of a fleet.
+type ClusterReport struct {
+	Context string `json:"context"`
+	// Directory is the directory of the output of the cluster, relative to
+	// the output directory.
+	Directory string `json:"directory"`
+	// Error is why the cluster failed to convert, if it did.
+	Error      string         `json:"error,omitempty"`
+	Ingresses  NamespaceStats `json:"ingresses"`
+	Gateways   int            `json:"gateways"`
+	HTTPRoutes int            `json:"httpRoutes"`
+	// Findings are the numbers of findings by severity.
+	Findings map[string]int `json:"findings,omitempty"`
+}
+
+func validateFleet(runOpts RunOptions) error {
+	if len(runOpts.FleetContexts) == 0 {
+		return nil
+	}
+	switch {
+	case runOpts.OutputDir == "":
+		return fmt.Errorf("fleet mode requires an output directory")
+	case runOpts.InputFile != "" || runOpts.HelmChart.Chart != "" || runOpts.SourceContext != "" || runOpts.Stream:
+		return fmt.Errorf("fleet mode reads every cluster of its contexts, it can't be combined with an input file, a Helm chart, a source context or streaming")
+	case runOpts.Template != "" || isGraphFormat(runOpts.Output):
+		return fmt.Errorf("fleet mode doesn't support templates and the %s and %s output formats", OutputMermaid, OutputDOT)
+	case runOpts.Summary || runOpts.CapacityReport || runOpts.WeightReport || runOpts.Findings != "" || runOpts.HostMapping != "" || runOpts.Stats || runOpts.CleanupFile != "":
+		return fmt.Errorf("fleet mode writes a consolidated report instead of the summary, reports, findings, host mapping, statistics or cleanup file")
+	case runOpts.TargetContext != "" || runOpts.MarkMigrated || runOpts.CacheDir != "":
+		return fmt.Errorf("fleet mode can't be combined with a target context, marking Ingresses as migrated or the output cache")
+	}
+	return nil
+}
+
+var invalidDirectoryChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// fleetDirectories returns the output directory of every context, named
+// after it. Contexts whose names only differ by the characters left out of
+// directory names get a hash of their name.
+func fleetDirectories(contexts []string) []string {
+	dirs := make([]string, len(contexts))
+	taken := map[string]bool{}
+	for i, kubeContext := range contexts {
+		dir := invalidDirectoryChars.ReplaceAllString(kubeContext, "-")
+		if dir == "" || dir == "." || dir == ".." || taken[dir] {
+			dir += "-" + shortHash(kubeContext)
+		}
+		taken[dir] = true
+		dirs[i] = dir
+	}
+	return dirs
+}
+
+// runFleet converts the clusters of the fleet contexts one after the
+// other, writing the output of each to a directory of the output directory
+// and the consolidated report to FleetReportFile. Clusters failing to
+// convert are reported, without stopping the others.
+func runFleet(ctx context.Context, w io.Writer, runOpts RunOptions, tmpl *template.Template, now time.Time) error {
+	fleet := FleetReport{GeneratedAt: now.UTC().Format(time.RFC3339)}
+	var failed int
+	for i, dir := range fleetDirectories(runOpts.FleetContexts) {
+		cluster := ClusterReport{Context: runOpts.FleetContexts[i], Directory: dir}
+		if err := convertFleetCluster(ctx, runOpts, tmpl, &cluster); err != nil {
+			cluster.Error = err.Error()
+			failed++
+		}
+		fleet.Total = addNamespaceStats(fleet.Total, cluster.Ingresses)
+		fleet.Clusters = append(fleet.Clusters, cluster)
+		fmt.Fprintf(w, "%s: %s\n", cluster.Context, clusterSummary(cluster))
+	}
+
+	data, err := json.MarshalIndent(fleet, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to write the fleet report: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(runOpts.OutputDir, FleetReportFile), append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write the fleet report: %w", err)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d clusters failed to convert, see %s", failed, len(runOpts.FleetContexts), filepath.Join(runOpts.OutputDir, FleetReportFile))
+	}
+	return nil
+}
+
+// convertFleetCluster converts the cluster of a context and writes its
+// output to its directory, with the report of the conversion.
+func convertFleetCluster(ctx context.Context, runOpts RunOptions, tmpl *template.Template, cluster *ClusterReport) error {
+	runOpts.SourceContext = cluster.Context
+	runOpts.OutputDir = filepath.Join(runOpts.OutputDir, cluster.Directory)
+	opts := runConvertOptions(runOpts)
+	if runOpts.CheckGatewayClasses {
+		gatewayClasses, err := targetGatewayClasses(ctx, runOpts)
+		if err != nil {
+			return err
+		}
+		opts.GatewayClasses = gatewayClasses
+	}
+	if runOpts.DiscoverCapabilities {
+		caps, err := targetCapabilities(ctx, runOpts)
+		if err != nil {
+			return err
+		}
+		opts.Capabilities = caps
+	}
+	cl, err := newClient(cluster.Context, client.Options{})
+	if err != nil {
+		return err
+	}
+	opts.Client = cl
+
+	resources, report, err := Convert(ctx, opts)
+	if err != nil {
+		return err
+	}
+	cluster.Ingresses = Stats(resources, report).Total
+	cluster.Gateways, cluster.HTTPRoutes = len(resources.Gateways), len(resources.HTTPRoutes)
+	for _, finding := range Findings(report) {
+		if cluster.Findings == nil {
+			cluster.Findings = map[string]int{}
+		}
+		cluster.Findings[finding.Severity]++
+	}
+
+	if err := os.MkdirAll(runOpts.OutputDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	// Directory layouts write the resources themselves, only the report
+	// goes to the file.
+	name := "resources.yaml"
+	if isDirectoryLayout(runOpts.OutputLayout) {
+		name = "report.txt"
+	}
+	f, err := os.Create(filepath.Join(runOpts.OutputDir, name))
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer f.Close()
+	sink, err := runSink(runOpts, tmpl, f, f)
+	if err != nil {
+		return err
+	}
+	if err := sink.Write(ctx, resources, report); err != nil {
+		return err
+	}
+	if err := sink.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// addNamespaceStats adds the numbers of Ingresses of two statistics.
+func addNamespaceStats(total, stats NamespaceStats) NamespaceStats {
+	total.Ingresses += stats.Ingresses
+	total.Converted += stats.Converted
+	total.PartiallyConverted += stats.PartiallyConverted
+	total.Blocked += stats.Blocked
+	total.Skipped += stats.Skipped
+	total.Excluded += stats.Excluded
+	for annotation, count := range stats.UnsupportedAnnotations {
+		if total.UnsupportedAnnotations == nil {
+			total.UnsupportedAnnotations = map[string]int{}
+		}
+		total.UnsupportedAnnotations[annotation] += count
+	}
+	return total
+}
+
+func clusterSummary(cluster ClusterReport) string {
+	if cluster.Error != "" {
+		return "failed: " + cluster.Error
+	}
+	return fmt.Sprintf("%d Ingresses, %d converted, %d partially converted, %d blocked, %d skipped, %d excluded; %d Gateways, %d HTTPRoutes",
+		cluster.Ingresses.Ingresses, cluster.Ingresses.Converted, cluster.Ingresses.PartiallyConverted, cluster.Ingresses.Blocked, cluster.Ingresses.Skipped, cluster.Ingresses.Excluded, cluster.Gateways, cluster.HTTPRoutes)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_validateFleet(t *testing.T) {
+	testCases := []struct {
+		name    string
+		runOpts RunOptions
+		wantErr bool
+	}{
+		{name: "no fleet", runOpts: RunOptions{InputFile: "input.yaml"}},
+		{name: "fleet", runOpts: RunOptions{FleetContexts: []string{"a", "b"}, OutputDir: "out"}},
+		{name: "directory layout", runOpts: RunOptions{FleetContexts: []string{"a"}, OutputDir: "out", OutputLayout: LayoutNamespaces}},
+		{name: "no output directory", runOpts: RunOptions{FleetContexts: []string{"a"}}, wantErr: true},
+		{name: "input file", runOpts: RunOptions{FleetContexts: []string{"a"}, OutputDir: "out", InputFile: "input.yaml"}, wantErr: true},
+		{name: "graph output", runOpts: RunOptions{FleetContexts: []string{"a"}, OutputDir: "out", Output: OutputMermaid}, wantErr: true},
+		{name: "statistics", runOpts: RunOptions{FleetContexts: []string{"a"}, OutputDir: "out", Stats: true}, wantErr: true},
+		{name: "target context", runOpts: RunOptions{FleetContexts: []string{"a"}, OutputDir: "out", TargetContext: "b"}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateFleet(tc.runOpts)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("validateFleet() error = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func Test_fleetDirectories(t *testing.T) {
+	got := fleetDirectories([]string{"prod-eu", "arn:aws:eks:eu-west-1:123:cluster/prod", "arn:aws:eks:eu-west-1:123:cluster:prod", ".."})
+	want := []string{
+		"prod-eu",
+		"arn-aws-eks-eu-west-1-123-cluster-prod",
+		"arn-aws-eks-eu-west-1-123-cluster-prod-" + shortHash("arn:aws:eks:eu-west-1:123:cluster:prod"),
+		"..-" + shortHash(".."),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("fleetDirectories() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_addNamespaceStats(t *testing.T) {
+	total := addNamespaceStats(NamespaceStats{}, NamespaceStats{Ingresses: 2, Converted: 1, Blocked: 1, UnsupportedAnnotations: map[string]int{"a": 1}})
+	total = addNamespaceStats(total, NamespaceStats{Ingresses: 3, PartiallyConverted: 2, Skipped: 1, UnsupportedAnnotations: map[string]int{"a": 2, "b": 1}})
+	want := NamespaceStats{Ingresses: 5, Converted: 1, PartiallyConverted: 2, Blocked: 1, Skipped: 1, UnsupportedAnnotations: map[string]int{"a": 3, "b": 1}}
+	if diff := cmp.Diff(want, total); diff != "" {
+		t.Errorf("addNamespaceStats() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_runFleet_failedClusters(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(kubeconfig, []byte("apiVersion: v1\nkind: Config\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("KUBECONFIG", kubeconfig)
+	dir := t.TempDir()
+
+	var out bytes.Buffer
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	err := runFleet(context.Background(), &out, RunOptions{FleetContexts: []string{"prod", "staging"}, OutputDir: dir}, nil, now)
+	if err == nil || !strings.Contains(err.Error(), "2 of 2 clusters failed to convert") {
+		t.Errorf("runFleet() error = %v, want the failed clusters", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, FleetReportFile))
+	if err != nil {
+		t.Fatalf("Failed to read the fleet report: %v", err)
+	}
+	var report FleetReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Failed to decode the fleet report: %v", err)
+	}
+	if report.GeneratedAt != "2024-05-01T12:00:00Z" {
+		t.Errorf("Unexpected generatedAt %q", report.GeneratedAt)
+	}
+	if len(report.Clusters) != 2 {
+		t.Fatalf("Expected 2 clusters, got %v", report.Clusters)
+	}
+	for i, kubeContext := range []string{"prod", "staging"} {
+		cluster := report.Clusters[i]
+		if cluster.Context != kubeContext || cluster.Directory != kubeContext || cluster.Error == "" {
+			t.Errorf("Unexpected report of cluster %s: %+v", kubeContext, cluster)
+		}
+		if !strings.Contains(out.String(), kubeContext+": failed: ") {
+			t.Errorf("Expected the failure of cluster %s in the output, got %q", kubeContext, out.String())
+		}
+	}
+}
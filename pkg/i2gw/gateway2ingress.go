@@ -0,0 +1,366 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/printers"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// ReverseOptions configures where ConvertToIngresses reads Gateways and
+// HTTPRoutes from. Objects from all configured sources are converted
+// together.
+type ReverseOptions struct {
+	// Client, if set, is used to list Gateways and HTTPRoutes from a
+	// cluster.
+	Client client.Client
+	// InputFile, if set, is the path of a manifest file to read Gateways
+	// and HTTPRoutes from.
+	InputFile  string
+	Gateways   []gatewayv1.Gateway
+	HTTPRoutes []gatewayv1.HTTPRoute
+}
+
+// ConvertToIngresses converts simple HTTPRoutes, and the TLS configuration
+// of their Gateways, back to Ingresses. Parts of the routes that Ingress
+// can't express, such as header matches, filters or weighted backends, are
+// reported as errors and the rules using them are dropped. An error is only
+// returned when the input can't be read.
+func ConvertToIngresses(ctx context.Context, opts ReverseOptions) ([]networkingv1.Ingress, Report, error) {
+	gateways := opts.Gateways
+	httpRoutes := opts.HTTPRoutes
+
+	if opts.InputFile != "" {
+		f, err := os.Open(opts.InputFile)
+		if err != nil {
+			return nil, Report{}, fmt.Errorf("failed to open input file: %w", err)
+		}
+		defer f.Close()
+		fileGateways, fileHTTPRoutes, err := decodeGatewayInput(f)
+		if err != nil {
+			return nil, Report{}, fmt.Errorf("failed to read input from %s: %w", opts.InputFile, err)
+		}
+		gateways = append(gateways, fileGateways...)
+		httpRoutes = append(httpRoutes, fileHTTPRoutes...)
+	}
+
+	if opts.Client != nil {
+		gatewayList := &gatewayv1.GatewayList{}
+		if err := opts.Client.List(ctx, gatewayList); err != nil {
+			return nil, Report{}, fmt.Errorf("failed to list gateways: %w", err)
+		}
+		gateways = append(gateways, gatewayList.Items...)
+
+		httpRouteList := &gatewayv1.HTTPRouteList{}
+		if err := opts.Client.List(ctx, httpRouteList); err != nil {
+			return nil, Report{}, fmt.Errorf("failed to list httproutes: %w", err)
+		}
+		httpRoutes = append(httpRoutes, httpRouteList.Items...)
+	}
+
+	ingresses, errors := httpRoutesToIngresses(gateways, httpRoutes)
+	return ingresses, Report{Errors: errors}, nil
+}
+
+func decodeGatewayInput(r io.Reader) ([]gatewayv1.Gateway, []gatewayv1.HTTPRoute, error) {
+	var gateways []gatewayv1.Gateway
+	var httpRoutes []gatewayv1.HTTPRoute
+	err := decodeObjects(r, func(obj unstructured.Unstructured) error {
+		gvk := obj.GroupVersionKind()
+		if gvk.Group != gatewayv1.GroupName {
+			return nil
+		}
+		// v1alpha2 and v1beta1 Gateways and HTTPRoutes have the same schema
+		// as v1 ones.
+		switch gvk.Kind {
+		case "Gateway":
+			gateway := gatewayv1.Gateway{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &gateway); err != nil {
+				return fmt.Errorf("failed to decode Gateway %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+			}
+			gateways = append(gateways, gateway)
+		case "HTTPRoute":
+			httpRoute := gatewayv1.HTTPRoute{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &httpRoute); err != nil {
+				return fmt.Errorf("failed to decode HTTPRoute %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+			}
+			httpRoutes = append(httpRoutes, httpRoute)
+		}
+		return nil
+	})
+	return gateways, httpRoutes, err
+}
+
+func httpRoutesToIngresses(gateways []gatewayv1.Gateway, httpRoutes []gatewayv1.HTTPRoute) ([]networkingv1.Ingress, []error) {
+	gatewaysByKey := map[string]gatewayv1.Gateway{}
+	for _, gw := range gateways {
+		gatewaysByKey[fmt.Sprintf("%s/%s", gw.Namespace, gw.Name)] = gw
+	}
+
+	var ingresses []networkingv1.Ingress
+	var errors []error
+	for _, route := range httpRoutes {
+		ingress, routeErrors := httpRouteToIngress(route, gatewaysByKey)
+		errors = append(errors, routeErrors...)
+		if ingress != nil {
+			ingresses = append(ingresses, *ingress)
+		}
+	}
+	sort.SliceStable(ingresses, func(i, j int) bool {
+		if ingresses[i].Namespace != ingresses[j].Namespace {
+			return ingresses[i].Namespace < ingresses[j].Namespace
+		}
+		return ingresses[i].Name < ingresses[j].Name
+	})
+	return ingresses, errors
+}
+
+func httpRouteToIngress(route gatewayv1.HTTPRoute, gatewaysByKey map[string]gatewayv1.Gateway) (*networkingv1.Ingress, []error) {
+	var errors []error
+	routeErrorf := func(format string, args ...interface{}) {
+		errors = append(errors, fmt.Errorf("HTTPRoute %s/%s: %s", route.Namespace, route.Name, fmt.Sprintf(format, args...)))
+	}
+
+	if len(route.Spec.ParentRefs) != 1 {
+		routeErrorf("only HTTPRoutes with a single parentRef can be converted, found %d", len(route.Spec.ParentRefs))
+		return nil, errors
+	}
+	parentRef := route.Spec.ParentRefs[0]
+	gwNamespace := route.Namespace
+	if parentRef.Namespace != nil {
+		gwNamespace = string(*parentRef.Namespace)
+	}
+	if gwNamespace != route.Namespace {
+		routeErrorf("parent Gateway %s/%s is in another namespace", gwNamespace, parentRef.Name)
+		return nil, errors
+	}
+	gateway, gatewayFound := gatewaysByKey[fmt.Sprintf("%s/%s", gwNamespace, parentRef.Name)]
+	ingressClass := string(parentRef.Name)
+	if gatewayFound {
+		ingressClass = string(gateway.Spec.GatewayClassName)
+	}
+
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: route.Namespace,
+			Name:      route.Name,
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: &ingressClass,
+		},
+	}
+	ingress.SetGroupVersionKind(networkingv1.SchemeGroupVersion.WithKind("Ingress"))
+
+	var paths []networkingv1.HTTPIngressPath
+	for i, rule := range route.Spec.Rules {
+		if len(rule.Filters) > 0 {
+			routeErrorf("rule %d has filters, which Ingress can't express", i)
+			continue
+		}
+		if len(rule.BackendRefs) != 1 {
+			routeErrorf("rule %d has %d backendRefs, Ingress rules have exactly one backend", i, len(rule.BackendRefs))
+			continue
+		}
+		backend, err := toIngressBackend(route.Namespace, rule.BackendRefs[0])
+		if err != nil {
+			routeErrorf("rule %d: %v", i, err)
+			continue
+		}
+		if len(rule.Matches) == 0 {
+			if len(route.Spec.Hostnames) == 0 {
+				ingress.Spec.DefaultBackend = backend
+				continue
+			}
+			rule.Matches = []gatewayv1.HTTPRouteMatch{{}}
+		}
+		for _, match := range rule.Matches {
+			path, err := toIngressPath(match, *backend)
+			if err != nil {
+				routeErrorf("rule %d: %v", i, err)
+				continue
+			}
+			paths = append(paths, *path)
+		}
+	}
+
+	if len(paths) > 0 {
+		var hosts []string
+		for _, hostname := range route.Spec.Hostnames {
+			hosts = append(hosts, string(hostname))
+		}
+		if len(hosts) == 0 {
+			hosts = []string{""}
+		}
+		for _, host := range hosts {
+			ingress.Spec.Rules = append(ingress.Spec.Rules, networkingv1.IngressRule{
+				Host: host,
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{Paths: paths},
+				},
+			})
+		}
+	}
+
+	if gatewayFound {
+		ingress.Spec.TLS = ingressTLSForHosts(gateway, route.Spec.Hostnames)
+	}
+
+	if len(ingress.Spec.Rules) == 0 && ingress.Spec.DefaultBackend == nil {
+		return nil, errors
+	}
+	return ingress, errors
+}
+
+func toIngressBackend(namespace string, br gatewayv1.HTTPBackendRef) (*networkingv1.IngressBackend, error) {
+	if len(br.Filters) > 0 {
+		return nil, fmt.Errorf("backendRef %s has filters, which Ingress can't express", br.Name)
+	}
+	if br.Namespace != nil && string(*br.Namespace) != namespace {
+		return nil, fmt.Errorf("backendRef %s is in namespace %s, Ingress backends must be in the same namespace", br.Name, *br.Namespace)
+	}
+	isService := (br.Group == nil || *br.Group == "") && (br.Kind == nil || *br.Kind == "Service")
+	if !isService {
+		var group string
+		if br.Group != nil {
+			group = string(*br.Group)
+		}
+		var kind string
+		if br.Kind != nil {
+			kind = string(*br.Kind)
+		}
+		return &networkingv1.IngressBackend{
+			Resource: &corev1.TypedLocalObjectReference{APIGroup: &group, Kind: kind, Name: string(br.Name)},
+		}, nil
+	}
+	if br.Port == nil {
+		return nil, fmt.Errorf("backendRef %s has no port", br.Name)
+	}
+	return &networkingv1.IngressBackend{
+		Service: &networkingv1.IngressServiceBackend{
+			Name: string(br.Name),
+			Port: networkingv1.ServiceBackendPort{Number: int32(*br.Port)},
+		},
+	}, nil
+}
+
+func toIngressPath(match gatewayv1.HTTPRouteMatch, backend networkingv1.IngressBackend) (*networkingv1.HTTPIngressPath, error) {
+	if len(match.Headers) > 0 || len(match.QueryParams) > 0 || match.Method != nil {
+		return nil, fmt.Errorf("header, query parameter and method matches can't be expressed by Ingress")
+	}
+	pathType := networkingv1.PathTypePrefix
+	path := "/"
+	if match.Path != nil {
+		if match.Path.Value != nil {
+			path = *match.Path.Value
+		}
+		if match.Path.Type != nil {
+			switch *match.Path.Type {
+			case gatewayv1.PathMatchPathPrefix:
+			case gatewayv1.PathMatchExact:
+				pathType = networkingv1.PathTypeExact
+			default:
+				return nil, fmt.Errorf("unsupported path match type %s", *match.Path.Type)
+			}
+		}
+	}
+	return &networkingv1.HTTPIngressPath{
+		Path:     path,
+		PathType: &pathType,
+		Backend:  backend,
+	}, nil
+}
+
+// ingressTLSForHosts returns the TLS configuration of the HTTPS listeners of
+// the Gateway serving the given hostnames.
+func ingressTLSForHosts(gateway gatewayv1.Gateway, hostnames []gatewayv1.Hostname) []networkingv1.IngressTLS {
+	var tls []networkingv1.IngressTLS
+	for _, listener := range gateway.Spec.Listeners {
+		if listener.Protocol != gatewayv1.HTTPSProtocolType || listener.TLS == nil || listener.Hostname == nil {
+			continue
+		}
+		served := false
+		for _, hostname := range hostnames {
+			if hostname == *listener.Hostname {
+				served = true
+			}
+		}
+		if !served {
+			continue
+		}
+		for _, ref := range listener.TLS.CertificateRefs {
+			if ref.Namespace != nil && string(*ref.Namespace) != gateway.Namespace {
+				continue
+			}
+			tls = append(tls, networkingv1.IngressTLS{
+				Hosts:      []string{string(*listener.Hostname)},
+				SecretName: string(ref.Name),
+			})
+		}
+	}
+	return tls
+}
+
+// RunReverse converts the Gateways and HTTPRoutes read from inputFile, or
+// from the cluster if it is empty, to Ingresses and prints them as YAML.
+func RunReverse(inputFile string) {
+	opts := ReverseOptions{InputFile: inputFile}
+	if inputFile == "" {
+		scheme := runtime.NewScheme()
+		if err := clientgoscheme.AddToScheme(scheme); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := gatewayv1.AddToScheme(scheme); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		cl, err := newClient("", client.Options{Scheme: scheme})
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		opts.Client = cl
+	}
+
+	ctx, cancel := runContext(0)
+	defer cancel()
+	ingresses, report, err := ConvertToIngresses(ctx, opts)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	WriteResult(os.Stdout, Resources{}, report)
+	y := printers.YAMLPrinter{}
+	for _, ingress := range ingresses {
+		if err := y.PrintObj(&ingress, os.Stdout); err != nil {
+			fmt.Printf("# Error printing YAML for %s Ingress: %v\n", ingress.Name, err)
+		}
+	}
+}
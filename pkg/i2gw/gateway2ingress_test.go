@@ -0,0 +1,114 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_ConvertToIngresses_roundTrip(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	iExact := networkingv1.PathTypeExact
+
+	original := networkingv1.Ingress{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "Ingress"},
+		ObjectMeta: metav1.ObjectMeta{Name: "example-com", Namespace: "test"},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: stringPtr("nginx"),
+			TLS:              []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-cert"}},
+			Rules: []networkingv1.IngressRule{{
+				Host: "example.com",
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{
+							{Path: "/exact", PathType: &iExact, Backend: serviceBackend("exact", 8080)},
+							{Path: "/", PathType: &iPrefix, Backend: serviceBackend("root", 80)},
+						},
+					},
+				},
+			}},
+		},
+	}
+
+	// Ingress can't express the HSTS header and HTTPS redirect that
+	// ingress-nginx adds by default.
+	controllerConfig := unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "ingress-nginx-controller", "namespace": "ingress-nginx"},
+		"data":       map[string]interface{}{"hsts": "false", "ssl-redirect": "false"},
+	}}
+
+	resources, report, err := Convert(context.Background(), ConvertOptions{
+		Ingresses: []networkingv1.Ingress{original},
+		Objects:   []unstructured.Unstructured{controllerConfig},
+	})
+	if err != nil || len(report.Errors) != 0 {
+		t.Fatalf("Unexpected forward conversion errors: %v %v", err, report.Errors)
+	}
+
+	ingresses, report, err := ConvertToIngresses(context.Background(), ReverseOptions{
+		Gateways:   resources.Gateways,
+		HTTPRoutes: resources.HTTPRoutes,
+	})
+	if err != nil || len(report.Errors) != 0 {
+		t.Fatalf("Unexpected reverse conversion errors: %v %v", err, report.Errors)
+	}
+	if len(ingresses) != 1 {
+		t.Fatalf("Expected 1 Ingress, got %d", len(ingresses))
+	}
+	if !apiequality.Semantic.DeepEqual(original, ingresses[0]) {
+		t.Errorf("Round trip changed the Ingress, diff (-want +got): %s", cmp.Diff(original, ingresses[0]))
+	}
+}
+
+func Test_ConvertToIngresses_unsupported(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	primary := ingressWithPath("primary", "/", &iPrefix, serviceBackend("primary", 80), nil)
+	canary := ingressWithPath("canary", "/", &iPrefix, serviceBackend("canary", 80), map[string]string{
+		"nginx.ingress.kubernetes.io/canary":           "true",
+		"nginx.ingress.kubernetes.io/canary-by-header": "X-Canary",
+		"nginx.ingress.kubernetes.io/canary-weight":    "20",
+	})
+
+	resources, _, err := Convert(context.Background(), ConvertOptions{Ingresses: []networkingv1.Ingress{primary, canary}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ingresses, report, err := ConvertToIngresses(context.Background(), ReverseOptions{
+		Gateways:   resources.Gateways,
+		HTTPRoutes: resources.HTTPRoutes,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// Neither the two header matches nor the weighted backends can be
+	// expressed.
+	if len(report.Errors) != 3 {
+		t.Errorf("Expected 3 errors, got %v", report.Errors)
+	}
+	if len(ingresses) != 0 {
+		t.Errorf("Expected no Ingresses, got %+v", ingresses)
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+var gatewayClassGVK = schema.GroupVersionKind{
+	Group:   "gateway.networking.k8s.io",
+	Version: "v1",
+	Kind:    "GatewayClass",
+}
+
+// gatewayControllerRegexp is the pattern of the controllerName of
+// GatewayClasses, a domain-prefixed path.
+var gatewayControllerRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*\/[A-Za-z0-9\/\-._~%!$&'()*+,;=:]+$`)
+
+func validateGatewayClassController(controller string) error {
+	if controller == "" {
+		return nil
+	}
+	if len(controller) > 253 || !gatewayControllerRegexp.MatchString(controller) {
+		return fmt.Errorf("invalid GatewayClass controller name %q, it must be a domain-prefixed path such as example.net/gateway-controller", controller)
+	}
+	return nil
+}
+
+// gatewayClassesFor checks that the GatewayClasses of the Gateways are among
+// the existing ones, when opts.CheckGatewayClasses is set, and generates the
+// missing ones for opts.GatewayClassController. Without a controller, every
+// missing GatewayClass is an error.
+func gatewayClassesFor(gateways []gatewayv1.Gateway, opts ConvertOptions) ([]gatewayv1.GatewayClass, []Notification, []error) {
+	if !opts.CheckGatewayClasses && opts.GatewayClassController == "" {
+		return nil, nil, nil
+	}
+	existing := map[string]bool{}
+	if opts.CheckGatewayClasses {
+		for _, name := range opts.GatewayClasses {
+			existing[name] = true
+		}
+	}
+
+	var gatewayClasses []gatewayv1.GatewayClass
+	var notes []Notification
+	var errors []error
+	missing := map[string]bool{}
+	for _, gw := range gateways {
+		name := string(gw.Spec.GatewayClassName)
+		if name == "" || existing[name] || missing[name] {
+			continue
+		}
+		missing[name] = true
+		if opts.GatewayClassController == "" {
+			errors = append(errors, fmt.Errorf("GatewayClass %s of Gateway %s/%s doesn't exist in the target cluster, create it or set --gatewayclass-controller to generate it", name, gw.Namespace, gw.Name))
+			continue
+		}
+		gatewayClass := gatewayv1.GatewayClass{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: gatewayv1.GatewayClassSpec{
+				ControllerName: gatewayv1.GatewayController(opts.GatewayClassController),
+			},
+		}
+		gatewayClass.SetGroupVersionKind(gatewayClassGVK)
+		gatewayClasses = append(gatewayClasses, gatewayClass)
+		if opts.CheckGatewayClasses {
+			notes = append(notes, notifications.NewInfo("GatewayClass %s doesn't exist in the target cluster, it is generated for controller %s", name, opts.GatewayClassController))
+		}
+	}
+	return gatewayClasses, notes, errors
+}
+
+// targetGatewayClasses lists the names of the GatewayClasses of the cluster
+// the output of the command line is applied to.
+func targetGatewayClasses(ctx context.Context, runOpts RunOptions) ([]string, error) {
+	cl, err := newClient(targetKubeContext(runOpts), client.Options{})
+	if err != nil {
+		return nil, err
+	}
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gatewayClassGVK.GroupVersion().WithKind(gatewayClassGVK.Kind + "List"))
+	if err := cl.List(ctx, list); err != nil {
+		return nil, fmt.Errorf("failed to list GatewayClasses: %w", err)
+	}
+	var names []string
+	for _, gatewayClass := range list.Items {
+		names = append(names, gatewayClass.GetName())
+	}
+	return names, nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_gatewayClassesFor(t *testing.T) {
+	gateways := []gatewayv1.Gateway{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "nginx"}, Spec: gatewayv1.GatewaySpec{GatewayClassName: "nginx"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "api", Name: "nginx"}, Spec: gatewayv1.GatewaySpec{GatewayClassName: "nginx"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "internal"}, Spec: gatewayv1.GatewaySpec{GatewayClassName: "internal"}},
+	}
+	gatewayClass := func(name string) gatewayv1.GatewayClass {
+		gc := gatewayv1.GatewayClass{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       gatewayv1.GatewayClassSpec{ControllerName: "example.net/gateway-controller"},
+		}
+		gc.SetGroupVersionKind(gatewayClassGVK)
+		return gc
+	}
+
+	testCases := []struct {
+		name               string
+		opts               ConvertOptions
+		wantGatewayClasses []gatewayv1.GatewayClass
+		wantNotes          []Notification
+		wantErrors         []error
+	}{
+		{
+			name: "not checked",
+		},
+		{
+			name: "every GatewayClass exists",
+			opts: ConvertOptions{CheckGatewayClasses: true, GatewayClasses: []string{"internal", "nginx"}},
+		},
+		{
+			name: "missing GatewayClass",
+			opts: ConvertOptions{CheckGatewayClasses: true, GatewayClasses: []string{"nginx"}},
+			wantErrors: []error{
+				errors.New("GatewayClass internal of Gateway web/internal doesn't exist in the target cluster, create it or set --gatewayclass-controller to generate it"),
+			},
+		},
+		{
+			name:               "missing GatewayClass generated",
+			opts:               ConvertOptions{CheckGatewayClasses: true, GatewayClasses: []string{"nginx"}, GatewayClassController: "example.net/gateway-controller"},
+			wantGatewayClasses: []gatewayv1.GatewayClass{gatewayClass("internal")},
+			wantNotes: []Notification{
+				notifications.NewInfo("GatewayClass internal doesn't exist in the target cluster, it is generated for controller example.net/gateway-controller"),
+			},
+		},
+		{
+			name:               "every GatewayClass generated",
+			opts:               ConvertOptions{GatewayClassController: "example.net/gateway-controller"},
+			wantGatewayClasses: []gatewayv1.GatewayClass{gatewayClass("nginx"), gatewayClass("internal")},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gatewayClasses, notes, errs := gatewayClassesFor(gateways, tc.opts)
+			if diff := cmp.Diff(tc.wantGatewayClasses, gatewayClasses); diff != "" {
+				t.Errorf("Unexpected GatewayClasses (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantNotes, notes); diff != "" {
+				t.Errorf("Unexpected notifications (-want +got):\n%s", diff)
+			}
+			var gotErrors []string
+			for _, err := range errs {
+				gotErrors = append(gotErrors, err.Error())
+			}
+			var wantErrors []string
+			for _, err := range tc.wantErrors {
+				wantErrors = append(wantErrors, err.Error())
+			}
+			if diff := cmp.Diff(wantErrors, gotErrors); diff != "" {
+				t.Errorf("Unexpected errors (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_validateGatewayClassController(t *testing.T) {
+	for _, controller := range []string{"", "example.net/gateway-controller", "gateway.envoyproxy.io/gatewayclass-controller"} {
+		if err := validateGatewayClassController(controller); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", controller, err)
+		}
+	}
+	for _, controller := range []string{"gateway-controller", "Example.net/controller", "example.net/"} {
+		if err := validateGatewayClassController(controller); err == nil {
+			t.Errorf("Expected %q to be invalid", controller)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	apimachineryvalidation "k8s.io/apimachinery/pkg/util/validation"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// maxInfrastructureEntries is the largest number of labels, and of
+// annotations, the spec.infrastructure of a Gateway accepts.
+const maxInfrastructureEntries = 8
+
+// validateGatewayMetadata checks that the labels and annotations of the
+// Gateways are valid, and fit in their spec.infrastructure if they are
+// set there too.
+func validateGatewayMetadata(labels, annotations map[string]string, infrastructure bool) error {
+	for _, key := range sortedKeys(labels) {
+		if errs := apimachineryvalidation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid Gateway label %q: %s", key, strings.Join(errs, ", "))
+		}
+		if errs := apimachineryvalidation.IsValidLabelValue(labels[key]); len(errs) > 0 {
+			return fmt.Errorf("invalid value %q of Gateway label %s: %s", labels[key], key, strings.Join(errs, ", "))
+		}
+	}
+	for _, key := range sortedKeys(annotations) {
+		if errs := apimachineryvalidation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid Gateway annotation %q: %s", key, strings.Join(errs, ", "))
+		}
+	}
+	if infrastructure && (len(labels) > maxInfrastructureEntries || len(annotations) > maxInfrastructureEntries) {
+		return fmt.Errorf("the infrastructure of Gateways has at most %d labels and %d annotations", maxInfrastructureEntries, maxInfrastructureEntries)
+	}
+	return nil
+}
+
+// setGatewayMetadata sets the labels and annotations on the Gateways, and in
+// their spec.infrastructure, which implementations set on the resources
+// they provision for Gateways, when infrastructure is set. Annotations
+// converted from Ingresses with another value are replaced and reported.
+func setGatewayMetadata(gateways []gatewayv1.Gateway, labels, annotations map[string]string, infrastructure bool) []Notification {
+	if len(labels) == 0 && len(annotations) == 0 {
+		return nil
+	}
+	var notes []Notification
+	for i := range gateways {
+		gw := &gateways[i]
+		for key, value := range labels {
+			if gw.Labels == nil {
+				gw.Labels = map[string]string{}
+			}
+			gw.Labels[key] = value
+		}
+		for _, key := range sortedKeys(annotations) {
+			if value, ok := gw.Annotations[key]; ok && value != annotations[key] {
+				notes = append(notes, notifications.NewWarning("Gateway %s/%s gets annotation %s=%q from its Ingresses, which the Gateway annotations replace with %q", gw.Namespace, gw.Name, key, value, annotations[key]))
+			}
+			if gw.Annotations == nil {
+				gw.Annotations = map[string]string{}
+			}
+			gw.Annotations[key] = annotations[key]
+		}
+		if !infrastructure {
+			continue
+		}
+		if gw.Spec.Infrastructure == nil {
+			gw.Spec.Infrastructure = &gatewayv1.GatewayInfrastructure{}
+		}
+		for key, value := range labels {
+			if gw.Spec.Infrastructure.Labels == nil {
+				gw.Spec.Infrastructure.Labels = map[gatewayv1.LabelKey]gatewayv1.LabelValue{}
+			}
+			gw.Spec.Infrastructure.Labels[gatewayv1.LabelKey(key)] = gatewayv1.LabelValue(value)
+		}
+		for key, value := range annotations {
+			if gw.Spec.Infrastructure.Annotations == nil {
+				gw.Spec.Infrastructure.Annotations = map[gatewayv1.AnnotationKey]gatewayv1.AnnotationValue{}
+			}
+			gw.Spec.Infrastructure.Annotations[gatewayv1.AnnotationKey(key)] = gatewayv1.AnnotationValue(value)
+		}
+	}
+	return notes
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,132 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_setGatewayMetadata(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	web := ingressWithPath("web", "/", &iPrefix, serviceBackend("web", 80), map[string]string{
+		"external-dns.alpha.kubernetes.io/target": "lb.example.com",
+	})
+	labels := map[string]string{"team": "web"}
+	annotations := map[string]string{
+		"external-dns.alpha.kubernetes.io/target": "gateway.example.com",
+		"example.com/firewall":                    "public",
+	}
+
+	testCases := []struct {
+		name               string
+		infrastructure     bool
+		wantInfrastructure *gatewayv1.GatewayInfrastructure
+	}{
+		{
+			name: "metadata",
+		},
+		{
+			name:           "metadata and infrastructure",
+			infrastructure: true,
+			wantInfrastructure: &gatewayv1.GatewayInfrastructure{
+				Labels: map[gatewayv1.LabelKey]gatewayv1.LabelValue{"team": "web"},
+				Annotations: map[gatewayv1.AnnotationKey]gatewayv1.AnnotationValue{
+					"external-dns.alpha.kubernetes.io/target": "gateway.example.com",
+					"example.com/firewall":                    "public",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resources, report := convertInput(inputResources{ingresses: []networkingv1.Ingress{web}}, ConvertOptions{
+				GatewayLabels:         labels,
+				GatewayAnnotations:    annotations,
+				GatewayInfrastructure: tc.infrastructure,
+			})
+			if len(resources.Gateways) != 1 {
+				t.Fatalf("expected a single Gateway, got %d", len(resources.Gateways))
+			}
+			gw := resources.Gateways[0]
+			if diff := cmp.Diff(labels, gw.Labels); diff != "" {
+				t.Errorf("unexpected labels (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(annotations, gw.Annotations); diff != "" {
+				t.Errorf("unexpected annotations (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantInfrastructure, gw.Spec.Infrastructure); diff != "" {
+				t.Errorf("unexpected infrastructure (-want +got):\n%s", diff)
+			}
+			var gotNotes []string
+			for _, n := range report.Notifications {
+				gotNotes = append(gotNotes, n.String())
+			}
+			wantNotes := []string{`WARNING: Gateway test/nginx gets annotation external-dns.alpha.kubernetes.io/target="lb.example.com" from its Ingresses, which the Gateway annotations replace with "gateway.example.com"`}
+			if diff := cmp.Diff(wantNotes, gotNotes); diff != "" {
+				t.Errorf("unexpected notifications (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_validateGatewayMetadata(t *testing.T) {
+	testCases := []struct {
+		name           string
+		labels         map[string]string
+		annotations    map[string]string
+		infrastructure bool
+		wantErr        string
+	}{
+		{
+			name:        "valid",
+			labels:      map[string]string{"example.com/team": "web"},
+			annotations: map[string]string{"example.com/description": "Public web Gateway"},
+		},
+		{
+			name:    "invalid label key",
+			labels:  map[string]string{"team name": "web"},
+			wantErr: `invalid Gateway label "team name": name part must consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  or '123-abc', regex used for validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]')`,
+		},
+		{
+			name:    "invalid label value",
+			labels:  map[string]string{"team": "web team"},
+			wantErr: `invalid value "web team" of Gateway label team: a valid label must be an empty string or consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyValue',  or 'my_value',  or '12345', regex used for validation is '(([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9])?')`,
+		},
+		{
+			name:           "too many infrastructure labels",
+			labels:         map[string]string{"a": "", "b": "", "c": "", "d": "", "e": "", "f": "", "g": "", "h": "", "i": ""},
+			infrastructure: true,
+			wantErr:        "the infrastructure of Gateways has at most 8 labels and 8 annotations",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotErr string
+			if err := validateGatewayMetadata(tc.labels, tc.annotations, tc.infrastructure); err != nil {
+				gotErr = err.Error()
+			}
+			if gotErr != tc.wantErr {
+				t.Errorf("expected error %q, got %q", tc.wantErr, gotErr)
+			}
+		})
+	}
+}
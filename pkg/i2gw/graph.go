@@ -0,0 +1,244 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// conversionGraph is the traffic layout of a conversion: Ingresses, the
+// Gateway listeners serving their hosts, the HTTPRoutes attached to these
+// listeners and the backends of the HTTPRoutes.
+type conversionGraph struct {
+	nodes []graphNode
+	ids   map[string]string
+	edges [][2]string
+	seen  map[[2]string]bool
+}
+
+type graphNode struct {
+	id    string
+	label string
+	// group is the label of the Gateway of listener nodes.
+	group string
+}
+
+func (g *conversionGraph) node(key, label, group string) string {
+	if id, ok := g.ids[key]; ok {
+		return id
+	}
+	id := fmt.Sprintf("n%d", len(g.nodes))
+	g.ids[key] = id
+	g.nodes = append(g.nodes, graphNode{id: id, label: label, group: group})
+	return id
+}
+
+func (g *conversionGraph) edge(from, to string) {
+	e := [2]string{from, to}
+	if !g.seen[e] {
+		g.seen[e] = true
+		g.edges = append(g.edges, e)
+	}
+}
+
+func buildConversionGraph(resources Resources) *conversionGraph {
+	g := &conversionGraph{ids: map[string]string{}, seen: map[[2]string]bool{}}
+	gateways := map[string]gatewayv1.Gateway{}
+	for _, gw := range resources.Gateways {
+		gateways[gw.Namespace+"/"+gw.Name] = gw
+		for _, listener := range gw.Spec.Listeners {
+			g.node(listenerKey(gw, listener.Name), listenerLabel(listener), fmt.Sprintf("Gateway %s/%s", gw.Namespace, gw.Name))
+		}
+	}
+
+	for _, route := range resources.HTTPRoutes {
+		routeID := g.node("HTTPRoute/"+route.Namespace+"/"+route.Name, fmt.Sprintf("HTTPRoute %s/%s", route.Namespace, route.Name), "")
+		var listenerIDs []string
+		for _, parentRef := range route.Spec.ParentRefs {
+			namespace := route.Namespace
+			if parentRef.Namespace != nil {
+				namespace = string(*parentRef.Namespace)
+			}
+			gw, ok := gateways[namespace+"/"+string(parentRef.Name)]
+			if !ok {
+				continue
+			}
+			for _, listener := range gw.Spec.Listeners {
+				if parentRef.SectionName != nil && *parentRef.SectionName != listener.Name {
+					continue
+				}
+				if parentRef.SectionName == nil && !listenerMatchesHostnames(listener.Hostname, route.Spec.Hostnames) {
+					continue
+				}
+				listenerID := g.ids[listenerKey(gw, listener.Name)]
+				listenerIDs = append(listenerIDs, listenerID)
+				g.edge(listenerID, routeID)
+			}
+		}
+		for _, source := range resources.Sources[ObjectRef{Kind: "HTTPRoute", Namespace: route.Namespace, Name: route.Name}] {
+			ingressID := g.node("Ingress/"+source.Ingress.String(), "Ingress "+source.Ingress.String(), "")
+			for _, listenerID := range listenerIDs {
+				g.edge(ingressID, listenerID)
+			}
+			if len(listenerIDs) == 0 {
+				g.edge(ingressID, routeID)
+			}
+		}
+		for _, rule := range route.Spec.Rules {
+			for _, backendRef := range rule.BackendRefs {
+				key, label := backendNode(route.Namespace, backendRef.BackendObjectReference)
+				g.edge(routeID, g.node(key, label, ""))
+			}
+		}
+	}
+	return g
+}
+
+func listenerKey(gw gatewayv1.Gateway, name gatewayv1.SectionName) string {
+	return "Listener/" + gw.Namespace + "/" + gw.Name + "/" + string(name)
+}
+
+func listenerLabel(listener gatewayv1.Listener) string {
+	hostname := "*"
+	if listener.Hostname != nil {
+		hostname = string(*listener.Hostname)
+	}
+	return fmt.Sprintf("%s %s %s:%d", listener.Name, listener.Protocol, hostname, listener.Port)
+}
+
+func backendNode(routeNamespace string, ref gatewayv1.BackendObjectReference) (string, string) {
+	kind := "Service"
+	if ref.Kind != nil && *ref.Kind != "" {
+		kind = string(*ref.Kind)
+	}
+	namespace := routeNamespace
+	if ref.Namespace != nil {
+		namespace = string(*ref.Namespace)
+	}
+	label := fmt.Sprintf("%s %s/%s", kind, namespace, ref.Name)
+	if ref.Port != nil {
+		label += fmt.Sprintf(":%d", *ref.Port)
+	}
+	return kind + "/" + label, label
+}
+
+// listenerMatchesHostnames tells whether a listener serves any of the
+// hostnames of an HTTPRoute, or any hostname if the route has none.
+func listenerMatchesHostnames(listenerHostname *gatewayv1.Hostname, hostnames []gatewayv1.Hostname) bool {
+	if listenerHostname == nil || len(hostnames) == 0 {
+		return true
+	}
+	for _, hostname := range hostnames {
+		if hostname == *listenerHostname {
+			return true
+		}
+		if suffix, ok := strings.CutPrefix(string(*listenerHostname), "*"); ok && strings.HasSuffix(string(hostname), suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeMermaid writes the graph as a Mermaid flowchart, with the listeners
+// of every Gateway in a subgraph.
+func (g *conversionGraph) writeMermaid(w io.Writer) {
+	escape := strings.NewReplacer(`"`, "#quot;").Replace
+	fmt.Fprintln(w, "flowchart LR")
+	g.writeGroups(func(group string, nodes []graphNode) {
+		indent := "  "
+		if group != "" {
+			fmt.Fprintf(w, "  subgraph %s[\"%s\"]\n", nodes[0].id+"g", escape(group))
+			indent = "    "
+		}
+		for _, n := range nodes {
+			fmt.Fprintf(w, "%s%s[\"%s\"]\n", indent, n.id, escape(n.label))
+		}
+		if group != "" {
+			fmt.Fprintln(w, "  end")
+		}
+	})
+	for _, e := range g.edges {
+		fmt.Fprintf(w, "  %s --> %s\n", e[0], e[1])
+	}
+}
+
+// writeDOT writes the graph in the Graphviz DOT language, with the
+// listeners of every Gateway in a cluster.
+func (g *conversionGraph) writeDOT(w io.Writer) {
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace
+	fmt.Fprintln(w, "digraph conversion {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	g.writeGroups(func(group string, nodes []graphNode) {
+		indent := "  "
+		if group != "" {
+			fmt.Fprintf(w, "  subgraph cluster_%s {\n    label=\"%s\";\n", nodes[0].id, escape(group))
+			indent = "    "
+		}
+		for _, n := range nodes {
+			fmt.Fprintf(w, "%s%s [label=\"%s\"];\n", indent, n.id, escape(n.label))
+		}
+		if group != "" {
+			fmt.Fprintln(w, "  }")
+		}
+	})
+	for _, e := range g.edges {
+		fmt.Fprintf(w, "  %s -> %s;\n", e[0], e[1])
+	}
+	fmt.Fprintln(w, "}")
+}
+
+// writeGroups calls fn with the nodes of every group, in the order of their
+// first node, ungrouped nodes last.
+func (g *conversionGraph) writeGroups(fn func(group string, nodes []graphNode)) {
+	var groups []string
+	byGroup := map[string][]graphNode{}
+	for _, n := range g.nodes {
+		if _, ok := byGroup[n.group]; !ok && n.group != "" {
+			groups = append(groups, n.group)
+		}
+		byGroup[n.group] = append(byGroup[n.group], n)
+	}
+	for _, group := range groups {
+		fn(group, byGroup[group])
+	}
+	if nodes := byGroup[""]; len(nodes) > 0 {
+		fn("", nodes)
+	}
+}
+
+// WriteGraph writes the traffic layout of the resources, from Ingresses to
+// the Gateway listeners serving their hosts, the HTTPRoutes attached to
+// them and their backends, in the OutputMermaid or OutputDOT format.
+func WriteGraph(w io.Writer, resources Resources, format OutputFormat) error {
+	g := buildConversionGraph(resources)
+	switch format {
+	case OutputMermaid:
+		g.writeMermaid(w)
+	case OutputDOT:
+		g.writeDOT(w)
+	default:
+		return fmt.Errorf("output format %q is not a graph format", format)
+	}
+	return nil
+}
+
+func isGraphFormat(format OutputFormat) bool {
+	return format == OutputMermaid || format == OutputDOT
+}
@@ -0,0 +1,102 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_WriteGraph(t *testing.T) {
+	resources := Resources{
+		Gateways: []gatewayv1.Gateway{{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nginx"},
+			Spec: gatewayv1.GatewaySpec{Listeners: []gatewayv1.Listener{
+				{Name: "example-com-http", Hostname: ptrTo(gatewayv1.Hostname("example.com")), Port: 80, Protocol: gatewayv1.HTTPProtocolType},
+				{Name: "other-com-http", Hostname: ptrTo(gatewayv1.Hostname("other.com")), Port: 80, Protocol: gatewayv1.HTTPProtocolType},
+			}},
+		}},
+		HTTPRoutes: []gatewayv1.HTTPRoute{{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example-com"},
+			Spec: gatewayv1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1.CommonRouteSpec{ParentRefs: []gatewayv1.ParentReference{{Name: "nginx"}}},
+				Hostnames:       []gatewayv1.Hostname{"example.com"},
+				Rules: []gatewayv1.HTTPRouteRule{{
+					BackendRefs: []gatewayv1.HTTPBackendRef{{BackendRef: gatewayv1.BackendRef{BackendObjectReference: gatewayv1.BackendObjectReference{Name: "web", Port: ptrTo(gatewayv1.PortNumber(80))}}}},
+				}},
+			},
+		}},
+		Sources: map[ObjectRef][]IngressSource{
+			{Kind: "HTTPRoute", Namespace: "default", Name: "example-com"}: {{Ingress: types.NamespacedName{Namespace: "default", Name: "web"}}},
+		},
+	}
+
+	testCases := []struct {
+		format   OutputFormat
+		expected string
+	}{
+		{
+			format: OutputMermaid,
+			expected: `flowchart LR
+  subgraph n0g["Gateway default/nginx"]
+    n0["example-com-http HTTP example.com:80"]
+    n1["other-com-http HTTP other.com:80"]
+  end
+  n2["HTTPRoute default/example-com"]
+  n3["Ingress default/web"]
+  n4["Service default/web:80"]
+  n0 --> n2
+  n3 --> n0
+  n2 --> n4
+`,
+		},
+		{
+			format: OutputDOT,
+			expected: `digraph conversion {
+  rankdir=LR;
+  subgraph cluster_n0 {
+    label="Gateway default/nginx";
+    n0 [label="example-com-http HTTP example.com:80"];
+    n1 [label="other-com-http HTTP other.com:80"];
+  }
+  n2 [label="HTTPRoute default/example-com"];
+  n3 [label="Ingress default/web"];
+  n4 [label="Service default/web:80"];
+  n0 -> n2;
+  n3 -> n0;
+  n2 -> n4;
+}
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteGraph(&buf, resources, tc.format); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, buf.String()); diff != "" {
+				t.Errorf("Unexpected graph, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// helmReleaseSecretType is the type of the Secrets Helm stores its
+	// releases in.
+	helmReleaseSecretType = "helm.sh/release.v1"
+	// helmDeployedStatus is the status of the current revision of a Helm
+	// release.
+	helmDeployedStatus = "deployed"
+)
+
+// helmRelease is the part of a Helm release record read for the conversion.
+type helmRelease struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Manifest  string `json:"manifest"`
+	Info      struct {
+		Status string `json:"status"`
+	} `json:"info"`
+}
+
+// helmReleaseData returns the encoded release of obj if it is a Secret or a
+// ConfigMap of the Helm release storage.
+func helmReleaseData(obj unstructured.Unstructured) (string, bool, error) {
+	if obj.GetAPIVersion() != "v1" || obj.GetLabels()["owner"] != "helm" {
+		return "", false, nil
+	}
+	data, found, _ := unstructured.NestedString(obj.Object, "data", "release")
+	if !found {
+		return "", false, nil
+	}
+	switch obj.GetKind() {
+	case "Secret":
+		if t, _, _ := unstructured.NestedString(obj.Object, "type"); t != helmReleaseSecretType {
+			return "", false, nil
+		}
+		decoded, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return "", true, err
+		}
+		return string(decoded), true, nil
+	case "ConfigMap":
+		return data, true, nil
+	}
+	return "", false, nil
+}
+
+// decodeHelmRelease decodes a release the way Helm encodes it: gzipped JSON,
+// base64 encoded.
+func decodeHelmRelease(data string) (helmRelease, error) {
+	release := helmRelease{}
+	b, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return release, err
+	}
+	if bytes.HasPrefix(b, []byte{0x1f, 0x8b}) {
+		r, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return release, err
+		}
+		defer r.Close()
+		if b, err = io.ReadAll(r); err != nil {
+			return release, err
+		}
+	}
+	return release, json.Unmarshal(b, &release)
+}
+
+// helmReleaseObjects returns the objects of the manifest of obj if it is a
+// Helm release Secret or ConfigMap, in the namespace of the release unless
+// they set theirs. Releases that aren't deployed, such as the superseded
+// revisions, have no objects.
+func helmReleaseObjects(obj unstructured.Unstructured) ([]unstructured.Unstructured, bool, error) {
+	data, isRelease, err := helmReleaseData(obj)
+	if !isRelease {
+		return nil, false, nil
+	}
+	var release helmRelease
+	if err == nil {
+		release, err = decodeHelmRelease(data)
+	}
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to decode Helm release %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+	if release.Info.Status != helmDeployedStatus {
+		return nil, true, nil
+	}
+	namespace := release.Namespace
+	if namespace == "" {
+		namespace = obj.GetNamespace()
+	}
+	objects, err := decodeManifestObjects(strings.NewReader(release.Manifest), namespace)
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to decode the manifest of Helm release %s/%s: %w", namespace, release.Name, err)
+	}
+	return objects, true, nil
+}
+
+// decodeManifestObjects decodes the objects of a Helm manifest, setting the
+// namespace of the namespaced objects that don't set theirs, as Helm does
+// when it installs them.
+func decodeManifestObjects(r io.Reader, namespace string) ([]unstructured.Unstructured, error) {
+	var objects []unstructured.Unstructured
+	err := decodeObjects(r, func(obj unstructured.Unstructured) error {
+		if obj.GetNamespace() == "" && obj.GetKind() != "IngressClass" {
+			obj.SetNamespace(namespace)
+		}
+		objects = append(objects, obj)
+		return nil
+	})
+	return objects, err
+}
+
+// HelmChartOptions configures the rendering of a Helm chart with
+// helm template.
+type HelmChartOptions struct {
+	// Chart is the chart reference passed to helm template: a path, a URL
+	// or a repository/name reference.
+	Chart string
+	// Release is the name of the release, release-name if empty as for
+	// helm template.
+	Release string
+	// Namespace is the namespace of the release, set on the namespaced
+	// objects that don't set theirs. "default" if empty.
+	Namespace string
+	// ValuesFiles are the values files of the release, in order of
+	// precedence.
+	ValuesFiles []string
+	// Values are key=value overrides of the values, as for --set.
+	Values []string
+}
+
+// RenderHelmChart renders the chart of opts with the helm binary of the
+// PATH and returns the objects of the rendered manifest.
+func RenderHelmChart(ctx context.Context, opts HelmChartOptions) ([]unstructured.Unstructured, error) {
+	release := opts.Release
+	if release == "" {
+		release = "release-name"
+	}
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	args := []string{"template", release, opts.Chart, "--namespace", namespace}
+	for _, f := range opts.ValuesFiles {
+		args = append(args, "--values", f)
+	}
+	for _, v := range opts.Values {
+		args = append(args, "--set", v)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "helm", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to render Helm chart %s: %w: %s", opts.Chart, err, strings.TrimSpace(stderr.String()))
+	}
+	objects, err := decodeManifestObjects(&stdout, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode Helm chart %s: %w", opts.Chart, err)
+	}
+	return objects, nil
+}
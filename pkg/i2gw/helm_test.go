@@ -0,0 +1,175 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// encodeHelmRelease encodes a release as Helm stores it in ConfigMaps, base64
+// encoded once more in Secrets.
+func encodeHelmRelease(t *testing.T, status, manifest string) string {
+	t.Helper()
+	release := helmRelease{Name: "app", Namespace: "apps", Manifest: manifest}
+	release.Info.Status = status
+	b, err := json.Marshal(release)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func Test_decodeInputHelmReleases(t *testing.T) {
+	manifest := `---
+# Source: app/templates/ingress.yaml
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: app
+spec:
+  rules:
+  - host: example.com
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: other
+  namespace: other
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+`
+	secret := func(status string) string {
+		return fmt.Sprintf(`
+apiVersion: v1
+kind: Secret
+type: helm.sh/release.v1
+metadata:
+  name: sh.helm.release.v1.app.v1
+  namespace: apps
+  labels:
+    owner: helm
+data:
+  release: %s
+`, base64.StdEncoding.EncodeToString([]byte(encodeHelmRelease(t, status, manifest))))
+	}
+
+	testCases := []struct {
+		name            string
+		input           string
+		expectIngresses []string
+		expectObjects   []string
+		expectError     string
+	}{{
+		name:            "deployed release Secret",
+		input:           secret("deployed"),
+		expectIngresses: []string{"apps/app", "other/other"},
+		expectObjects:   []string{"Service apps/app"},
+	}, {
+		name:  "superseded release Secret",
+		input: secret("superseded"),
+	}, {
+		name: "deployed release ConfigMap",
+		input: fmt.Sprintf(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app.v1
+  namespace: apps
+  labels:
+    owner: helm
+data:
+  release: %s
+`, encodeHelmRelease(t, "deployed", manifest)),
+		expectIngresses: []string{"apps/app", "other/other"},
+		expectObjects:   []string{"Service apps/app"},
+	}, {
+		name: "Secret of another type",
+		input: `
+apiVersion: v1
+kind: Secret
+type: Opaque
+metadata:
+  name: app
+  namespace: apps
+  labels:
+    owner: helm
+data:
+  release: bm90IGEgcmVsZWFzZQ==
+`,
+		expectObjects: []string{"Secret apps/app"},
+	}, {
+		name: "invalid release",
+		input: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app.v1
+  namespace: apps
+  labels:
+    owner: helm
+data:
+  release: not a release
+`,
+		expectError: "failed to decode Helm release apps/app.v1: illegal base64 data at input byte 3",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			input, err := decodeInput(strings.NewReader(tc.input))
+			if tc.expectError != "" {
+				if err == nil || err.Error() != tc.expectError {
+					t.Fatalf("Expected error %q, got %v", tc.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var ingresses, objects []string
+			for _, ingress := range input.ingresses {
+				ingresses = append(ingresses, ingress.Namespace+"/"+ingress.Name)
+			}
+			for _, obj := range input.objects {
+				objects = append(objects, obj.GetKind()+" "+obj.GetNamespace()+"/"+obj.GetName())
+			}
+			if diff := cmp.Diff(tc.expectIngresses, ingresses); diff != "" {
+				t.Errorf("Unexpected Ingresses, diff (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectObjects, objects); diff != "" {
+				t.Errorf("Unexpected objects, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// hostnameClaim is an HTTPRoute matching requests for a hostname, and the
+// Ingress its rule was converted from.
+type hostnameClaim struct {
+	route   types.NamespacedName
+	ingress types.NamespacedName
+}
+
+// hostnameConflicts reports the hostnames claimed by the HTTPRoutes of
+// several namespaces, which Ingress controllers and Gateway API resolve
+// differently. Ingress controllers serve a host on a single address and the
+// oldest Ingress wins the requests matched by several ones, whereas
+// Gateways of different namespaces get an address each and the oldest
+// HTTPRoute, then the first one in namespace/name order, wins the requests
+// matched by several routes of the same Gateway.
+func hostnameConflicts(result ir.IR) []Notification {
+	ingressOrder := map[types.NamespacedName]int{}
+	for i, ingress := range result.Ingresses {
+		ingressOrder[types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}] = i
+	}
+	gatewayClasses := map[types.NamespacedName]string{}
+	for _, gw := range result.Gateways {
+		gatewayClasses[types.NamespacedName{Namespace: gw.Namespace, Name: gw.Name}] = gw.GatewayClassName
+	}
+
+	routesByHost := map[string][]ir.HTTPRoute{}
+	var hosts []string
+	for _, route := range result.HTTPRoutes {
+		if route.Hostname == "" {
+			continue
+		}
+		if _, ok := routesByHost[route.Hostname]; !ok {
+			hosts = append(hosts, route.Hostname)
+		}
+		routesByHost[route.Hostname] = append(routesByHost[route.Hostname], route)
+	}
+	sort.Strings(hosts)
+
+	var notes []Notification
+	for _, host := range hosts {
+		routes := routesByHost[host]
+		namespaces := map[string]struct{}{}
+		for _, route := range routes {
+			namespaces[route.Namespace] = struct{}{}
+		}
+		if len(namespaces) < 2 {
+			continue
+		}
+		notes = append(notes, gatewayAddressConflicts(host, routes, gatewayClasses)...)
+		notes = append(notes, routeMatchConflicts(host, routes, ingressOrder)...)
+	}
+	return notes
+}
+
+// gatewayAddressConflicts warns about the Gateways of the same class in
+// different namespaces serving host, only one of which the host can
+// resolve to.
+func gatewayAddressConflicts(host string, routes []ir.HTTPRoute, gatewayClasses map[types.NamespacedName]string) []Notification {
+	gatewaysByClass := map[string][]types.NamespacedName{}
+	var classes []string
+	for _, route := range routes {
+		gw := route.Gateway()
+		class := gatewayClasses[gw]
+		if _, ok := gatewaysByClass[class]; !ok {
+			classes = append(classes, class)
+		}
+		if !containsNamespacedName(gatewaysByClass[class], gw) {
+			gatewaysByClass[class] = append(gatewaysByClass[class], gw)
+		}
+	}
+	sort.Strings(classes)
+
+	var notes []Notification
+	for _, class := range classes {
+		gateways := sortedNamespacedNames(gatewaysByClass[class])
+		if len(gateways) < 2 {
+			continue
+		}
+		notes = append(notes, notifications.NewWarning("Host %q is served by Gateways %s of class %s, which get an address each whereas the Ingress controller served the host on a single address, only the Gateway the host resolves to will receive its requests", host, joinNamespacedNames(gateways), class))
+	}
+	return notes
+}
+
+// routeMatchConflicts reports the requests for host matched by HTTPRoutes
+// of different namespaces attached to the same Gateway, with the route
+// winning them once the routes are created together, and warns when it was
+// converted from another Ingress than the one winning them before.
+func routeMatchConflicts(host string, routes []ir.HTTPRoute, ingressOrder map[types.NamespacedName]int) []Notification {
+	type matchKey struct {
+		gateway types.NamespacedName
+		match   string
+	}
+	claims := map[matchKey][]hostnameClaim{}
+	matches := map[matchKey]gatewayv1.HTTPRouteMatch{}
+	var keys []matchKey
+	for _, route := range routes {
+		routeName := types.NamespacedName{Namespace: route.Namespace, Name: route.Name}
+		for _, rule := range route.Rules {
+			var ingress types.NamespacedName
+			if len(rule.Backends) > 0 {
+				ingress = rule.Backends[0].Source
+			}
+			for _, match := range rule.Matches {
+				encoded, err := json.Marshal(match)
+				if err != nil {
+					continue
+				}
+				key := matchKey{gateway: route.Gateway(), match: string(encoded)}
+				if _, ok := claims[key]; !ok {
+					keys = append(keys, key)
+					matches[key] = match
+				}
+				claims[key] = append(claims[key], hostnameClaim{route: routeName, ingress: ingress})
+			}
+		}
+	}
+
+	var notes []Notification
+	for _, key := range keys {
+		keyClaims := claims[key]
+		namespaces := map[string]struct{}{}
+		for _, claim := range keyClaims {
+			namespaces[claim.route.Namespace] = struct{}{}
+		}
+		if len(namespaces) < 2 {
+			continue
+		}
+		// HTTPRoutes created together have the same age, so the first one
+		// in namespace/name order wins.
+		routeWinner := keyClaims[0]
+		ingressWinner := keyClaims[0]
+		for _, claim := range keyClaims[1:] {
+			if claim.route.String() < routeWinner.route.String() {
+				routeWinner = claim
+			}
+			if ingressPrecedes(claim.ingress, ingressWinner.ingress, ingressOrder) {
+				ingressWinner = claim
+			}
+		}
+		var losers []types.NamespacedName
+		for _, claim := range keyClaims {
+			if claim.route != routeWinner.route && !containsNamespacedName(losers, claim.route) {
+				losers = append(losers, claim.route)
+			}
+		}
+		losers = sortedNamespacedNames(losers)
+		if routeWinner.ingress == ingressWinner.ingress {
+			notes = append(notes, notifications.NewInfo("Requests for host %q matching %s are matched by HTTPRoutes of several namespaces attached to Gateway %s, HTTPRoute %s wins them over %s, serving Ingress %s like before the migration", host, describeMatch(matches[key]), key.gateway, routeWinner.route, joinNamespacedNames(losers), routeWinner.ingress))
+			continue
+		}
+		notes = append(notes, notifications.NewWarning("Requests for host %q matching %s are matched by HTTPRoutes of several namespaces attached to Gateway %s, HTTPRoute %s wins them over %s, serving Ingress %s instead of Ingress %s, unless HTTPRoute %s is created first", host, describeMatch(matches[key]), key.gateway, routeWinner.route, joinNamespacedNames(losers), routeWinner.ingress, ingressWinner.ingress, ingressWinner.route))
+	}
+	return notes
+}
+
+// ingressPrecedes returns whether Ingress a wins the requests it matches
+// over Ingress b, Ingresses missing from the order losing to the others.
+func ingressPrecedes(a, b types.NamespacedName, ingressOrder map[types.NamespacedName]int) bool {
+	i, iok := ingressOrder[a]
+	j, jok := ingressOrder[b]
+	if iok != jok {
+		return iok
+	}
+	return iok && i < j
+}
+
+func describeMatch(match gatewayv1.HTTPRouteMatch) string {
+	var parts []string
+	if match.Path != nil && match.Path.Value != nil {
+		pathType := gatewayv1.PathMatchPathPrefix
+		if match.Path.Type != nil {
+			pathType = *match.Path.Type
+		}
+		parts = append(parts, fmt.Sprintf("%s %s", pathType, *match.Path.Value))
+	}
+	for _, header := range match.Headers {
+		parts = append(parts, fmt.Sprintf("header %s: %s", header.Name, header.Value))
+	}
+	for _, param := range match.QueryParams {
+		parts = append(parts, fmt.Sprintf("query parameter %s=%s", param.Name, param.Value))
+	}
+	if match.Method != nil {
+		parts = append(parts, fmt.Sprintf("method %s", *match.Method))
+	}
+	if len(parts) == 0 {
+		return "any request"
+	}
+	return strings.Join(parts, " and ")
+}
+
+func joinNamespacedNames(names []types.NamespacedName) string {
+	var s []string
+	for _, name := range names {
+		s = append(s, name.String())
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,116 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_hostnameConflicts(t *testing.T) {
+	webIngress := types.NamespacedName{Namespace: "web", Name: "shop"}
+	apiIngress := types.NamespacedName{Namespace: "api", Name: "shop"}
+	pathPrefix := gatewayv1.PathMatchPathPrefix
+	route := func(ingress types.NamespacedName, gatewayNamespace, path string) ir.HTTPRoute {
+		return ir.HTTPRoute{
+			Namespace:        ingress.Namespace,
+			Name:             "shop-example-com",
+			GatewayName:      "nginx",
+			GatewayNamespace: gatewayNamespace,
+			Hostname:         "shop.example.com",
+			Rules: []ir.HTTPRouteRule{{
+				Matches:  []gatewayv1.HTTPRouteMatch{{Path: &gatewayv1.HTTPPathMatch{Type: &pathPrefix, Value: ptrTo(path)}}},
+				Backends: []ir.Backend{{Source: ingress}},
+			}},
+		}
+	}
+	gateway := func(namespace string) ir.Gateway {
+		return ir.Gateway{Namespace: namespace, Name: "nginx", GatewayClassName: "nginx"}
+	}
+	// The Ingress of the web namespace is the oldest one.
+	ingresses := []networkingv1.Ingress{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "shop"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "api", Name: "shop"}},
+	}
+
+	testCases := []struct {
+		name                string
+		result              ir.IR
+		expectNotifications []string
+	}{{
+		name: "routes of a single namespace",
+		result: ir.IR{
+			Gateways:   []ir.Gateway{gateway("web")},
+			HTTPRoutes: []ir.HTTPRoute{route(webIngress, "", "/")},
+			Ingresses:  ingresses,
+		},
+	}, {
+		name: "Gateways of the same class in each namespace",
+		result: ir.IR{
+			Gateways:   []ir.Gateway{gateway("web"), gateway("api")},
+			HTTPRoutes: []ir.HTTPRoute{route(webIngress, "", "/"), route(apiIngress, "", "/api")},
+			Ingresses:  ingresses,
+		},
+		expectNotifications: []string{
+			`WARNING: Host "shop.example.com" is served by Gateways api/nginx, web/nginx of class nginx, which get an address each whereas the Ingress controller served the host on a single address, only the Gateway the host resolves to will receive its requests`,
+		},
+	}, {
+		name: "shared Gateway with distinct paths",
+		result: ir.IR{
+			Gateways:   []ir.Gateway{gateway("infra")},
+			HTTPRoutes: []ir.HTTPRoute{route(webIngress, "infra", "/"), route(apiIngress, "infra", "/api")},
+			Ingresses:  ingresses,
+		},
+	}, {
+		name: "shared Gateway with the same path",
+		result: ir.IR{
+			Gateways:   []ir.Gateway{gateway("infra")},
+			HTTPRoutes: []ir.HTTPRoute{route(webIngress, "infra", "/"), route(apiIngress, "infra", "/")},
+			Ingresses:  ingresses,
+		},
+		expectNotifications: []string{
+			`WARNING: Requests for host "shop.example.com" matching PathPrefix / are matched by HTTPRoutes of several namespaces attached to Gateway infra/nginx, HTTPRoute api/shop-example-com wins them over web/shop-example-com, serving Ingress api/shop instead of Ingress web/shop, unless HTTPRoute web/shop-example-com is created first`,
+		},
+	}, {
+		name: "shared Gateway with the same path won by the oldest Ingress",
+		result: ir.IR{
+			Gateways:   []ir.Gateway{gateway("infra")},
+			HTTPRoutes: []ir.HTTPRoute{route(webIngress, "infra", "/"), route(apiIngress, "infra", "/")},
+			Ingresses:  []networkingv1.Ingress{ingresses[1], ingresses[0]},
+		},
+		expectNotifications: []string{
+			`INFO: Requests for host "shop.example.com" matching PathPrefix / are matched by HTTPRoutes of several namespaces attached to Gateway infra/nginx, HTTPRoute api/shop-example-com wins them over web/shop-example-com, serving Ingress api/shop like before the migration`,
+		},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotNotifications []string
+			for _, n := range hostnameConflicts(tc.result) {
+				gotNotifications = append(gotNotifications, n.String())
+			}
+			if diff := cmp.Diff(tc.expectNotifications, gotNotifications); diff != "" {
+				t.Errorf("Unexpected notifications, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
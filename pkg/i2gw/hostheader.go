@@ -0,0 +1,117 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// HostHeaderMode is how the controller of a provider sets the Host header of
+// the requests it sends to backends, for the Ingresses that don't configure
+// it.
+type HostHeaderMode string
+
+const (
+	// HostHeaderPreserve sends the Host header of the request, like Gateway
+	// API implementations do.
+	HostHeaderPreserve HostHeaderMode = "preserve"
+	// HostHeaderRewrite sends the DNS name of the Service of the backend,
+	// which the generated HTTPRoutes rewrite the Host header to.
+	HostHeaderRewrite HostHeaderMode = "rewrite"
+)
+
+// HostHeaderModes returns the names of the supported Host header modes.
+func HostHeaderModes() []string {
+	return []string{string(HostHeaderPreserve), string(HostHeaderRewrite)}
+}
+
+// validateHostHeaders checks the modes of the providers, by provider name.
+func validateHostHeaders(modes map[string]HostHeaderMode, providers []Provider) error {
+	var names []string
+	for _, p := range providers {
+		names = append(names, p.Name())
+	}
+	for name, mode := range modes {
+		switch mode {
+		case HostHeaderPreserve, HostHeaderRewrite:
+		default:
+			return fmt.Errorf("unknown Host header mode %q for provider %s, supported ones are: %s", mode, name, strings.Join(HostHeaderModes(), ", "))
+		}
+		found := false
+		for _, n := range names {
+			found = found || n == name
+		}
+		if !found {
+			sort.Strings(names)
+			return fmt.Errorf("unknown provider %q for the Host header mode, supported ones are: %s", name, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// defaultHostHeader rewrites the Host header of the requests of Ingresses
+// that don't configure it to the name of their Service when the provider of
+// their class does so.
+func (a *ingressAggregator) defaultHostHeader(ingressClass string, features *ir.IngressFeatures) {
+	if features.HostHeader != nil {
+		return
+	}
+	providers := a.providersFor(ingressClass)
+	if len(providers) > 0 && a.hostHeaders[providers[0].Name()] == HostHeaderRewrite {
+		features.HostHeader = &ir.HostHeader{}
+	}
+}
+
+// setHostRewrite rewrites the Host header of the requests of the rule, in
+// the URLRewrite filter of the rule if it has one. Rewrites to the name of
+// the Service of the backends require the backends to be a single Service.
+func setHostRewrite(rule *ir.HTTPRouteRule, namespace string, hostHeader *ir.HostHeader) error {
+	if hostHeader == nil || len(rule.Backends) == 0 {
+		return nil
+	}
+	hostname := hostHeader.Hostname
+	if hostname == "" {
+		first := backendRefTrafficBackend(namespace, rule.Backends[0].BackendObjectReference)
+		for _, backend := range rule.Backends {
+			b := backendRefTrafficBackend(namespace, backend.BackendObjectReference)
+			if b.Kind != "Service" || backend.Group != nil && *backend.Group != "" {
+				return fmt.Errorf("can't rewrite the Host header of the requests to %s to the name of a Service", b)
+			}
+			if b.Namespace != first.Namespace || b.Name != first.Name {
+				return fmt.Errorf("can't rewrite the Host header of the requests split between %s and %s to the name of their Service", first, b)
+			}
+		}
+		hostname = fmt.Sprintf("%s.%s.svc.cluster.local", first.Name, first.Namespace)
+	}
+
+	precise := gatewayv1.PreciseHostname(hostname)
+	for i := range rule.Filters {
+		if rule.Filters[i].Type == gatewayv1.HTTPRouteFilterURLRewrite && rule.Filters[i].URLRewrite != nil {
+			rule.Filters[i].URLRewrite.Hostname = &precise
+			return nil
+		}
+	}
+	rule.Filters = append(rule.Filters, gatewayv1.HTTPRouteFilter{
+		Type:       gatewayv1.HTTPRouteFilterURLRewrite,
+		URLRewrite: &gatewayv1.HTTPURLRewriteFilter{Hostname: &precise},
+	})
+	return nil
+}
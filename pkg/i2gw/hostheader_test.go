@@ -0,0 +1,135 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_hostHeader(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	web := ingressWithPath("web", "/", &iPrefix, serviceBackend("web", 80), nil)
+	vhost := ingressWithPath("web", "/", &iPrefix, serviceBackend("web", 80), map[string]string{
+		"nginx.ingress.kubernetes.io/upstream-vhost": "web.internal",
+	})
+	canary := ingressWithPath("web-canary", "/", &iPrefix, serviceBackend("web-canary", 80), map[string]string{
+		"nginx.ingress.kubernetes.io/canary":        "true",
+		"nginx.ingress.kubernetes.io/canary-weight": "20",
+	})
+	rewrite := map[string]HostHeaderMode{"ingress-nginx": HostHeaderRewrite}
+
+	testCases := []struct {
+		name         string
+		ingresses    []networkingv1.Ingress
+		hostHeaders  map[string]HostHeaderMode
+		wantHostname string
+		wantErrors   []string
+	}{
+		{
+			name:      "preserved by default",
+			ingresses: []networkingv1.Ingress{web},
+		},
+		{
+			name:        "preserved by the provider",
+			ingresses:   []networkingv1.Ingress{web},
+			hostHeaders: map[string]HostHeaderMode{"ingress-nginx": HostHeaderPreserve},
+		},
+		{
+			name:         "rewritten to the Service by the provider",
+			ingresses:    []networkingv1.Ingress{web},
+			hostHeaders:  rewrite,
+			wantHostname: "web.test.svc.cluster.local",
+		},
+		{
+			name:         "rewritten by the annotation",
+			ingresses:    []networkingv1.Ingress{vhost},
+			hostHeaders:  rewrite,
+			wantHostname: "web.internal",
+		},
+		{
+			name:        "split between Services",
+			ingresses:   []networkingv1.Ingress{web, canary},
+			hostHeaders: rewrite,
+			wantErrors: []string{
+				"can't rewrite the Host header of the requests split between Service test/web:80 and Service test/web-canary:80 to the name of their Service",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resources, report := convertInput(inputResources{ingresses: tc.ingresses}, ConvertOptions{HostHeaders: tc.hostHeaders})
+			var gotErrors []string
+			for _, err := range report.Errors {
+				gotErrors = append(gotErrors, err.Error())
+			}
+			if diff := cmp.Diff(tc.wantErrors, gotErrors); diff != "" {
+				t.Errorf("unexpected errors (-want +got):\n%s", diff)
+			}
+			if len(resources.HTTPRoutes) != 1 || len(resources.HTTPRoutes[0].Spec.Rules) != 1 {
+				t.Fatalf("expected a single HTTPRoute rule, got %+v", resources.HTTPRoutes)
+			}
+			var gotHostname string
+			for _, filter := range resources.HTTPRoutes[0].Spec.Rules[0].Filters {
+				if filter.Type == gatewayv1.HTTPRouteFilterURLRewrite && filter.URLRewrite.Hostname != nil {
+					gotHostname = string(*filter.URLRewrite.Hostname)
+				}
+			}
+			if gotHostname != tc.wantHostname {
+				t.Errorf("expected the Host header to be rewritten to %q, got %q", tc.wantHostname, gotHostname)
+			}
+		})
+	}
+}
+
+func Test_validateHostHeaders(t *testing.T) {
+	testCases := []struct {
+		name    string
+		modes   map[string]HostHeaderMode
+		wantErr string
+	}{
+		{
+			name:  "known provider",
+			modes: map[string]HostHeaderMode{"aws-load-balancer-controller": HostHeaderRewrite},
+		},
+		{
+			name:    "unknown mode",
+			modes:   map[string]HostHeaderMode{"aws-load-balancer-controller": "keep"},
+			wantErr: `unknown Host header mode "keep" for provider aws-load-balancer-controller, supported ones are: preserve, rewrite`,
+		},
+		{
+			name:    "unknown provider",
+			modes:   map[string]HostHeaderMode{"traefik": HostHeaderRewrite},
+			wantErr: `unknown provider "traefik" for the Host header mode, supported ones are: aws-load-balancer-controller, ingress-nginx`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotErr string
+			if err := validateHostHeaders(tc.modes, builtinProviders()); err != nil {
+				gotErr = err.Error()
+			}
+			if gotErr != tc.wantErr {
+				t.Errorf("expected error %q, got %q", tc.wantErr, gotErr)
+			}
+		})
+	}
+}
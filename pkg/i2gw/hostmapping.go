@@ -0,0 +1,278 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// HostMappingFormat selects how the host mapping of a conversion is
+// serialized.
+type HostMappingFormat string
+
+const (
+	// HostMappingCSV writes the host mapping as CSV, with a header row.
+	HostMappingCSV HostMappingFormat = "csv"
+	// HostMappingJSON writes the host mapping as a JSON document.
+	HostMappingJSON HostMappingFormat = "json"
+)
+
+// HostMappingFormats returns the names of the supported host mapping
+// formats.
+func HostMappingFormats() []string {
+	return []string{string(HostMappingCSV), string(HostMappingJSON)}
+}
+
+func validateHostMappingFormat(format HostMappingFormat) error {
+	switch format {
+	case "", HostMappingCSV, HostMappingJSON:
+		return nil
+	default:
+		return fmt.Errorf("unknown host mapping format %q, supported ones are: %s", format, strings.Join(HostMappingFormats(), ", "))
+	}
+}
+
+// HostMapping is a hostname served by a listener of a generated Gateway,
+// for DNS and certificate teams to re-point records and move certificates.
+type HostMapping struct {
+	// Hostname is the hostname of the listener, or else of the HTTPRoutes
+	// attached to it. It is "*" for listeners accepting any hostname
+	// without such HTTPRoutes.
+	Hostname     string `json:"hostname"`
+	Gateway      string `json:"gateway"`
+	GatewayClass string `json:"gatewayClass"`
+	Listener     string `json:"listener"`
+	Protocol     string `json:"protocol"`
+	Port         int32  `json:"port"`
+	// CertificateSecrets are the certificates of HTTPS listeners, as
+	// namespace/name for Secrets and kind namespace/name otherwise.
+	CertificateSecrets []string `json:"certificateSecrets,omitempty"`
+	// Ingresses are the Ingresses the hostname was converted from.
+	Ingresses []string `json:"ingresses,omitempty"`
+}
+
+// HostMappings returns the hostnames served by every listener of the
+// Gateways of the resources, sorted by hostname, Gateway and listener.
+func HostMappings(resources Resources) []HostMapping {
+	m := &hostMappingReport{}
+	m.add(resources)
+	return m.mappings
+}
+
+// hostMappingReport collects the host mapping of a conversion, or of the
+// conversions of every namespace of a stream, where the same listener may
+// be generated for several namespaces.
+type hostMappingReport struct {
+	mappings []HostMapping
+}
+
+func (m *hostMappingReport) add(resources Resources) {
+	for i := range resources.Gateways {
+		gw := &resources.Gateways[i]
+		gatewayRef := ObjectRef{Kind: "Gateway", Namespace: gw.Namespace, Name: gw.Name}
+		for _, listener := range gw.Spec.Listeners {
+			base := HostMapping{
+				Gateway:            gw.Namespace + "/" + gw.Name,
+				GatewayClass:       string(gw.Spec.GatewayClassName),
+				Listener:           string(listener.Name),
+				Protocol:           string(listener.Protocol),
+				Port:               int32(listener.Port),
+				CertificateSecrets: listenerCertificates(gw, listener),
+			}
+			// Hostnames map to the Ingresses of the routes serving them.
+			hostnames := map[string][]string{}
+			for j := range resources.HTTPRoutes {
+				route := &resources.HTTPRoutes[j]
+				if !attachesToListener(route, gw, listener) {
+					continue
+				}
+				ingresses := sourceIngressNames(resources.Sources[ObjectRef{Kind: "HTTPRoute", Namespace: route.Namespace, Name: route.Name}])
+				for _, hostname := range servedHostnames(listener.Hostname, route.Spec.Hostnames) {
+					hostnames[hostname] = append(hostnames[hostname], ingresses...)
+				}
+			}
+			if len(hostnames) == 0 {
+				hostnames[servedHostnames(listener.Hostname, nil)[0]] = sourceIngressNames(resources.Sources[gatewayRef])
+			}
+			for hostname, ingresses := range hostnames {
+				mapping := base
+				mapping.Hostname = hostname
+				mapping.Ingresses = ingresses
+				m.merge(mapping)
+			}
+		}
+	}
+	sort.Slice(m.mappings, func(i, j int) bool {
+		a, b := m.mappings[i], m.mappings[j]
+		if a.Hostname != b.Hostname {
+			return a.Hostname < b.Hostname
+		}
+		if a.Gateway != b.Gateway {
+			return a.Gateway < b.Gateway
+		}
+		return a.Listener < b.Listener
+	})
+}
+
+// merge adds the mapping, or its certificates and Ingresses to the mapping
+// of the same hostname and listener.
+func (m *hostMappingReport) merge(mapping HostMapping) {
+	for i := range m.mappings {
+		existing := &m.mappings[i]
+		if existing.Hostname == mapping.Hostname && existing.Gateway == mapping.Gateway && existing.Listener == mapping.Listener {
+			existing.CertificateSecrets = mergeSortedStrings(existing.CertificateSecrets, mapping.CertificateSecrets)
+			existing.Ingresses = mergeSortedStrings(existing.Ingresses, mapping.Ingresses)
+			return
+		}
+	}
+	mapping.CertificateSecrets = mergeSortedStrings(nil, mapping.CertificateSecrets)
+	mapping.Ingresses = mergeSortedStrings(nil, mapping.Ingresses)
+	m.mappings = append(m.mappings, mapping)
+}
+
+func mergeSortedStrings(a, b []string) []string {
+	var merged []string
+	for _, s := range append(slices.Clone(a), b...) {
+		if !slices.Contains(merged, s) {
+			merged = append(merged, s)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
+
+// servedHostnames returns the hostnames of the route the listener serves:
+// the route hostnames the listener hostname matches, or the listener
+// hostname where it is more specific than the route hostname.
+func servedHostnames(listenerHostname *gatewayv1.Hostname, routeHostnames []gatewayv1.Hostname) []string {
+	listenerHost := "*"
+	if listenerHostname != nil {
+		listenerHost = string(*listenerHostname)
+	}
+	if len(routeHostnames) == 0 {
+		return []string{listenerHost}
+	}
+	var hostnames []string
+	for _, hostname := range routeHostnames {
+		routeHost := string(hostname)
+		switch {
+		case listenerHostname == nil || trafficHostScore(listenerHost, routeHost, false) > 0:
+			hostnames = append(hostnames, routeHost)
+		case trafficHostScore(routeHost, listenerHost, false) > 0:
+			hostnames = append(hostnames, listenerHost)
+		}
+	}
+	return hostnames
+}
+
+func listenerCertificates(gw *gatewayv1.Gateway, listener gatewayv1.Listener) []string {
+	if listener.TLS == nil {
+		return nil
+	}
+	var certificates []string
+	for _, ref := range listener.TLS.CertificateRefs {
+		namespace := gw.Namespace
+		if ref.Namespace != nil {
+			namespace = string(*ref.Namespace)
+		}
+		certificate := namespace + "/" + string(ref.Name)
+		if ref.Kind != nil && *ref.Kind != "Secret" {
+			certificate = string(*ref.Kind) + " " + certificate
+		}
+		certificates = append(certificates, certificate)
+	}
+	return certificates
+}
+
+func sourceIngressNames(sources []IngressSource) []string {
+	var names []string
+	for _, source := range sources {
+		names = append(names, source.Ingress.String())
+	}
+	return names
+}
+
+// WriteHostMappings writes the host mappings in the format. Lists are
+// separated by spaces in CSV cells.
+func WriteHostMappings(w io.Writer, mappings []HostMapping, format HostMappingFormat) error {
+	m := &hostMappingReport{mappings: mappings}
+	return m.write(w, format)
+}
+
+func (m *hostMappingReport) write(w io.Writer, format HostMappingFormat) error {
+	switch format {
+	case "", HostMappingCSV:
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"hostname", "gateway", "gatewayClass", "listener", "protocol", "port", "certificateSecrets", "ingresses"})
+		for _, mapping := range m.mappings {
+			cw.Write([]string{
+				mapping.Hostname,
+				mapping.Gateway,
+				mapping.GatewayClass,
+				mapping.Listener,
+				mapping.Protocol,
+				strconv.Itoa(int(mapping.Port)),
+				strings.Join(mapping.CertificateSecrets, " "),
+				strings.Join(mapping.Ingresses, " "),
+			})
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf("failed to write host mapping: %w", err)
+		}
+		return nil
+	case HostMappingJSON:
+		mappings := m.mappings
+		if mappings == nil {
+			mappings = []HostMapping{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(struct {
+			Hosts []HostMapping `json:"hosts"`
+		}{mappings}); err != nil {
+			return fmt.Errorf("failed to write host mapping: %w", err)
+		}
+		return nil
+	default:
+		return validateHostMappingFormat(format)
+	}
+}
+
+func writeHostMappingFile(runOpts RunOptions, m *hostMappingReport) error {
+	if runOpts.HostMappingFile == "" {
+		return m.write(os.Stderr, runOpts.HostMapping)
+	}
+	f, err := os.Create(runOpts.HostMappingFile)
+	if err != nil {
+		return fmt.Errorf("failed to create host mapping file: %w", err)
+	}
+	if err := m.write(f, runOpts.HostMapping); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,118 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+func Test_HostMappings(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	web := ingressWithPath("web", "/", &iPrefix, serviceBackend("web", 80), nil)
+	web.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-com-tls"}}
+	api := ingressWithPath("api", "/api", &iPrefix, serviceBackend("api", 80), nil)
+	api.Spec.Rules[0].Host = "api.example.com"
+	api.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"*.example.com"}, SecretName: "wildcard-tls"}}
+
+	testCases := []struct {
+		name             string
+		ingresses        []networkingv1.Ingress
+		listenerStrategy ListenerStrategy
+		format           HostMappingFormat
+		want             string
+	}{
+		{
+			name:      "csv",
+			ingresses: []networkingv1.Ingress{web, api},
+			format:    HostMappingCSV,
+			want: `hostname,gateway,gatewayClass,listener,protocol,port,certificateSecrets,ingresses
+api.example.com,test/nginx,nginx,api-example-com-http,HTTP,80,,
+api.example.com,test/nginx,nginx,api-example-com-https,HTTPS,443,test/wildcard-tls,test/api
+example.com,test/nginx,nginx,example-com-http,HTTP,80,,
+example.com,test/nginx,nginx,example-com-https,HTTPS,443,test/example-com-tls,test/web
+`,
+		},
+		{
+			name:      "json",
+			ingresses: []networkingv1.Ingress{web},
+			format:    HostMappingJSON,
+			want: `{
+  "hosts": [
+    {
+      "hostname": "example.com",
+      "gateway": "test/nginx",
+      "gatewayClass": "nginx",
+      "listener": "example-com-http",
+      "protocol": "HTTP",
+      "port": 80
+    },
+    {
+      "hostname": "example.com",
+      "gateway": "test/nginx",
+      "gatewayClass": "nginx",
+      "listener": "example-com-https",
+      "protocol": "HTTPS",
+      "port": 443,
+      "certificateSecrets": [
+        "test/example-com-tls"
+      ],
+      "ingresses": [
+        "test/web"
+      ]
+    }
+  ]
+}
+`,
+		},
+		{
+			// The hostnames of wildcard listeners are the ones of their
+			// routes, the HTTP listener only serving the HTTPS redirect
+			// of the ssl-redirect default of ingress-nginx.
+			name:             "listeners per certificate",
+			ingresses:        []networkingv1.Ingress{api},
+			listenerStrategy: ListenerPerCertificate,
+			format:           HostMappingCSV,
+			want: `hostname,gateway,gatewayClass,listener,protocol,port,certificateSecrets,ingresses
+*.example.com,test/nginx,nginx,wildcard-example-com-http,HTTP,80,,
+api.example.com,test/nginx,nginx,wildcard-example-com-https,HTTPS,443,test/wildcard-tls,test/api
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resources, _, err := Convert(context.Background(), ConvertOptions{
+				Ingresses:        tc.ingresses,
+				ListenerStrategy: tc.listenerStrategy,
+			})
+			if err != nil {
+				t.Fatalf("Failed to convert: %v", err)
+			}
+			var buf bytes.Buffer
+			if err := WriteHostMappings(&buf, HostMappings(resources), tc.format); err != nil {
+				t.Fatalf("Failed to write host mappings: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, buf.String()); diff != "" {
+				t.Errorf("Unexpected host mappings, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"golang.org/x/net/idna"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// normalizeHosts lowercases the hosts of the rules and TLS entries of the
+// Ingresses and converts internationalized ones to punycode, as Ingress
+// controllers match hosts case-insensitively while the API server rejects
+// the hostnames of listeners and HTTPRoutes that aren't. The Ingresses are
+// copied before being modified. Hosts that are invalid once converted are
+// kept for validation to report them.
+func normalizeHosts(ingresses []networkingv1.Ingress) ([]networkingv1.Ingress, []Notification) {
+	var normalized []networkingv1.Ingress
+	var notes []Notification
+	for _, ingress := range ingresses {
+		converted := map[string]string{}
+		var order []string
+		normalize := func(host string) string {
+			n, ok := normalizeHost(host)
+			if !ok || n == host {
+				return host
+			}
+			if _, ok := converted[host]; !ok {
+				converted[host] = n
+				order = append(order, host)
+			}
+			return n
+		}
+
+		copied := ingress.DeepCopy()
+		for i := range copied.Spec.Rules {
+			copied.Spec.Rules[i].Host = normalize(copied.Spec.Rules[i].Host)
+		}
+		for i := range copied.Spec.TLS {
+			for j := range copied.Spec.TLS[i].Hosts {
+				copied.Spec.TLS[i].Hosts[j] = normalize(copied.Spec.TLS[i].Hosts[j])
+			}
+		}
+		if len(order) == 0 {
+			normalized = append(normalized, ingress)
+			continue
+		}
+		normalized = append(normalized, *copied)
+		for _, host := range order {
+			notes = append(notes, notifications.NewInfo("Host %q of Ingress %s/%s is converted to %q", host, ingress.Namespace, ingress.Name, converted[host]))
+		}
+	}
+	return normalized, notes
+}
+
+// normalizeHost returns the lowercase ASCII form of a host, keeping the
+// "*." prefix of wildcard hosts, and whether it is a valid host once
+// converted.
+func normalizeHost(host string) (string, bool) {
+	name, wildcard := strings.CutPrefix(host, "*.")
+	name = strings.TrimSuffix(name, ".")
+	if isASCII(name) {
+		name = strings.ToLower(name)
+	} else {
+		ascii, err := idna.Lookup.ToASCII(name)
+		if err != nil {
+			return host, false
+		}
+		name = ascii
+	}
+	if wildcard {
+		name = "*." + name
+	}
+	if len(validateIngressHost(name, field.NewPath("host"))) > 0 {
+		return host, false
+	}
+	return name, true
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
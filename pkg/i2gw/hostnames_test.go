@@ -0,0 +1,89 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+func Test_normalizeHost(t *testing.T) {
+	testCases := []struct {
+		host   string
+		want   string
+		wantOK bool
+	}{
+		{host: "example.com", want: "example.com", wantOK: true},
+		{host: "Shop.Example.COM", want: "shop.example.com", wantOK: true},
+		{host: "*.Example.com", want: "*.example.com", wantOK: true},
+		{host: "example.com.", want: "example.com", wantOK: true},
+		{host: "bücher.example", want: "xn--bcher-kva.example", wantOK: true},
+		{host: "*.Bücher.example", want: "*.xn--bcher-kva.example", wantOK: true},
+		{host: "Example_com", want: "Example_com"},
+		{host: "10.0.0.1", want: "10.0.0.1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.host, func(t *testing.T) {
+			got, ok := normalizeHost(tc.host)
+			if got != tc.want || ok != tc.wantOK {
+				t.Errorf("normalizeHost(%q) = %q, %t, want %q, %t", tc.host, got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+func Test_normalizeHosts(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	ingress := ingressWithPath("web", "/", &iPrefix, serviceBackend("web", 80), nil)
+	ingress.Spec.Rules[0].Host = "Bücher.Example"
+	ingress.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"Bücher.Example"}, SecretName: "tls"}}
+
+	resources, report := convertInput(inputResources{ingresses: []networkingv1.Ingress{ingress}}, ConvertOptions{})
+	if len(report.Errors) > 0 {
+		t.Fatalf("Unexpected errors: %v", report.Errors)
+	}
+	if ingress.Spec.Rules[0].Host != "Bücher.Example" {
+		t.Errorf("The input Ingress is modified: %v", ingress.Spec.Rules[0].Host)
+	}
+
+	var hostnames []string
+	for _, gw := range resources.Gateways {
+		for _, listener := range gw.Spec.Listeners {
+			hostnames = append(hostnames, string(*listener.Hostname))
+		}
+	}
+	for _, route := range resources.HTTPRoutes {
+		hostnames = append(hostnames, route.Name)
+		for _, hostname := range route.Spec.Hostnames {
+			hostnames = append(hostnames, string(hostname))
+		}
+	}
+	want := []string{"xn--bcher-kva.example", "xn--bcher-kva.example", "xn-bcher-kva-example-https-redirect", "xn--bcher-kva.example", "xn-bcher-kva-example", "xn--bcher-kva.example"}
+	if diff := cmp.Diff(want, hostnames); diff != "" {
+		t.Errorf("Unexpected hostnames, diff (-want +got): %s", diff)
+	}
+
+	var found bool
+	for _, n := range report.Notifications {
+		found = found || n.Type == InfoNotification && n.Message == `Host "Bücher.Example" of Ingress test/web is converted to "xn--bcher-kva.example"`
+	}
+	if !found {
+		t.Errorf("Expected a notification about the converted host, got %v", report.Notifications)
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package i2gwtest is a golden-file test harness for conversions, meant for
+// testing providers and emitters.
+package i2gwtest
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+)
+
+// UpdateEnv is the environment variable that, when set to "true", makes Run
+// write the actual output to the golden files instead of comparing with them.
+const UpdateEnv = "I2GW_UPDATE_GOLDEN"
+
+// Run converts every .yaml file in dir/input with opts and compares the
+// output, as written by i2gw.WriteResult, with the file of the same name in
+// dir/output. Each input file is run as a subtest.
+func Run(t *testing.T, dir string, opts i2gw.ConvertOptions) {
+	t.Helper()
+
+	inputs, err := filepath.Glob(filepath.Join(dir, "input", "*.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to list input files: %v", err)
+	}
+	if len(inputs) == 0 {
+		t.Fatalf("No input files found in %s", filepath.Join(dir, "input"))
+	}
+	update := os.Getenv(UpdateEnv) == "true"
+
+	for _, input := range inputs {
+		input := input
+		name := strings.TrimSuffix(filepath.Base(input), ".yaml")
+		t.Run(name, func(t *testing.T) {
+			fileOpts := opts
+			fileOpts.InputFile = input
+			resources, report, err := i2gw.Convert(context.Background(), fileOpts)
+			if err != nil {
+				t.Fatalf("Failed to convert %s: %v", input, err)
+			}
+			var got bytes.Buffer
+			i2gw.WriteResult(&got, resources, report)
+
+			golden := filepath.Join(dir, "output", filepath.Base(input))
+			if update {
+				if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+					t.Fatalf("Failed to create output directory: %v", err)
+				}
+				if err := os.WriteFile(golden, got.Bytes(), 0o644); err != nil {
+					t.Fatalf("Failed to update %s: %v", golden, err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Failed to read %s, run with %s=true to create it: %v", golden, UpdateEnv, err)
+			}
+			if diff := cmp.Diff(string(want), got.String()); diff != "" {
+				t.Errorf("Unexpected output for %s, diff (-want +got): %s", input, diff)
+			}
+		})
+	}
+}
@@ -16,64 +16,1415 @@ limitations under the License.
 package i2gw
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/template"
+	"time"
 
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
 	networkingv1 "k8s.io/api/networking/v1"
-	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	apimachineryvalidation "k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1alpha3 "sigs.k8s.io/gateway-api/apis/v1alpha3"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 )
 
-func Run() {
-	cl, err := client.New(config.GetConfigOrDie(), client.Options{})
+// ConvertOptions configures where Convert reads its input from, and what it
+// produces. Objects from all configured sources are converted together.
+type ConvertOptions struct {
+	// Client, if set, is used to list Ingresses and IngressClasses from a
+	// cluster, and to check the Secrets of the certificateRefs of the
+	// Gateways.
+	Client client.Client
+	// InputFile, if set, is the path of a manifest file to read Ingresses
+	// and IngressClasses from.
+	InputFile string
+	// Input, if set, is a stream of YAML or JSON manifests read like
+	// InputFile.
+	Input io.Reader
+	// Ingresses and IngressClasses are converted in addition to the objects
+	// read from the Client, InputFile and Input.
+	Ingresses      []networkingv1.Ingress
+	IngressClasses []networkingv1.IngressClass
+	// Objects are read in addition to Ingresses and IngressClasses, for the
+	// target implementation, such as Services and the resources that
+	// configure them.
+	Objects []unstructured.Unstructured
+	// Exclusions are namespace/name glob patterns of the Ingresses left out
+	// of the conversion entirely, such as the workloads that will never
+	// migrate or are handled separately. See ParseExclusions.
+	Exclusions []string
+	// Checkpoint, if set, records the Ingresses converted by ConvertStream
+	// and skips the ones a previous conversion recorded.
+	Checkpoint *Checkpoint
+	// TargetImplementation, if set, is the Gateway API implementation the
+	// output is tailored for. Its policies are returned in
+	// Resources.CustomResources. See TargetImplementations.
+	TargetImplementation string
+	// Experimental enables fields of the experimental channel of Gateway
+	// API, such as HTTPRoute retries, for the features the target
+	// implementation doesn't convert to policies. It enables every
+	// experimental feature.
+	Experimental bool
+	// ExperimentalFeatures are the names of the features outside of the
+	// standard channel of Gateway API to enable, see ExperimentalFeatures.
+	// The features not enabled are converted to standard resources or
+	// reported instead.
+	ExperimentalFeatures []string
+	// ListenerStrategy selects how the listeners of Gateways are generated.
+	// It defaults to ListenerPerHost.
+	ListenerStrategy ListenerStrategy
+	// ListenerPorts are the ports of the HTTP and HTTPS listeners of
+	// Gateways. They default to 80 and 443.
+	ListenerPorts ListenerPorts
+	// ClassListenerPorts override ListenerPorts for the Gateways of
+	// IngressClasses, by name.
+	ClassListenerPorts map[string]ListenerPorts
+	// HTTPSOnly, if set, stops serving plain HTTP requests to the hosts
+	// served over HTTPS. Otherwise, only the hosts of Ingresses forcing SSL
+	// redirects redirect them.
+	HTTPSOnly HTTPSOnlyMode
+	// AttachToListeners attaches every HTTPRoute to the listeners generated
+	// for its host, with the sectionName and port of their parentRefs, so
+	// that it doesn't bind to the other listeners of the Gateway.
+	AttachToListeners bool
+	// NormalizeWeights, if set, is the total the weights of the backends of
+	// weighted HTTPRoute rules add up to. Every backend gets its exact share
+	// rounded down and the weight left goes to the backends with the largest
+	// remainders, the first ones on ties. Otherwise, weights add up to the
+	// weight totals of the canaries.
+	NormalizeWeights int32
+	// ConsolidateRules merges the backendRefs of a rule to the same
+	// backend, and the rules of an HTTPRoute differing only by their
+	// matches, such as the ones of Ingresses generated from a template.
+	ConsolidateRules bool
+	// PathCollisions selects how the paths of several Ingresses matching
+	// the same requests of a host with different backends are converted.
+	// It defaults to PathCollisionMergeWeighted.
+	PathCollisions PathCollisionPolicy
+	// IncludeMigrated converts the Ingresses annotated with
+	// MigratedToAnnotation by an earlier conversion too, instead of
+	// skipping them.
+	IncludeMigrated bool
+	// DisabledFeatures are the names of the features of Ingresses left out
+	// of the conversion, for users to handle them manually, see
+	// ConversionFeatures.
+	DisabledFeatures []string
+	// GatewayNamespace, if set, is the namespace of every Gateway, shared
+	// by the HTTPRoutes of all namespaces. Otherwise, each namespace gets
+	// its own Gateways.
+	GatewayNamespace string
+	// Workers is the number of goroutines converting Ingresses concurrently.
+	// It defaults to GOMAXPROCS.
+	Workers int
+	// Providers extract features from the annotations of Ingresses, in
+	// addition to the built-in providers.
+	Providers []Provider
+	// Emitters produce additional resources from the converted Ingresses,
+	// returned in Resources.CustomResources.
+	Emitters []Emitter
+	// Mode selects what is generated for the Ingresses that can't be fully
+	// converted. It defaults to ModeBestEffort.
+	Mode ConversionMode
+	// RouteNaming selects what the names of HTTPRoutes are derived from.
+	// It defaults to RouteNamingHost.
+	RouteNaming RouteNaming
+	// RouteStrategy selects how the rules of Ingresses are grouped into
+	// HTTPRoutes. It defaults to RoutePerHost.
+	RouteStrategy RouteStrategy
+	// MaxSupportLevel, if set, drops the features of Gateways and
+	// HTTPRoutes whose Gateway API support level is above it, for
+	// implementations only supporting the core features for instance.
+	MaxSupportLevel SupportLevel
+	// ExternalDNS selects how the external-dns annotations of Ingresses are
+	// propagated. It defaults to ExternalDNSCarry.
+	ExternalDNS ExternalDNSMode
+	// ProgressiveDelivery selects how the Ingresses managed by Argo
+	// Rollouts or Flagger are converted. It defaults to
+	// ProgressiveDeliveryFreeze.
+	ProgressiveDelivery ProgressiveDeliveryMode
+	// HostHeaders are how the controllers of the providers, by provider
+	// name, set the Host header of the requests sent to backends for the
+	// Ingresses that don't configure it. They default to
+	// HostHeaderPreserve, the behavior of Gateway API implementations.
+	HostHeaders map[string]HostHeaderMode
+	// TLSModes select whether the TLS connections to hosts, by hostname or
+	// wildcard hostname, are passed through to their backends with
+	// TLSRoutes or terminated by the Gateway, overriding the behavior of
+	// the Ingress controller. The tls-mode annotation of Ingresses takes
+	// precedence.
+	TLSModes map[string]gatewayv1.TLSModeType
+	// NamespaceMap moves the resources generated for the Ingresses of a
+	// namespace to another namespace, by source namespace, such as when
+	// consolidating applications. Their backendRefs and certificateRefs
+	// keep pointing at the source namespaces, allowed by ReferenceGrants.
+	NamespaceMap map[string]string
+	// GatewayLabels and GatewayAnnotations are set on every Gateway, such
+	// as the ones the provisioning automation of the target
+	// implementation relies on. GatewayInfrastructure sets them in the
+	// spec.infrastructure of Gateways too, which implementations set on
+	// the resources they provision, such as load balancer Services.
+	GatewayLabels         map[string]string
+	GatewayAnnotations    map[string]string
+	GatewayInfrastructure bool
+	// SourceChecksums annotates the Gateways and HTTPRoutes with the
+	// Ingresses they were converted from and their checksum, which Verify
+	// compares with the current Ingresses.
+	SourceChecksums bool
+	// ConfidenceAnnotations annotates the Gateways and HTTPRoutes with
+	// their confidence, also reported in Report.Confidence.
+	ConfidenceAnnotations bool
+	// SingleGateway, if set, merges the Gateways of every IngressClass into
+	// a single Gateway of this GatewayClass, which every HTTPRoute attaches
+	// to. It requires GatewayNamespace.
+	SingleGateway string
+	// DefaultCertificate, if set, is the Secret of the certificate served
+	// for the hosts of Ingress TLS entries without secretName, which
+	// Ingress controllers serve their default certificate for.
+	DefaultCertificate types.NamespacedName
+	// ExternalNameBackends routes the backendRefs of ExternalName Services
+	// to backends of the target implementation for their external host,
+	// such as Envoy Gateway Backends, which it emits.
+	ExternalNameBackends bool
+	// NameGenerator, if set, names the generated Gateways and HTTPRoutes
+	// instead of the default names.
+	NameGenerator NameGenerator
+	// Cache, if set, holds the results of previous conversions so that only
+	// the namespaces whose Ingresses changed are converted again. It
+	// requires GatewayNamespace to be empty.
+	Cache *ConversionCache
+	// CheckGatewayClasses checks that the GatewayClass of every generated
+	// Gateway is one of GatewayClasses, the GatewayClasses of the target
+	// cluster. Missing GatewayClasses are errors without
+	// GatewayClassController.
+	CheckGatewayClasses bool
+	GatewayClasses      []string
+	// GatewayClassController, if set, generates the missing GatewayClasses,
+	// or all of them without CheckGatewayClasses, for this controller name.
+	GatewayClassController string
+	// Capabilities, if set, are the resources the target cluster serves.
+	// The output is tailored to them and every downgraded feature is
+	// reported.
+	Capabilities *Capabilities
+}
+
+// ListenerStrategy selects how the listeners of Gateways are generated.
+type ListenerStrategy string
+
+const (
+	// ListenerPerHost generates listeners for every host of the Ingresses.
+	ListenerPerHost ListenerStrategy = "host"
+	// ListenerPerCertificate generates a single listener for the hosts
+	// covered by the wildcard host of a TLS certificate, which reduces the
+	// number of listeners of Gateways with wildcard certificates. Other
+	// hosts get their own listeners.
+	ListenerPerCertificate ListenerStrategy = "certificate"
+)
+
+// ListenerStrategies returns the names of the supported listener strategies.
+func ListenerStrategies() []string {
+	return []string{string(ListenerPerHost), string(ListenerPerCertificate)}
+}
+
+func validateListenerStrategy(strategy ListenerStrategy) error {
+	switch strategy {
+	case "", ListenerPerHost, ListenerPerCertificate:
+		return nil
+	default:
+		return fmt.Errorf("unknown listener strategy %q, supported ones are: %s", strategy, strings.Join(ListenerStrategies(), ", "))
+	}
+}
+
+// HTTPSOnlyMode selects how plain HTTP requests to the hosts served over
+// HTTPS are handled.
+type HTTPSOnlyMode string
+
+const (
+	// HTTPSOnlyOmit omits the HTTP listeners of the hosts served over HTTPS.
+	HTTPSOnlyOmit HTTPSOnlyMode = "omit"
+	// HTTPSOnlyRedirect redirects the requests to the HTTP listeners of the
+	// hosts served over HTTPS to HTTPS.
+	HTTPSOnlyRedirect HTTPSOnlyMode = "redirect"
+)
+
+func validateNormalizeWeights(total int32) error {
+	if total < 0 || total > maxBackendWeight {
+		return fmt.Errorf("invalid weight total %d, it must be between 1 and %d", total, maxBackendWeight)
+	}
+	return nil
+}
+
+func validateGatewayNamespace(namespace string) error {
+	if namespace == "" {
+		return nil
+	}
+	if msgs := apimachineryvalidation.IsDNS1123Label(namespace); len(msgs) > 0 {
+		return fmt.Errorf("invalid Gateway namespace %q: %s", namespace, strings.Join(msgs, ", "))
+	}
+	return nil
+}
+
+func validateHTTPSOnlyMode(mode HTTPSOnlyMode) error {
+	switch mode {
+	case "", HTTPSOnlyOmit, HTTPSOnlyRedirect:
+		return nil
+	default:
+		return fmt.Errorf("unknown HTTPS only mode %q, supported ones are: %s, %s", mode, HTTPSOnlyOmit, HTTPSOnlyRedirect)
+	}
+}
+
+const (
+	defaultHTTPPort  = 80
+	defaultHTTPSPort = 443
+)
+
+// ListenerPorts are the ports Gateways accept HTTP and HTTPS traffic on, for
+// instance when they run behind an external load balancer. Zero ports are
+// unset.
+type ListenerPorts struct {
+	HTTP  int32
+	HTTPS int32
+}
+
+// ParseListenerPorts parses listener ports formatted as HTTP:HTTPS, either of
+// which may be omitted, such as "8080:8443" or ":8443".
+func ParseListenerPorts(s string) (ListenerPorts, error) {
+	httpPort, httpsPort, found := strings.Cut(s, ":")
+	if !found {
+		return ListenerPorts{}, fmt.Errorf("invalid listener ports %q, expected HTTP:HTTPS ports", s)
+	}
+	var ports ListenerPorts
+	for _, p := range []struct {
+		value string
+		port  *int32
+	}{{httpPort, &ports.HTTP}, {httpsPort, &ports.HTTPS}} {
+		if p.value == "" {
+			continue
+		}
+		port, err := strconv.ParseInt(p.value, 10, 32)
+		if err != nil || port < 1 || port > 65535 {
+			return ListenerPorts{}, fmt.Errorf("invalid port %q in listener ports %q", p.value, s)
+		}
+		*p.port = int32(port)
+	}
+	return ports, nil
+}
+
+// ParseDefaultCertificate parses the Secret of a default certificate
+// formatted as namespace/name.
+func ParseDefaultCertificate(s string) (types.NamespacedName, error) {
+	namespace, name, found := strings.Cut(s, "/")
+	if !found || namespace == "" || name == "" {
+		return types.NamespacedName{}, fmt.Errorf("invalid default certificate %q, expected namespace/name", s)
+	}
+	if msgs := apimachineryvalidation.IsDNS1123Label(namespace); len(msgs) > 0 {
+		return types.NamespacedName{}, fmt.Errorf("invalid namespace of default certificate %q: %s", s, strings.Join(msgs, ", "))
+	}
+	if msgs := apimachineryvalidation.IsDNS1123Subdomain(name); len(msgs) > 0 {
+		return types.NamespacedName{}, fmt.Errorf("invalid name of default certificate %q: %s", s, strings.Join(msgs, ", "))
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}, nil
+}
+
+// Resources are the Gateway API resources generated by a conversion.
+type Resources struct {
+	// GatewayClasses are only generated with
+	// ConvertOptions.GatewayClassController.
+	GatewayClasses []gatewayv1.GatewayClass
+	Gateways       []gatewayv1.Gateway
+	HTTPRoutes     []gatewayv1.HTTPRoute
+	// TLSRoutes and TCPRoutes are only generated with
+	// ConvertOptions.Experimental.
+	TLSRoutes       []gatewayv1alpha2.TLSRoute
+	TCPRoutes       []gatewayv1alpha2.TCPRoute
+	ReferenceGrants []gatewayv1beta1.ReferenceGrant
+	// BackendLBPolicies are only generated with ConvertOptions.Experimental.
+	BackendLBPolicies []gatewayv1alpha2.BackendLBPolicy
+	// BackendTLSPolicies are only generated with ConvertOptions.Experimental.
+	BackendTLSPolicies []gatewayv1alpha3.BackendTLSPolicy
+	// CustomResources are the resources produced by ConvertOptions.Emitters.
+	CustomResources []unstructured.Unstructured
+	// Sources are the Ingresses the Gateways and HTTPRoutes were converted
+	// from.
+	Sources map[ObjectRef][]IngressSource
+}
+
+// Report describes the parts of the input that could not be converted as
+// well as anything else the user should review before applying Resources.
+type Report struct {
+	Notifications []Notification
+	// Errors are the parts of the input that failed to convert. They are
+	// not fatal, the rest of the input is still converted.
+	Errors []error
+	// UnsupportedAnnotations are the implementation-specific annotations
+	// that were ignored. Each is also reported as a notification.
+	UnsupportedAnnotations []UnsupportedAnnotation
+	// DisabledAnnotations are the annotations of the features disabled by
+	// ConvertOptions.DisabledFeatures, which are intentionally not
+	// converted. Each disabled feature of an Ingress is also reported as a
+	// notification.
+	DisabledAnnotations []DisabledAnnotation
+	// SkippedIngresses are the Ingresses no Gateway or HTTPRoute was
+	// converted from, such as Ingresses without rules or whose rules all
+	// failed to convert.
+	SkippedIngresses []types.NamespacedName
+	// ExcludedIngresses are the Ingresses matching
+	// ConvertOptions.Exclusions, which are not converted nor reported
+	// otherwise.
+	ExcludedIngresses []types.NamespacedName
+	// Confidence is the confidence of every Gateway and HTTPRoute converted
+	// from Ingresses, for reviewers to verify the least confident ones
+	// first.
+	Confidence map[ObjectRef]ResourceConfidence
+	// WeightSplits are the splits of the requests of the HTTPRoute rules
+	// between several backends or weighted ones.
+	WeightSplits []WeightSplit
+	// SupportLevels are the Gateway API features every Gateway and
+	// HTTPRoute uses, with their support level.
+	SupportLevels map[ObjectRef][]FeatureSupport
+}
+
+// UnsupportedAnnotation is an annotation of an Ingress that has no Gateway
+// API equivalent in the conversion.
+type UnsupportedAnnotation struct {
+	Ingress    types.NamespacedName
+	Annotation string
+}
+
+// validateConvertOptions checks the options of the conversion of the
+// Ingresses, whichever way they are read.
+func validateConvertOptions(opts ConvertOptions) error {
+	if _, err := lookupTargetImplementation(opts.TargetImplementation); err != nil {
+		return err
+	}
+	if err := validateListenerStrategy(opts.ListenerStrategy); err != nil {
+		return err
+	}
+	if err := validateHTTPSOnlyMode(opts.HTTPSOnly); err != nil {
+		return err
+	}
+	if err := validateNormalizeWeights(opts.NormalizeWeights); err != nil {
+		return err
+	}
+	if err := validatePathCollisionPolicy(opts.PathCollisions); err != nil {
+		return err
+	}
+	if err := validateDisabledFeatures(opts.DisabledFeatures); err != nil {
+		return err
+	}
+	if err := validateExperimentalFeatures(opts.ExperimentalFeatures); err != nil {
+		return err
+	}
+	if err := validateExclusions(opts.Exclusions); err != nil {
+		return err
+	}
+	if err := validateGatewayNamespace(opts.GatewayNamespace); err != nil {
+		return err
+	}
+	if err := validateConversionMode(opts.Mode); err != nil {
+		return err
+	}
+	if err := validateRouteNaming(opts.RouteNaming); err != nil {
+		return err
+	}
+	if err := validateRouteStrategy(opts.RouteStrategy); err != nil {
+		return err
+	}
+	if err := validateMaxSupportLevel(opts.MaxSupportLevel); err != nil {
+		return err
+	}
+	if err := validateExternalDNSMode(opts.ExternalDNS); err != nil {
+		return err
+	}
+	if err := validateProgressiveDeliveryMode(opts.ProgressiveDelivery); err != nil {
+		return err
+	}
+	if err := validateHostHeaders(opts.HostHeaders, append(builtinProviders(), opts.Providers...)); err != nil {
+		return err
+	}
+	if err := validateTLSModes(opts.TLSModes); err != nil {
+		return err
+	}
+	if err := validateNamespaceMap(opts.NamespaceMap); err != nil {
+		return err
+	}
+	if err := validateGatewayMetadata(opts.GatewayLabels, opts.GatewayAnnotations, opts.GatewayInfrastructure); err != nil {
+		return err
+	}
+	if err := validateSingleGateway(opts.SingleGateway, opts.GatewayNamespace); err != nil {
+		return err
+	}
+	if err := validateGatewayClassController(opts.GatewayClassController); err != nil {
+		return err
+	}
+	if err := validateExternalNameBackends(opts.ExternalNameBackends, opts.TargetImplementation); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert converts the Ingresses read according to opts to Gateway API
+// resources. An error is only returned when the input can't be read.
+func Convert(ctx context.Context, opts ConvertOptions) (Resources, Report, error) {
+	var report Report
+
+	if err := validateConvertOptions(opts); err != nil {
+		return Resources{}, report, err
+	}
+	target := targetImplementations[opts.TargetImplementation]
+	if opts.Cache != nil && opts.GatewayNamespace != "" {
+		return Resources{}, report, fmt.Errorf("caching conversions doesn't support Gateways shared by several namespaces")
+	}
+	if opts.Cache != nil && len(opts.NamespaceMap) > 0 {
+		return Resources{}, report, fmt.Errorf("caching conversions doesn't support namespace maps")
+	}
+
+	input, inputNotifications, err := readConvertInput(ctx, opts, target)
 	if err != nil {
-		fmt.Println("failed to create client")
-		os.Exit(1)
+		return Resources{}, report, err
+	}
+	if err := ctx.Err(); err != nil {
+		return Resources{}, report, err
 	}
+	report.Notifications = inputNotifications
+
+	var resources Resources
+	var conversionReport Report
+	if opts.Cache != nil {
+		resources, conversionReport = opts.Cache.convert(input, opts)
+	} else {
+		resources, conversionReport = convertInput(input, opts)
+	}
+	report.Notifications = append(report.Notifications, conversionReport.Notifications...)
+	if opts.Client != nil {
+		report.Notifications = append(report.Notifications, checkCertificateRefs(ctx, opts.Client, resources.Gateways)...)
+	}
+	report.Errors = conversionReport.Errors
+	report.UnsupportedAnnotations = conversionReport.UnsupportedAnnotations
+	report.DisabledAnnotations = conversionReport.DisabledAnnotations
+	report.SkippedIngresses = conversionReport.SkippedIngresses
+	report.ExcludedIngresses = conversionReport.ExcludedIngresses
+	report.Confidence = conversionReport.Confidence
+	report.WeightSplits = conversionReport.WeightSplits
+	report.SupportLevels = conversionReport.SupportLevels
+	return resources, report, nil
+}
+
+// readConvertInput reads the Ingresses, IngressClasses and other objects of
+// opts from every configured source, with warnings about the objects of the
+// cluster that couldn't be listed.
+func readConvertInput(ctx context.Context, opts ConvertOptions, target targetImplementation) (inputResources, []Notification, error) {
+	input := inputResources{
+		ingresses:      opts.Ingresses,
+		ingressClasses: opts.IngressClasses,
+		objects:        opts.Objects,
+	}
+	var notes []Notification
+	if opts.InputFile != "" {
+		fileInput, err := readInputFromFile(opts.InputFile)
+		if err != nil {
+			return input, notes, fmt.Errorf("failed to read input from %s: %w", opts.InputFile, err)
+		}
+		input.ingresses = append(input.ingresses, fileInput.ingresses...)
+		input.ingressClasses = append(input.ingressClasses, fileInput.ingressClasses...)
+		input.objects = append(input.objects, fileInput.objects...)
+	}
+	if opts.Input != nil {
+		streamInput, err := decodeInput(opts.Input)
+		if err != nil {
+			return input, notes, err
+		}
+		input.ingresses = append(input.ingresses, streamInput.ingresses...)
+		input.ingressClasses = append(input.ingressClasses, streamInput.ingressClasses...)
+		input.objects = append(input.objects, streamInput.objects...)
+	}
+
+	if opts.Client != nil {
+		clusterInput, clusterNotifications, err := readInputFromCluster(ctx, opts.Client, target, "")
+		if err != nil {
+			return input, notes, err
+		}
+		notes = append(notes, clusterNotifications...)
+		input.ingresses = append(input.ingresses, clusterInput.ingresses...)
+		input.ingressClasses = append(input.ingressClasses, clusterInput.ingressClasses...)
+		input.objects = append(input.objects, clusterInput.objects...)
+	}
+	return input, notes, nil
+}
+
+// readInputFromCluster lists the Ingresses of the namespace, or of every
+// namespace if it is empty, the IngressClasses, the Services and the objects
+// of the input kinds of the target implementation. Only failing to list
+// Ingresses is an error, the other failures are returned as warnings.
+func readInputFromCluster(ctx context.Context, cl client.Client, target targetImplementation, namespace string) (inputResources, []notifications.Notification, error) {
+	var input inputResources
+	var warnings []notifications.Notification
 
 	ingressList := &networkingv1.IngressList{}
+	if err := cl.List(ctx, ingressList, client.InNamespace(namespace)); err != nil {
+		return input, warnings, fmt.Errorf("failed to list ingresses: %w", err)
+	}
+	input.ingresses = ingressList.Items
+
+	ingressClassList := &networkingv1.IngressClassList{}
+	if err := cl.List(ctx, ingressClassList); err != nil {
+		warnings = append(warnings, notifications.NewWarning("Failed to list IngressClasses, continuing without them: %v", err))
+	}
+	input.ingressClasses = ingressClassList.Items
+
+	inputKinds := target.inputKinds
+	if !slices.Contains(inputKinds, serviceGVK) {
+		// Services tell which backends are ExternalName Services.
+		inputKinds = append(slices.Clip(inputKinds), serviceGVK)
+	}
+	for _, gvk := range inputKinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := cl.List(ctx, list, client.InNamespace(namespace)); meta.IsNoMatchError(err) {
+			// The cluster doesn't serve the kind, so there is none.
+			continue
+		} else if err != nil {
+			warnings = append(warnings, notifications.NewWarning("Failed to list %s, continuing without them: %v", gvk.Kind, err))
+			continue
+		}
+		for _, item := range list.Items {
+			item.SetGroupVersionKind(gvk)
+			input.objects = append(input.objects, item)
+		}
+	}
+	return input, warnings, nil
+}
+
+// RunOptions configures the command line conversion.
+type RunOptions struct {
+	// InputFile is the path of a manifest file to read Ingresses from. If
+	// empty, and without a HelmChart, Ingresses are read from the cluster of
+	// the current kubeconfig.
+	InputFile string
+	// HelmChart, if its Chart is set, is rendered with helm template and
+	// its objects are converted instead of the Ingresses of the cluster,
+	// along with the ones of the InputFile.
+	HelmChart HelmChartOptions
+	// Stream converts the InputFile one namespace at a time, writing the
+	// output of each namespace as soon as it is converted.
+	Stream bool
+	// CheckpointFile, if set, is the path of the Checkpoint of a Stream
+	// conversion, resuming the conversion it records.
+	CheckpointFile string
+	// TargetImplementation is the Gateway API implementation the output is
+	// tailored for.
+	TargetImplementation string
+	// Experimental enables fields of the experimental channel of Gateway
+	// API.
+	Experimental bool
+	// ExperimentalFeatures are the experimental features to enable when
+	// Experimental isn't set.
+	ExperimentalFeatures []string
+	// ListenerStrategy selects how the listeners of Gateways are generated.
+	ListenerStrategy ListenerStrategy
+	// ListenerPorts and ClassListenerPorts are the ports of the listeners
+	// of Gateways.
+	ListenerPorts      ListenerPorts
+	ClassListenerPorts map[string]ListenerPorts
+	// HTTPSOnly stops serving plain HTTP requests to the hosts served over
+	// HTTPS.
+	HTTPSOnly HTTPSOnlyMode
+	// AttachToListeners attaches every HTTPRoute to the listeners
+	// generated for its host.
+	AttachToListeners bool
+	// NormalizeWeights is the total the backend weights add up to.
+	NormalizeWeights int32
+	// ConsolidateRules merges the identical rules and backendRefs of
+	// HTTPRoutes.
+	ConsolidateRules bool
+	// PathCollisions selects how colliding paths of Ingresses are
+	// converted.
+	PathCollisions PathCollisionPolicy
+	// IncludeMigrated converts the Ingresses annotated as migrated too.
+	IncludeMigrated bool
+	// DisabledFeatures are the features left out of the conversion.
+	DisabledFeatures []string
+	// Exclusions are namespace/name glob patterns of the Ingresses left
+	// out of the conversion.
+	Exclusions []string
+	// GatewayNamespace is the namespace of every Gateway.
+	GatewayNamespace string
+	// Annotate precedes Gateways and HTTPRoutes with comments about what
+	// they were converted from.
+	Annotate bool
+	// Output is the format the resources are written in. It defaults to
+	// OutputYAML.
+	Output OutputFormat
+	// OutputLayout is where the resources are written. It defaults to
+	// LayoutStdout. The report is always written to stdout.
+	OutputLayout OutputLayout
+	// OutputDir is the directory of the LayoutGitOps and LayoutNamespaces
+	// layouts.
+	OutputDir string
+	// ArgoCD annotates the resources of the LayoutGitOps layout with Argo
+	// CD sync waves.
+	ArgoCD bool
+	// Summary writes a human-readable summary of the conversion to stderr,
+	// after the output.
+	Summary bool
+	// Mode selects what is generated for the Ingresses that can't be fully
+	// converted.
+	Mode ConversionMode
+	// RouteNaming selects what the names of HTTPRoutes are derived from.
+	RouteNaming RouteNaming
+	// RouteStrategy selects how the rules of Ingresses are grouped into
+	// HTTPRoutes.
+	RouteStrategy RouteStrategy
+	// MaxSupportLevel drops the features above the Gateway API support
+	// level.
+	MaxSupportLevel SupportLevel
+	// ExternalDNS selects how the external-dns annotations of Ingresses are
+	// propagated.
+	ExternalDNS ExternalDNSMode
+	// ProgressiveDelivery selects how the Ingresses managed by Argo
+	// Rollouts or Flagger are converted.
+	ProgressiveDelivery ProgressiveDeliveryMode
+	// HostHeaders are how the controllers of the providers, by provider
+	// name, set the Host header of the requests sent to backends.
+	HostHeaders map[string]HostHeaderMode
+	// TLSModes select whether the TLS connections to hosts are passed
+	// through to their backends or terminated by the Gateway.
+	TLSModes map[string]gatewayv1.TLSModeType
+	// NamespaceMap moves the resources generated for the Ingresses of a
+	// namespace to another namespace, by source namespace.
+	NamespaceMap map[string]string
+	// GatewayLabels and GatewayAnnotations are set on every Gateway, and
+	// in their spec.infrastructure with GatewayInfrastructure.
+	GatewayLabels         map[string]string
+	GatewayAnnotations    map[string]string
+	GatewayInfrastructure bool
+	// SourceChecksums annotates the Gateways and HTTPRoutes with the
+	// checksum of their Ingresses.
+	SourceChecksums bool
+	// ConfidenceAnnotations annotates the Gateways and HTTPRoutes with
+	// their confidence.
+	ConfidenceAnnotations bool
+	// Clean writes the resources without status and the metadata populated
+	// by the API server.
+	Clean bool
+	// Template is the path of a text/template rendering the resources and
+	// the report instead of the output format, see TemplateData.
+	Template string
+	// SingleGateway merges the Gateways of every IngressClass into a single
+	// Gateway of this GatewayClass.
+	SingleGateway string
+	// DefaultCertificate is the Secret of the certificate served for the
+	// hosts of Ingress TLS entries without secretName.
+	DefaultCertificate types.NamespacedName
+	// ExternalNameBackends routes the backendRefs of ExternalName Services
+	// to backends of the target implementation.
+	ExternalNameBackends bool
+	// CapacityReport writes the numbers of resources generated by namespace
+	// and GatewayClass to stderr, after the output.
+	CapacityReport bool
+	// WeightReport writes the share of the requests of every backend of
+	// the HTTPRoute rules splitting them to stderr, after the output.
+	WeightReport bool
+	// Findings writes the errors, notifications, unsupported annotations and
+	// skipped Ingresses of the conversion in this format, after the output.
+	Findings FindingsFormat
+	// FindingsFile is the path the findings are written to. They are
+	// written to stderr if empty.
+	FindingsFile string
+	// HostMapping writes the hostname, Gateway, listener and certificates
+	// of every listener generated in this format, after the output.
+	HostMapping HostMappingFormat
+	// HostMappingFile is the path the host mapping is written to. It is
+	// written to stderr if empty.
+	HostMappingFile string
+	// Stats writes the numbers of Ingresses converted, partially converted,
+	// blocked, skipped and excluded, in total and by namespace, as a JSON
+	// document after the output.
+	Stats bool
+	// StatsFile is the path the statistics are written to. They are
+	// written to stderr if empty.
+	StatsFile string
+	// Clock tells the time the statistics and the fleet report are
+	// generated at, the system clock if nil.
+	Clock Clock
+	// Providers extract features from the annotations of Ingresses, in
+	// addition to the built-in providers, such as provider plugins.
+	Providers []Provider
+	// SourceContext is the kubeconfig context of the cluster Ingresses are
+	// read from, instead of the current context.
+	SourceContext string
+	// FleetContexts, if set, are the kubeconfig contexts of clusters to
+	// convert one after the other, each to a directory of OutputDir, with a
+	// consolidated report in FleetReportFile.
+	FleetContexts []string
+	// TargetContext, if set, is the kubeconfig context of the cluster the
+	// resources are applied to with Server-Side Apply, after the output.
+	TargetContext string
+	// TargetDryRun only validates the resources with the API server of the
+	// TargetContext, without persisting them.
+	TargetDryRun bool
+	// TargetWait, if set, is how long to wait for the Gateways and routes
+	// applied to the TargetContext to be accepted, reporting the ones the
+	// target implementation rejected as an error.
+	TargetWait time.Duration
+	// MarkMigrated annotates the Ingresses of the source cluster with the
+	// routes they were converted to, after the output and the apply to the
+	// TargetContext, if any. It only validates the annotations with
+	// TargetDryRun.
+	MarkMigrated bool
+	// CleanupFile, if set, is the path of a shell script deleting the
+	// Ingresses converted in full, written after the output, see Cleanup.
+	CleanupFile string
+	// CleanupVerify only deletes the Ingresses whose routes are accepted in
+	// the TargetContext, or the source cluster if empty, in the
+	// CleanupFile.
+	CleanupVerify bool
+	// CheckGatewayClasses checks that the GatewayClasses of the Gateways
+	// exist in the TargetContext, or the source cluster if empty.
+	CheckGatewayClasses bool
+	// GatewayClassController generates the missing GatewayClasses for this
+	// controller name.
+	GatewayClassController string
+	// DiscoverCapabilities tailors the output to the Gateway API CRDs
+	// installed in the TargetContext, or the source cluster if empty.
+	DiscoverCapabilities bool
+	// Timeout, if set, bounds the whole run, from reading the Ingresses to
+	// applying the resources, which is otherwise only cancelled on
+	// interrupt.
+	Timeout time.Duration
+	// CacheDir, if set, is the directory caching the output of the
+	// conversions of an InputFile by digest of the file, the options and
+	// the binary, for repeated runs over unchanged input to replay it.
+	CacheDir string
+}
+
+func Run(runOpts RunOptions) {
+	if err := validateOutputFormat(runOpts.Output); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err := validateOutputLayout(runOpts.OutputLayout, runOpts.OutputDir); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err := validateFindingsFormat(runOpts.Findings); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err := validateHostMappingFormat(runOpts.HostMapping); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if (isGraphFormat(runOpts.Output) || runOpts.Output == OutputList) && isDirectoryLayout(runOpts.OutputLayout) {
+		fmt.Printf("the %s output format doesn't support the %s output layout\n", runOpts.Output, runOpts.OutputLayout)
+		os.Exit(1)
+	}
+	var tmpl *template.Template
+	if runOpts.Template != "" {
+		if (runOpts.Output != "" && runOpts.Output != OutputYAML) || isDirectoryLayout(runOpts.OutputLayout) {
+			fmt.Println("a template can't be combined with another output format or the gitops and namespaces output layouts")
+			os.Exit(1)
+		}
+		var err error
+		if tmpl, err = ParseTemplateFile(runOpts.Template); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+	if runOpts.SourceContext != "" && (runOpts.InputFile != "" || runOpts.HelmChart.Chart != "") {
+		fmt.Println("a source context can't be combined with an input file or a Helm chart")
+		os.Exit(1)
+	}
+	if runOpts.HelmChart.Chart != "" && runOpts.Stream {
+		fmt.Println("a Helm chart can't be combined with streaming")
+		os.Exit(1)
+	}
+	if runOpts.TargetWait > 0 && (runOpts.TargetContext == "" || runOpts.TargetDryRun) {
+		fmt.Println("waiting for the resources to be accepted requires a target context without dry run")
+		os.Exit(1)
+	}
+	if runOpts.TargetDryRun && runOpts.TargetContext == "" {
+		fmt.Println("a target dry run requires a target context")
+		os.Exit(1)
+	}
+	if runOpts.MarkMigrated && (runOpts.InputFile != "" || runOpts.HelmChart.Chart != "") {
+		fmt.Println("marking Ingresses as migrated requires reading them from the cluster")
+		os.Exit(1)
+	}
+	if runOpts.CleanupVerify && runOpts.CleanupFile == "" {
+		fmt.Println("verifying the routes of the cleanup requires a cleanup file")
+		os.Exit(1)
+	}
+	if runOpts.CleanupVerify && runOpts.TargetDryRun {
+		fmt.Println("verifying the routes of the cleanup can't be combined with a target dry run")
+		os.Exit(1)
+	}
+	if runOpts.CleanupFile != "" && runOpts.Stream {
+		fmt.Println("a cleanup file can't be combined with streaming")
+		os.Exit(1)
+	}
+	if runOpts.CheckpointFile != "" && !runOpts.Stream {
+		fmt.Println("a checkpoint file requires streaming")
+		os.Exit(1)
+	}
+	if runOpts.Output == OutputList && runOpts.Stream {
+		fmt.Printf("the %s output format can't be streamed\n", runOpts.Output)
+		os.Exit(1)
+	}
+	if err := validateOutputCache(runOpts); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err := validateFleet(runOpts); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	ctx, cancel := runContext(runOpts.Timeout)
+	defer cancel()
+	if len(runOpts.FleetContexts) > 0 {
+		if err := runFleet(ctx, os.Stdout, runOpts, tmpl, runClock(runOpts).Now()); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+	if runOpts.Stream {
+		if err := runStream(ctx, runOpts, tmpl); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	err = cl.List(context.Background(), ingressList)
+	stdout, stderr := io.Writer(os.Stdout), io.Writer(os.Stderr)
+	var cacheKey string
+	var cachedStdout, cachedStderr bytes.Buffer
+	if runOpts.CacheDir != "" {
+		var err error
+		if cacheKey, err = outputCacheKey(runOpts); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if out, ok := readCachedOutput(runOpts.CacheDir, cacheKey); ok {
+			os.Stdout.Write(out.Stdout)
+			os.Stderr.Write(out.Stderr)
+			return
+		}
+		stdout = io.MultiWriter(os.Stdout, &cachedStdout)
+		stderr = io.MultiWriter(os.Stderr, &cachedStderr)
+	}
+
+	opts := runConvertOptions(runOpts)
+	opts.InputFile = runOpts.InputFile
+	if runOpts.CheckGatewayClasses {
+		gatewayClasses, err := targetGatewayClasses(ctx, runOpts)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		opts.GatewayClasses = gatewayClasses
+	}
+	if runOpts.DiscoverCapabilities {
+		caps, err := targetCapabilities(ctx, runOpts)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		opts.Capabilities = caps
+	}
+	if runOpts.HelmChart.Chart != "" {
+		objects, err := RenderHelmChart(ctx, runOpts.HelmChart)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		var chartInput inputResources
+		for _, obj := range objects {
+			if err := chartInput.add(obj); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+		opts.Ingresses, opts.IngressClasses, opts.Objects = chartInput.ingresses, chartInput.ingressClasses, chartInput.objects
+	} else if runOpts.InputFile == "" {
+		cl, err := newClient(runOpts.SourceContext, client.Options{})
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		opts.Client = cl
+	}
+
+	resources, report, err := Convert(ctx, opts)
 	if err != nil {
-		fmt.Printf("failed to list ingresses: %v\n", err)
+		fmt.Println(err)
 		os.Exit(1)
 	}
 
-	outputResult(ingresses2GatewaysAndHttpRoutes(ingressList.Items))
+	sink, err := runSink(runOpts, tmpl, stdout, stderr)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err := sink.Write(ctx, resources, report); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err := sink.Close(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if runOpts.CacheDir != "" {
+		if err := writeCachedOutput(runOpts.CacheDir, cacheKey, cachedOutput{Stdout: cachedStdout.Bytes(), Stderr: cachedStderr.Bytes()}); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+	if runOpts.Summary {
+		WriteSummary(os.Stderr, resources, report, colorOutput(os.Stderr))
+	}
+	if runOpts.CapacityReport {
+		WriteCapacityReport(os.Stderr, resources)
+	}
+	if runOpts.WeightReport {
+		WriteWeightSplits(os.Stderr, report.WeightSplits)
+	}
+	if runOpts.Findings != "" {
+		findings := &findingsReport{}
+		findings.add(report)
+		if err := writeFindingsFile(runOpts, findings); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+	if runOpts.HostMapping != "" {
+		hostMapping := &hostMappingReport{}
+		hostMapping.add(resources)
+		if err := writeHostMappingFile(runOpts, hostMapping); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+	if runOpts.Stats {
+		stats := newStatsReport()
+		stats.add(resources, report)
+		if err := writeStatsFile(runOpts, stats); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+	if runOpts.TargetContext != "" {
+		if err := applyToContext(ctx, os.Stderr, runOpts.TargetContext, resources, runOpts.TargetDryRun, runOpts.TargetWait); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+	if runOpts.MarkMigrated {
+		if err := markMigrated(ctx, os.Stderr, opts.Client, resources, runOpts.TargetDryRun); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+	if runOpts.CleanupFile != "" {
+		var cl client.Client
+		if runOpts.CleanupVerify {
+			var err error
+			if cl, err = newClient(targetKubeContext(runOpts), client.Options{}); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+		if err := writeCleanupFile(ctx, runOpts.CleanupFile, cl, resources, report); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
 }
 
-func ingresses2GatewaysAndHttpRoutes(ingresses []networkingv1.Ingress) ([]gatewayv1beta1.HTTPRoute, []gatewayv1beta1.Gateway, []error) {
-	aggregator := ingressAggregator{ruleGroups: map[ruleGroupKey]*ingressRuleGroup{}}
+// runConvertOptions returns the options of the conversion of a run, without
+// its input.
+func runConvertOptions(runOpts RunOptions) ConvertOptions {
+	return ConvertOptions{
+		TargetImplementation:   runOpts.TargetImplementation,
+		Experimental:           runOpts.Experimental,
+		ExperimentalFeatures:   runOpts.ExperimentalFeatures,
+		ListenerStrategy:       runOpts.ListenerStrategy,
+		ListenerPorts:          runOpts.ListenerPorts,
+		ClassListenerPorts:     runOpts.ClassListenerPorts,
+		HTTPSOnly:              runOpts.HTTPSOnly,
+		AttachToListeners:      runOpts.AttachToListeners,
+		NormalizeWeights:       runOpts.NormalizeWeights,
+		ConsolidateRules:       runOpts.ConsolidateRules,
+		PathCollisions:         runOpts.PathCollisions,
+		IncludeMigrated:        runOpts.IncludeMigrated,
+		DisabledFeatures:       runOpts.DisabledFeatures,
+		Exclusions:             runOpts.Exclusions,
+		GatewayNamespace:       runOpts.GatewayNamespace,
+		Mode:                   runOpts.Mode,
+		RouteNaming:            runOpts.RouteNaming,
+		RouteStrategy:          runOpts.RouteStrategy,
+		MaxSupportLevel:        runOpts.MaxSupportLevel,
+		ExternalDNS:            runOpts.ExternalDNS,
+		ProgressiveDelivery:    runOpts.ProgressiveDelivery,
+		HostHeaders:            runOpts.HostHeaders,
+		TLSModes:               runOpts.TLSModes,
+		NamespaceMap:           runOpts.NamespaceMap,
+		GatewayLabels:          runOpts.GatewayLabels,
+		GatewayAnnotations:     runOpts.GatewayAnnotations,
+		GatewayInfrastructure:  runOpts.GatewayInfrastructure,
+		SourceChecksums:        runOpts.SourceChecksums,
+		ConfidenceAnnotations:  runOpts.ConfidenceAnnotations,
+		SingleGateway:          runOpts.SingleGateway,
+		DefaultCertificate:     runOpts.DefaultCertificate,
+		ExternalNameBackends:   runOpts.ExternalNameBackends,
+		Providers:              runOpts.Providers,
+		CheckGatewayClasses:    runOpts.CheckGatewayClasses,
+		GatewayClassController: runOpts.GatewayClassController,
+	}
+}
 
-	for _, ingress := range ingresses {
-		aggregator.addIngress(ingress)
+// runContext returns the context of a command, cancelled on interrupt and
+// after timeout, if set.
+func runContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	if timeout <= 0 {
+		return ctx, stop
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, func() {
+		cancel()
+		stop()
 	}
+}
 
-	return aggregator.toHTTPRoutesAndGateways()
+func writeFindingsFile(runOpts RunOptions, findings *findingsReport) error {
+	if runOpts.FindingsFile == "" {
+		return findings.write(os.Stderr, runOpts.Findings, runOpts.InputFile)
+	}
+	f, err := os.Create(runOpts.FindingsFile)
+	if err != nil {
+		return fmt.Errorf("failed to create findings file: %w", err)
+	}
+	if err := findings.write(f, runOpts.Findings, runOpts.InputFile); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
 }
 
-func outputResult(httpRoutes []gatewayv1beta1.HTTPRoute, gateways []gatewayv1beta1.Gateway, errors []error) {
-	if len(errors) > 0 {
-		fmt.Printf("# Encountered %d errors\n", len(errors))
-		for _, err := range errors {
-			fmt.Printf("# %s", err)
-		}
+// runSink returns the sink of the output of a run, with the report written
+// next to the resources when they don't go to stdout.
+func runSink(runOpts RunOptions, tmpl *template.Template, stdout, stderr io.Writer) (OutputSink, error) {
+	writer := NewWriterSink(stdout, WriterSinkOptions{Output: runOpts.Output, Template: tmpl, Annotate: runOpts.Annotate, Clean: runOpts.Clean})
+	if tmpl != nil {
+		return writer, nil
 	}
-	y := printers.YAMLPrinter{}
-	for _, gateway := range gateways {
-		err := y.PrintObj(&gateway, os.Stdout)
+	if isGraphFormat(runOpts.Output) {
+		// The report goes to stderr to keep the graph renderable.
+		return MultiSink(reportSink{stderr}, writer), nil
+	}
+	if runOpts.OutputLayout == LayoutNamespaces || runOpts.OutputLayout == LayoutGitOps {
+		dir, err := NewDirectorySink(runOpts.OutputDir, DirectorySinkOptions{
+			Layout:   runOpts.OutputLayout,
+			Output:   runOpts.Output,
+			Annotate: runOpts.Annotate,
+			ArgoCD:   runOpts.ArgoCD,
+			Clean:    runOpts.Clean,
+		})
 		if err != nil {
-			fmt.Printf("# Error printing YAML for %s HTTPRoute: %v\n", gateway.Name, err)
+			return nil, err
 		}
+		return MultiSink(reportSink{stdout}, dir), nil
 	}
+	return writer, nil
+}
 
-	for _, httpRoute := range httpRoutes {
-		err := y.PrintObj(&httpRoute, os.Stdout)
+func runStream(ctx context.Context, runOpts RunOptions, tmpl *template.Template) error {
+	if runOpts.InputFile == "" {
+		return fmt.Errorf("streaming requires an input file")
+	}
+	f, err := os.Open(runOpts.InputFile)
+	if err != nil {
+		return fmt.Errorf("failed to open input file: %w", err)
+	}
+	defer f.Close()
+
+	opts := runConvertOptions(runOpts)
+	if runOpts.CheckGatewayClasses {
+		gatewayClasses, err := targetGatewayClasses(ctx, runOpts)
+		if err != nil {
+			return err
+		}
+		opts.GatewayClasses = gatewayClasses
+	}
+	if runOpts.DiscoverCapabilities {
+		caps, err := targetCapabilities(ctx, runOpts)
 		if err != nil {
-			fmt.Printf("# Error printing YAML for %s HTTPRoute: %v\n", httpRoute.Name, err)
+			return err
+		}
+		opts.Capabilities = caps
+	}
+	if runOpts.CheckpointFile != "" {
+		checkpoint, err := OpenCheckpoint(runOpts.CheckpointFile)
+		if err != nil {
+			return err
+		}
+		defer checkpoint.Close()
+		opts.Checkpoint = checkpoint
+	}
+	summary := newConversionSummary()
+	capacity := newCapacityReport()
+	findings := &findingsReport{}
+	hostMapping := &hostMappingReport{}
+	stats := newStatsReport()
+	var weightSplits []WeightSplit
+	sink, err := runSink(runOpts, tmpl, os.Stdout, os.Stderr)
+	if err != nil {
+		return err
+	}
+	err = ConvertStream(ctx, f, opts, func(resources Resources, report Report) error {
+		if err := sink.Write(ctx, resources, report); err != nil {
+			return err
+		}
+		summary.add(resources, report)
+		capacity.add(resources)
+		findings.add(report)
+		hostMapping.add(resources)
+		stats.add(resources, report)
+		weightSplits = append(weightSplits, report.WeightSplits...)
+		if runOpts.TargetContext != "" {
+			return applyToContext(ctx, os.Stderr, runOpts.TargetContext, resources, runOpts.TargetDryRun, runOpts.TargetWait)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := sink.Close(); err != nil {
+		return err
+	}
+	if runOpts.Summary {
+		summary.write(os.Stderr, colorOutput(os.Stderr))
+	}
+	if runOpts.CapacityReport {
+		capacity.write(os.Stderr)
+	}
+	if runOpts.WeightReport {
+		WriteWeightSplits(os.Stderr, weightSplits)
+	}
+	if runOpts.Findings != "" {
+		if err := writeFindingsFile(runOpts, findings); err != nil {
+			return err
+		}
+	}
+	if runOpts.HostMapping != "" {
+		if err := writeHostMappingFile(runOpts, hostMapping); err != nil {
+			return err
+		}
+	}
+	if runOpts.Stats {
+		return writeStatsFile(runOpts, stats)
+	}
+	return nil
+}
+
+func convertInput(input inputResources, opts ConvertOptions) (Resources, Report) {
+	capabilityNotes := opts.Capabilities.restrictOptions(&opts)
+	aggregator := newIngressAggregator(append(builtinProviders(), opts.Providers...))
+	if opts.Workers > 0 {
+		aggregator.workers = opts.Workers
+	}
+	aggregator.listenerStrategy = opts.ListenerStrategy
+	aggregator.listenerPorts = opts.ListenerPorts
+	aggregator.classListenerPorts = opts.ClassListenerPorts
+	aggregator.httpsOnly = opts.HTTPSOnly
+	aggregator.attachToListeners = opts.AttachToListeners
+	aggregator.experimental = enabledExperimentalFeatures(opts)
+	aggregator.normalizeWeights = opts.NormalizeWeights
+	aggregator.pathCollisions = opts.PathCollisions
+	aggregator.includeMigrated = opts.IncludeMigrated
+	aggregator.disabledFeatures = disabledConversionFeatures(opts.DisabledFeatures)
+	aggregator.gatewayNamespace = opts.GatewayNamespace
+	aggregator.routeNaming = opts.RouteNaming
+	aggregator.routeStrategy = opts.RouteStrategy
+	aggregator.externalDNSMode = opts.ExternalDNS
+	aggregator.progressiveDelivery = opts.ProgressiveDelivery
+	aggregator.hostHeaders = opts.HostHeaders
+	aggregator.tlsModes = opts.TLSModes
+	aggregator.defaultCertificate = opts.DefaultCertificate
+	aggregator.targetAnnotations = targetImplementations[opts.TargetImplementation].annotations
+	aggregator.backendKinds = targetBackendKinds(opts.TargetImplementation)
+	aggregator.externalNameBackends = opts.ExternalNameBackends
+	aggregator.externalNameKind = targetImplementations[opts.TargetImplementation].externalNameKind
+	aggregator.addIngressClasses(input.ingressClasses)
+	aggregator.readControllerConfig(input.objects)
+	aggregator.readIngressClassParameters(input.ingressClasses, input.objects)
+	aggregator.addServices(input.objects)
+	aggregator.addDeliveryControllers(input.objects)
+
+	ingresses, excluded, exclusionNotes := excludeIngresses(input.ingresses, opts.Exclusions)
+	sortIngresses(ingresses)
+	normalized, hostNotes := normalizeHosts(ingresses)
+	valid, validationErrors := validateIngresses(normalized)
+	for _, ingress := range valid {
+		aggregator.addIngress(ingress)
+	}
+
+	result, errors := aggregator.toIR()
+	errors = append(validationErrors, errors...)
+	result.Ingresses = ingresses
+	result.Objects = input.objects
+	var notes []Notification
+	if opts.Mode == ModeStrict {
+		notes = dropPartialIngresses(&result, errors, aggregator.unsupported)
+	}
+	if opts.SingleGateway != "" {
+		notes = append(notes, mergeGateways(&result, opts.SingleGateway)...)
+	}
+	if opts.NameGenerator != nil {
+		notes = append(notes, generateNames(&result, opts.NameGenerator)...)
+	}
+	notes = append(notes, shardGateways(&result, opts.ListenerStrategy)...)
+	notes = append(notes, aggregator.setCertManagerAnnotations(&result)...)
+	notes = append(notes, aggregator.setExternalDNSAnnotations(&result)...)
+	notes = append(notes, remapNamespaces(&result, opts.NamespaceMap)...)
+	emitters := opts.Emitters
+	target, ok := targetImplementations[opts.TargetImplementation]
+	if ok {
+		emitters = append([]Emitter{target.emitter}, emitters...)
+	}
+	notes = append(notes, transformIR(emitters, &result)...)
+	for _, f := range experimentalFeatures {
+		if aggregator.experimental[f.name] && !containsPolicyFeature(target.policies, f.feature) {
+			notes = append(notes, f.convert(&result)...)
+			target.policies = append(target.policies[:len(target.policies):len(target.policies)], f.feature)
 		}
 	}
+	if containsPolicyFeature(target.policies, retryFeature) {
+		extendRequestTimeouts(&result)
+	}
+
+	notes = append(notes, normalizeFilters(&result)...)
+	notes = append(notes, splitHTTPRoutes(&result)...)
+	notes = append(notes, uniqueHTTPRouteNames(&result)...)
+	notes = append(notes, hostnameConflicts(result)...)
+
+	httpRoutes, gateways := emitGatewayAPI(result)
+	if opts.ConsolidateRules {
+		notes = append(notes, consolidateHTTPRoutes(httpRoutes)...)
+	}
+	notes = append(notes, setGatewayMetadata(gateways, opts.GatewayLabels, opts.GatewayAnnotations, opts.GatewayInfrastructure)...)
+	errors = append(errors, validateResources(httpRoutes, gateways)...)
+	gatewayClasses, gatewayClassNotes, gatewayClassErrors := gatewayClassesFor(gateways, opts)
+	notes = append(notes, gatewayClassNotes...)
+	errors = append(errors, gatewayClassErrors...)
+	referenceGrants, referenceGrantNotes := referenceGrantsFor(httpRoutes, gateways)
+	notes = append(notes, referenceGrantNotes...)
+	notes = append(notes, unconvertedPolicyNotifications(target, aggregator.policies)...)
+	if !containsPolicyFeature(target.policies, ipDenyListFeature) {
+		notes = append(notes, ipDenyListNotifications(result)...)
+	}
+	notes = append(notes, clientAddressNotifications(target, result)...)
+	customResources, emitterNotes, emitterErrors := runEmitters(emitters, result)
+	tlsRoutes, tcpRoutes := emitStreamRoutes(result)
+
+	resources := Resources{
+		GatewayClasses:     gatewayClasses,
+		Gateways:           gateways,
+		HTTPRoutes:         httpRoutes,
+		TLSRoutes:          tlsRoutes,
+		TCPRoutes:          tcpRoutes,
+		ReferenceGrants:    referenceGrants,
+		BackendLBPolicies:  emitBackendLBPolicies(result),
+		BackendTLSPolicies: emitBackendTLSPolicies(result),
+		CustomResources:    customResources,
+		Sources:            objectSources(result, aggregator.annotations),
+	}
+	if opts.SourceChecksums {
+		stampSourceChecksums(&resources, ingresses)
+	}
+	if opts.ProgressiveDelivery == ProgressiveDeliveryHints {
+		notes = append(notes, aggregator.deliveryHints(resources)...)
+	}
+	notes = append(notes, emitterNotes...)
+	notes = append(notes, capabilityNotes...)
+	notes = append(notes, opts.Capabilities.tailor(&resources)...)
+	supportLevels, supportNotes := applySupportLevel(&resources, opts.MaxSupportLevel)
+	notes = append(notes, supportNotes...)
+	report := Report{
+		Notifications:          append(append(append(exclusionNotes, hostNotes...), aggregator.notifications...), notes...),
+		Errors:                 append(errors, emitterErrors...),
+		UnsupportedAnnotations: aggregator.unsupported,
+		DisabledAnnotations:    aggregator.disabled,
+		SkippedIngresses:       skippedIngresses(ingresses, resources.Sources),
+		ExcludedIngresses:      excluded,
+		WeightSplits:           weightSplits(result, aggregator.canaries),
+		SupportLevels:          supportLevels,
+	}
+	report.Confidence = resourceConfidences(resources, report)
+	if opts.ConfidenceAnnotations {
+		stampConfidences(&resources, report.Confidence)
+	}
+	return resources, report
+}
+
+// sortIngresses orders Ingresses oldest first, falling back to namespace/name,
+// so that earlier Ingresses take precedence when their rules conflict.
+func sortIngresses(ingresses []networkingv1.Ingress) {
+	sort.SliceStable(ingresses, func(i, j int) bool {
+		ti, tj := ingresses[i].CreationTimestamp, ingresses[j].CreationTimestamp
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+		if ingresses[i].Namespace != ingresses[j].Namespace {
+			return ingresses[i].Namespace < ingresses[j].Namespace
+		}
+		return ingresses[i].Name < ingresses[j].Name
+	})
+}
+
+// writeReport writes the errors and notifications of the report as YAML
+// comments.
+func writeReport(w io.Writer, report Report) {
+	if len(report.Errors) > 0 {
+		fmt.Fprintf(w, "# Encountered %d errors\n", len(report.Errors))
+		for _, err := range report.Errors {
+			fmt.Fprintf(w, "# %s\n", err)
+		}
+	}
+	for _, n := range report.Notifications {
+		fmt.Fprintf(w, "# %s\n", n)
+	}
+}
+
+// WriteResult writes the report as YAML comments followed by the resources
+// as YAML documents, as the command line does.
+func WriteResult(w io.Writer, resources Resources, report Report) {
+	writeResult(w, resources, report, nil, false)
+}
+
+// WriteAnnotatedResult writes the result like WriteResult, preceding every
+// Gateway and HTTPRoute with comments about the Ingresses and annotations it
+// was converted from.
+func WriteAnnotatedResult(w io.Writer, resources Resources, report Report) {
+	writeResult(w, resources, report, resources.Sources, false)
+}
+
+func writeResult(w io.Writer, resources Resources, report Report, sources map[ObjectRef][]IngressSource, clean bool) {
+	writeReport(w, report)
+	writeObjects(w, resources, sources, clean)
 }
@@ -0,0 +1,255 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_Convert(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	fromClient := ingressWithPath("from-client", "/client", &iPrefix, serviceBackend("client", 80), nil)
+	fromOptions := ingressWithPath("from-options", "/options", &iPrefix, serviceBackend("options", 80), nil)
+
+	cl := fake.NewClientBuilder().WithObjects(&fromClient).Build()
+
+	resources, report, err := Convert(context.Background(), ConvertOptions{
+		Client:    cl,
+		Ingresses: []networkingv1.Ingress{fromOptions},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(report.Errors) != 0 {
+		t.Errorf("Expected no conversion errors, got %+v", report.Errors)
+	}
+	if len(resources.Gateways) != 1 {
+		t.Errorf("Expected 1 Gateway, got %d", len(resources.Gateways))
+	}
+	if len(resources.HTTPRoutes) != 1 || len(resources.HTTPRoutes[0].Spec.Rules) != 2 {
+		t.Fatalf("Expected 1 HTTPRoute with 2 rules, got %+v", resources.HTTPRoutes)
+	}
+}
+
+func Test_Convert_missingInputFile(t *testing.T) {
+	_, _, err := Convert(context.Background(), ConvertOptions{InputFile: "does-not-exist.yaml"})
+	if err == nil {
+		t.Errorf("Expected an error for a missing input file")
+	}
+}
+
+type routePolicyEmitter struct{}
+
+func (routePolicyEmitter) Name() string {
+	return "route-policy"
+}
+
+func (routePolicyEmitter) Emit(result ir.IR) ([]unstructured.Unstructured, []Notification, []error) {
+	var objects []unstructured.Unstructured
+	for _, route := range result.HTTPRoutes {
+		policy := unstructured.Unstructured{}
+		policy.SetAPIVersion("example.com/v1")
+		policy.SetKind("RoutePolicy")
+		policy.SetNamespace(route.Namespace)
+		policy.SetName(route.Name)
+		objects = append(objects, policy)
+	}
+	// Objects without a kind are rejected.
+	objects = append(objects, unstructured.Unstructured{Object: map[string]interface{}{}})
+	return objects, nil, nil
+}
+
+func Test_Convert_emitters(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	ingress := ingressWithPath("ingress", "/", &iPrefix, serviceBackend("svc", 80), nil)
+
+	resources, report, err := Convert(context.Background(), ConvertOptions{
+		Ingresses: []networkingv1.Ingress{ingress},
+		Emitters:  []Emitter{routePolicyEmitter{}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resources.CustomResources) != 1 {
+		t.Fatalf("Expected 1 custom resource, got %+v", resources.CustomResources)
+	}
+	policy := resources.CustomResources[0]
+	if policy.GetKind() != "RoutePolicy" || policy.GetNamespace() != "test" || policy.GetName() != "example-com" {
+		t.Errorf("Unexpected custom resource %s %s/%s", policy.GetKind(), policy.GetNamespace(), policy.GetName())
+	}
+	if len(report.Errors) != 1 {
+		t.Errorf("Expected 1 error for the object without kind, got %+v", report.Errors)
+	}
+}
+
+func Test_Convert_listenerPorts(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	ingress := ingressWithPath("ingress", "/", &iPrefix, serviceBackend("svc", 80), nil)
+	ingress.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-cert"}}
+
+	testCases := []struct {
+		name        string
+		opts        ConvertOptions
+		expectPorts []gatewayv1.PortNumber
+	}{{
+		name:        "defaults",
+		expectPorts: []gatewayv1.PortNumber{80, 443},
+	}, {
+		name:        "listener ports",
+		opts:        ConvertOptions{ListenerPorts: ListenerPorts{HTTP: 8080, HTTPS: 8443}},
+		expectPorts: []gatewayv1.PortNumber{8080, 8443},
+	}, {
+		name: "IngressClass override",
+		opts: ConvertOptions{
+			ListenerPorts:      ListenerPorts{HTTP: 8080, HTTPS: 8443},
+			ClassListenerPorts: map[string]ListenerPorts{"nginx": {HTTPS: 9443}, "other": {HTTP: 9080}},
+		},
+		expectPorts: []gatewayv1.PortNumber{8080, 9443},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := tc.opts
+			opts.Ingresses = []networkingv1.Ingress{ingress}
+			resources, _, err := Convert(context.Background(), opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(resources.Gateways) != 1 {
+				t.Fatalf("Expected 1 Gateway, got %+v", resources.Gateways)
+			}
+			var gotPorts []gatewayv1.PortNumber
+			for _, listener := range resources.Gateways[0].Spec.Listeners {
+				gotPorts = append(gotPorts, listener.Port)
+			}
+			if diff := cmp.Diff(tc.expectPorts, gotPorts); diff != "" {
+				t.Errorf("Unexpected listener ports, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func Test_WriteAnnotatedResult(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	web := ingressWithPath("web", "/", &iPrefix, serviceBackend("web", 80), map[string]string{
+		"nginx.ingress.kubernetes.io/affinity":             "cookie",
+		"nginx.ingress.kubernetes.io/session-cookie-name":  "route",
+		"kubectl.kubernetes.io/last-applied-configuration": "{}",
+	})
+	api := ingressWithPath("api", "/api", &iPrefix, serviceBackend("api", 80), nil)
+
+	resources, report, err := Convert(context.Background(), ConvertOptions{Ingresses: []networkingv1.Ingress{web, api}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	WriteAnnotatedResult(&buf, resources, report)
+
+	var gotComments []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.HasPrefix(line, "# converted from") || line == "---" {
+			gotComments = append(gotComments, line)
+		}
+	}
+	expectComments := []string{
+		"# converted from Ingress test/api",
+		"# converted from Ingress test/web",
+		"---",
+		"# converted from Ingress test/api",
+		"# converted from Ingress test/web, annotations nginx.ingress.kubernetes.io/affinity=cookie, nginx.ingress.kubernetes.io/session-cookie-name=route",
+	}
+	if diff := cmp.Diff(expectComments, gotComments); diff != "" {
+		t.Errorf("Unexpected comments, diff (-want +got): %s", diff)
+	}
+}
+
+func Test_ParseListenerPorts(t *testing.T) {
+	testCases := []struct {
+		value       string
+		expected    ListenerPorts
+		expectError bool
+	}{{
+		value:    "8080:8443",
+		expected: ListenerPorts{HTTP: 8080, HTTPS: 8443},
+	}, {
+		value:    ":8443",
+		expected: ListenerPorts{HTTPS: 8443},
+	}, {
+		value:    "8080:",
+		expected: ListenerPorts{HTTP: 8080},
+	}, {
+		value:       "8080",
+		expectError: true,
+	}, {
+		value:       "0:70000",
+		expectError: true,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			ports, err := ParseListenerPorts(tc.value)
+			if (err != nil) != tc.expectError {
+				t.Fatalf("Expected error %t, got %v", tc.expectError, err)
+			}
+			if ports != tc.expected {
+				t.Errorf("Expected %+v, got %+v", tc.expected, ports)
+			}
+		})
+	}
+}
+
+func Test_ParseDefaultCertificate(t *testing.T) {
+	testCases := []struct {
+		value       string
+		expected    types.NamespacedName
+		expectError bool
+	}{{
+		value:    "ingress-nginx/default-cert",
+		expected: types.NamespacedName{Namespace: "ingress-nginx", Name: "default-cert"},
+	}, {
+		value:       "default-cert",
+		expectError: true,
+	}, {
+		value:       "ingress-nginx/",
+		expectError: true,
+	}, {
+		value:       "Ingress_Nginx/default-cert",
+		expectError: true,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			secret, err := ParseDefaultCertificate(tc.value)
+			if (err != nil) != tc.expectError {
+				t.Fatalf("Expected error %t, got %v", tc.expectError, err)
+			}
+			if secret != tc.expected {
+				t.Errorf("Expected %+v, got %+v", tc.expected, secret)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"sort"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// addIngressClasses records the IngressClasses known to the conversion and
+// resolves the default class used for Ingresses that don't specify one. As
+// with the API server, if several classes are marked as default the most
+// recently created one wins.
+func (a *ingressAggregator) addIngressClasses(ingressClasses []networkingv1.IngressClass) {
+	if a.ingressClasses == nil {
+		a.ingressClasses = map[string]networkingv1.IngressClass{}
+	}
+
+	var defaults []networkingv1.IngressClass
+	for _, ic := range ingressClasses {
+		a.ingressClasses[ic.Name] = ic
+		if ic.Annotations[networkingv1.AnnotationIsDefaultIngressClass] == "true" {
+			defaults = append(defaults, ic)
+		}
+		if p := ic.Spec.Parameters; p != nil && !a.readsParameters(ic.Name) {
+			var group string
+			if p.APIGroup != nil {
+				group = *p.APIGroup + "/"
+			}
+			a.notifications = append(a.notifications, notifications.NewInfo("IngressClass %s references parameters %s%s %s that have no Gateway API equivalent, configure the equivalent infrastructure settings on GatewayClass %s", ic.Name, group, p.Kind, p.Name, ic.Name))
+		}
+	}
+
+	for _, p := range a.providers {
+		reader, ok := p.(IngressClassReader)
+		if !ok {
+			continue
+		}
+		var classes []networkingv1.IngressClass
+		for _, ic := range ingressClasses {
+			if ic.Spec.Controller == p.Controller() {
+				classes = append(classes, ic)
+			}
+		}
+		reader.ReadIngressClasses(classes)
+	}
+
+	if len(defaults) == 0 {
+		return
+	}
+	sort.Slice(defaults, func(i, j int) bool {
+		ti, tj := defaults[i].CreationTimestamp, defaults[j].CreationTimestamp
+		if !ti.Equal(&tj) {
+			return tj.Before(&ti)
+		}
+		return defaults[i].Name < defaults[j].Name
+	})
+	a.defaultIngressClass = defaults[0].Name
+	if len(defaults) > 1 {
+		a.notifications = append(a.notifications, notifications.NewWarning("Multiple IngressClasses are marked as default, using the most recent one: %s", a.defaultIngressClass))
+	}
+}
+
+// readsParameters reports whether a provider of the IngressClass converts
+// its parameters.
+func (a *ingressAggregator) readsParameters(ingressClass string) bool {
+	for _, p := range a.providersFor(ingressClass) {
+		if _, ok := p.(IngressClassParametersReader); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// readIngressClassParameters has the providers of the IngressClasses with
+// parameters convert them.
+func (a *ingressAggregator) readIngressClassParameters(ingressClasses []networkingv1.IngressClass, objects []unstructured.Unstructured) {
+	for _, ic := range ingressClasses {
+		if ic.Spec.Parameters == nil {
+			continue
+		}
+		for _, p := range a.providersFor(ic.Name) {
+			if reader, ok := p.(IngressClassParametersReader); ok {
+				a.notifications = append(a.notifications, reader.ReadIngressClassParameters(ic, objects)...)
+			}
+		}
+	}
+}
+
+// providersFor returns the providers whose annotations are taken into
+// account for Ingresses of the given class. If the IngressClass is unknown,
+// every provider is tried.
+func (a *ingressAggregator) providersFor(ingressClass string) []Provider {
+	ic, ok := a.ingressClasses[ingressClass]
+	if !ok {
+		return a.providers
+	}
+	var providers []Provider
+	for _, p := range a.providers {
+		if p.Controller() == ic.Spec.Controller {
+			providers = append(providers, p)
+		}
+	}
+	return providers
+}
@@ -0,0 +1,114 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/ingressnginx"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_addIngressClasses(t *testing.T) {
+	ingressClass := func(name, controller string, isDefault bool, created time.Time) networkingv1.IngressClass {
+		ic := networkingv1.IngressClass{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created)},
+			Spec:       networkingv1.IngressClassSpec{Controller: controller},
+		}
+		if isDefault {
+			ic.Annotations = map[string]string{networkingv1.AnnotationIsDefaultIngressClass: "true"}
+		}
+		return ic
+	}
+	now := time.Now()
+
+	testCases := []struct {
+		name                string
+		ingressClasses      []networkingv1.IngressClass
+		expectDefault       string
+		expectGatewayName   string
+		expectCanaryParsed  bool
+		expectNotifications []string
+	}{{
+		name:               "no IngressClasses",
+		expectGatewayName:  "primary",
+		expectCanaryParsed: true,
+	}, {
+		name: "default IngressClass used for Ingresses without a class",
+		ingressClasses: []networkingv1.IngressClass{
+			ingressClass("nginx", ingressnginx.Controller, true, now),
+		},
+		expectDefault:      "nginx",
+		expectGatewayName:  "nginx",
+		expectCanaryParsed: true,
+	}, {
+		name: "most recent default wins",
+		ingressClasses: []networkingv1.IngressClass{
+			ingressClass("old", ingressnginx.Controller, true, now.Add(-time.Hour)),
+			ingressClass("new", ingressnginx.Controller, true, now),
+		},
+		expectDefault:       "new",
+		expectGatewayName:   "new",
+		expectCanaryParsed:  true,
+		expectNotifications: []string{"WARNING: Multiple IngressClasses are marked as default, using the most recent one: new"},
+	}, {
+		name: "annotations of other controllers are reported",
+		ingressClasses: []networkingv1.IngressClass{
+			ingressClass("other", "example.com/other-controller", true, now),
+		},
+		expectDefault:       "other",
+		expectGatewayName:   "other",
+		expectNotifications: []string{"WARNING: Ingress test/primary uses annotations that are not converted: nginx.ingress.kubernetes.io/canary"},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			aggregator := newIngressAggregator(builtinProviders())
+			aggregator.addIngressClasses(tc.ingressClasses)
+			if aggregator.defaultIngressClass != tc.expectDefault {
+				t.Errorf("Expected default IngressClass %q, got %q", tc.expectDefault, aggregator.defaultIngressClass)
+			}
+
+			iPrefix := networkingv1.PathTypePrefix
+			ingress := ingressWithPath("primary", "/", &iPrefix, serviceBackend("stable", 80), map[string]string{
+				"nginx.ingress.kubernetes.io/canary": "true",
+			})
+			ingress.Spec.IngressClassName = nil
+			aggregator.addIngress(ingress)
+
+			for _, rg := range aggregator.ruleGroups {
+				if rg.ingressClass != tc.expectGatewayName {
+					t.Errorf("Expected Ingress class %q, got %q", tc.expectGatewayName, rg.ingressClass)
+				}
+				gotCanaryParsed := rg.rules[0].features.Canary != nil
+				if gotCanaryParsed != tc.expectCanaryParsed {
+					t.Errorf("Expected canary annotations parsed to be %t", tc.expectCanaryParsed)
+				}
+			}
+
+			var gotNotifications []string
+			for _, n := range aggregator.notifications {
+				gotNotifications = append(gotNotifications, n.String())
+			}
+			if diff := cmp.Diff(tc.expectNotifications, gotNotifications); diff != "" {
+				t.Errorf("Unexpected notifications, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
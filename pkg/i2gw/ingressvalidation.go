@@ -0,0 +1,125 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	apimachineryvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// validateIngresses checks the fields of the Ingresses the conversion
+// relies on, like the API server does for the Ingresses it stores, and
+// returns the valid ones. The invalid ones, such as the ones of hand-written
+// manifests, are left out of the conversion with one error per problem,
+// with the path of its field, instead of failing midway.
+func validateIngresses(ingresses []networkingv1.Ingress) ([]networkingv1.Ingress, []error) {
+	var valid []networkingv1.Ingress
+	var errors []error
+	for _, ingress := range ingresses {
+		name := types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}
+		errs := validateIngress(&ingress)
+		for _, err := range errs {
+			errors = append(errors, newIngressError(name, fmt.Errorf("Ingress %s is invalid, it is not converted: %w", name, err)))
+		}
+		if len(errs) == 0 {
+			valid = append(valid, ingress)
+		}
+	}
+	return valid, errors
+}
+
+func validateIngress(ingress *networkingv1.Ingress) field.ErrorList {
+	var errs field.ErrorList
+	spec := field.NewPath("spec")
+	if ingress.Spec.DefaultBackend != nil {
+		errs = append(errs, validateIngressBackend(ingress.Spec.DefaultBackend, spec.Child("defaultBackend"))...)
+	}
+	for i, tls := range ingress.Spec.TLS {
+		for j, host := range tls.Hosts {
+			errs = append(errs, validateIngressHost(host, spec.Child("tls").Index(i).Child("hosts").Index(j))...)
+		}
+	}
+	for i, rule := range ingress.Spec.Rules {
+		rulePath := spec.Child("rules").Index(i)
+		if rule.Host != "" {
+			errs = append(errs, validateIngressHost(rule.Host, rulePath.Child("host"))...)
+		}
+		if rule.HTTP == nil {
+			continue
+		}
+		for j, path := range rule.HTTP.Paths {
+			pathPath := rulePath.Child("http", "paths").Index(j)
+			switch {
+			case path.PathType == nil:
+				errs = append(errs, field.Required(pathPath.Child("pathType"), "pathType must be Exact, Prefix or ImplementationSpecific"))
+			case *path.PathType == networkingv1.PathTypeExact || *path.PathType == networkingv1.PathTypePrefix:
+				if !strings.HasPrefix(path.Path, "/") {
+					errs = append(errs, field.Invalid(pathPath.Child("path"), path.Path, "must be an absolute path"))
+				}
+			}
+			errs = append(errs, validateIngressBackend(&path.Backend, pathPath.Child("backend"))...)
+		}
+	}
+	return errs
+}
+
+func validateIngressHost(host string, fldPath *field.Path) field.ErrorList {
+	if net.ParseIP(host) != nil {
+		return field.ErrorList{field.Invalid(fldPath, host, "must be a DNS name, not an IP address")}
+	}
+	var msgs []string
+	if strings.HasPrefix(host, "*.") {
+		msgs = apimachineryvalidation.IsWildcardDNS1123Subdomain(host)
+	} else {
+		msgs = apimachineryvalidation.IsDNS1123Subdomain(host)
+	}
+	if len(msgs) > 0 {
+		return field.ErrorList{field.Invalid(fldPath, host, strings.Join(msgs, ", "))}
+	}
+	return nil
+}
+
+func validateIngressBackend(backend *networkingv1.IngressBackend, fldPath *field.Path) field.ErrorList {
+	switch {
+	case backend.Service != nil && backend.Resource != nil:
+		return field.ErrorList{field.Invalid(fldPath, "service and resource", "only one of service or resource can be set")}
+	case backend.Resource != nil:
+		if backend.Resource.Kind == "" || backend.Resource.Name == "" {
+			return field.ErrorList{field.Required(fldPath.Child("resource"), "the kind and name of the resource must be set")}
+		}
+		return nil
+	case backend.Service == nil:
+		return field.ErrorList{field.Required(fldPath, "one of service or resource must be set")}
+	}
+	var errs field.ErrorList
+	if backend.Service.Name == "" {
+		errs = append(errs, field.Required(fldPath.Child("service", "name"), "the name of the Service must be set"))
+	}
+	port := backend.Service.Port
+	switch {
+	case port.Name != "" && port.Number != 0:
+		errs = append(errs, field.Invalid(fldPath.Child("service", "port"), fmt.Sprintf("%s, %d", port.Name, port.Number), "only one of name or number can be set"))
+	case port.Name == "" && port.Number == 0:
+		errs = append(errs, field.Required(fldPath.Child("service", "port"), "one of name or number must be set"))
+	}
+	return errs
+}
@@ -0,0 +1,112 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+func Test_validateIngresses(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	namedAndNumbered := serviceBackend("web", 80)
+	namedAndNumbered.Service.Port.Name = "http"
+	both := serviceBackend("web", 80)
+	both.Resource = &corev1.TypedLocalObjectReference{Kind: "Bucket", Name: "assets"}
+
+	testCases := []struct {
+		name     string
+		ingress  func() networkingv1.Ingress
+		expected []string
+	}{
+		{
+			name: "valid",
+			ingress: func() networkingv1.Ingress {
+				return ingressWithPath("web", "/", &prefix, serviceBackend("web", 80), nil)
+			},
+		},
+		{
+			name: "nil pathType",
+			ingress: func() networkingv1.Ingress {
+				return ingressWithPath("web", "/", nil, serviceBackend("web", 80), nil)
+			},
+			expected: []string{"Ingress test/web is invalid, it is not converted: spec.rules[0].http.paths[0].pathType: Required value: pathType must be Exact, Prefix or ImplementationSpecific"},
+		},
+		{
+			name: "relative path",
+			ingress: func() networkingv1.Ingress {
+				return ingressWithPath("web", "api", &prefix, serviceBackend("web", 80), nil)
+			},
+			expected: []string{`Ingress test/web is invalid, it is not converted: spec.rules[0].http.paths[0].path: Invalid value: "api": must be an absolute path`},
+		},
+		{
+			name: "missing backend",
+			ingress: func() networkingv1.Ingress {
+				return ingressWithPath("web", "/", &prefix, networkingv1.IngressBackend{}, nil)
+			},
+			expected: []string{"Ingress test/web is invalid, it is not converted: spec.rules[0].http.paths[0].backend: Required value: one of service or resource must be set"},
+		},
+		{
+			name: "service and resource backends",
+			ingress: func() networkingv1.Ingress {
+				return ingressWithPath("web", "/", &prefix, both, nil)
+			},
+			expected: []string{`Ingress test/web is invalid, it is not converted: spec.rules[0].http.paths[0].backend: Invalid value: "service and resource": only one of service or resource can be set`},
+		},
+		{
+			name: "port name and number",
+			ingress: func() networkingv1.Ingress {
+				return ingressWithPath("web", "/", &prefix, namedAndNumbered, nil)
+			},
+			expected: []string{`Ingress test/web is invalid, it is not converted: spec.rules[0].http.paths[0].backend.service.port: Invalid value: "http, 80": only one of name or number can be set`},
+		},
+		{
+			name: "malformed hosts",
+			ingress: func() networkingv1.Ingress {
+				ingress := ingressWithPath("web", "/", &prefix, serviceBackend("web", 80), nil)
+				ingress.Spec.Rules[0].Host = "Example_com"
+				ingress.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"10.0.0.1"}, SecretName: "tls"}}
+				return ingress
+			},
+			expected: []string{
+				`Ingress test/web is invalid, it is not converted: spec.tls[0].hosts[0]: Invalid value: "10.0.0.1": must be a DNS name, not an IP address`,
+				`Ingress test/web is invalid, it is not converted: spec.rules[0].host: Invalid value: "Example_com": a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')`,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resources, report, err := Convert(context.Background(), ConvertOptions{Ingresses: []networkingv1.Ingress{tc.ingress()}})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var got []string
+			for _, err := range report.Errors {
+				got = append(got, err.Error())
+			}
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Errorf("Unexpected errors, diff (-want +got): %s", diff)
+			}
+			if converted := len(resources.HTTPRoutes) > 0; converted != (len(tc.expected) == 0) {
+				t.Errorf("Expected only valid Ingresses to be converted, got %d HTTPRoutes", len(resources.HTTPRoutes))
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	networkingV1APIVersion      = "networking.k8s.io/v1"
+	networkingV1beta1APIVersion = "networking.k8s.io/v1beta1"
+	extensionsV1beta1APIVersion = "extensions/v1beta1"
+)
+
+// inputResources holds every object read from the cluster or input files
+// that takes part in the conversion.
+type inputResources struct {
+	ingresses      []networkingv1.Ingress
+	ingressClasses []networkingv1.IngressClass
+	// objects are the objects of other kinds, used by target
+	// implementations.
+	objects []unstructured.Unstructured
+}
+
+func readInputFromFile(path string) (inputResources, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return inputResources{}, fmt.Errorf("failed to open input file: %w", err)
+	}
+	defer f.Close()
+
+	return decodeInput(f)
+}
+
+// decodeInput decodes a stream of YAML or JSON documents and returns the
+// Ingresses and IngressClasses it contains, upgrading deprecated
+// networking.k8s.io/v1beta1 and extensions/v1beta1 Ingresses to
+// networking.k8s.io/v1. Objects of other kinds are kept as is, List objects
+// are expanded and so are the deployed Helm release Secrets and ConfigMaps.
+func decodeInput(r io.Reader) (inputResources, error) {
+	var input inputResources
+	err := decodeObjects(r, input.add)
+	return input, err
+}
+
+// decodeObjects calls fn with every object decoded from a stream of YAML or
+// JSON documents, as soon as it is decoded. List objects are expanded into
+// their items and Helm release Secrets and ConfigMaps into the objects of
+// their manifest.
+func decodeObjects(r io.Reader, fn func(unstructured.Unstructured) error) error {
+	decoder := yaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		obj := unstructured.Unstructured{}
+		err := decoder.Decode(&obj.Object)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to decode input: %w", err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+
+		var items []unstructured.Unstructured
+		if obj.IsList() {
+			list, err := obj.ToList()
+			if err != nil {
+				return fmt.Errorf("failed to decode List: %w", err)
+			}
+			items = list.Items
+		} else {
+			items = []unstructured.Unstructured{obj}
+		}
+
+		for _, item := range items {
+			objects, isRelease, err := helmReleaseObjects(item)
+			if err != nil {
+				return err
+			}
+			if !isRelease {
+				objects = []unstructured.Unstructured{item}
+			}
+			for _, obj := range objects {
+				if err := fn(obj); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+func (input *inputResources) add(obj unstructured.Unstructured) error {
+	switch obj.GetKind() {
+	case "Ingress":
+		ingress, err := ingressFromUnstructured(obj)
+		if err != nil {
+			return err
+		}
+		input.ingresses = append(input.ingresses, ingress)
+	case "IngressClass":
+		if obj.GetAPIVersion() != networkingV1APIVersion && obj.GetAPIVersion() != networkingV1beta1APIVersion {
+			return fmt.Errorf("unsupported IngressClass apiVersion %q for %s", obj.GetAPIVersion(), obj.GetName())
+		}
+		// networking.k8s.io/v1beta1 IngressClasses have the same schema as
+		// networking.k8s.io/v1 ones.
+		ingressClass := networkingv1.IngressClass{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &ingressClass); err != nil {
+			return fmt.Errorf("failed to decode IngressClass %s: %w", obj.GetName(), err)
+		}
+		ingressClass.SetGroupVersionKind(networkingv1.SchemeGroupVersion.WithKind("IngressClass"))
+		input.ingressClasses = append(input.ingressClasses, ingressClass)
+	default:
+		input.objects = append(input.objects, obj)
+	}
+	return nil
+}
+
+func ingressFromUnstructured(obj unstructured.Unstructured) (networkingv1.Ingress, error) {
+	switch obj.GetAPIVersion() {
+	case networkingV1APIVersion:
+		ingress := networkingv1.Ingress{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &ingress); err != nil {
+			return ingress, fmt.Errorf("failed to decode Ingress %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+		}
+		return ingress, nil
+	case networkingV1beta1APIVersion, extensionsV1beta1APIVersion:
+		// extensions/v1beta1 Ingresses have the same schema as
+		// networking.k8s.io/v1beta1 ones.
+		legacy := networkingv1beta1.Ingress{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &legacy); err != nil {
+			return networkingv1.Ingress{}, fmt.Errorf("failed to decode %s Ingress %s/%s: %w", obj.GetAPIVersion(), obj.GetNamespace(), obj.GetName(), err)
+		}
+		return convertV1beta1Ingress(legacy), nil
+	default:
+		return networkingv1.Ingress{}, fmt.Errorf("unsupported Ingress apiVersion %q for %s/%s", obj.GetAPIVersion(), obj.GetNamespace(), obj.GetName())
+	}
+}
+
+// convertV1beta1Ingress upgrades a v1beta1 Ingress to networking.k8s.io/v1,
+// applying the same defaulting as the API server does for paths without a
+// pathType.
+func convertV1beta1Ingress(legacy networkingv1beta1.Ingress) networkingv1.Ingress {
+	ingress := networkingv1.Ingress{
+		ObjectMeta: legacy.ObjectMeta,
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: legacy.Spec.IngressClassName,
+		},
+	}
+	for _, lb := range legacy.Status.LoadBalancer.Ingress {
+		ingress.Status.LoadBalancer.Ingress = append(ingress.Status.LoadBalancer.Ingress, networkingv1.IngressLoadBalancerIngress{
+			IP:       lb.IP,
+			Hostname: lb.Hostname,
+			Ports:    convertV1beta1PortStatuses(lb.Ports),
+		})
+	}
+	ingress.SetGroupVersionKind(networkingv1.SchemeGroupVersion.WithKind("Ingress"))
+
+	if legacy.Spec.Backend != nil {
+		backend := convertV1beta1IngressBackend(*legacy.Spec.Backend)
+		ingress.Spec.DefaultBackend = &backend
+	}
+	for _, tls := range legacy.Spec.TLS {
+		ingress.Spec.TLS = append(ingress.Spec.TLS, networkingv1.IngressTLS{
+			Hosts:      tls.Hosts,
+			SecretName: tls.SecretName,
+		})
+	}
+	for _, legacyRule := range legacy.Spec.Rules {
+		rule := networkingv1.IngressRule{Host: legacyRule.Host}
+		if legacyRule.HTTP != nil {
+			rule.HTTP = &networkingv1.HTTPIngressRuleValue{}
+			for _, legacyPath := range legacyRule.HTTP.Paths {
+				pathType := networkingv1.PathTypeImplementationSpecific
+				if legacyPath.PathType != nil {
+					pathType = networkingv1.PathType(*legacyPath.PathType)
+				}
+				rule.HTTP.Paths = append(rule.HTTP.Paths, networkingv1.HTTPIngressPath{
+					Path:     legacyPath.Path,
+					PathType: &pathType,
+					Backend:  convertV1beta1IngressBackend(legacyPath.Backend),
+				})
+			}
+		}
+		ingress.Spec.Rules = append(ingress.Spec.Rules, rule)
+	}
+	return ingress
+}
+
+func convertV1beta1PortStatuses(legacy []networkingv1beta1.IngressPortStatus) []networkingv1.IngressPortStatus {
+	var ports []networkingv1.IngressPortStatus
+	for _, port := range legacy {
+		ports = append(ports, networkingv1.IngressPortStatus{Port: port.Port, Protocol: port.Protocol, Error: port.Error})
+	}
+	return ports
+}
+
+func convertV1beta1IngressBackend(legacy networkingv1beta1.IngressBackend) networkingv1.IngressBackend {
+	if legacy.Resource != nil {
+		return networkingv1.IngressBackend{Resource: legacy.Resource}
+	}
+	backend := networkingv1.IngressBackend{
+		Service: &networkingv1.IngressServiceBackend{Name: legacy.ServiceName},
+	}
+	if legacy.ServicePort.Type == intstr.String {
+		backend.Service.Port.Name = legacy.ServicePort.StrVal
+	} else {
+		backend.Service.Port.Number = legacy.ServicePort.IntVal
+	}
+	return backend
+}
@@ -0,0 +1,177 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_decodeInput(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	iImplementationSpecific := networkingv1.PathTypeImplementationSpecific
+
+	expectedIngress := func(pathType *networkingv1.PathType, port networkingv1.ServiceBackendPort) networkingv1.Ingress {
+		return networkingv1.Ingress{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "Ingress"},
+			ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test"},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{
+					Host: "example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{{
+								Path:     "/foo",
+								PathType: pathType,
+								Backend: networkingv1.IngressBackend{
+									Service: &networkingv1.IngressServiceBackend{Name: "example", Port: port},
+								},
+							}},
+						},
+					},
+				}},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name                 string
+		input                string
+		expectIngresses      []networkingv1.Ingress
+		expectIngressClasses []networkingv1.IngressClass
+		expectError          string
+	}{{
+		name: "networking.k8s.io/v1",
+		input: `
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: example
+  namespace: test
+spec:
+  rules:
+  - host: example.com
+    http:
+      paths:
+      - path: /foo
+        pathType: Prefix
+        backend:
+          service:
+            name: example
+            port:
+              number: 80
+`,
+		expectIngresses: []networkingv1.Ingress{expectedIngress(&iPrefix, networkingv1.ServiceBackendPort{Number: 80})},
+	}, {
+		name: "networking.k8s.io/v1beta1 with named port",
+		input: `
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: example
+  namespace: test
+spec:
+  rules:
+  - host: example.com
+    http:
+      paths:
+      - path: /foo
+        pathType: Prefix
+        backend:
+          serviceName: example
+          servicePort: http
+`,
+		expectIngresses: []networkingv1.Ingress{expectedIngress(&iPrefix, networkingv1.ServiceBackendPort{Name: "http"})},
+	}, {
+		name: "extensions/v1beta1 without pathType in a List, other kinds ignored",
+		input: `
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: example
+    namespace: test
+- apiVersion: extensions/v1beta1
+  kind: Ingress
+  metadata:
+    name: example
+    namespace: test
+  spec:
+    rules:
+    - host: example.com
+      http:
+        paths:
+        - path: /foo
+          backend:
+            serviceName: example
+            servicePort: 80
+`,
+		expectIngresses: []networkingv1.Ingress{expectedIngress(&iImplementationSpecific, networkingv1.ServiceBackendPort{Number: 80})},
+	}, {
+		name: "IngressClass",
+		input: `
+apiVersion: networking.k8s.io/v1
+kind: IngressClass
+metadata:
+  name: nginx
+spec:
+  controller: k8s.io/ingress-nginx
+`,
+		expectIngressClasses: []networkingv1.IngressClass{{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "IngressClass"},
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx"},
+			Spec:       networkingv1.IngressClassSpec{Controller: "k8s.io/ingress-nginx"},
+		}},
+	}, {
+		name: "unsupported apiVersion",
+		input: `
+apiVersion: networking.k8s.io/v2
+kind: Ingress
+metadata:
+  name: example
+  namespace: test
+`,
+		expectError: `unsupported Ingress apiVersion "networking.k8s.io/v2" for test/example`,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			input, err := decodeInput(strings.NewReader(tc.input))
+			if tc.expectError != "" {
+				if err == nil || err.Error() != tc.expectError {
+					t.Fatalf("Expected error %q, got %v", tc.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !apiequality.Semantic.DeepEqual(tc.expectIngresses, input.ingresses) {
+				t.Errorf("Unexpected Ingresses, diff (-want +got): %s", cmp.Diff(tc.expectIngresses, input.ingresses))
+			}
+			if !apiequality.Semantic.DeepEqual(tc.expectIngressClasses, input.ingressClasses) {
+				t.Errorf("Unexpected IngressClasses, diff (-want +got): %s", cmp.Diff(tc.expectIngressClasses, input.ingressClasses))
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ir
+
+import (
+	"fmt"
+	"strings"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// IngressFeatures are the features a provider extracted from the
+// implementation-specific configuration, such as annotations, of a single
+// Ingress.
+type IngressFeatures struct {
+	// Canary is set when the Ingress is a canary of the Ingress defining
+	// the same hosts and paths.
+	Canary *Canary
+	// Policy is set when the Ingress configures traffic policies.
+	Policy *Policy
+	// Rewrites are the rewrites of the requests to the paths of the Ingress,
+	// by path.
+	Rewrites map[string]Rewrite
+	// HostHeader is set when the Host header of the requests sent to the
+	// backends isn't the one of the request.
+	HostHeader *HostHeader
+	// Headers are set when the headers of the requests sent to the backends
+	// or of their responses are modified.
+	Headers *Headers
+	// PathOverrides are the features of some paths of the Ingress that
+	// override the ones of the Ingress, by path, for providers configuring
+	// paths individually. The rules of the other paths inherit the features
+	// of the Ingress.
+	PathOverrides map[string]PathOverride
+	// RegexPaths are the regular expressions the paths of the Ingress are
+	// matched as, by path, for controllers treating paths as regular
+	// expressions. Literal prefix paths are omitted.
+	RegexPaths map[string]RegexPath
+	// Group is the name of the group of Ingresses sharing a load balancer,
+	// whose Gateway is named after the group instead of their IngressClass.
+	Group string
+	// ListenPorts are the ports the hosts of the Ingress are served on,
+	// with their protocol. The ports other than the HTTP and HTTPS ports
+	// of the Gateway are served by extra listeners.
+	ListenPorts []ListenerPort
+	// Stream is set when the Ingress exposes its backends to TLS or TCP
+	// connections instead of HTTP requests, such as with TLS passthrough.
+	// Its paths and HTTP features are then ignored.
+	Stream *Stream
+	// BackendProtocol is set when the controller connects to the backends
+	// of the Ingress with another protocol than HTTP/1.1, such as gRPC.
+	BackendProtocol *BackendProtocol
+	// Matches restrict the requests to the paths of the Ingress beyond the
+	// path, by path and backend, such as by the methods and query
+	// parameters matched by the routes of controller CRDs. A request to a
+	// path matching any of the matches of a backend is routed to it, the
+	// backends of the path without matches receiving the other requests.
+	// The matches of a path without a Service apply to all of its backends.
+	Matches map[PathBackend][]RequestMatch
+	// UnsupportedAnnotations are the implementation-specific annotations of
+	// the Ingress that the provider could not convert.
+	UnsupportedAnnotations []string
+	// ConvertedAnnotations are the implementation-specific annotations of
+	// the Ingress that the provider converted.
+	ConvertedAnnotations []string
+}
+
+// Rewrite changes the path of the requests to an Ingress path before they
+// are sent to the backends.
+type Rewrite struct {
+	// Prefix, when set, replaces the path of the Ingress in the generated
+	// PathPrefix match, for regular expressions matching a prefix.
+	Prefix string
+	Path   gatewayv1.HTTPPathModifier
+}
+
+// HostHeader is the Host header of the requests sent to the backends of an
+// Ingress. Hostname is empty for the DNS name of the Service of the backend.
+type HostHeader struct {
+	Hostname string
+}
+
+// Headers are the header changes of the requests to the backends of an
+// Ingress and of their responses.
+type Headers struct {
+	Request  *gatewayv1.HTTPHeaderFilter
+	Response *gatewayv1.HTTPHeaderFilter
+}
+
+// PathOverride are the features of an Ingress path overriding the ones of
+// the Ingress. Unset fields inherit the ones of the Ingress, and the headers
+// it sets or removes replace the changes the Ingress makes to the same
+// headers.
+type PathOverride struct {
+	Timeouts *Timeouts
+	Headers  *Headers
+}
+
+// Stream is how the connections to the hosts of an Ingress are forwarded to
+// its backends without being handled as HTTP.
+type Stream struct {
+	// Protocol is "tls" for TLS connections routed by their SNI without
+	// being terminated, or "tcp".
+	Protocol string
+	// Port is the port connections are accepted on. It defaults to the
+	// HTTPS port of the Gateway for TLS connections and is required for
+	// TCP ones.
+	Port int32
+}
+
+// AppProtocolH2C is the appProtocol of the Service ports that Gateway API
+// implementations connect to with HTTP/2 without TLS, as gRPC backends
+// expect.
+const AppProtocolH2C = "kubernetes.io/h2c"
+
+// BackendProtocol is the protocol the controller connects to the backends
+// of an Ingress with.
+type BackendProtocol struct {
+	// Name is the protocol as the Ingress configures it, such as GRPC.
+	Name string
+	// AppProtocol is the appProtocol of the Service ports of the backends
+	// that makes Gateway API implementations connect to them with the same
+	// protocol. It is empty when there is none, such as for HTTP/2 over
+	// TLS, which is negotiated with the backends.
+	AppProtocol string
+}
+
+// RegexPath is the RE2 regular expression matching the whole path of the
+// requests to an Ingress path. Error is set instead of Pattern for the paths
+// whose expression can't be translated, which are not converted.
+type RegexPath struct {
+	Pattern string
+	Error   string
+}
+
+// PathBackend is a path of an Ingress and the name of the Service of its
+// backend. Its text form, used as JSON object key, is the path, prefixed by
+// the Service and a colon if set, such as "/api" or "api:/api".
+type PathBackend struct {
+	Path    string
+	Service string
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (b PathBackend) MarshalText() ([]byte, error) {
+	if b.Service == "" {
+		return []byte(b.Path), nil
+	}
+	return []byte(b.Service + ":" + b.Path), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. Ingress paths are
+// absolute, so text starting with a slash is a path without a Service.
+func (b *PathBackend) UnmarshalText(text []byte) error {
+	s := string(text)
+	if s == "" || strings.HasPrefix(s, "/") {
+		*b = PathBackend{Path: s}
+		return nil
+	}
+	service, path, ok := strings.Cut(s, ":")
+	if !ok || service == "" {
+		return fmt.Errorf("invalid path backend %q, expected a path or a Service and a path separated by a colon", s)
+	}
+	*b = PathBackend{Path: path, Service: service}
+	return nil
+}
+
+// RequestMatch matches the method, headers and query parameters of a
+// request, all of the set ones matching.
+type RequestMatch struct {
+	Method      *gatewayv1.HTTPMethod
+	Headers     []gatewayv1.HTTPHeaderMatch
+	QueryParams []gatewayv1.HTTPQueryParamMatch
+}
+
+// Canary describes how traffic is split between a canary Ingress and its
+// primary Ingress.
+type Canary struct {
+	// Matches are evaluated in order before the weighted split, the first
+	// one a request matches decides where it is sent.
+	Matches []CanaryMatch
+	// Weight is the share of the remaining requests, out of WeightTotal,
+	// sent to the canary. Weighted is set when the canary configures a
+	// weight, a Weight of 0 then sending it no requests.
+	Weight      int
+	WeightTotal int
+	Weighted    bool
+	// Managed is set when a progressive delivery controller changes the
+	// weight at runtime, the canary then stays a backend with no weight.
+	Managed bool
+}
+
+// CanaryMatch sends the requests matching Header to the canary, or to the
+// primary Ingress when Primary is set.
+type CanaryMatch struct {
+	Header  gatewayv1.HTTPHeaderMatch
+	Primary bool
+}
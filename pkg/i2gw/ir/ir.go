@@ -0,0 +1,243 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ir contains the provider-neutral intermediate representation of a
+// conversion. Providers populate IngressFeatures from the
+// implementation-specific configuration of Ingresses, the aggregator groups
+// Ingresses into an IR, and emitters turn the IR into Gateway API resources.
+package ir
+
+import (
+	"fmt"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// IR describes the Gateways and HTTPRoutes a conversion produces.
+type IR struct {
+	Gateways   []Gateway
+	HTTPRoutes []HTTPRoute
+	// StreamRoutes are only set when fields of the experimental channel
+	// are enabled.
+	StreamRoutes []StreamRoute
+	// BackendLBPolicies are only set when fields of the experimental
+	// channel are enabled.
+	BackendLBPolicies  []BackendLBPolicy
+	BackendTLSPolicies []BackendTLSPolicy
+	// ExternalBackends are the backends of the target implementation that
+	// backendRefs of ExternalName Services point to instead.
+	ExternalBackends []ExternalBackend
+
+	// Ingresses are the Ingresses the IR was built from.
+	Ingresses []networkingv1.Ingress
+	// Objects are the other objects read from the input, such as Services
+	// and implementation-specific resources referenced by the Ingresses.
+	Objects []unstructured.Unstructured
+}
+
+// Gateway is a Gateway accepting traffic for a set of listeners.
+type Gateway struct {
+	Namespace        string
+	Name             string
+	GatewayClassName string
+	// HTTPPort and HTTPSPort are the ports every listener accepts HTTP and
+	// HTTPS traffic on.
+	HTTPPort  int32
+	HTTPSPort int32
+	Listeners []Listener
+	// Ingresses are the Ingresses the listeners were converted from.
+	Ingresses []types.NamespacedName
+	// Annotations are the annotations of the Gateway, such as the ones
+	// requesting certificates from cert-manager or configuring
+	// external-dns.
+	Annotations map[string]string
+}
+
+// ExternalBackend routes the requests of backendRefs to the port of the
+// external host of an ExternalName Service.
+type ExternalBackend struct {
+	Namespace string
+	Name      string
+	Service   string
+	Hostname  string
+	Port      int32
+}
+
+// BackendLBPolicy keeps the requests of a session on the same endpoint of a
+// Service.
+type BackendLBPolicy struct {
+	Namespace          string
+	Service            string
+	SessionPersistence gatewayv1.SessionPersistence
+}
+
+// BackendTLSPolicy verifies the certificates of the endpoints of a Service,
+// which are connected to with TLS.
+type BackendTLSPolicy struct {
+	Namespace string
+	Service   string
+	// CACertificates is the name of the Secret holding the trusted CA
+	// certificates, in the namespace of the Service.
+	CACertificates string
+	Hostname       string
+}
+
+// Listener is a hostname a Gateway accepts traffic for.
+type Listener struct {
+	// Name is the prefix of the names of the Gateway listeners emitted for
+	// the hostname, all-hosts for the catch-all listener without hostname.
+	Name string
+	// Hostname is empty for listeners accepting traffic for any host.
+	Hostname string
+	// CertificateRefs are the TLS certificates served for Hostname. HTTPS
+	// traffic is only accepted if at least one is set.
+	CertificateRefs []gatewayv1.SecretObjectReference
+	// TLSOptions are the implementation-specific TLS settings of the HTTPS
+	// listener.
+	TLSOptions map[gatewayv1.AnnotationKey]gatewayv1.AnnotationValue
+	// ClientValidation is the client certificate authentication of the
+	// Ingresses of the hostname.
+	ClientValidation *ClientValidation
+	// FrontendValidation is only set when fields of the experimental channel
+	// are enabled.
+	FrontendValidation *gatewayv1.FrontendTLSValidation
+	// HTTP is how plain HTTP requests to Hostname are handled when it is
+	// served over HTTPS.
+	HTTP HTTPMode
+	// AllowedNamespaces are the namespaces other than the one of the
+	// Gateway whose routes attach to the listener.
+	AllowedNamespaces []string
+	// ExtraPorts are the ports Hostname is served on in addition to the
+	// HTTP and HTTPS ports of the Gateway.
+	ExtraPorts []ListenerPort
+	// Stream is set for the hostnames only accepting the TLS or TCP
+	// connections of their ExtraPorts, which get no HTTP listener.
+	Stream bool
+}
+
+// ListenerPort is a port a listener accepts traffic on with a protocol,
+// "http" or "https", or "tls" and "tcp" for the connections of stream
+// routes.
+type ListenerPort struct {
+	Protocol string
+	Port     int32
+}
+
+// HTTPMode is how a listener with certificates handles plain HTTP requests.
+type HTTPMode string
+
+const (
+	// HTTPServe serves plain HTTP requests like HTTPS ones.
+	HTTPServe HTTPMode = ""
+	// HTTPOmit doesn't accept plain HTTP requests.
+	HTTPOmit HTTPMode = "Omit"
+	// HTTPRedirect redirects plain HTTP requests to HTTPS.
+	HTTPRedirect HTTPMode = "Redirect"
+)
+
+// SectionName returns the name of the Gateway listener emitted for the
+// protocol, "http" or "https".
+func (l Listener) SectionName(protocol string) string {
+	if l.Name == "" {
+		return protocol
+	}
+	return l.Name + "-" + protocol
+}
+
+// PortSectionName returns the name of the Gateway listener emitted for an
+// extra port.
+func (l Listener) PortSectionName(p ListenerPort) string {
+	return fmt.Sprintf("%s-%d", l.SectionName(p.Protocol), p.Port)
+}
+
+// HTTPRoute is a set of routing rules for a hostname.
+type HTTPRoute struct {
+	Namespace string
+	Name      string
+	// GatewayName is the name of the parent Gateway, which lives in
+	// GatewayNamespace, or the namespace of the HTTPRoute if it is empty.
+	GatewayName      string
+	GatewayNamespace string
+	// SectionNames are the listeners of the Gateway the route attaches
+	// to, all of them that match Hostname if empty.
+	SectionNames []string
+	// SectionPorts are the ports set on the parentRefs of SectionNames, by
+	// section name. The port of sections missing from it is left unset.
+	SectionPorts map[string]int32
+	// Hostname is empty for routes matching any host.
+	Hostname string
+	Rules    []HTTPRouteRule
+	// Policies are the policies of the Ingresses the route was converted
+	// from, by Ingress. Ingresses without policies are omitted.
+	Policies map[types.NamespacedName]Policy
+	// Annotations are the annotations of the HTTPRoute, such as the ones
+	// configuring external-dns.
+	Annotations map[string]string
+}
+
+// Gateway returns the namespaced name of the parent Gateway of the route.
+func (r HTTPRoute) Gateway() types.NamespacedName {
+	if r.GatewayNamespace != "" {
+		return types.NamespacedName{Namespace: r.GatewayNamespace, Name: r.GatewayName}
+	}
+	return types.NamespacedName{Namespace: r.Namespace, Name: r.GatewayName}
+}
+
+// StreamRoute forwards the TLS or TCP connections accepted by the listeners
+// it attaches to to its backends, and is emitted as a TLSRoute or TCPRoute.
+type StreamRoute struct {
+	Namespace string
+	Name      string
+	// Protocol is the protocol of the listener ports the route attaches
+	// to, "tls" or "tcp".
+	Protocol string
+	// GatewayName is the name of the parent Gateway, which lives in
+	// GatewayNamespace, or the namespace of the route if it is empty.
+	GatewayName      string
+	GatewayNamespace string
+	SectionNames     []string
+	// Hostnames are the SNI hostnames of TLS routes.
+	Hostnames []string
+	Backends  []Backend
+}
+
+// Gateway returns the namespaced name of the parent Gateway of the route.
+func (r StreamRoute) Gateway() types.NamespacedName {
+	if r.GatewayNamespace != "" {
+		return types.NamespacedName{Namespace: r.GatewayNamespace, Name: r.GatewayName}
+	}
+	return types.NamespacedName{Namespace: r.Namespace, Name: r.GatewayName}
+}
+
+// HTTPRouteRule routes requests matching any of Matches to Backends.
+type HTTPRouteRule struct {
+	Matches  []gatewayv1.HTTPRouteMatch
+	Filters  []gatewayv1.HTTPRouteFilter
+	Timeouts *gatewayv1.HTTPRouteTimeouts
+	// Retry is only set when fields of the experimental channel are enabled.
+	Retry    *gatewayv1.HTTPRouteRetry
+	Backends []Backend
+}
+
+// Backend is a destination of an HTTPRouteRule.
+type Backend struct {
+	gatewayv1.BackendRef
+	// Source is the Ingress the backend was converted from.
+	Source types.NamespacedName
+}
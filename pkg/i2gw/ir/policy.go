@@ -0,0 +1,296 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ir
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Policy is the traffic configuration of an Ingress that Gateway API can
+// only express through implementation-specific policies.
+type Policy struct {
+	Timeouts   *Timeouts
+	Retry      *Retry
+	RateLimits []RateLimit
+	// IPAllowList are the client CIDRs allowed to send requests, all
+	// others are denied.
+	IPAllowList []string
+	// IPDenyList are the client CIDRs denied, which takes precedence over
+	// IPAllowList.
+	IPDenyList []string
+	ExtAuth    *ExtAuth
+	// OIDC is set when ExtAuth delegates to oauth2-proxy, for targets able
+	// to authenticate clients with OpenID Connect themselves.
+	OIDC *OIDC
+	// TLSCiphers are the ciphers accepted from clients for TLS connections.
+	TLSCiphers []string
+	// TLSPreferServerCiphers is nil unless the Ingress sets whether the
+	// cipher order of the server takes precedence over the one of clients.
+	TLSPreferServerCiphers *bool
+	// TLSProtocols are the accepted TLS versions, such as TLSv1.2.
+	TLSProtocols     []string
+	ClientValidation *ClientValidation
+	// SSLRedirect redirects plain HTTP requests to HTTPS.
+	SSLRedirect  bool
+	BackendTLS   *BackendTLS
+	HSTS         *HSTS
+	CustomErrors *CustomErrors
+	// ConsistentHash pins the requests of each client to a backend endpoint.
+	// It takes precedence over LoadBalance.
+	ConsistentHash *ConsistentHash
+	LoadBalance    LoadBalanceAlgorithm
+	WAF            *WAF
+	Tracing        *Tracing
+	// Compression are the algorithms responses are compressed with, when
+	// clients accept them.
+	Compression []CompressionAlgorithm
+	// MaxRequestBodySize is the largest request body accepted, in bytes.
+	// It is nil when unset and zero when unlimited.
+	MaxRequestBodySize *int64
+	// KeepAliveTimeout is the time idle client connections are kept open,
+	// zero when unset.
+	KeepAliveTimeout time.Duration
+	// HTTP2 is nil unless the controller sets whether clients can use
+	// HTTP/2.
+	HTTP2 *bool
+	// ProxyProtocol reads the client addresses from the PROXY protocol
+	// header of client connections.
+	ProxyProtocol bool
+	// ClientIP is nil unless the address of clients is read from a header
+	// set by the proxies in front of the controller.
+	ClientIP *ClientIP
+	// Snippets are NGINX configuration, which only NGINX based
+	// implementations can apply.
+	Snippets     *Snippets
+	ProxyBuffers *ProxyBuffers
+}
+
+// IsEmpty reports whether the policy configures nothing.
+func (p *Policy) IsEmpty() bool {
+	return p == nil || p.Timeouts == nil && p.Retry == nil && len(p.RateLimits) == 0 && len(p.IPAllowList) == 0 && len(p.IPDenyList) == 0 && p.ExtAuth == nil && p.OIDC == nil &&
+		len(p.TLSCiphers) == 0 && p.TLSPreferServerCiphers == nil && len(p.TLSProtocols) == 0 && p.ClientValidation == nil && !p.SSLRedirect && p.BackendTLS == nil && p.HSTS == nil && p.CustomErrors == nil &&
+		p.ConsistentHash == nil && p.LoadBalance == "" && p.WAF == nil && p.Tracing == nil && len(p.Compression) == 0 && p.MaxRequestBodySize == nil && p.KeepAliveTimeout == 0 && p.HTTP2 == nil && !p.ProxyProtocol && p.ClientIP == nil && p.Snippets == nil && p.ProxyBuffers == nil
+}
+
+// Timeouts of the connections to backends. Zero values are unset.
+type Timeouts struct {
+	// Connect is the time allowed to establish a connection.
+	Connect time.Duration
+	// Read is the time allowed between two reads of the response.
+	Read time.Duration
+	// Send is the time allowed between two writes of the request.
+	Send time.Duration
+}
+
+// Retry sends failed requests to another backend endpoint. Requests are
+// retried on connection errors and timeouts, and on the StatusCodes.
+type Retry struct {
+	// Attempts is the number of retries, zero for the default of the
+	// implementation.
+	Attempts    int
+	StatusCodes []int
+	// Timeout bounds the time spent on a request and its retries, zero when
+	// unlimited.
+	Timeout time.Duration
+}
+
+// RateLimitUnit is the period a RateLimit applies to.
+type RateLimitUnit string
+
+const (
+	RateLimitUnitSecond RateLimitUnit = "Second"
+	RateLimitUnitMinute RateLimitUnit = "Minute"
+)
+
+// RateLimit limits the number of requests each client IP address can send.
+type RateLimit struct {
+	Requests int
+	Unit     RateLimitUnit
+}
+
+// ExtAuth delegates the authentication of requests to an external HTTP
+// service.
+type ExtAuth struct {
+	// URL of the authentication service. Requests are allowed when it
+	// responds with a 2xx status.
+	URL string
+	// ResponseHeaders are the headers of the authentication response that
+	// are copied to the request sent to the backend.
+	ResponseHeaders []string
+}
+
+// OIDC authenticates clients with OpenID Connect. The provider and client
+// credentials are configured on oauth2-proxy rather than on Ingresses, so
+// only the URLs of the authorization flow are known.
+type OIDC struct {
+	// RedirectURL is the callback URL the provider redirects clients to
+	// after they signed in.
+	RedirectURL string
+	// LogoutPath is the path signing clients out.
+	LogoutPath string
+}
+
+// ClientValidation requires TLS clients to present a certificate signed by a
+// trusted CA.
+type ClientValidation struct {
+	// CACertificates is the Secret holding the trusted CA certificates in
+	// its ca.crt key.
+	CACertificates types.NamespacedName
+	// Optional lets clients without a certificate through.
+	Optional bool
+}
+
+// BackendTLS verifies the certificates of backends connected to with TLS.
+type BackendTLS struct {
+	// CACertificates is the Secret holding the CA certificates trusted to
+	// sign backend certificates in its ca.crt key.
+	CACertificates types.NamespacedName
+	// Hostname is the name backend certificates must be valid for, which is
+	// also sent as server name. Empty for the DNS name of the Service.
+	Hostname string
+}
+
+// CustomErrors replaces the error responses of backends with the responses
+// of an error backend.
+type CustomErrors struct {
+	// Codes are the HTTP status codes of the replaced responses.
+	Codes []int
+	// Backend is the Service of the namespace of the Ingress serving the
+	// error pages, which also serves the requests no path matches. It is nil
+	// for the default backend of the controller. The aggregator sets its
+	// port when the Service is part of the input.
+	Backend *networkingv1.IngressServiceBackend
+}
+
+// HSTS is the HTTP Strict Transport Security policy sent to clients in
+// responses.
+type HSTS struct {
+	MaxAge            time.Duration
+	IncludeSubdomains bool
+	Preload           bool
+}
+
+// ClientIP reads the address of clients from a header, such as
+// X-Forwarded-For, instead of the address of the connection.
+type ClientIP struct {
+	Header string
+	// TrustedCIDRs are the addresses of the proxies trusted to set the
+	// header, every proxy when empty.
+	TrustedCIDRs []string
+}
+
+// LoadBalanceAlgorithm selects the backend endpoint of requests.
+type LoadBalanceAlgorithm string
+
+const (
+	LoadBalanceRoundRobin LoadBalanceAlgorithm = "RoundRobin"
+	// LoadBalanceLeastRequest prefers the endpoints with the fewest active
+	// requests.
+	LoadBalanceLeastRequest LoadBalanceAlgorithm = "LeastRequest"
+)
+
+// WAF inspects requests with a web application firewall running ModSecurity
+// rules.
+type WAF struct {
+	// OWASPCoreRules enables the rules of the OWASP Core Rule Set.
+	OWASPCoreRules bool
+	// Rules are additional ModSecurity directives, one per line.
+	Rules string
+}
+
+// Tracing sends the spans of requests to a tracing backend.
+type Tracing struct {
+	// ParentBased samples the requests whose incoming parent span is
+	// sampled, instead of all of them.
+	ParentBased bool
+}
+
+// CompressionAlgorithm compresses response bodies.
+type CompressionAlgorithm string
+
+const (
+	CompressionGzip   CompressionAlgorithm = "Gzip"
+	CompressionBrotli CompressionAlgorithm = "Brotli"
+)
+
+// ConsistentHash selects backend endpoints by hashing a property of requests.
+// Exactly one field is set.
+type ConsistentHash struct {
+	// Header is the name of the hashed request header.
+	Header string
+	// Cookie is created by the proxy when requests don't have it.
+	Cookie *HashCookie
+	// QueryParameter is the name of the hashed query parameter.
+	QueryParameter string
+	SourceIP       bool
+}
+
+// HashCookie is a session affinity cookie.
+type HashCookie struct {
+	Name string
+	Path string
+	// TTL is zero for session cookies.
+	TTL time.Duration
+}
+
+// Snippets are raw NGINX configuration added to the generated configuration.
+type Snippets struct {
+	// Server is added to the server blocks of the hosts of the Ingress.
+	Server string
+	// Location is added to the location blocks of the paths of the Ingress.
+	Location string
+}
+
+// ProxyBuffers configures the buffering of responses from backends.
+type ProxyBuffers struct {
+	// Buffering is nil when unset.
+	Buffering *bool
+	// Size of the buffers, as an NGINX size such as "8k".
+	Size   string
+	Number int
+}
+
+// ParseNGINXSize returns the number of bytes of an NGINX size, a number
+// optionally followed by k, m or g.
+func ParseNGINXSize(size string) (int64, error) {
+	number := strings.ToLower(strings.TrimSpace(size))
+	multiplier := int64(1)
+	if number != "" {
+		switch number[len(number)-1] {
+		case 'k':
+			multiplier = 1 << 10
+		case 'm':
+			multiplier = 1 << 20
+		case 'g':
+			multiplier = 1 << 30
+		}
+		if multiplier != 1 {
+			number = number[:len(number)-1]
+		}
+	}
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", size)
+	}
+	return n * multiplier, nil
+}
@@ -0,0 +1,46 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// RESTConfigGetter returns the config of the cluster of a kubeconfig
+// context, or of the current context if kubeContext is empty.
+type RESTConfigGetter func(kubeContext string) (*rest.Config, error)
+
+// RESTConfig is how the commands get the config of the clusters they read
+// and write. The command line replaces it to honor its kubeconfig flags,
+// such as --kubeconfig, --context and --as.
+var RESTConfig RESTConfigGetter = config.GetConfigWithContext
+
+// newClient returns a client of the cluster of the kubeconfig context.
+func newClient(kubeContext string, opts client.Options) (client.Client, error) {
+	cfg, err := RESTConfig(kubeContext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	cl, err := client.New(cfg, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	return cl, nil
+}
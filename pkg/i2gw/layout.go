@@ -0,0 +1,207 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/printers"
+	"sigs.k8s.io/yaml"
+)
+
+// OutputLayout selects where the command line writes the resources.
+type OutputLayout string
+
+const (
+	// LayoutStdout writes every resource to stdout.
+	LayoutStdout OutputLayout = "stdout"
+	// LayoutGitOps writes every resource to a file of its own in
+	// namespaces/<namespace>/<kind>/ directories of an output directory,
+	// with kustomization files listing them, ready to be committed to a
+	// GitOps repository.
+	LayoutGitOps OutputLayout = "gitops"
+	// LayoutNamespaces writes the resources of every namespace to a file of
+	// its own in an output directory, with an index. See
+	// WriteNamespaceLayout.
+	LayoutNamespaces OutputLayout = "namespaces"
+)
+
+// OutputLayouts returns the names of the supported output layouts.
+func OutputLayouts() []string {
+	return []string{string(LayoutStdout), string(LayoutGitOps), string(LayoutNamespaces)}
+}
+
+func validateOutputLayout(layout OutputLayout, dir string) error {
+	switch layout {
+	case "", LayoutStdout:
+		return nil
+	case LayoutGitOps, LayoutNamespaces:
+		if dir == "" {
+			return fmt.Errorf("the %s output layout requires an output directory", layout)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown output layout %q, supported ones are: %s", layout, strings.Join(OutputLayouts(), ", "))
+	}
+}
+
+// isDirectoryLayout returns whether a layout writes files to an output
+// directory.
+func isDirectoryLayout(layout OutputLayout) bool {
+	return layout == LayoutGitOps || layout == LayoutNamespaces
+}
+
+// GitOpsOptions configures WriteGitOpsLayout.
+type GitOpsOptions struct {
+	// Output is the format of the resources. Resources shaped for
+	// Server-Side Apply are annotated for Argo CD to apply them so.
+	Output OutputFormat
+	// ArgoCD annotates the resources with Argo CD sync waves, so that
+	// Gateways and ReferenceGrants are synced before the HTTPRoutes
+	// attaching to them, and policies last.
+	ArgoCD bool
+	// Clean writes the resources without status and the metadata populated
+	// by the API server, as for Server-Side Apply.
+	Clean bool
+}
+
+const (
+	argoCDSyncWaveAnnotation    = "argocd.argoproj.io/sync-wave"
+	argoCDSyncOptionsAnnotation = "argocd.argoproj.io/sync-options"
+)
+
+// WriteGitOpsLayout writes the resources to dir in the LayoutGitOps layout.
+// The kustomization file of a namespace lists the resources of the
+// conversion, so the files of resources no longer generated are ignored,
+// and the kustomization file of dir lists every namespace of dir, so that
+// the namespaces of several conversions can be written to the same
+// directory.
+func WriteGitOpsLayout(dir string, resources Resources, opts GitOpsOptions) error {
+	byNamespace := map[string][]string{}
+	for _, obj := range resourceObjects(resources) {
+		u, err := layoutObject(obj, opts)
+		if err != nil {
+			return fmt.Errorf("failed to convert %s %s/%s: %w", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetNamespace(), obj.GetName(), err)
+		}
+		namespaceDir := filepath.Join("namespaces", u.GetNamespace())
+		if u.GetNamespace() == "" {
+			namespaceDir = "cluster"
+		}
+		file := filepath.Join(kindDirectory(u.GetKind()), u.GetName()+".yaml")
+		var buf bytes.Buffer
+		if err := (&printers.YAMLPrinter{}).PrintObj(u, &buf); err != nil {
+			return fmt.Errorf("failed to print %s %s/%s: %w", u.GetKind(), u.GetNamespace(), u.GetName(), err)
+		}
+		if err := writeLayoutFile(filepath.Join(dir, namespaceDir, file), buf.Bytes()); err != nil {
+			return err
+		}
+		byNamespace[namespaceDir] = append(byNamespace[namespaceDir], filepath.ToSlash(file))
+	}
+
+	for namespaceDir, files := range byNamespace {
+		sort.Strings(files)
+		if err := writeKustomization(filepath.Join(dir, namespaceDir), files); err != nil {
+			return err
+		}
+	}
+
+	var namespaceDirs []string
+	for _, pattern := range []string{"cluster", filepath.Join("namespaces", "*")} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern, "kustomization.yaml"))
+		if err != nil {
+			return err
+		}
+		for _, match := range matches {
+			rel, err := filepath.Rel(dir, filepath.Dir(match))
+			if err != nil {
+				return err
+			}
+			namespaceDirs = append(namespaceDirs, filepath.ToSlash(rel))
+		}
+	}
+	sort.Strings(namespaceDirs)
+	return writeKustomization(dir, namespaceDirs)
+}
+
+// layoutObject returns obj in the output format, with the Argo CD
+// annotations of opts.
+func layoutObject(obj runtime.Object, opts GitOpsOptions) (*unstructured.Unstructured, error) {
+	u, err := toUnstructured(obj, opts.Clean || opts.Output == OutputServerSideApply)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.ArgoCD {
+		return u, nil
+	}
+
+	annotations := u.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	switch u.GetKind() {
+	case "Gateway", "ReferenceGrant":
+		annotations[argoCDSyncWaveAnnotation] = "0"
+	case "HTTPRoute":
+		annotations[argoCDSyncWaveAnnotation] = "1"
+	default:
+		annotations[argoCDSyncWaveAnnotation] = "2"
+	}
+	if opts.Output == OutputServerSideApply {
+		annotations[argoCDSyncOptionsAnnotation] = "ServerSideApply=true"
+	}
+	u.SetAnnotations(annotations)
+	return u, nil
+}
+
+// kindDirectory returns the directory of the resources of a kind, its
+// lowercase plural.
+func kindDirectory(kind string) string {
+	name := strings.ToLower(kind)
+	if strings.HasSuffix(name, "y") && !strings.ContainsAny(name[len(name)-2:len(name)-1], "aeiou") {
+		return name[:len(name)-1] + "ies"
+	}
+	return name + "s"
+}
+
+func writeKustomization(dir string, resources []string) error {
+	kustomization := map[string]any{
+		"apiVersion": "kustomize.config.k8s.io/v1beta1",
+		"kind":       "Kustomization",
+		"resources":  resources,
+	}
+	data, err := yaml.Marshal(kustomization)
+	if err != nil {
+		return fmt.Errorf("failed to marshal kustomization: %w", err)
+	}
+	return writeLayoutFile(filepath.Join(dir, "kustomization.yaml"), data)
+}
+
+func writeLayoutFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_WriteGitOpsLayout(t *testing.T) {
+	className := "nginx"
+	ingress := func(namespace string) networkingv1.Ingress {
+		return networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "web"},
+			Spec: networkingv1.IngressSpec{
+				IngressClassName: &className,
+				Rules: []networkingv1.IngressRule{{
+					Host: "example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     "/",
+							PathType: ptrTo(networkingv1.PathTypePrefix),
+							Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+								Name: "web",
+								Port: networkingv1.ServiceBackendPort{Number: 80},
+							}},
+						}},
+					}},
+				}},
+			},
+		}
+	}
+	resources, _, err := Convert(context.Background(), ConvertOptions{Ingresses: []networkingv1.Ingress{ingress("a"), ingress("b")}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	dir := t.TempDir()
+	if err := WriteGitOpsLayout(dir, resources, GitOpsOptions{Output: OutputServerSideApply, ArgoCD: true}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var files []string
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		files = append(files, filepath.ToSlash(rel))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Failed to list output: %v", err)
+	}
+	expectedFiles := []string{
+		"kustomization.yaml",
+		"namespaces/a/gateways/nginx.yaml",
+		"namespaces/a/httproutes/example-com.yaml",
+		"namespaces/a/kustomization.yaml",
+		"namespaces/b/gateways/nginx.yaml",
+		"namespaces/b/httproutes/example-com.yaml",
+		"namespaces/b/kustomization.yaml",
+	}
+	if diff := cmp.Diff(expectedFiles, files); diff != "" {
+		t.Errorf("Unexpected files, diff (-want +got): %s", diff)
+	}
+
+	readFile := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		return string(data)
+	}
+	expectedKustomization := `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- gateways/nginx.yaml
+- httproutes/example-com.yaml
+`
+	if diff := cmp.Diff(expectedKustomization, readFile("namespaces/a/kustomization.yaml")); diff != "" {
+		t.Errorf("Unexpected namespace kustomization, diff (-want +got): %s", diff)
+	}
+	if got := readFile("kustomization.yaml"); !strings.Contains(got, "- namespaces/a\n- namespaces/b\n") {
+		t.Errorf("Expected the kustomization to list the namespaces, got:\n%s", got)
+	}
+	route := readFile("namespaces/a/httproutes/example-com.yaml")
+	for _, expected := range []string{`argocd.argoproj.io/sync-wave: "1"`, "argocd.argoproj.io/sync-options: ServerSideApply=true"} {
+		if !strings.Contains(route, expected) {
+			t.Errorf("Expected the HTTPRoute to contain %q, got:\n%s", expected, route)
+		}
+	}
+	if strings.Contains(route, "status:") {
+		t.Errorf("Expected the HTTPRoute to be shaped for Server-Side Apply, got:\n%s", route)
+	}
+}
+
+func Test_kindDirectory(t *testing.T) {
+	for kind, expected := range map[string]string{
+		"Gateway":          "gateways",
+		"HTTPRoute":        "httproutes",
+		"BackendTLSPolicy": "backendtlspolicies",
+	} {
+		if got := kindDirectory(kind); got != expected {
+			t.Errorf("kindDirectory(%q) = %q, expected %q", kind, got, expected)
+		}
+	}
+}
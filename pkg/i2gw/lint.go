@@ -0,0 +1,264 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// LintOptions are the options of Lint.
+type LintOptions struct {
+	// InputFile is a manifest file to read the Ingresses and Services from.
+	InputFile string
+	// Client lists the Ingresses and Services of the cluster, when set.
+	Client client.Client
+}
+
+// LintPriority is how urgently a lint issue should be remediated before the
+// migration.
+type LintPriority string
+
+const (
+	// LintHigh issues are constructs the conversion can't translate.
+	LintHigh LintPriority = "high"
+	// LintMedium issues are translated with a behavior that may differ.
+	LintMedium LintPriority = "medium"
+	// LintLow issues only need checking after the conversion.
+	LintLow LintPriority = "low"
+)
+
+var lintPriorityRanks = map[LintPriority]int{LintHigh: 0, LintMedium: 1, LintLow: 2}
+
+// LintIssue is a construct of an Ingress that is unconvertible or risky to
+// convert, and how to remediate it.
+type LintIssue struct {
+	Priority    LintPriority `json:"priority"`
+	Rule        string       `json:"rule"`
+	Ingress     string       `json:"ingress"`
+	Message     string       `json:"message"`
+	Remediation string       `json:"remediation"`
+}
+
+const (
+	lintBackendProtocolAnnotation = "nginx.ingress.kubernetes.io/backend-protocol"
+	lintUseRegexAnnotation        = "nginx.ingress.kubernetes.io/use-regex"
+	lintRewriteTargetAnnotation   = "nginx.ingress.kubernetes.io/rewrite-target"
+)
+
+// Lint reads the Ingresses of the input file and the cluster and returns the
+// issues found in them, the most urgent ones first.
+func Lint(ctx context.Context, opts LintOptions) ([]LintIssue, error) {
+	var input inputResources
+	if opts.InputFile != "" {
+		var err error
+		input, err = readInputFromFile(opts.InputFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input from %s: %w", opts.InputFile, err)
+		}
+	}
+	services := map[types.NamespacedName][]corev1.ServicePort{}
+	for _, obj := range input.objects {
+		if obj.GetAPIVersion() != "v1" || obj.GetKind() != "Service" {
+			continue
+		}
+		service := corev1.Service{}
+		if err := k8sruntime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &service); err != nil {
+			continue
+		}
+		services[types.NamespacedName{Namespace: service.Namespace, Name: service.Name}] = service.Spec.Ports
+	}
+	if opts.Client != nil {
+		ingressList := &networkingv1.IngressList{}
+		if err := opts.Client.List(ctx, ingressList); err != nil {
+			return nil, fmt.Errorf("failed to list ingresses: %w", err)
+		}
+		input.ingresses = append(input.ingresses, ingressList.Items...)
+		serviceList := &corev1.ServiceList{}
+		if err := opts.Client.List(ctx, serviceList); err != nil {
+			return nil, fmt.Errorf("failed to list services: %w", err)
+		}
+		for _, service := range serviceList.Items {
+			services[types.NamespacedName{Namespace: service.Namespace, Name: service.Name}] = service.Spec.Ports
+		}
+	}
+	return lintIngresses(input.ingresses, services), nil
+}
+
+// lintIngresses returns the issues of the Ingresses, sorted by priority and
+// Ingress. Named ports are checked against the ports of the Services when
+// they are known.
+func lintIngresses(ingresses []networkingv1.Ingress, services map[types.NamespacedName][]corev1.ServicePort) []LintIssue {
+	var issues []LintIssue
+	for _, ingress := range ingresses {
+		name := ingress.Namespace + "/" + ingress.Name
+		add := func(priority LintPriority, rule, remediation, format string, args ...any) {
+			issues = append(issues, LintIssue{Priority: priority, Rule: rule, Ingress: name, Message: fmt.Sprintf(format, args...), Remediation: remediation})
+		}
+
+		var controllerAnnotations []string
+		for annotation, value := range ingress.Annotations {
+			switch {
+			case hasControllerPrefix(annotation) && (strings.HasSuffix(annotation, "-snippet") || strings.HasSuffix(annotation, "-snippets")):
+				add(LintHigh, "snippet", "Move the configuration to HTTPRoute filters or to the policies of the target implementation, or drop it if it is no longer needed.",
+					"annotation %s holds raw controller configuration", annotation)
+			case annotation == lintBackendProtocolAnnotation && (strings.EqualFold(value, "AJP") || strings.EqualFold(value, "FCGI")):
+				add(LintHigh, "backend-protocol", "Serve HTTP from the backends, or keep them behind a proxy translating the protocol.",
+					"backends are reached with %s, which Gateway API has no equivalent for", strings.ToUpper(value))
+			case annotation == lintBackendProtocolAnnotation, annotation == lintUseRegexAnnotation, annotation == lintRewriteTargetAnnotation:
+			case hasControllerPrefix(annotation):
+				controllerAnnotations = append(controllerAnnotations, annotation)
+			}
+		}
+		if len(controllerAnnotations) > 0 {
+			sort.Strings(controllerAnnotations)
+			add(LintLow, "controller-annotations", "Check in the conversion report how each annotation is converted.",
+				"controller annotations %s", strings.Join(controllerAnnotations, ", "))
+		}
+
+		regex := ingress.Annotations[lintUseRegexAnnotation] == "true" || ingress.Annotations[lintRewriteTargetAnnotation] != ""
+		seenPorts := map[string]bool{}
+		checkBackend := func(backend *networkingv1.IngressBackend) {
+			if backend == nil || backend.Service == nil || backend.Service.Port.Name == "" {
+				return
+			}
+			service := types.NamespacedName{Namespace: ingress.Namespace, Name: backend.Service.Name}
+			if seenPorts[service.String()+":"+backend.Service.Port.Name] {
+				return
+			}
+			seenPorts[service.String()+":"+backend.Service.Port.Name] = true
+			ports, ok := services[service]
+			if !ok {
+				add(LintMedium, "named-port", "Use the port number, or include the Service in the conversion input for the port to be resolved.",
+					"backend Service %s is referenced by the port name %s", service.Name, backend.Service.Port.Name)
+				return
+			}
+			if _, ok := findServicePort(ports, backend.Service.Port); !ok {
+				add(LintHigh, "named-port", "Fix the port name of the backend, or add the port to the Service.",
+					"backend Service %s has no port named %s", service.Name, backend.Service.Port.Name)
+			}
+		}
+		checkBackend(ingress.Spec.DefaultBackend)
+		for _, rule := range ingress.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				checkBackend(&path.Backend)
+				if !regex || !isLintRegexPath(path) {
+					continue
+				}
+				if err := lintRegexPath(path.Path); err != nil {
+					add(LintHigh, "regex-path", "Rewrite the expression without PCRE only syntax, such as lookarounds and backreferences, or split it into several paths.",
+						"path %s uses an expression RE2 can't express: %v", path.Path, err)
+					continue
+				}
+				add(LintMedium, "regex-path", "Replace the expression with Prefix or Exact paths where possible, and check that the target implementation supports RegularExpression path matches.",
+					"path %s is a regular expression", path.Path)
+			}
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Priority != issues[j].Priority {
+			return lintPriorityRanks[issues[i].Priority] < lintPriorityRanks[issues[j].Priority]
+		}
+		if issues[i].Ingress != issues[j].Ingress {
+			return issues[i].Ingress < issues[j].Ingress
+		}
+		if issues[i].Rule != issues[j].Rule {
+			return issues[i].Rule < issues[j].Rule
+		}
+		return issues[i].Message < issues[j].Message
+	})
+	return issues
+}
+
+// isLintRegexPath tells whether ingress-nginx matches the path as a regular
+// expression when regular expressions are enabled for the Ingress.
+func isLintRegexPath(path networkingv1.HTTPIngressPath) bool {
+	if path.PathType != nil && *path.PathType == networkingv1.PathTypeExact {
+		return false
+	}
+	return path.PathType == nil || *path.PathType != networkingv1.PathTypePrefix || regexp.QuoteMeta(path.Path) != path.Path
+}
+
+// lintRegexPath returns why the PCRE expression of a path can't be parsed
+// as an RE2 expression.
+func lintRegexPath(path string) error {
+	if strings.HasPrefix(path, "^~") {
+		return nil
+	}
+	_, err := syntax.Parse(path, syntax.Perl)
+	return err
+}
+
+// WriteLintIssues writes the issues as a list grouped by priority, each
+// followed by its remediation.
+func WriteLintIssues(w io.Writer, issues []LintIssue) error {
+	for _, issue := range issues {
+		if _, err := fmt.Fprintf(w, "[%s] %s %s: %s\n    %s\n", issue.Priority, issue.Rule, issue.Ingress, issue.Message, issue.Remediation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunLint lints the Ingresses of the input file, or of the cluster without
+// one, and exits with an error when high priority issues are found.
+func RunLint(opts LintOptions) {
+	if opts.InputFile == "" {
+		cl, err := newClient("", client.Options{})
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		opts.Client = cl
+	}
+
+	ctx, cancel := runContext(0)
+	defer cancel()
+	issues, err := Lint(ctx, opts)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err := WriteLintIssues(os.Stdout, issues); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if len(issues) == 0 {
+		fmt.Println("No issues found")
+		return
+	}
+	for _, issue := range issues {
+		if issue.Priority == LintHigh {
+			os.Exit(1)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func Test_lintIngresses(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	implementationSpecific := networkingv1.PathTypeImplementationSpecific
+	namedBackend := networkingv1.IngressBackend{
+		Service: &networkingv1.IngressServiceBackend{Name: "svc", Port: networkingv1.ServiceBackendPort{Name: "http"}},
+	}
+
+	testCases := []struct {
+		name      string
+		ingresses []networkingv1.Ingress
+		services  map[types.NamespacedName][]corev1.ServicePort
+		want      []LintIssue
+	}{{
+		name:      "plain ingress",
+		ingresses: []networkingv1.Ingress{ingressWithPath("plain", "/", &prefix, serviceBackend("svc", 80), nil)},
+	}, {
+		name: "snippets and AJP backends",
+		ingresses: []networkingv1.Ingress{ingressWithPath("snippets", "/", &prefix, serviceBackend("svc", 80), map[string]string{
+			"nginx.ingress.kubernetes.io/server-snippet":   "return 403;",
+			"nginx.org/location-snippets":                  "add_header X-Test 1;",
+			"nginx.ingress.kubernetes.io/backend-protocol": "ajp",
+		})},
+		want: []LintIssue{{
+			Priority:    LintHigh,
+			Rule:        "backend-protocol",
+			Ingress:     "test/snippets",
+			Message:     "backends are reached with AJP, which Gateway API has no equivalent for",
+			Remediation: "Serve HTTP from the backends, or keep them behind a proxy translating the protocol.",
+		}, {
+			Priority:    LintHigh,
+			Rule:        "snippet",
+			Ingress:     "test/snippets",
+			Message:     "annotation nginx.ingress.kubernetes.io/server-snippet holds raw controller configuration",
+			Remediation: "Move the configuration to HTTPRoute filters or to the policies of the target implementation, or drop it if it is no longer needed.",
+		}, {
+			Priority:    LintHigh,
+			Rule:        "snippet",
+			Ingress:     "test/snippets",
+			Message:     "annotation nginx.org/location-snippets holds raw controller configuration",
+			Remediation: "Move the configuration to HTTPRoute filters or to the policies of the target implementation, or drop it if it is no longer needed.",
+		}},
+	}, {
+		name: "regex paths",
+		ingresses: []networkingv1.Ingress{
+			ingressWithPath("lookahead", "/api/(?!internal)", &implementationSpecific, serviceBackend("svc", 80), map[string]string{"nginx.ingress.kubernetes.io/use-regex": "true"}),
+			ingressWithPath("regex", "/api/v[0-9]+", &implementationSpecific, serviceBackend("svc", 80), map[string]string{"nginx.ingress.kubernetes.io/use-regex": "true"}),
+			ingressWithPath("literal", "/api", &prefix, serviceBackend("svc", 80), map[string]string{"nginx.ingress.kubernetes.io/use-regex": "true"}),
+			ingressWithPath("disabled", "/api/v[0-9]+", &implementationSpecific, serviceBackend("svc", 80), nil),
+		},
+		want: []LintIssue{{
+			Priority:    LintHigh,
+			Rule:        "regex-path",
+			Ingress:     "test/lookahead",
+			Message:     "path /api/(?!internal) uses an expression RE2 can't express: error parsing regexp: invalid or unsupported Perl syntax: `(?!`",
+			Remediation: "Rewrite the expression without PCRE only syntax, such as lookarounds and backreferences, or split it into several paths.",
+		}, {
+			Priority:    LintMedium,
+			Rule:        "regex-path",
+			Ingress:     "test/regex",
+			Message:     "path /api/v[0-9]+ is a regular expression",
+			Remediation: "Replace the expression with Prefix or Exact paths where possible, and check that the target implementation supports RegularExpression path matches.",
+		}},
+	}, {
+		name: "named ports",
+		ingresses: []networkingv1.Ingress{
+			ingressWithPath("unknown", "/", &prefix, namedBackend, nil),
+			ingressWithPath("missing", "/", &prefix, networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "web", Port: networkingv1.ServiceBackendPort{Name: "https"}}}, nil),
+			ingressWithPath("resolved", "/", &prefix, networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "web", Port: networkingv1.ServiceBackendPort{Name: "http"}}}, nil),
+		},
+		services: map[types.NamespacedName][]corev1.ServicePort{
+			{Namespace: "test", Name: "web"}: {{Name: "http", Port: 8080}},
+		},
+		want: []LintIssue{{
+			Priority:    LintHigh,
+			Rule:        "named-port",
+			Ingress:     "test/missing",
+			Message:     "backend Service web has no port named https",
+			Remediation: "Fix the port name of the backend, or add the port to the Service.",
+		}, {
+			Priority:    LintMedium,
+			Rule:        "named-port",
+			Ingress:     "test/unknown",
+			Message:     "backend Service svc is referenced by the port name http",
+			Remediation: "Use the port number, or include the Service in the conversion input for the port to be resolved.",
+		}},
+	}, {
+		name: "other controller annotations",
+		ingresses: []networkingv1.Ingress{ingressWithPath("annotated", "/", &prefix, serviceBackend("svc", 80), map[string]string{
+			"nginx.ingress.kubernetes.io/ssl-redirect":  "true",
+			"nginx.ingress.kubernetes.io/proxy-timeout": "30",
+			"nginx.ingress.kubernetes.io/use-regex":     "false",
+			"example.com/owner":                         "team",
+		})},
+		want: []LintIssue{{
+			Priority:    LintLow,
+			Rule:        "controller-annotations",
+			Ingress:     "test/annotated",
+			Message:     "controller annotations nginx.ingress.kubernetes.io/proxy-timeout, nginx.ingress.kubernetes.io/ssl-redirect",
+			Remediation: "Check in the conversion report how each annotation is converted.",
+		}},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := lintIngresses(tc.ingresses, tc.services)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("lintIngresses() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLint_inputFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.yaml")
+	manifest := `apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+  namespace: test
+spec:
+  rules:
+  - host: example.com
+    http:
+      paths:
+      - path: /
+        pathType: Prefix
+        backend:
+          service:
+            name: web
+            port:
+              name: http
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: test
+spec:
+  ports:
+  - name: grpc
+    port: 9090
+`
+	if err := os.WriteFile(path, []byte(manifest), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Lint(context.Background(), LintOptions{InputFile: path})
+	if err != nil {
+		t.Fatalf("Lint() error = %v", err)
+	}
+	want := []LintIssue{{
+		Priority:    LintHigh,
+		Rule:        "named-port",
+		Ingress:     "test/web",
+		Message:     "backend Service web has no port named http",
+		Remediation: "Fix the port name of the backend, or add the port to the Service.",
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Lint() mismatch (-want +got):\n%s", diff)
+	}
+}
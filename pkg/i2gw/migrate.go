@@ -0,0 +1,109 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MigratedToAnnotation lists the routes an Ingress was converted to, as
+// comma-separated kind/name pairs such as httproute/example-com, prefixed
+// with the namespace of the route when it isn't the one of the Ingress.
+// Ingresses with this annotation are skipped by later conversions, unless
+// ConvertOptions.IncludeMigrated is set.
+const MigratedToAnnotation = "ingress2gateway.kubernetes.io/migrated-to"
+
+// migratedTo returns the MigratedToAnnotation of every Ingress routes were
+// converted from.
+func migratedTo(sources map[ObjectRef][]IngressSource) map[types.NamespacedName]string {
+	routes := map[types.NamespacedName][]string{}
+	for ref, objectSources := range sources {
+		if ref.Kind == "Gateway" {
+			continue
+		}
+		for _, source := range objectSources {
+			route := strings.ToLower(ref.Kind) + "/" + ref.Name
+			if ref.Namespace != source.Ingress.Namespace {
+				route = strings.ToLower(ref.Kind) + "/" + ref.Namespace + "/" + ref.Name
+			}
+			routes[source.Ingress] = append(routes[source.Ingress], route)
+		}
+	}
+	values := map[types.NamespacedName]string{}
+	for ingress, ingressRoutes := range routes {
+		sort.Strings(ingressRoutes)
+		values[ingress] = strings.Join(ingressRoutes, ",")
+	}
+	return values
+}
+
+// MarkMigrated annotates the Ingresses the resources were converted from
+// with MigratedToAnnotation, and returns the Ingresses annotated. It stops
+// at the first Ingress failing to be patched.
+func MarkMigrated(ctx context.Context, cl client.Client, resources Resources, dryRun bool) ([]types.NamespacedName, error) {
+	values := migratedTo(resources.Sources)
+	ingresses := make([]types.NamespacedName, 0, len(values))
+	for ingress := range values {
+		ingresses = append(ingresses, ingress)
+	}
+	ingresses = sortedNamespacedNames(ingresses)
+
+	var patchOpts []client.PatchOption
+	if dryRun {
+		patchOpts = append(patchOpts, client.DryRunAll)
+	}
+	var marked []types.NamespacedName
+	for _, name := range ingresses {
+		patch, err := json.Marshal(map[string]any{
+			"metadata": map[string]any{
+				"annotations": map[string]string{MigratedToAnnotation: values[name]},
+			},
+		})
+		if err != nil {
+			return marked, err
+		}
+		ingress := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: name.Namespace, Name: name.Name}}
+		if err := cl.Patch(ctx, ingress, client.RawPatch(types.MergePatchType, patch), patchOpts...); err != nil {
+			return marked, fmt.Errorf("failed to annotate Ingress %s: %w", name, err)
+		}
+		marked = append(marked, name)
+	}
+	return marked, nil
+}
+
+// markMigrated annotates the Ingresses of the source cluster, writing the
+// Ingresses annotated to w.
+func markMigrated(ctx context.Context, w io.Writer, cl client.Client, resources Resources, dryRun bool) error {
+	marked, err := MarkMigrated(ctx, cl, resources, dryRun)
+	suffix := ""
+	if dryRun {
+		suffix = " (server dry run)"
+	}
+	for _, name := range marked {
+		fmt.Fprintf(w, "Ingress %s annotated as migrated%s\n", name, suffix)
+	}
+	return err
+}
@@ -0,0 +1,113 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_MarkMigrated(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	web := ingressWithPath("web", "/", &prefix, serviceBackend("web", 80), nil)
+	api := ingressWithPath("api", "/api", &prefix, serviceBackend("api", 80), nil)
+	api.Spec.Rules[0].Host = "api.example.com"
+	cl := fake.NewClientBuilder().WithObjects(web.DeepCopy(), api.DeepCopy()).Build()
+
+	resources, _, err := Convert(context.Background(), ConvertOptions{Ingresses: []networkingv1.Ingress{web, api}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	marked, err := MarkMigrated(context.Background(), cl, resources, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectMarked := []types.NamespacedName{{Namespace: "test", Name: "api"}, {Namespace: "test", Name: "web"}}
+	if diff := cmp.Diff(expectMarked, marked); diff != "" {
+		t.Errorf("Unexpected marked Ingresses, diff (-want +got): %s", diff)
+	}
+
+	annotations := map[string]string{}
+	var ingresses []networkingv1.Ingress
+	for _, name := range marked {
+		ingress := networkingv1.Ingress{}
+		if err := cl.Get(context.Background(), name, &ingress); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		annotations[name.Name] = ingress.Annotations[MigratedToAnnotation]
+		ingresses = append(ingresses, ingress)
+	}
+	expectAnnotations := map[string]string{"api": "httproute/api-example-com", "web": "httproute/example-com"}
+	if diff := cmp.Diff(expectAnnotations, annotations); diff != "" {
+		t.Errorf("Unexpected %s annotations, diff (-want +got): %s", MigratedToAnnotation, diff)
+	}
+
+	testCases := []struct {
+		name            string
+		includeMigrated bool
+		expectedRoutes  int
+	}{
+		{name: "skip migrated", expectedRoutes: 0},
+		{name: "include migrated", includeMigrated: true, expectedRoutes: 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resources, report, err := Convert(context.Background(), ConvertOptions{Ingresses: ingresses, IncludeMigrated: tc.includeMigrated})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(resources.HTTPRoutes) != tc.expectedRoutes {
+				t.Errorf("Expected %d HTTPRoutes, got %d", tc.expectedRoutes, len(resources.HTTPRoutes))
+			}
+			skipped := false
+			for _, n := range report.Notifications {
+				if n.String() == "INFO: Ingress test/web is skipped since it is already migrated to httproute/example-com" {
+					skipped = true
+				}
+			}
+			if skipped == tc.includeMigrated {
+				t.Errorf("Expected the skipped notification %v, got notifications %v", !tc.includeMigrated, report.Notifications)
+			}
+		})
+	}
+}
+
+func Test_MarkMigrated_dryRun(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	web := ingressWithPath("web", "/", &prefix, serviceBackend("web", 80), nil)
+	cl := fake.NewClientBuilder().WithObjects(web.DeepCopy()).Build()
+
+	resources, _, err := Convert(context.Background(), ConvertOptions{Ingresses: []networkingv1.Ingress{web}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := MarkMigrated(context.Background(), cl, resources, true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ingress := networkingv1.Ingress{}
+	if err := cl.Get(context.Background(), client.ObjectKeyFromObject(&web), &ingress); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if value, ok := ingress.Annotations[MigratedToAnnotation]; ok {
+		t.Errorf("Expected a dry run not to annotate the Ingress, got %q", value)
+	}
+}
@@ -0,0 +1,249 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"k8s.io/apimachinery/pkg/types"
+	apimachineryvalidation "k8s.io/apimachinery/pkg/util/validation"
+)
+
+// RouteNaming selects what the names of HTTPRoutes are derived from.
+type RouteNaming string
+
+const (
+	// RouteNamingHost names HTTPRoutes after their host.
+	RouteNamingHost RouteNaming = "host"
+	// RouteNamingIngress names HTTPRoutes after the oldest Ingress they
+	// were converted from, followed by their host, so that the routes of
+	// distinct teams sharing a namespace are told apart.
+	RouteNamingIngress RouteNaming = "ingress"
+)
+
+// RouteNamings returns the names of the supported route namings.
+func RouteNamings() []string {
+	return []string{string(RouteNamingHost), string(RouteNamingIngress)}
+}
+
+func validateRouteNaming(naming RouteNaming) error {
+	switch naming {
+	case "", RouteNamingHost, RouteNamingIngress:
+		return nil
+	default:
+		return fmt.Errorf("unknown route naming %q, supported ones are: %s", naming, strings.Join(RouteNamings(), ", "))
+	}
+}
+
+// NameGenerator returns the name of a generated object of kind Gateway or
+// HTTPRoute, to enforce naming conventions. For Gateways, source is the
+// IngressClass or the group the Gateway is named after by default, and host
+// is empty. For HTTPRoutes, source is the namespace/name of the oldest
+// Ingress the route was converted from, and host is the host of the route,
+// empty for routes of any host. Returning an empty name keeps the default
+// one.
+type NameGenerator func(source, kind, host string) string
+
+// generateNames renames the Gateways and HTTPRoutes of the IR with generate,
+// keeping the default names that generate leaves empty, that are invalid or
+// that are taken by another Gateway of the namespace. HTTPRoutes sharing a
+// name are renamed afterwards by uniqueHTTPRouteNames.
+func generateNames(result *ir.IR, generate NameGenerator) []Notification {
+	var notes []Notification
+	valid := func(kind, namespace, defaultName, name string) bool {
+		if msgs := apimachineryvalidation.IsDNS1123Subdomain(name); len(msgs) > 0 {
+			notes = append(notes, notifications.NewWarning("Generated name %q of %s %s/%s is invalid, keeping the default name: %s", name, kind, namespace, defaultName, strings.Join(msgs, ", ")))
+			return false
+		}
+		return true
+	}
+
+	gatewayNames := map[types.NamespacedName]string{}
+	taken := map[types.NamespacedName]bool{}
+	for _, gw := range result.Gateways {
+		taken[types.NamespacedName{Namespace: gw.Namespace, Name: gw.Name}] = true
+	}
+	for i := range result.Gateways {
+		gw := &result.Gateways[i]
+		name := generate(gw.Name, "Gateway", "")
+		if name == "" || name == gw.Name || !valid("Gateway", gw.Namespace, gw.Name, name) {
+			continue
+		}
+		if taken[types.NamespacedName{Namespace: gw.Namespace, Name: name}] {
+			notes = append(notes, notifications.NewWarning("Generated name %q of Gateway %s/%s is taken by another Gateway, keeping the default name", name, gw.Namespace, gw.Name))
+			continue
+		}
+		key := types.NamespacedName{Namespace: gw.Namespace, Name: gw.Name}
+		delete(taken, key)
+		taken[types.NamespacedName{Namespace: gw.Namespace, Name: name}] = true
+		gatewayNames[key] = name
+		gw.Name = name
+	}
+
+	ingressOrder := map[types.NamespacedName]int{}
+	for i, ingress := range result.Ingresses {
+		ingressOrder[types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}] = i
+	}
+	for i := range result.HTTPRoutes {
+		route := &result.HTTPRoutes[i]
+		if name, ok := gatewayNames[route.Gateway()]; ok {
+			route.GatewayName = name
+		}
+		name := generate(oldestSource(*route, ingressOrder).String(), "HTTPRoute", route.Hostname)
+		if name == "" || name == route.Name || !valid("HTTPRoute", route.Namespace, route.Name, name) {
+			continue
+		}
+		route.Name = name
+	}
+	for i := range result.StreamRoutes {
+		route := &result.StreamRoutes[i]
+		if name, ok := gatewayNames[route.Gateway()]; ok {
+			route.GatewayName = name
+		}
+	}
+	return notes
+}
+
+// oldestSource returns the oldest Ingress an HTTPRoute was converted from,
+// given the order of the Ingresses, oldest first.
+func oldestSource(route ir.HTTPRoute, ingressOrder map[types.NamespacedName]int) types.NamespacedName {
+	var sources []types.NamespacedName
+	for _, rule := range route.Rules {
+		for _, backend := range rule.Backends {
+			sources = append(sources, backend.Source)
+		}
+	}
+	for source := range route.Policies {
+		sources = append(sources, source)
+	}
+	var oldest types.NamespacedName
+	found := false
+	for _, source := range sources {
+		order, ok := ingressOrder[source]
+		if !ok {
+			continue
+		}
+		if !found || order < ingressOrder[oldest] {
+			oldest = source
+			found = true
+		}
+	}
+	return oldest
+}
+
+const (
+	// maxDNSLabelLength is the maximum length of an RFC 1123 label.
+	maxDNSLabelLength = 63
+	// maxObjectNameLength is the maximum length of an RFC 1123 subdomain,
+	// which is what most Kubernetes object names must be.
+	maxObjectNameLength = 253
+	// nameHashLength is the number of hex characters of the hash appended to
+	// names that had to be truncated.
+	nameHashLength = 8
+)
+
+var invalidNameChars = regexp.MustCompile("[^a-z0-9]+")
+
+// nameFromHost returns an RFC 1123 label derived from host. Wildcard hosts
+// are prefixed with "wildcard-" so they don't collide with the host they
+// cover, and names exceeding the label length are truncated and suffixed
+// with a short hash of the host to keep them unique.
+func nameFromHost(host string) string {
+	if host == "" {
+		return "all-hosts"
+	}
+	name := strings.ToLower(host)
+	if strings.HasPrefix(name, "*.") {
+		name = "wildcard-" + name[2:]
+	}
+	name = strings.Trim(invalidNameChars.ReplaceAllString(name, "-"), "-")
+	if name == "" {
+		return "host-" + shortHash(host)
+	}
+	return truncateName(name, host, maxDNSLabelLength)
+}
+
+// truncateName shortens name to at most maxLength characters. Truncated names
+// end with a hash of seed so that distinct inputs sharing a long prefix still
+// produce distinct names.
+func truncateName(name, seed string, maxLength int) string {
+	if len(name) <= maxLength {
+		return name
+	}
+	hash := shortHash(seed)
+	prefix := strings.TrimRight(name[:maxLength-len(hash)-1], "-.")
+	return prefix + "-" + hash
+}
+
+func shortHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])[:nameHashLength]
+}
+
+// uniqueHTTPRouteNames renames the HTTPRoutes sharing the name of another
+// HTTPRoute of their namespace, such as the HTTPRoutes of the same host for
+// two IngressClasses. Which route keeps the name, and the names of the
+// others, only depend on the Gateways and hosts of the routes, not on the
+// order they were generated in, so identical input always gets identical
+// names.
+func uniqueHTTPRouteNames(result *ir.IR) []Notification {
+	byName := map[string][]int{}
+	var names []string
+	taken := map[string]bool{}
+	for i, route := range result.HTTPRoutes {
+		key := route.Namespace + "/" + route.Name
+		if _, ok := byName[key]; !ok {
+			names = append(names, key)
+		}
+		byName[key] = append(byName[key], i)
+		taken[key] = true
+	}
+
+	var notes []Notification
+	for _, key := range names {
+		indexes := byName[key]
+		if len(indexes) < 2 {
+			continue
+		}
+		identity := func(i int) string {
+			route := result.HTTPRoutes[i]
+			return fmt.Sprintf("%s/%s/%s", route.Gateway(), route.Hostname, route.Name)
+		}
+		sort.SliceStable(indexes, func(i, j int) bool {
+			return identity(indexes[i]) < identity(indexes[j])
+		})
+		for n, i := range indexes[1:] {
+			route := &result.HTTPRoutes[i]
+			seed := identity(i)
+			name := truncateName(route.Name+"-"+shortHash(seed), seed, maxObjectNameLength)
+			for attempt := 1; taken[route.Namespace+"/"+name]; attempt++ {
+				seed = fmt.Sprintf("%s/%d", identity(i), n+attempt)
+				name = truncateName(route.Name+"-"+shortHash(seed), seed, maxObjectNameLength)
+			}
+			taken[route.Namespace+"/"+name] = true
+			notes = append(notes, notifications.NewInfo("HTTPRoute %s of Gateway %s for host %q is renamed %s, its name is taken by the HTTPRoute of Gateway %s", key, route.Gateway(), route.Hostname, name, result.HTTPRoutes[indexes[0]].Gateway()))
+			route.Name = name
+		}
+	}
+	return notes
+}
@@ -0,0 +1,213 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apimachineryvalidation "k8s.io/apimachinery/pkg/util/validation"
+)
+
+func Test_nameFromHost(t *testing.T) {
+	longHost := strings.Repeat("a", 40) + "." + strings.Repeat("b", 40) + ".example.com"
+
+	testCases := []struct {
+		name       string
+		host       string
+		expectName string
+	}{{
+		name:       "empty host",
+		host:       "",
+		expectName: "all-hosts",
+	}, {
+		name:       "simple host",
+		host:       "example.com",
+		expectName: "example-com",
+	}, {
+		name:       "uppercase host",
+		host:       "Foo.Example.COM",
+		expectName: "foo-example-com",
+	}, {
+		name:       "wildcard host",
+		host:       "*.example.com",
+		expectName: "wildcard-example-com",
+	}, {
+		name:       "trailing dot",
+		host:       "example.com.",
+		expectName: "example-com",
+	}, {
+		name:       "long host is truncated with a hash",
+		host:       longHost,
+		expectName: strings.Repeat("a", 40) + "-" + strings.Repeat("b", 13) + "-" + shortHash(longHost),
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := nameFromHost(tc.host)
+			if got != tc.expectName {
+				t.Errorf("Expected name %q, got %q", tc.expectName, got)
+			}
+			if errs := apimachineryvalidation.IsDNS1123Label(got); len(errs) > 0 {
+				t.Errorf("Expected %q to be a valid DNS label: %v", got, errs)
+			}
+		})
+	}
+}
+
+func Test_nameFromHost_uniqueAfterTruncation(t *testing.T) {
+	prefix := strings.Repeat("sub.", 20)
+	a, b := nameFromHost(prefix+"one.example.com"), nameFromHost(prefix+"two.example.com")
+	if a == b {
+		t.Errorf("Expected distinct names for distinct long hosts, got %q for both", a)
+	}
+}
+
+func Test_uniqueHTTPRouteNames(t *testing.T) {
+	routes := []ir.HTTPRoute{
+		{Namespace: "default", Name: "example-com", GatewayName: "public", Hostname: "example.com"},
+		{Namespace: "default", Name: "example-com", GatewayName: "internal", Hostname: "example.com"},
+		{Namespace: "other", Name: "example-com", GatewayName: "public", Hostname: "example.com"},
+	}
+	renamed := "example-com-" + shortHash("default/public/example.com/example-com")
+	expected := map[string]string{"default/internal": "example-com", "default/public": renamed, "other/public": "example-com"}
+
+	// The names don't depend on the order of the routes.
+	for _, order := range [][]int{{0, 1, 2}, {2, 1, 0}} {
+		result := ir.IR{}
+		for _, i := range order {
+			result.HTTPRoutes = append(result.HTTPRoutes, routes[i])
+		}
+		notes := uniqueHTTPRouteNames(&result)
+
+		got := map[string]string{}
+		for _, route := range result.HTTPRoutes {
+			got[route.Namespace+"/"+route.GatewayName] = route.Name
+		}
+		if diff := cmp.Diff(expected, got); diff != "" {
+			t.Errorf("Unexpected names for order %v, diff (-want +got): %s", order, diff)
+		}
+		if len(notes) != 1 {
+			t.Errorf("Expected 1 notification, got %d: %v", len(notes), notes)
+		}
+	}
+}
+
+func Test_RouteNamingIngress(t *testing.T) {
+	className := "nginx"
+	ingress := func(name string, created int64) networkingv1.Ingress {
+		return networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, CreationTimestamp: metav1.Unix(created, 0)},
+			Spec: networkingv1.IngressSpec{
+				IngressClassName: &className,
+				Rules: []networkingv1.IngressRule{{
+					Host: "example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     "/" + name,
+							PathType: ptrTo(networkingv1.PathTypePrefix),
+							Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+								Name: name,
+								Port: networkingv1.ServiceBackendPort{Number: 80},
+							}},
+						}},
+					}},
+				}},
+			},
+		}
+	}
+	resources, _, err := Convert(context.Background(), ConvertOptions{
+		Ingresses:   []networkingv1.Ingress{ingress("shop", 2), ingress("web", 1)},
+		RouteNaming: RouteNamingIngress,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var names []string
+	for _, route := range resources.HTTPRoutes {
+		names = append(names, route.Name)
+	}
+	if diff := cmp.Diff([]string{"web-example-com"}, names); diff != "" {
+		t.Errorf("Unexpected HTTPRoute names, diff (-want +got): %s", diff)
+	}
+}
+
+func Test_NameGenerator(t *testing.T) {
+	className := "nginx"
+	ingress := func(name, host string) networkingv1.Ingress {
+		return networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: networkingv1.IngressSpec{
+				IngressClassName: &className,
+				Rules: []networkingv1.IngressRule{{
+					Host: host,
+					IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     "/",
+							PathType: ptrTo(networkingv1.PathTypePrefix),
+							Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+								Name: name,
+								Port: networkingv1.ServiceBackendPort{Number: 80},
+							}},
+						}},
+					}},
+				}},
+			},
+		}
+	}
+	generate := func(source, kind, host string) string {
+		switch {
+		case kind == "Gateway":
+			return "gw-" + source
+		case host == "invalid.example.com":
+			return "Invalid_Name"
+		default:
+			return "route-" + strings.ReplaceAll(source, "/", "-")
+		}
+	}
+	resources, report, err := Convert(context.Background(), ConvertOptions{
+		Ingresses:     []networkingv1.Ingress{ingress("shop", "shop.example.com"), ingress("web", "invalid.example.com")},
+		NameGenerator: generate,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resources.Gateways) != 1 || resources.Gateways[0].Name != "gw-nginx" {
+		t.Fatalf("Expected Gateway gw-nginx, got %+v", resources.Gateways)
+	}
+	var names []string
+	for _, route := range resources.HTTPRoutes {
+		names = append(names, route.Name+" -> "+string(route.Spec.ParentRefs[0].Name))
+	}
+	expected := []string{"invalid-example-com -> gw-nginx", "route-default-shop -> gw-nginx"}
+	if diff := cmp.Diff(expected, names); diff != "" {
+		t.Errorf("Unexpected HTTPRoute names, diff (-want +got): %s", diff)
+	}
+	var warnings []string
+	for _, n := range report.Notifications {
+		if strings.Contains(n.String(), "Generated name") {
+			warnings = append(warnings, n.String())
+		}
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `Generated name "Invalid_Name" of HTTPRoute default/invalid-example-com is invalid`) {
+		t.Errorf("Expected a warning about the invalid generated name, got %v", warnings)
+	}
+}
@@ -0,0 +1,334 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// namespaceIndexFile is the index of the LayoutNamespaces layout.
+	namespaceIndexFile = "index.yaml"
+	// clusterNamespaceFile holds the cluster-scoped resources and the
+	// findings about no namespace. Namespace names can't start with an
+	// underscore, so it doesn't collide with the file of a namespace.
+	clusterNamespaceFile = "_cluster.yaml"
+)
+
+// NamespaceLayoutOptions configures WriteNamespaceLayout.
+type NamespaceLayoutOptions struct {
+	// Output is the format of the resources, OutputYAML or
+	// OutputServerSideApply.
+	Output OutputFormat
+	// Annotate precedes every resource with comments about the Ingresses
+	// it was converted from.
+	Annotate bool
+	// Clean writes the resources without status and the metadata populated
+	// by the API server.
+	Clean bool
+}
+
+// namespaceIndex is the index of the LayoutNamespaces layout, listing what
+// the file of every namespace holds.
+type namespaceIndex struct {
+	Cluster    *namespaceIndexEntry  `json:"cluster,omitempty"`
+	Namespaces []namespaceIndexEntry `json:"namespaces"`
+}
+
+type namespaceIndexEntry struct {
+	Namespace string `json:"namespace,omitempty"`
+	File      string `json:"file"`
+	// Ingresses are the Ingresses the resources were converted from.
+	Ingresses []string `json:"ingresses,omitempty"`
+	Resources []string `json:"resources"`
+	// Findings are the numbers of findings about the namespace, by
+	// severity.
+	Findings map[string]int `json:"findings,omitempty"`
+}
+
+// namespaceLayout writes the results of conversions to a file per namespace
+// as they are added, and the cluster file and the index once closed.
+type namespaceLayout struct {
+	dir     string
+	opts    NamespaceLayoutOptions
+	entries map[string]*namespaceIndexEntry
+	// cluster is the result of the cluster file, merged across conversions.
+	cluster       Resources
+	clusterReport Report
+}
+
+func newNamespaceLayout(dir string, opts NamespaceLayoutOptions) *namespaceLayout {
+	return &namespaceLayout{dir: dir, opts: opts, entries: map[string]*namespaceIndexEntry{}}
+}
+
+// WriteNamespaceLayout writes the resources to dir in the LayoutNamespaces
+// layout: the resources of each namespace to a <namespace>.yaml file,
+// preceded by the errors and notifications about the namespace, the
+// cluster-scoped resources and the other findings to _cluster.yaml, and an
+// index.yaml file listing the Ingresses, resources and numbers of findings
+// of every file, for the conversion of each namespace to be handed off to
+// its owners.
+func WriteNamespaceLayout(dir string, resources Resources, report Report, opts NamespaceLayoutOptions) error {
+	l := newNamespaceLayout(dir, opts)
+	if err := l.add(resources, report); err != nil {
+		return err
+	}
+	return l.close()
+}
+
+// add writes the files of the namespaces of a conversion. The namespaces of
+// different conversions, such as those of a stream, must differ.
+func (l *namespaceLayout) add(resources Resources, report Report) error {
+	byNamespace := splitResourcesByNamespace(resources)
+	reports := splitReportByNamespace(report)
+	var namespaces []string
+	for namespace := range byNamespace {
+		namespaces = append(namespaces, namespace)
+	}
+	for namespace := range reports {
+		if _, ok := byNamespace[namespace]; !ok {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	sort.Strings(namespaces)
+
+	for _, namespace := range namespaces {
+		if namespace == "" {
+			l.addCluster(byNamespace[namespace], reports[namespace])
+			continue
+		}
+		file := namespace + ".yaml"
+		if err := l.writeFile(file, byNamespace[namespace], reports[namespace]); err != nil {
+			return err
+		}
+		l.entries[namespace] = newNamespaceIndexEntry(namespace, file, byNamespace[namespace], reports[namespace])
+	}
+	return nil
+}
+
+// close writes the cluster file and the index.
+func (l *namespaceLayout) close() error {
+	index := namespaceIndex{Namespaces: []namespaceIndexEntry{}}
+	if len(resourceObjects(l.cluster)) > 0 || len(Findings(l.clusterReport)) > 0 {
+		if err := l.writeFile(clusterNamespaceFile, l.cluster, l.clusterReport); err != nil {
+			return err
+		}
+		index.Cluster = newNamespaceIndexEntry("", clusterNamespaceFile, l.cluster, l.clusterReport)
+	}
+	var namespaces []string
+	for namespace := range l.entries {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		index.Namespaces = append(index.Namespaces, *l.entries[namespace])
+	}
+	data, err := yaml.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("failed to marshal namespace index: %w", err)
+	}
+	return writeLayoutFile(filepath.Join(l.dir, namespaceIndexFile), data)
+}
+
+func (l *namespaceLayout) writeFile(file string, resources Resources, report Report) error {
+	var sources map[ObjectRef][]IngressSource
+	if l.opts.Annotate {
+		sources = resources.Sources
+	}
+	var buf bytes.Buffer
+	if l.opts.Output == OutputServerSideApply {
+		writeApplyResult(&buf, resources, report, sources)
+	} else {
+		writeResult(&buf, resources, report, sources, l.opts.Clean)
+	}
+	return writeLayoutFile(filepath.Join(l.dir, file), buf.Bytes())
+}
+
+func newNamespaceIndexEntry(namespace, file string, resources Resources, report Report) *namespaceIndexEntry {
+	entry := &namespaceIndexEntry{Namespace: namespace, File: file, Resources: []string{}}
+	ingresses := map[string]bool{}
+	for _, objectSources := range resources.Sources {
+		for _, source := range objectSources {
+			name := source.Ingress.Name
+			if source.Ingress.Namespace != namespace {
+				name = source.Ingress.String()
+			}
+			ingresses[name] = true
+		}
+	}
+	for name := range ingresses {
+		entry.Ingresses = append(entry.Ingresses, name)
+	}
+	sort.Strings(entry.Ingresses)
+	for _, obj := range resourceObjects(resources) {
+		entry.Resources = append(entry.Resources, obj.GetObjectKind().GroupVersionKind().Kind+"/"+obj.GetName())
+	}
+	for _, finding := range Findings(report) {
+		if entry.Findings == nil {
+			entry.Findings = map[string]int{}
+		}
+		entry.Findings[finding.Severity]++
+	}
+	return entry
+}
+
+// splitReportByNamespace splits a report by the namespaces of the objects
+// its errors and notifications refer to, the ones referring to no
+// namespaced object being keyed by the empty namespace.
+func splitReportByNamespace(report Report) map[string]Report {
+	reports := map[string]Report{}
+	namespaces := func(message string) []string {
+		var namespaces []string
+		for _, obj := range findingObjects(message) {
+			if !slices.Contains(namespaces, obj.Namespace) {
+				namespaces = append(namespaces, obj.Namespace)
+			}
+		}
+		if len(namespaces) == 0 {
+			return []string{""}
+		}
+		return namespaces
+	}
+	for _, err := range report.Errors {
+		for _, namespace := range namespaces(err.Error()) {
+			r := reports[namespace]
+			r.Errors = append(r.Errors, err)
+			reports[namespace] = r
+		}
+	}
+	for _, n := range report.Notifications {
+		for _, namespace := range namespaces(n.Message) {
+			r := reports[namespace]
+			r.Notifications = append(r.Notifications, n)
+			reports[namespace] = r
+		}
+	}
+	for _, u := range report.UnsupportedAnnotations {
+		r := reports[u.Ingress.Namespace]
+		r.UnsupportedAnnotations = append(r.UnsupportedAnnotations, u)
+		reports[u.Ingress.Namespace] = r
+	}
+	for _, d := range report.DisabledAnnotations {
+		r := reports[d.Ingress.Namespace]
+		r.DisabledAnnotations = append(r.DisabledAnnotations, d)
+		reports[d.Ingress.Namespace] = r
+	}
+	for _, ingress := range report.SkippedIngresses {
+		r := reports[ingress.Namespace]
+		r.SkippedIngresses = append(r.SkippedIngresses, ingress)
+		reports[ingress.Namespace] = r
+	}
+	for _, ingress := range report.ExcludedIngresses {
+		r := reports[ingress.Namespace]
+		r.ExcludedIngresses = append(r.ExcludedIngresses, ingress)
+		reports[ingress.Namespace] = r
+	}
+	for ref, confidence := range report.Confidence {
+		r := reports[ref.Namespace]
+		if r.Confidence == nil {
+			r.Confidence = map[ObjectRef]ResourceConfidence{}
+		}
+		r.Confidence[ref] = confidence
+		reports[ref.Namespace] = r
+	}
+	for ref, features := range report.SupportLevels {
+		r := reports[ref.Namespace]
+		if r.SupportLevels == nil {
+			r.SupportLevels = map[ObjectRef][]FeatureSupport{}
+		}
+		r.SupportLevels[ref] = features
+		reports[ref.Namespace] = r
+	}
+	for _, split := range report.WeightSplits {
+		r := reports[split.HTTPRoute.Namespace]
+		r.WeightSplits = append(r.WeightSplits, split)
+		reports[split.HTTPRoute.Namespace] = r
+	}
+	return reports
+}
+
+// addCluster adds the cluster-scoped resources and the findings about no
+// namespace of a conversion to the cluster file. Like the conversions of a
+// cache, the conversions of a stream generate the same GatewayClasses and
+// notifications about shared objects, which are only written once.
+func (l *namespaceLayout) addCluster(resources Resources, report Report) {
+	for _, gatewayClass := range resources.GatewayClasses {
+		if !slices.ContainsFunc(l.cluster.GatewayClasses, func(gc gatewayv1.GatewayClass) bool { return gc.Name == gatewayClass.Name }) {
+			l.cluster.GatewayClasses = append(l.cluster.GatewayClasses, gatewayClass)
+		}
+	}
+	l.cluster.CustomResources = append(l.cluster.CustomResources, resources.CustomResources...)
+	for _, n := range report.Notifications {
+		if !slices.ContainsFunc(l.clusterReport.Notifications, func(written Notification) bool { return written.String() == n.String() }) {
+			l.clusterReport.Notifications = append(l.clusterReport.Notifications, n)
+		}
+	}
+	l.clusterReport.Errors = append(l.clusterReport.Errors, report.Errors...)
+}
+
+// splitResourcesByNamespace splits resources by namespace, the
+// cluster-scoped ones being keyed by the empty namespace.
+func splitResourcesByNamespace(resources Resources) map[string]Resources {
+	byNamespace := map[string]Resources{}
+	update := func(namespace string, fn func(*Resources)) {
+		r := byNamespace[namespace]
+		fn(&r)
+		byNamespace[namespace] = r
+	}
+	for _, gatewayClass := range resources.GatewayClasses {
+		update("", func(r *Resources) { r.GatewayClasses = append(r.GatewayClasses, gatewayClass) })
+	}
+	for _, gateway := range resources.Gateways {
+		update(gateway.Namespace, func(r *Resources) { r.Gateways = append(r.Gateways, gateway) })
+	}
+	for _, route := range resources.HTTPRoutes {
+		update(route.Namespace, func(r *Resources) { r.HTTPRoutes = append(r.HTTPRoutes, route) })
+	}
+	for _, route := range resources.TLSRoutes {
+		update(route.Namespace, func(r *Resources) { r.TLSRoutes = append(r.TLSRoutes, route) })
+	}
+	for _, route := range resources.TCPRoutes {
+		update(route.Namespace, func(r *Resources) { r.TCPRoutes = append(r.TCPRoutes, route) })
+	}
+	for _, grant := range resources.ReferenceGrants {
+		update(grant.Namespace, func(r *Resources) { r.ReferenceGrants = append(r.ReferenceGrants, grant) })
+	}
+	for _, policy := range resources.BackendLBPolicies {
+		update(policy.Namespace, func(r *Resources) { r.BackendLBPolicies = append(r.BackendLBPolicies, policy) })
+	}
+	for _, policy := range resources.BackendTLSPolicies {
+		update(policy.Namespace, func(r *Resources) { r.BackendTLSPolicies = append(r.BackendTLSPolicies, policy) })
+	}
+	for _, obj := range resources.CustomResources {
+		update(obj.GetNamespace(), func(r *Resources) { r.CustomResources = append(r.CustomResources, obj) })
+	}
+	for ref, sources := range resources.Sources {
+		update(ref.Namespace, func(r *Resources) {
+			if r.Sources == nil {
+				r.Sources = map[ObjectRef][]IngressSource{}
+			}
+			r.Sources[ref] = sources
+		})
+	}
+	return byNamespace
+}
@@ -0,0 +1,116 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func Test_WriteNamespaceLayout(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	var ingresses []networkingv1.Ingress
+	for _, namespace := range []string{"a", "b"} {
+		ingress := ingressWithPath("web", "/", &iPrefix, serviceBackend("web", 80), nil)
+		ingress.Namespace = namespace
+		ingresses = append(ingresses, ingress)
+	}
+	resources, _, err := Convert(context.Background(), ConvertOptions{Ingresses: ingresses, GatewayClassController: "example.com/gateway-controller"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	report := Report{
+		Notifications: []Notification{
+			notifications.NewWarning("Ingress a/web uses a snippet"),
+			notifications.NewInfo("The input has no IngressClasses"),
+		},
+		Errors:           []error{errors.New("HTTPRoute b/example-com is invalid: too many rules")},
+		SkippedIngresses: []types.NamespacedName{{Namespace: "a", Name: "empty"}},
+	}
+
+	dir := t.TempDir()
+	if err := WriteNamespaceLayout(dir, resources, report, NamespaceLayoutOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to list output: %v", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		files = append(files, entry.Name())
+	}
+	if diff := cmp.Diff([]string{"_cluster.yaml", "a.yaml", "b.yaml", "index.yaml"}, files); diff != "" {
+		t.Errorf("Unexpected files, diff (-want +got): %s", diff)
+	}
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	expectedIndex := `cluster:
+  file: _cluster.yaml
+  findings:
+    INFO: 1
+  resources:
+  - GatewayClass/nginx
+namespaces:
+- file: a.yaml
+  findings:
+    WARNING: 2
+  ingresses:
+  - web
+  namespace: a
+  resources:
+  - Gateway/nginx
+  - HTTPRoute/example-com
+- file: b.yaml
+  findings:
+    ERROR: 1
+  ingresses:
+  - web
+  namespace: b
+  resources:
+  - Gateway/nginx
+  - HTTPRoute/example-com
+`
+	if diff := cmp.Diff(expectedIndex, string(index)); diff != "" {
+		t.Errorf("Unexpected index, diff (-want +got): %s", diff)
+	}
+
+	a, err := os.ReadFile(filepath.Join(dir, "a.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to read namespace file: %v", err)
+	}
+	for _, expected := range []string{"# WARNING: Ingress a/web uses a snippet\n", "namespace: a\n"} {
+		if !strings.Contains(string(a), expected) {
+			t.Errorf("Expected the file of namespace a to contain %q, got:\n%s", expected, a)
+		}
+	}
+	if strings.Contains(string(a), "namespace: b") || strings.Contains(string(a), "too many rules") {
+		t.Errorf("Expected the file of namespace a to only hold namespace a, got:\n%s", a)
+	}
+}
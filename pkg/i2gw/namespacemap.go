@@ -0,0 +1,180 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"k8s.io/apimachinery/pkg/types"
+	apimachineryvalidation "k8s.io/apimachinery/pkg/util/validation"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// validateNamespaceMap checks the source and target namespaces of a
+// namespace map.
+func validateNamespaceMap(namespaces map[string]string) error {
+	for from, to := range namespaces {
+		for _, ns := range []string{from, to} {
+			if msgs := apimachineryvalidation.IsDNS1123Label(ns); len(msgs) > 0 {
+				return fmt.Errorf("invalid namespace %q in the namespace map: %s", ns, strings.Join(msgs, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// remapNamespaces moves the generated resources of the namespaces of the
+// map to their target namespaces. The backendRefs and certificateRefs keep
+// pointing at the Services and Secrets of the source namespaces, which
+// ReferenceGrants then allow, and the Gateways that end up with the same
+// namespace and name are merged.
+func remapNamespaces(result *ir.IR, namespaces map[string]string) []Notification {
+	if len(namespaces) == 0 {
+		return nil
+	}
+	// moved are the target namespaces of the namespaces holding resources.
+	moved := map[string]string{}
+	mapNamespace := func(ns string) string {
+		if to, ok := namespaces[ns]; ok && to != ns {
+			moved[ns] = to
+			return to
+		}
+		return ns
+	}
+	// generated are the backends living with the routes, which move along
+	// with them.
+	generated := map[types.NamespacedName]bool{}
+	for i := range result.ExternalBackends {
+		backend := &result.ExternalBackends[i]
+		generated[types.NamespacedName{Namespace: backend.Namespace, Name: backend.Name}] = true
+		backend.Namespace = mapNamespace(backend.Namespace)
+	}
+	pinBackends := func(namespace string, backends []ir.Backend) {
+		for i := range backends {
+			ref := &backends[i].BackendRef
+			if ref.Namespace != nil || generated[types.NamespacedName{Namespace: namespace, Name: string(ref.Name)}] || mapNamespace(namespace) == namespace {
+				continue
+			}
+			ns := gatewayv1.Namespace(namespace)
+			ref.Namespace = &ns
+		}
+	}
+
+	var notes []Notification
+	var gateways []ir.Gateway
+	gatewayIndexes := map[types.NamespacedName]int{}
+	for _, gw := range result.Gateways {
+		namespace := gw.Namespace
+		gw.Namespace = mapNamespace(namespace)
+		for i := range gw.Listeners {
+			l := &gw.Listeners[i]
+			if gw.Namespace != namespace {
+				ns := gatewayv1.Namespace(namespace)
+				l.CertificateRefs = slices.Clone(l.CertificateRefs)
+				for j := range l.CertificateRefs {
+					if l.CertificateRefs[j].Namespace == nil {
+						l.CertificateRefs[j].Namespace = &ns
+					}
+				}
+				if l.FrontendValidation != nil {
+					l.FrontendValidation = l.FrontendValidation.DeepCopy()
+					for j := range l.FrontendValidation.CACertificateRefs {
+						if l.FrontendValidation.CACertificateRefs[j].Namespace == nil {
+							l.FrontendValidation.CACertificateRefs[j].Namespace = &ns
+						}
+					}
+				}
+			}
+			var allowed []string
+			for _, ns := range l.AllowedNamespaces {
+				if ns = mapNamespace(ns); ns != gw.Namespace && !slices.Contains(allowed, ns) {
+					allowed = append(allowed, ns)
+				}
+			}
+			l.AllowedNamespaces = allowed
+		}
+
+		key := types.NamespacedName{Namespace: gw.Namespace, Name: gw.Name}
+		i, ok := gatewayIndexes[key]
+		if !ok {
+			gatewayIndexes[key] = len(gateways)
+			gateways = append(gateways, gw)
+			continue
+		}
+		merged := &gateways[i]
+		if gw.HTTPPort != merged.HTTPPort || gw.HTTPSPort != merged.HTTPSPort {
+			notes = append(notes, notifications.NewWarning("Gateway %s/%s moved to namespace %s listens on ports %d and %d, the Gateway it's merged into listens on ports %d and %d instead", namespace, gw.Name, gw.Namespace, gw.HTTPPort, gw.HTTPSPort, merged.HTTPPort, merged.HTTPSPort))
+		}
+		for _, l := range gw.Listeners {
+			j := listenerIndex(merged.Listeners, l.Hostname)
+			if j < 0 {
+				merged.Listeners = append(merged.Listeners, l)
+				continue
+			}
+			if !sameListenerTLS(merged.Listeners[j], l) {
+				notes = append(notes, notifications.NewWarning("Host %q is served by Gateway %s/%s moved to namespace %s with different TLS settings than the Gateway it's merged into, the ones of the latter are kept", l.Hostname, namespace, gw.Name, gw.Namespace))
+			}
+			for _, ns := range l.AllowedNamespaces {
+				if !slices.Contains(merged.Listeners[j].AllowedNamespaces, ns) {
+					merged.Listeners[j].AllowedNamespaces = append(merged.Listeners[j].AllowedNamespaces, ns)
+				}
+			}
+			merged.Listeners[j].ExtraPorts = mergeListenerPorts(merged.Listeners[j].ExtraPorts, l.ExtraPorts)
+			merged.Listeners[j].Stream = merged.Listeners[j].Stream && l.Stream
+		}
+		for _, source := range gw.Ingresses {
+			if !containsNamespacedName(merged.Ingresses, source) {
+				merged.Ingresses = append(merged.Ingresses, source)
+			}
+		}
+		notes = append(notes, notifications.NewInfo("Gateway %s/%s is merged into Gateway %s/%s", namespace, gw.Name, merged.Namespace, merged.Name))
+	}
+	result.Gateways = gateways
+
+	for i := range result.HTTPRoutes {
+		route := &result.HTTPRoutes[i]
+		for j := range route.Rules {
+			pinBackends(route.Namespace, route.Rules[j].Backends)
+		}
+		route.Namespace = mapNamespace(route.Namespace)
+		if route.GatewayNamespace != "" {
+			route.GatewayNamespace = mapNamespace(route.GatewayNamespace)
+		}
+	}
+	for i := range result.StreamRoutes {
+		route := &result.StreamRoutes[i]
+		pinBackends(route.Namespace, route.Backends)
+		route.Namespace = mapNamespace(route.Namespace)
+		if route.GatewayNamespace != "" {
+			route.GatewayNamespace = mapNamespace(route.GatewayNamespace)
+		}
+	}
+
+	var moves []string
+	for from, to := range moved {
+		moves = append(moves, fmt.Sprintf("%s to %s", from, to))
+	}
+	if len(moves) > 0 {
+		sort.Strings(moves)
+		notes = append(notes, notifications.NewInfo("The resources of namespaces are moved, %s, the Services and Secrets they reference are left in their namespaces", strings.Join(moves, ", ")))
+	}
+	return notes
+}
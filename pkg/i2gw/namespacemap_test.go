@@ -0,0 +1,153 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+func Test_remapNamespaces(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	ingress := func(namespace, host string) networkingv1.Ingress {
+		ingress := ingressWithPath(namespace, "/", &iPrefix, serviceBackend("web", 80), nil)
+		ingress.Namespace = namespace
+		ingress.Spec.Rules[0].Host = host
+		return ingress
+	}
+	shop := ingress("shop", "shop.example.com")
+	shop.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"shop.example.com"}, SecretName: "shop-tls"}}
+	cart := ingress("cart", "cart.example.com")
+
+	testCases := []struct {
+		name          string
+		namespaceMap  map[string]string
+		wantGateways  []string
+		wantRoutes    []string
+		wantBackends  []string
+		wantCertRefs  []string
+		wantGrantsFor []string
+	}{{
+		name:         "no map",
+		wantGateways: []string{"cart/nginx", "shop/nginx"},
+		wantRoutes:   []string{"cart/cart-example-com", "shop/shop-example-com-https-redirect", "shop/shop-example-com"},
+		wantBackends: []string{"web", "web"},
+		wantCertRefs: []string{"shop-tls"},
+	}, {
+		name:          "consolidated namespaces",
+		namespaceMap:  map[string]string{"shop": "apps", "cart": "apps"},
+		wantGateways:  []string{"apps/nginx"},
+		wantRoutes:    []string{"apps/cart-example-com", "apps/shop-example-com-https-redirect", "apps/shop-example-com"},
+		wantBackends:  []string{"cart/web", "shop/web"},
+		wantCertRefs:  []string{"shop/shop-tls"},
+		wantGrantsFor: []string{"cart/from-apps: HTTPRoute", "shop/from-apps: HTTPRoute", "shop/from-apps-gateways: Gateway"},
+	}, {
+		name:          "namespace moved into another one",
+		namespaceMap:  map[string]string{"cart": "shop"},
+		wantGateways:  []string{"shop/nginx"},
+		wantRoutes:    []string{"shop/cart-example-com", "shop/shop-example-com-https-redirect", "shop/shop-example-com"},
+		wantBackends:  []string{"cart/web", "web"},
+		wantCertRefs:  []string{"shop-tls"},
+		wantGrantsFor: []string{"cart/from-shop: HTTPRoute"},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			input := inputResources{ingresses: []networkingv1.Ingress{shop, cart}}
+			resources, report := convertInput(input, ConvertOptions{NamespaceMap: tc.namespaceMap})
+			if len(report.Errors) > 0 {
+				t.Fatalf("unexpected errors: %v", report.Errors)
+			}
+			var gotGateways, gotRoutes, gotBackends, gotCertRefs, gotGrantsFor []string
+			for _, gw := range resources.Gateways {
+				gotGateways = append(gotGateways, gw.Namespace+"/"+gw.Name)
+				for _, l := range gw.Spec.Listeners {
+					if l.TLS == nil {
+						continue
+					}
+					for _, ref := range l.TLS.CertificateRefs {
+						if ref.Namespace != nil {
+							gotCertRefs = append(gotCertRefs, fmt.Sprintf("%s/%s", *ref.Namespace, ref.Name))
+						} else {
+							gotCertRefs = append(gotCertRefs, string(ref.Name))
+						}
+					}
+				}
+			}
+			for _, route := range resources.HTTPRoutes {
+				gotRoutes = append(gotRoutes, route.Namespace+"/"+route.Name)
+				for _, rule := range route.Spec.Rules {
+					for _, br := range rule.BackendRefs {
+						if br.Namespace != nil {
+							gotBackends = append(gotBackends, fmt.Sprintf("%s/%s", *br.Namespace, br.Name))
+						} else {
+							gotBackends = append(gotBackends, string(br.Name))
+						}
+					}
+				}
+			}
+			for _, grant := range resources.ReferenceGrants {
+				gotGrantsFor = append(gotGrantsFor, fmt.Sprintf("%s/%s: %s", grant.Namespace, grant.Name, grant.Spec.From[0].Kind))
+			}
+			if diff := cmp.Diff(tc.wantGateways, gotGateways); diff != "" {
+				t.Errorf("unexpected Gateways (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantRoutes, gotRoutes); diff != "" {
+				t.Errorf("unexpected HTTPRoutes (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantBackends, gotBackends); diff != "" {
+				t.Errorf("unexpected backendRefs (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantCertRefs, gotCertRefs); diff != "" {
+				t.Errorf("unexpected certificateRefs (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantGrantsFor, gotGrantsFor); diff != "" {
+				t.Errorf("unexpected ReferenceGrants (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_validateNamespaceMap(t *testing.T) {
+	testCases := []struct {
+		name         string
+		namespaceMap map[string]string
+		expectError  string
+	}{{
+		name:         "valid",
+		namespaceMap: map[string]string{"shop": "apps", "cart": "apps"},
+	}, {
+		name:         "invalid target namespace",
+		namespaceMap: map[string]string{"shop": "Apps"},
+		expectError:  `invalid namespace "Apps" in the namespace map: a lowercase RFC 1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')`,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateNamespaceMap(tc.namespaceMap)
+			var gotError string
+			if err != nil {
+				gotError = err.Error()
+			}
+			if diff := cmp.Diff(tc.expectError, gotError); diff != "" {
+				t.Errorf("unexpected error (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import "github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+
+// Notification is a non-fatal message about the conversion that the user
+// should be aware of, such as parts of an Ingress that were not converted.
+type Notification = notifications.Notification
+
+// NotificationType is the severity of a Notification.
+type NotificationType = notifications.Type
+
+const (
+	InfoNotification     = notifications.InfoNotification
+	WarningNotification  = notifications.WarningNotification
+	BlockingNotification = notifications.BlockingNotification
+)
@@ -0,0 +1,60 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package notifications contains the messages reported to users about a
+// conversion.
+package notifications
+
+import "fmt"
+
+// Type is the severity of a Notification.
+type Type string
+
+const (
+	InfoNotification    Type = "INFO"
+	WarningNotification Type = "WARNING"
+	// BlockingNotification reports a part of an Ingress that prevents its
+	// workloads from being migrated to Gateway API.
+	BlockingNotification Type = "BLOCKING"
+)
+
+// Notification is a non-fatal message about the conversion that the user
+// should be aware of, such as parts of an Ingress that were not converted.
+type Notification struct {
+	Type    Type
+	Message string
+}
+
+func (n Notification) String() string {
+	return fmt.Sprintf("%s: %s", n.Type, n.Message)
+}
+
+// NewInfo returns an informational Notification.
+func NewInfo(format string, args ...interface{}) Notification {
+	return Notification{Type: InfoNotification, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewWarning returns a Notification about something that may not behave as
+// it did before the conversion.
+func NewWarning(format string, args ...interface{}) Notification {
+	return Notification{Type: WarningNotification, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewBlocking returns a Notification about something that Gateway API can't
+// serve at all, so that the Ingress can't be replaced yet.
+func NewBlocking(format string, args ...interface{}) Notification {
+	return Notification{Type: BlockingNotification, Message: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,193 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/printers"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// OutputFormat selects how the command line writes the resources.
+type OutputFormat string
+
+const (
+	// OutputYAML writes the resources as YAML documents.
+	OutputYAML OutputFormat = "yaml"
+	// OutputServerSideApply writes the resources as YAML documents shaped
+	// for Server-Side Apply, without status and the metadata populated by
+	// the API server, so that every field in the output is owned by the
+	// field manager applying it.
+	OutputServerSideApply OutputFormat = "ssa"
+	// OutputList writes the resources as the items of a single v1 List,
+	// for tools that don't read multi-document YAML streams.
+	OutputList OutputFormat = "list"
+	// OutputMermaid writes the traffic layout of the conversion, instead of
+	// the resources, as a Mermaid flowchart.
+	OutputMermaid OutputFormat = "mermaid"
+	// OutputDOT writes the traffic layout of the conversion, instead of the
+	// resources, as a Graphviz graph.
+	OutputDOT OutputFormat = "dot"
+)
+
+// FieldManager is the field manager the output is applied with. Applying
+// every conversion with the same field manager lets later conversions
+// update and remove the fields set by earlier ones without conflicts.
+const FieldManager = "ingress2gateway"
+
+// OutputFormats returns the names of the supported output formats.
+func OutputFormats() []string {
+	return []string{string(OutputYAML), string(OutputServerSideApply), string(OutputList), string(OutputMermaid), string(OutputDOT)}
+}
+
+func validateOutputFormat(format OutputFormat) error {
+	switch format {
+	case "", OutputYAML, OutputServerSideApply, OutputList, OutputMermaid, OutputDOT:
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q, supported ones are: %s", format, strings.Join(OutputFormats(), ", "))
+	}
+}
+
+// WriteApplyResult writes the result like WriteResult, with the resources
+// shaped for Server-Side Apply. The output is meant to be applied with
+// kubectl apply --server-side --field-manager=ingress2gateway, or with the
+// apply command.
+func WriteApplyResult(w io.Writer, resources Resources, report Report) {
+	writeApplyResult(w, resources, report, nil)
+}
+
+func writeApplyResult(w io.Writer, resources Resources, report Report, sources map[ObjectRef][]IngressSource) {
+	writeReport(w, report)
+	fmt.Fprintf(w, "# Apply with: kubectl apply --server-side --field-manager=%s -f <file>\n", FieldManager)
+	writeObjects(w, resources, sources, true)
+}
+
+// writeObjects writes the resources as YAML documents, preceded by comments
+// about their sources. Clean resources are written without status and the
+// metadata populated by the API server.
+func writeObjects(w io.Writer, resources Resources, sources map[ObjectRef][]IngressSource, clean bool) {
+	y := printers.YAMLPrinter{}
+	for _, obj := range resourceObjects(resources) {
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		var printed runtime.Object = obj
+		if clean {
+			u, err := cleanObject(obj)
+			if err != nil {
+				fmt.Fprintf(w, "# Error printing YAML for %s %s: %v\n", obj.GetName(), kind, err)
+				continue
+			}
+			printed = u
+		}
+		comments := sourceComments(sources[ObjectRef{Kind: kind, Namespace: obj.GetNamespace(), Name: obj.GetName()}])
+		if err := printAnnotated(w, &y, printed, comments); err != nil {
+			fmt.Fprintf(w, "# Error printing YAML for %s %s: %v\n", obj.GetName(), kind, err)
+		}
+	}
+}
+
+// WriteListResult writes the report as YAML comments followed by a single
+// v1 List holding the resources.
+func WriteListResult(w io.Writer, resources Resources, report Report) error {
+	return writeListResult(w, resources, report, false)
+}
+
+func writeListResult(w io.Writer, resources Resources, report Report, clean bool) error {
+	writeReport(w, report)
+	list := &unstructured.UnstructuredList{Object: map[string]interface{}{"apiVersion": "v1", "kind": "List"}}
+	for _, obj := range resourceObjects(resources) {
+		u, err := toUnstructured(obj, clean)
+		if err != nil {
+			return fmt.Errorf("failed to convert %s %s: %w", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName(), err)
+		}
+		list.Items = append(list.Items, *u)
+	}
+	y := printers.YAMLPrinter{}
+	return y.PrintObj(list, w)
+}
+
+// Objects returns the resources in the order they are written.
+func (r Resources) Objects() []client.Object {
+	return resourceObjects(r)
+}
+
+// resourceObjects returns the resources in the order they are written.
+func resourceObjects(resources Resources) []client.Object {
+	var objects []client.Object
+	for i := range resources.GatewayClasses {
+		objects = append(objects, &resources.GatewayClasses[i])
+	}
+	for i := range resources.Gateways {
+		objects = append(objects, &resources.Gateways[i])
+	}
+	for i := range resources.HTTPRoutes {
+		objects = append(objects, &resources.HTTPRoutes[i])
+	}
+	for i := range resources.TLSRoutes {
+		objects = append(objects, &resources.TLSRoutes[i])
+	}
+	for i := range resources.TCPRoutes {
+		objects = append(objects, &resources.TCPRoutes[i])
+	}
+	for i := range resources.ReferenceGrants {
+		objects = append(objects, &resources.ReferenceGrants[i])
+	}
+	for i := range resources.BackendLBPolicies {
+		objects = append(objects, &resources.BackendLBPolicies[i])
+	}
+	for i := range resources.BackendTLSPolicies {
+		objects = append(objects, &resources.BackendTLSPolicies[i])
+	}
+	for i := range resources.CustomResources {
+		objects = append(objects, &resources.CustomResources[i])
+	}
+	return objects
+}
+
+// toUnstructured returns obj as an unstructured object, cleaned when clean
+// is set.
+func toUnstructured(obj runtime.Object, clean bool) (*unstructured.Unstructured, error) {
+	if clean {
+		return cleanObject(obj)
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: content}, nil
+}
+
+// cleanObject returns obj as an unstructured object without status and the
+// metadata populated by the API server, such as the null creationTimestamp
+// of generated objects. A field manager must not own these fields, and they
+// only add noise to the diffs of the manifests.
+func cleanObject(obj runtime.Object) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: content}
+	unstructured.RemoveNestedField(u.Object, "status")
+	for _, field := range []string{"creationTimestamp", "resourceVersion", "uid", "generation", "managedFields", "selfLink"} {
+		unstructured.RemoveNestedField(u.Object, "metadata", field)
+	}
+	return u, nil
+}
@@ -0,0 +1,117 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_WriteListResult(t *testing.T) {
+	gateway := gatewayv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nginx"},
+		Spec:       gatewayv1.GatewaySpec{GatewayClassName: "nginx"},
+	}
+	gateway.SetGroupVersionKind(gatewayGVK)
+	route := gatewayv1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example-com"}}
+	route.SetGroupVersionKind(httpRouteGVK)
+	resources := Resources{
+		Gateways:   []gatewayv1.Gateway{gateway},
+		HTTPRoutes: []gatewayv1.HTTPRoute{route},
+	}
+	report := Report{Notifications: []Notification{notifications.NewWarning("check the routes")}}
+
+	var buf bytes.Buffer
+	if err := WriteListResult(&buf, resources, report); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `# WARNING: check the routes
+apiVersion: v1
+items:
+- apiVersion: gateway.networking.k8s.io/v1
+  kind: Gateway
+  metadata:
+    creationTimestamp: null
+    name: nginx
+    namespace: default
+  spec:
+    gatewayClassName: nginx
+    listeners: null
+  status: {}
+- apiVersion: gateway.networking.k8s.io/v1
+  kind: HTTPRoute
+  metadata:
+    creationTimestamp: null
+    name: example-com
+    namespace: default
+  spec: {}
+  status:
+    parents: null
+kind: List
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("Unexpected output, diff (-want +got): %s", diff)
+	}
+}
+
+func Test_writeObjects(t *testing.T) {
+	route := gatewayv1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example-com"}}
+	route.SetGroupVersionKind(httpRouteGVK)
+	resources := Resources{HTTPRoutes: []gatewayv1.HTTPRoute{route}}
+
+	testCases := []struct {
+		name     string
+		clean    bool
+		expected string
+	}{{
+		name: "as generated",
+		expected: `apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  creationTimestamp: null
+  name: example-com
+  namespace: default
+spec: {}
+status:
+  parents: null
+`,
+	}, {
+		name:  "clean",
+		clean: true,
+		expected: `apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: example-com
+  namespace: default
+spec: {}
+`,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeObjects(&buf, resources, nil, tc.clean)
+			if diff := cmp.Diff(tc.expected, buf.String()); diff != "" {
+				t.Errorf("Unexpected output, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
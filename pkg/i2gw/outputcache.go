@@ -0,0 +1,144 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// outputCacheVersion is the version of the format of the keys and entries
+// of the output cache, changed to invalidate the entries of older ones.
+const outputCacheVersion = "1"
+
+// cachedOutput is what a run wrote to stdout and stderr, replayed when the
+// same input is converted with the same options by the same binary.
+type cachedOutput struct {
+	Stdout []byte `json:"stdout"`
+	Stderr []byte `json:"stderr"`
+}
+
+// validateOutputCache checks that the output of a run only depends on its
+// input file and options, for it to be cached.
+func validateOutputCache(runOpts RunOptions) error {
+	if runOpts.CacheDir == "" {
+		return nil
+	}
+	switch {
+	case runOpts.InputFile == "" || runOpts.HelmChart.Chart != "" || runOpts.Stream:
+		return fmt.Errorf("the output cache requires an input file, without a Helm chart or streaming")
+	case isDirectoryLayout(runOpts.OutputLayout):
+		return fmt.Errorf("the output cache doesn't support the %s output layout", runOpts.OutputLayout)
+	case runOpts.Summary || runOpts.CapacityReport || runOpts.WeightReport || runOpts.Findings != "" || runOpts.HostMapping != "" || runOpts.Stats || runOpts.CleanupFile != "":
+		return fmt.Errorf("the output cache can't be combined with the summary, reports, findings, host mapping, statistics or cleanup file")
+	case runOpts.TargetContext != "" || runOpts.MarkMigrated || runOpts.CheckGatewayClasses || runOpts.DiscoverCapabilities:
+		return fmt.Errorf("the output cache can't be combined with the options reading or changing a cluster")
+	case len(runOpts.Providers) > 0:
+		return fmt.Errorf("the output cache can't be combined with provider plugins, whose output it can't tell apart")
+	}
+	return nil
+}
+
+// outputCacheKey returns the digest of the input file, the template and the
+// options of a run, and of the running binary, for the entries of older
+// versions not to be replayed.
+func outputCacheKey(runOpts RunOptions) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n", outputCacheVersion)
+	executable, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the executable for the output cache: %w", err)
+	}
+	files := []string{executable, runOpts.InputFile}
+	if runOpts.Template != "" {
+		files = append(files, runOpts.Template)
+	}
+	for _, path := range files {
+		if err := hashFile(h, path); err != nil {
+			return "", err
+		}
+	}
+	// The paths of the files don't change the output, only their content
+	// does, and neither do the timeout and the clock, which only dates the
+	// statistics and can't be encoded.
+	runOpts.InputFile, runOpts.Template, runOpts.CacheDir, runOpts.Timeout = "", "", "", 0
+	runOpts.Clock = nil
+	// Maps are marshaled with sorted keys, so the key is stable.
+	opts, err := json.Marshal(runOpts)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode the options for the output cache: %w", err)
+	}
+	h.Write(opts)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s for the output cache: %w", path, err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(w, f); err != nil {
+		return fmt.Errorf("failed to read %s for the output cache: %w", path, err)
+	}
+	return nil
+}
+
+// readCachedOutput returns the cached output of a key, if any. Unreadable
+// entries are treated as missing, and overwritten.
+func readCachedOutput(dir, key string) (cachedOutput, bool) {
+	data, err := os.ReadFile(filepath.Join(dir, key+".json"))
+	if err != nil {
+		return cachedOutput{}, false
+	}
+	var out cachedOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return cachedOutput{}, false
+	}
+	return out, true
+}
+
+// writeCachedOutput stores the output of a key, through a temporary file
+// renamed into place so that concurrent runs never read partial entries.
+func writeCachedOutput(dir, key string, out cachedOutput) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output cache directory: %w", err)
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return fmt.Errorf("failed to encode output cache entry: %w", err)
+	}
+	f, err := os.CreateTemp(dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write output cache entry: %w", err)
+	}
+	_, err = f.Write(data)
+	err = errors.Join(err, f.Close())
+	if err == nil {
+		err = os.Rename(f.Name(), filepath.Join(dir, key+".json"))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to write output cache entry: %w", err)
+	}
+	return nil
+}
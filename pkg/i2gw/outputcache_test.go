@@ -0,0 +1,148 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_outputCacheKey(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	input := write("input.yaml", "kind: Ingress\n")
+	copied := write("copy.yaml", "kind: Ingress\n")
+	changed := write("changed.yaml", "kind: IngressClass\n")
+
+	key := func(runOpts RunOptions) string {
+		k, err := outputCacheKey(runOpts)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return k
+	}
+	base := key(RunOptions{InputFile: input, CacheDir: dir})
+
+	testCases := []struct {
+		name     string
+		runOpts  RunOptions
+		wantSame bool
+	}{{
+		name:     "same content at another path",
+		runOpts:  RunOptions{InputFile: copied, CacheDir: filepath.Join(dir, "other")},
+		wantSame: true,
+	}, {
+		name:     "timeout",
+		runOpts:  RunOptions{InputFile: input, CacheDir: dir, Timeout: 1},
+		wantSame: true,
+	}, {
+		name:     "clock",
+		runOpts:  RunOptions{InputFile: input, CacheDir: dir, Clock: FixedClock(time.Unix(0, 0))},
+		wantSame: true,
+	}, {
+		name:    "changed content",
+		runOpts: RunOptions{InputFile: changed, CacheDir: dir},
+	}, {
+		name:    "changed options",
+		runOpts: RunOptions{InputFile: input, CacheDir: dir, GatewayNamespace: "gateways"},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := key(tc.runOpts) == base; got != tc.wantSame {
+				t.Errorf("expected the key to be the same: %t, got %t", tc.wantSame, got)
+			}
+		})
+	}
+}
+
+func Test_cachedOutput(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	if _, ok := readCachedOutput(dir, "key"); ok {
+		t.Fatal("unexpected cached output before writing it")
+	}
+	want := cachedOutput{Stdout: []byte("kind: Gateway\n"), Stderr: []byte("report\n")}
+	if err := writeCachedOutput(dir, "key", want); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, ok := readCachedOutput(dir, "key")
+	if !ok {
+		t.Fatal("expected the cached output")
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected cached output (-want +got):\n%s", diff)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only the entry in the cache directory, got %d files", len(entries))
+	}
+}
+
+func Test_validateOutputCache(t *testing.T) {
+	testCases := []struct {
+		name        string
+		runOpts     RunOptions
+		expectError string
+	}{{
+		name:    "no cache",
+		runOpts: RunOptions{Summary: true},
+	}, {
+		name:    "input file to stdout",
+		runOpts: RunOptions{CacheDir: "cache", InputFile: "input.yaml", Output: OutputMermaid},
+	}, {
+		name:        "cluster input",
+		runOpts:     RunOptions{CacheDir: "cache"},
+		expectError: "the output cache requires an input file, without a Helm chart or streaming",
+	}, {
+		name:        "directory layout",
+		runOpts:     RunOptions{CacheDir: "cache", InputFile: "input.yaml", OutputLayout: LayoutGitOps},
+		expectError: "the output cache doesn't support the gitops output layout",
+	}, {
+		name:        "statistics",
+		runOpts:     RunOptions{CacheDir: "cache", InputFile: "input.yaml", Stats: true},
+		expectError: "the output cache can't be combined with the summary, reports, findings, host mapping, statistics or cleanup file",
+	}, {
+		name:        "target context",
+		runOpts:     RunOptions{CacheDir: "cache", InputFile: "input.yaml", TargetContext: "kind"},
+		expectError: "the output cache can't be combined with the options reading or changing a cluster",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateOutputCache(tc.runOpts)
+			var gotError string
+			if err != nil {
+				gotError = err.Error()
+			}
+			if diff := cmp.Diff(tc.expectError, gotError); diff != "" {
+				t.Errorf("unexpected error (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	apimachineryvalidation "k8s.io/apimachinery/pkg/util/validation"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// Annotations of Ingresses steering their own conversion.
+const (
+	// SkipAnnotation set to "true" leaves the Ingress out of the conversion.
+	SkipAnnotation = "ingress2gateway.kubernetes.io/skip"
+	// GatewayNameAnnotation names the Gateway the Ingress is converted to,
+	// instead of its IngressClass or group.
+	GatewayNameAnnotation = "ingress2gateway.kubernetes.io/gateway-name"
+	// RouteNameAnnotation names the HTTPRoutes of the hosts of the Ingress.
+	// The routes of Ingresses with several hosts are suffixed with their
+	// host.
+	RouteNameAnnotation = "ingress2gateway.kubernetes.io/route-name"
+	// TLSModeAnnotation set to Passthrough or Terminate selects whether the
+	// TLS connections to the hosts of the Ingress are passed through to
+	// their backends with TLSRoutes or terminated by the Gateway.
+	TLSModeAnnotation = "ingress2gateway.kubernetes.io/tls-mode"
+)
+
+// ingressOverrides are the conversion settings of an Ingress set by its
+// annotations.
+type ingressOverrides struct {
+	skip        bool
+	gatewayName string
+	routeName   string
+	tlsMode     gatewayv1.TLSModeType
+}
+
+func parseOverrides(ingress networkingv1.Ingress) (ingressOverrides, []Notification) {
+	var overrides ingressOverrides
+	var notes []Notification
+	if value, ok := ingress.Annotations[SkipAnnotation]; ok {
+		skip, err := strconv.ParseBool(value)
+		if err != nil {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, SkipAnnotation, value))
+		}
+		overrides.skip = skip
+	}
+	name := func(annotation string) string {
+		value, ok := ingress.Annotations[annotation]
+		if !ok {
+			return ""
+		}
+		if msgs := apimachineryvalidation.IsDNS1123Subdomain(value); len(msgs) > 0 {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it: %s", ingress.Namespace, ingress.Name, annotation, value, strings.Join(msgs, ", ")))
+			return ""
+		}
+		return value
+	}
+	overrides.gatewayName = name(GatewayNameAnnotation)
+	overrides.routeName = name(RouteNameAnnotation)
+	if value, ok := ingress.Annotations[TLSModeAnnotation]; ok {
+		if err := validateTLSMode(gatewayv1.TLSModeType(value)); err != nil {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation: %v, ignoring it", ingress.Namespace, ingress.Name, TLSModeAnnotation, err))
+		} else {
+			overrides.tlsMode = gatewayv1.TLSModeType(value)
+		}
+	}
+	return overrides, notes
+}
+
+// ingressRouteName is the route-name annotation of an Ingress, with the
+// number of hosts of the Ingress.
+type ingressRouteName struct {
+	name  string
+	hosts int
+}
+
+// routeNameOverride returns the name of the HTTPRoute of a rule group set by
+// the route-name annotation of the first of its Ingresses setting one.
+func (a *ingressAggregator) routeNameOverride(rg *ingressRuleGroup) (string, bool) {
+	var name string
+	var source string
+	for _, rule := range rg.rules {
+		ingress := types.NamespacedName{Namespace: rg.namespace, Name: rule.ingressName}
+		override, ok := a.routeNames[ingress]
+		if !ok {
+			continue
+		}
+		routeName := override.name
+		if override.hosts > 1 {
+			routeName = truncateName(routeName+"-"+nameFromHost(rg.host), routeName+"/"+rg.host, maxObjectNameLength)
+		}
+		switch {
+		case name == "":
+			name = routeName
+			source = rule.ingressName
+		case routeName != name:
+			a.notifications = append(a.notifications, notifications.NewWarning("Ingresses %s/%s and %s/%s name the HTTPRoute of host %q differently, it is named %s after the %s annotation of %s/%s", rg.namespace, source, rg.namespace, rule.ingressName, rg.host, name, RouteNameAnnotation, rg.namespace, source))
+		}
+	}
+	return name, name != ""
+}
@@ -0,0 +1,105 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func Test_ingressOverrides(t *testing.T) {
+	className := "nginx"
+	ingress := func(name string, annotations map[string]string, hosts ...string) networkingv1.Ingress {
+		ingress := networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Annotations: annotations},
+			Spec:       networkingv1.IngressSpec{IngressClassName: &className},
+		}
+		for _, host := range hosts {
+			ingress.Spec.Rules = append(ingress.Spec.Rules, networkingv1.IngressRule{
+				Host: host,
+				IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{{
+						Path:     "/" + name,
+						PathType: ptrTo(networkingv1.PathTypePrefix),
+						Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+							Name: name,
+							Port: networkingv1.ServiceBackendPort{Number: 80},
+						}},
+					}},
+				}},
+			})
+		}
+		return ingress
+	}
+
+	resources, report, err := Convert(context.Background(), ConvertOptions{
+		Ingresses: []networkingv1.Ingress{
+			ingress("legacy", map[string]string{SkipAnnotation: "true"}, "legacy.example.com"),
+			ingress("internal", map[string]string{GatewayNameAnnotation: "internal"}, "internal.example.com"),
+			ingress("shop", map[string]string{RouteNameAnnotation: "storefront"}, "shop.example.com"),
+			ingress("blog", map[string]string{RouteNameAnnotation: "blog"}, "blog.example.com", "news.example.com"),
+			ingress("invalid", map[string]string{RouteNameAnnotation: "Invalid_Name"}, "invalid.example.com"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var gateways []string
+	for _, gw := range resources.Gateways {
+		gateways = append(gateways, gw.Name)
+	}
+	sort.Strings(gateways)
+	if diff := cmp.Diff([]string{"internal", "nginx"}, gateways); diff != "" {
+		t.Errorf("Unexpected Gateways, diff (-want +got): %s", diff)
+	}
+
+	routes := map[string]string{}
+	for _, route := range resources.HTTPRoutes {
+		routes[string(route.Spec.Hostnames[0])] = route.Name + " -> " + string(route.Spec.ParentRefs[0].Name)
+	}
+	expectRoutes := map[string]string{
+		"internal.example.com": "internal-example-com -> internal",
+		"shop.example.com":     "storefront -> nginx",
+		"blog.example.com":     "blog-blog-example-com -> nginx",
+		"news.example.com":     "blog-news-example-com -> nginx",
+		"invalid.example.com":  "invalid-example-com -> nginx",
+	}
+	if diff := cmp.Diff(expectRoutes, routes); diff != "" {
+		t.Errorf("Unexpected HTTPRoutes, diff (-want +got): %s", diff)
+	}
+
+	if diff := cmp.Diff([]types.NamespacedName{{Namespace: "default", Name: "legacy"}}, report.SkippedIngresses); diff != "" {
+		t.Errorf("Unexpected skipped Ingresses, diff (-want +got): %s", diff)
+	}
+	var gotNotifications []string
+	for _, n := range report.Notifications {
+		gotNotifications = append(gotNotifications, n.String())
+	}
+	expectNotifications := []string{
+		"WARNING: Ingress default/invalid has an invalid ingress2gateway.kubernetes.io/route-name annotation \"Invalid_Name\", ignoring it: a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')",
+		"INFO: Ingress default/legacy is skipped as requested by its ingress2gateway.kubernetes.io/skip annotation",
+	}
+	if diff := cmp.Diff(expectNotifications, gotNotifications); diff != "" {
+		t.Errorf("Unexpected notifications, diff (-want +got): %s", diff)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// pathFeatures returns the features the rules of an Ingress path are
+// converted with: the ones of the Ingress, overridden by the ones the
+// provider set for the path. The policies of the Ingress attached to the
+// HTTPRoute are left as is.
+func pathFeatures(features *ir.IngressFeatures, override *ir.PathOverride) *ir.IngressFeatures {
+	if features == nil || override == nil {
+		return features
+	}
+	inherited := *features
+	if override.Timeouts != nil {
+		policy := ir.Policy{}
+		if features.Policy != nil {
+			policy = *features.Policy
+		}
+		policy.Timeouts = override.Timeouts
+		inherited.Policy = &policy
+	}
+	if override.Headers != nil {
+		headers := ir.Headers{Request: override.Headers.Request, Response: override.Headers.Response}
+		if features.Headers != nil {
+			headers.Request = overrideHeaderFilter(features.Headers.Request, override.Headers.Request)
+			headers.Response = overrideHeaderFilter(features.Headers.Response, override.Headers.Response)
+		}
+		inherited.Headers = &headers
+	}
+	return &inherited
+}
+
+// overrideHeaderFilter returns the header changes of override, and the ones
+// of inherited to the other headers.
+func overrideHeaderFilter(inherited, override *gatewayv1.HTTPHeaderFilter) *gatewayv1.HTTPHeaderFilter {
+	if inherited == nil {
+		return override
+	}
+	if override == nil {
+		return inherited
+	}
+	overridden := func(name gatewayv1.HTTPHeaderName) bool {
+		return headerIndex(override.Set, name) >= 0 || headerIndex(override.Add, name) >= 0 || containsFold(override.Remove, string(name))
+	}
+	merged := override.DeepCopy()
+	for _, header := range inherited.Set {
+		if !overridden(header.Name) {
+			merged.Set = append(merged.Set, header)
+		}
+	}
+	for _, header := range inherited.Add {
+		if !overridden(header.Name) {
+			merged.Add = append(merged.Add, header)
+		}
+	}
+	for _, name := range inherited.Remove {
+		if !overridden(gatewayv1.HTTPHeaderName(name)) {
+			merged.Remove = append(merged.Remove, name)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,111 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+type pathOverrideProvider struct {
+	features ir.IngressFeatures
+}
+
+func (pathOverrideProvider) Name() string       { return "path-overrides" }
+func (pathOverrideProvider) Controller() string { return "example.com/path-overrides" }
+func (p pathOverrideProvider) ParseIngress(networkingv1.Ingress) (ir.IngressFeatures, []notifications.Notification) {
+	return p.features, nil
+}
+
+func Test_pathFeatures(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	ingress := ingressWithPath("web", "/", &iPrefix, serviceBackend("web", 80), nil)
+	ingress.Spec.IngressClassName = stringPtr("custom")
+	ingress.Spec.Rules[0].HTTP.Paths = append(ingress.Spec.Rules[0].HTTP.Paths, networkingv1.HTTPIngressPath{
+		Path: "/upload", PathType: &iPrefix, Backend: serviceBackend("upload", 80),
+	})
+	class := networkingv1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "custom"},
+		Spec:       networkingv1.IngressClassSpec{Controller: "example.com/path-overrides"},
+	}
+	provider := pathOverrideProvider{features: ir.IngressFeatures{
+		Policy: &ir.Policy{Timeouts: &ir.Timeouts{Read: 10 * time.Second}},
+		Headers: &ir.Headers{Request: &gatewayv1.HTTPHeaderFilter{
+			Set:    []gatewayv1.HTTPHeader{{Name: "X-Team", Value: "web"}, {Name: "X-Tier", Value: "frontend"}},
+			Remove: []string{"X-Debug"},
+		}},
+		PathOverrides: map[string]ir.PathOverride{
+			"/upload": {
+				Timeouts: &ir.Timeouts{Read: 5 * time.Minute},
+				Headers: &ir.Headers{Request: &gatewayv1.HTTPHeaderFilter{
+					Set: []gatewayv1.HTTPHeader{{Name: "x-tier", Value: "storage"}},
+				}},
+			},
+		},
+	}}
+
+	resources, report := convertInput(inputResources{
+		ingresses:      []networkingv1.Ingress{ingress},
+		ingressClasses: []networkingv1.IngressClass{class},
+	}, ConvertOptions{Providers: []Provider{provider}})
+	if len(report.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", report.Errors)
+	}
+	if len(resources.HTTPRoutes) != 1 {
+		t.Fatalf("expected a single HTTPRoute, got %d", len(resources.HTTPRoutes))
+	}
+
+	type rule struct {
+		Timeout gatewayv1.Duration
+		Headers gatewayv1.HTTPHeaderFilter
+	}
+	got := map[string]rule{}
+	for _, r := range resources.HTTPRoutes[0].Spec.Rules {
+		var headers gatewayv1.HTTPHeaderFilter
+		for _, filter := range r.Filters {
+			if filter.Type == gatewayv1.HTTPRouteFilterRequestHeaderModifier {
+				headers = *filter.RequestHeaderModifier
+			}
+		}
+		got[*r.Matches[0].Path.Value] = rule{Timeout: *r.Timeouts.Request, Headers: headers}
+	}
+	want := map[string]rule{
+		"/": {
+			Timeout: "10s",
+			Headers: gatewayv1.HTTPHeaderFilter{
+				Set:    []gatewayv1.HTTPHeader{{Name: "X-Team", Value: "web"}, {Name: "X-Tier", Value: "frontend"}},
+				Remove: []string{"X-Debug"},
+			},
+		},
+		"/upload": {
+			Timeout: "300s",
+			Headers: gatewayv1.HTTPHeaderFilter{
+				Set:    []gatewayv1.HTTPHeader{{Name: "x-tier", Value: "storage"}, {Name: "X-Team", Value: "web"}},
+				Remove: []string{"X-Debug"},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected rules (-want +got):\n%s", diff)
+	}
+}
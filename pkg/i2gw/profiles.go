@@ -0,0 +1,106 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/targets/envoygateway"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/targets/gke"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/targets/istio"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/targets/nginxgatewayfabric"
+)
+
+// strictProfileVariant is the suffix of the profiles converting only what
+// is fully supported, such as envoy-gateway/strict.
+const strictProfileVariant = "strict"
+
+// Profile is a named preset of conversion options for a Gateway API
+// implementation, bundling the settings that are usually chosen together.
+// Zero fields leave the options at their defaults.
+type Profile struct {
+	Name        string
+	Description string
+
+	TargetImplementation string
+	Experimental         bool
+	ListenerStrategy     ListenerStrategy
+	AttachToListeners    bool
+	HTTPSOnly            HTTPSOnlyMode
+	Mode                 ConversionMode
+	DisabledFeatures     []string
+}
+
+var baseProfiles = []Profile{
+	{
+		Name:                 envoygateway.Name,
+		Description:          "Envoy Gateway policies and experimental Gateway API fields, with HTTPRoutes attached to their listeners",
+		TargetImplementation: envoygateway.Name,
+		Experimental:         true,
+		AttachToListeners:    true,
+	},
+	{
+		Name:                 gke.Name,
+		Description:          "GKE policies, with the hosts of wildcard certificates sharing their listeners",
+		TargetImplementation: gke.Name,
+		ListenerStrategy:     ListenerPerCertificate,
+	},
+	{
+		Name:                 istio.Name,
+		Description:          "Istio policies, with HTTPRoutes attached to their listeners",
+		TargetImplementation: istio.Name,
+		AttachToListeners:    true,
+	},
+	{
+		Name:                 nginxgatewayfabric.Name,
+		Description:          "NGINX Gateway Fabric policies",
+		TargetImplementation: nginxgatewayfabric.Name,
+	},
+}
+
+// Profiles returns the supported profiles. Each profile has a strict
+// variant, such as gke/strict, which converts only the Ingresses that are
+// fully supported, serves HTTPS only and leaves NGINX snippets out.
+func Profiles() []Profile {
+	var profiles []Profile
+	for _, p := range baseProfiles {
+		profiles = append(profiles, p, strictProfile(p))
+	}
+	return profiles
+}
+
+func strictProfile(p Profile) Profile {
+	p.Name += "/" + strictProfileVariant
+	p.Description += "; only fully converted Ingresses, HTTPS only and no NGINX snippets"
+	p.Mode = ModeStrict
+	p.HTTPSOnly = HTTPSOnlyRedirect
+	p.DisabledFeatures = append(slices.Clip(p.DisabledFeatures), "snippets")
+	return p
+}
+
+// LookupProfile returns the profile of the name, see Profiles.
+func LookupProfile(name string) (Profile, error) {
+	var names []string
+	for _, p := range Profiles() {
+		if p.Name == name {
+			return p, nil
+		}
+		names = append(names, p.Name)
+	}
+	return Profile{}, fmt.Errorf("unknown profile %q, supported ones are: %s", name, strings.Join(names, ", "))
+}
@@ -0,0 +1,89 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLookupProfile(t *testing.T) {
+	testCases := []struct {
+		name    string
+		want    Profile
+		wantErr bool
+	}{
+		{
+			name: "gke",
+			want: Profile{
+				Name:                 "gke",
+				Description:          "GKE policies, with the hosts of wildcard certificates sharing their listeners",
+				TargetImplementation: "gke",
+				ListenerStrategy:     ListenerPerCertificate,
+			},
+		},
+		{
+			name: "envoy-gateway/strict",
+			want: Profile{
+				Name:                 "envoy-gateway/strict",
+				Description:          "Envoy Gateway policies and experimental Gateway API fields, with HTTPRoutes attached to their listeners; only fully converted Ingresses, HTTPS only and no NGINX snippets",
+				TargetImplementation: "envoy-gateway",
+				Experimental:         true,
+				AttachToListeners:    true,
+				HTTPSOnly:            HTTPSOnlyRedirect,
+				Mode:                 ModeStrict,
+				DisabledFeatures:     []string{"snippets"},
+			},
+		},
+		{
+			name:    "gke/lenient",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := LookupProfile(tc.name)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected profile (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestProfilesAreValid(t *testing.T) {
+	for _, p := range Profiles() {
+		if _, err := lookupTargetImplementation(p.TargetImplementation); err != nil {
+			t.Errorf("profile %s: %v", p.Name, err)
+		}
+		if err := validateListenerStrategy(p.ListenerStrategy); err != nil {
+			t.Errorf("profile %s: %v", p.Name, err)
+		}
+		if err := validateHTTPSOnlyMode(p.HTTPSOnly); err != nil {
+			t.Errorf("profile %s: %v", p.Name, err)
+		}
+		if err := validateConversionMode(p.Mode); err != nil {
+			t.Errorf("profile %s: %v", p.Name, err)
+		}
+		if err := validateDisabledFeatures(p.DisabledFeatures); err != nil {
+			t.Errorf("profile %s: %v", p.Name, err)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ProgressiveDeliveryMode selects how the Ingresses whose canary weights a
+// progressive delivery controller, such as Argo Rollouts or Flagger, changes
+// at runtime are converted.
+type ProgressiveDeliveryMode string
+
+const (
+	// ProgressiveDeliveryFreeze converts the weights of the canaries as
+	// they are at conversion time, and reports them.
+	ProgressiveDeliveryFreeze ProgressiveDeliveryMode = "freeze"
+	// ProgressiveDeliveryHints sends no traffic to the canaries, as at the
+	// end of a rollout, and reports how to configure the controller to
+	// shift the traffic of the generated resources instead.
+	ProgressiveDeliveryHints ProgressiveDeliveryMode = "hints"
+)
+
+// ProgressiveDeliveryModes returns the names of the supported progressive
+// delivery modes.
+func ProgressiveDeliveryModes() []string {
+	return []string{string(ProgressiveDeliveryFreeze), string(ProgressiveDeliveryHints)}
+}
+
+func validateProgressiveDeliveryMode(mode ProgressiveDeliveryMode) error {
+	switch mode {
+	case "", ProgressiveDeliveryFreeze, ProgressiveDeliveryHints:
+		return nil
+	default:
+		return fmt.Errorf("unknown progressive delivery mode %q, supported ones are: %s", mode, strings.Join(ProgressiveDeliveryModes(), ", "))
+	}
+}
+
+var (
+	rolloutGroupKind = schema.GroupKind{Group: "argoproj.io", Kind: "Rollout"}
+	canaryGroupKind  = schema.GroupKind{Group: "flagger.app", Kind: "Canary"}
+)
+
+// deliveryController is the Rollout or Canary object managing the weights
+// of an Ingress.
+type deliveryController struct {
+	kind schema.GroupKind
+	name types.NamespacedName
+}
+
+func (c deliveryController) String() string {
+	if c.kind == rolloutGroupKind {
+		return fmt.Sprintf("Argo Rollouts Rollout %s", c.name)
+	}
+	return fmt.Sprintf("Flagger Canary %s", c.name)
+}
+
+// addDeliveryControllers records the Ingresses the Rollouts and Canaries of
+// the input route the traffic of. Their canary Ingresses are found by their
+// owner references.
+func (a *ingressAggregator) addDeliveryControllers(objects []unstructured.Unstructured) {
+	for _, obj := range objects {
+		var ingresses []string
+		switch obj.GroupVersionKind().GroupKind() {
+		case rolloutGroupKind:
+			routing := []string{"spec", "strategy", "canary", "trafficRouting"}
+			for _, field := range [][]string{{"nginx", "stableIngress"}, {"alb", "ingress"}} {
+				if name, _, _ := unstructured.NestedString(obj.Object, append(routing, field...)...); name != "" {
+					ingresses = append(ingresses, name)
+				}
+			}
+			for _, field := range [][]string{{"nginx", "additionalStableIngresses"}, {"alb", "ingresses"}} {
+				names, _, _ := unstructured.NestedStringSlice(obj.Object, append(routing, field...)...)
+				ingresses = append(ingresses, names...)
+			}
+		case canaryGroupKind:
+			if kind, _, _ := unstructured.NestedString(obj.Object, "spec", "ingressRef", "kind"); kind == "Ingress" {
+				name, _, _ := unstructured.NestedString(obj.Object, "spec", "ingressRef", "name")
+				ingresses = append(ingresses, name)
+			}
+		default:
+			continue
+		}
+		controller := deliveryController{
+			kind: obj.GroupVersionKind().GroupKind(),
+			name: types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()},
+		}
+		for _, name := range ingresses {
+			if a.deliveryControllers == nil {
+				a.deliveryControllers = map[types.NamespacedName]deliveryController{}
+			}
+			a.deliveryControllers[types.NamespacedName{Namespace: obj.GetNamespace(), Name: name}] = controller
+		}
+	}
+}
+
+// ingressDeliveryController returns the controller managing the weights of
+// the Ingress, if any.
+func (a *ingressAggregator) ingressDeliveryController(ingress networkingv1.Ingress) (deliveryController, bool) {
+	for _, owner := range ingress.OwnerReferences {
+		gv, err := schema.ParseGroupVersion(owner.APIVersion)
+		if err != nil {
+			continue
+		}
+		if kind := gv.WithKind(owner.Kind).GroupKind(); kind == rolloutGroupKind || kind == canaryGroupKind {
+			return deliveryController{kind: kind, name: types.NamespacedName{Namespace: ingress.Namespace, Name: owner.Name}}, true
+		}
+	}
+	controller, ok := a.deliveryControllers[types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}]
+	return controller, ok
+}
+
+// markDeliveryManaged reports the Ingresses managed by a progressive
+// delivery controller and, with ProgressiveDeliveryHints, sends no traffic
+// to their canaries.
+func (a *ingressAggregator) markDeliveryManaged(ingress networkingv1.Ingress, features *ir.IngressFeatures) {
+	controller, ok := a.ingressDeliveryController(ingress)
+	if !ok {
+		return
+	}
+	source := types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}
+	if a.managedIngresses == nil {
+		a.managedIngresses = map[types.NamespacedName]deliveryController{}
+	}
+	a.managedIngresses[source] = controller
+	if a.progressiveDelivery != ProgressiveDeliveryHints {
+		a.notifications = append(a.notifications, notifications.NewWarning("Ingress %s is managed by %s, which changes its canary weights at runtime: the weights of the HTTPRoutes are the ones at conversion time", source, controller))
+		return
+	}
+	if features.Canary != nil {
+		features.Canary.Weight = 0
+		features.Canary.Managed = true
+	}
+}
+
+// deliveryHints reports how to configure the progressive delivery
+// controllers of the Ingresses to shift the traffic of the HTTPRoutes and
+// Gateways converted from them.
+func (a *ingressAggregator) deliveryHints(resources Resources) []Notification {
+	objects := map[deliveryController][]string{}
+	var controllers []deliveryController
+	for i := range resources.HTTPRoutes {
+		route := &resources.HTTPRoutes[i]
+		for _, source := range resources.Sources[ObjectRef{Kind: "HTTPRoute", Namespace: route.Namespace, Name: route.Name}] {
+			controller, ok := a.managedIngresses[source.Ingress]
+			if !ok {
+				continue
+			}
+			if _, ok := objects[controller]; !ok {
+				controllers = append(controllers, controller)
+			}
+			var object string
+			if controller.kind == rolloutGroupKind {
+				object = fmt.Sprintf("HTTPRoute %s/%s", route.Namespace, route.Name)
+			} else {
+				for _, parent := range route.Spec.ParentRefs {
+					namespace := route.Namespace
+					if parent.Namespace != nil {
+						namespace = string(*parent.Namespace)
+					}
+					object = fmt.Sprintf("Gateway %s/%s", namespace, parent.Name)
+				}
+			}
+			if object != "" && !slices.Contains(objects[controller], object) {
+				objects[controller] = append(objects[controller], object)
+			}
+		}
+	}
+	sort.Slice(controllers, func(i, j int) bool {
+		return controllers[i].String() < controllers[j].String()
+	})
+
+	var notes []Notification
+	for _, controller := range controllers {
+		if controller.kind == rolloutGroupKind {
+			notes = append(notes, notifications.NewInfo("Configure %s to shift the traffic of %s with the argoproj-labs/gatewayAPI plugin of spec.strategy.canary.trafficRouting.plugins instead of its Ingresses, its canary gets no traffic until then", controller, strings.Join(objects[controller], ", ")))
+		} else {
+			notes = append(notes, notifications.NewInfo("Configure %s with the gatewayapi:v1 provider and the spec.service.gatewayRefs %s instead of its Ingresses, Flagger then generates the HTTPRoute of its canary, which replaces the converted one", controller, strings.Join(objects[controller], ", ")))
+		}
+	}
+	return notes
+}
@@ -0,0 +1,102 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_progressiveDelivery(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	web := ingressWithPath("web", "/", &iPrefix, serviceBackend("web", 80), nil)
+	rolloutCanary := ingressWithPath("web-canary", "/", &iPrefix, serviceBackend("web-preview", 80), map[string]string{
+		"nginx.ingress.kubernetes.io/canary":        "true",
+		"nginx.ingress.kubernetes.io/canary-weight": "20",
+	})
+	rolloutCanary.OwnerReferences = []metav1.OwnerReference{{APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout", Name: "web"}}
+	flaggerCanary := unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "flagger.app/v1beta1",
+		"kind":       "Canary",
+		"metadata":   map[string]interface{}{"namespace": "test", "name": "web"},
+		"spec": map[string]interface{}{
+			"ingressRef": map[string]interface{}{"apiVersion": "networking.k8s.io/v1", "kind": "Ingress", "name": "web"},
+		},
+	}}
+
+	testCases := []struct {
+		name        string
+		input       inputResources
+		mode        ProgressiveDeliveryMode
+		wantWeights []int32
+		wantNotes   []string
+	}{
+		{
+			name:        "frozen Argo Rollouts weights",
+			input:       inputResources{ingresses: []networkingv1.Ingress{web, rolloutCanary}},
+			wantWeights: []int32{80, 20},
+			wantNotes: []string{
+				"WARNING: Ingress test/web-canary is managed by Argo Rollouts Rollout test/web, which changes its canary weights at runtime: the weights of the HTTPRoutes are the ones at conversion time",
+			},
+		},
+		{
+			name:        "Argo Rollouts hints",
+			input:       inputResources{ingresses: []networkingv1.Ingress{web, rolloutCanary}},
+			mode:        ProgressiveDeliveryHints,
+			wantWeights: []int32{100, 0},
+			wantNotes: []string{
+				"INFO: Configure Argo Rollouts Rollout test/web to shift the traffic of HTTPRoute test/example-com with the argoproj-labs/gatewayAPI plugin of spec.strategy.canary.trafficRouting.plugins instead of its Ingresses, its canary gets no traffic until then",
+			},
+		},
+		{
+			name:  "Flagger hints",
+			input: inputResources{ingresses: []networkingv1.Ingress{web}, objects: []unstructured.Unstructured{flaggerCanary}},
+			mode:  ProgressiveDeliveryHints,
+			wantNotes: []string{
+				"INFO: Configure Flagger Canary test/web with the gatewayapi:v1 provider and the spec.service.gatewayRefs Gateway test/nginx instead of its Ingresses, Flagger then generates the HTTPRoute of its canary, which replaces the converted one",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resources, report := convertInput(tc.input, ConvertOptions{ProgressiveDelivery: tc.mode})
+			if len(resources.HTTPRoutes) != 1 || len(resources.HTTPRoutes[0].Spec.Rules) != 1 {
+				t.Fatalf("Expected 1 HTTPRoute with 1 rule, got %+v", resources.HTTPRoutes)
+			}
+			var weights []int32
+			for _, ref := range resources.HTTPRoutes[0].Spec.Rules[0].BackendRefs {
+				if ref.Weight != nil {
+					weights = append(weights, *ref.Weight)
+				}
+			}
+			if diff := cmp.Diff(tc.wantWeights, weights); diff != "" {
+				t.Errorf("Unexpected weights, diff (-want +got): %s", diff)
+			}
+			var notes []string
+			for _, n := range report.Notifications {
+				notes = append(notes, n.String())
+			}
+			if diff := cmp.Diff(tc.wantNotes, notes); diff != "" {
+				t.Errorf("Unexpected notifications, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/alb"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/ingressnginx"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Provider extracts the features of Ingresses served by a given Ingress
+// controller from its implementation-specific configuration, such as
+// annotations.
+type Provider interface {
+	// Name identifies the provider in user-facing messages.
+	Name() string
+	// Controller is the IngressClass spec.controller of the Ingresses the
+	// provider understands.
+	Controller() string
+	// ParseIngress returns the features of the Ingress, and notifications
+	// about the configuration that could not be converted.
+	ParseIngress(ingress networkingv1.Ingress) (ir.IngressFeatures, []notifications.Notification)
+}
+
+// ControllerConfigReader is implemented by providers that read the
+// controller-wide configuration, such as the ConfigMap of the controller,
+// from the objects of the input. ReadControllerConfig is called before any
+// Ingress is parsed.
+type ControllerConfigReader interface {
+	ReadControllerConfig(objects []unstructured.Unstructured) []notifications.Notification
+}
+
+// IngressClassReader is implemented by providers that need to know the
+// IngressClasses of their controller found in the input. ReadIngressClasses
+// is called before any Ingress is parsed.
+type IngressClassReader interface {
+	ReadIngressClasses(ingressClasses []networkingv1.IngressClass)
+}
+
+// IngressClassParametersReader is implemented by providers that convert the
+// spec.parameters of the IngressClasses of their controller, which are
+// looked up in the objects of the input. ReadIngressClassParameters is
+// called before any Ingress is parsed.
+type IngressClassParametersReader interface {
+	ReadIngressClassParameters(ingressClass networkingv1.IngressClass, objects []unstructured.Unstructured) []notifications.Notification
+}
+
+// BackendKind is the API group and kind of the resource of an Ingress
+// backend or of a backendRef, the group of core kinds being empty.
+type BackendKind struct {
+	Group string
+	Kind  string
+}
+
+// BackendKindTranslator is implemented by providers whose Ingress controller
+// routes resource backends of its own kinds, such as the backend CRDs of the
+// controller, which Gateway API implementations don't route to.
+// BackendKinds maps them to the kinds of the backendRefs of the same
+// backends, in order of preference: the first one the target
+// implementation routes to is used.
+type BackendKindTranslator interface {
+	BackendKinds() map[BackendKind][]BackendKind
+}
+
+func builtinProviders() []Provider {
+	return []Provider{ingressnginx.NewProvider(), alb.NewProvider()}
+}
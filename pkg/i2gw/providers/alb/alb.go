@@ -0,0 +1,197 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package alb converts the annotations of Ingresses served by the AWS Load
+// Balancer Controller.
+package alb
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+)
+
+const (
+	// Name is the name of the provider.
+	Name = "aws-load-balancer-controller"
+	// Controller is the IngressClass spec.controller of the AWS Load
+	// Balancer Controller.
+	Controller = "ingress.k8s.aws/alb"
+
+	annotationPrefix = "alb.ingress.kubernetes.io/"
+
+	groupNameAnnotation              = annotationPrefix + "group.name"
+	listenPortsAnnotation            = annotationPrefix + "listen-ports"
+	backendProtocolAnnotation        = annotationPrefix + "backend-protocol"
+	backendProtocolVersionAnnotation = annotationPrefix + "backend-protocol-version"
+)
+
+// Provider extracts features from the annotations of the AWS Load Balancer
+// Controller and the IngressClassParams of IngressClasses.
+type Provider struct {
+	// classGroups are the groups set by the IngressClassParams of
+	// IngressClasses, by IngressClass.
+	classGroups map[string]string
+}
+
+// NewProvider returns the AWS Load Balancer Controller provider.
+func NewProvider() *Provider {
+	return &Provider{classGroups: map[string]string{}}
+}
+
+func (p *Provider) Name() string {
+	return Name
+}
+
+func (p *Provider) Controller() string {
+	return Controller
+}
+
+// ParseIngress returns the group, listen ports, backend protocol and request
+// matches of the Ingress. Ingresses of a group share an ALB, so they are
+// converted to a Gateway named after the group. The group of the
+// IngressClassParams of the IngressClass takes precedence over the group.name
+// annotation, as with the controller.
+func (p *Provider) ParseIngress(ingress networkingv1.Ingress) (ir.IngressFeatures, []notifications.Notification) {
+	var features ir.IngressFeatures
+	var notes []notifications.Notification
+
+	ingressClass := ingress.Annotations[networkingv1beta1.AnnotationIngressClass]
+	if ingress.Spec.IngressClassName != nil {
+		ingressClass = *ingress.Spec.IngressClassName
+	}
+	annotationGroup := strings.TrimSpace(ingress.Annotations[groupNameAnnotation])
+	if group, ok := p.classGroups[ingressClass]; ok {
+		features.Group = group
+		if annotationGroup != "" && annotationGroup != group {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s sets %s %q, which is overridden by the group %q of the IngressClassParams of IngressClass %s", ingress.Namespace, ingress.Name, groupNameAnnotation, annotationGroup, group, ingressClass))
+		}
+	} else {
+		features.Group = annotationGroup
+	}
+
+	if value, ok := ingress.Annotations[listenPortsAnnotation]; ok {
+		ports, err := parseListenPorts(value)
+		if err != nil {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation, its hosts are served on the ports of the Gateway: %v", ingress.Namespace, ingress.Name, listenPortsAnnotation, err))
+		}
+		features.ListenPorts = ports
+	}
+
+	backendProtocol, backendProtocolNotes := parseBackendProtocolVersion(ingress)
+	features.BackendProtocol = backendProtocol
+	notes = append(notes, backendProtocolNotes...)
+
+	matches, unsupportedConditions, conditionNotes := parseConditions(ingress)
+	notes = append(notes, conditionNotes...)
+	features.Matches = matches
+
+	features.UnsupportedAnnotations = append(unsupportedAnnotations(ingress), unsupportedConditions...)
+	sort.Strings(features.UnsupportedAnnotations)
+	features.ConvertedAnnotations = slices.DeleteFunc(convertedAnnotations(ingress), func(annotation string) bool {
+		return slices.Contains(unsupportedConditions, annotation)
+	})
+	return features, notes
+}
+
+// supportedAnnotations are the AWS Load Balancer Controller annotations that
+// are converted.
+var supportedAnnotations = map[string]struct{}{
+	groupNameAnnotation:              {},
+	listenPortsAnnotation:            {},
+	backendProtocolVersionAnnotation: {},
+}
+
+func isSupportedAnnotation(annotation string) bool {
+	_, ok := supportedAnnotations[annotation]
+	return ok || strings.HasPrefix(annotation, conditionsAnnotationPrefix)
+}
+
+// parseListenPorts parses the listen-ports annotation, a JSON list of
+// single-entry objects from protocol to port, such as
+// [{"HTTP": 80}, {"HTTPS": 8443}].
+func parseListenPorts(value string) ([]ir.ListenerPort, error) {
+	var entries []map[string]int32
+	if err := json.Unmarshal([]byte(value), &entries); err != nil {
+		return nil, err
+	}
+	var ports []ir.ListenerPort
+	for _, entry := range entries {
+		for protocol, port := range entry {
+			if protocol != "HTTP" && protocol != "HTTPS" {
+				return nil, fmt.Errorf("unsupported protocol %q", protocol)
+			}
+			if port < 1 || port > 65535 {
+				return nil, fmt.Errorf("invalid %s port %d", protocol, port)
+			}
+			ports = append(ports, ir.ListenerPort{Protocol: strings.ToLower(protocol), Port: port})
+		}
+	}
+	return ports, nil
+}
+
+// parseBackendProtocolVersion converts backend-protocol-version, with which
+// the ALB connects to its targets with HTTP/2, such as for gRPC, over TLS
+// when backend-protocol is HTTPS.
+func parseBackendProtocolVersion(ingress networkingv1.Ingress) (*ir.BackendProtocol, []notifications.Notification) {
+	value, ok := ingress.Annotations[backendProtocolVersionAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	switch version := strings.ToUpper(strings.TrimSpace(value)); version {
+	case "HTTP1":
+		return nil, nil
+	case "HTTP2", "GRPC":
+		if strings.ToUpper(strings.TrimSpace(ingress.Annotations[backendProtocolAnnotation])) == "HTTPS" {
+			return &ir.BackendProtocol{Name: version}, []notifications.Notification{notifications.NewInfo("Ingress %s/%s connects to its targets with %s over TLS, Gateway API implementations only use HTTP/2 for them if they negotiate it with the backends", ingress.Namespace, ingress.Name, version)}
+		}
+		return &ir.BackendProtocol{Name: version, AppProtocol: ir.AppProtocolH2C}, nil
+	default:
+		return nil, []notifications.Notification{notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, backendProtocolVersionAnnotation, value)}
+	}
+}
+
+func convertedAnnotations(ingress networkingv1.Ingress) []string {
+	var converted []string
+	for annotation := range ingress.Annotations {
+		if isSupportedAnnotation(annotation) {
+			converted = append(converted, annotation)
+		}
+	}
+	sort.Strings(converted)
+	return converted
+}
+
+func unsupportedAnnotations(ingress networkingv1.Ingress) []string {
+	var unsupported []string
+	for annotation := range ingress.Annotations {
+		if !strings.HasPrefix(annotation, annotationPrefix) {
+			continue
+		}
+		if !isSupportedAnnotation(annotation) {
+			unsupported = append(unsupported, annotation)
+		}
+	}
+	sort.Strings(unsupported)
+	return unsupported
+}
@@ -0,0 +1,214 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// conditionsAnnotationPrefix prefixes the conditions annotations, named
+// after the Service of the paths whose requests they restrict.
+const conditionsAnnotationPrefix = annotationPrefix + "conditions."
+
+// condition is a rule condition of the conditions annotations. The values of
+// a condition match any of them, and all the conditions of a path must
+// match.
+type condition struct {
+	Field            string `json:"field"`
+	HTTPHeaderConfig *struct {
+		HTTPHeaderName string   `json:"httpHeaderName"`
+		Values         []string `json:"values"`
+	} `json:"httpHeaderConfig"`
+	HTTPRequestMethodConfig *struct {
+		Values []string `json:"values"`
+	} `json:"httpRequestMethodConfig"`
+	QueryStringConfig *struct {
+		Values []struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		} `json:"values"`
+	} `json:"queryStringConfig"`
+}
+
+var httpMethods = map[string]bool{
+	"GET": true, "HEAD": true, "POST": true, "PUT": true, "DELETE": true,
+	"CONNECT": true, "OPTIONS": true, "TRACE": true, "PATCH": true,
+}
+
+// parseConditions converts the http-header, http-request-method and
+// query-string conditions of the Services of the paths of the Ingress to
+// request matches, one for each combination of their values. The other
+// conditions, such as source-ip, can't be matched by HTTPRoutes, so their
+// annotations are returned as unsupported.
+func parseConditions(ingress networkingv1.Ingress) (map[ir.PathBackend][]ir.RequestMatch, []string, []notifications.Notification) {
+	var notes []notifications.Notification
+	var unsupported []string
+	byService := map[string][]ir.RequestMatch{}
+	for annotation, value := range ingress.Annotations {
+		service, ok := strings.CutPrefix(annotation, conditionsAnnotationPrefix)
+		if !ok {
+			continue
+		}
+		matches, err := conditionMatches(value)
+		var unconverted unconvertedConditionError
+		if errors.As(err, &unconverted) {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s restricts the requests to Service %s with %s conditions, which can't be converted, the requests are not restricted", ingress.Namespace, ingress.Name, service, unconverted.field))
+			unsupported = append(unsupported, annotation)
+			continue
+		}
+		if err != nil {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation, the requests to Service %s are not restricted by it: %v", ingress.Namespace, ingress.Name, annotation, service, err))
+			continue
+		}
+		byService[service] = matches
+	}
+	if len(byService) == 0 {
+		return nil, unsupported, notes
+	}
+
+	matches := map[ir.PathBackend][]ir.RequestMatch{}
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service == nil {
+				continue
+			}
+			// Request matches are set by path and backend, so that the
+			// other backends of the path receive the other requests.
+			if pathMatches, ok := byService[path.Backend.Service.Name]; ok {
+				matches[ir.PathBackend{Path: path.Path, Service: path.Backend.Service.Name}] = pathMatches
+			}
+		}
+	}
+	return matches, unsupported, notes
+}
+
+// unconvertedConditionError is returned for the conditions HTTPRoutes can't
+// match.
+type unconvertedConditionError struct {
+	field string
+}
+
+func (e unconvertedConditionError) Error() string {
+	return fmt.Sprintf("%s conditions can't be converted", e.field)
+}
+
+func conditionMatches(value string) ([]ir.RequestMatch, error) {
+	var conditions []condition
+	if err := json.Unmarshal([]byte(value), &conditions); err != nil {
+		return nil, err
+	}
+	matches := []ir.RequestMatch{{}}
+	// cross adds the alternatives of a condition to every match.
+	cross := func(n int, set func(m *ir.RequestMatch, i int)) {
+		var crossed []ir.RequestMatch
+		for _, m := range matches {
+			for i := 0; i < n; i++ {
+				c := ir.RequestMatch{
+					Method:      m.Method,
+					Headers:     append([]gatewayv1.HTTPHeaderMatch(nil), m.Headers...),
+					QueryParams: append([]gatewayv1.HTTPQueryParamMatch(nil), m.QueryParams...),
+				}
+				set(&c, i)
+				crossed = append(crossed, c)
+			}
+		}
+		matches = crossed
+	}
+	for _, c := range conditions {
+		switch c.Field {
+		case "http-header":
+			if c.HTTPHeaderConfig == nil || c.HTTPHeaderConfig.HTTPHeaderName == "" || len(c.HTTPHeaderConfig.Values) == 0 {
+				return nil, fmt.Errorf("http-header condition without header name or values")
+			}
+			config := c.HTTPHeaderConfig
+			cross(len(config.Values), func(m *ir.RequestMatch, i int) {
+				matchType, matchValue := wildcardMatch(config.Values[i])
+				headerType := gatewayv1.HeaderMatchType(matchType)
+				m.Headers = append(m.Headers, gatewayv1.HTTPHeaderMatch{Type: &headerType, Name: gatewayv1.HTTPHeaderName(config.HTTPHeaderName), Value: matchValue})
+			})
+		case "http-request-method":
+			if c.HTTPRequestMethodConfig == nil || len(c.HTTPRequestMethodConfig.Values) == 0 {
+				return nil, fmt.Errorf("http-request-method condition without values")
+			}
+			methods := c.HTTPRequestMethodConfig.Values
+			for _, method := range methods {
+				if !httpMethods[method] {
+					return nil, fmt.Errorf("unsupported HTTP method %q", method)
+				}
+			}
+			if len(matches) > 0 && matches[0].Method != nil {
+				return nil, fmt.Errorf("several http-request-method conditions")
+			}
+			cross(len(methods), func(m *ir.RequestMatch, i int) {
+				method := gatewayv1.HTTPMethod(methods[i])
+				m.Method = &method
+			})
+		case "query-string":
+			if c.QueryStringConfig == nil || len(c.QueryStringConfig.Values) == 0 {
+				return nil, fmt.Errorf("query-string condition without values")
+			}
+			values := c.QueryStringConfig.Values
+			for _, v := range values {
+				if v.Key == "" {
+					return nil, fmt.Errorf("query-string condition on the value %q of any key", v.Value)
+				}
+			}
+			cross(len(values), func(m *ir.RequestMatch, i int) {
+				matchType, matchValue := wildcardMatch(values[i].Value)
+				queryType := gatewayv1.QueryParamMatchType(matchType)
+				m.QueryParams = append(m.QueryParams, gatewayv1.HTTPQueryParamMatch{Type: &queryType, Name: gatewayv1.HTTPHeaderName(values[i].Key), Value: matchValue})
+			})
+		default:
+			return nil, unconvertedConditionError{field: c.Field}
+		}
+	}
+	return matches, nil
+}
+
+// wildcardMatch returns the match type and value of a condition value, in
+// which * matches any characters and ? a single one.
+func wildcardMatch(value string) (string, string) {
+	if !strings.ContainsAny(value, "*?") {
+		return "Exact", value
+	}
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	for _, r := range value {
+		switch r {
+		case '*':
+			pattern.WriteString(".*")
+		case '?':
+			pattern.WriteString(".")
+		default:
+			pattern.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	pattern.WriteString("$")
+	return "RegularExpression", pattern.String()
+}
@@ -0,0 +1,70 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alb
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	paramsAPIGroup   = "elbv2.k8s.aws"
+	paramsAPIVersion = paramsAPIGroup + "/v1beta1"
+	paramsKind       = "IngressClassParams"
+)
+
+// ReadIngressClassParameters reads the group of the IngressClassParams of
+// the IngressClass. Its other settings configure the ALB itself, which has
+// no Gateway API equivalent, so they are reported.
+func (p *Provider) ReadIngressClassParameters(ingressClass networkingv1.IngressClass, objects []unstructured.Unstructured) []notifications.Notification {
+	ref := ingressClass.Spec.Parameters
+	if ref.APIGroup == nil || *ref.APIGroup != paramsAPIGroup || ref.Kind != paramsKind {
+		return nil
+	}
+	var params *unstructured.Unstructured
+	for i := range objects {
+		if objects[i].GetAPIVersion() == paramsAPIVersion && objects[i].GetKind() == paramsKind && objects[i].GetName() == ref.Name {
+			params = &objects[i]
+			break
+		}
+	}
+	if params == nil {
+		return []notifications.Notification{notifications.NewWarning("IngressClassParams %s of IngressClass %s is not part of the input, the group of its Ingresses is read from their %s annotation", ref.Name, ingressClass.Name, groupNameAnnotation)}
+	}
+
+	var notes []notifications.Notification
+	group, _, _ := unstructured.NestedString(params.Object, "spec", "group", "name")
+	if group = strings.TrimSpace(group); group != "" {
+		p.classGroups[ingressClass.Name] = group
+	}
+	spec, _, _ := unstructured.NestedMap(params.Object, "spec")
+	var settings []string
+	for field := range spec {
+		if field != "group" {
+			settings = append(settings, field)
+		}
+	}
+	if len(settings) > 0 {
+		sort.Strings(settings)
+		notes = append(notes, notifications.NewInfo("IngressClassParams %s of IngressClass %s sets %s, which have no Gateway API equivalent, configure the equivalent infrastructure settings on GatewayClass %s", ref.Name, ingressClass.Name, strings.Join(settings, ", "), ingressClass.Name))
+	}
+	return notes
+}
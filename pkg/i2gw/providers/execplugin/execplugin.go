@@ -0,0 +1,175 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package execplugin runs out-of-tree providers as external commands, in the
+// same way as kubectl credential plugins: the command receives a
+// ProviderRequest with the Ingress as JSON on stdin and writes a
+// ProviderResponse with the features of the Ingress as JSON on stdout.
+package execplugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+const (
+	// APIVersion is the version of the protocol, set in requests and
+	// expected in responses.
+	APIVersion = "ingress2gateway.kubernetes.io/v1alpha1"
+
+	requestKind  = "ProviderRequest"
+	responseKind = "ProviderResponse"
+
+	// DefaultTimeout is how long the command is given to parse an Ingress.
+	DefaultTimeout = 30 * time.Second
+)
+
+// ProviderRequest is written to the stdin of the command.
+type ProviderRequest struct {
+	APIVersion string               `json:"apiVersion"`
+	Kind       string               `json:"kind"`
+	Ingress    networkingv1.Ingress `json:"ingress"`
+}
+
+// ProviderResponse is read from the stdout of the command. Features are
+// decoded into ir.IngressFeatures, whose fields are matched
+// case-insensitively, such as "canary", "policy" or
+// "unsupportedAnnotations".
+type ProviderResponse struct {
+	APIVersion    string                 `json:"apiVersion"`
+	Kind          string                 `json:"kind"`
+	Features      ir.IngressFeatures     `json:"features"`
+	Notifications []ResponseNotification `json:"notifications,omitempty"`
+}
+
+// ResponseNotification is a notification about the Ingress, of type INFO,
+// WARNING or BLOCKING.
+type ResponseNotification struct {
+	Type    notifications.Type `json:"type"`
+	Message string             `json:"message"`
+}
+
+// Provider parses the Ingresses of a controller by running a command.
+type Provider struct {
+	name       string
+	controller string
+	command    string
+	args       []string
+	// Timeout is how long the command is given to parse an Ingress. It
+	// defaults to DefaultTimeout.
+	Timeout time.Duration
+}
+
+// NewProvider returns a provider parsing the Ingresses of the IngressClasses
+// of controller with the command. The provider is named after the command.
+func NewProvider(controller, command string, args ...string) *Provider {
+	return &Provider{
+		name:       filepath.Base(command),
+		controller: controller,
+		command:    command,
+		args:       args,
+		Timeout:    DefaultTimeout,
+	}
+}
+
+// ParseProviderPlugin parses the controller=command form of a provider
+// plugin. The command is split on spaces into the path of the command and
+// its arguments.
+func ParseProviderPlugin(s string) (*Provider, error) {
+	controller, command, ok := strings.Cut(s, "=")
+	fields := strings.Fields(command)
+	if !ok || strings.TrimSpace(controller) == "" || len(fields) == 0 {
+		return nil, fmt.Errorf("%q must be of the form controller=command", s)
+	}
+	return NewProvider(strings.TrimSpace(controller), fields[0], fields[1:]...), nil
+}
+
+func (p *Provider) Name() string {
+	return p.name
+}
+
+func (p *Provider) Controller() string {
+	return p.controller
+}
+
+// ParseIngress runs the command with the Ingress. If the command fails, or
+// its response can't be decoded, the features of the Ingress are left
+// empty and the failure is reported as a warning.
+func (p *Provider) ParseIngress(ingress networkingv1.Ingress) (ir.IngressFeatures, []notifications.Notification) {
+	response, err := p.run(ingress)
+	if err != nil {
+		return ir.IngressFeatures{}, []notifications.Notification{
+			notifications.NewWarning("Provider plugin %s failed to parse Ingress %s/%s, its annotations are not converted: %v", p.name, ingress.Namespace, ingress.Name, err),
+		}
+	}
+	var notes []notifications.Notification
+	for _, n := range response.Notifications {
+		switch n.Type {
+		case notifications.InfoNotification, notifications.WarningNotification, notifications.BlockingNotification:
+			notes = append(notes, notifications.Notification{Type: n.Type, Message: n.Message})
+		default:
+			notes = append(notes, notifications.NewWarning("%s (reported by provider plugin %s with unknown type %q)", n.Message, p.name, n.Type))
+		}
+	}
+	return response.Features, notes
+}
+
+func (p *Provider) run(ingress networkingv1.Ingress) (ProviderResponse, error) {
+	request, err := json.Marshal(ProviderRequest{APIVersion: APIVersion, Kind: requestKind, Ingress: ingress})
+	if err != nil {
+		return ProviderResponse{}, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, p.command, p.args...)
+	var stdout bytes.Buffer
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = &stdout
+	// As with credential plugins, the command may talk to the user on
+	// stderr.
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ProviderResponse{}, fmt.Errorf("timed out after %s", timeout)
+		}
+		return ProviderResponse{}, err
+	}
+
+	var response ProviderResponse
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return ProviderResponse{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if response.APIVersion != APIVersion || response.Kind != responseKind {
+		return ProviderResponse{}, fmt.Errorf("unsupported response %s %s, expected %s %s", response.APIVersion, response.Kind, APIVersion, responseKind)
+	}
+	return response, nil
+}
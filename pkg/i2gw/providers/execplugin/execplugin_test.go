@@ -0,0 +1,171 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package execplugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// TestMain runs the test binary as a provider plugin, answering as the
+// I2GW_TEST_PLUGIN environment variable tells, if it is set.
+func TestMain(m *testing.M) {
+	switch os.Getenv("I2GW_TEST_PLUGIN") {
+	case "":
+		os.Exit(m.Run())
+	case "features":
+		var request ProviderRequest
+		if err := json.NewDecoder(os.Stdin).Decode(&request); err != nil {
+			os.Exit(2)
+		}
+		fmt.Printf(`{"apiVersion": %q, "kind": "ProviderResponse", "features": {"group": %q, "policy": {"ipAllowList": ["10.0.0.0/8"]}, "unsupportedAnnotations": ["example.com/waf"]}, "notifications": [{"type": "INFO", "message": "Ingress %s/%s is served by the example controller"}, {"type": "DEBUG", "message": "debug"}]}`,
+			APIVersion, request.Ingress.Annotations["example.com/group"], request.Ingress.Namespace, request.Ingress.Name)
+	case "matches":
+		fmt.Printf(`{"apiVersion": %q, "kind": "ProviderResponse", "features": {"matches": {"/api": [{"method": "GET", "queryParams": [{"name": "version", "value": "2"}]}], "beta:/web": [{"headers": [{"name": "X-Beta", "value": "true"}]}]}}}`, APIVersion)
+	case "version":
+		fmt.Print(`{"apiVersion": "ingress2gateway.kubernetes.io/v2", "kind": "ProviderResponse"}`)
+	case "fail":
+		os.Exit(1)
+	case "sleep":
+		time.Sleep(time.Minute)
+	}
+	os.Exit(0)
+}
+
+func Test_ParseIngress(t *testing.T) {
+	ingress := networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "default",
+		Name:        "web",
+		Annotations: map[string]string{"example.com/group": "shared"},
+	}}
+	get := gatewayv1.HTTPMethodGet
+
+	testCases := []struct {
+		name                string
+		plugin              string
+		timeout             time.Duration
+		expectedFeatures    ir.IngressFeatures
+		expectNotifications []notifications.Notification
+	}{{
+		name:   "features and notifications",
+		plugin: "features",
+		expectedFeatures: ir.IngressFeatures{
+			Group:                  "shared",
+			Policy:                 &ir.Policy{IPAllowList: []string{"10.0.0.0/8"}},
+			UnsupportedAnnotations: []string{"example.com/waf"},
+		},
+		expectNotifications: []notifications.Notification{
+			notifications.NewInfo("Ingress default/web is served by the example controller"),
+			notifications.NewWarning(`debug (reported by provider plugin execplugin.test with unknown type "DEBUG")`),
+		},
+	}, {
+		name:   "matches by path and backend",
+		plugin: "matches",
+		expectedFeatures: ir.IngressFeatures{
+			Matches: map[ir.PathBackend][]ir.RequestMatch{
+				{Path: "/api"}: {{
+					Method:      &get,
+					QueryParams: []gatewayv1.HTTPQueryParamMatch{{Name: "version", Value: "2"}},
+				}},
+				{Path: "/web", Service: "beta"}: {{
+					Headers: []gatewayv1.HTTPHeaderMatch{{Name: "X-Beta", Value: "true"}},
+				}},
+			},
+		},
+	}, {
+		name:   "unsupported version",
+		plugin: "version",
+		expectNotifications: []notifications.Notification{
+			notifications.NewWarning("Provider plugin execplugin.test failed to parse Ingress default/web, its annotations are not converted: unsupported response ingress2gateway.kubernetes.io/v2 ProviderResponse, expected ingress2gateway.kubernetes.io/v1alpha1 ProviderResponse"),
+		},
+	}, {
+		name:   "failing command",
+		plugin: "fail",
+		expectNotifications: []notifications.Notification{
+			notifications.NewWarning("Provider plugin execplugin.test failed to parse Ingress default/web, its annotations are not converted: exit status 1"),
+		},
+	}, {
+		name:    "timeout",
+		plugin:  "sleep",
+		timeout: 100 * time.Millisecond,
+		expectNotifications: []notifications.Notification{
+			notifications.NewWarning("Provider plugin execplugin.test failed to parse Ingress default/web, its annotations are not converted: timed out after 100ms"),
+		},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("I2GW_TEST_PLUGIN", tc.plugin)
+			p := NewProvider("example.com/ingress-controller", os.Args[0])
+			if tc.timeout > 0 {
+				p.Timeout = tc.timeout
+			}
+
+			features, notes := p.ParseIngress(ingress)
+			if diff := cmp.Diff(tc.expectedFeatures, features); diff != "" {
+				t.Errorf("ParseIngress() features mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectNotifications, notes); diff != "" {
+				t.Errorf("ParseIngress() notifications mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_ParseProviderPlugin(t *testing.T) {
+	testCases := []struct {
+		value              string
+		expectedController string
+		expectedCommand    string
+		expectedArgs       []string
+		expectError        bool
+	}{
+		{value: "example.com/ingress-controller=/usr/bin/i2gw-example", expectedController: "example.com/ingress-controller", expectedCommand: "/usr/bin/i2gw-example"},
+		{value: "example.com/ingress-controller=i2gw-example --verbose", expectedController: "example.com/ingress-controller", expectedCommand: "i2gw-example", expectedArgs: []string{"--verbose"}},
+		{value: "i2gw-example", expectError: true},
+		{value: "=i2gw-example", expectError: true},
+		{value: "example.com/ingress-controller=", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			p, err := ParseProviderPlugin(tc.value)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("ParseProviderPlugin() succeeded, expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseProviderPlugin() failed: %v", err)
+			}
+			if p.Controller() != tc.expectedController || p.command != tc.expectedCommand || !cmp.Equal(p.args, tc.expectedArgs, cmpopts.EquateEmpty()) {
+				t.Errorf("ParseProviderPlugin() = %s %s %v, expected %s %s %v", p.Controller(), p.command, p.args, tc.expectedController, tc.expectedCommand, tc.expectedArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+const (
+	upstreamHashByAnnotation      = annotationPrefix + "upstream-hash-by"
+	affinityAnnotation            = annotationPrefix + "affinity"
+	sessionCookieNameAnnotation   = annotationPrefix + "session-cookie-name"
+	sessionCookiePathAnnotation   = annotationPrefix + "session-cookie-path"
+	sessionCookieMaxAgeAnnotation = annotationPrefix + "session-cookie-max-age"
+	loadBalanceAnnotation         = annotationPrefix + "load-balance"
+
+	defaultSessionCookieName = "INGRESSCOOKIE"
+)
+
+// parseConsistentHash converts cookie affinity and upstream-hash-by keys made
+// of a single NGINX variable. Cookie affinity takes precedence, as it does in
+// ingress-nginx.
+func parseConsistentHash(ingress networkingv1.Ingress) (*ir.ConsistentHash, []notifications.Notification) {
+	var notes []notifications.Notification
+
+	if affinity, ok := ingress.Annotations[affinityAnnotation]; ok {
+		if affinity != "cookie" {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, affinityAnnotation, affinity))
+			return nil, notes
+		}
+		cookie := &ir.HashCookie{
+			Name: ingress.Annotations[sessionCookieNameAnnotation],
+			Path: ingress.Annotations[sessionCookiePathAnnotation],
+		}
+		if cookie.Name == "" {
+			cookie.Name = defaultSessionCookieName
+		}
+		if value, ok := ingress.Annotations[sessionCookieMaxAgeAnnotation]; ok {
+			seconds, err := strconv.Atoi(value)
+			if err != nil || seconds < 0 {
+				notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, sessionCookieMaxAgeAnnotation, value))
+			} else {
+				cookie.TTL = time.Duration(seconds) * time.Second
+			}
+		}
+		return &ir.ConsistentHash{Cookie: cookie}, notes
+	}
+
+	key, ok := ingress.Annotations[upstreamHashByAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	switch {
+	case key == "$remote_addr" || key == "$binary_remote_addr":
+		return &ir.ConsistentHash{SourceIP: true}, nil
+	case isVariable(key, "$http_"):
+		return &ir.ConsistentHash{Header: strings.ReplaceAll(strings.TrimPrefix(key, "$http_"), "_", "-")}, nil
+	case isVariable(key, "$cookie_"):
+		return &ir.ConsistentHash{Cookie: &ir.HashCookie{Name: strings.TrimPrefix(key, "$cookie_")}}, nil
+	case isVariable(key, "$arg_"):
+		return &ir.ConsistentHash{QueryParameter: strings.TrimPrefix(key, "$arg_")}, nil
+	}
+	notes = append(notes, notifications.NewWarning("Ingress %s/%s hashes requests by %q, only $remote_addr, $http_*, $cookie_* and $arg_* variables can be converted", ingress.Namespace, ingress.Name, key))
+	return nil, notes
+}
+
+// loadBalanceAlgorithms are the algorithms of ingress-nginx. EWMA, which
+// prefers the endpoints with the lowest weighted latency, is approximated by
+// least request.
+var loadBalanceAlgorithms = map[string]ir.LoadBalanceAlgorithm{
+	"round_robin": ir.LoadBalanceRoundRobin,
+	"ewma":        ir.LoadBalanceLeastRequest,
+	"least_conn":  ir.LoadBalanceLeastRequest,
+}
+
+func parseLoadBalance(ingress networkingv1.Ingress) (ir.LoadBalanceAlgorithm, []notifications.Notification) {
+	value, ok := ingress.Annotations[loadBalanceAnnotation]
+	if !ok {
+		return "", nil
+	}
+	algorithm, ok := loadBalanceAlgorithms[value]
+	if !ok {
+		return "", []notifications.Notification{notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, loadBalanceAnnotation, value)}
+	}
+	return algorithm, nil
+}
+
+// isVariable reports whether key is a single NGINX variable with the prefix.
+func isVariable(key, prefix string) bool {
+	name := strings.TrimPrefix(key, prefix)
+	if name == key || name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !(r == '_' || r == '-' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
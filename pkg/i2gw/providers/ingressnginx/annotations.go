@@ -0,0 +1,207 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package ingressnginx
+
+import "sort"
+
+// AnnotationStatus is how an ingress-nginx annotation is converted.
+type AnnotationStatus string
+
+const (
+	// AnnotationConverted annotations are converted to Gateway API fields
+	// with the same behavior.
+	AnnotationConverted AnnotationStatus = "converted"
+	// AnnotationApproximated annotations are converted to Gateway API
+	// fields that behave differently in some cases, which are reported.
+	AnnotationApproximated AnnotationStatus = "approximated"
+	// AnnotationPolicy annotations are converted to the policies of the
+	// target implementation, and reported without one.
+	AnnotationPolicy AnnotationStatus = "policy-emitted"
+	// AnnotationUnsupported annotations are reported as not converted.
+	AnnotationUnsupported AnnotationStatus = "unsupported"
+)
+
+// AnnotationStatuses returns the statuses of annotations.
+func AnnotationStatuses() []string {
+	return []string{string(AnnotationConverted), string(AnnotationApproximated), string(AnnotationPolicy), string(AnnotationUnsupported)}
+}
+
+// Annotation is a documented ingress-nginx annotation and how it is
+// converted.
+type Annotation struct {
+	Name   string           `json:"name"`
+	Status AnnotationStatus `json:"status"`
+	// Conversion describes what the annotation is converted to, or why it
+	// isn't.
+	Conversion string `json:"conversion,omitempty"`
+}
+
+// annotations are the documented ingress-nginx annotations. The provider
+// converts the ones that are not unsupported and reports the others.
+var annotations = []Annotation{
+	{Name: "affinity", Status: AnnotationApproximated, Conversion: "cookie session persistence of a BackendLBPolicy, with --experimental"},
+	{Name: "affinity-canary-behavior", Status: AnnotationUnsupported},
+	{Name: "affinity-mode", Status: AnnotationUnsupported, Conversion: "sessions are always persistent"},
+	{Name: "allowlist-source-range", Status: AnnotationPolicy, Conversion: "IP allow list"},
+	{Name: "app-root", Status: AnnotationUnsupported},
+	{Name: "auth-always-set-cookie", Status: AnnotationUnsupported},
+	{Name: "auth-cache-duration", Status: AnnotationUnsupported},
+	{Name: "auth-cache-key", Status: AnnotationUnsupported},
+	{Name: "auth-keepalive", Status: AnnotationUnsupported},
+	{Name: "auth-keepalive-requests", Status: AnnotationUnsupported},
+	{Name: "auth-keepalive-share-vars", Status: AnnotationUnsupported},
+	{Name: "auth-keepalive-timeout", Status: AnnotationUnsupported},
+	{Name: "auth-method", Status: AnnotationUnsupported},
+	{Name: "auth-proxy-set-headers", Status: AnnotationUnsupported},
+	{Name: "auth-realm", Status: AnnotationUnsupported},
+	{Name: "auth-request-redirect", Status: AnnotationUnsupported},
+	{Name: "auth-response-headers", Status: AnnotationPolicy, Conversion: "headers copied by external authentication"},
+	{Name: "auth-secret", Status: AnnotationUnsupported},
+	{Name: "auth-secret-type", Status: AnnotationUnsupported},
+	{Name: "auth-signin", Status: AnnotationPolicy, Conversion: "OAuth2 sign-in of oauth2-proxy, other sign-in URLs are reported"},
+	{Name: "auth-signin-redirect-param", Status: AnnotationUnsupported},
+	{Name: "auth-snippet", Status: AnnotationUnsupported},
+	{Name: "auth-tls-error-page", Status: AnnotationUnsupported},
+	{Name: "auth-tls-match-cn", Status: AnnotationUnsupported},
+	{Name: "auth-tls-pass-certificate-to-upstream", Status: AnnotationUnsupported},
+	{Name: "auth-tls-secret", Status: AnnotationApproximated, Conversion: "frontend TLS validation of the listener, with --experimental"},
+	{Name: "auth-tls-verify-client", Status: AnnotationApproximated, Conversion: "frontend TLS validation of the listener, with --experimental"},
+	{Name: "auth-tls-verify-depth", Status: AnnotationApproximated, Conversion: "only the default depth of the implementation"},
+	{Name: "auth-type", Status: AnnotationUnsupported},
+	{Name: "auth-url", Status: AnnotationPolicy, Conversion: "external authentication"},
+	{Name: "backend-protocol", Status: AnnotationConverted, Conversion: "BackendTLSPolicy for HTTPS, with --experimental, and the kubernetes.io/h2c appProtocol the Service ports of GRPC backends need"},
+	{Name: "canary", Status: AnnotationConverted, Conversion: "weighted and matched backends of the primary HTTPRoute"},
+	{Name: "canary-by-cookie", Status: AnnotationApproximated, Conversion: "regular expression match of the Cookie header"},
+	{Name: "canary-by-header", Status: AnnotationConverted, Conversion: "header match"},
+	{Name: "canary-by-header-pattern", Status: AnnotationConverted, Conversion: "regular expression header match"},
+	{Name: "canary-by-header-value", Status: AnnotationConverted, Conversion: "header match"},
+	{Name: "canary-weight", Status: AnnotationConverted, Conversion: "backend weights"},
+	{Name: "canary-weight-total", Status: AnnotationConverted, Conversion: "backend weights"},
+	{Name: "client-body-buffer-size", Status: AnnotationUnsupported},
+	{Name: "configuration-snippet", Status: AnnotationPolicy, Conversion: "SnippetsFilters, and the compression and GeoIP directives it contains"},
+	{Name: "connection-proxy-header", Status: AnnotationUnsupported},
+	{Name: "cors-allow-credentials", Status: AnnotationUnsupported},
+	{Name: "cors-allow-headers", Status: AnnotationUnsupported},
+	{Name: "cors-allow-methods", Status: AnnotationUnsupported},
+	{Name: "cors-allow-origin", Status: AnnotationUnsupported},
+	{Name: "cors-expose-headers", Status: AnnotationUnsupported},
+	{Name: "cors-max-age", Status: AnnotationUnsupported},
+	{Name: "custom-headers", Status: AnnotationUnsupported},
+	{Name: "custom-http-errors", Status: AnnotationPolicy, Conversion: "error pages, reported without a target implementation"},
+	{Name: "default-backend", Status: AnnotationPolicy, Conversion: "error pages, reported without a target implementation"},
+	{Name: "denylist-source-range", Status: AnnotationPolicy, Conversion: "IP deny list"},
+	{Name: "disable-proxy-intercept-errors", Status: AnnotationUnsupported},
+	{Name: "enable-access-log", Status: AnnotationPolicy, Conversion: "access logging"},
+	{Name: "enable-cors", Status: AnnotationUnsupported},
+	{Name: "enable-global-auth", Status: AnnotationUnsupported},
+	{Name: "enable-modsecurity", Status: AnnotationPolicy, Conversion: "web application firewall"},
+	{Name: "enable-opentelemetry", Status: AnnotationPolicy, Conversion: "tracing"},
+	{Name: "enable-opentracing", Status: AnnotationPolicy, Conversion: "tracing"},
+	{Name: "enable-owasp-core-rules", Status: AnnotationPolicy, Conversion: "web application firewall"},
+	{Name: "enable-rewrite-log", Status: AnnotationUnsupported},
+	{Name: "force-ssl-redirect", Status: AnnotationConverted, Conversion: "HTTPS redirect HTTPRoute"},
+	{Name: "from-to-www-redirect", Status: AnnotationUnsupported},
+	{Name: "limit-burst-multiplier", Status: AnnotationUnsupported},
+	{Name: "limit-connections", Status: AnnotationUnsupported},
+	{Name: "limit-rate", Status: AnnotationUnsupported},
+	{Name: "limit-rate-after", Status: AnnotationUnsupported},
+	{Name: "limit-rpm", Status: AnnotationPolicy, Conversion: "rate limit"},
+	{Name: "limit-rps", Status: AnnotationPolicy, Conversion: "rate limit"},
+	{Name: "limit-whitelist", Status: AnnotationUnsupported},
+	{Name: "load-balance", Status: AnnotationPolicy, Conversion: "load balancing algorithm"},
+	{Name: "mirror-host", Status: AnnotationUnsupported},
+	{Name: "mirror-request-body", Status: AnnotationUnsupported},
+	{Name: "mirror-target", Status: AnnotationUnsupported},
+	{Name: "modsecurity-snippet", Status: AnnotationPolicy, Conversion: "web application firewall"},
+	{Name: "modsecurity-transaction-id", Status: AnnotationUnsupported},
+	{Name: "opentelemetry-trust-incoming-span", Status: AnnotationUnsupported},
+	{Name: "opentracing-trust-incoming-span", Status: AnnotationUnsupported},
+	{Name: "permanent-redirect", Status: AnnotationUnsupported},
+	{Name: "permanent-redirect-code", Status: AnnotationUnsupported},
+	{Name: "preserve-trailing-slash", Status: AnnotationUnsupported},
+	{Name: "proxy-body-size", Status: AnnotationPolicy, Conversion: "request body size limit"},
+	{Name: "proxy-buffer-size", Status: AnnotationPolicy, Conversion: "response buffering"},
+	{Name: "proxy-buffering", Status: AnnotationPolicy, Conversion: "response buffering"},
+	{Name: "proxy-buffers-number", Status: AnnotationPolicy, Conversion: "response buffering"},
+	{Name: "proxy-connect-timeout", Status: AnnotationPolicy, Conversion: "connect timeout"},
+	{Name: "proxy-cookie-domain", Status: AnnotationUnsupported},
+	{Name: "proxy-cookie-path", Status: AnnotationUnsupported},
+	{Name: "proxy-http-version", Status: AnnotationApproximated, Conversion: "HTTP/1.1, the version Gateway API implementations use, HTTP/1.0 is reported"},
+	{Name: "proxy-max-temp-file-size", Status: AnnotationUnsupported},
+	{Name: "proxy-next-upstream", Status: AnnotationApproximated, Conversion: "HTTPRoute retries, with --experimental, or a retry policy"},
+	{Name: "proxy-next-upstream-timeout", Status: AnnotationApproximated, Conversion: "HTTPRoute retries, with --experimental, or a retry policy"},
+	{Name: "proxy-next-upstream-tries", Status: AnnotationApproximated, Conversion: "HTTPRoute retries, with --experimental, or a retry policy"},
+	{Name: "proxy-read-timeout", Status: AnnotationApproximated, Conversion: "HTTPRoute request and backend request timeouts"},
+	{Name: "proxy-redirect-from", Status: AnnotationUnsupported},
+	{Name: "proxy-redirect-to", Status: AnnotationUnsupported},
+	{Name: "proxy-request-buffering", Status: AnnotationUnsupported},
+	{Name: "proxy-send-timeout", Status: AnnotationApproximated, Conversion: "HTTPRoute request and backend request timeouts"},
+	{Name: "proxy-ssl-ciphers", Status: AnnotationUnsupported},
+	{Name: "proxy-ssl-name", Status: AnnotationConverted, Conversion: "hostname of the BackendTLSPolicy"},
+	{Name: "proxy-ssl-protocols", Status: AnnotationUnsupported},
+	{Name: "proxy-ssl-secret", Status: AnnotationConverted, Conversion: "CA certificates of the BackendTLSPolicy"},
+	{Name: "proxy-ssl-server-name", Status: AnnotationApproximated, Conversion: "the server name is always sent"},
+	{Name: "proxy-ssl-verify", Status: AnnotationConverted, Conversion: "BackendTLSPolicy"},
+	{Name: "proxy-ssl-verify-depth", Status: AnnotationApproximated, Conversion: "only the default depth of the implementation"},
+	{Name: "rewrite-target", Status: AnnotationApproximated, Conversion: "URLRewrite filter, for the targets a Gateway API rewrite can express"},
+	{Name: "satisfy", Status: AnnotationUnsupported},
+	{Name: "server-alias", Status: AnnotationUnsupported},
+	{Name: "server-snippet", Status: AnnotationPolicy, Conversion: "SnippetsFilters, and the compression and GeoIP directives it contains"},
+	{Name: "service-upstream", Status: AnnotationUnsupported},
+	{Name: "session-cookie-change-on-failure", Status: AnnotationUnsupported},
+	{Name: "session-cookie-conditional-samesite-none", Status: AnnotationUnsupported},
+	{Name: "session-cookie-domain", Status: AnnotationUnsupported},
+	{Name: "session-cookie-expires", Status: AnnotationUnsupported},
+	{Name: "session-cookie-max-age", Status: AnnotationConverted, Conversion: "absolute timeout of the session persistence"},
+	{Name: "session-cookie-name", Status: AnnotationConverted, Conversion: "session name of the session persistence"},
+	{Name: "session-cookie-path", Status: AnnotationApproximated, Conversion: "the cookie path is set by the implementation"},
+	{Name: "session-cookie-samesite", Status: AnnotationUnsupported},
+	{Name: "session-cookie-secure", Status: AnnotationUnsupported},
+	{Name: "ssl-ciphers", Status: AnnotationApproximated, Conversion: "TLS options of the listener, whose support is implementation-specific"},
+	{Name: "ssl-passthrough", Status: AnnotationConverted, Conversion: "TLS listener in Passthrough mode and TLSRoute to the backend of the \"/\" path, with --experimental"},
+	{Name: "ssl-prefer-server-ciphers", Status: AnnotationApproximated, Conversion: "TLS options of the listener, whose support is implementation-specific"},
+	{Name: "ssl-redirect", Status: AnnotationConverted, Conversion: "HTTPS redirect HTTPRoute"},
+	{Name: "stream-snippet", Status: AnnotationUnsupported},
+	{Name: "temporal-redirect", Status: AnnotationUnsupported},
+	{Name: "upstream-hash-by", Status: AnnotationApproximated, Conversion: "header or cookie session persistence, or a consistent hashing policy"},
+	{Name: "upstream-vhost", Status: AnnotationConverted, Conversion: "hostname of a URLRewrite filter, for hostnames without nginx variables"},
+	{Name: "use-regex", Status: AnnotationApproximated, Conversion: "case-insensitive RegularExpression path matches, whose support is implementation-specific, for the expressions RE2 can express"},
+	{Name: "whitelist-source-range", Status: AnnotationPolicy, Conversion: "IP allow list"},
+	{Name: "x-forwarded-prefix", Status: AnnotationConverted, Conversion: "RequestHeaderModifier filter setting X-Forwarded-Prefix on every rule of the Ingress"},
+}
+
+// Annotations returns the documented ingress-nginx annotations, with
+// their prefix, sorted by name.
+func Annotations() []Annotation {
+	result := make([]Annotation, 0, len(annotations))
+	for _, a := range annotations {
+		a.Name = annotationPrefix + a.Name
+		result = append(result, a)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// supportedAnnotations are the ingress-nginx annotations that are converted.
+var supportedAnnotations = func() map[string]struct{} {
+	supported := map[string]struct{}{}
+	for _, a := range annotations {
+		if a.Status != AnnotationUnsupported {
+			supported[annotationPrefix+a.Name] = struct{}{}
+		}
+	}
+	return supported
+}()
@@ -0,0 +1,63 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package ingressnginx
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_annotations(t *testing.T) {
+	names := map[string]bool{}
+	for _, a := range annotations {
+		if names[a.Name] {
+			t.Errorf("Annotation %s is listed twice", a.Name)
+		}
+		names[a.Name] = true
+		if strings.HasPrefix(a.Name, annotationPrefix) {
+			t.Errorf("Annotation %s is listed with its prefix", a.Name)
+		}
+		if !slices.Contains(AnnotationStatuses(), string(a.Status)) {
+			t.Errorf("Annotation %s has an unknown status %q", a.Name, a.Status)
+		}
+		if a.Status != AnnotationUnsupported && a.Conversion == "" {
+			t.Errorf("Annotation %s is %s without describing its conversion", a.Name, a.Status)
+		}
+	}
+}
+
+func Test_unsupportedAnnotations(t *testing.T) {
+	ingress := networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{}}}
+	var expectConverted, expectUnsupported []string
+	for _, a := range Annotations() {
+		ingress.Annotations[a.Name] = "true"
+		if a.Status == AnnotationUnsupported {
+			expectUnsupported = append(expectUnsupported, a.Name)
+		} else {
+			expectConverted = append(expectConverted, a.Name)
+		}
+	}
+	if got := unsupportedAnnotations(ingress); !slices.Equal(expectUnsupported, got) {
+		t.Errorf("Expected unsupported annotations %v, got %v", expectUnsupported, got)
+	}
+	if got := convertedAnnotations(ingress); !slices.Equal(expectConverted, got) {
+		t.Errorf("Expected converted annotations %v, got %v", expectConverted, got)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	backendProtocolAnnotation     = annotationPrefix + "backend-protocol"
+	proxyHTTPVersionAnnotation    = annotationPrefix + "proxy-http-version"
+	proxySSLSecretAnnotation      = annotationPrefix + "proxy-ssl-secret"
+	proxySSLVerifyAnnotation      = annotationPrefix + "proxy-ssl-verify"
+	proxySSLVerifyDepthAnnotation = annotationPrefix + "proxy-ssl-verify-depth"
+	proxySSLNameAnnotation        = annotationPrefix + "proxy-ssl-name"
+	proxySSLServerNameAnnotation  = annotationPrefix + "proxy-ssl-server-name"
+)
+
+// parseBackendProtocol converts backend-protocol and proxy-http-version and
+// reports the backend protocols that Gateway API can't proxy requests with.
+// HTTP backends are routed as is, gRPC ones over the HTTP/2 connections their
+// Service ports are marked for, and HTTPS ones with parseBackendTLS.
+func parseBackendProtocol(ingress networkingv1.Ingress) (*ir.BackendProtocol, []notifications.Notification) {
+	var protocol *ir.BackendProtocol
+	var notes []notifications.Notification
+	if value, ok := ingress.Annotations[backendProtocolAnnotation]; ok {
+		switch name := strings.ToUpper(strings.TrimSpace(value)); name {
+		case "HTTP", "HTTPS", "AUTO_HTTP":
+		case "GRPC":
+			protocol = &ir.BackendProtocol{Name: name, AppProtocol: ir.AppProtocolH2C}
+		case "GRPCS":
+			protocol = &ir.BackendProtocol{Name: name}
+			notes = append(notes, notifications.NewInfo("Ingress %s/%s connects to its gRPC backends with TLS, Gateway API implementations only use HTTP/2 for them if they negotiate it with the backends", ingress.Namespace, ingress.Name))
+		case "FCGI", "AJP":
+			notes = append(notes, notifications.NewBlocking("Ingress %s/%s proxies requests to its backends with %s, which Gateway API doesn't support, its backends must serve HTTP before they can be migrated", ingress.Namespace, ingress.Name, name))
+		default:
+			notes = append(notes, notifications.NewBlocking("Ingress %s/%s proxies requests to its backends with unknown protocol %q, its backends must serve HTTP before they can be migrated", ingress.Namespace, ingress.Name, value))
+		}
+	}
+
+	if version, ok := ingress.Annotations[proxyHTTPVersionAnnotation]; ok {
+		switch strings.TrimSpace(version) {
+		case "1.1":
+		case "1.0":
+			if protocol == nil {
+				notes = append(notes, notifications.NewWarning("Ingress %s/%s proxies requests to its backends with HTTP/1.0, Gateway API implementations use HTTP/1.1, which also lets WebSocket connections through", ingress.Namespace, ingress.Name))
+			}
+		default:
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, proxyHTTPVersionAnnotation, version))
+		}
+		if protocol != nil {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s sets %s along with backend-protocol %s, which ingress-nginx ignores for gRPC backends, so it is not converted", ingress.Namespace, ingress.Name, proxyHTTPVersionAnnotation, protocol.Name))
+		}
+	}
+	return protocol, notes
+}
+
+// parseBackendTLS converts the proxy-ssl annotations. ingress-nginx only
+// verifies the certificates of backends with proxy-ssl-verify, while Gateway
+// API always does once it connects to a backend with TLS.
+func parseBackendTLS(ingress networkingv1.Ingress) (*ir.BackendTLS, []notifications.Notification) {
+	var notes []notifications.Notification
+	protocol := strings.ToUpper(ingress.Annotations[backendProtocolAnnotation])
+	secret, hasSecret := ingress.Annotations[proxySSLSecretAnnotation]
+	verify := ingress.Annotations[proxySSLVerifyAnnotation] == "on"
+
+	if !hasSecret || !verify {
+		if protocol == "HTTPS" || protocol == "GRPCS" || hasSecret {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s connects to its backends with TLS without verifying their certificates, which Gateway API can't express", ingress.Namespace, ingress.Name))
+		}
+		return nil, notes
+	}
+
+	namespace, name, found := strings.Cut(secret, "/")
+	if !found {
+		namespace, name = ingress.Namespace, secret
+	}
+	if namespace == "" || name == "" {
+		notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, proxySSLSecretAnnotation, secret))
+		return nil, notes
+	}
+	if protocol != "HTTPS" && protocol != "GRPCS" {
+		notes = append(notes, notifications.NewWarning("Ingress %s/%s verifies the certificates of its backends without connecting to them with TLS, set the %s annotation to HTTPS", ingress.Namespace, ingress.Name, backendProtocolAnnotation))
+	}
+	if depth, ok := ingress.Annotations[proxySSLVerifyDepthAnnotation]; ok && depth != "1" {
+		notes = append(notes, notifications.NewWarning("Ingress %s/%s verifies backend certificate chains up to a depth of %s, which can't be converted", ingress.Namespace, ingress.Name, depth))
+	}
+	if value, ok := ingress.Annotations[proxySSLServerNameAnnotation]; ok && value != "on" {
+		notes = append(notes, notifications.NewInfo("Ingress %s/%s doesn't send the server name to its backends, Gateway API always does", ingress.Namespace, ingress.Name))
+	}
+	return &ir.BackendTLS{
+		CACertificates: types.NamespacedName{Namespace: namespace, Name: name},
+		Hostname:       strings.TrimSpace(ingress.Annotations[proxySSLNameAnnotation]),
+	}, notes
+}
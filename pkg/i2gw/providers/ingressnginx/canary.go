@@ -0,0 +1,106 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	canaryAnnotation                = annotationPrefix + "canary"
+	canaryByHeaderAnnotation        = annotationPrefix + "canary-by-header"
+	canaryByHeaderValueAnnotation   = annotationPrefix + "canary-by-header-value"
+	canaryByHeaderPatternAnnotation = annotationPrefix + "canary-by-header-pattern"
+	canaryByCookieAnnotation        = annotationPrefix + "canary-by-cookie"
+	canaryWeightAnnotation          = annotationPrefix + "canary-weight"
+	canaryWeightTotalAnnotation     = annotationPrefix + "canary-weight-total"
+
+	defaultCanaryWeightTotal = 100
+)
+
+func parseCanary(ingress networkingv1.Ingress) (*ir.Canary, []notifications.Notification) {
+	if ingress.Annotations[canaryAnnotation] != "true" {
+		return nil, nil
+	}
+	var notes []notifications.Notification
+
+	c := &ir.Canary{}
+	hmExact := gatewayv1.HeaderMatchExact
+	hmRegex := gatewayv1.HeaderMatchRegularExpression
+
+	// ingress-nginx routes a request to the canary by header, then by
+	// cookie, then by weight: requests the header or cookie don't decide on
+	// fall through to the next condition.
+	if header := ingress.Annotations[canaryByHeaderAnnotation]; header != "" {
+		name := gatewayv1.HTTPHeaderName(header)
+		if value := ingress.Annotations[canaryByHeaderValueAnnotation]; value != "" {
+			c.Matches = append(c.Matches, ir.CanaryMatch{Header: gatewayv1.HTTPHeaderMatch{Name: name, Value: value, Type: &hmExact}})
+		} else if pattern := ingress.Annotations[canaryByHeaderPatternAnnotation]; pattern != "" {
+			c.Matches = append(c.Matches, ir.CanaryMatch{Header: gatewayv1.HTTPHeaderMatch{Name: name, Value: pattern, Type: &hmRegex}})
+		} else {
+			c.Matches = append(c.Matches,
+				ir.CanaryMatch{Header: gatewayv1.HTTPHeaderMatch{Name: name, Value: "always", Type: &hmExact}},
+				ir.CanaryMatch{Header: gatewayv1.HTTPHeaderMatch{Name: name, Value: "never", Type: &hmExact}, Primary: true},
+			)
+		}
+	}
+	if cookie := ingress.Annotations[canaryByCookieAnnotation]; cookie != "" {
+		// Gateway API can't match cookies, so the Cookie header is matched
+		// with a regular expression instead.
+		c.Matches = append(c.Matches,
+			ir.CanaryMatch{Header: gatewayv1.HTTPHeaderMatch{Name: "Cookie", Value: cookieValueRegex(cookie, "always"), Type: &hmRegex}},
+			ir.CanaryMatch{Header: gatewayv1.HTTPHeaderMatch{Name: "Cookie", Value: cookieValueRegex(cookie, "never"), Type: &hmRegex}, Primary: true},
+		)
+		notes = append(notes, notifications.NewInfo("Ingress %s/%s routes by the %s cookie, which is converted to a regular expression match of the Cookie header whose support is implementation-specific", ingress.Namespace, ingress.Name, cookie))
+	}
+	if weight := ingress.Annotations[canaryWeightAnnotation]; weight != "" {
+		w, err := strconv.Atoi(weight)
+		switch {
+		case err != nil:
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, canaryWeightAnnotation, weight))
+		case w < 0:
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s has a negative %s annotation %q, routing no requests to the canary by weight", ingress.Namespace, ingress.Name, canaryWeightAnnotation, weight))
+			c.Weighted = true
+		default:
+			c.Weight = w
+			c.Weighted = true
+		}
+		c.WeightTotal = defaultCanaryWeightTotal
+	}
+	if weightTotal := ingress.Annotations[canaryWeightTotalAnnotation]; weightTotal != "" {
+		wt, err := strconv.Atoi(weightTotal)
+		if err != nil || wt < 0 {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, canaryWeightTotalAnnotation, weightTotal))
+		} else {
+			c.WeightTotal = wt
+		}
+	}
+	return c, notes
+}
+
+// cookieValueRegex returns a regular expression matching Cookie headers
+// setting the cookie name to value.
+func cookieValueRegex(name, value string) string {
+	return fmt.Sprintf(`^(.*;\s*)?%s=%s(;.*)?$`, regexp.QuoteMeta(name), regexp.QuoteMeta(value))
+}
@@ -0,0 +1,150 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_parseCanary(t *testing.T) {
+	hmExact := gatewayv1.HeaderMatchExact
+	hmRegex := gatewayv1.HeaderMatchRegularExpression
+
+	testCases := []struct {
+		name          string
+		annotations   map[string]string
+		expectCanary  *ir.Canary
+		expectNotices int
+	}{{
+		name:        "not a canary",
+		annotations: map[string]string{canaryWeightAnnotation: "20"},
+	}, {
+		name: "weight with default total",
+		annotations: map[string]string{
+			canaryAnnotation:       "true",
+			canaryWeightAnnotation: "20",
+		},
+		expectCanary: &ir.Canary{Weight: 20, WeightTotal: 100, Weighted: true},
+	}, {
+		name: "weight with custom total",
+		annotations: map[string]string{
+			canaryAnnotation:            "true",
+			canaryWeightAnnotation:      "20",
+			canaryWeightTotalAnnotation: "1000",
+		},
+		expectCanary: &ir.Canary{Weight: 20, WeightTotal: 1000, Weighted: true},
+	}, {
+		name: "zero weight",
+		annotations: map[string]string{
+			canaryAnnotation:       "true",
+			canaryWeightAnnotation: "0",
+		},
+		expectCanary: &ir.Canary{WeightTotal: 100, Weighted: true},
+	}, {
+		name: "header with default value",
+		annotations: map[string]string{
+			canaryAnnotation:         "true",
+			canaryByHeaderAnnotation: "X-Canary",
+		},
+		expectCanary: &ir.Canary{Matches: []ir.CanaryMatch{
+			{Header: gatewayv1.HTTPHeaderMatch{Name: "X-Canary", Value: "always", Type: &hmExact}},
+			{Header: gatewayv1.HTTPHeaderMatch{Name: "X-Canary", Value: "never", Type: &hmExact}, Primary: true},
+		}},
+	}, {
+		name: "header with value",
+		annotations: map[string]string{
+			canaryAnnotation:              "true",
+			canaryByHeaderAnnotation:      "X-Canary",
+			canaryByHeaderValueAnnotation: "yes",
+		},
+		expectCanary: &ir.Canary{Matches: []ir.CanaryMatch{{Header: gatewayv1.HTTPHeaderMatch{Name: "X-Canary", Value: "yes", Type: &hmExact}}}},
+	}, {
+		name: "header with pattern",
+		annotations: map[string]string{
+			canaryAnnotation:                "true",
+			canaryByHeaderAnnotation:        "X-Canary",
+			canaryByHeaderPatternAnnotation: "^y.*",
+		},
+		expectCanary: &ir.Canary{Matches: []ir.CanaryMatch{{Header: gatewayv1.HTTPHeaderMatch{Name: "X-Canary", Value: "^y.*", Type: &hmRegex}}}},
+	}, {
+		name: "header, cookie and weight",
+		annotations: map[string]string{
+			canaryAnnotation:              "true",
+			canaryByHeaderAnnotation:      "X-Canary",
+			canaryByHeaderValueAnnotation: "yes",
+			canaryByCookieAnnotation:      "canary",
+			canaryWeightAnnotation:        "20",
+		},
+		expectCanary: &ir.Canary{
+			Matches: []ir.CanaryMatch{
+				{Header: gatewayv1.HTTPHeaderMatch{Name: "X-Canary", Value: "yes", Type: &hmExact}},
+				{Header: gatewayv1.HTTPHeaderMatch{Name: "Cookie", Value: `^(.*;\s*)?canary=always(;.*)?$`, Type: &hmRegex}},
+				{Header: gatewayv1.HTTPHeaderMatch{Name: "Cookie", Value: `^(.*;\s*)?canary=never(;.*)?$`, Type: &hmRegex}, Primary: true},
+			},
+			Weight:      20,
+			WeightTotal: 100,
+			Weighted:    true,
+		},
+		expectNotices: 1,
+	}, {
+		name: "invalid weight",
+		annotations: map[string]string{
+			canaryAnnotation:       "true",
+			canaryWeightAnnotation: "twenty",
+		},
+		expectCanary:  &ir.Canary{WeightTotal: 100},
+		expectNotices: 1,
+	}, {
+		name: "negative weight",
+		annotations: map[string]string{
+			canaryAnnotation:       "true",
+			canaryWeightAnnotation: "-5",
+		},
+		expectCanary:  &ir.Canary{WeightTotal: 100, Weighted: true},
+		expectNotices: 1,
+	}, {
+		name: "negative total",
+		annotations: map[string]string{
+			canaryAnnotation:            "true",
+			canaryWeightAnnotation:      "20",
+			canaryWeightTotalAnnotation: "-100",
+		},
+		expectCanary:  &ir.Canary{Weight: 20, WeightTotal: 100, Weighted: true},
+		expectNotices: 1,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ingress := networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: "canary", Namespace: "test", Annotations: tc.annotations},
+			}
+			canary, notes := parseCanary(ingress)
+			if diff := cmp.Diff(tc.expectCanary, canary); diff != "" {
+				t.Errorf("Unexpected canary, diff (-want +got): %s", diff)
+			}
+			if len(notes) != tc.expectNotices {
+				t.Errorf("Expected %d notifications, got %d: %v", tc.expectNotices, len(notes), notes)
+			}
+		})
+	}
+}
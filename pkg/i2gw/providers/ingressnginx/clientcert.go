@@ -0,0 +1,72 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	authTLSSecretAnnotation       = annotationPrefix + "auth-tls-secret"
+	authTLSVerifyClientAnnotation = annotationPrefix + "auth-tls-verify-client"
+	authTLSVerifyDepthAnnotation  = annotationPrefix + "auth-tls-verify-depth"
+)
+
+// parseClientValidation converts the auth-tls annotations. Client
+// certificates are verified by default once a CA Secret is set.
+func parseClientValidation(ingress networkingv1.Ingress) (*ir.ClientValidation, []notifications.Notification) {
+	var notes []notifications.Notification
+	secret, ok := ingress.Annotations[authTLSSecretAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	validation := &ir.ClientValidation{}
+	namespace, name, found := strings.Cut(secret, "/")
+	if !found {
+		namespace, name = ingress.Namespace, secret
+	}
+	if namespace == "" || name == "" {
+		notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, authTLSSecretAnnotation, secret))
+		return nil, notes
+	}
+	validation.CACertificates = types.NamespacedName{Namespace: namespace, Name: name}
+
+	switch value := ingress.Annotations[authTLSVerifyClientAnnotation]; value {
+	case "", "on":
+	case "off":
+		return nil, nil
+	case "optional", "optional_no_ca":
+		validation.Optional = true
+	default:
+		notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, authTLSVerifyClientAnnotation, value))
+	}
+
+	if depth, ok := ingress.Annotations[authTLSVerifyDepthAnnotation]; ok && depth != "1" {
+		notes = append(notes, notifications.NewWarning("Ingress %s/%s verifies client certificate chains up to a depth of %s, which can't be converted", ingress.Namespace, ingress.Name, depth))
+	}
+	if len(ingress.Spec.TLS) == 0 {
+		notes = append(notes, notifications.NewWarning("Ingress %s/%s configures client certificate authentication without TLS, ignoring it", ingress.Namespace, ingress.Name))
+		return nil, notes
+	}
+	return validation, notes
+}
@@ -0,0 +1,50 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"regexp"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+const (
+	// Keys of the ConfigMap of the controller.
+	useGzipKey      = "use-gzip"
+	enableBrotliKey = "enable-brotli"
+)
+
+var (
+	gzipDirectiveRegexp   = regexp.MustCompile(`(?m)^\s*gzip\s+on\s*;`)
+	brotliDirectiveRegexp = regexp.MustCompile(`(?m)^\s*brotli\s+on\s*;`)
+)
+
+// parseCompression returns the compression algorithms of the responses to
+// the Ingress. ingress-nginx has no compression annotations, so they are
+// enabled by the ConfigMap of the controller or by snippets.
+func parseCompression(ingress networkingv1.Ingress, config controllerConfig) []ir.CompressionAlgorithm {
+	snippets := ingress.Annotations[serverSnippetAnnotation] + "\n" + ingress.Annotations[configurationSnippetAnnotation]
+	var algorithms []ir.CompressionAlgorithm
+	if config.gzip || gzipDirectiveRegexp.MatchString(snippets) {
+		algorithms = append(algorithms, ir.CompressionGzip)
+	}
+	if config.brotli || brotliDirectiveRegexp.MatchString(snippets) {
+		algorithms = append(algorithms, ir.CompressionBrotli)
+	}
+	return algorithms
+}
@@ -0,0 +1,67 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+const (
+	customHTTPErrorsAnnotation = annotationPrefix + "custom-http-errors"
+	defaultBackendAnnotation   = annotationPrefix + "default-backend"
+)
+
+// parseCustomErrors converts the default-backend annotation to a catch-all
+// route and reports the error handling of ingress-nginx, which intercepts
+// the error responses of backends and falls back to the default backend
+// when a Service has no endpoints. Gateway API can express neither.
+func parseCustomErrors(ingress networkingv1.Ingress) (*ir.CustomErrors, []notifications.Notification) {
+	var notes []notifications.Notification
+	customErrors := &ir.CustomErrors{}
+	for _, value := range splitList(ingress.Annotations[customHTTPErrorsAnnotation], ",") {
+		code, err := strconv.Atoi(value)
+		if err != nil || code < 400 || code > 599 {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, customHTTPErrorsAnnotation, value))
+			continue
+		}
+		customErrors.Codes = append(customErrors.Codes, code)
+	}
+	if service := strings.TrimSpace(ingress.Annotations[defaultBackendAnnotation]); service != "" {
+		customErrors.Backend = &networkingv1.IngressServiceBackend{Name: service}
+	}
+
+	var codes []string
+	for _, code := range customErrors.Codes {
+		codes = append(codes, strconv.Itoa(code))
+	}
+	switch {
+	case len(codes) > 0 && customErrors.Backend != nil:
+		notes = append(notes, notifications.NewWarning("Ingress %s/%s replaces the responses with HTTP status %s by the error pages of Service %s, which must be re-implemented with the error handling of the Gateway implementation", ingress.Namespace, ingress.Name, strings.Join(codes, ", "), customErrors.Backend.Name))
+	case len(codes) > 0:
+		notes = append(notes, notifications.NewWarning("Ingress %s/%s replaces the responses with HTTP status %s by the error pages of the default backend of the controller, which must be re-implemented with the error handling of the Gateway implementation", ingress.Namespace, ingress.Name, strings.Join(codes, ", ")))
+	case customErrors.Backend != nil:
+		notes = append(notes, notifications.NewWarning("Ingress %s/%s falls back to Service %s when its backends have no endpoints, which must be re-implemented with the error handling of the Gateway implementation", ingress.Namespace, ingress.Name, customErrors.Backend.Name))
+	default:
+		return nil, notes
+	}
+	return customErrors, notes
+}
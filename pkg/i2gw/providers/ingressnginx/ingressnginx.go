@@ -0,0 +1,157 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ingressnginx converts the annotations of Ingresses served by
+// ingress-nginx.
+package ingressnginx
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+)
+
+const (
+	// Name is the name of the provider.
+	Name = "ingress-nginx"
+	// Controller is the IngressClass spec.controller of ingress-nginx.
+	Controller = "k8s.io/ingress-nginx"
+
+	annotationPrefix = "nginx.ingress.kubernetes.io/"
+)
+
+// Provider extracts features from ingress-nginx annotations and the
+// ConfigMap of the controller.
+type Provider struct {
+	config controllerConfig
+	// classes are the IngressClasses of the controller, the controller-wide
+	// settings only applying to their Ingresses, and to the Ingresses
+	// without a class if one of them is the default class.
+	classes      map[string]bool
+	defaultClass bool
+}
+
+// defaultIngressClass is the IngressClass of the Helm chart and of the
+// static manifests of ingress-nginx.
+const defaultIngressClass = "nginx"
+
+// NewProvider returns the ingress-nginx provider.
+func NewProvider() *Provider {
+	return &Provider{
+		config:  defaultControllerConfig(),
+		classes: map[string]bool{defaultIngressClass: true},
+	}
+}
+
+// ReadIngressClasses records the IngressClasses of the controller.
+func (p *Provider) ReadIngressClasses(ingressClasses []networkingv1.IngressClass) {
+	for _, ic := range ingressClasses {
+		p.classes[ic.Name] = true
+		if ic.Annotations[networkingv1.AnnotationIsDefaultIngressClass] == "true" {
+			p.defaultClass = true
+		}
+	}
+}
+
+// controllerConfig returns the controller-wide settings that apply to the
+// Ingress. They don't apply to the Ingresses that may be of another
+// controller, which the provider is tried on when their IngressClass is not
+// part of the input, whether or not the ConfigMap of the controller is.
+func (p *Provider) controllerConfig(ingress networkingv1.Ingress) controllerConfig {
+	class := ingress.Annotations[networkingv1beta1.AnnotationIngressClass]
+	if ingress.Spec.IngressClassName != nil && *ingress.Spec.IngressClassName != "" {
+		class = *ingress.Spec.IngressClassName
+	}
+	if class == "" && !p.defaultClass || class != "" && !p.classes[class] {
+		return controllerConfig{}
+	}
+	return p.config
+}
+
+func (p *Provider) Name() string {
+	return Name
+}
+
+func (p *Provider) Controller() string {
+	return Controller
+}
+
+func (p *Provider) ParseIngress(ingress networkingv1.Ingress) (ir.IngressFeatures, []notifications.Notification) {
+	var features ir.IngressFeatures
+	var notes []notifications.Notification
+
+	canary, canaryNotes := parseCanary(ingress)
+	features.Canary = canary
+	notes = append(notes, canaryNotes...)
+
+	policy, policyNotes := parsePolicy(ingress, p.controllerConfig(ingress))
+	features.Policy = policy
+	notes = append(notes, policyNotes...)
+
+	rewrites, rewriteNotes := parseRewrites(ingress)
+	features.Rewrites = rewrites
+	notes = append(notes, rewriteNotes...)
+
+	features.RegexPaths = parseRegexPaths(ingress)
+
+	hostHeader, hostHeaderNotes := parseHostHeader(ingress)
+	features.HostHeader = hostHeader
+	notes = append(notes, hostHeaderNotes...)
+
+	headers, headerNotes := parseHeaders(ingress)
+	features.Headers = headers
+	notes = append(notes, headerNotes...)
+
+	features.Stream = parseStream(ingress)
+
+	backendProtocol, backendProtocolNotes := parseBackendProtocol(ingress)
+	features.BackendProtocol = backendProtocol
+	notes = append(notes, backendProtocolNotes...)
+
+	features.UnsupportedAnnotations = unsupportedAnnotations(ingress)
+	features.ConvertedAnnotations = convertedAnnotations(ingress)
+
+	return features, notes
+}
+
+func convertedAnnotations(ingress networkingv1.Ingress) []string {
+	var converted []string
+	for annotation := range ingress.Annotations {
+		if _, ok := supportedAnnotations[annotation]; ok {
+			converted = append(converted, annotation)
+		}
+	}
+	sort.Strings(converted)
+	return converted
+}
+
+func unsupportedAnnotations(ingress networkingv1.Ingress) []string {
+	var unsupported []string
+	for annotation := range ingress.Annotations {
+		if !strings.HasPrefix(annotation, annotationPrefix) {
+			continue
+		}
+		if _, ok := supportedAnnotations[annotation]; !ok {
+			unsupported = append(unsupported, annotation)
+		}
+	}
+	sort.Strings(unsupported)
+	return unsupported
+}
@@ -0,0 +1,88 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+const authSigninAnnotation = annotationPrefix + "auth-signin"
+
+// oauth2ProxySignInPaths are the oauth2-proxy endpoints starting the
+// authorization flow.
+var oauth2ProxySignInPaths = []string{"/oauth2/start", "/oauth2/sign_in"}
+
+// parseOAuth2Proxy recognizes the oauth2-proxy setup documented by
+// ingress-nginx, where auth-url points to the /oauth2/auth endpoint of the
+// proxy and auth-signin to the endpoint starting the authorization flow,
+// and returns the URLs of the flow. Other sign-in URLs can't be converted.
+func parseOAuth2Proxy(ingress networkingv1.Ingress) (*ir.OIDC, []notifications.Notification) {
+	signIn := ingress.Annotations[authSigninAnnotation]
+	if signIn == "" {
+		return nil, nil
+	}
+	unconverted := []notifications.Notification{notifications.NewWarning("Ingress %s/%s redirects unauthenticated clients to %s, which can't be converted", ingress.Namespace, ingress.Name, signIn)}
+
+	authURL, err := url.Parse(ingress.Annotations[authURLAnnotation])
+	if err != nil || !strings.HasSuffix(authURL.Path, "/oauth2/auth") {
+		return nil, unconverted
+	}
+	signInURL, err := url.Parse(signIn)
+	if err != nil {
+		return nil, unconverted
+	}
+	var prefix string
+	var ok bool
+	for _, path := range oauth2ProxySignInPaths {
+		if prefix, _, ok = strings.Cut(signInURL.Path, path); ok {
+			break
+		}
+	}
+	if !ok {
+		return nil, unconverted
+	}
+
+	// The callback URL is fixed, while oauth2-proxy is usually served under
+	// the host of each request.
+	host := signInURL.Host
+	if strings.Contains(host, "$") {
+		var hosts []string
+		for _, rule := range ingress.Spec.Rules {
+			if rule.Host != "" {
+				hosts = append(hosts, rule.Host)
+			}
+		}
+		if len(hosts) != 1 {
+			return nil, []notifications.Notification{notifications.NewWarning("Ingress %s/%s signs clients in with oauth2-proxy on the host of each request, which can only be converted for Ingresses with a single host", ingress.Namespace, ingress.Name)}
+		}
+		host = hosts[0]
+	}
+	scheme := signInURL.Scheme
+	if scheme == "" || strings.Contains(scheme, "$") {
+		scheme = "https"
+	}
+	return &ir.OIDC{
+		RedirectURL: fmt.Sprintf("%s://%s%s/oauth2/callback", scheme, host, prefix),
+		LogoutPath:  prefix + "/oauth2/sign_out",
+	}, nil
+}
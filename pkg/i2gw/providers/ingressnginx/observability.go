@@ -0,0 +1,67 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strconv"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+const (
+	enableOpenTelemetryAnnotation = annotationPrefix + "enable-opentelemetry"
+	enableOpenTracingAnnotation   = annotationPrefix + "enable-opentracing"
+	enableAccessLogAnnotation     = annotationPrefix + "enable-access-log"
+
+	// Keys of the ConfigMap of the controller.
+	enableOpenTelemetryKey    = "enable-opentelemetry"
+	enableOpenTracingKey      = "enable-opentracing"
+	otelSamplerParentBasedKey = "otel-sampler-parent-based"
+)
+
+// parseTracing converts the tracing of the requests of the Ingress, enabled
+// by its annotations or else by the ConfigMap of the controller. Access logs
+// can only be configured for whole Gateways, so disabling them is reported.
+func parseTracing(ingress networkingv1.Ingress, config controllerConfig) (*ir.Tracing, []notifications.Notification) {
+	var notes []notifications.Notification
+	enabled := config.tracing
+	for _, annotation := range []string{enableOpenTracingAnnotation, enableOpenTelemetryAnnotation} {
+		value, ok := ingress.Annotations[annotation]
+		if !ok {
+			continue
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, annotation, value))
+			continue
+		}
+		enabled = b
+	}
+	if value, ok := ingress.Annotations[enableAccessLogAnnotation]; ok {
+		if b, err := strconv.ParseBool(value); err != nil {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, enableAccessLogAnnotation, value))
+		} else if !b {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s disables access logs, which can't be converted, its requests are logged as configured for the Gateway", ingress.Namespace, ingress.Name))
+		}
+	}
+	if !enabled {
+		return nil, notes
+	}
+	return &ir.Tracing{ParentBased: config.tracingParentBased}, notes
+}
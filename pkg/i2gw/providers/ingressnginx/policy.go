@@ -0,0 +1,293 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+const (
+	proxyConnectTimeoutAnnotation      = annotationPrefix + "proxy-connect-timeout"
+	proxyReadTimeoutAnnotation         = annotationPrefix + "proxy-read-timeout"
+	proxySendTimeoutAnnotation         = annotationPrefix + "proxy-send-timeout"
+	proxyNextUpstreamAnnotation        = annotationPrefix + "proxy-next-upstream"
+	proxyNextUpstreamTriesAnnotation   = annotationPrefix + "proxy-next-upstream-tries"
+	proxyNextUpstreamTimeoutAnnotation = annotationPrefix + "proxy-next-upstream-timeout"
+	limitRPSAnnotation                 = annotationPrefix + "limit-rps"
+	limitRPMAnnotation                 = annotationPrefix + "limit-rpm"
+	whitelistSourceRangeAnnotation     = annotationPrefix + "whitelist-source-range"
+	allowlistSourceRangeAnnotation     = annotationPrefix + "allowlist-source-range"
+	denylistSourceRangeAnnotation      = annotationPrefix + "denylist-source-range"
+	authURLAnnotation                  = annotationPrefix + "auth-url"
+	authResponseHeadersAnnotation      = annotationPrefix + "auth-response-headers"
+	sslCiphersAnnotation               = annotationPrefix + "ssl-ciphers"
+	serverSnippetAnnotation            = annotationPrefix + "server-snippet"
+	configurationSnippetAnnotation     = annotationPrefix + "configuration-snippet"
+	proxyBufferingAnnotation           = annotationPrefix + "proxy-buffering"
+	proxyBufferSizeAnnotation          = annotationPrefix + "proxy-buffer-size"
+	proxyBuffersNumberAnnotation       = annotationPrefix + "proxy-buffers-number"
+	proxyBodySizeAnnotation            = annotationPrefix + "proxy-body-size"
+
+	// Keys of the ConfigMap of the controller.
+	proxyBodySizeKey = "proxy-body-size"
+	keepAliveKey     = "keep-alive"
+)
+
+func parsePolicy(ingress networkingv1.Ingress, config controllerConfig) (*ir.Policy, []notifications.Notification) {
+	var notes []notifications.Notification
+	policy := &ir.Policy{}
+
+	parseSeconds := func(annotation string) time.Duration {
+		value, ok := ingress.Annotations[annotation]
+		if !ok {
+			return 0
+		}
+		seconds, err := strconv.Atoi(strings.TrimSuffix(value, "s"))
+		if err != nil || seconds < 0 {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, annotation, value))
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	timeouts := ir.Timeouts{
+		Connect: parseSeconds(proxyConnectTimeoutAnnotation),
+		Read:    parseSeconds(proxyReadTimeoutAnnotation),
+		Send:    parseSeconds(proxySendTimeoutAnnotation),
+	}
+	if timeouts != (ir.Timeouts{}) {
+		policy.Timeouts = &timeouts
+	}
+
+	retry, retryNotes := parseRetry(ingress)
+	notes = append(notes, retryNotes...)
+	policy.Retry = retry
+
+	for _, limit := range []struct {
+		annotation string
+		unit       ir.RateLimitUnit
+	}{
+		{limitRPSAnnotation, ir.RateLimitUnitSecond},
+		{limitRPMAnnotation, ir.RateLimitUnitMinute},
+	} {
+		value, ok := ingress.Annotations[limit.annotation]
+		if !ok {
+			continue
+		}
+		requests, err := strconv.Atoi(value)
+		if err != nil || requests <= 0 {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, limit.annotation, value))
+			continue
+		}
+		policy.RateLimits = append(policy.RateLimits, ir.RateLimit{Requests: requests, Unit: limit.unit})
+	}
+
+	sourceRange := ingress.Annotations[allowlistSourceRangeAnnotation]
+	if sourceRange == "" {
+		sourceRange = ingress.Annotations[whitelistSourceRangeAnnotation]
+	}
+	policy.IPAllowList = splitList(sourceRange, ",")
+	policy.IPDenyList = splitList(ingress.Annotations[denylistSourceRangeAnnotation], ",")
+
+	if url := ingress.Annotations[authURLAnnotation]; url != "" {
+		policy.ExtAuth = &ir.ExtAuth{
+			URL:             url,
+			ResponseHeaders: splitList(ingress.Annotations[authResponseHeadersAnnotation], ","),
+		}
+		oidc, oidcNotes := parseOAuth2Proxy(ingress)
+		notes = append(notes, oidcNotes...)
+		policy.OIDC = oidc
+	}
+
+	notes = append(notes, parseTLS(ingress, config, policy)...)
+
+	hash, hashNotes := parseConsistentHash(ingress)
+	notes = append(notes, hashNotes...)
+	policy.ConsistentHash = hash
+
+	algorithm, algorithmNotes := parseLoadBalance(ingress)
+	notes = append(notes, algorithmNotes...)
+	policy.LoadBalance = algorithm
+	policy.Compression = parseCompression(ingress, config)
+
+	waf, wafNotes := parseWAF(ingress)
+	notes = append(notes, wafNotes...)
+	policy.WAF = waf
+
+	tracing, tracingNotes := parseTracing(ingress, config)
+	notes = append(notes, tracingNotes...)
+	policy.Tracing = tracing
+
+	snippets := ir.Snippets{
+		Server:   strings.TrimSpace(ingress.Annotations[serverSnippetAnnotation]),
+		Location: strings.TrimSpace(ingress.Annotations[configurationSnippetAnnotation]),
+	}
+	if snippets != (ir.Snippets{}) {
+		policy.Snippets = &snippets
+	}
+	if strings.Contains(snippets.Server+snippets.Location, "$geoip") {
+		notes = append(notes, notifications.NewWarning("Ingress %s/%s restricts access by client location with GeoIP variables in snippets, which can only be applied by NGINX based implementations", ingress.Namespace, ingress.Name))
+	}
+
+	customErrors, customErrorsNotes := parseCustomErrors(ingress)
+	notes = append(notes, customErrorsNotes...)
+	policy.CustomErrors = customErrors
+
+	validation, validationNotes := parseClientValidation(ingress)
+	notes = append(notes, validationNotes...)
+	policy.ClientValidation = validation
+
+	backendTLS, backendTLSNotes := parseBackendTLS(ingress)
+	notes = append(notes, backendTLSNotes...)
+	policy.BackendTLS = backendTLS
+
+	buffers, bufferNotes := parseProxyBuffers(ingress)
+	notes = append(notes, bufferNotes...)
+	policy.ProxyBuffers = buffers
+
+	if value, ok := ingress.Annotations[proxyBodySizeAnnotation]; ok {
+		size, err := ir.ParseNGINXSize(value)
+		if err != nil {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, proxyBodySizeAnnotation, value))
+		} else {
+			policy.MaxRequestBodySize = &size
+		}
+	} else {
+		policy.MaxRequestBodySize = config.proxyBodySize
+	}
+	policy.KeepAliveTimeout = config.keepAlive
+	policy.HTTP2 = config.http2
+	policy.ProxyProtocol = config.proxyProtocol
+	policy.ClientIP = config.clientIP
+
+	if policy.IsEmpty() {
+		return nil, notes
+	}
+	return policy, notes
+}
+
+// defaultProxyNextUpstreamTries is the number of tries ingress-nginx makes
+// when proxy-next-upstream-tries isn't set.
+const defaultProxyNextUpstreamTries = 3
+
+// parseRetry converts the proxy-next-upstream annotations. Connection errors
+// and timeouts, which ingress-nginx retries by default, are always retried.
+func parseRetry(ingress networkingv1.Ingress) (*ir.Retry, []notifications.Notification) {
+	var notes []notifications.Notification
+	conditions, hasConditions := ingress.Annotations[proxyNextUpstreamAnnotation]
+	tries, hasTries := ingress.Annotations[proxyNextUpstreamTriesAnnotation]
+	timeout, hasTimeout := ingress.Annotations[proxyNextUpstreamTimeoutAnnotation]
+	if !hasConditions && !hasTries && !hasTimeout || strings.TrimSpace(conditions) == "off" {
+		return nil, nil
+	}
+
+	retry := &ir.Retry{Attempts: defaultProxyNextUpstreamTries - 1}
+	var unconverted []string
+	for _, condition := range strings.Fields(conditions) {
+		switch {
+		case condition == "error" || condition == "timeout":
+		case strings.HasPrefix(condition, "http_"):
+			code, err := strconv.Atoi(strings.TrimPrefix(condition, "http_"))
+			if err != nil {
+				unconverted = append(unconverted, condition)
+				continue
+			}
+			retry.StatusCodes = append(retry.StatusCodes, code)
+		default:
+			unconverted = append(unconverted, condition)
+		}
+	}
+	if len(unconverted) > 0 {
+		notes = append(notes, notifications.NewWarning("Ingress %s/%s retries requests on %s, which can't be converted", ingress.Namespace, ingress.Name, strings.Join(unconverted, ", ")))
+	}
+
+	if hasTries {
+		n, err := strconv.Atoi(tries)
+		switch {
+		case err != nil || n < 0:
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, proxyNextUpstreamTriesAnnotation, tries))
+		case n == 0:
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s retries requests an unlimited number of times, the default number of retries of the implementation is used instead", ingress.Namespace, ingress.Name))
+			retry.Attempts = 0
+		case n == 1:
+			// A single try disables retries.
+			return nil, notes
+		default:
+			retry.Attempts = n - 1
+		}
+	}
+
+	if hasTimeout {
+		seconds, err := strconv.Atoi(strings.TrimSuffix(timeout, "s"))
+		if err != nil || seconds < 0 {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, proxyNextUpstreamTimeoutAnnotation, timeout))
+		} else {
+			retry.Timeout = time.Duration(seconds) * time.Second
+		}
+	}
+	return retry, notes
+}
+
+func parseProxyBuffers(ingress networkingv1.Ingress) (*ir.ProxyBuffers, []notifications.Notification) {
+	var notes []notifications.Notification
+	buffers := &ir.ProxyBuffers{}
+	if value, ok := ingress.Annotations[proxyBufferSizeAnnotation]; ok {
+		if _, err := ir.ParseNGINXSize(value); err != nil {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, proxyBufferSizeAnnotation, value))
+		} else {
+			buffers.Size = value
+		}
+	}
+
+	switch value := ingress.Annotations[proxyBufferingAnnotation]; value {
+	case "":
+	case "on", "off":
+		buffering := value == "on"
+		buffers.Buffering = &buffering
+	default:
+		notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, proxyBufferingAnnotation, value))
+	}
+
+	if value, ok := ingress.Annotations[proxyBuffersNumberAnnotation]; ok {
+		number, err := strconv.Atoi(value)
+		if err != nil || number <= 0 {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, proxyBuffersNumberAnnotation, value))
+		} else {
+			buffers.Number = number
+		}
+	}
+
+	if buffers.Buffering == nil && buffers.Size == "" && buffers.Number == 0 {
+		return nil, notes
+	}
+	return buffers, notes
+}
+
+func splitList(value, sep string) []string {
+	var items []string
+	for _, item := range strings.Split(value, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,131 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+const useRegexAnnotation = annotationPrefix + "use-regex"
+
+// parseRegexPaths translates the paths of Ingresses using regular
+// expressions, which ingress-nginx matches as case-insensitive PCRE
+// expressions anchored at the start of the path, to RE2 expressions
+// matching the whole path. Exact paths and literal Prefix paths keep their
+// match type.
+func parseRegexPaths(ingress networkingv1.Ingress) map[string]ir.RegexPath {
+	if ingress.Annotations[useRegexAnnotation] != "true" {
+		return nil
+	}
+
+	regexPaths := map[string]ir.RegexPath{}
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.PathType != nil && *path.PathType == networkingv1.PathTypeExact {
+				continue
+			}
+			if path.PathType != nil && *path.PathType == networkingv1.PathTypePrefix && regexp.QuoteMeta(path.Path) == path.Path {
+				continue
+			}
+			pattern, err := translateRegexPath(path.Path)
+			if err != nil {
+				regexPaths[path.Path] = ir.RegexPath{Error: err.Error()}
+				continue
+			}
+			regexPaths[path.Path] = ir.RegexPath{Pattern: pattern}
+		}
+	}
+	if len(regexPaths) == 0 {
+		return nil
+	}
+	return regexPaths
+}
+
+// translateRegexPath translates an ingress-nginx path to an RE2 expression
+// matching the whole path of the requests it matches. The "^~" modifier of
+// nginx locations makes the rest of the path a case-sensitive literal
+// prefix. Otherwise the expression is made case-insensitive, its "^" anchor
+// is dropped since it is matched from the start of the path, and it matches
+// any path it is a prefix of unless it ends with a "$" anchor. Expressions
+// using PCRE syntax RE2 doesn't support, such as lookarounds and
+// backreferences, can't be translated.
+func translateRegexPath(path string) (string, error) {
+	if literal, ok := strings.CutPrefix(path, "^~"); ok {
+		return regexp.QuoteMeta(strings.TrimSpace(literal)) + ".*", nil
+	}
+
+	expr := strings.TrimPrefix(path, "(?i)")
+	expr = strings.TrimPrefix(expr, "^")
+	anchored := strings.HasSuffix(expr, "$") && !strings.HasSuffix(expr, `\$`)
+	if anchored {
+		expr = strings.TrimSuffix(expr, "$")
+	}
+	if _, err := syntax.Parse(expr, syntax.Perl); err != nil {
+		var syntaxErr *syntax.Error
+		if errors.As(err, &syntaxErr) {
+			return "", fmt.Errorf("%s: `%s`", syntaxErr.Code, syntaxErr.Expr)
+		}
+		return "", err
+	}
+	// Alternatives are grouped for the suffix to apply to all of them.
+	if hasTopLevelAlternation(expr) {
+		expr = "(?:" + expr + ")"
+	}
+	pattern := "(?i)" + expr
+	if !anchored && !strings.HasSuffix(expr, ".*") {
+		pattern += ".*"
+	}
+	return pattern, nil
+}
+
+// hasTopLevelAlternation tells whether a valid expression has alternatives
+// outside of groups and character classes.
+func hasTopLevelAlternation(expr string) bool {
+	depth := 0
+	inClass := false
+	for i := 0; i < len(expr); i++ {
+		switch c := expr[i]; {
+		case c == '\\':
+			i++
+		case inClass:
+			inClass = c != ']'
+		case c == '[':
+			inClass = true
+			// A leading ] is a literal of the class.
+			if strings.HasPrefix(expr[i+1:], "]") || strings.HasPrefix(expr[i+1:], "^]") {
+				i += strings.Index(expr[i:], "]")
+			}
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == '|' && depth == 0:
+			return true
+		}
+	}
+	return false
+}
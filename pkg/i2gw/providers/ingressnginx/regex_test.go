@@ -0,0 +1,56 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"testing"
+)
+
+func Test_translateRegexPath(t *testing.T) {
+	testCases := []struct {
+		path    string
+		want    string
+		wantErr string
+	}{
+		{path: "/api/v[0-9]+", want: "(?i)/api/v[0-9]+.*"},
+		{path: "/api/.*", want: "(?i)/api/.*"},
+		{path: "^/api/v[0-9]+$", want: "(?i)/api/v[0-9]+"},
+		{path: "(?i)/api", want: "(?i)/api.*"},
+		{path: "/price\\$", want: "(?i)/price\\$.*"},
+		{path: "/a|/b", want: "(?i)(?:/a|/b).*"},
+		{path: "/(a|b)/[|]", want: "(?i)/(a|b)/[|].*"},
+		{path: "^~ /static/v1.2", want: "/static/v1\\.2.*"},
+		{path: "/(?!admin).*", wantErr: "invalid or unsupported Perl syntax: `(?!`"},
+		{path: "/(a)\\1", wantErr: "invalid escape sequence: `\\1`"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			got, err := translateRegexPath(tc.path)
+			var gotErr string
+			if err != nil {
+				gotErr = err.Error()
+			}
+			if gotErr != tc.wantErr {
+				t.Fatalf("expected error %q, got %q", tc.wantErr, gotErr)
+			}
+			if got != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
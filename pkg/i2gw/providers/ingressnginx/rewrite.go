@@ -0,0 +1,177 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	rewriteTargetAnnotation   = annotationPrefix + "rewrite-target"
+	upstreamVhostAnnotation   = annotationPrefix + "upstream-vhost"
+	forwardedPrefixAnnotation = annotationPrefix + "x-forwarded-prefix"
+)
+
+// prefixStrip is a regular expression path matching a prefix and capturing
+// the rest of the path in group, with its leading slash when slash is set.
+type prefixStrip struct {
+	regexp *regexp.Regexp
+	group  string
+	slash  bool
+}
+
+// prefixStrips are the regular expressions commonly used with rewrite-target
+// to strip a prefix, such as /api(/|$)(.*) rewritten to /$2.
+var prefixStrips = []prefixStrip{
+	{regexp: regexp.MustCompile(`^(.*)\(/\|\$\)\(\.\*\)$`), group: "$2"},
+	{regexp: regexp.MustCompile(`^(.*)/\(\.\*\)$`), group: "$1"},
+	{regexp: regexp.MustCompile(`^(.*)\(/\.\*\)$`), group: "$1", slash: true},
+}
+
+// parseRewrites converts the rewrite-target annotation. ingress-nginx
+// replaces the whole path of the requests by the target, which expands the
+// groups captured by the paths of the Ingress, then treated as regular
+// expressions. Paths stripping a prefix are converted to a PathPrefix match
+// replacing the prefix, other regular expressions are reported.
+func parseRewrites(ingress networkingv1.Ingress) (map[string]ir.Rewrite, []notifications.Notification) {
+	target := strings.TrimSpace(ingress.Annotations[rewriteTargetAnnotation])
+	if target == "" {
+		return nil, nil
+	}
+
+	var notes []notifications.Notification
+	rewrites := map[string]ir.Rewrite{}
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if _, ok := rewrites[path.Path]; ok {
+				continue
+			}
+			rewrite, ok := toRewrite(path, target)
+			if !ok {
+				notes = append(notes, notifications.NewWarning("Ingress %s/%s rewrites path %q to %q, which can't be converted to a Gateway API rewrite", ingress.Namespace, ingress.Name, path.Path, target))
+				continue
+			}
+			rewrites[path.Path] = rewrite
+		}
+	}
+	if len(rewrites) == 0 {
+		return nil, notes
+	}
+	return rewrites, notes
+}
+
+func toRewrite(path networkingv1.HTTPIngressPath, target string) (ir.Rewrite, bool) {
+	if regexp.QuoteMeta(path.Path) == path.Path {
+		if strings.Contains(target, "$") {
+			return ir.Rewrite{}, false
+		}
+		rewrite := ir.Rewrite{Path: replaceFullPath(target)}
+		if path.PathType != nil && *path.PathType == networkingv1.PathTypeImplementationSpecific {
+			rewrite.Prefix = path.Path
+		}
+		return rewrite, true
+	}
+	if path.PathType != nil && *path.PathType == networkingv1.PathTypeExact {
+		return ir.Rewrite{}, false
+	}
+
+	for _, strip := range prefixStrips {
+		groups := strip.regexp.FindStringSubmatch(path.Path)
+		if groups == nil || regexp.QuoteMeta(groups[1]) != groups[1] {
+			continue
+		}
+		prefix := groups[1]
+		if prefix == "" {
+			prefix = "/"
+		}
+		if !strings.Contains(target, "$") {
+			return ir.Rewrite{Prefix: prefix, Path: replaceFullPath(target)}, true
+		}
+
+		replacement, ok := strings.CutSuffix(target, strip.group)
+		if !ok || strings.Contains(replacement, "$") {
+			return ir.Rewrite{}, false
+		}
+		if strip.slash == strings.HasSuffix(replacement, "/") {
+			return ir.Rewrite{}, false
+		}
+		replacement = strings.TrimSuffix(replacement, "/")
+		if replacement == "" {
+			replacement = "/"
+		}
+		if !strings.HasPrefix(replacement, "/") {
+			return ir.Rewrite{}, false
+		}
+		return ir.Rewrite{
+			Prefix: prefix,
+			Path: gatewayv1.HTTPPathModifier{
+				Type:               gatewayv1.PrefixMatchHTTPPathModifier,
+				ReplacePrefixMatch: &replacement,
+			},
+		}, true
+	}
+	return ir.Rewrite{}, false
+}
+
+func replaceFullPath(target string) gatewayv1.HTTPPathModifier {
+	return gatewayv1.HTTPPathModifier{
+		Type:            gatewayv1.FullPathHTTPPathModifier,
+		ReplaceFullPath: &target,
+	}
+}
+
+// parseHostHeader converts the upstream-vhost annotation, which replaces the
+// Host header of the requests sent to the backends. Without it,
+// ingress-nginx sends the Host header of the request, like Gateway API
+// implementations do.
+func parseHostHeader(ingress networkingv1.Ingress) (*ir.HostHeader, []notifications.Notification) {
+	vhost := strings.TrimSpace(ingress.Annotations[upstreamVhostAnnotation])
+	if vhost == "" {
+		return nil, nil
+	}
+	if strings.Contains(vhost, "$") {
+		return nil, []notifications.Notification{notifications.NewWarning("Ingress %s/%s sets the Host header of the requests to its backends to %q, whose nginx variables can't be converted", ingress.Namespace, ingress.Name, vhost)}
+	}
+	return &ir.HostHeader{Hostname: vhost}, nil
+}
+
+// parseHeaders converts x-forwarded-prefix, whose X-Forwarded-Prefix header
+// ingress-nginx adds to the requests to every path of the Ingress, usually
+// the prefix stripped by rewrite-target.
+func parseHeaders(ingress networkingv1.Ingress) (*ir.Headers, []notifications.Notification) {
+	prefix := strings.TrimSpace(ingress.Annotations[forwardedPrefixAnnotation])
+	if prefix == "" {
+		return nil, nil
+	}
+	if strings.Contains(prefix, "$") {
+		return nil, []notifications.Notification{notifications.NewWarning("Ingress %s/%s sets the X-Forwarded-Prefix header of the requests to its backends to %q, whose nginx variables can't be converted", ingress.Namespace, ingress.Name, prefix)}
+	}
+	return &ir.Headers{
+		Request: &gatewayv1.HTTPHeaderFilter{
+			Set: []gatewayv1.HTTPHeader{{Name: "X-Forwarded-Prefix", Value: prefix}},
+		},
+	}, nil
+}
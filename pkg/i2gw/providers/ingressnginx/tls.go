@@ -0,0 +1,277 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	sslPreferServerCiphersAnnotation = annotationPrefix + "ssl-prefer-server-ciphers"
+	forceSSLRedirectAnnotation       = annotationPrefix + "force-ssl-redirect"
+	sslRedirectAnnotation            = annotationPrefix + "ssl-redirect"
+	sslPassthroughAnnotation         = annotationPrefix + "ssl-passthrough"
+
+	// Keys of the ConfigMap of the controller.
+	sslCiphersKey             = "ssl-ciphers"
+	sslPreferServerCiphersKey = "ssl-prefer-server-ciphers"
+	sslProtocolsKey           = "ssl-protocols"
+	hstsKey                   = "hsts"
+	hstsMaxAgeKey             = "hsts-max-age"
+	hstsIncludeSubdomainsKey  = "hsts-include-subdomains"
+	hstsPreloadKey            = "hsts-preload"
+	forceSSLRedirectKey       = "force-ssl-redirect"
+	sslRedirectKey            = "ssl-redirect"
+	useHTTP2Key               = "use-http2"
+	useProxyProtocolKey       = "use-proxy-protocol"
+	useForwardedHeadersKey    = "use-forwarded-headers"
+	enableRealIPKey           = "enable-real-ip"
+	forwardedForHeaderKey     = "forwarded-for-header"
+	proxyRealIPCIDRKey        = "proxy-real-ip-cidr"
+
+	defaultForwardedForHeader = "X-Forwarded-For"
+
+	defaultHSTSMaxAge = 31536000 * time.Second
+)
+
+// controllerConfigMapNames are the names of the ConfigMap of the controller
+// in the Helm chart and in the static manifests of ingress-nginx.
+var controllerConfigMapNames = map[string]bool{
+	"ingress-nginx-controller": true,
+	"nginx-configuration":      true,
+}
+
+// controllerConfig holds the settings of the ConfigMap of the controller
+// that apply to every Ingress without the equivalent annotation.
+type controllerConfig struct {
+	sslCiphers             []string
+	sslPreferServerCiphers *bool
+	sslProtocols           []string
+	// hsts is nil if the ConfigMap disables HSTS, which is enabled by
+	// default.
+	hsts             *ir.HSTS
+	forceSSLRedirect bool
+	// sslRedirect redirects HTTP requests to HTTPS for the Ingresses with
+	// TLS. It is enabled by default.
+	sslRedirect bool
+	// proxyBodySize is the default limit of the size of request bodies.
+	proxyBodySize *int64
+	// keepAlive is the time idle client connections are kept open.
+	keepAlive time.Duration
+	// http2 is nil unless the ConfigMap sets whether clients can use
+	// HTTP/2, which is enabled by default.
+	http2 *bool
+	// proxyProtocol reads the PROXY protocol header of client connections.
+	proxyProtocol bool
+	// clientIP is nil unless the ConfigMap reads the address of clients
+	// from the forwarded-for-header.
+	clientIP *ir.ClientIP
+	// gzip and brotli compress the responses to every Ingress.
+	gzip   bool
+	brotli bool
+	// tracing traces the requests to every Ingress that doesn't disable it.
+	tracing            bool
+	tracingParentBased bool
+}
+
+// defaultControllerConfig returns the settings the controller applies
+// without a ConfigMap, or for the keys a ConfigMap doesn't set.
+func defaultControllerConfig() controllerConfig {
+	return controllerConfig{
+		hsts:        &ir.HSTS{MaxAge: defaultHSTSMaxAge, IncludeSubdomains: true},
+		sslRedirect: true,
+	}
+}
+
+// ReadControllerConfig reads the TLS, HSTS, request body size, keep-alive,
+// HTTP/2, PROXY protocol, client IP, compression and tracing settings of the
+// ConfigMap of the controller, when it is part of the input, overriding the
+// defaults of the controller.
+func (p *Provider) ReadControllerConfig(objects []unstructured.Unstructured) []notifications.Notification {
+	var notes []notifications.Notification
+	var configMaps []unstructured.Unstructured
+	for _, obj := range objects {
+		if obj.GetAPIVersion() == "v1" && obj.GetKind() == "ConfigMap" && controllerConfigMapNames[obj.GetName()] {
+			configMaps = append(configMaps, obj)
+		}
+	}
+	if len(configMaps) == 0 {
+		return nil
+	}
+	sort.Slice(configMaps, func(i, j int) bool {
+		return configMaps[i].GetNamespace()+"/"+configMaps[i].GetName() < configMaps[j].GetNamespace()+"/"+configMaps[j].GetName()
+	})
+	configMap := configMaps[0]
+	if len(configMaps) > 1 {
+		notes = append(notes, notifications.NewWarning("Found %d ConfigMaps of the ingress-nginx controller, only %s/%s is read", len(configMaps), configMap.GetNamespace(), configMap.GetName()))
+	}
+
+	data, _, err := unstructured.NestedStringMap(configMap.Object, "data")
+	if err != nil {
+		notes = append(notes, notifications.NewWarning("ConfigMap %s/%s of the ingress-nginx controller is invalid: %v", configMap.GetNamespace(), configMap.GetName(), err))
+		return notes
+	}
+	p.config = defaultControllerConfig()
+	p.config.sslCiphers = splitList(data[sslCiphersKey], ":")
+	p.config.sslProtocols = strings.Fields(data[sslProtocolsKey])
+	parseBool := func(key string, defaultValue bool) *bool {
+		value, ok := data[key]
+		if !ok {
+			return &defaultValue
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			notes = append(notes, notifications.NewWarning("ConfigMap %s/%s of the ingress-nginx controller has an invalid %s setting %q, ignoring it", configMap.GetNamespace(), configMap.GetName(), key, value))
+			return &defaultValue
+		}
+		return &b
+	}
+	if _, ok := data[sslPreferServerCiphersKey]; ok {
+		p.config.sslPreferServerCiphers = parseBool(sslPreferServerCiphersKey, true)
+	}
+
+	if _, ok := data[useHTTP2Key]; ok {
+		p.config.http2 = parseBool(useHTTP2Key, true)
+	}
+	p.config.proxyProtocol = *parseBool(useProxyProtocolKey, false)
+	if *parseBool(useForwardedHeadersKey, false) || *parseBool(enableRealIPKey, false) {
+		p.config.clientIP = &ir.ClientIP{Header: defaultForwardedForHeader}
+		if header := strings.TrimSpace(data[forwardedForHeaderKey]); header != "" {
+			p.config.clientIP.Header = header
+		}
+		// The controller trusts every proxy by default.
+		for _, cidr := range splitList(data[proxyRealIPCIDRKey], ",") {
+			if cidr != "0.0.0.0/0" {
+				p.config.clientIP.TrustedCIDRs = append(p.config.clientIP.TrustedCIDRs, cidr)
+			}
+		}
+	}
+	p.config.forceSSLRedirect = *parseBool(forceSSLRedirectKey, false)
+	p.config.sslRedirect = *parseBool(sslRedirectKey, true)
+	p.config.gzip = *parseBool(useGzipKey, false)
+	p.config.brotli = *parseBool(enableBrotliKey, false)
+	p.config.tracing = *parseBool(enableOpenTelemetryKey, false) || *parseBool(enableOpenTracingKey, false)
+	p.config.tracingParentBased = *parseBool(otelSamplerParentBasedKey, false)
+
+	if *parseBool(hstsKey, true) {
+		p.config.hsts = &ir.HSTS{
+			MaxAge:            defaultHSTSMaxAge,
+			IncludeSubdomains: *parseBool(hstsIncludeSubdomainsKey, true),
+			Preload:           *parseBool(hstsPreloadKey, false),
+		}
+		if value, ok := data[hstsMaxAgeKey]; ok {
+			seconds, err := strconv.Atoi(value)
+			if err != nil || seconds < 0 {
+				notes = append(notes, notifications.NewWarning("ConfigMap %s/%s of the ingress-nginx controller has an invalid %s setting %q, ignoring it", configMap.GetNamespace(), configMap.GetName(), hstsMaxAgeKey, value))
+			} else {
+				p.config.hsts.MaxAge = time.Duration(seconds) * time.Second
+			}
+		}
+	} else {
+		p.config.hsts = nil
+	}
+
+	if value, ok := data[proxyBodySizeKey]; ok {
+		size, err := ir.ParseNGINXSize(value)
+		if err != nil {
+			notes = append(notes, notifications.NewWarning("ConfigMap %s/%s of the ingress-nginx controller has an invalid %s setting %q, ignoring it", configMap.GetNamespace(), configMap.GetName(), proxyBodySizeKey, value))
+		} else {
+			p.config.proxyBodySize = &size
+		}
+	}
+	if value, ok := data[keepAliveKey]; ok {
+		seconds, err := strconv.Atoi(strings.TrimSuffix(value, "s"))
+		switch {
+		case err != nil || seconds < 0:
+			notes = append(notes, notifications.NewWarning("ConfigMap %s/%s of the ingress-nginx controller has an invalid %s setting %q, ignoring it", configMap.GetNamespace(), configMap.GetName(), keepAliveKey, value))
+		case seconds == 0:
+			notes = append(notes, notifications.NewWarning("ConfigMap %s/%s of the ingress-nginx controller disables keep-alive client connections, which can't be converted", configMap.GetNamespace(), configMap.GetName()))
+		default:
+			p.config.keepAlive = time.Duration(seconds) * time.Second
+		}
+	}
+	return notes
+}
+
+// parseTLS converts the TLS settings of Ingresses with TLS, falling back to
+// the ones of the controller. The HSTS policy and SSL redirects of the
+// controller apply to every Ingress with TLS.
+func parseTLS(ingress networkingv1.Ingress, config controllerConfig, policy *ir.Policy) []notifications.Notification {
+	var notes []notifications.Notification
+	policy.TLSCiphers = splitList(ingress.Annotations[sslCiphersAnnotation], ":")
+	if value, ok := ingress.Annotations[sslPreferServerCiphersAnnotation]; ok {
+		prefer, err := strconv.ParseBool(value)
+		if err != nil {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, sslPreferServerCiphersAnnotation, value))
+		} else {
+			policy.TLSPreferServerCiphers = &prefer
+		}
+	}
+	forceSSLRedirect := config.forceSSLRedirect
+	if value, ok := ingress.Annotations[forceSSLRedirectAnnotation]; ok {
+		force, err := strconv.ParseBool(value)
+		if err != nil {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, forceSSLRedirectAnnotation, value))
+		} else {
+			forceSSLRedirect = force
+			if force && len(ingress.Spec.TLS) == 0 {
+				notes = append(notes, notifications.NewWarning("Ingress %s/%s redirects HTTP requests to HTTPS without TLS, the Gateway can't serve HTTPS for it", ingress.Namespace, ingress.Name))
+			}
+		}
+	}
+	if len(ingress.Spec.TLS) == 0 {
+		return notes
+	}
+
+	sslRedirect := config.sslRedirect
+	if value, ok := ingress.Annotations[sslRedirectAnnotation]; ok {
+		redirect, err := strconv.ParseBool(value)
+		if err != nil {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, sslRedirectAnnotation, value))
+		} else {
+			sslRedirect = redirect
+		}
+	}
+	policy.SSLRedirect = forceSSLRedirect || sslRedirect
+	if len(policy.TLSCiphers) == 0 {
+		policy.TLSCiphers = config.sslCiphers
+	}
+	if policy.TLSPreferServerCiphers == nil {
+		policy.TLSPreferServerCiphers = config.sslPreferServerCiphers
+	}
+	policy.TLSProtocols = config.sslProtocols
+	policy.HSTS = config.hsts
+	return notes
+}
+
+// parseStream converts ssl-passthrough, with which the controller forwards
+// the TLS connections to the hosts of the Ingress to the backend of their
+// "/" path without terminating them.
+func parseStream(ingress networkingv1.Ingress) *ir.Stream {
+	if ingress.Annotations[sslPassthroughAnnotation] != "true" {
+		return nil
+	}
+	return &ir.Stream{Protocol: "tls"}
+}
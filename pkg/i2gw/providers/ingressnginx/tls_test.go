@@ -0,0 +1,128 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_Provider_controllerDefaults(t *testing.T) {
+	defaultHSTS := &ir.HSTS{MaxAge: defaultHSTSMaxAge, IncludeSubdomains: true}
+	configMap := func(data map[string]interface{}) []unstructured.Unstructured {
+		obj := unstructured.Unstructured{Object: map[string]interface{}{"data": data}}
+		obj.SetAPIVersion("v1")
+		obj.SetKind("ConfigMap")
+		obj.SetNamespace("ingress-nginx")
+		obj.SetName("ingress-nginx-controller")
+		return []unstructured.Unstructured{obj}
+	}
+
+	testCases := []struct {
+		name           string
+		ingressClass   string
+		ingressClasses []networkingv1.IngressClass
+		objects        []unstructured.Unstructured
+		expectRedirect bool
+		expectHSTS     *ir.HSTS
+	}{{
+		name:           "nginx class without ConfigMap",
+		ingressClass:   "nginx",
+		expectRedirect: true,
+		expectHSTS:     defaultHSTS,
+	}, {
+		name:           "IngressClass of the controller",
+		ingressClass:   "internal",
+		ingressClasses: []networkingv1.IngressClass{{ObjectMeta: metav1.ObjectMeta{Name: "internal"}}},
+		expectRedirect: true,
+		expectHSTS:     defaultHSTS,
+	}, {
+		name: "no class with a default IngressClass of the controller",
+		ingressClasses: []networkingv1.IngressClass{{ObjectMeta: metav1.ObjectMeta{
+			Name:        "internal",
+			Annotations: map[string]string{networkingv1.AnnotationIsDefaultIngressClass: "true"},
+		}}},
+		expectRedirect: true,
+		expectHSTS:     defaultHSTS,
+	}, {
+		name:         "class of another controller",
+		ingressClass: "gce",
+	}, {
+		name: "no class",
+	}, {
+		name:         "ConfigMap disabling the defaults",
+		ingressClass: "nginx",
+		objects:      configMap(map[string]interface{}{"hsts": "false", "ssl-redirect": "false"}),
+	}, {
+		name:           "empty ConfigMap",
+		ingressClass:   "nginx",
+		objects:        configMap(map[string]interface{}{}),
+		expectRedirect: true,
+		expectHSTS:     defaultHSTS,
+	}, {
+		name:           "IngressClass of the controller with a ConfigMap",
+		ingressClass:   "internal",
+		ingressClasses: []networkingv1.IngressClass{{ObjectMeta: metav1.ObjectMeta{Name: "internal"}}},
+		objects:        configMap(map[string]interface{}{"hsts-max-age": "600"}),
+		expectRedirect: true,
+		expectHSTS:     &ir.HSTS{MaxAge: 10 * time.Minute, IncludeSubdomains: true},
+	}, {
+		name:         "class of another controller with a ConfigMap",
+		ingressClass: "gce",
+		objects:      configMap(map[string]interface{}{}),
+	}, {
+		name:    "no class with a ConfigMap",
+		objects: configMap(map[string]interface{}{}),
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ingress := networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "shop"},
+				Spec: networkingv1.IngressSpec{
+					TLS: []networkingv1.IngressTLS{{Hosts: []string{"shop.example.com"}, SecretName: "shop-cert"}},
+				},
+			}
+			if tc.ingressClass != "" {
+				ingress.Spec.IngressClassName = &tc.ingressClass
+			}
+
+			p := NewProvider()
+			p.ReadIngressClasses(tc.ingressClasses)
+			if notes := p.ReadControllerConfig(tc.objects); len(notes) > 0 {
+				t.Fatalf("Unexpected notifications: %v", notes)
+			}
+			features, _ := p.ParseIngress(ingress)
+			var policy ir.Policy
+			if features.Policy != nil {
+				policy = *features.Policy
+			}
+			if policy.SSLRedirect != tc.expectRedirect {
+				t.Errorf("Expected SSLRedirect %t, got %t", tc.expectRedirect, policy.SSLRedirect)
+			}
+			if diff := cmp.Diff(tc.expectHSTS, policy.HSTS); diff != "" {
+				t.Errorf("Unexpected HSTS, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
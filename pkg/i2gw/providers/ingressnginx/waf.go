@@ -0,0 +1,68 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+const (
+	enableModSecurityAnnotation        = annotationPrefix + "enable-modsecurity"
+	enableOWASPCoreRulesAnnotation     = annotationPrefix + "enable-owasp-core-rules"
+	modSecurityTransactionIDAnnotation = annotationPrefix + "modsecurity-transaction-id"
+	modSecuritySnippetAnnotation       = annotationPrefix + "modsecurity-snippet"
+)
+
+// parseWAF converts the ModSecurity annotations. Requests are no longer
+// inspected once converted unless the Gateway implementation runs a web
+// application firewall, which is reported whether or not a target converts
+// it.
+func parseWAF(ingress networkingv1.Ingress) (*ir.WAF, []notifications.Notification) {
+	var notes []notifications.Notification
+	parseBool := func(annotation string) bool {
+		value, ok := ingress.Annotations[annotation]
+		if !ok {
+			return false
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s has an invalid %s annotation %q, ignoring it", ingress.Namespace, ingress.Name, annotation, value))
+		}
+		return b
+	}
+	enabled := parseBool(enableModSecurityAnnotation)
+	waf := &ir.WAF{
+		OWASPCoreRules: parseBool(enableOWASPCoreRulesAnnotation),
+		Rules:          strings.TrimSpace(ingress.Annotations[modSecuritySnippetAnnotation]),
+	}
+	if !enabled && !waf.OWASPCoreRules {
+		if waf.Rules != "" {
+			notes = append(notes, notifications.NewWarning("Ingress %s/%s sets %s without enabling ModSecurity, ignoring it", ingress.Namespace, ingress.Name, modSecuritySnippetAnnotation))
+		}
+		return nil, notes
+	}
+	if _, ok := ingress.Annotations[modSecurityTransactionIDAnnotation]; ok {
+		notes = append(notes, notifications.NewWarning("Ingress %s/%s sets the ModSecurity transaction ID, which can't be converted", ingress.Namespace, ingress.Name))
+	}
+	notes = append(notes, notifications.NewWarning("SECURITY: Ingress %s/%s inspects requests with the ModSecurity web application firewall, the converted routes are unprotected until an equivalent firewall is configured for the Gateway", ingress.Namespace, ingress.Name))
+	return waf, notes
+}
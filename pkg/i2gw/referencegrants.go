@@ -0,0 +1,135 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+var referenceGrantGVK = schema.GroupVersionKind{
+	Group:   "gateway.networking.k8s.io",
+	Version: "v1beta1",
+	Kind:    "ReferenceGrant",
+}
+
+type referenceGrantKey struct {
+	fromKind      gatewayv1beta1.Kind
+	fromNamespace string
+	toNamespace   string
+}
+
+// referenceGrantsFor generates a ReferenceGrant in every namespace holding
+// backends referenced by HTTPRoutes, or TLS certificates referenced by
+// Gateways, from another namespace. Without these grants the implementation
+// would reject the cross-namespace references.
+func referenceGrantsFor(httpRoutes []gatewayv1.HTTPRoute, gateways []gatewayv1.Gateway) ([]gatewayv1beta1.ReferenceGrant, []Notification) {
+	grantsByKey := map[referenceGrantKey]*gatewayv1beta1.ReferenceGrant{}
+	var keys []referenceGrantKey
+	seenTo := map[referenceGrantKey]map[gatewayv1beta1.ReferenceGrantTo]struct{}{}
+
+	addGrant := func(key referenceGrantKey, to gatewayv1beta1.ReferenceGrantTo) {
+		grant, ok := grantsByKey[key]
+		if !ok {
+			name := fmt.Sprintf("from-%s", key.fromNamespace)
+			if key.fromKind != "HTTPRoute" {
+				name = fmt.Sprintf("from-%s-%ss", key.fromNamespace, strings.ToLower(string(key.fromKind)))
+			}
+			grant = &gatewayv1beta1.ReferenceGrant{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      truncateName(name, key.fromNamespace, maxObjectNameLength),
+					Namespace: key.toNamespace,
+				},
+				Spec: gatewayv1beta1.ReferenceGrantSpec{
+					From: []gatewayv1beta1.ReferenceGrantFrom{{
+						Group:     gatewayv1beta1.Group(gatewayv1.GroupName),
+						Kind:      key.fromKind,
+						Namespace: gatewayv1beta1.Namespace(key.fromNamespace),
+					}},
+				},
+			}
+			grant.SetGroupVersionKind(referenceGrantGVK)
+			grantsByKey[key] = grant
+			seenTo[key] = map[gatewayv1beta1.ReferenceGrantTo]struct{}{}
+			keys = append(keys, key)
+		}
+		if _, ok := seenTo[key][to]; !ok {
+			seenTo[key][to] = struct{}{}
+			grant.Spec.To = append(grant.Spec.To, to)
+		}
+	}
+
+	for _, route := range httpRoutes {
+		for _, rule := range route.Spec.Rules {
+			for _, br := range rule.BackendRefs {
+				if br.Namespace == nil || string(*br.Namespace) == route.Namespace {
+					continue
+				}
+				to := gatewayv1beta1.ReferenceGrantTo{Kind: "Service"}
+				if br.Group != nil {
+					to.Group = gatewayv1beta1.Group(*br.Group)
+				}
+				if br.Kind != nil {
+					to.Kind = gatewayv1beta1.Kind(*br.Kind)
+				}
+				addGrant(referenceGrantKey{fromKind: "HTTPRoute", fromNamespace: route.Namespace, toNamespace: string(*br.Namespace)}, to)
+			}
+		}
+	}
+	for _, gw := range gateways {
+		for _, listener := range gw.Spec.Listeners {
+			if listener.TLS == nil {
+				continue
+			}
+			for _, ref := range listener.TLS.CertificateRefs {
+				if ref.Namespace == nil || string(*ref.Namespace) == gw.Namespace {
+					continue
+				}
+				addGrant(referenceGrantKey{fromKind: "Gateway", fromNamespace: gw.Namespace, toNamespace: string(*ref.Namespace)}, gatewayv1beta1.ReferenceGrantTo{Kind: "Secret"})
+			}
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].toNamespace != keys[j].toNamespace {
+			return keys[i].toNamespace < keys[j].toNamespace
+		}
+		if keys[i].fromNamespace != keys[j].fromNamespace {
+			return keys[i].fromNamespace < keys[j].fromNamespace
+		}
+		return keys[i].fromKind > keys[j].fromKind
+	})
+
+	var grants []gatewayv1beta1.ReferenceGrant
+	var notes []Notification
+	for _, key := range keys {
+		grant := grantsByKey[key]
+		grants = append(grants, *grant)
+		if key.fromKind == "HTTPRoute" {
+			notes = append(notes, notifications.NewInfo("Generated ReferenceGrant %s/%s allowing HTTPRoutes in namespace %s to reference its backends, the routes will be rejected if it is not applied", grant.Namespace, grant.Name, key.fromNamespace))
+		} else {
+			notes = append(notes, notifications.NewInfo("Generated ReferenceGrant %s/%s allowing Gateways in namespace %s to reference its TLS certificates, the listeners will be rejected if it is not applied", grant.Namespace, grant.Name, key.fromNamespace))
+		}
+	}
+	return grants, notes
+}
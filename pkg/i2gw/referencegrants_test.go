@@ -0,0 +1,142 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+func Test_referenceGrantsForHTTPRoutes(t *testing.T) {
+	backendRef := func(namespace, group, kind, name string) gatewayv1.HTTPBackendRef {
+		ref := gatewayv1.HTTPBackendRef{BackendRef: gatewayv1.BackendRef{
+			BackendObjectReference: gatewayv1.BackendObjectReference{Name: gatewayv1.ObjectName(name)},
+		}}
+		if namespace != "" {
+			ref.Namespace = (*gatewayv1.Namespace)(&namespace)
+		}
+		if group != "" {
+			ref.Group = apiGroupPtr(group)
+		}
+		if kind != "" {
+			ref.Kind = apiKindPtr(kind)
+		}
+		return ref
+	}
+
+	httpRoutes := []gatewayv1.HTTPRoute{{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-com", Namespace: "apps"},
+		Spec: gatewayv1.HTTPRouteSpec{
+			Rules: []gatewayv1.HTTPRouteRule{{
+				BackendRefs: []gatewayv1.HTTPBackendRef{
+					backendRef("", "", "", "local"),
+					backendRef("apps", "", "", "same-namespace"),
+					backendRef("shared", "", "", "remote"),
+					backendRef("shared", "", "", "another-remote"),
+				},
+			}, {
+				BackendRefs: []gatewayv1.HTTPBackendRef{
+					backendRef("shared", "vendor.example.com", "StorageBucket", "bucket"),
+				},
+			}},
+		},
+	}}
+
+	grants, notifications := referenceGrantsFor(httpRoutes, nil)
+
+	expectGrants := []gatewayv1beta1.ReferenceGrant{{
+		ObjectMeta: metav1.ObjectMeta{Name: "from-apps", Namespace: "shared"},
+		Spec: gatewayv1beta1.ReferenceGrantSpec{
+			From: []gatewayv1beta1.ReferenceGrantFrom{{
+				Group:     "gateway.networking.k8s.io",
+				Kind:      "HTTPRoute",
+				Namespace: "apps",
+			}},
+			To: []gatewayv1beta1.ReferenceGrantTo{{
+				Kind: "Service",
+			}, {
+				Group: "vendor.example.com",
+				Kind:  "StorageBucket",
+			}},
+		},
+	}}
+	for i := range expectGrants {
+		expectGrants[i].SetGroupVersionKind(referenceGrantGVK)
+	}
+	if !apiequality.Semantic.DeepEqual(expectGrants, grants) {
+		t.Errorf("Unexpected ReferenceGrants, diff (-want +got): %s", cmp.Diff(expectGrants, grants))
+	}
+	if len(notifications) != 1 {
+		t.Errorf("Expected 1 notification, got %+v", notifications)
+	}
+	// The v1.2 CRDs only serve ReferenceGrants as v1beta1.
+	if len(grants) > 0 && grants[0].APIVersion != "gateway.networking.k8s.io/v1beta1" {
+		t.Errorf("Expected a gateway.networking.k8s.io/v1beta1 ReferenceGrant, got %s", grants[0].APIVersion)
+	}
+}
+
+func Test_referenceGrantsForGateways(t *testing.T) {
+	apps := gatewayv1.Namespace("apps")
+	infra := gatewayv1.Namespace("infra")
+	gateways := []gatewayv1.Gateway{{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "infra"},
+		Spec: gatewayv1.GatewaySpec{
+			Listeners: []gatewayv1.Listener{{
+				Name:     "example-com-http",
+				Protocol: gatewayv1.HTTPProtocolType,
+			}, {
+				Name:     "example-com-https",
+				Protocol: gatewayv1.HTTPSProtocolType,
+				TLS: &gatewayv1.GatewayTLSConfig{CertificateRefs: []gatewayv1.SecretObjectReference{
+					{Name: "example-cert", Namespace: &apps},
+					{Name: "other-cert", Namespace: &apps},
+					{Name: "infra-cert", Namespace: &infra},
+					{Name: "local-cert"},
+				}},
+			}},
+		},
+	}}
+
+	grants, notifications := referenceGrantsFor(nil, gateways)
+
+	expectGrants := []gatewayv1beta1.ReferenceGrant{{
+		ObjectMeta: metav1.ObjectMeta{Name: "from-infra-gateways", Namespace: "apps"},
+		Spec: gatewayv1beta1.ReferenceGrantSpec{
+			From: []gatewayv1beta1.ReferenceGrantFrom{{
+				Group:     "gateway.networking.k8s.io",
+				Kind:      "Gateway",
+				Namespace: "infra",
+			}},
+			To: []gatewayv1beta1.ReferenceGrantTo{{
+				Kind: "Secret",
+			}},
+		},
+	}}
+	for i := range expectGrants {
+		expectGrants[i].SetGroupVersionKind(referenceGrantGVK)
+	}
+	if !apiequality.Semantic.DeepEqual(expectGrants, grants) {
+		t.Errorf("Unexpected ReferenceGrants, diff (-want +got): %s", cmp.Diff(expectGrants, grants))
+	}
+	if len(notifications) != 1 {
+		t.Errorf("Expected 1 notification, got %+v", notifications)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+)
+
+// RouteStrategy selects how the rules of Ingresses are grouped into
+// HTTPRoutes.
+type RouteStrategy string
+
+const (
+	// RoutePerHost merges the rules of every Ingress of a host into one
+	// HTTPRoute per namespace and Gateway.
+	RoutePerHost RouteStrategy = "host"
+	// RoutePerIngress generates the HTTPRoutes of every Ingress on their
+	// own, named after the Ingress, so that they keep the ownership and
+	// RBAC boundaries of the Ingresses. Canary Ingresses stay in the
+	// HTTPRoutes of their primary Ingress.
+	RoutePerIngress RouteStrategy = "ingress"
+)
+
+// RouteStrategies returns the names of the supported route strategies.
+func RouteStrategies() []string {
+	return []string{string(RoutePerHost), string(RoutePerIngress)}
+}
+
+func validateRouteStrategy(strategy RouteStrategy) error {
+	switch strategy {
+	case "", RoutePerHost, RoutePerIngress:
+		return nil
+	default:
+		return fmt.Errorf("unknown route strategy %q, supported ones are: %s", strategy, strings.Join(RouteStrategies(), ", "))
+	}
+}
+
+// splitRuleGroupsByIngress splits the rule groups of several Ingresses into
+// a rule group per Ingress. The rules of canary Ingresses join the rule
+// group of the Ingress of their primary path. Paths of several Ingresses
+// matching the same requests aren't merged anymore, Gateways send their
+// requests to the oldest HTTPRoute, which is reported.
+func (a *ingressAggregator) splitRuleGroupsByIngress() {
+	for _, key := range a.sortedRuleGroupKeys() {
+		rg := a.ruleGroups[key]
+		var names []string
+		byIngress := map[string][]ingressRule{}
+		owners := map[pathMatchKey]ingressPath{}
+		var canaries []ingressRule
+		for _, rule := range rg.rules {
+			if rule.features != nil && rule.features.Canary != nil {
+				canaries = append(canaries, rule)
+				continue
+			}
+			if _, ok := byIngress[rule.ingressName]; !ok {
+				names = append(names, rule.ingressName)
+			}
+			byIngress[rule.ingressName] = append(byIngress[rule.ingressName], rule)
+			if rule.rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.rule.HTTP.Paths {
+				ip := newIngressPath(rule.ingressName, path, rule.features)
+				owner, ok := owners[getPathMatchKey(ip)]
+				if !ok {
+					owners[getPathMatchKey(ip)] = ip
+					continue
+				}
+				if owner.ingressName != ip.ingressName && !reflect.DeepEqual(owner.path.Backend, ip.path.Backend) {
+					a.notifications = append(a.notifications, notifications.NewWarning("Ingresses %s/%s and %s/%s route path %q of host %q to different backends from their own HTTPRoutes, Gateways send its requests to the oldest HTTPRoute only", rg.namespace, owner.ingressName, rg.namespace, ip.ingressName, ip.path.Path, rg.host))
+				}
+			}
+		}
+		if len(names) < 2 {
+			continue
+		}
+		for _, rule := range canaries {
+			owner := names[0]
+			if rule.rule.HTTP != nil {
+				for _, path := range rule.rule.HTTP.Paths {
+					if primary, ok := owners[getPrimaryPathMatchKey(newIngressPath(rule.ingressName, path, rule.features))]; ok {
+						owner = primary.ingressName
+						break
+					}
+				}
+			}
+			byIngress[owner] = append(byIngress[owner], rule)
+		}
+
+		delete(a.ruleGroups, key)
+		for _, name := range names {
+			group := *rg
+			group.rules = byIngress[name]
+			a.ruleGroups[key+ruleGroupKey("/"+name)] = &group
+		}
+	}
+}
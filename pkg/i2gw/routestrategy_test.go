@@ -0,0 +1,123 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+func Test_RoutePerIngress(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	canary := map[string]string{
+		"nginx.ingress.kubernetes.io/canary":        "true",
+		"nginx.ingress.kubernetes.io/canary-weight": "20",
+	}
+
+	testCases := []struct {
+		name      string
+		ingresses []networkingv1.Ingress
+		strategy  RouteStrategy
+		// wantRoutes are the backends of the rules of every HTTPRoute, by
+		// name.
+		wantRoutes    map[string][][]string
+		wantListeners int
+		wantWarning   string
+	}{{
+		name: "ingresses of a host are merged by default",
+		ingresses: []networkingv1.Ingress{
+			ingressWithPath("shop", "/shop", &iPrefix, serviceBackend("shop", 80), nil),
+			ingressWithPath("web", "/web", &iPrefix, serviceBackend("web", 80), nil),
+		},
+		wantRoutes:    map[string][][]string{"example-com": {{"shop"}, {"web"}}},
+		wantListeners: 1,
+	}, {
+		name: "every ingress gets its own route",
+		ingresses: []networkingv1.Ingress{
+			ingressWithPath("shop", "/shop", &iPrefix, serviceBackend("shop", 80), nil),
+			ingressWithPath("web", "/web", &iPrefix, serviceBackend("web", 80), nil),
+		},
+		strategy:      RoutePerIngress,
+		wantRoutes:    map[string][][]string{"shop-example-com": {{"shop"}}, "web-example-com": {{"web"}}},
+		wantListeners: 1,
+	}, {
+		name: "canary ingresses stay with their primary ingress",
+		ingresses: []networkingv1.Ingress{
+			ingressWithPath("shop", "/shop", &iPrefix, serviceBackend("shop", 80), nil),
+			ingressWithPath("web", "/web", &iPrefix, serviceBackend("web", 80), nil),
+			ingressWithPath("web-canary", "/web", &iPrefix, serviceBackend("web-canary", 80), canary),
+		},
+		strategy:      RoutePerIngress,
+		wantRoutes:    map[string][][]string{"shop-example-com": {{"shop"}}, "web-example-com": {{"web", "web-canary"}}},
+		wantListeners: 1,
+	}, {
+		name: "colliding paths are reported",
+		ingresses: []networkingv1.Ingress{
+			ingressWithPath("shop", "/", &iPrefix, serviceBackend("shop", 80), nil),
+			ingressWithPath("web", "/", &iPrefix, serviceBackend("web", 80), nil),
+		},
+		strategy:      RoutePerIngress,
+		wantRoutes:    map[string][][]string{"shop-example-com": {{"shop"}}, "web-example-com": {{"web"}}},
+		wantListeners: 1,
+		wantWarning:   `Ingresses test/shop and test/web route path "/" of host "example.com" to different backends from their own HTTPRoutes`,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resources, report := convertInput(inputResources{ingresses: tc.ingresses}, ConvertOptions{RouteStrategy: tc.strategy})
+			if len(report.Errors) > 0 {
+				t.Fatalf("Unexpected errors: %v", report.Errors)
+			}
+			routes := map[string][][]string{}
+			for _, route := range resources.HTTPRoutes {
+				for _, rule := range route.Spec.Rules {
+					var backends []string
+					for _, ref := range rule.BackendRefs {
+						backends = append(backends, string(ref.Name))
+					}
+					routes[route.Name] = append(routes[route.Name], backends)
+				}
+			}
+			if diff := cmp.Diff(tc.wantRoutes, routes); diff != "" {
+				t.Errorf("Unexpected HTTPRoutes, diff (-want +got): %s", diff)
+			}
+			if len(resources.Gateways) != 1 || len(resources.Gateways[0].Spec.Listeners) != tc.wantListeners {
+				t.Errorf("Expected 1 Gateway with %d listeners, got %v", tc.wantListeners, resources.Gateways)
+			}
+			var warned bool
+			for _, n := range report.Notifications {
+				warned = warned || tc.wantWarning != "" && n.Type == WarningNotification && strings.Contains(n.Message, tc.wantWarning)
+			}
+			if tc.wantWarning != "" && !warned {
+				t.Errorf("Expected a warning containing %q, got %v", tc.wantWarning, report.Notifications)
+			}
+		})
+	}
+}
+
+func Test_validateRouteStrategy(t *testing.T) {
+	for _, strategy := range []RouteStrategy{"", RoutePerHost, RoutePerIngress} {
+		if err := validateRouteStrategy(strategy); err != nil {
+			t.Errorf("validateRouteStrategy(%q) = %v", strategy, err)
+		}
+	}
+	if err := validateRouteStrategy("path"); err == nil {
+		t.Errorf("validateRouteStrategy(%q) expected an error", "path")
+	}
+}
@@ -0,0 +1,203 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// shardGateways splits the Gateways with more listeners than Gateway API
+// allows into Gateways named after them with a -1, -2... suffix, keeping
+// the HTTP and HTTPS listeners of a host together, and moves the routes of
+// each host to the Gateway of its listeners. Every Gateway gets addresses of
+// its own, so the layout is reported.
+func shardGateways(result *ir.IR, strategy ListenerStrategy) []Notification {
+	var notes []Notification
+	var gateways []ir.Gateway
+	for _, gw := range result.Gateways {
+		total := 0
+		for _, l := range gw.Listeners {
+			total += gatewayListenerCount(l)
+		}
+		if total <= maxGatewayListeners {
+			gateways = append(gateways, gw)
+			continue
+		}
+
+		var shards []ir.Gateway
+		count := 0
+		for _, l := range gw.Listeners {
+			if len(shards) == 0 || count+gatewayListenerCount(l) > maxGatewayListeners {
+				shard := gw
+				shard.Name = fmt.Sprintf("%s-%d", gw.Name, len(shards)+1)
+				shard.Listeners, shard.Ingresses = nil, nil
+				shards = append(shards, shard)
+				count = 0
+			}
+			shards[len(shards)-1].Listeners = append(shards[len(shards)-1].Listeners, l)
+			count += gatewayListenerCount(l)
+		}
+
+		key := types.NamespacedName{Namespace: gw.Namespace, Name: gw.Name}
+		index := newShardIndex(shards)
+		shardIngresses := make([]namespacedNameSet, len(shards))
+		for i := range shardIngresses {
+			shardIngresses[i] = namespacedNameSet{}
+		}
+		for i := range result.HTTPRoutes {
+			route := &result.HTTPRoutes[i]
+			if route.Gateway() != key {
+				continue
+			}
+			i := index.routeShard(*route)
+			shard := &shards[i]
+			route.GatewayName = shard.Name
+			for _, source := range routeSources(*route) {
+				shard.Ingresses = shardIngresses[i].add(shard.Ingresses, source)
+			}
+		}
+
+		for i := range result.StreamRoutes {
+			route := &result.StreamRoutes[i]
+			if route.Gateway() != key {
+				continue
+			}
+			i := streamRouteShard(*route, shards)
+			shard := &shards[i]
+			route.GatewayName = shard.Name
+			for _, backend := range route.Backends {
+				shard.Ingresses = shardIngresses[i].add(shard.Ingresses, backend.Source)
+			}
+		}
+
+		msg := fmt.Sprintf("Gateway %s has %d listeners, more than the %d of a Gateway, it is sharded into %d Gateways with addresses of their own", key, total, maxGatewayListeners, len(shards))
+		if strategy != ListenerPerCertificate {
+			msg += ", --listener-strategy=certificate may share listeners between the hosts of wildcard certificates instead"
+		}
+		notes = append(notes, notifications.NewWarning("%s", msg))
+		for _, shard := range shards {
+			var hosts []string
+			for _, l := range shard.Listeners {
+				hostname := l.Hostname
+				if hostname == "" {
+					hostname = "*"
+				}
+				hosts = append(hosts, hostname)
+			}
+			notes = append(notes, notifications.NewInfo("Gateway %s/%s serves %s", shard.Namespace, shard.Name, strings.Join(hosts, ", ")))
+		}
+		gateways = append(gateways, shards...)
+	}
+	result.Gateways = gateways
+	return notes
+}
+
+// gatewayListenerCount returns the number of Gateway listeners emitted for
+// a listener.
+func gatewayListenerCount(l ir.Listener) int {
+	if l.Stream {
+		return len(l.ExtraPorts)
+	}
+	if len(l.CertificateRefs) == 0 || l.HTTP == ir.HTTPOmit {
+		return 1 + len(l.ExtraPorts)
+	}
+	return 2 + len(l.ExtraPorts)
+}
+
+// shardIndex finds the shards of the routes of a sharded Gateway without
+// scanning the listeners of every shard for each route.
+type shardIndex struct {
+	// sections and hostnames are the first shards with a listener of each
+	// section name and hostname.
+	sections  map[string]int
+	hostnames map[string]int
+	wildcards []shardWildcard
+	catchAll  int
+}
+
+// shardWildcard is a wildcard hostname of the listeners of a shard.
+type shardWildcard struct {
+	suffix string
+	shard  int
+}
+
+func newShardIndex(shards []ir.Gateway) shardIndex {
+	index := shardIndex{sections: map[string]int{}, hostnames: map[string]int{}}
+	first := func(m map[string]int, key string, shard int) {
+		if _, ok := m[key]; !ok {
+			m[key] = shard
+		}
+	}
+	for i, shard := range shards {
+		for _, l := range shard.Listeners {
+			first(index.sections, l.SectionName("http"), i)
+			first(index.sections, l.SectionName("https"), i)
+			first(index.hostnames, l.Hostname, i)
+			switch {
+			case strings.HasPrefix(l.Hostname, "*."):
+				index.wildcards = append(index.wildcards, shardWildcard{suffix: l.Hostname[1:], shard: i})
+			case l.Hostname == "":
+				index.catchAll = i
+			}
+		}
+	}
+	return index
+}
+
+// routeShard returns the index of the shard with the listeners of the
+// route: the ones it attaches to, else the ones of its hostname, of a
+// wildcard covering it, or the catch-all listener.
+func (s shardIndex) routeShard(route ir.HTTPRoute) int {
+	shard := -1
+	for _, sectionName := range route.SectionNames {
+		if i, ok := s.sections[sectionName]; ok && (shard < 0 || i < shard) {
+			shard = i
+		}
+	}
+	if shard >= 0 {
+		return shard
+	}
+	if i, ok := s.hostnames[route.Hostname]; ok {
+		return i
+	}
+	for _, wildcard := range s.wildcards {
+		if strings.HasSuffix(route.Hostname, wildcard.suffix) {
+			return wildcard.shard
+		}
+	}
+	return s.catchAll
+}
+
+// streamRouteShard returns the index of the shard with the listener port
+// the stream route attaches to.
+func streamRouteShard(route ir.StreamRoute, shards []ir.Gateway) int {
+	for i, shard := range shards {
+		for _, l := range shard.Listeners {
+			for _, p := range l.ExtraPorts {
+				if slices.Contains(route.SectionNames, l.PortSectionName(p)) {
+					return i
+				}
+			}
+		}
+	}
+	return 0
+}
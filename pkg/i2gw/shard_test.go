@@ -0,0 +1,135 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_shardGateways(t *testing.T) {
+	cert := []gatewayv1.SecretObjectReference{{Name: "cert"}}
+	source := func(i int) types.NamespacedName {
+		return types.NamespacedName{Namespace: "default", Name: fmt.Sprintf("ingress%d", i)}
+	}
+	route := func(name, hostname string, i int, sectionNames ...string) ir.HTTPRoute {
+		return ir.HTTPRoute{
+			Namespace:    "default",
+			Name:         name,
+			GatewayName:  "nginx",
+			Hostname:     hostname,
+			SectionNames: sectionNames,
+			Rules:        []ir.HTTPRouteRule{{Backends: []ir.Backend{{Source: source(i)}}}},
+		}
+	}
+
+	// 40 hosts with HTTP and HTTPS listeners, 80 listeners in total.
+	var listeners []ir.Listener
+	for i := 0; i < 40; i++ {
+		listeners = append(listeners, ir.Listener{Name: fmt.Sprintf("host%d", i), Hostname: fmt.Sprintf("host%d.example.com", i), CertificateRefs: cert})
+	}
+	listeners = append(listeners, ir.Listener{Name: "wildcard", Hostname: "*.apps.example.com"}, ir.Listener{Name: "all-hosts"})
+
+	testCases := []struct {
+		name           string
+		listeners      []ir.Listener
+		routes         []ir.HTTPRoute
+		expectedShards map[string]int
+		expectedRoutes map[string]string
+		expectedSource map[string][]types.NamespacedName
+		expectedNotes  int
+	}{
+		{
+			name:           "gateway within limits",
+			listeners:      listeners[:32],
+			routes:         []ir.HTTPRoute{route("host0", "host0.example.com", 0)},
+			expectedShards: map[string]int{"nginx": 32},
+			expectedRoutes: map[string]string{"host0": "nginx"},
+		},
+		{
+			name:      "host listeners are kept together",
+			listeners: listeners,
+			routes: []ir.HTTPRoute{
+				route("host0", "host0.example.com", 0),
+				route("host35", "host35.example.com", 35),
+				route("redirect", "host39.example.com", 39, "host39-http"),
+				route("app", "foo.apps.example.com", 40),
+				route("default", "", 41),
+				route("unknown", "unknown.example.com", 42),
+			},
+			expectedShards: map[string]int{"nginx-1": 32, "nginx-2": 10},
+			expectedRoutes: map[string]string{
+				"host0":    "nginx-1",
+				"host35":   "nginx-2",
+				"redirect": "nginx-2",
+				"app":      "nginx-2",
+				"default":  "nginx-2",
+				"unknown":  "nginx-2",
+			},
+			expectedSource: map[string][]types.NamespacedName{
+				"nginx-1": {source(0)},
+				"nginx-2": {source(35), source(39), source(40), source(41), source(42)},
+			},
+			expectedNotes: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := ir.IR{
+				Gateways:   []ir.Gateway{{Namespace: "default", Name: "nginx", GatewayClassName: "nginx", Listeners: tc.listeners}},
+				HTTPRoutes: tc.routes,
+			}
+			notes := shardGateways(&result, ListenerPerHost)
+
+			shards := map[string]int{}
+			sources := map[string][]types.NamespacedName{}
+			for _, gw := range result.Gateways {
+				if gw.GatewayClassName != "nginx" {
+					t.Errorf("Gateway %s has class %q, expected nginx", gw.Name, gw.GatewayClassName)
+				}
+				shards[gw.Name] = len(gw.Listeners)
+				if len(gw.Ingresses) > 0 {
+					sources[gw.Name] = gw.Ingresses
+				}
+			}
+			if diff := cmp.Diff(tc.expectedShards, shards); diff != "" {
+				t.Errorf("Unexpected listeners by Gateway, diff (-want +got): %s", diff)
+			}
+			if tc.expectedSource != nil {
+				if diff := cmp.Diff(tc.expectedSource, sources); diff != "" {
+					t.Errorf("Unexpected Ingresses by Gateway, diff (-want +got): %s", diff)
+				}
+			}
+
+			routes := map[string]string{}
+			for _, route := range result.HTTPRoutes {
+				routes[route.Name] = route.GatewayName
+			}
+			if diff := cmp.Diff(tc.expectedRoutes, routes); diff != "" {
+				t.Errorf("Unexpected Gateways by route, diff (-want +got): %s", diff)
+			}
+			if len(notes) != tc.expectedNotes {
+				t.Errorf("Expected %d notifications, got %d: %v", tc.expectedNotes, len(notes), notes)
+			}
+		})
+	}
+}
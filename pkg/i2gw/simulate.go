@@ -0,0 +1,468 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// SimulateOptions configures Simulate.
+type SimulateOptions struct {
+	// ConvertOptions select the Ingresses and how they are converted.
+	ConvertOptions ConvertOptions
+	// ManifestFile, if set, is the path of a manifest whose Gateways and
+	// HTTPRoutes route the request instead of the conversion of the
+	// Ingresses.
+	ManifestFile string
+	Request      TrafficRequest
+}
+
+// SimulatedMatch is a match of a rule of an HTTPRoute attached to a listener
+// of a Gateway that matches the simulated request.
+type SimulatedMatch struct {
+	Gateway   string
+	Listener  string
+	HTTPRoute string
+	Rule      int
+	Match     int
+	// Decision is how the rule routes the request.
+	Decision TrafficDecision
+}
+
+// Simulation is how the Gateways and HTTPRoutes route a request.
+type Simulation struct {
+	Request TrafficRequest
+	// Matches are the matches of the request by decreasing precedence. The
+	// first one routes the request.
+	Matches []SimulatedMatch
+}
+
+// Decision returns how the request is routed.
+func (s Simulation) Decision() TrafficDecision {
+	if len(s.Matches) == 0 {
+		return TrafficDecision{}
+	}
+	return s.Matches[0].Decision
+}
+
+// Simulate routes the request with the Gateways and HTTPRoutes the
+// Ingresses are converted to, or those of the manifest file, following the
+// precedence rules of Gateway API, and returns every match of the request.
+// Filters other than redirects are not applied to the request.
+func Simulate(ctx context.Context, opts SimulateOptions) (Simulation, error) {
+	simulation := Simulation{Request: opts.Request}
+	var input inputResources
+	if opts.ManifestFile == "" {
+		target, err := lookupTargetImplementation(opts.ConvertOptions.TargetImplementation)
+		if err != nil {
+			return simulation, err
+		}
+		input, _, err = readConvertInput(ctx, opts.ConvertOptions, target)
+		if err != nil {
+			return simulation, err
+		}
+	}
+	gateways, httpRoutes, err := routingResources(ctx, opts.ConvertOptions, input, opts.ManifestFile)
+	if err != nil {
+		return simulation, err
+	}
+
+	for _, candidate := range gatewayRequestCandidates(gateways, httpRoutes, opts.Request) {
+		simulation.Matches = append(simulation.Matches, SimulatedMatch{
+			Gateway:   candidate.gateway.Namespace + "/" + candidate.gateway.Name,
+			Listener:  string(candidate.listener),
+			HTTPRoute: candidate.route.Namespace + "/" + candidate.route.Name,
+			Rule:      candidate.rule,
+			Match:     candidate.match,
+			Decision:  candidate.decision(opts.Request),
+		})
+	}
+	return simulation, nil
+}
+
+// WriteSimulation writes the decision of the simulation, followed by every
+// match of the request by decreasing precedence.
+func WriteSimulation(w io.Writer, simulation Simulation) {
+	fmt.Fprintf(w, "%s\n", simulation.Request)
+	fmt.Fprintf(w, "  Routed to: %s\n", simulation.Decision())
+	if len(simulation.Matches) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "  Matches, by precedence:\n")
+	for i, match := range simulation.Matches {
+		decision := match.Decision
+		decision.Route = ""
+		fmt.Fprintf(w, "  %d. HTTPRoute %s rule %d match %d on Gateway %s listener %s: %s\n",
+			i+1, match.HTTPRoute, match.Rule, match.Match, match.Gateway, match.Listener, decision)
+	}
+}
+
+// RunSimulate routes a request as the simulate command does. The Ingresses
+// are read from the cluster of the current kubeconfig if opts has no input
+// file nor manifest file.
+func RunSimulate(opts SimulateOptions, method, rawURL string, headers []string) {
+	request, err := NewTrafficRequest(method, rawURL, headers)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	opts.Request = request
+
+	if opts.ManifestFile == "" && opts.ConvertOptions.InputFile == "" {
+		cl, err := newClient("", client.Options{})
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		opts.ConvertOptions.Client = cl
+	}
+
+	ctx, cancel := runContext(0)
+	defer cancel()
+	simulation, err := Simulate(ctx, opts)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	WriteSimulation(os.Stdout, simulation)
+}
+
+// httpRouteCandidate is a match of a rule of an HTTPRoute matching a
+// request.
+type httpRouteCandidate struct {
+	hostScore   int
+	exact       bool
+	pathLength  int
+	method      bool
+	headers     int
+	queryParams int
+	gateway     *gatewayv1.Gateway
+	listener    gatewayv1.SectionName
+	route       *gatewayv1.HTTPRoute
+	rule        int
+	match       int
+}
+
+// compareHTTPRouteCandidates orders the matches of HTTPRoutes by the
+// precedence of Gateway API: the most specific hostname, exact paths, the
+// longest paths, methods, and the most header and query parameter matches
+// first, then the oldest routes, by name on ties, and their first rules.
+func compareHTTPRouteCandidates(a, b httpRouteCandidate) int {
+	switch {
+	case a.hostScore != b.hostScore:
+		return a.hostScore - b.hostScore
+	case a.exact != b.exact:
+		if a.exact {
+			return 1
+		}
+		return -1
+	case a.pathLength != b.pathLength:
+		return a.pathLength - b.pathLength
+	case a.method != b.method:
+		if a.method {
+			return 1
+		}
+		return -1
+	case a.headers != b.headers:
+		return a.headers - b.headers
+	case a.queryParams != b.queryParams:
+		return a.queryParams - b.queryParams
+	}
+	if a.route != b.route {
+		ta, tb := a.route.CreationTimestamp, b.route.CreationTimestamp
+		if !ta.Equal(&tb) {
+			if ta.Before(&tb) {
+				return 1
+			}
+			return -1
+		}
+		na, nb := a.route.Namespace+"/"+a.route.Name, b.route.Namespace+"/"+b.route.Name
+		if na < nb {
+			return 1
+		}
+		return -1
+	}
+	if a.rule != b.rule {
+		return b.rule - a.rule
+	}
+	return b.match - a.match
+}
+
+// routeGatewayRequest routes the request as specified by Gateway API, with
+// the rule of the match of highest precedence.
+func routeGatewayRequest(gateways []gatewayv1.Gateway, httpRoutes []gatewayv1.HTTPRoute, request TrafficRequest) TrafficDecision {
+	candidates := gatewayRequestCandidates(gateways, httpRoutes, request)
+	if len(candidates) == 0 {
+		return TrafficDecision{}
+	}
+	return candidates[0].decision(request)
+}
+
+// gatewayRequestCandidates returns the matches of the routes attached to
+// the listeners of the Gateways accepting the port, protocol and host of the
+// request, by decreasing precedence.
+func gatewayRequestCandidates(gateways []gatewayv1.Gateway, httpRoutes []gatewayv1.HTTPRoute, request TrafficRequest) []httpRouteCandidate {
+	host := request.URL.Hostname()
+	protocol := gatewayv1.HTTPProtocolType
+	port := 80
+	if request.URL.Scheme == "https" {
+		protocol = gatewayv1.HTTPSProtocolType
+		port = 443
+	}
+	if p := request.URL.Port(); p != "" {
+		port, _ = strconv.Atoi(p)
+	}
+
+	var candidates []httpRouteCandidate
+	for i := range gateways {
+		gw := &gateways[i]
+		for _, listener := range gw.Spec.Listeners {
+			if listener.Protocol != protocol || int(listener.Port) != port {
+				continue
+			}
+			listenerHostname := ""
+			if listener.Hostname != nil {
+				listenerHostname = string(*listener.Hostname)
+			}
+			listenerScore := trafficHostScore(listenerHostname, host, false)
+			if listenerScore == 0 {
+				continue
+			}
+			for j := range httpRoutes {
+				route := &httpRoutes[j]
+				if !attachesToListener(route, gw, listener) {
+					continue
+				}
+				hostScore := listenerScore
+				if len(route.Spec.Hostnames) > 0 {
+					hostScore = 0
+					for _, hostname := range route.Spec.Hostnames {
+						hostScore = max(hostScore, trafficHostScore(string(hostname), host, false))
+					}
+					if hostScore == 0 {
+						continue
+					}
+				}
+				for k, rule := range route.Spec.Rules {
+					matches := rule.Matches
+					if len(matches) == 0 {
+						matches = []gatewayv1.HTTPRouteMatch{{}}
+					}
+					for l, match := range matches {
+						candidate, ok := matchHTTPRouteRequest(match, request)
+						if !ok {
+							continue
+						}
+						candidate.hostScore, candidate.gateway, candidate.listener = hostScore, gw, listener.Name
+						candidate.route, candidate.rule, candidate.match = route, k, l
+						candidates = append(candidates, candidate)
+					}
+				}
+			}
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b httpRouteCandidate) int {
+		return compareHTTPRouteCandidates(b, a)
+	})
+	return candidates
+}
+
+// decision returns how the rule of the candidate routes the request.
+func (c httpRouteCandidate) decision(request TrafficRequest) TrafficDecision {
+	rule := c.route.Spec.Rules[c.rule]
+	decision := TrafficDecision{Route: fmt.Sprintf("HTTPRoute %s/%s rule %d", c.route.Namespace, c.route.Name, c.rule)}
+	for _, filter := range rule.Filters {
+		if filter.Type == gatewayv1.HTTPRouteFilterRequestRedirect && filter.RequestRedirect != nil {
+			decision.Redirect = trafficRedirectURL(request, *filter.RequestRedirect)
+			return decision
+		}
+	}
+	for _, ref := range rule.BackendRefs {
+		if ref.Weight != nil && *ref.Weight == 0 {
+			continue
+		}
+		backend := backendRefTrafficBackend(c.route.Namespace, ref.BackendObjectReference)
+		if !containsTrafficBackend(decision.Backends, backend) {
+			decision.Backends = append(decision.Backends, backend)
+		}
+	}
+	return decision
+}
+
+// backendRefTrafficBackend returns the backend of a backendRef of an
+// HTTPRoute of namespace.
+func backendRefTrafficBackend(namespace string, ref gatewayv1.BackendObjectReference) TrafficBackend {
+	backend := TrafficBackend{Kind: "Service", Namespace: namespace, Name: string(ref.Name)}
+	if ref.Kind != nil {
+		backend.Kind = string(*ref.Kind)
+	}
+	if ref.Namespace != nil {
+		backend.Namespace = string(*ref.Namespace)
+	}
+	if ref.Port != nil {
+		backend.Port = strconv.Itoa(int(*ref.Port))
+	}
+	return backend
+}
+
+// attachesToListener tells whether a parentRef of the route attaches it to
+// the listener of the Gateway, and the listener allows the namespace of the
+// route.
+func attachesToListener(route *gatewayv1.HTTPRoute, gw *gatewayv1.Gateway, listener gatewayv1.Listener) bool {
+	attached := false
+	for _, ref := range route.Spec.ParentRefs {
+		namespace := route.Namespace
+		if ref.Namespace != nil {
+			namespace = string(*ref.Namespace)
+		}
+		if (ref.Kind != nil && *ref.Kind != "Gateway") || string(ref.Name) != gw.Name || namespace != gw.Namespace {
+			continue
+		}
+		if (ref.SectionName == nil || *ref.SectionName == listener.Name) && (ref.Port == nil || *ref.Port == listener.Port) {
+			attached = true
+			break
+		}
+	}
+	if !attached {
+		return false
+	}
+	if listener.AllowedRoutes == nil || listener.AllowedRoutes.Namespaces == nil || listener.AllowedRoutes.Namespaces.From == nil {
+		return route.Namespace == gw.Namespace
+	}
+	namespaces := listener.AllowedRoutes.Namespaces
+	switch *namespaces.From {
+	case gatewayv1.NamespacesFromAll:
+		return true
+	case gatewayv1.NamespacesFromSelector:
+		if namespaces.Selector == nil {
+			return false
+		}
+		// The name label is the only label of namespaces known.
+		selector, err := metav1.LabelSelectorAsSelector(namespaces.Selector)
+		return err == nil && selector.Matches(labels.Set{corev1.LabelMetadataName: route.Namespace})
+	default:
+		return route.Namespace == gw.Namespace
+	}
+}
+
+// matchHTTPRouteRequest tells whether the match matches the request, with
+// the precedence of the match.
+func matchHTTPRouteRequest(match gatewayv1.HTTPRouteMatch, request TrafficRequest) (httpRouteCandidate, bool) {
+	var candidate httpRouteCandidate
+	path := request.URL.Path
+	pathType, value := gatewayv1.PathMatchPathPrefix, "/"
+	if match.Path != nil {
+		if match.Path.Type != nil {
+			pathType = *match.Path.Type
+		}
+		if match.Path.Value != nil {
+			value = *match.Path.Value
+		}
+	}
+	switch pathType {
+	case gatewayv1.PathMatchExact:
+		if path != value {
+			return candidate, false
+		}
+		candidate.exact = true
+	case gatewayv1.PathMatchRegularExpression:
+		if !regexpMatches(value, path) {
+			return candidate, false
+		}
+	default:
+		if !prefixPathMatches(value, path) {
+			return candidate, false
+		}
+	}
+	candidate.pathLength = len(value)
+
+	if match.Method != nil {
+		if string(*match.Method) != request.Method {
+			return candidate, false
+		}
+		candidate.method = true
+	}
+	for _, header := range match.Headers {
+		values, ok := request.Headers[http.CanonicalHeaderKey(string(header.Name))]
+		if !ok || !stringMatches(header.Type == nil || *header.Type == gatewayv1.HeaderMatchExact, header.Value, strings.Join(values, ",")) {
+			return candidate, false
+		}
+	}
+	candidate.headers = len(match.Headers)
+	query := request.URL.Query()
+	for _, param := range match.QueryParams {
+		values, ok := query[string(param.Name)]
+		if !ok || !stringMatches(param.Type == nil || *param.Type == gatewayv1.QueryParamMatchExact, param.Value, values[0]) {
+			return candidate, false
+		}
+	}
+	candidate.queryParams = len(match.QueryParams)
+	return candidate, true
+}
+
+func stringMatches(exact bool, pattern, value string) bool {
+	if exact {
+		return pattern == value
+	}
+	return regexpMatches(pattern, value)
+}
+
+// regexpMatches tells whether the regular expression matches the whole
+// value. Invalid expressions match nothing.
+func regexpMatches(pattern, value string) bool {
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	return err == nil && re.MatchString(value)
+}
+
+// trafficRedirectURL returns the URL a RequestRedirect filter redirects the
+// request to. Path modifiers are ignored, since redirects are only followed
+// to compare their destination.
+func trafficRedirectURL(request TrafficRequest, redirect gatewayv1.HTTPRequestRedirectFilter) string {
+	u := *request.URL
+	if redirect.Scheme != nil {
+		u.Scheme = *redirect.Scheme
+		if redirect.Port == nil {
+			u.Host = u.Hostname()
+		}
+	}
+	if redirect.Hostname != nil {
+		u.Host = string(*redirect.Hostname)
+		if p := request.URL.Port(); p != "" && redirect.Scheme == nil {
+			u.Host += ":" + p
+		}
+	}
+	if redirect.Port != nil {
+		u.Host = u.Hostname() + ":" + strconv.Itoa(int(*redirect.Port))
+	}
+	if redirect.Path != nil && redirect.Path.ReplaceFullPath != nil {
+		u.Path = *redirect.Path.ReplaceFullPath
+	}
+	return u.String()
+}
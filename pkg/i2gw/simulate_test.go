@@ -0,0 +1,135 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_Simulate(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	iExact := networkingv1.PathTypeExact
+	web := ingressWithPath("web", "/app", &iPrefix, serviceBackend("web", 80), nil)
+	paths := &web.Spec.Rules[0].HTTP.Paths
+	*paths = append(*paths, networkingv1.HTTPIngressPath{Path: "/app/login", PathType: &iExact, Backend: serviceBackend("login", 80)})
+
+	testCases := []struct {
+		name    string
+		method  string
+		url     string
+		headers []string
+		want    string
+	}{
+		{
+			name: "matches by precedence",
+			url:  "http://example.com/app/login",
+			want: `GET http://example.com/app/login
+  Routed to: Service test/login:80 (HTTPRoute test/example-com rule 0)
+  Matches, by precedence:
+  1. HTTPRoute test/example-com rule 0 match 0 on Gateway test/nginx listener example-com-http: Service test/login:80
+  2. HTTPRoute test/example-com rule 1 match 0 on Gateway test/nginx listener example-com-http: Service test/web:80
+`,
+		},
+		{
+			name: "prefix match",
+			url:  "http://example.com/app/cart",
+			want: `GET http://example.com/app/cart
+  Routed to: Service test/web:80 (HTTPRoute test/example-com rule 1)
+  Matches, by precedence:
+  1. HTTPRoute test/example-com rule 1 match 0 on Gateway test/nginx listener example-com-http: Service test/web:80
+`,
+		},
+		{
+			name:    "method and headers",
+			method:  "post",
+			url:     "http://example.com/app/cart",
+			headers: []string{"X-Debug: true"},
+			want: `POST http://example.com/app/cart X-Debug:true
+  Routed to: Service test/web:80 (HTTPRoute test/example-com rule 1)
+  Matches, by precedence:
+  1. HTTPRoute test/example-com rule 1 match 0 on Gateway test/nginx listener example-com-http: Service test/web:80
+`,
+		},
+		{
+			name: "no match",
+			url:  "http://other.example.com/app",
+			want: `GET http://other.example.com/app
+  Routed to: not routed
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request, err := NewTrafficRequest(tc.method, tc.url, tc.headers)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			simulation, err := Simulate(context.Background(), SimulateOptions{
+				ConvertOptions: ConvertOptions{Ingresses: []networkingv1.Ingress{web}},
+				Request:        request,
+			})
+			if err != nil {
+				t.Fatalf("Failed to simulate: %v", err)
+			}
+			var buf bytes.Buffer
+			WriteSimulation(&buf, simulation)
+			if diff := cmp.Diff(tc.want, buf.String()); diff != "" {
+				t.Errorf("Unexpected simulation, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func Test_matchHTTPRouteRequest(t *testing.T) {
+	request := TrafficRequest{Method: http.MethodGet, Headers: http.Header{"X-Canary": {"always"}}}
+	parsed, _ := NewTrafficRequest("", "https://example.com/api/v1?debug=true", nil)
+	request.URL = parsed.URL
+
+	testCases := []struct {
+		name      string
+		match     string
+		wantMatch bool
+	}{
+		{name: "prefix", match: `{"path":{"type":"PathPrefix","value":"/api"}}`, wantMatch: true},
+		{name: "prefix of another path element", match: `{"path":{"type":"PathPrefix","value":"/ap"}}`, wantMatch: false},
+		{name: "regular expression", match: `{"path":{"type":"RegularExpression","value":"/api/v[0-9]+"}}`, wantMatch: true},
+		{name: "header", match: `{"headers":[{"name":"x-canary","value":"always"}]}`, wantMatch: true},
+		{name: "other header value", match: `{"headers":[{"name":"X-Canary","value":"never"}]}`, wantMatch: false},
+		{name: "query parameter", match: `{"queryParams":[{"name":"debug","value":"true"}]}`, wantMatch: true},
+		{name: "method", match: `{"method":"POST"}`, wantMatch: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var match gatewayv1.HTTPRouteMatch
+			if err := json.Unmarshal([]byte(tc.match), &match); err != nil {
+				t.Fatalf("Failed to decode match: %v", err)
+			}
+			if _, got := matchHTTPRouteRequest(match, request); got != tc.wantMatch {
+				t.Errorf("Expected match %t, got %t", tc.wantMatch, got)
+			}
+		})
+	}
+}
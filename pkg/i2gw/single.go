@@ -0,0 +1,123 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"k8s.io/apimachinery/pkg/types"
+	apimachineryvalidation "k8s.io/apimachinery/pkg/util/validation"
+)
+
+func validateSingleGateway(class, gatewayNamespace string) error {
+	if class == "" {
+		return nil
+	}
+	if msgs := apimachineryvalidation.IsDNS1123Subdomain(class); len(msgs) > 0 {
+		return fmt.Errorf("invalid single Gateway class %q: %s", class, strings.Join(msgs, ", "))
+	}
+	if gatewayNamespace == "" {
+		return fmt.Errorf("a single Gateway requires a Gateway namespace shared by every namespace")
+	}
+	return nil
+}
+
+// mergeGateways merges the Gateways of every IngressClass into a single
+// Gateway named after class, of the GatewayClass class, and attaches every
+// route to it, for clusters consolidating several Ingress controllers into
+// one Gateway implementation. The Gateways all live in the shared Gateway
+// namespace. Listeners of the same host are merged, keeping the TLS
+// settings of the first Gateway serving it.
+func mergeGateways(result *ir.IR, class string) []Notification {
+	if len(result.Gateways) == 0 {
+		return nil
+	}
+	var notes []Notification
+	merged := ir.Gateway{
+		Namespace:        result.Gateways[0].Namespace,
+		Name:             class,
+		GatewayClassName: class,
+		HTTPPort:         result.Gateways[0].HTTPPort,
+		HTTPSPort:        result.Gateways[0].HTTPSPort,
+	}
+	// gateways are the merged Gateways, whose ports the ports of the
+	// parentRefs of their routes are remapped from.
+	gateways := map[types.NamespacedName]ir.Gateway{}
+	// hostClasses are the classes of the Gateways the listeners of the
+	// merged Gateway are taken from, by host.
+	hostClasses := map[string]string{}
+	for _, gw := range result.Gateways {
+		gateways[types.NamespacedName{Namespace: gw.Namespace, Name: gw.Name}] = gw
+		if gw.HTTPPort != merged.HTTPPort || gw.HTTPSPort != merged.HTTPSPort {
+			notes = append(notes, notifications.NewWarning("Gateway %s/%s listens on ports %d and %d, the single Gateway %s/%s listens on ports %d and %d instead", gw.Namespace, gw.Name, gw.HTTPPort, gw.HTTPSPort, merged.Namespace, merged.Name, merged.HTTPPort, merged.HTTPSPort))
+		}
+		for _, l := range gw.Listeners {
+			i := listenerIndex(merged.Listeners, l.Hostname)
+			if i < 0 {
+				merged.Listeners = append(merged.Listeners, l)
+				hostClasses[l.Hostname] = gw.GatewayClassName
+				continue
+			}
+			if !sameListenerTLS(merged.Listeners[i], l) {
+				notes = append(notes, notifications.NewWarning("Host %q is served by the Gateways of classes %s and %s with different TLS settings, the ones of class %s are kept in the single Gateway", l.Hostname, hostClasses[l.Hostname], gw.GatewayClassName, hostClasses[l.Hostname]))
+			}
+			for _, namespace := range l.AllowedNamespaces {
+				if !slices.Contains(merged.Listeners[i].AllowedNamespaces, namespace) {
+					merged.Listeners[i].AllowedNamespaces = append(merged.Listeners[i].AllowedNamespaces, namespace)
+				}
+			}
+			merged.Listeners[i].ExtraPorts = mergeListenerPorts(merged.Listeners[i].ExtraPorts, l.ExtraPorts)
+			merged.Listeners[i].Stream = merged.Listeners[i].Stream && l.Stream
+		}
+		for _, source := range gw.Ingresses {
+			if !containsNamespacedName(merged.Ingresses, source) {
+				merged.Ingresses = append(merged.Ingresses, source)
+			}
+		}
+	}
+
+	for i := range result.HTTPRoutes {
+		route := &result.HTTPRoutes[i]
+		gw, ok := gateways[route.Gateway()]
+		if !ok {
+			continue
+		}
+		route.GatewayName = merged.Name
+		for section, port := range route.SectionPorts {
+			switch port {
+			case gw.HTTPPort:
+				route.SectionPorts[section] = merged.HTTPPort
+			case gw.HTTPSPort:
+				route.SectionPorts[section] = merged.HTTPSPort
+			}
+		}
+	}
+	for i := range result.StreamRoutes {
+		route := &result.StreamRoutes[i]
+		if _, ok := gateways[route.Gateway()]; ok {
+			route.GatewayName = merged.Name
+		}
+	}
+	if len(result.Gateways) > 1 {
+		notes = append(notes, notifications.NewInfo("The Gateways of %d IngressClasses are merged into the single Gateway %s/%s", len(result.Gateways), merged.Namespace, merged.Name))
+	}
+	result.Gateways = []ir.Gateway{merged}
+	return notes
+}
@@ -0,0 +1,96 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_mergeGateways(t *testing.T) {
+	web := types.NamespacedName{Namespace: "web", Name: "web"}
+	api := types.NamespacedName{Namespace: "api", Name: "api"}
+	result := ir.IR{
+		Gateways: []ir.Gateway{{
+			Namespace:        "infra",
+			Name:             "nginx",
+			GatewayClassName: "nginx",
+			HTTPPort:         80,
+			HTTPSPort:        443,
+			Listeners: []ir.Listener{
+				{Name: "example-com", Hostname: "example.com", CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "cert"}}, AllowedNamespaces: []string{"web"}},
+			},
+			Ingresses: []types.NamespacedName{web},
+		}, {
+			Namespace:        "infra",
+			Name:             "traefik",
+			GatewayClassName: "traefik",
+			HTTPPort:         8080,
+			HTTPSPort:        8443,
+			Listeners: []ir.Listener{
+				{Name: "example-com", Hostname: "example.com", AllowedNamespaces: []string{"api"}},
+				{Name: "api-example-com", Hostname: "api.example.com", AllowedNamespaces: []string{"api"}},
+			},
+			Ingresses: []types.NamespacedName{api},
+		}},
+		HTTPRoutes: []ir.HTTPRoute{
+			{Namespace: "web", Name: "example-com", GatewayName: "nginx", GatewayNamespace: "infra"},
+			{Namespace: "api", Name: "api-example-com", GatewayName: "traefik", GatewayNamespace: "infra", SectionNames: []string{"api-example-com-http"}, SectionPorts: map[string]int32{"api-example-com-http": 8080}},
+		},
+	}
+
+	notes := mergeGateways(&result, "envoy")
+
+	expectedGateways := []ir.Gateway{{
+		Namespace:        "infra",
+		Name:             "envoy",
+		GatewayClassName: "envoy",
+		HTTPPort:         80,
+		HTTPSPort:        443,
+		Listeners: []ir.Listener{
+			{Name: "example-com", Hostname: "example.com", CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "cert"}}, AllowedNamespaces: []string{"web", "api"}},
+			{Name: "api-example-com", Hostname: "api.example.com", AllowedNamespaces: []string{"api"}},
+		},
+		Ingresses: []types.NamespacedName{web, api},
+	}}
+	if diff := cmp.Diff(expectedGateways, result.Gateways); diff != "" {
+		t.Errorf("Unexpected Gateways, diff (-want +got): %s", diff)
+	}
+	expectedRoutes := []ir.HTTPRoute{
+		{Namespace: "web", Name: "example-com", GatewayName: "envoy", GatewayNamespace: "infra"},
+		{Namespace: "api", Name: "api-example-com", GatewayName: "envoy", GatewayNamespace: "infra", SectionNames: []string{"api-example-com-http"}, SectionPorts: map[string]int32{"api-example-com-http": 80}},
+	}
+	if diff := cmp.Diff(expectedRoutes, result.HTTPRoutes); diff != "" {
+		t.Errorf("Unexpected HTTPRoutes, diff (-want +got): %s", diff)
+	}
+
+	var messages []string
+	for _, n := range notes {
+		messages = append(messages, string(n.Type)+": "+n.Message)
+	}
+	expectedMessages := []string{
+		"WARNING: Gateway infra/traefik listens on ports 8080 and 8443, the single Gateway infra/envoy listens on ports 80 and 443 instead",
+		`WARNING: Host "example.com" is served by the Gateways of classes nginx and traefik with different TLS settings, the ones of class nginx are kept in the single Gateway`,
+		"INFO: The Gateways of 2 IngressClasses are merged into the single Gateway infra/envoy",
+	}
+	if diff := cmp.Diff(expectedMessages, messages); diff != "" {
+		t.Errorf("Unexpected notifications, diff (-want +got): %s", diff)
+	}
+}
@@ -0,0 +1,267 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// OutputSink receives the results of conversions: once for Convert, and
+// once per namespace for ConvertStream. It is closed once every result was
+// written, for sinks writing indexes or flushing what they buffered.
+type OutputSink interface {
+	Write(ctx context.Context, resources Resources, report Report) error
+	Close() error
+}
+
+// SinkFunc adapts a function to an OutputSink with nothing to do on Close.
+type SinkFunc func(ctx context.Context, resources Resources, report Report) error
+
+func (f SinkFunc) Write(ctx context.Context, resources Resources, report Report) error {
+	return f(ctx, resources, report)
+}
+
+func (f SinkFunc) Close() error {
+	return nil
+}
+
+// ConvertTo converts the Ingresses like Convert and writes the result to
+// sink, closing it. The report is returned as well, for the callers to
+// check the errors of the conversion.
+func ConvertTo(ctx context.Context, opts ConvertOptions, sink OutputSink) (Report, error) {
+	resources, report, err := Convert(ctx, opts)
+	if err != nil {
+		return report, err
+	}
+	if err := sink.Write(ctx, resources, report); err != nil {
+		return report, err
+	}
+	return report, sink.Close()
+}
+
+// ConvertStreamTo converts the Ingresses decoded from r like ConvertStream,
+// writing the result of each namespace to sink, and closes it at the end of
+// the stream.
+func ConvertStreamTo(ctx context.Context, r io.Reader, opts ConvertOptions, sink OutputSink) error {
+	err := ConvertStream(ctx, r, opts, func(resources Resources, report Report) error {
+		return sink.Write(ctx, resources, report)
+	})
+	if err != nil {
+		return err
+	}
+	return sink.Close()
+}
+
+// MultiSink returns a sink writing to every sink in order, stopping at the
+// first error, and closing all of them.
+func MultiSink(sinks ...OutputSink) OutputSink {
+	return multiSink(sinks)
+}
+
+type multiSink []OutputSink
+
+func (m multiSink) Write(ctx context.Context, resources Resources, report Report) error {
+	for _, sink := range m {
+		if err := sink.Write(ctx, resources, report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m multiSink) Close() error {
+	var errs []error
+	for _, sink := range m {
+		errs = append(errs, sink.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// WriterSinkOptions are the options of NewWriterSink.
+type WriterSinkOptions struct {
+	// Output is the format of the resources, OutputYAML by default. The
+	// graph formats write the traffic layout without the report.
+	Output OutputFormat
+	// Template, if set, renders every result instead of Output.
+	Template *template.Template
+	// Annotate precedes every resource with comments about the Ingresses
+	// it was converted from.
+	Annotate bool
+	// Clean writes the resources without status and the metadata populated
+	// by the API server.
+	Clean bool
+}
+
+// NewWriterSink returns a sink writing every result to w, as the command
+// line does to stdout.
+func NewWriterSink(w io.Writer, opts WriterSinkOptions) OutputSink {
+	return &writerSink{w: w, opts: opts}
+}
+
+type writerSink struct {
+	w    io.Writer
+	opts WriterSinkOptions
+}
+
+func (s *writerSink) Write(_ context.Context, resources Resources, report Report) error {
+	var sources map[ObjectRef][]IngressSource
+	if s.opts.Annotate {
+		sources = resources.Sources
+	}
+	switch {
+	case s.opts.Template != nil:
+		return WriteTemplate(s.w, s.opts.Template, resources, report)
+	case isGraphFormat(s.opts.Output):
+		return WriteGraph(s.w, resources, s.opts.Output)
+	case s.opts.Output == OutputList:
+		return writeListResult(s.w, resources, report, s.opts.Clean)
+	case s.opts.Output == OutputServerSideApply:
+		writeApplyResult(s.w, resources, report, sources)
+	default:
+		writeResult(s.w, resources, report, sources, s.opts.Clean)
+	}
+	return nil
+}
+
+func (s *writerSink) Close() error {
+	return nil
+}
+
+// reportSink writes only the reports of the results, for the command line
+// to write them to the terminal while the resources go elsewhere.
+type reportSink struct {
+	w io.Writer
+}
+
+func (s reportSink) Write(_ context.Context, _ Resources, report Report) error {
+	writeReport(s.w, report)
+	return nil
+}
+
+func (s reportSink) Close() error {
+	return nil
+}
+
+// DirectorySinkOptions are the options of NewDirectorySink.
+type DirectorySinkOptions struct {
+	// Layout is the layout of the directory, LayoutGitOps or
+	// LayoutNamespaces.
+	Layout OutputLayout
+	// Output is the format of the resources, OutputYAML or
+	// OutputServerSideApply.
+	Output OutputFormat
+	// Annotate precedes every resource with comments about the Ingresses
+	// it was converted from, with LayoutNamespaces.
+	Annotate bool
+	// ArgoCD annotates the resources with Argo CD sync waves, with
+	// LayoutGitOps.
+	ArgoCD bool
+	// Clean writes the resources without status and the metadata populated
+	// by the API server.
+	Clean bool
+}
+
+// NewDirectorySink returns a sink writing the results to files in dir, in
+// the layout of the options. The index of LayoutNamespaces is written on
+// Close.
+func NewDirectorySink(dir string, opts DirectorySinkOptions) (OutputSink, error) {
+	switch opts.Layout {
+	case LayoutNamespaces:
+		layout := newNamespaceLayout(dir, NamespaceLayoutOptions{Output: opts.Output, Annotate: opts.Annotate, Clean: opts.Clean})
+		return namespaceLayoutSink{layout}, nil
+	case LayoutGitOps:
+		gitOpsOpts := GitOpsOptions{Output: opts.Output, ArgoCD: opts.ArgoCD, Clean: opts.Clean}
+		return SinkFunc(func(_ context.Context, resources Resources, _ Report) error {
+			return WriteGitOpsLayout(dir, resources, gitOpsOpts)
+		}), nil
+	default:
+		return nil, fmt.Errorf("the %q layout doesn't write to a directory", opts.Layout)
+	}
+}
+
+type namespaceLayoutSink struct {
+	*namespaceLayout
+}
+
+func (s namespaceLayoutSink) Write(_ context.Context, resources Resources, report Report) error {
+	return s.add(resources, report)
+}
+
+func (s namespaceLayoutSink) Close() error {
+	return s.close()
+}
+
+// ApplySink applies the resources of every result with Server-Side Apply,
+// as Apply does, recording the objects applied.
+type ApplySink struct {
+	// Options are the options of the Apply calls. Their InputFile and
+	// Objects are ignored.
+	Options ApplyOptions
+	// Applied are the objects applied so far.
+	Applied []ObjectRef
+}
+
+// NewApplySink returns a sink applying the resources with opts.Client.
+func NewApplySink(opts ApplyOptions) *ApplySink {
+	return &ApplySink{Options: opts}
+}
+
+func (s *ApplySink) Write(ctx context.Context, resources Resources, _ Report) error {
+	var objects []unstructured.Unstructured
+	for _, obj := range resourceObjects(resources) {
+		u, err := toUnstructured(obj, true)
+		if err != nil {
+			return fmt.Errorf("failed to convert %s %s/%s: %w", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetNamespace(), obj.GetName(), err)
+		}
+		objects = append(objects, *u)
+	}
+	opts := s.Options
+	opts.InputFile = ""
+	opts.Objects = objects
+	applied, err := Apply(ctx, opts)
+	s.Applied = append(s.Applied, applied...)
+	return err
+}
+
+func (s *ApplySink) Close() error {
+	return nil
+}
+
+// MemorySink keeps the results in memory, for embedders handing the objects
+// to their own reconcilers instead of serializing them.
+type MemorySink struct {
+	// Objects are the objects of every result, in the order of Resources.Objects.
+	Objects []client.Object
+	// Reports are the reports of every result.
+	Reports []Report
+}
+
+func (s *MemorySink) Write(_ context.Context, resources Resources, report Report) error {
+	s.Objects = append(s.Objects, resources.Objects()...)
+	s.Reports = append(s.Reports, report)
+	return nil
+}
+
+func (s *MemorySink) Close() error {
+	return nil
+}
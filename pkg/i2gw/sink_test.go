@@ -0,0 +1,108 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_ConvertStreamTo(t *testing.T) {
+	ingress := func(namespace, name string) string {
+		return fmt.Sprintf(`apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: %s
+  namespace: %s
+spec:
+  ingressClassName: nginx
+  rules:
+  - host: %s.example.com
+    http:
+      paths:
+      - path: /
+        pathType: Prefix
+        backend:
+          service:
+            name: %s
+            port:
+              number: 80
+`, name, namespace, name, name)
+	}
+	input := strings.Join([]string{ingress("a", "one"), ingress("b", "two")}, "---\n")
+
+	memory := &MemorySink{}
+	var stdout bytes.Buffer
+	sink := MultiSink(memory, NewWriterSink(&stdout, WriterSinkOptions{}))
+	if err := ConvertStreamTo(context.Background(), strings.NewReader(input), ConvertOptions{}, sink); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, obj := range memory.Objects {
+		got = append(got, fmt.Sprintf("%s %s/%s", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetNamespace(), obj.GetName()))
+	}
+	want := []string{
+		"Gateway a/nginx",
+		"HTTPRoute a/one-example-com",
+		"Gateway b/nginx",
+		"HTTPRoute b/two-example-com",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected objects, diff (-want +got): %s", diff)
+	}
+	if len(memory.Reports) != 2 {
+		t.Errorf("Expected a report per namespace, got %d", len(memory.Reports))
+	}
+	if got := strings.Count(stdout.String(), "kind: HTTPRoute"); got != 2 {
+		t.Errorf("Expected 2 HTTPRoutes written, got %d in:\n%s", got, stdout.String())
+	}
+}
+
+func Test_MultiSinkClose(t *testing.T) {
+	errFirst := errors.New("first")
+	errSecond := errors.New("second")
+	closed := 0
+	closer := func(err error) OutputSink {
+		return &closeSink{close: func() error {
+			closed++
+			return err
+		}}
+	}
+
+	err := MultiSink(closer(errFirst), closer(nil), closer(errSecond)).Close()
+	if !errors.Is(err, errFirst) || !errors.Is(err, errSecond) {
+		t.Errorf("Expected both close errors, got %v", err)
+	}
+	if closed != 3 {
+		t.Errorf("Expected every sink to be closed, got %d", closed)
+	}
+}
+
+type closeSink struct {
+	SinkFunc
+	close func() error
+}
+
+func (s *closeSink) Close() error {
+	return s.close()
+}
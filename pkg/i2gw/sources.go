@@ -0,0 +1,168 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// ObjectRef identifies a generated object.
+type ObjectRef struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// IngressSource is an Ingress a generated object was converted from.
+type IngressSource struct {
+	Ingress types.NamespacedName
+	// Annotations are the converted annotations of the Ingress, as
+	// key=value pairs. They are only set for HTTPRoutes.
+	Annotations []string
+}
+
+// objectSources returns the Ingresses the Gateways and routes of the IR
+// were converted from, with the annotations converted for each route.
+func objectSources(result ir.IR, annotations map[types.NamespacedName][]string) map[ObjectRef][]IngressSource {
+	sources := map[ObjectRef][]IngressSource{}
+	for _, gw := range result.Gateways {
+		ref := ObjectRef{Kind: "Gateway", Namespace: gw.Namespace, Name: gw.Name}
+		for _, ingress := range sortedNamespacedNames(gw.Ingresses) {
+			sources[ref] = append(sources[ref], IngressSource{Ingress: ingress})
+		}
+	}
+	for _, route := range result.HTTPRoutes {
+		ref := ObjectRef{Kind: "HTTPRoute", Namespace: route.Namespace, Name: route.Name}
+		for _, ingress := range sortedNamespacedNames(routeSources(route)) {
+			sources[ref] = append(sources[ref], IngressSource{Ingress: ingress, Annotations: annotations[ingress]})
+		}
+	}
+	for _, route := range result.StreamRoutes {
+		ref := ObjectRef{Kind: streamRouteKind(route.Protocol), Namespace: route.Namespace, Name: route.Name}
+		for _, backend := range route.Backends {
+			sources[ref] = append(sources[ref], IngressSource{Ingress: backend.Source})
+		}
+	}
+	return sources
+}
+
+// routeSources returns the Ingresses a route was converted from.
+func routeSources(route ir.HTTPRoute) []types.NamespacedName {
+	seen := namespacedNameSet{}
+	sources := backendSources(route, seen)
+	for source := range route.Policies {
+		sources = seen.add(sources, source)
+	}
+	return sources
+}
+
+// backendSources returns the Ingresses of the backends of a route, adding
+// them to seen.
+func backendSources(route ir.HTTPRoute, seen namespacedNameSet) []types.NamespacedName {
+	var sources []types.NamespacedName
+	for _, rule := range route.Rules {
+		for _, backend := range rule.Backends {
+			sources = seen.add(sources, backend.Source)
+		}
+	}
+	return sources
+}
+
+// skippedIngresses returns the Ingresses none of the sources were converted
+// from.
+func skippedIngresses(ingresses []networkingv1.Ingress, sources map[ObjectRef][]IngressSource) []types.NamespacedName {
+	converted := map[types.NamespacedName]bool{}
+	for _, objectSources := range sources {
+		for _, source := range objectSources {
+			converted[source.Ingress] = true
+		}
+	}
+	var skipped []types.NamespacedName
+	for _, ingress := range ingresses {
+		name := types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}
+		if !converted[name] {
+			skipped = append(skipped, name)
+		}
+	}
+	return skipped
+}
+
+func sortedNamespacedNames(names []types.NamespacedName) []types.NamespacedName {
+	// The names are sorted by their string, formatted once.
+	keys := make([]string, len(names))
+	order := make([]int, len(names))
+	for i, name := range names {
+		keys[i] = name.String()
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return keys[order[i]] < keys[order[j]]
+	})
+	sorted := make([]types.NamespacedName, len(names))
+	for i, index := range order {
+		sorted[i] = names[index]
+	}
+	return sorted
+}
+
+// sourceComments explains which Ingresses, and which of their annotations,
+// a generated object was converted from.
+func sourceComments(sources []IngressSource) []string {
+	var comments []string
+	for _, source := range sources {
+		comment := fmt.Sprintf("converted from Ingress %s", source.Ingress)
+		switch len(source.Annotations) {
+		case 0:
+		case 1:
+			comment += ", annotation " + source.Annotations[0]
+		default:
+			comment += ", annotations " + strings.Join(source.Annotations, ", ")
+		}
+		comments = append(comments, comment)
+	}
+	return comments
+}
+
+// printAnnotated prints obj as a YAML document preceded by comments, placed
+// after the document separator so that they belong to obj.
+func printAnnotated(w io.Writer, y *printers.YAMLPrinter, obj runtime.Object, comments []string) error {
+	if len(comments) == 0 {
+		return y.PrintObj(obj, w)
+	}
+	var buf bytes.Buffer
+	if err := y.PrintObj(obj, &buf); err != nil {
+		return err
+	}
+	document, separated := strings.CutPrefix(buf.String(), "---\n")
+	if separated {
+		fmt.Fprint(w, "---\n")
+	}
+	for _, comment := range comments {
+		fmt.Fprintf(w, "# %s\n", comment)
+	}
+	_, err := fmt.Fprint(w, document)
+	return err
+}
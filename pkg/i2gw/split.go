@@ -0,0 +1,77 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+)
+
+// splitHTTPRoutes splits the HTTPRoutes with more rules than Gateway API
+// allows into HTTPRoutes named after them with a -1, -2... suffix. Rules are
+// distributed in the precedence order of their highest-precedence match.
+// Rules of equal precedence, which is decided by their order in the route,
+// are kept in the same HTTPRoute.
+func splitHTTPRoutes(result *ir.IR) []Notification {
+	var notes []Notification
+	var routes []ir.HTTPRoute
+	for _, route := range result.HTTPRoutes {
+		if len(route.Rules) <= maxHTTPRouteRules {
+			routes = append(routes, route)
+			continue
+		}
+		rules := slices.Clone(route.Rules)
+		sort.SliceStable(rules, func(i, j int) bool {
+			return matchPrecedes(topMatch(rules[i].Matches), topMatch(rules[j].Matches))
+		})
+
+		var chunks [][]ir.HTTPRouteRule
+		var chunk []ir.HTTPRouteRule
+		for i, rule := range rules {
+			tied := i > 0 && !matchPrecedes(topMatch(rules[i-1].Matches), topMatch(rule.Matches))
+			if len(chunk) == maxHTTPRouteRules || len(chunk) > 0 && !tied && len(chunk)+tieLength(rules[i:]) > maxHTTPRouteRules {
+				chunks = append(chunks, chunk)
+				chunk = nil
+			}
+			chunk = append(chunk, rule)
+		}
+		chunks = append(chunks, chunk)
+
+		for i, chunk := range chunks {
+			split := route
+			split.Name = fmt.Sprintf("%s-%d", route.Name, i+1)
+			split.Rules = chunk
+			routes = append(routes, split)
+		}
+		notes = append(notes, notifications.NewInfo("HTTPRoute %s/%s has %d rules, more than the %d of an HTTPRoute, it is split into HTTPRoutes %s-1 to %s-%d", route.Namespace, route.Name, len(rules), maxHTTPRouteRules, route.Name, route.Name, len(chunks)))
+	}
+	result.HTTPRoutes = routes
+	return notes
+}
+
+// tieLength returns the number of rules at the start of the sorted rules
+// sharing the precedence of the first one.
+func tieLength(rules []ir.HTTPRouteRule) int {
+	n := 1
+	for n < len(rules) && !matchPrecedes(topMatch(rules[0].Matches), topMatch(rules[n].Matches)) {
+		n++
+	}
+	return n
+}
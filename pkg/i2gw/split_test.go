@@ -0,0 +1,135 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_splitHTTPRoutes(t *testing.T) {
+	prefixRule := func(path string, headers ...string) ir.HTTPRouteRule {
+		match := gatewayv1.HTTPRouteMatch{Path: &gatewayv1.HTTPPathMatch{Type: ptrTo(gatewayv1.PathMatchPathPrefix), Value: ptrTo(path)}}
+		for _, h := range headers {
+			match.Headers = append(match.Headers, gatewayv1.HTTPHeaderMatch{Name: gatewayv1.HTTPHeaderName(h), Value: "always"})
+		}
+		return ir.HTTPRouteRule{Matches: []gatewayv1.HTTPRouteMatch{match}}
+	}
+	// describe lists the paths of the matches of the rules of each route,
+	// with their headers.
+	describe := func(routes []ir.HTTPRoute) map[string][]string {
+		got := map[string][]string{}
+		for _, route := range routes {
+			for _, rule := range route.Rules {
+				var matches []string
+				for _, m := range rule.Matches {
+					desc := *m.Path.Value
+					for _, h := range m.Headers {
+						desc += " " + string(h.Name)
+					}
+					matches = append(matches, desc)
+				}
+				got[route.Name] = append(got[route.Name], strings.Join(matches, ", "))
+			}
+		}
+		return got
+	}
+
+	var manyPaths []ir.HTTPRouteRule
+	var expectFirst, expectSecond []string
+	for i := 10; i < 30; i++ {
+		path := fmt.Sprintf("/path%d", i)
+		manyPaths = append(manyPaths, prefixRule(path))
+		if i < 26 {
+			expectFirst = append(expectFirst, path)
+		} else {
+			expectSecond = append(expectSecond, path)
+		}
+	}
+
+	// Fourteen longer paths, then three canary rules of equal precedence
+	// for /api that don't fit in the first route.
+	var ties []ir.HTTPRouteRule
+	var expectTiesFirst []string
+	for i := 10; i < 24; i++ {
+		path := fmt.Sprintf("/longer%d", i)
+		ties = append(ties, prefixRule(path))
+		expectTiesFirst = append(expectTiesFirst, path)
+	}
+	ties = append(ties, prefixRule("/api", "X-Canary"), prefixRule("/api", "Cookie"), prefixRule("/api", "X-Other"), prefixRule("/api"))
+
+	multiMatch := ir.HTTPRouteRule{}
+	for i := 0; i < 10; i++ {
+		multiMatch.Matches = append(multiMatch.Matches, prefixRule(fmt.Sprintf("/m%d", i)).Matches[0])
+	}
+	// Rule whose last match precedes the paths of manyPaths.
+	longestLast := ir.HTTPRouteRule{Matches: append(prefixRule("/").Matches, prefixRule("/longest/path").Matches...)}
+
+	testCases := []struct {
+		name               string
+		rules              []ir.HTTPRouteRule
+		expectRoutes       map[string][]string
+		expectNotification bool
+	}{{
+		name:         "small route untouched",
+		rules:        []ir.HTTPRouteRule{prefixRule("/"), prefixRule("/api")},
+		expectRoutes: map[string][]string{"web": {"/", "/api"}},
+	}, {
+		name:               "rules split in precedence order",
+		rules:              manyPaths,
+		expectRoutes:       map[string][]string{"web-1": expectFirst, "web-2": expectSecond},
+		expectNotification: true,
+	}, {
+		name:  "rules of equal precedence kept together",
+		rules: ties,
+		expectRoutes: map[string][]string{
+			"web-1": expectTiesFirst,
+			"web-2": {"/api X-Canary", "/api Cookie", "/api X-Other", "/api"},
+		},
+		expectNotification: true,
+	}, {
+		name:         "rule with several matches counted once",
+		rules:        []ir.HTTPRouteRule{multiMatch, prefixRule("/a"), prefixRule("/b"), prefixRule("/c"), prefixRule("/d"), prefixRule("/e"), prefixRule("/f"), prefixRule("/g")},
+		expectRoutes: map[string][]string{"web": {"/m0, /m1, /m2, /m3, /m4, /m5, /m6, /m7, /m8, /m9", "/a", "/b", "/c", "/d", "/e", "/f", "/g"}},
+	}, {
+		name:  "rules with several matches split at their highest-precedence match",
+		rules: slices.Concat(manyPaths, []ir.HTTPRouteRule{longestLast}),
+		expectRoutes: map[string][]string{
+			"web-1": slices.Concat([]string{"/, /longest/path"}, expectFirst[:15]),
+			"web-2": slices.Concat(expectFirst[15:], expectSecond),
+		},
+		expectNotification: true,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := ir.IR{HTTPRoutes: []ir.HTTPRoute{{Namespace: "test", Name: "web", Rules: tc.rules}}}
+			notes := splitHTTPRoutes(&result)
+			if diff := cmp.Diff(tc.expectRoutes, describe(result.HTTPRoutes)); diff != "" {
+				t.Errorf("Unexpected routes, diff (-want +got): %s", diff)
+			}
+			if got := len(notes) > 0; got != tc.expectNotification {
+				t.Errorf("Expected notification: %t, got %v", tc.expectNotification, notes)
+			}
+		})
+	}
+}
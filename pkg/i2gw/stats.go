@@ -0,0 +1,212 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Statuses of the Ingresses in the conversion statistics.
+const (
+	// IngressStatusConverted Ingresses are converted without findings.
+	IngressStatusConverted = "converted"
+	// IngressStatusPartial Ingresses are converted with warnings or
+	// unsupported annotations.
+	IngressStatusPartial = "partiallyConverted"
+	// IngressStatusBlocked Ingresses have errors or blocking notifications.
+	IngressStatusBlocked = "blocked"
+	// IngressStatusSkipped Ingresses are converted to no resource.
+	IngressStatusSkipped = "skipped"
+	// IngressStatusExcluded Ingresses are left out by the exclusions.
+	IngressStatusExcluded = "excluded"
+)
+
+// ConversionStats are the numbers of Ingresses of a conversion by status,
+// in total and by namespace, for dashboards tracking a migration over time.
+type ConversionStats struct {
+	// GeneratedAt is when the conversion ran, in RFC 3339 format.
+	GeneratedAt string         `json:"generatedAt,omitempty"`
+	Total       NamespaceStats `json:"total"`
+	// Namespaces are sorted by namespace.
+	Namespaces []NamespaceStats `json:"namespaces"`
+}
+
+// NamespaceStats are the numbers of Ingresses of a namespace, or of every
+// namespace for the total, by status.
+type NamespaceStats struct {
+	Namespace          string `json:"namespace,omitempty"`
+	Ingresses          int    `json:"ingresses"`
+	Converted          int    `json:"converted"`
+	PartiallyConverted int    `json:"partiallyConverted"`
+	Blocked            int    `json:"blocked"`
+	Skipped            int    `json:"skipped"`
+	Excluded           int    `json:"excluded"`
+	// UnsupportedAnnotations are the numbers of Ingresses using each
+	// unsupported annotation.
+	UnsupportedAnnotations map[string]int `json:"unsupportedAnnotations,omitempty"`
+}
+
+// Stats returns the statistics of the conversion of the resources and
+// report, without GeneratedAt.
+func Stats(resources Resources, report Report) ConversionStats {
+	s := newStatsReport()
+	s.add(resources, report)
+	return s.stats()
+}
+
+// statsReport collects the status of the Ingresses of a conversion, or of
+// the conversions of every namespace of a stream.
+type statsReport struct {
+	statuses    map[types.NamespacedName]string
+	annotations map[types.NamespacedName][]string
+}
+
+func newStatsReport() *statsReport {
+	return &statsReport{
+		statuses:    map[types.NamespacedName]string{},
+		annotations: map[types.NamespacedName][]string{},
+	}
+}
+
+// statusRanks orders the statuses of an Ingress reported several times,
+// the highest one winning.
+var statusRanks = map[string]int{
+	IngressStatusConverted: 1,
+	IngressStatusPartial:   2,
+	IngressStatusSkipped:   3,
+	IngressStatusBlocked:   4,
+	IngressStatusExcluded:  5,
+}
+
+func (s *statsReport) set(ingress types.NamespacedName, status string) {
+	if statusRanks[status] > statusRanks[s.statuses[ingress]] {
+		s.statuses[ingress] = status
+	}
+}
+
+func (s *statsReport) add(resources Resources, report Report) {
+	for _, sources := range resources.Sources {
+		for _, source := range sources {
+			s.set(source.Ingress, IngressStatusConverted)
+		}
+	}
+	for _, finding := range Findings(report) {
+		var status string
+		switch finding.Severity {
+		case FindingSeverityError, string(BlockingNotification):
+			status = IngressStatusBlocked
+		case string(WarningNotification):
+			status = IngressStatusPartial
+		default:
+			continue
+		}
+		for _, obj := range finding.Objects {
+			ingress := types.NamespacedName{Namespace: obj.Namespace, Name: obj.Name}
+			// Only the Ingresses of the conversion are counted, not the
+			// ones a message mentions.
+			if _, ok := s.statuses[ingress]; obj.Kind == "Ingress" && (ok || finding.Severity == FindingSeverityError) {
+				s.set(ingress, status)
+			}
+		}
+	}
+	for _, u := range report.UnsupportedAnnotations {
+		s.annotations[u.Ingress] = append(s.annotations[u.Ingress], u.Annotation)
+		s.set(u.Ingress, IngressStatusPartial)
+	}
+	for _, ingress := range report.SkippedIngresses {
+		s.set(ingress, IngressStatusSkipped)
+	}
+	for _, ingress := range report.ExcludedIngresses {
+		s.set(ingress, IngressStatusExcluded)
+	}
+}
+
+func (s *statsReport) stats() ConversionStats {
+	namespaces := map[string]*NamespaceStats{}
+	stats := ConversionStats{}
+	count := func(ns *NamespaceStats, ingress types.NamespacedName, status string) {
+		ns.Ingresses++
+		switch status {
+		case IngressStatusConverted:
+			ns.Converted++
+		case IngressStatusPartial:
+			ns.PartiallyConverted++
+		case IngressStatusBlocked:
+			ns.Blocked++
+		case IngressStatusSkipped:
+			ns.Skipped++
+		case IngressStatusExcluded:
+			ns.Excluded++
+		}
+		for _, annotation := range s.annotations[ingress] {
+			if ns.UnsupportedAnnotations == nil {
+				ns.UnsupportedAnnotations = map[string]int{}
+			}
+			ns.UnsupportedAnnotations[annotation]++
+		}
+	}
+	for ingress, status := range s.statuses {
+		ns, ok := namespaces[ingress.Namespace]
+		if !ok {
+			ns = &NamespaceStats{Namespace: ingress.Namespace}
+			namespaces[ingress.Namespace] = ns
+		}
+		count(ns, ingress, status)
+		count(&stats.Total, ingress, status)
+	}
+	stats.Namespaces = []NamespaceStats{}
+	for _, ns := range namespaces {
+		stats.Namespaces = append(stats.Namespaces, *ns)
+	}
+	sort.Slice(stats.Namespaces, func(i, j int) bool {
+		return stats.Namespaces[i].Namespace < stats.Namespaces[j].Namespace
+	})
+	return stats
+}
+
+// write writes the statistics as a JSON document, generated at now.
+func (s *statsReport) write(w io.Writer, now time.Time) error {
+	stats := s.stats()
+	stats.GeneratedAt = now.UTC().Format(time.RFC3339)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(stats); err != nil {
+		return fmt.Errorf("failed to write conversion statistics: %w", err)
+	}
+	return nil
+}
+
+func writeStatsFile(runOpts RunOptions, s *statsReport) error {
+	if runOpts.StatsFile == "" {
+		return s.write(os.Stderr, runClock(runOpts).Now())
+	}
+	f, err := os.Create(runOpts.StatsFile)
+	if err != nil {
+		return fmt.Errorf("failed to create statistics file: %w", err)
+	}
+	if err := s.write(f, runClock(runOpts).Now()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
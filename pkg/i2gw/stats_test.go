@@ -0,0 +1,99 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+func Test_statsReport(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	web := ingressWithPath("web", "/", &iPrefix, serviceBackend("web", 80), nil)
+	legacy := ingressWithPath("legacy", "/legacy", &iPrefix, serviceBackend("legacy", 80), map[string]string{
+		"nginx.ingress.kubernetes.io/app-root": "/app",
+	})
+	php := ingressWithPath("php", "/", &iPrefix, serviceBackend("php", 9000), map[string]string{
+		"nginx.ingress.kubernetes.io/backend-protocol": "FCGI",
+	})
+	php.Spec.Rules[0].Host = "php.example.com"
+	empty := networkingv1.Ingress{}
+	empty.Namespace, empty.Name = "test", "empty"
+	empty.Spec.IngressClassName = stringPtr("nginx")
+	other := ingressWithPath("other", "/", &iPrefix, serviceBackend("other", 80), nil)
+	other.Namespace = "other"
+	excluded := ingressWithPath("excluded", "/", &iPrefix, serviceBackend("excluded", 80), nil)
+	excluded.Namespace = "other"
+
+	input := inputResources{ingresses: []networkingv1.Ingress{web, legacy, php, empty, other, excluded}}
+	resources, report := convertInput(input, ConvertOptions{Exclusions: []string{"other/excluded"}})
+	s := newStatsReport()
+	s.add(resources, report)
+
+	expected := ConversionStats{
+		Total: NamespaceStats{
+			Ingresses:              6,
+			Converted:              2,
+			PartiallyConverted:     1,
+			Blocked:                1,
+			Skipped:                1,
+			Excluded:               1,
+			UnsupportedAnnotations: map[string]int{"nginx.ingress.kubernetes.io/app-root": 1},
+		},
+		Namespaces: []NamespaceStats{{
+			Namespace: "other",
+			Ingresses: 2,
+			Converted: 1,
+			Excluded:  1,
+		}, {
+			Namespace:              "test",
+			Ingresses:              4,
+			Converted:              1,
+			PartiallyConverted:     1,
+			Blocked:                1,
+			Skipped:                1,
+			UnsupportedAnnotations: map[string]int{"nginx.ingress.kubernetes.io/app-root": 1},
+		}},
+	}
+	if diff := cmp.Diff(expected, s.stats()); diff != "" {
+		t.Errorf("Unexpected statistics, diff (-want +got):\n%s", diff)
+	}
+
+	var buf bytes.Buffer
+	if err := newStatsReport().write(&buf, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	expectedJSON := `{
+  "generatedAt": "2024-05-01T12:00:00Z",
+  "total": {
+    "ingresses": 0,
+    "converted": 0,
+    "partiallyConverted": 0,
+    "blocked": 0,
+    "skipped": 0,
+    "excluded": 0
+  },
+  "namespaces": []
+}
+`
+	if diff := cmp.Diff(expectedJSON, buf.String()); diff != "" {
+		t.Errorf("Unexpected JSON, diff (-want +got):\n%s", diff)
+	}
+}
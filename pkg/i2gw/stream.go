@@ -0,0 +1,206 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ConvertStream converts the Ingresses decoded from r one namespace at a
+// time, calling output with the resources generated for a namespace as soon
+// as the next one starts, so that only a single namespace is held in memory.
+// Ingresses must be grouped by namespace, as kubectl lists them, and
+// IngressClasses and other objects, such as the Services used by the
+// target implementation, must precede the Ingresses using them. The
+// Ingresses of the Checkpoint of opts are skipped and the others recorded
+// in it once output returns. The Client, InputFile and Ingresses of opts
+// are ignored.
+func ConvertStream(ctx context.Context, r io.Reader, opts ConvertOptions, output func(Resources, Report) error) error {
+	if _, err := lookupTargetImplementation(opts.TargetImplementation); err != nil {
+		return err
+	}
+	if err := validateListenerStrategy(opts.ListenerStrategy); err != nil {
+		return err
+	}
+	if err := validateHTTPSOnlyMode(opts.HTTPSOnly); err != nil {
+		return err
+	}
+	if err := validateNormalizeWeights(opts.NormalizeWeights); err != nil {
+		return err
+	}
+	if err := validatePathCollisionPolicy(opts.PathCollisions); err != nil {
+		return err
+	}
+	if err := validateDisabledFeatures(opts.DisabledFeatures); err != nil {
+		return err
+	}
+	if err := validateExperimentalFeatures(opts.ExperimentalFeatures); err != nil {
+		return err
+	}
+	if err := validateExclusions(opts.Exclusions); err != nil {
+		return err
+	}
+	if err := validateConversionMode(opts.Mode); err != nil {
+		return err
+	}
+	if err := validateRouteNaming(opts.RouteNaming); err != nil {
+		return err
+	}
+	if err := validateRouteStrategy(opts.RouteStrategy); err != nil {
+		return err
+	}
+	if err := validateMaxSupportLevel(opts.MaxSupportLevel); err != nil {
+		return err
+	}
+	if err := validateExternalDNSMode(opts.ExternalDNS); err != nil {
+		return err
+	}
+	if err := validateProgressiveDeliveryMode(opts.ProgressiveDelivery); err != nil {
+		return err
+	}
+	if err := validateHostHeaders(opts.HostHeaders, append(builtinProviders(), opts.Providers...)); err != nil {
+		return err
+	}
+	if err := validateTLSModes(opts.TLSModes); err != nil {
+		return err
+	}
+	if err := validateNamespaceMap(opts.NamespaceMap); err != nil {
+		return err
+	}
+	if err := validateGatewayMetadata(opts.GatewayLabels, opts.GatewayAnnotations, opts.GatewayInfrastructure); err != nil {
+		return err
+	}
+	if err := validateSingleGateway(opts.SingleGateway, opts.GatewayNamespace); err != nil {
+		return err
+	}
+	if err := validateExternalNameBackends(opts.ExternalNameBackends, opts.TargetImplementation); err != nil {
+		return err
+	}
+	if opts.GatewayNamespace != "" {
+		return fmt.Errorf("converting one namespace at a time doesn't support Gateways shared by several namespaces")
+	}
+	if len(opts.NamespaceMap) > 0 {
+		return fmt.Errorf("converting one namespace at a time doesn't support namespace maps, which may merge namespaces")
+	}
+	s := &streamConverter{
+		ctx:            ctx,
+		opts:           opts,
+		ingressClasses: opts.IngressClasses,
+		objects:        opts.Objects,
+		output:         output,
+		converted:      map[string]bool{},
+		resumed:        map[string]bool{},
+		written:        map[string]bool{},
+	}
+	if err := decodeObjects(r, s.add); err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+type streamConverter struct {
+	ctx            context.Context
+	opts           ConvertOptions
+	ingressClasses []networkingv1.IngressClass
+	objects        []unstructured.Unstructured
+	output         func(Resources, Report) error
+
+	namespace string
+	batch     []networkingv1.Ingress
+	converted map[string]bool
+	// resumed holds the namespaces with Ingresses skipped as recorded in
+	// the checkpoint.
+	resumed map[string]bool
+	report  Report
+	// written holds the notifications already output. Notifications about
+	// IngressClasses would otherwise be repeated for every namespace.
+	written map[string]bool
+}
+
+func (s *streamConverter) add(obj unstructured.Unstructured) error {
+	var input inputResources
+	if err := input.add(obj); err != nil {
+		return err
+	}
+	for _, ic := range input.ingressClasses {
+		if len(s.converted) > 0 {
+			s.report.Notifications = append(s.report.Notifications, notifications.NewWarning("IngressClass %s is defined after Ingresses were converted, it is not applied to Ingresses of namespaces already written", ic.Name))
+		}
+		s.ingressClasses = append(s.ingressClasses, ic)
+	}
+	s.objects = append(s.objects, input.objects...)
+	for _, ingress := range input.ingresses {
+		if s.opts.Checkpoint != nil && s.opts.Checkpoint.isProcessed(ingress) {
+			if !s.resumed[ingress.Namespace] {
+				s.resumed[ingress.Namespace] = true
+				s.report.Notifications = append(s.report.Notifications, notifications.NewInfo("Ingresses of namespace %s were converted by a previous run recorded in the checkpoint, skipping them", ingress.Namespace))
+			}
+			continue
+		}
+		if len(s.batch) > 0 && ingress.Namespace != s.namespace {
+			if err := s.flush(); err != nil {
+				return err
+			}
+		}
+		if len(s.batch) == 0 && s.converted[ingress.Namespace] {
+			s.report.Errors = append(s.report.Errors, fmt.Errorf("Ingresses of namespace %s are not grouped together in the input, their Gateways and HTTPRoutes are written more than once", ingress.Namespace))
+		}
+		s.namespace = ingress.Namespace
+		s.batch = append(s.batch, ingress)
+	}
+	return nil
+}
+
+// flush converts the Ingresses of the current namespace and writes them out.
+func (s *streamConverter) flush() error {
+	if len(s.batch) == 0 && len(s.report.Notifications) == 0 && len(s.report.Errors) == 0 {
+		return nil
+	}
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	input := inputResources{ingresses: s.batch, ingressClasses: s.ingressClasses, objects: s.objects}
+	resources, report := convertInput(input, s.opts)
+	var notes []Notification
+	for _, n := range append(s.report.Notifications, report.Notifications...) {
+		if !s.written[n.String()] {
+			s.written[n.String()] = true
+			notes = append(notes, n)
+		}
+	}
+	report.Notifications = notes
+	report.Errors = append(s.report.Errors, report.Errors...)
+
+	if len(s.batch) > 0 {
+		s.converted[s.namespace] = true
+	}
+	batch := s.batch
+	s.batch = nil
+	s.report = Report{}
+	if err := s.output(resources, report); err != nil {
+		return err
+	}
+	if s.opts.Checkpoint != nil {
+		return s.opts.Checkpoint.record(batch)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_ConvertStream(t *testing.T) {
+	ingress := func(namespace, name string) string {
+		return fmt.Sprintf(`apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: %s
+  namespace: %s
+spec:
+  ingressClassName: nginx
+  rules:
+  - host: example.com
+    http:
+      paths:
+      - path: /%s
+        pathType: Prefix
+        backend:
+          service:
+            name: %s
+            port:
+              number: 80
+`, name, namespace, name, name)
+	}
+	input := strings.Join([]string{
+		ingress("a", "one"),
+		ingress("a", "two"),
+		ingress("b", "three"),
+		ingress("a", "four"),
+	}, "---\n")
+
+	var gotNamespaces []string
+	var gotRules []int
+	var gotErrors int
+	err := ConvertStream(context.Background(), strings.NewReader(input), ConvertOptions{}, func(resources Resources, report Report) error {
+		for _, route := range resources.HTTPRoutes {
+			gotNamespaces = append(gotNamespaces, route.Namespace)
+			gotRules = append(gotRules, len(route.Spec.Rules))
+		}
+		gotErrors += len(report.Errors)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"a", "b", "a"}, gotNamespaces); diff != "" {
+		t.Errorf("Unexpected output order, diff (-want +got): %s", diff)
+	}
+	if diff := cmp.Diff([]int{2, 1, 1}, gotRules); diff != "" {
+		t.Errorf("Unexpected rule counts, diff (-want +got): %s", diff)
+	}
+	if gotErrors != 1 {
+		t.Errorf("Expected 1 error for the ungrouped namespace, got %d", gotErrors)
+	}
+}
+
+func Test_ConvertStreamCancelled(t *testing.T) {
+	input := `apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: one
+  namespace: a
+spec:
+  ingressClassName: nginx
+  defaultBackend:
+    service:
+      name: one
+      port:
+        number: 80
+`
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	err := ConvertStream(ctx, strings.NewReader(input), ConvertOptions{}, func(Resources, Report) error {
+		calls++
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("Expected no output once cancelled, got %d calls", calls)
+	}
+}
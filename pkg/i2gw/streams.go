@@ -0,0 +1,284 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+var (
+	tlsRouteGVK = schema.GroupVersionKind{
+		Group:   "gateway.networking.k8s.io",
+		Version: "v1alpha2",
+		Kind:    "TLSRoute",
+	}
+
+	tcpRouteGVK = schema.GroupVersionKind{
+		Group:   "gateway.networking.k8s.io",
+		Version: "v1alpha2",
+		Kind:    "TCPRoute",
+	}
+)
+
+const (
+	streamProtocolTLS = "tls"
+	streamProtocolTCP = "tcp"
+)
+
+// ingressStream is an Ingress forwarding the TLS or TCP connections to its
+// hosts to their backends, converted to a stream route per host instead of
+// HTTPRoute rules.
+type ingressStream struct {
+	source       types.NamespacedName
+	ingressClass string
+	gateway      string
+	stream       ir.Stream
+	// hosts are the hosts of the Ingress in order, the empty host for TCP
+	// streams, and backends their backends.
+	hosts    []string
+	backends map[string]networkingv1.IngressBackend
+}
+
+// addStream records the stream of an Ingress whose provider reports that it
+// forwards connections without handling them as HTTP. It returns false if
+// the Ingress is to be converted to HTTPRoutes instead, since stream routes
+// are only part of the experimental channel of Gateway API.
+func (a *ingressAggregator) addStream(ingress networkingv1.Ingress, ingressClass, gateway string, features *ir.IngressFeatures) bool {
+	if features.Stream == nil {
+		return false
+	}
+	source := types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}
+	protocol := features.Stream.Protocol
+	if protocol != streamProtocolTLS && protocol != streamProtocolTCP {
+		a.notifications = append(a.notifications, notifications.NewWarning("Ingress %s forwards connections of unknown protocol %q, its rules are converted to HTTPRoutes instead", source, protocol))
+		return false
+	}
+	if feature := streamExperimentalFeature(protocol); !a.experimental[feature] {
+		a.notifications = append(a.notifications, notifications.NewWarning("Ingress %s forwards %s connections without handling them as HTTP, which needs the %ss of the experimental channel of Gateway API, use --experimental-features=%s to convert them, its rules are converted to HTTPRoutes instead", source, strings.ToUpper(protocol), streamRouteKind(protocol), feature))
+		return false
+	}
+	if protocol == streamProtocolTCP && features.Stream.Port == 0 {
+		a.notifications = append(a.notifications, notifications.NewWarning("Ingress %s forwards TCP connections without a port, they are not converted", source))
+		return true
+	}
+
+	s := ingressStream{
+		source:       source,
+		ingressClass: ingressClass,
+		gateway:      gateway,
+		stream:       *features.Stream,
+		backends:     map[string]networkingv1.IngressBackend{},
+	}
+	add := func(host string, backend networkingv1.IngressBackend) {
+		if _, ok := s.backends[host]; !ok {
+			s.hosts = append(s.hosts, host)
+			s.backends[host] = backend
+		}
+	}
+	for _, rule := range ingress.Spec.Rules {
+		host := rule.Host
+		if protocol == streamProtocolTCP {
+			host = ""
+		}
+		// Like ingress-nginx, the connections are forwarded to the
+		// backend of the "/" path, or the default backend.
+		if backend, ok := rootPathBackend(rule); ok {
+			add(host, backend)
+		} else if ingress.Spec.DefaultBackend != nil {
+			add(host, *ingress.Spec.DefaultBackend)
+		} else {
+			a.notifications = append(a.notifications, notifications.NewWarning("Ingress %s has no \"/\" path for host %q to forward %s connections to, they are not converted", source, rule.Host, strings.ToUpper(protocol)))
+		}
+	}
+	if len(ingress.Spec.Rules) == 0 && ingress.Spec.DefaultBackend != nil {
+		add("", *ingress.Spec.DefaultBackend)
+	}
+	if !features.Policy.IsEmpty() || len(features.Rewrites) > 0 {
+		a.notifications = append(a.notifications, notifications.NewInfo("The HTTP features of Ingress %s are not converted, its %s connections are forwarded without being handled as HTTP", source, strings.ToUpper(protocol)))
+	}
+	a.streams = append(a.streams, s)
+	return true
+}
+
+func rootPathBackend(rule networkingv1.IngressRule) (networkingv1.IngressBackend, bool) {
+	if rule.HTTP == nil {
+		return networkingv1.IngressBackend{}, false
+	}
+	for _, path := range rule.HTTP.Paths {
+		if path.Path == "/" || path.Path == "" {
+			return path.Backend, true
+		}
+	}
+	return networkingv1.IngressBackend{}, false
+}
+
+func streamRouteKind(protocol string) string {
+	if protocol == streamProtocolTCP {
+		return tcpRouteGVK.Kind
+	}
+	return tlsRouteGVK.Kind
+}
+
+// streamExperimentalFeature is the experimental feature enabling the stream
+// routes of protocol.
+func streamExperimentalFeature(protocol string) string {
+	if protocol == streamProtocolTCP {
+		return ExperimentalTCPRoute
+	}
+	return ExperimentalTLSRoute
+}
+
+// addStreamRoutes adds the stream routes of the Ingresses forwarding
+// connections and the listener ports they attach to. TLS connections are
+// accepted on the HTTPS port of the Gateway by default, which HTTPS and TLS
+// listeners can share as long as their hostnames differ.
+func (a *ingressAggregator) addStreamRoutes(result *ir.IR, gatewayFor func(namespace, gateway, ingressClass string) *ir.Gateway, allowNamespace func(gw *ir.Gateway, i int, namespace string)) []error {
+	var errors []error
+	routes := map[types.NamespacedName]types.NamespacedName{}
+	for _, s := range a.streams {
+		port := ir.ListenerPort{Protocol: s.stream.Protocol, Port: s.stream.Port}
+		if port.Port == 0 {
+			port.Port = a.gatewayListenerPorts(s.ingressClass).HTTPS
+		}
+		for _, host := range s.hosts {
+			gw := gatewayFor(s.source.Namespace, s.gateway, s.ingressClass)
+			gwKey := types.NamespacedName{Namespace: gw.Namespace, Name: gw.Name}
+			i := listenerIndex(gw.Listeners, host)
+			if i >= 0 && port.Protocol == streamProtocolTLS && port.Port == gw.HTTPSPort && len(gw.Listeners[i].CertificateRefs) > 0 {
+				a.notifications = append(a.notifications, notifications.NewWarning("Host %q is served over HTTPS on port %d of Gateway %s, the TLS passthrough of Ingress %s is not converted", host, port.Port, gwKey, s.source))
+				continue
+			}
+
+			name := nameFromHost(host)
+			if port.Protocol == streamProtocolTCP {
+				name = fmt.Sprintf("tcp-%d", port.Port)
+			}
+			key := types.NamespacedName{Namespace: s.source.Namespace, Name: streamRouteKind(port.Protocol) + "/" + name}
+			if first, ok := routes[key]; ok {
+				a.notifications = append(a.notifications, notifications.NewWarning("Ingresses %s and %s both forward the %s connections of %s, only the ones of %s are converted", first, s.source, strings.ToUpper(port.Protocol), streamTarget(host, port), first))
+				continue
+			}
+			backendRef, err := a.backendResolver().toBackendRef(s.source.Namespace, s.backends[host])
+			if err != nil {
+				errors = append(errors, newIngressError(s.source, err))
+				continue
+			}
+			routes[key] = s.source
+
+			if i < 0 {
+				gw.Listeners = append(gw.Listeners, ir.Listener{Name: nameFromHost(host), Hostname: host, Stream: true})
+				i = len(gw.Listeners) - 1
+			}
+			gw.Listeners[i].ExtraPorts = mergeListenerPorts(gw.Listeners[i].ExtraPorts, []ir.ListenerPort{port})
+			allowNamespace(gw, i, s.source.Namespace)
+			if !containsNamespacedName(gw.Ingresses, s.source) {
+				gw.Ingresses = append(gw.Ingresses, s.source)
+			}
+
+			route := ir.StreamRoute{
+				Namespace:    s.source.Namespace,
+				Name:         name,
+				Protocol:     port.Protocol,
+				GatewayName:  gw.Name,
+				SectionNames: []string{gw.Listeners[i].PortSectionName(port)},
+				Backends:     []ir.Backend{{BackendRef: *backendRef, Source: s.source}},
+			}
+			if gw.Namespace != s.source.Namespace {
+				route.GatewayNamespace = gw.Namespace
+			}
+			if host != "" && port.Protocol == streamProtocolTLS {
+				route.Hostnames = []string{host}
+			}
+			result.StreamRoutes = append(result.StreamRoutes, route)
+		}
+	}
+	return errors
+}
+
+func streamTarget(host string, port ir.ListenerPort) string {
+	if port.Protocol == streamProtocolTCP || host == "" {
+		return fmt.Sprintf("port %d", port.Port)
+	}
+	return fmt.Sprintf("host %q", host)
+}
+
+// emitStreamRoutes turns the stream routes of the IR into TLSRoutes and
+// TCPRoutes.
+func emitStreamRoutes(result ir.IR) ([]gatewayv1alpha2.TLSRoute, []gatewayv1alpha2.TCPRoute) {
+	var tlsRoutes []gatewayv1alpha2.TLSRoute
+	var tcpRoutes []gatewayv1alpha2.TCPRoute
+	for _, route := range result.StreamRoutes {
+		meta := metav1.ObjectMeta{Namespace: route.Namespace, Name: route.Name}
+		spec := gatewayv1.CommonRouteSpec{ParentRefs: streamParentRefs(route)}
+		var backendRefs []gatewayv1.BackendRef
+		for _, backend := range route.Backends {
+			backendRefs = append(backendRefs, backend.BackendRef)
+		}
+		if route.Protocol == streamProtocolTCP {
+			tcpRoute := gatewayv1alpha2.TCPRoute{
+				ObjectMeta: meta,
+				Spec: gatewayv1alpha2.TCPRouteSpec{
+					CommonRouteSpec: spec,
+					Rules:           []gatewayv1alpha2.TCPRouteRule{{BackendRefs: backendRefs}},
+				},
+			}
+			tcpRoute.SetGroupVersionKind(tcpRouteGVK)
+			tcpRoutes = append(tcpRoutes, tcpRoute)
+			continue
+		}
+		tlsRoute := gatewayv1alpha2.TLSRoute{
+			ObjectMeta: meta,
+			Spec: gatewayv1alpha2.TLSRouteSpec{
+				CommonRouteSpec: spec,
+				Rules:           []gatewayv1alpha2.TLSRouteRule{{BackendRefs: backendRefs}},
+			},
+		}
+		for _, hostname := range route.Hostnames {
+			tlsRoute.Spec.Hostnames = append(tlsRoute.Spec.Hostnames, gatewayv1alpha2.Hostname(hostname))
+		}
+		tlsRoute.SetGroupVersionKind(tlsRouteGVK)
+		tlsRoutes = append(tlsRoutes, tlsRoute)
+	}
+	return tlsRoutes, tcpRoutes
+}
+
+func streamParentRefs(route ir.StreamRoute) []gatewayv1.ParentReference {
+	var namespace *gatewayv1.Namespace
+	if route.GatewayNamespace != "" && route.GatewayNamespace != route.Namespace {
+		ns := gatewayv1.Namespace(route.GatewayNamespace)
+		namespace = &ns
+	}
+	var parentRefs []gatewayv1.ParentReference
+	for _, sectionName := range route.SectionNames {
+		name := gatewayv1.SectionName(sectionName)
+		parentRefs = append(parentRefs, gatewayv1.ParentReference{
+			Namespace:   namespace,
+			Name:        gatewayv1.ObjectName(route.GatewayName),
+			SectionName: &name,
+		})
+	}
+	return parentRefs
+}
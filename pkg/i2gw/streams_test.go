@@ -0,0 +1,155 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+type tcpProvider struct{}
+
+func (tcpProvider) Name() string       { return "tcp" }
+func (tcpProvider) Controller() string { return "example.com/tcp" }
+func (tcpProvider) ParseIngress(ingress networkingv1.Ingress) (ir.IngressFeatures, []notifications.Notification) {
+	// Ingresses of classes without an IngressClass are parsed by every
+	// provider.
+	if ingress.Spec.IngressClassName == nil || *ingress.Spec.IngressClassName != "tcp" {
+		return ir.IngressFeatures{}, nil
+	}
+	return ir.IngressFeatures{Stream: &ir.Stream{Protocol: "tcp", Port: 5432}}, nil
+}
+
+func Test_streamRoutes(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	passthrough := ingressWithPath("secure", "/", &iPrefix, serviceBackend("secure", 443), map[string]string{
+		"nginx.ingress.kubernetes.io/ssl-passthrough": "true",
+	})
+	web := ingressWithPath("web", "/", &iPrefix, serviceBackend("web", 80), nil)
+	web.Spec.Rules[0].Host = "www.example.com"
+	database := ingressWithPath("database", "/", &iPrefix, serviceBackend("postgres", 5432), nil)
+	database.Spec.IngressClassName = stringPtr("tcp")
+	tcpClass := networkingv1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "tcp"},
+		Spec:       networkingv1.IngressClassSpec{Controller: "example.com/tcp"},
+	}
+
+	testCases := []struct {
+		name           string
+		experimental   bool
+		features       []string
+		wantListeners  map[string][]string
+		wantTLSRoutes  []string
+		wantTCPRoutes  []string
+		wantHTTPRoutes int
+		wantWarning    string
+	}{
+		{
+			name:         "passthrough and tcp with experimental",
+			experimental: true,
+			wantListeners: map[string][]string{
+				"nginx": {"www-example-com-http", "example-com-tls-443"},
+				"tcp":   {"all-hosts-tcp-5432"},
+			},
+			wantTLSRoutes:  []string{"test/example-com example.com nginx/example-com-tls-443 secure"},
+			wantTCPRoutes:  []string{"test/tcp-5432 tcp/all-hosts-tcp-5432 postgres"},
+			wantHTTPRoutes: 1,
+		},
+		{
+			name: "converted as HTTP without experimental",
+			wantListeners: map[string][]string{
+				"nginx": {"example-com-http", "www-example-com-http"},
+				"tcp":   {"example-com-http"},
+			},
+			wantHTTPRoutes: 3,
+			wantWarning:    "WARNING: Ingress test/secure forwards TLS connections without handling them as HTTP, which needs the TLSRoutes of the experimental channel of Gateway API, use --experimental-features=tlsroute to convert them, its rules are converted to HTTPRoutes instead",
+		},
+		{
+			name:     "tcp converted as HTTP with only tlsroute",
+			features: []string{ExperimentalTLSRoute},
+			wantListeners: map[string][]string{
+				"nginx": {"www-example-com-http", "example-com-tls-443"},
+				"tcp":   {"example-com-http"},
+			},
+			wantTLSRoutes:  []string{"test/example-com example.com nginx/example-com-tls-443 secure"},
+			wantHTTPRoutes: 2,
+			wantWarning:    "WARNING: Ingress test/database forwards TCP connections without handling them as HTTP, which needs the TCPRoutes of the experimental channel of Gateway API, use --experimental-features=tcproute to convert them, its rules are converted to HTTPRoutes instead",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			input := inputResources{
+				ingresses:      []networkingv1.Ingress{passthrough, web, database},
+				ingressClasses: []networkingv1.IngressClass{tcpClass},
+			}
+			resources, report := convertInput(input, ConvertOptions{Experimental: tc.experimental, ExperimentalFeatures: tc.features, Providers: []Provider{tcpProvider{}}})
+			if len(report.Errors) > 0 {
+				t.Fatalf("unexpected errors: %v", report.Errors)
+			}
+
+			gotListeners := map[string][]string{}
+			for _, gw := range resources.Gateways {
+				for _, l := range gw.Spec.Listeners {
+					gotListeners[gw.Name] = append(gotListeners[gw.Name], string(l.Name))
+					if l.Protocol == gatewayv1.TLSProtocolType && (l.TLS == nil || l.TLS.Mode == nil || *l.TLS.Mode != gatewayv1.TLSModePassthrough) {
+						t.Errorf("expected listener %s to pass TLS through, got %v", l.Name, l.TLS)
+					}
+				}
+			}
+			if diff := cmp.Diff(tc.wantListeners, gotListeners); diff != "" {
+				t.Errorf("unexpected listeners (-want +got):\n%s", diff)
+			}
+
+			var gotTLSRoutes, gotTCPRoutes []string
+			for _, route := range resources.TLSRoutes {
+				ref := route.Spec.ParentRefs[0]
+				gotTLSRoutes = append(gotTLSRoutes, route.Namespace+"/"+route.Name+" "+string(route.Spec.Hostnames[0])+" "+string(ref.Name)+"/"+string(*ref.SectionName)+" "+string(route.Spec.Rules[0].BackendRefs[0].Name))
+			}
+			for _, route := range resources.TCPRoutes {
+				ref := route.Spec.ParentRefs[0]
+				gotTCPRoutes = append(gotTCPRoutes, route.Namespace+"/"+route.Name+" "+string(ref.Name)+"/"+string(*ref.SectionName)+" "+string(route.Spec.Rules[0].BackendRefs[0].Name))
+			}
+			if diff := cmp.Diff(tc.wantTLSRoutes, gotTLSRoutes); diff != "" {
+				t.Errorf("unexpected TLSRoutes (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantTCPRoutes, gotTCPRoutes); diff != "" {
+				t.Errorf("unexpected TCPRoutes (-want +got):\n%s", diff)
+			}
+			if len(resources.HTTPRoutes) != tc.wantHTTPRoutes {
+				t.Errorf("expected %d HTTPRoutes, got %d", tc.wantHTTPRoutes, len(resources.HTTPRoutes))
+			}
+			if len(report.SkippedIngresses) != 0 {
+				t.Errorf("expected no skipped Ingresses, got %v", report.SkippedIngresses)
+			}
+			if tc.wantWarning != "" {
+				found := false
+				for _, n := range report.Notifications {
+					found = found || n.String() == tc.wantWarning
+				}
+				if !found {
+					t.Errorf("expected notification %q, got %v", tc.wantWarning, report.Notifications)
+				}
+			}
+		})
+	}
+}
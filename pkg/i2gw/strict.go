@@ -0,0 +1,160 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ConversionMode selects what is generated for the Ingresses that can't be
+// fully converted.
+type ConversionMode string
+
+const (
+	// ModeBestEffort converts what it can of every Ingress, dropping the
+	// paths, backends and annotations that can't be converted.
+	ModeBestEffort ConversionMode = "best-effort"
+	// ModeStrict generates nothing for the Ingresses that can't be fully
+	// converted, so that their traffic isn't partially migrated.
+	ModeStrict ConversionMode = "strict"
+)
+
+// ConversionModes returns the names of the supported conversion modes.
+func ConversionModes() []string {
+	return []string{string(ModeBestEffort), string(ModeStrict)}
+}
+
+func validateConversionMode(mode ConversionMode) error {
+	switch mode {
+	case "", ModeBestEffort, ModeStrict:
+		return nil
+	default:
+		return fmt.Errorf("unknown conversion mode %q, supported ones are: %s", mode, strings.Join(ConversionModes(), ", "))
+	}
+}
+
+// ingressError is an error converting a part of an Ingress.
+type ingressError struct {
+	ingress types.NamespacedName
+	err     error
+}
+
+func (e ingressError) Error() string {
+	return e.err.Error()
+}
+
+func (e ingressError) Unwrap() error {
+	return e.err
+}
+
+// dropPartialIngresses removes the rules and policies converted from the
+// Ingresses that failed to convert in part, or use annotations that are not
+// supported, as well as from the Ingresses sharing rules with them, such as
+// the primary Ingresses of canaries, since these rules can't be converted
+// in full either. HTTPRoutes left without rules are removed.
+func dropPartialIngresses(result *ir.IR, conversionErrors []error, unsupported []UnsupportedAnnotation) []Notification {
+	reasons := map[types.NamespacedName]string{}
+	var failed []types.NamespacedName
+	fail := func(ingress types.NamespacedName, reason string) {
+		if _, ok := reasons[ingress]; !ok {
+			reasons[ingress] = reason
+			failed = append(failed, ingress)
+		}
+	}
+	for _, err := range conversionErrors {
+		if ingress, ok := ErrorIngress(err); ok {
+			fail(ingress, fmt.Sprintf("failed to convert: %v", err))
+		}
+	}
+	for _, u := range unsupported {
+		fail(u.Ingress, fmt.Sprintf("uses annotation %s, which is not supported", u.Annotation))
+	}
+
+	// Fail the Ingresses sharing a rule with a failed one, until no rule
+	// mixes failed and converted Ingresses.
+	for changed := len(failed) > 0; changed; {
+		changed = false
+		for _, route := range result.HTTPRoutes {
+			for _, rule := range route.Rules {
+				var failedSource *types.NamespacedName
+				for _, backend := range rule.Backends {
+					if _, ok := reasons[backend.Source]; ok {
+						failedSource = &backend.Source
+						break
+					}
+				}
+				if failedSource == nil {
+					continue
+				}
+				for _, backend := range rule.Backends {
+					if _, ok := reasons[backend.Source]; !ok {
+						fail(backend.Source, fmt.Sprintf("shares rules with Ingress %s", *failedSource))
+						changed = true
+					}
+				}
+			}
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	var routes []ir.HTTPRoute
+	for _, route := range result.HTTPRoutes {
+		var rules []ir.HTTPRouteRule
+		for _, rule := range route.Rules {
+			dropped := false
+			for _, backend := range rule.Backends {
+				if _, ok := reasons[backend.Source]; ok {
+					dropped = true
+				}
+			}
+			if !dropped {
+				rules = append(rules, rule)
+			}
+		}
+		// Rules without backends, such as HTTPS redirects, are kept.
+		if len(rules) == 0 && len(route.Rules) > 0 {
+			continue
+		}
+		route.Rules = rules
+		for ingress := range reasons {
+			delete(route.Policies, ingress)
+		}
+		routes = append(routes, route)
+	}
+	result.HTTPRoutes = routes
+	for i := range result.Gateways {
+		var ingresses []types.NamespacedName
+		for _, ingress := range result.Gateways[i].Ingresses {
+			if _, ok := reasons[ingress]; !ok {
+				ingresses = append(ingresses, ingress)
+			}
+		}
+		result.Gateways[i].Ingresses = ingresses
+	}
+
+	var notes []Notification
+	for _, ingress := range failed {
+		notes = append(notes, notifications.NewWarning("Ingress %s %s, strict mode generates no HTTPRoute rule for it, its traffic must stay on the Ingress controller", ingress, reasons[ingress]))
+	}
+	return notes
+}
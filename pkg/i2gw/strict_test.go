@@ -0,0 +1,88 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_dropPartialIngresses(t *testing.T) {
+	ingress := func(name string) types.NamespacedName {
+		return types.NamespacedName{Namespace: "default", Name: name}
+	}
+	rule := func(path string, sources ...string) ir.HTTPRouteRule {
+		r := ir.HTTPRouteRule{Matches: []gatewayv1.HTTPRouteMatch{{Path: &gatewayv1.HTTPPathMatch{Value: ptrTo(path)}}}}
+		for _, source := range sources {
+			r.Backends = append(r.Backends, ir.Backend{Source: ingress(source)})
+		}
+		return r
+	}
+	result := ir.IR{
+		Gateways: []ir.Gateway{{Name: "nginx", Ingresses: []types.NamespacedName{ingress("web"), ingress("api"), ingress("primary")}}},
+		HTTPRoutes: []ir.HTTPRoute{
+			{
+				Name:     "a-example-com",
+				Rules:    []ir.HTTPRouteRule{rule("/web", "web"), rule("/api", "api"), rule("/shop", "primary", "canary")},
+				Policies: map[types.NamespacedName]ir.Policy{ingress("web"): {}, ingress("api"): {}},
+			},
+			{Name: "b-example-com", Rules: []ir.HTTPRouteRule{rule("/", "web")}},
+			{Name: "a-example-com-https-redirect", Rules: []ir.HTTPRouteRule{rule("/")}},
+		},
+	}
+	conversionErrors := []error{
+		ingressError{ingress: ingress("web"), err: errors.New("Named ports not supported: http")},
+		errors.New("unrelated"),
+	}
+	unsupported := []UnsupportedAnnotation{{Ingress: ingress("canary"), Annotation: "nginx.ingress.kubernetes.io/mirror-uri"}}
+
+	notes := dropPartialIngresses(&result, conversionErrors, unsupported)
+
+	var got []string
+	for _, route := range result.HTTPRoutes {
+		for _, rule := range route.Rules {
+			got = append(got, route.Name+*rule.Matches[0].Path.Value)
+		}
+	}
+	expected := []string{"a-example-com/api", "a-example-com-https-redirect/"}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("Unexpected rules, diff (-want +got): %s", diff)
+	}
+	if diff := cmp.Diff(map[types.NamespacedName]ir.Policy{ingress("api"): {}}, result.HTTPRoutes[0].Policies); diff != "" {
+		t.Errorf("Unexpected policies, diff (-want +got): %s", diff)
+	}
+	if diff := cmp.Diff([]types.NamespacedName{ingress("api")}, result.Gateways[0].Ingresses); diff != "" {
+		t.Errorf("Unexpected Gateway Ingresses, diff (-want +got): %s", diff)
+	}
+
+	var messages []string
+	for _, n := range notes {
+		messages = append(messages, n.Message)
+	}
+	expectedMessages := []string{
+		"Ingress default/web failed to convert: Named ports not supported: http, strict mode generates no HTTPRoute rule for it, its traffic must stay on the Ingress controller",
+		"Ingress default/canary uses annotation nginx.ingress.kubernetes.io/mirror-uri, which is not supported, strict mode generates no HTTPRoute rule for it, its traffic must stay on the Ingress controller",
+		"Ingress default/primary shares rules with Ingress default/canary, strict mode generates no HTTPRoute rule for it, its traffic must stay on the Ingress controller",
+	}
+	if diff := cmp.Diff(expectedMessages, messages); diff != "" {
+		t.Errorf("Unexpected notifications, diff (-want +got): %s", diff)
+	}
+}
@@ -0,0 +1,190 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	colorReset  = "\x1b[0m"
+	colorRed    = "\x1b[31m"
+	colorYellow = "\x1b[33m"
+	colorCyan   = "\x1b[36m"
+	colorGreen  = "\x1b[32m"
+)
+
+// conversionSummary counts what a conversion, or the conversions of every
+// namespace of a stream, produced.
+type conversionSummary struct {
+	converted     map[types.NamespacedName]bool
+	skipped       int
+	excluded      int
+	resources     map[string]int
+	kinds         []string
+	notifications map[NotificationType]int
+	errors        int
+	// annotations are the numbers of Ingresses using each unsupported
+	// annotation.
+	annotations map[string]int
+	// confidence are the numbers of resources of each confidence level.
+	confidence map[Confidence]int
+}
+
+func newConversionSummary() *conversionSummary {
+	return &conversionSummary{
+		converted:     map[types.NamespacedName]bool{},
+		resources:     map[string]int{},
+		notifications: map[NotificationType]int{},
+		annotations:   map[string]int{},
+		confidence:    map[Confidence]int{},
+	}
+}
+
+func (s *conversionSummary) add(resources Resources, report Report) {
+	for _, sources := range resources.Sources {
+		for _, source := range sources {
+			s.converted[source.Ingress] = true
+		}
+	}
+	s.skipped += len(report.SkippedIngresses)
+	s.excluded += len(report.ExcludedIngresses)
+	for _, obj := range resourceObjects(resources) {
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		if _, ok := s.resources[kind]; !ok {
+			s.kinds = append(s.kinds, kind)
+		}
+		s.resources[kind]++
+	}
+	for _, n := range report.Notifications {
+		s.notifications[n.Type]++
+	}
+	s.errors += len(report.Errors)
+	for _, u := range report.UnsupportedAnnotations {
+		s.annotations[u.Annotation]++
+	}
+	for _, confidence := range report.Confidence {
+		s.confidence[confidence.Level]++
+	}
+}
+
+// write writes the summary as tables, with the counts colored by severity
+// if color is set. Only the last column is colored, since the escape codes
+// would misalign the columns.
+func (s *conversionSummary) write(w io.Writer, color bool) {
+	paint := func(code, text string) string {
+		if !color {
+			return text
+		}
+		return code + text + colorReset
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "Conversion summary")
+	fmt.Fprintf(tw, "  Ingresses converted\t%s\n", paint(colorGreen, fmt.Sprint(len(s.converted))))
+	skipped := fmt.Sprint(s.skipped)
+	if s.skipped > 0 {
+		skipped = paint(colorYellow, skipped)
+	}
+	fmt.Fprintf(tw, "  Ingresses skipped\t%s\n", skipped)
+	if s.excluded > 0 {
+		fmt.Fprintf(tw, "  Ingresses excluded\t%d\n", s.excluded)
+	}
+	for _, kind := range s.kinds {
+		fmt.Fprintf(tw, "  %ss generated\t%d\n", kind, s.resources[kind])
+	}
+
+	fmt.Fprintln(tw, "\nNotifications")
+	for _, severity := range []struct {
+		label string
+		count int
+		color string
+	}{
+		{"ERROR", s.errors, colorRed},
+		{string(BlockingNotification), s.notifications[BlockingNotification], colorRed},
+		{string(WarningNotification), s.notifications[WarningNotification], colorYellow},
+		{string(InfoNotification), s.notifications[InfoNotification], colorCyan},
+	} {
+		count := fmt.Sprint(severity.count)
+		if severity.count > 0 {
+			count = paint(severity.color, count)
+		}
+		fmt.Fprintf(tw, "  %s\t%s\n", severity.label, count)
+	}
+
+	if len(s.confidence) > 0 {
+		fmt.Fprintln(tw, "\nConfidence")
+		for _, level := range []struct {
+			level Confidence
+			color string
+		}{
+			{ConfidenceExact, colorGreen},
+			{ConfidenceApproximated, colorYellow},
+			{ConfidencePartial, colorRed},
+		} {
+			count := fmt.Sprint(s.confidence[level.level])
+			if s.confidence[level.level] > 0 {
+				count = paint(level.color, count)
+			}
+			fmt.Fprintf(tw, "  %s\t%s\n", level.level, count)
+		}
+	}
+
+	if len(s.annotations) > 0 {
+		var annotations []string
+		for annotation := range s.annotations {
+			annotations = append(annotations, annotation)
+		}
+		sort.Slice(annotations, func(i, j int) bool {
+			if s.annotations[annotations[i]] != s.annotations[annotations[j]] {
+				return s.annotations[annotations[i]] > s.annotations[annotations[j]]
+			}
+			return annotations[i] < annotations[j]
+		})
+		fmt.Fprintln(tw, "\nUnsupported annotations\tIngresses")
+		for _, annotation := range annotations {
+			fmt.Fprintf(tw, "  %s\t%s\n", annotation, paint(colorYellow, fmt.Sprint(s.annotations[annotation])))
+		}
+	}
+	tw.Flush()
+}
+
+// WriteSummary writes a human-readable summary of the result: the numbers
+// of Ingresses converted and skipped and of resources generated, the
+// numbers of notifications by severity, the numbers of resources of each
+// confidence level, and the numbers of Ingresses using each unsupported
+// annotation. Severities are colored if color is set.
+func WriteSummary(w io.Writer, resources Resources, report Report, color bool) {
+	s := newConversionSummary()
+	s.add(resources, report)
+	s.write(w, color)
+}
+
+// colorOutput tells whether f is a terminal output is colored on, which
+// the NO_COLOR environment variable disables.
+func colorOutput(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,91 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_WriteSummary(t *testing.T) {
+	web := types.NamespacedName{Namespace: "default", Name: "web"}
+	api := types.NamespacedName{Namespace: "default", Name: "api"}
+	route := gatewayv1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example-com"}}
+	route.SetGroupVersionKind(httpRouteGVK)
+	resources := Resources{
+		HTTPRoutes: []gatewayv1.HTTPRoute{route},
+		Sources: map[ObjectRef][]IngressSource{
+			{Kind: "HTTPRoute", Namespace: "default", Name: "example-com"}: {{Ingress: web}},
+		},
+	}
+	report := Report{
+		Notifications: []Notification{
+			notifications.NewWarning("first"),
+			notifications.NewWarning("second"),
+			notifications.NewInfo("third"),
+		},
+		Errors: []error{errors.New("failed")},
+		UnsupportedAnnotations: []UnsupportedAnnotation{
+			{Ingress: web, Annotation: "nginx.ingress.kubernetes.io/server-snippet"},
+			{Ingress: api, Annotation: "nginx.ingress.kubernetes.io/server-snippet"},
+			{Ingress: api, Annotation: "nginx.ingress.kubernetes.io/mirror-uri"},
+		},
+		SkippedIngresses: []types.NamespacedName{api},
+		Confidence: map[ObjectRef]ResourceConfidence{
+			{Kind: "HTTPRoute", Namespace: "default", Name: "example-com"}: {Level: ConfidencePartial},
+		},
+	}
+
+	var buf bytes.Buffer
+	WriteSummary(&buf, resources, report, false)
+	expected := `Conversion summary
+  Ingresses converted   1
+  Ingresses skipped     1
+  HTTPRoutes generated  1
+
+Notifications
+  ERROR     1
+  BLOCKING  0
+  WARNING   2
+  INFO      1
+
+Confidence
+  exact         0
+  approximated  0
+  partial       1
+
+Unsupported annotations                       Ingresses
+  nginx.ingress.kubernetes.io/server-snippet  2
+  nginx.ingress.kubernetes.io/mirror-uri      1
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("Unexpected summary, diff (-want +got): %s", diff)
+	}
+
+	buf.Reset()
+	WriteSummary(&buf, resources, report, true)
+	if !strings.Contains(buf.String(), colorYellow+"2"+colorReset) {
+		t.Errorf("Expected warnings to be colored, got:\n%q", buf.String())
+	}
+}
@@ -0,0 +1,326 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// SupportLevel is the Gateway API support level of a feature: how many
+// implementations are expected to support it.
+type SupportLevel string
+
+const (
+	// SupportCore features are supported by every implementation.
+	SupportCore SupportLevel = "core"
+	// SupportExtended features are portable, but optional.
+	SupportExtended SupportLevel = "extended"
+	// SupportImplementationSpecific features behave differently from an
+	// implementation to another, if supported at all.
+	SupportImplementationSpecific SupportLevel = "implementation-specific"
+)
+
+// SupportLevels returns the names of the support levels, from the most to
+// the least portable.
+func SupportLevels() []string {
+	return []string{string(SupportCore), string(SupportExtended), string(SupportImplementationSpecific)}
+}
+
+func validateMaxSupportLevel(level SupportLevel) error {
+	switch level {
+	case "", SupportCore, SupportExtended, SupportImplementationSpecific:
+		return nil
+	default:
+		return fmt.Errorf("unknown support level %q, supported ones are: %s", level, strings.Join(SupportLevels(), ", "))
+	}
+}
+
+func supportRank(level SupportLevel) int {
+	switch level {
+	case SupportCore:
+		return 0
+	case SupportExtended:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// FeatureSupport is a Gateway API feature a generated resource uses, and its
+// support level.
+type FeatureSupport struct {
+	Feature string
+	Level   SupportLevel
+}
+
+// supportLevelFeatures collects the features of a resource, dropping the
+// ones above the maximum support level.
+type supportLevelFeatures struct {
+	max      SupportLevel
+	features []FeatureSupport
+	dropped  []string
+}
+
+// use records that the resource uses a feature, and returns whether it is
+// kept.
+func (f *supportLevelFeatures) use(feature string, level SupportLevel) bool {
+	if f.max != "" && supportRank(level) > supportRank(f.max) {
+		if !slices.Contains(f.dropped, feature) {
+			f.dropped = append(f.dropped, feature)
+		}
+		return false
+	}
+	if !slices.Contains(f.features, FeatureSupport{Feature: feature, Level: level}) {
+		f.features = append(f.features, FeatureSupport{Feature: feature, Level: level})
+	}
+	return true
+}
+
+// sorted returns the features from the most to the least portable.
+func (f *supportLevelFeatures) sorted() []FeatureSupport {
+	sort.SliceStable(f.features, func(i, j int) bool {
+		if f.features[i].Level != f.features[j].Level {
+			return supportRank(f.features[i].Level) < supportRank(f.features[j].Level)
+		}
+		return f.features[i].Feature < f.features[j].Feature
+	})
+	return f.features
+}
+
+// applySupportLevel returns the features of the Gateways and HTTPRoutes with
+// their support levels and, when max is set, drops the features above
+// it with a warning per resource. Extended fields are dropped on their own,
+// the matches of HTTPRoute rules widening, while the rules with
+// implementation-specific matches are dropped as a whole, since no portable
+// match reproduces them, and so are the redirects needing extended fields.
+// HTTPRoutes left without rules are dropped.
+func applySupportLevel(resources *Resources, max SupportLevel) (map[ObjectRef][]FeatureSupport, []Notification) {
+	tags := map[ObjectRef][]FeatureSupport{}
+	var notes []Notification
+	report := func(kind, namespace, name string, f *supportLevelFeatures) {
+		tags[ObjectRef{Kind: kind, Namespace: namespace, Name: name}] = f.sorted()
+		if len(f.dropped) > 0 {
+			notes = append(notes, notifications.NewWarning("%s %s/%s is restricted to %s features, %s are dropped", kind, namespace, name, max, strings.Join(f.dropped, ", ")))
+		}
+	}
+	for i := range resources.Gateways {
+		gw := &resources.Gateways[i]
+		if max == "" {
+			gw = gw.DeepCopy()
+		}
+		f := &supportLevelFeatures{max: max}
+		f.use("Gateway", SupportCore)
+		restrictGateway(gw, f)
+		report("Gateway", gw.Namespace, gw.Name, f)
+	}
+	routes := resources.HTTPRoutes[:0]
+	for i := range resources.HTTPRoutes {
+		route := &resources.HTTPRoutes[i]
+		if max == "" {
+			route = route.DeepCopy()
+		}
+		f := &supportLevelFeatures{max: max}
+		f.use("HTTPRoute", SupportCore)
+		if !restrictHTTPRoute(route, f) {
+			notes = append(notes, notifications.NewWarning("HTTPRoute %s/%s is dropped, its rules need %s, above %s features", route.Namespace, route.Name, strings.Join(f.dropped, ", "), max))
+			continue
+		}
+		report("HTTPRoute", route.Namespace, route.Name, f)
+		routes = append(routes, resources.HTTPRoutes[i])
+	}
+	resources.HTTPRoutes = routes
+	return tags, notes
+}
+
+func restrictGateway(gw *gatewayv1.Gateway, f *supportLevelFeatures) {
+	if len(gw.Spec.Addresses) > 0 && !f.use("GatewayStaticAddresses", SupportExtended) {
+		gw.Spec.Addresses = nil
+	}
+	if gw.Spec.Infrastructure != nil && !f.use("GatewayInfrastructurePropagation", SupportExtended) {
+		gw.Spec.Infrastructure = nil
+	}
+	for i := range gw.Spec.Listeners {
+		tls := gw.Spec.Listeners[i].TLS
+		if tls != nil && len(tls.Options) > 0 && !f.use("listener TLS options", SupportImplementationSpecific) {
+			tls := *tls
+			tls.Options = nil
+			gw.Spec.Listeners[i].TLS = &tls
+		}
+	}
+}
+
+// restrictHTTPRoute drops the features of a route above the maximum
+// support level, and returns whether the route keeps any rule.
+func restrictHTTPRoute(route *gatewayv1.HTTPRoute, f *supportLevelFeatures) bool {
+	var rules []gatewayv1.HTTPRouteRule
+	for _, rule := range route.Spec.Rules {
+		if restrictHTTPRouteRule(&rule, f) {
+			rules = append(rules, rule)
+		}
+	}
+	kept := len(rules) > 0 || len(route.Spec.Rules) == 0
+	route.Spec.Rules = rules
+	return kept
+}
+
+// restrictHTTPRouteRule drops the features of a rule above the maximum
+// support level, and returns whether the rule is kept.
+func restrictHTTPRouteRule(rule *gatewayv1.HTTPRouteRule, f *supportLevelFeatures) bool {
+	keep := true
+	rule.Matches = slices.Clone(rule.Matches)
+	for i := range rule.Matches {
+		match := &rule.Matches[i]
+		if match.Path != nil && match.Path.Type != nil && *match.Path.Type == gatewayv1.PathMatchRegularExpression {
+			keep = f.use("RegularExpression path matches", SupportImplementationSpecific) && keep
+		}
+		for _, header := range match.Headers {
+			if header.Type != nil && *header.Type == gatewayv1.HeaderMatchRegularExpression {
+				keep = f.use("RegularExpression header matches", SupportImplementationSpecific) && keep
+			}
+		}
+		for _, query := range match.QueryParams {
+			if query.Type != nil && *query.Type == gatewayv1.QueryParamMatchRegularExpression {
+				keep = f.use("RegularExpression query parameter matches", SupportImplementationSpecific) && keep
+			}
+		}
+		if len(match.QueryParams) > 0 && !f.use("HTTPRouteQueryParamMatching", SupportExtended) {
+			match.QueryParams = nil
+		}
+		if match.Method != nil && !f.use("HTTPRouteMethodMatching", SupportExtended) {
+			match.Method = nil
+		}
+	}
+	if !keep {
+		return false
+	}
+
+	redirects := slices.ContainsFunc(rule.Filters, isRequestRedirect)
+	rule.Filters = restrictFilters(rule.Filters, f, "")
+	if redirects && !slices.ContainsFunc(rule.Filters, isRequestRedirect) {
+		// Without its redirect, the rule would answer the requests itself.
+		return false
+	}
+	if rule.Timeouts != nil {
+		timeouts := *rule.Timeouts
+		rule.Timeouts = &timeouts
+		if rule.Timeouts.Request != nil && !f.use("HTTPRouteRequestTimeout", SupportExtended) {
+			rule.Timeouts.Request = nil
+		}
+		if rule.Timeouts.BackendRequest != nil && !f.use("HTTPRouteBackendTimeout", SupportExtended) {
+			rule.Timeouts.BackendRequest = nil
+		}
+		if rule.Timeouts.Request == nil && rule.Timeouts.BackendRequest == nil {
+			rule.Timeouts = nil
+		}
+	}
+	if rule.Retry != nil && !f.use("HTTPRouteRetry", SupportExtended) {
+		rule.Retry = nil
+	}
+	if rule.SessionPersistence != nil && !f.use("HTTPRouteSessionPersistence", SupportExtended) {
+		rule.SessionPersistence = nil
+	}
+
+	var backendRefs []gatewayv1.HTTPBackendRef
+	for _, ref := range rule.BackendRefs {
+		if ref.Kind != nil && *ref.Kind != "Service" || ref.Group != nil && *ref.Group != "" {
+			if !f.use(fmt.Sprintf("backendRefs of kind %s", backendRefKind(ref.BackendRef)), SupportImplementationSpecific) {
+				continue
+			}
+		}
+		ref.Filters = restrictFilters(ref.Filters, f, "backendRef ")
+		backendRefs = append(backendRefs, ref)
+	}
+	rule.BackendRefs = backendRefs
+	return true
+}
+
+func isRequestRedirect(filter gatewayv1.HTTPRouteFilter) bool {
+	return filter.Type == gatewayv1.HTTPRouteFilterRequestRedirect
+}
+
+func backendRefKind(ref gatewayv1.BackendRef) string {
+	kind := "Service"
+	if ref.Kind != nil {
+		kind = string(*ref.Kind)
+	}
+	if ref.Group != nil && *ref.Group != "" {
+		kind += "." + string(*ref.Group)
+	}
+	return kind
+}
+
+// restrictFilters drops the filters above the maximum support level. The
+// RequestRedirect filters setting an extended field are dropped as a whole,
+// since the scheme, path or port is what they redirect to: without its
+// scheme, an HTTPS redirect would redirect to itself. The features of
+// backendRef filters are prefixed.
+func restrictFilters(filters []gatewayv1.HTTPRouteFilter, f *supportLevelFeatures, prefix string) []gatewayv1.HTTPRouteFilter {
+	if len(filters) == 0 {
+		return filters
+	}
+	var kept []gatewayv1.HTTPRouteFilter
+	for _, filter := range filters {
+		var ok bool
+		switch filter.Type {
+		case gatewayv1.HTTPRouteFilterRequestHeaderModifier:
+			if prefix != "" {
+				ok = f.use("HTTPRouteBackendRequestHeaderModification", SupportExtended)
+			} else {
+				ok = f.use("HTTPRoute request header modification", SupportCore)
+			}
+		case gatewayv1.HTTPRouteFilterResponseHeaderModifier:
+			ok = f.use(prefix+"HTTPRouteResponseHeaderModification", SupportExtended)
+		case gatewayv1.HTTPRouteFilterRequestMirror:
+			ok = f.use(prefix+"HTTPRouteRequestMirror", SupportExtended)
+		case gatewayv1.HTTPRouteFilterURLRewrite:
+			ok = true
+			if filter.URLRewrite != nil && filter.URLRewrite.Hostname != nil {
+				ok = f.use(prefix+"HTTPRouteHostRewrite", SupportExtended) && ok
+			}
+			if filter.URLRewrite != nil && filter.URLRewrite.Path != nil {
+				ok = f.use(prefix+"HTTPRoutePathRewrite", SupportExtended) && ok
+			}
+		case gatewayv1.HTTPRouteFilterRequestRedirect:
+			ok = true
+			if redirect := filter.RequestRedirect; redirect != nil {
+				if redirect.Scheme != nil {
+					ok = f.use(prefix+"HTTPRouteSchemeRedirect", SupportExtended) && ok
+				}
+				if redirect.Path != nil {
+					ok = f.use(prefix+"HTTPRoutePathRedirect", SupportExtended) && ok
+				}
+				if redirect.Port != nil {
+					ok = f.use(prefix+"HTTPRoutePortRedirect", SupportExtended) && ok
+				}
+			}
+			ok = ok && f.use(prefix+"HTTPRoute request redirect", SupportCore)
+		case gatewayv1.HTTPRouteFilterExtensionRef:
+			ok = f.use(prefix+"ExtensionRef filters", SupportImplementationSpecific)
+		default:
+			ok = f.use(prefix+string(filter.Type)+" filters", SupportExtended)
+		}
+		if ok {
+			kept = append(kept, filter)
+		}
+	}
+	return kept
+}
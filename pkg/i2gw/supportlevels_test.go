@@ -0,0 +1,220 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_applySupportLevel(t *testing.T) {
+	regexPath := gatewayv1.PathMatchRegularExpression
+	prefixPath := gatewayv1.PathMatchPathPrefix
+	get := gatewayv1.HTTPMethodGet
+	route := func() gatewayv1.HTTPRoute {
+		return gatewayv1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "example-com"},
+			Spec: gatewayv1.HTTPRouteSpec{
+				Rules: []gatewayv1.HTTPRouteRule{{
+					Matches: []gatewayv1.HTTPRouteMatch{{
+						Path:   &gatewayv1.HTTPPathMatch{Type: &prefixPath, Value: ptrTo("/api")},
+						Method: &get,
+					}},
+					Filters: []gatewayv1.HTTPRouteFilter{{
+						Type:       gatewayv1.HTTPRouteFilterURLRewrite,
+						URLRewrite: &gatewayv1.HTTPURLRewriteFilter{Hostname: ptrTo(gatewayv1.PreciseHostname("backend.example.com"))},
+					}},
+					Timeouts: &gatewayv1.HTTPRouteTimeouts{Request: ptrTo(gatewayv1.Duration("10s"))},
+				}, {
+					Matches: []gatewayv1.HTTPRouteMatch{{
+						Path: &gatewayv1.HTTPPathMatch{Type: &prefixPath, Value: ptrTo("/old")},
+					}},
+					Filters: []gatewayv1.HTTPRouteFilter{{
+						Type: gatewayv1.HTTPRouteFilterRequestRedirect,
+						RequestRedirect: &gatewayv1.HTTPRequestRedirectFilter{
+							Scheme:     ptrTo("https"),
+							StatusCode: ptrTo(301),
+						},
+					}},
+				}, {
+					Matches: []gatewayv1.HTTPRouteMatch{{
+						Path: &gatewayv1.HTTPPathMatch{Type: &regexPath, Value: ptrTo("/v[0-9]+")},
+					}},
+				}},
+			},
+		}
+	}
+	gateway := gatewayv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "nginx"},
+		Spec: gatewayv1.GatewaySpec{
+			Addresses: []gatewayv1.GatewayAddress{{Value: "10.0.0.1"}},
+		},
+	}
+	routeRef := ObjectRef{Kind: "HTTPRoute", Namespace: "test", Name: "example-com"}
+	gatewayRef := ObjectRef{Kind: "Gateway", Namespace: "test", Name: "nginx"}
+
+	testCases := []struct {
+		name          string
+		max           SupportLevel
+		wantTags      map[ObjectRef][]FeatureSupport
+		wantRules     []gatewayv1.HTTPRouteRule
+		wantNotes     []Notification
+		wantAddresses int
+	}{{
+		name: "features are tagged without a maximum level",
+		wantTags: map[ObjectRef][]FeatureSupport{
+			gatewayRef: {{Feature: "Gateway", Level: SupportCore}, {Feature: "GatewayStaticAddresses", Level: SupportExtended}},
+			routeRef: {
+				{Feature: "HTTPRoute", Level: SupportCore},
+				{Feature: "HTTPRoute request redirect", Level: SupportCore},
+				{Feature: "HTTPRouteHostRewrite", Level: SupportExtended},
+				{Feature: "HTTPRouteMethodMatching", Level: SupportExtended},
+				{Feature: "HTTPRouteRequestTimeout", Level: SupportExtended},
+				{Feature: "HTTPRouteSchemeRedirect", Level: SupportExtended},
+				{Feature: "RegularExpression path matches", Level: SupportImplementationSpecific},
+			},
+		},
+		wantRules:     route().Spec.Rules,
+		wantAddresses: 1,
+	}, {
+		name: "extended features are kept up to the extended level",
+		max:  SupportExtended,
+		wantTags: map[ObjectRef][]FeatureSupport{
+			gatewayRef: {{Feature: "Gateway", Level: SupportCore}, {Feature: "GatewayStaticAddresses", Level: SupportExtended}},
+			routeRef: {
+				{Feature: "HTTPRoute", Level: SupportCore},
+				{Feature: "HTTPRoute request redirect", Level: SupportCore},
+				{Feature: "HTTPRouteHostRewrite", Level: SupportExtended},
+				{Feature: "HTTPRouteMethodMatching", Level: SupportExtended},
+				{Feature: "HTTPRouteRequestTimeout", Level: SupportExtended},
+				{Feature: "HTTPRouteSchemeRedirect", Level: SupportExtended},
+			},
+		},
+		wantRules: route().Spec.Rules[:2],
+		wantNotes: []Notification{
+			{Type: WarningNotification, Message: "HTTPRoute test/example-com is restricted to extended features, RegularExpression path matches are dropped"},
+		},
+		wantAddresses: 1,
+	}, {
+		name: "core level drops extended features",
+		max:  SupportCore,
+		wantTags: map[ObjectRef][]FeatureSupport{
+			gatewayRef: {{Feature: "Gateway", Level: SupportCore}},
+			routeRef:   {{Feature: "HTTPRoute", Level: SupportCore}},
+		},
+		wantRules: []gatewayv1.HTTPRouteRule{{
+			Matches: []gatewayv1.HTTPRouteMatch{{
+				Path: &gatewayv1.HTTPPathMatch{Type: &prefixPath, Value: ptrTo("/api")},
+			}},
+		}},
+		wantNotes: []Notification{
+			{Type: WarningNotification, Message: "Gateway test/nginx is restricted to core features, GatewayStaticAddresses are dropped"},
+			{Type: WarningNotification, Message: "HTTPRoute test/example-com is restricted to core features, HTTPRouteMethodMatching, HTTPRouteHostRewrite, HTTPRouteRequestTimeout, HTTPRouteSchemeRedirect, RegularExpression path matches are dropped"},
+		},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resources := Resources{
+				Gateways:   []gatewayv1.Gateway{*gateway.DeepCopy()},
+				HTTPRoutes: []gatewayv1.HTTPRoute{route()},
+			}
+			tags, notes := applySupportLevel(&resources, tc.max)
+			if diff := cmp.Diff(tc.wantTags, tags); diff != "" {
+				t.Errorf("Unexpected tags, diff (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(tc.wantNotes, notes); diff != "" {
+				t.Errorf("Unexpected notifications, diff (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(tc.wantRules, resources.HTTPRoutes[0].Spec.Rules); diff != "" {
+				t.Errorf("Unexpected rules, diff (-want +got): %s", diff)
+			}
+			if len(resources.Gateways[0].Spec.Addresses) != tc.wantAddresses {
+				t.Errorf("Expected %d addresses, got %v", tc.wantAddresses, resources.Gateways[0].Spec.Addresses)
+			}
+		})
+	}
+}
+
+func Test_applySupportLevelHTTPSRedirect(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	secure := ingressWithPath("secure", "/", &iPrefix, serviceBackend("secure", 80), nil)
+	secure.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-cert"}}
+
+	testCases := []struct {
+		name         string
+		max          SupportLevel
+		wantRedirect *gatewayv1.HTTPRequestRedirectFilter
+		wantNotes    []string
+	}{{
+		name:         "redirect kept at the extended level",
+		max:          SupportExtended,
+		wantRedirect: &gatewayv1.HTTPRequestRedirectFilter{Scheme: ptrTo("https"), StatusCode: ptrTo(301)},
+	}, {
+		name:      "redirect route dropped at the core level",
+		max:       SupportCore,
+		wantNotes: []string{"HTTPRoute test/example-com-https-redirect is dropped, its rules need HTTPRouteSchemeRedirect, above core features"},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			input := inputResources{ingresses: []networkingv1.Ingress{secure}}
+			resources, report := convertInput(input, ConvertOptions{MaxSupportLevel: tc.max})
+			if len(report.Errors) > 0 {
+				t.Fatalf("unexpected errors: %v", report.Errors)
+			}
+			var gotRedirect *gatewayv1.HTTPRequestRedirectFilter
+			for _, route := range resources.HTTPRoutes {
+				if route.Name != "example-com-https-redirect" {
+					continue
+				}
+				if len(route.Spec.Rules) != 1 || len(route.Spec.Rules[0].Filters) != 1 {
+					t.Fatalf("Expected a rule with a single filter, got %+v", route.Spec.Rules)
+				}
+				gotRedirect = route.Spec.Rules[0].Filters[0].RequestRedirect
+			}
+			if diff := cmp.Diff(tc.wantRedirect, gotRedirect); diff != "" {
+				t.Errorf("Unexpected redirect, diff (-want +got): %s", diff)
+			}
+			var gotNotes []string
+			for _, note := range report.Notifications {
+				if strings.Contains(note.Message, "https-redirect") {
+					gotNotes = append(gotNotes, note.Message)
+				}
+			}
+			if diff := cmp.Diff(tc.wantNotes, gotNotes); diff != "" {
+				t.Errorf("Unexpected notifications, diff (-want +got): %s", diff)
+			}
+			if _, ok := report.SupportLevels[ObjectRef{Kind: "HTTPRoute", Namespace: "test", Name: "example-com-https-redirect"}]; ok != (tc.wantRedirect != nil) {
+				t.Errorf("Expected the support levels of the redirect route to be reported: %t", tc.wantRedirect != nil)
+			}
+		})
+	}
+}
+
+func Test_validateMaxSupportLevel(t *testing.T) {
+	if err := validateMaxSupportLevel(SupportCore); err != nil {
+		t.Errorf("validateMaxSupportLevel(%q) = %v", SupportCore, err)
+	}
+	if err := validateMaxSupportLevel("minimal"); err == nil {
+		t.Errorf("validateMaxSupportLevel(%q) expected an error", "minimal")
+	}
+}
@@ -0,0 +1,350 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/targets/envoygateway"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/targets/gke"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/targets/istio"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/targets/nginxgatewayfabric"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// targetImplementation tailors the output for a Gateway API implementation
+// by emitting its policies alongside the Gateway API resources.
+type targetImplementation struct {
+	emitter Emitter
+	// inputKinds are the kinds of objects, besides Ingresses and
+	// IngressClasses, the emitter needs from the cluster.
+	inputKinds []schema.GroupVersionKind
+	// annotations are the annotations of Ingresses the emitter converts.
+	annotations []string
+	// policies are the parts of the ir.Policy of HTTPRoutes the emitter
+	// converts.
+	policies []policyFeature
+	// backendKinds are the kinds of resource backends the implementation
+	// routes to, besides Services.
+	backendKinds backendKinds
+	// externalNameKind, if set, is the kind of the backends the emitter
+	// emits for ExternalName Services, see ConvertOptions.ExternalNameBackends.
+	externalNameKind backendKind
+}
+
+var targetImplementations = map[string]targetImplementation{
+	gke.Name: {
+		emitter:     gke.NewEmitter(),
+		inputKinds:  slices.Concat(gke.InputKinds, []schema.GroupVersionKind{gkeServiceImportGVK}),
+		annotations: gke.IngressAnnotations,
+		// Multi-cluster Services are imported as net.gke.io ServiceImports.
+		backendKinds: backendKinds{
+			gkeServiceImportBackendKind: gkeServiceImportBackendKind,
+			serviceImportBackendKind:    gkeServiceImportBackendKind,
+		},
+	},
+	envoygateway.Name: {
+		emitter:    envoygateway.NewEmitter(),
+		inputKinds: []schema.GroupVersionKind{serviceGVK, serviceImportGVK},
+		policies:   []policyFeature{connectTimeoutFeature, retryFeature, rateLimitsFeature, ipAllowListFeature, ipDenyListFeature, extAuthFeature, oidcFeature, clientValidationFeature, affinityFeature, loadBalanceFeature, proxyBuffersFeature, bodySizeFeature, keepAliveFeature, http2Feature, proxyProtocolFeature, clientIPFeature, compressionFeature, wafFeature},
+		backendKinds: backendKinds{
+			serviceImportBackendKind: serviceImportBackendKind,
+			envoyBackendKind:         envoyBackendKind,
+		},
+		externalNameKind: envoyBackendKind,
+	},
+	istio.Name: {
+		emitter:  istio.NewEmitter(),
+		policies: []policyFeature{connectTimeoutFeature, affinityFeature, loadBalanceFeature},
+		backendKinds: backendKinds{
+			{group: "networking.istio.io", kind: "Hostname"}: {group: "networking.istio.io", kind: "Hostname"},
+		},
+	},
+	nginxgatewayfabric.Name: {
+		emitter:  nginxgatewayfabric.NewEmitter(),
+		policies: []policyFeature{snippetsFeature, proxyBuffersFeature, bodySizeFeature, keepAliveFeature, tracingFeature},
+	},
+}
+
+// policyFeature is a part of an ir.Policy, named as in user-facing messages.
+type policyFeature string
+
+const (
+	connectTimeoutFeature policyFeature = "connect timeouts"
+	retryFeature          policyFeature = "retries"
+	rateLimitsFeature     policyFeature = "rate limits"
+	ipAllowListFeature    policyFeature = "an IP allowlist"
+	ipDenyListFeature     policyFeature = "an IP denylist"
+	extAuthFeature        policyFeature = "external authentication"
+	oidcFeature           policyFeature = "OpenID Connect authentication"
+	affinityFeature       policyFeature = "session affinity"
+	loadBalanceFeature    policyFeature = "load balancing"
+	snippetsFeature       policyFeature = "NGINX snippets"
+	proxyBuffersFeature   policyFeature = "proxy buffers"
+	bodySizeFeature       policyFeature = "request body size limits"
+	keepAliveFeature      policyFeature = "client keep-alive timeouts"
+	http2Feature          policyFeature = "HTTP/2 settings"
+	proxyProtocolFeature  policyFeature = "the PROXY protocol"
+	clientIPFeature       policyFeature = "client IP detection"
+	compressionFeature    policyFeature = "response compression"
+	wafFeature            policyFeature = "a web application firewall"
+	tracingFeature        policyFeature = "tracing"
+
+	clientValidationFeature policyFeature = "client certificate authentication"
+	backendTLSFeature       policyFeature = "backend certificate verification"
+)
+
+func lookupTargetImplementation(name string) (targetImplementation, error) {
+	if name == "" {
+		return targetImplementation{}, nil
+	}
+	target, ok := targetImplementations[name]
+	if !ok {
+		return target, fmt.Errorf("unknown target implementation %q, supported ones are: %s", name, strings.Join(TargetImplementations(), ", "))
+	}
+	return target, nil
+}
+
+// TargetImplementations returns the names of the supported target
+// implementations.
+func TargetImplementations() []string {
+	var names []string
+	for name := range targetImplementations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// unconvertedPolicyNotifications warns about the parts of the policies of
+// Ingresses that the target implementation, if any, doesn't convert.
+func unconvertedPolicyNotifications(target targetImplementation, policies []ingressPolicy) []Notification {
+	var notes []Notification
+	for _, p := range policies {
+		var unconverted []string
+		var unconvertedFeatures []policyFeature
+		for _, feature := range policyFeatures(p.policy) {
+			if !containsPolicyFeature(target.policies, feature) {
+				unconverted = append(unconverted, string(feature))
+				unconvertedFeatures = append(unconvertedFeatures, feature)
+			}
+		}
+		if len(unconverted) == 0 {
+			continue
+		}
+		var converting []string
+		for _, name := range TargetImplementations() {
+			for _, feature := range unconverted {
+				if containsPolicyFeature(targetImplementations[name].policies, policyFeature(feature)) {
+					converting = append(converting, name)
+					break
+				}
+			}
+		}
+		msg := fmt.Sprintf("Ingress %s configures policies which Gateway API has no equivalent for (%s)", p.ingress, strings.Join(unconverted, ", "))
+		if target.emitter != nil {
+			msg = fmt.Sprintf("Ingress %s configures policies which Gateway API has no equivalent for and target implementation %s doesn't convert (%s)", p.ingress, target.emitter.Name(), strings.Join(unconverted, ", "))
+		}
+		if len(converting) > 0 {
+			msg += fmt.Sprintf(", use --target-implementation=%s to convert them to policies", strings.Join(converting, "|"))
+		}
+		var experimental, experimentalNames []string
+		for _, f := range experimentalFeatures {
+			if containsPolicyFeature(unconvertedFeatures, f.feature) {
+				experimental = append(experimental, string(f.feature))
+				experimentalNames = append(experimentalNames, f.name)
+			}
+		}
+		if len(experimental) > 0 {
+			msg += fmt.Sprintf(", or --experimental-features=%s to convert %s to experimental Gateway API fields", strings.Join(experimentalNames, ","), strings.Join(experimental, " and "))
+		}
+		notes = append(notes, notifications.NewWarning("%s", msg))
+	}
+	return notes
+}
+
+// ipDenyListNotifications lists the client CIDRs that the HTTPRoutes don't
+// deny although their Ingresses do, since denying them is a security
+// concern.
+func ipDenyListNotifications(result ir.IR) []Notification {
+	var notes []Notification
+	for _, route := range result.HTTPRoutes {
+		var sources []string
+		var cidrs []string
+		seen := map[string]bool{}
+		for source, p := range route.Policies {
+			if len(p.IPDenyList) == 0 {
+				continue
+			}
+			sources = append(sources, source.String())
+			for _, cidr := range p.IPDenyList {
+				if !seen[cidr] {
+					seen[cidr] = true
+					cidrs = append(cidrs, cidr)
+				}
+			}
+		}
+		if len(cidrs) == 0 {
+			continue
+		}
+		sort.Strings(sources)
+		sort.Strings(cidrs)
+		notes = append(notes, notifications.NewWarning("HTTPRoute %s/%s accepts requests from %s, denied by the IP denylist of %s", route.Namespace, route.Name, strings.Join(cidrs, ", "), strings.Join(sources, ", ")))
+	}
+	return notes
+}
+
+// clientAddressNotifications warns about the Gateways whose controller read
+// the address of clients from the PROXY protocol or a header, when the
+// target implementation doesn't, since their IP allowlists, denylists, rate
+// limits and access logs then see the address of the load balancer instead.
+func clientAddressNotifications(target targetImplementation, result ir.IR) []Notification {
+	type gatewayClients struct {
+		sources    []string
+		restricted []string
+	}
+	gateways := map[types.NamespacedName]*gatewayClients{}
+	var names []types.NamespacedName
+	for _, route := range result.HTTPRoutes {
+		for source, p := range route.Policies {
+			var sources []string
+			if p.ProxyProtocol && !containsPolicyFeature(target.policies, proxyProtocolFeature) {
+				sources = append(sources, "the PROXY protocol")
+			}
+			if p.ClientIP != nil && !containsPolicyFeature(target.policies, clientIPFeature) {
+				sources = append(sources, "the "+p.ClientIP.Header+" header")
+			}
+			if len(sources) == 0 {
+				continue
+			}
+			gw := route.Gateway()
+			clients, ok := gateways[gw]
+			if !ok {
+				clients = &gatewayClients{}
+				gateways[gw] = clients
+				names = append(names, gw)
+			}
+			clients.sources = append(clients.sources, sources...)
+			if len(p.IPAllowList) > 0 || len(p.IPDenyList) > 0 || len(p.RateLimits) > 0 {
+				clients.restricted = append(clients.restricted, source.String())
+			}
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i].String() < names[j].String() })
+
+	converted := "which Gateway API has no equivalent for"
+	if target.emitter != nil {
+		converted = fmt.Sprintf("which target implementation %s doesn't convert", target.emitter.Name())
+	}
+	var notes []Notification
+	for _, gw := range names {
+		clients := gateways[gw]
+		sort.Strings(clients.sources)
+		clients.sources = slices.Compact(clients.sources)
+		sort.Strings(clients.restricted)
+		clients.restricted = slices.Compact(clients.restricted)
+		msg := fmt.Sprintf("The ingress-nginx controller reads the address of the clients of Gateway %s from %s, %s: configure the Gateway to do so as well, or its access logs see the address of the load balancer in front of it", gw, strings.Join(clients.sources, " and "), converted)
+		if len(clients.restricted) > 0 {
+			msg += fmt.Sprintf(", and the IP allowlists, denylists and rate limits of %s apply to that address", strings.Join(clients.restricted, ", "))
+		}
+		notes = append(notes, notifications.NewWarning("%s", msg))
+	}
+	return notes
+}
+
+func policyFeatures(p ir.Policy) []policyFeature {
+	var features []policyFeature
+	// Read and send timeouts are converted to HTTPRoute timeouts and TLS
+	// settings to listener TLS options.
+	if p.Timeouts != nil && p.Timeouts.Connect != 0 {
+		features = append(features, connectTimeoutFeature)
+	}
+	if p.Retry != nil {
+		features = append(features, retryFeature)
+	}
+	if len(p.RateLimits) > 0 {
+		features = append(features, rateLimitsFeature)
+	}
+	if len(p.IPAllowList) > 0 {
+		features = append(features, ipAllowListFeature)
+	}
+	if len(p.IPDenyList) > 0 {
+		features = append(features, ipDenyListFeature)
+	}
+	if p.ExtAuth != nil {
+		features = append(features, extAuthFeature)
+	}
+	if p.OIDC != nil {
+		features = append(features, oidcFeature)
+	}
+	if p.ClientValidation != nil {
+		features = append(features, clientValidationFeature)
+	}
+	if p.BackendTLS != nil {
+		features = append(features, backendTLSFeature)
+	}
+	if p.ConsistentHash != nil {
+		features = append(features, affinityFeature)
+	} else if p.LoadBalance != "" {
+		features = append(features, loadBalanceFeature)
+	}
+	if p.Snippets != nil {
+		features = append(features, snippetsFeature)
+	}
+	if p.ProxyBuffers != nil {
+		features = append(features, proxyBuffersFeature)
+	}
+	if p.MaxRequestBodySize != nil {
+		features = append(features, bodySizeFeature)
+	}
+	if p.KeepAliveTimeout != 0 {
+		features = append(features, keepAliveFeature)
+	}
+	if p.HTTP2 != nil {
+		features = append(features, http2Feature)
+	}
+	if p.ProxyProtocol {
+		features = append(features, proxyProtocolFeature)
+	}
+	if p.ClientIP != nil {
+		features = append(features, clientIPFeature)
+	}
+	if len(p.Compression) > 0 {
+		features = append(features, compressionFeature)
+	}
+	if p.WAF != nil {
+		features = append(features, wafFeature)
+	}
+	if p.Tracing != nil {
+		features = append(features, tracingFeature)
+	}
+	return features
+}
+
+func containsPolicyFeature(features []policyFeature, feature policyFeature) bool {
+	for _, f := range features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,618 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package envoygateway emits the Envoy Gateway policies equivalent to the
+// traffic policies of Ingresses.
+package envoygateway
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Name is the name of the target implementation.
+const Name = "envoy-gateway"
+
+const (
+	policyGroup   = "gateway.envoyproxy.io"
+	policyVersion = "v1alpha1"
+
+	gatewayAPIGroup = "gateway.networking.k8s.io"
+)
+
+// Emitter emits BackendTrafficPolicies for timeouts, retries, load balancing,
+// rate limits, buffer sizes and compression, SecurityPolicies for IP allow
+// and deny lists, external and OpenID Connect authentication, and
+// ClientTrafficPolicies for TLS settings, client certificate authentication,
+// request body size limits, HTTP/2, the PROXY protocol and client IP
+// detection, EnvoyExtensionPolicies running a web application firewall, and
+// Backends routing to the external hosts of ExternalName Services.
+type Emitter struct{}
+
+// NewEmitter returns the Envoy Gateway emitter.
+func NewEmitter() *Emitter {
+	return &Emitter{}
+}
+
+func (e *Emitter) Name() string {
+	return Name
+}
+
+func (e *Emitter) Emit(result ir.IR) ([]unstructured.Unstructured, []notifications.Notification, []error) {
+	var objects []unstructured.Unstructured
+	var notes []notifications.Notification
+
+	tlsByGateway := map[types.NamespacedName]map[string]interface{}{}
+	bodySizeByGateway := map[types.NamespacedName]int64{}
+	keepAliveByGateway := map[types.NamespacedName]time.Duration{}
+	http2ByGateway := map[types.NamespacedName]bool{}
+	proxyProtocolGateways := map[types.NamespacedName]bool{}
+	clientIPByGateway := map[types.NamespacedName]*ir.ClientIP{}
+	for _, route := range result.HTTPRoutes {
+		policy, policyNotes := routePolicy(route)
+		notes = append(notes, policyNotes...)
+		if policy.IsEmpty() {
+			continue
+		}
+		targetRef := map[string]interface{}{"group": gatewayAPIGroup, "kind": "HTTPRoute", "name": route.Name}
+
+		if spec := backendTrafficPolicySpec(policy); len(spec) > 0 {
+			objects = append(objects, newPolicy("BackendTrafficPolicy", route.Namespace, route.Name, targetRef, spec))
+		}
+
+		spec, specNotes := securityPolicySpec(route, policy)
+		notes = append(notes, specNotes...)
+		if len(spec) > 0 {
+			objects = append(objects, newPolicy("SecurityPolicy", route.Namespace, route.Name, targetRef, spec))
+		}
+
+		if policy.WAF != nil {
+			objects = append(objects, newPolicy("EnvoyExtensionPolicy", route.Namespace, route.Name, targetRef, wafSpec(policy.WAF)))
+			notes = append(notes, notifications.NewWarning("EnvoyExtensionPolicy %s/%s is a stub running the Coraza web application firewall in place of ModSecurity, check its image and rules before relying on it", route.Namespace, route.Name))
+		}
+
+		if tls := tlsSpec(policy); len(tls) > 0 {
+			gw := route.Gateway()
+			if existing, ok := tlsByGateway[gw]; ok && !reflect.DeepEqual(existing, tls) {
+				notes = append(notes, notifications.NewWarning("HTTPRoutes of Gateway %s configure different TLS settings, only the ones of the first are converted", gw))
+			} else {
+				tlsByGateway[gw] = tls
+			}
+		}
+
+		// Envoy Gateway limits the size of request bodies per Gateway,
+		// with the buffer limit of client connections.
+		if size := policy.MaxRequestBodySize; size != nil {
+			gw := route.Gateway()
+			existing, ok := bodySizeByGateway[gw]
+			switch {
+			case *size == 0:
+				notes = append(notes, notifications.NewWarning("HTTPRoute %s/%s accepts request bodies of any size, which can't be converted, the buffer limit of Envoy Gateway applies", route.Namespace, route.Name))
+			case ok && existing != *size:
+				notes = append(notes, notifications.NewWarning("HTTPRoutes of Gateway %s limit the size of request bodies differently, only the limit of the first is converted", gw))
+			default:
+				bodySizeByGateway[gw] = *size
+			}
+		}
+
+		if keepAlive := policy.KeepAliveTimeout; keepAlive != 0 {
+			gw := route.Gateway()
+			if existing, ok := keepAliveByGateway[gw]; ok && existing != keepAlive {
+				notes = append(notes, notifications.NewWarning("HTTPRoutes of Gateway %s keep idle client connections open differently, only the timeout of the first is converted", gw))
+			} else {
+				keepAliveByGateway[gw] = keepAlive
+			}
+		}
+
+		// HTTP/2 and the PROXY protocol are settings of the controller, so
+		// they are the same for every HTTPRoute.
+		if http2 := policy.HTTP2; http2 != nil {
+			http2ByGateway[route.Gateway()] = *http2
+		}
+		if policy.ProxyProtocol {
+			proxyProtocolGateways[route.Gateway()] = true
+		}
+		if policy.ClientIP != nil {
+			clientIPByGateway[route.Gateway()] = policy.ClientIP
+		}
+	}
+
+	for _, gw := range result.Gateways {
+		key := types.NamespacedName{Namespace: gw.Namespace, Name: gw.Name}
+		gatewayTLS, hasTLS := tlsByGateway[key]
+		// Envoy Gateway negotiates HTTP/2 with ALPN, which only offers
+		// HTTP/1.1 once HTTP/2 is disabled.
+		if http2, ok := http2ByGateway[key]; ok && !http2 && hasTLSListener(gw) {
+			if !hasTLS {
+				gatewayTLS = map[string]interface{}{}
+				hasTLS = true
+			}
+			gatewayTLS["alpnProtocols"] = []interface{}{"http/1.1"}
+		}
+		// settings are the client connection settings, repeated by the
+		// policies of the listeners.
+		settings := map[string]interface{}{}
+		if bodySize, ok := bodySizeByGateway[key]; ok {
+			settings["connection"] = map[string]interface{}{"bufferLimit": quantity(bodySize)}
+		}
+		if keepAlive, ok := keepAliveByGateway[key]; ok {
+			settings["timeout"] = map[string]interface{}{"http": map[string]interface{}{"idleTimeout": formatDuration(keepAlive)}}
+		}
+		if proxyProtocolGateways[key] {
+			settings["enableProxyProtocol"] = true
+		}
+		if clientIP, ok := clientIPByGateway[key]; ok {
+			detection, detectionNotes := clientIPDetection(gw, clientIP)
+			notes = append(notes, detectionNotes...)
+			settings["clientIPDetection"] = detection
+		}
+		if hasTLS || len(settings) > 0 {
+			spec := map[string]interface{}{}
+			if hasTLS {
+				spec["tls"] = gatewayTLS
+			}
+			for k, v := range settings {
+				spec[k] = v
+			}
+			targetRef := map[string]interface{}{"group": gatewayAPIGroup, "kind": "Gateway", "name": gw.Name}
+			objects = append(objects, newPolicy("ClientTrafficPolicy", gw.Namespace, gw.Name, targetRef, spec))
+		}
+
+		// Policies of listeners replace the policy of their Gateway, so
+		// they repeat its settings.
+		for _, listener := range gw.Listeners {
+			if listener.ClientValidation == nil || len(listener.CertificateRefs) == 0 {
+				continue
+			}
+			sectionName := listener.SectionName("https")
+			tls, tlsNotes := clientValidationTLS(gw, listener)
+			notes = append(notes, tlsNotes...)
+			for k, v := range gatewayTLS {
+				tls[k] = v
+			}
+			targetRef := map[string]interface{}{"group": gatewayAPIGroup, "kind": "Gateway", "name": gw.Name, "sectionName": sectionName}
+			spec := map[string]interface{}{"tls": tls}
+			for k, v := range settings {
+				spec[k] = v
+			}
+			objects = append(objects, newPolicy("ClientTrafficPolicy", gw.Namespace, gw.Name+"-"+sectionName, targetRef, spec))
+		}
+	}
+
+	for _, backend := range result.ExternalBackends {
+		objects = append(objects, newBackend(backend))
+	}
+	if len(result.ExternalBackends) > 0 {
+		notes = append(notes, notifications.NewInfo("Backends are only served by Envoy Gateway with the Backend API enabled, by extensionApis.enableBackend in its configuration"))
+	}
+
+	return objects, notes, nil
+}
+
+// newBackend returns the Backend routing to the external host of an
+// ExternalName Service.
+func newBackend(backend ir.ExternalBackend) unstructured.Unstructured {
+	obj := unstructured.Unstructured{Object: map[string]interface{}{}}
+	obj.SetGroupVersionKind(schema.GroupVersionKind{Group: policyGroup, Version: policyVersion, Kind: "Backend"})
+	obj.SetNamespace(backend.Namespace)
+	obj.SetName(backend.Name)
+	obj.Object["spec"] = map[string]interface{}{
+		"endpoints": []interface{}{
+			map[string]interface{}{"fqdn": map[string]interface{}{"hostname": backend.Hostname, "port": int64(backend.Port)}},
+		},
+	}
+	return obj
+}
+
+// clientIPDetection returns the ClientTrafficPolicy settings reading the
+// address of clients from the header, trusting the proxies of the CIDRs
+// or, without them, the proxy in front of the Gateway.
+func clientIPDetection(gw ir.Gateway, clientIP *ir.ClientIP) (map[string]interface{}, []notifications.Notification) {
+	if !strings.EqualFold(clientIP.Header, "X-Forwarded-For") {
+		var notes []notifications.Notification
+		if len(clientIP.TrustedCIDRs) > 0 {
+			notes = append(notes, notifications.NewWarning("Gateway %s/%s reads the address of clients from the %s header, Envoy Gateway can't restrict it to the proxies of %s", gw.Namespace, gw.Name, clientIP.Header, strings.Join(clientIP.TrustedCIDRs, ", ")))
+		}
+		return map[string]interface{}{"customHeader": map[string]interface{}{"name": clientIP.Header}}, notes
+	}
+	xForwardedFor := map[string]interface{}{"numTrustedHops": int64(1)}
+	if len(clientIP.TrustedCIDRs) > 0 {
+		xForwardedFor = map[string]interface{}{"trustedCIDRs": toInterfaces(clientIP.TrustedCIDRs)}
+	}
+	return map[string]interface{}{"xForwardedFor": xForwardedFor}, nil
+}
+
+func hasTLSListener(gw ir.Gateway) bool {
+	for _, listener := range gw.Listeners {
+		if len(listener.CertificateRefs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// routePolicy returns the policy applied to the whole route. Envoy Gateway
+// policies attach to routes rather than to the rules converted from each
+// Ingress, so the policy of the first Ingress is used when they differ.
+func routePolicy(route ir.HTTPRoute) (*ir.Policy, []notifications.Notification) {
+	var notes []notifications.Notification
+	policies := map[types.NamespacedName]ir.Policy{}
+	for source, p := range route.Policies {
+		// Only keep the parts converted to Envoy Gateway policies.
+		policy := ir.Policy{
+			Timeouts:       connectTimeout(p.Timeouts),
+			Retry:          p.Retry,
+			RateLimits:     p.RateLimits,
+			IPAllowList:    p.IPAllowList,
+			IPDenyList:     p.IPDenyList,
+			ExtAuth:        p.ExtAuth,
+			OIDC:           p.OIDC,
+			TLSCiphers:     p.TLSCiphers,
+			TLSProtocols:   p.TLSProtocols,
+			ConsistentHash: p.ConsistentHash,
+			LoadBalance:    p.LoadBalance,
+
+			WAF:                p.WAF,
+			Compression:        p.Compression,
+			MaxRequestBodySize: p.MaxRequestBodySize,
+			KeepAliveTimeout:   p.KeepAliveTimeout,
+			HTTP2:              p.HTTP2,
+			ProxyProtocol:      p.ProxyProtocol,
+			ClientIP:           p.ClientIP,
+		}
+		if b := p.ProxyBuffers; b != nil {
+			if b.Buffering != nil || b.Number != 0 {
+				notes = append(notes, notifications.NewWarning("Ingress %s configures the buffering of responses, which Envoy Gateway can't convert, only the buffer size is", source))
+			}
+			if b.Size != "" {
+				policy.ProxyBuffers = &ir.ProxyBuffers{Size: b.Size}
+			}
+		}
+		if h := policy.ConsistentHash; h != nil && h.QueryParameter != "" {
+			notes = append(notes, notifications.NewWarning("Ingress %s hashes requests by query parameter, which Envoy Gateway can't convert", source))
+			policy.ConsistentHash = nil
+		}
+		if !policy.IsEmpty() {
+			policies[source] = policy
+		}
+	}
+	if len(policies) == 0 {
+		return nil, notes
+	}
+
+	var withPolicy []types.NamespacedName
+	for source := range policies {
+		withPolicy = append(withPolicy, source)
+	}
+	sort.Slice(withPolicy, func(i, j int) bool { return withPolicy[i].String() < withPolicy[j].String() })
+	policy := policies[withPolicy[0]]
+
+	for _, source := range withPolicy[1:] {
+		if !reflect.DeepEqual(policies[source], policy) {
+			notes = append(notes, notifications.NewWarning("Ingresses %s and %s of HTTPRoute %s/%s configure different policies, the policies of %s apply to the whole route", withPolicy[0], source, route.Namespace, route.Name, withPolicy[0]))
+		}
+	}
+
+	var withoutPolicy []string
+	seen := map[types.NamespacedName]bool{}
+	for _, rule := range route.Rules {
+		for _, backend := range rule.Backends {
+			if _, ok := policies[backend.Source]; !ok && !seen[backend.Source] {
+				seen[backend.Source] = true
+				withoutPolicy = append(withoutPolicy, backend.Source.String())
+			}
+		}
+	}
+	if len(withoutPolicy) > 0 {
+		sort.Strings(withoutPolicy)
+		notes = append(notes, notifications.NewWarning("The policies of Ingress %s apply to the whole HTTPRoute %s/%s, including the paths of Ingresses %s", withPolicy[0], route.Namespace, route.Name, strings.Join(withoutPolicy, ", ")))
+	}
+	return &policy, notes
+}
+
+func connectTimeout(t *ir.Timeouts) *ir.Timeouts {
+	if t == nil || t.Connect == 0 {
+		return nil
+	}
+	return &ir.Timeouts{Connect: t.Connect}
+}
+
+func backendTrafficPolicySpec(policy *ir.Policy) map[string]interface{} {
+	spec := map[string]interface{}{}
+	// Read and send timeouts are converted to HTTPRoute timeouts.
+	if t := policy.Timeouts; t != nil && t.Connect != 0 {
+		spec["timeout"] = map[string]interface{}{
+			"tcp": map[string]interface{}{"connectTimeout": formatDuration(t.Connect)},
+		}
+	}
+	if r := policy.Retry; r != nil {
+		// Connection errors and timeouts are always retried.
+		triggers := []interface{}{"connect-failure", "reset"}
+		retryOn := map[string]interface{}{}
+		if len(r.StatusCodes) > 0 {
+			triggers = append(triggers, "retriable-status-codes")
+			var codes []interface{}
+			for _, code := range r.StatusCodes {
+				codes = append(codes, int64(code))
+			}
+			retryOn["httpStatusCodes"] = codes
+		}
+		retryOn["triggers"] = triggers
+		retry := map[string]interface{}{"retryOn": retryOn}
+		if r.Attempts != 0 {
+			retry["numRetries"] = int64(r.Attempts)
+		}
+		spec["retry"] = retry
+	}
+	if b := policy.ProxyBuffers; b != nil {
+		// The size was validated by the provider.
+		if size, err := ir.ParseNGINXSize(b.Size); err == nil {
+			spec["connection"] = map[string]interface{}{"bufferLimit": quantity(size)}
+		}
+	}
+	if h := policy.ConsistentHash; h != nil {
+		spec["loadBalancer"] = map[string]interface{}{"type": "ConsistentHash", "consistentHash": consistentHash(h)}
+	} else if policy.LoadBalance != "" {
+		spec["loadBalancer"] = map[string]interface{}{"type": string(policy.LoadBalance)}
+	}
+	if len(policy.Compression) > 0 {
+		var compression []interface{}
+		for _, algorithm := range policy.Compression {
+			compression = append(compression, map[string]interface{}{"type": string(algorithm)})
+		}
+		spec["compression"] = compression
+	}
+	if len(policy.RateLimits) > 0 {
+		var rules []interface{}
+		for _, limit := range policy.RateLimits {
+			rules = append(rules, map[string]interface{}{
+				// Limits apply to each client IP address.
+				"clientSelectors": []interface{}{
+					map[string]interface{}{
+						"sourceCIDR": map[string]interface{}{"type": "Distinct", "value": "0.0.0.0/0"},
+					},
+				},
+				"limit": map[string]interface{}{"requests": int64(limit.Requests), "unit": string(limit.Unit)},
+			})
+		}
+		spec["rateLimit"] = map[string]interface{}{
+			"type":   "Global",
+			"global": map[string]interface{}{"rules": rules},
+		}
+	}
+	return spec
+}
+
+func consistentHash(hash *ir.ConsistentHash) map[string]interface{} {
+	switch {
+	case hash.Cookie != nil:
+		cookie := map[string]interface{}{"name": hash.Cookie.Name}
+		if hash.Cookie.TTL != 0 {
+			cookie["ttl"] = formatDuration(hash.Cookie.TTL)
+		}
+		return map[string]interface{}{"type": "Cookie", "cookie": cookie}
+	case hash.Header != "":
+		return map[string]interface{}{"type": "Header", "header": map[string]interface{}{"name": hash.Header}}
+	default:
+		return map[string]interface{}{"type": "SourceIP"}
+	}
+}
+
+func securityPolicySpec(route ir.HTTPRoute, policy *ir.Policy) (map[string]interface{}, []notifications.Notification) {
+	var notes []notifications.Notification
+	spec := map[string]interface{}{}
+	// The first matching rule applies, so denied CIDRs come first.
+	var rules []interface{}
+	if len(policy.IPDenyList) > 0 {
+		rules = append(rules, map[string]interface{}{
+			"action":    "Deny",
+			"principal": map[string]interface{}{"clientCIDRs": toInterfaces(policy.IPDenyList)},
+		})
+	}
+	defaultAction := "Allow"
+	if len(policy.IPAllowList) > 0 {
+		defaultAction = "Deny"
+		rules = append(rules, map[string]interface{}{
+			"action":    "Allow",
+			"principal": map[string]interface{}{"clientCIDRs": toInterfaces(policy.IPAllowList)},
+		})
+	}
+	if len(rules) > 0 {
+		spec["authorization"] = map[string]interface{}{"defaultAction": defaultAction, "rules": rules}
+	}
+	if policy.OIDC != nil {
+		// oauth2-proxy is replaced by the OIDC authentication of Envoy
+		// Gateway, configured with the provider and client of the proxy.
+		spec["oidc"] = map[string]interface{}{
+			"provider":     map[string]interface{}{"issuer": ""},
+			"clientID":     "",
+			"clientSecret": map[string]interface{}{"name": route.Name + "-oidc-client"},
+			"redirectURL":  policy.OIDC.RedirectURL,
+			"logoutPath":   policy.OIDC.LogoutPath,
+		}
+		notes = append(notes, notifications.NewWarning("SecurityPolicy %s/%s replaces oauth2-proxy with OpenID Connect authentication, its provider issuer and clientID must be set to the ones of oauth2-proxy and its client secret stored in Secret %s/%s-oidc-client", route.Namespace, route.Name, route.Namespace, route.Name))
+	} else if policy.ExtAuth != nil {
+		backendRef, path, err := extAuthBackend(policy.ExtAuth.URL, route.Namespace)
+		if err != nil {
+			notes = append(notes, notifications.NewWarning("External authentication of HTTPRoute %s/%s is not converted: %v", route.Namespace, route.Name, err))
+		} else {
+			http := map[string]interface{}{"backendRef": backendRef}
+			if path != "" {
+				http["path"] = path
+			}
+			if len(policy.ExtAuth.ResponseHeaders) > 0 {
+				http["headersToBackend"] = toInterfaces(policy.ExtAuth.ResponseHeaders)
+			}
+			spec["extAuth"] = map[string]interface{}{"http": http}
+			if ns := backendRef["namespace"]; ns != route.Namespace {
+				notes = append(notes, notifications.NewInfo("SecurityPolicy %s/%s references the authentication Service %s/%s, which requires a ReferenceGrant in namespace %s", route.Namespace, route.Name, ns, backendRef["name"], ns))
+			}
+		}
+	}
+	return spec, notes
+}
+
+// corazaImage is the Coraza WebAssembly extension of Envoy, which runs
+// ModSecurity rules.
+const corazaImage = "ghcr.io/corazawaf/coraza-proxy-wasm:latest"
+
+// wafSpec returns the EnvoyExtensionPolicy running Coraza with the rules of
+// the WAF. As with ingress-nginx, the recommended configuration only detects
+// attacks unless the rules enable blocking.
+func wafSpec(waf *ir.WAF) map[string]interface{} {
+	directives := []interface{}{"Include @recommended-conf"}
+	if waf.OWASPCoreRules {
+		directives = append(directives, "Include @crs-setup-conf", "Include @owasp_crs/*.conf")
+	}
+	for _, line := range strings.Split(waf.Rules, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			directives = append(directives, line)
+		}
+	}
+	return map[string]interface{}{
+		"wasm": []interface{}{
+			map[string]interface{}{
+				"name": "coraza-waf",
+				"code": map[string]interface{}{
+					"type":  "Image",
+					"image": map[string]interface{}{"url": corazaImage},
+				},
+				"config": map[string]interface{}{
+					"directives_map":     map[string]interface{}{"default": directives},
+					"default_directives": "default",
+				},
+			},
+		},
+	}
+}
+
+// tlsVersions are the Envoy Gateway TLS versions of the NGINX protocols.
+var tlsVersions = map[string]string{
+	"TLSv1":   "1.0",
+	"TLSv1.1": "1.1",
+	"TLSv1.2": "1.2",
+	"TLSv1.3": "1.3",
+}
+
+// tlsSpec returns the ClientTrafficPolicy TLS settings of the policy. The
+// accepted protocols become the range of versions between the oldest and
+// the newest of them.
+func tlsSpec(policy *ir.Policy) map[string]interface{} {
+	tls := map[string]interface{}{}
+	if len(policy.TLSCiphers) > 0 {
+		tls["ciphers"] = toInterfaces(policy.TLSCiphers)
+	}
+	var versions []string
+	for _, protocol := range policy.TLSProtocols {
+		if version, ok := tlsVersions[protocol]; ok {
+			versions = append(versions, version)
+		}
+	}
+	if len(versions) > 0 {
+		sort.Strings(versions)
+		tls["minVersion"] = versions[0]
+		tls["maxVersion"] = versions[len(versions)-1]
+	}
+	return tls
+}
+
+func clientValidationTLS(gw ir.Gateway, listener ir.Listener) (map[string]interface{}, []notifications.Notification) {
+	var notes []notifications.Notification
+	ca := listener.ClientValidation.CACertificates
+	ref := map[string]interface{}{"group": "", "kind": "Secret", "name": ca.Name}
+	if ca.Namespace != gw.Namespace {
+		ref["namespace"] = ca.Namespace
+		notes = append(notes, notifications.NewInfo("ClientTrafficPolicy %s/%s-%s references the CA certificates Secret %s, which requires a ReferenceGrant in namespace %s", gw.Namespace, gw.Name, listener.SectionName("https"), ca, ca.Namespace))
+	}
+	validation := map[string]interface{}{"caCertificateRefs": []interface{}{ref}}
+	if listener.ClientValidation.Optional {
+		validation["optional"] = true
+	}
+	return map[string]interface{}{"clientValidation": validation}, notes
+}
+
+// extAuthBackend returns the Service backendRef and path of an
+// authentication URL. Envoy Gateway only calls authentication services
+// running in the cluster.
+func extAuthBackend(rawURL, namespace string) (map[string]interface{}, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+	if u.Scheme != "http" {
+		return nil, "", fmt.Errorf("URL %s doesn't use plain HTTP", rawURL)
+	}
+	labels := strings.Split(strings.TrimSuffix(u.Hostname(), ".cluster.local"), ".")
+	switch {
+	case len(labels) == 1:
+	case len(labels) == 2 || len(labels) == 3 && labels[2] == "svc":
+		namespace = labels[1]
+	default:
+		return nil, "", fmt.Errorf("URL %s doesn't point to a Service of the cluster", rawURL)
+	}
+	port := int64(80)
+	if p := u.Port(); p != "" {
+		port, err = strconv.ParseInt(p, 10, 32)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid port in URL %s", rawURL)
+		}
+	}
+	backendRef := map[string]interface{}{"name": labels[0], "namespace": namespace, "port": port}
+	return backendRef, u.Path, nil
+}
+
+func newPolicy(kind, namespace, name string, targetRef, spec map[string]interface{}) unstructured.Unstructured {
+	policy := unstructured.Unstructured{Object: map[string]interface{}{}}
+	policy.SetGroupVersionKind(schema.GroupVersionKind{Group: policyGroup, Version: policyVersion, Kind: kind})
+	policy.SetNamespace(namespace)
+	policy.SetName(name)
+	spec["targetRef"] = targetRef
+	policy.Object["spec"] = spec
+	return policy
+}
+
+// formatDuration formats d as a Gateway API Duration.
+func formatDuration(d time.Duration) string {
+	if d%time.Second == 0 {
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+	return fmt.Sprintf("%dms", d/time.Millisecond)
+}
+
+// quantity formats a number of bytes as a Kubernetes quantity.
+func quantity(bytes int64) string {
+	return resource.NewQuantity(bytes, resource.BinarySI).String()
+}
+
+func toInterfaces(values []string) []interface{} {
+	var items []interface{}
+	for _, v := range values {
+		items = append(items, v)
+	}
+	return items
+}
@@ -0,0 +1,29 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envoygateway_test
+
+import (
+	"testing"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/i2gwtest"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/targets/envoygateway"
+)
+
+func TestGolden(t *testing.T) {
+	i2gwtest.Run(t, "testdata", i2gw.ConvertOptions{TargetImplementation: envoygateway.Name})
+}
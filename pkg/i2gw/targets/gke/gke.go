@@ -0,0 +1,395 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gke emits the policies of the GKE Gateway controller equivalent to
+// the BackendConfigs and FrontendConfigs of GKE Ingresses.
+package gke
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Name is the name of the target implementation.
+const Name = "gke"
+
+const (
+	backendConfigAnnotation     = "cloud.google.com/backend-config"
+	betaBackendConfigAnnotation = "beta.cloud.google.com/backend-config"
+	frontendConfigAnnotation    = "networking.gke.io/v1beta1.FrontendConfig"
+	staticIPAnnotation          = "kubernetes.io/ingress.global-static-ip-name"
+
+	policyGroup   = "networking.gke.io"
+	policyVersion = "v1"
+)
+
+var (
+	serviceGVK        = schema.GroupVersionKind{Version: "v1", Kind: "Service"}
+	backendConfigGVK  = schema.GroupVersionKind{Group: "cloud.google.com", Version: "v1", Kind: "BackendConfig"}
+	frontendConfigGVK = schema.GroupVersionKind{Group: "networking.gke.io", Version: "v1beta1", Kind: "FrontendConfig"}
+)
+
+// IngressAnnotations are the annotations of Ingresses the emitter converts.
+var IngressAnnotations = []string{frontendConfigAnnotation, staticIPAnnotation}
+
+// InputKinds are the kinds of objects, besides Ingresses, the emitter reads
+// from the input.
+var InputKinds = []schema.GroupVersionKind{serviceGVK, backendConfigGVK, frontendConfigGVK}
+
+// Emitter emits HealthCheckPolicies and GCPBackendPolicies for the
+// BackendConfigs of the Services used as backends, and GCPGatewayPolicies for
+// the FrontendConfigs of the Ingresses.
+type Emitter struct{}
+
+// NewEmitter returns the GKE emitter.
+func NewEmitter() *Emitter {
+	return &Emitter{}
+}
+
+func (e *Emitter) Name() string {
+	return Name
+}
+
+func (e *Emitter) Emit(result ir.IR) ([]unstructured.Unstructured, []notifications.Notification, []error) {
+	c := &converter{
+		services:        map[types.NamespacedName]corev1.Service{},
+		backendConfigs:  map[types.NamespacedName]unstructured.Unstructured{},
+		frontendConfigs: map[types.NamespacedName]unstructured.Unstructured{},
+	}
+	for _, obj := range result.Objects {
+		key := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+		switch obj.GroupVersionKind() {
+		case serviceGVK:
+			svc := corev1.Service{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &svc); err != nil {
+				c.errors = append(c.errors, fmt.Errorf("failed to decode Service %s: %w", key, err))
+				continue
+			}
+			c.services[key] = svc
+		case backendConfigGVK:
+			c.backendConfigs[key] = obj
+		case frontendConfigGVK:
+			c.frontendConfigs[key] = obj
+		}
+	}
+
+	c.convertBackendConfigs(result)
+	c.convertFrontendConfigs(result)
+	return c.objects, c.notes, c.errors
+}
+
+type converter struct {
+	services        map[types.NamespacedName]corev1.Service
+	backendConfigs  map[types.NamespacedName]unstructured.Unstructured
+	frontendConfigs map[types.NamespacedName]unstructured.Unstructured
+
+	objects []unstructured.Unstructured
+	notes   []notifications.Notification
+	errors  []error
+}
+
+func (c *converter) convertBackendConfigs(result ir.IR) {
+	portsByService := map[types.NamespacedName][]int32{}
+	var serviceKeys []types.NamespacedName
+	for _, route := range result.HTTPRoutes {
+		for _, rule := range route.Rules {
+			for _, backend := range rule.Backends {
+				if (backend.Group != nil && *backend.Group != "") || (backend.Kind != nil && *backend.Kind != "Service") || backend.Port == nil {
+					continue
+				}
+				namespace := route.Namespace
+				if backend.Namespace != nil {
+					namespace = string(*backend.Namespace)
+				}
+				key := types.NamespacedName{Namespace: namespace, Name: string(backend.Name)}
+				if _, ok := portsByService[key]; !ok {
+					serviceKeys = append(serviceKeys, key)
+				}
+				portsByService[key] = appendPort(portsByService[key], int32(*backend.Port))
+			}
+		}
+	}
+	sort.Slice(serviceKeys, func(i, j int) bool { return serviceKeys[i].String() < serviceKeys[j].String() })
+
+	for _, key := range serviceKeys {
+		svc, ok := c.services[key]
+		if !ok {
+			continue
+		}
+		bcName := c.backendConfigName(svc, portsByService[key])
+		if bcName == "" {
+			continue
+		}
+		bc, ok := c.backendConfigs[types.NamespacedName{Namespace: key.Namespace, Name: bcName}]
+		if !ok {
+			c.notes = append(c.notes, notifications.NewWarning("BackendConfig %s/%s used by Service %s was not found in the input, no GKE policies are generated for it", key.Namespace, bcName, key))
+			continue
+		}
+		c.convertBackendConfig(bc, svc)
+	}
+}
+
+func appendPort(ports []int32, port int32) []int32 {
+	for _, p := range ports {
+		if p == port {
+			return ports
+		}
+	}
+	return append(ports, port)
+}
+
+// backendConfigName returns the BackendConfig used by the given ports of the
+// Service. GKE policies apply to whole Services, so if the ports use
+// different BackendConfigs only the first one is converted.
+func (c *converter) backendConfigName(svc corev1.Service, ports []int32) string {
+	annotation, ok := svc.Annotations[backendConfigAnnotation]
+	if !ok {
+		annotation, ok = svc.Annotations[betaBackendConfigAnnotation]
+	}
+	if !ok {
+		return ""
+	}
+	var ref struct {
+		Default string            `json:"default"`
+		Ports   map[string]string `json:"ports"`
+	}
+	if err := json.Unmarshal([]byte(annotation), &ref); err != nil {
+		c.errors = append(c.errors, fmt.Errorf("Service %s/%s has an invalid %s annotation: %w", svc.Namespace, svc.Name, backendConfigAnnotation, err))
+		return ""
+	}
+
+	var names []string
+	for _, port := range ports {
+		name := ref.Default
+		if n, ok := ref.Ports[strconv.Itoa(int(port))]; ok {
+			name = n
+		}
+		for _, sp := range svc.Spec.Ports {
+			if n, ok := ref.Ports[sp.Name]; ok && sp.Port == port {
+				name = n
+			}
+		}
+		if name != "" && !contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	if len(names) > 1 {
+		c.notes = append(c.notes, notifications.NewWarning("Service %s/%s uses BackendConfigs %s for different ports, GKE policies apply to the whole Service so only %s is converted", svc.Namespace, svc.Name, strings.Join(names, ", "), names[0]))
+	}
+	return names[0]
+}
+
+func (c *converter) convertBackendConfig(bc unstructured.Unstructured, svc corev1.Service) {
+	spec, _, _ := unstructured.NestedMap(bc.Object, "spec")
+	targetRef := map[string]interface{}{"group": "", "kind": "Service", "name": svc.Name}
+	handled := map[string]bool{}
+
+	if hc, ok := spec["healthCheck"].(map[string]interface{}); ok {
+		handled["healthCheck"] = true
+		policy := newPolicy("HealthCheckPolicy", svc.Namespace, svc.Name, targetRef)
+		def := map[string]interface{}{}
+		for _, field := range []string{"checkIntervalSec", "timeoutSec", "healthyThreshold", "unhealthyThreshold"} {
+			if v, ok := hc[field]; ok {
+				def[field] = v
+			}
+		}
+		hcType := "HTTP"
+		if t, ok := hc["type"].(string); ok && t != "" {
+			hcType = t
+		}
+		check := map[string]interface{}{}
+		if v, ok := hc["port"]; ok {
+			check["port"] = v
+			check["portSpecification"] = "USE_FIXED_PORT"
+		}
+		if v, ok := hc["requestPath"]; ok && hcType != "TCP" {
+			check["requestPath"] = v
+		}
+		def["config"] = map[string]interface{}{
+			"type":                   hcType,
+			healthCheckField(hcType): check,
+		}
+		_ = unstructured.SetNestedMap(policy.Object, def, "spec", "default")
+		c.objects = append(c.objects, policy)
+	}
+
+	def := map[string]interface{}{}
+	if v, ok := spec["timeoutSec"]; ok {
+		handled["timeoutSec"] = true
+		def["timeoutSec"] = v
+	}
+	if v, ok, _ := unstructured.NestedFieldNoCopy(spec, "connectionDraining", "drainingTimeoutSec"); ok {
+		handled["connectionDraining"] = true
+		def["connectionDraining"] = map[string]interface{}{"drainingTimeoutSec": v}
+	}
+	if sa, ok := spec["sessionAffinity"].(map[string]interface{}); ok {
+		handled["sessionAffinity"] = true
+		affinity := map[string]interface{}{}
+		if v, ok := sa["affinityType"]; ok {
+			affinity["type"] = v
+		}
+		if v, ok := sa["affinityCookieTtlSec"]; ok {
+			affinity["cookieTtlSec"] = v
+		}
+		def["sessionAffinity"] = affinity
+	}
+	if v, ok, _ := unstructured.NestedString(spec, "securityPolicy", "name"); ok {
+		handled["securityPolicy"] = true
+		def["securityPolicy"] = v
+	}
+	if l, ok := spec["logging"].(map[string]interface{}); ok {
+		handled["logging"] = true
+		logging := map[string]interface{}{}
+		if v, ok := l["enable"]; ok {
+			logging["enabled"] = v
+		}
+		if v, ok := toFloat(l["sampleRate"]); ok {
+			// BackendConfigs use a rate between 0 and 1, GCPBackendPolicies
+			// an integer between 0 and 1,000,000.
+			logging["sampleRate"] = int64(v * 1000000)
+		}
+		def["logging"] = logging
+	}
+	if i, ok := spec["iap"].(map[string]interface{}); ok {
+		handled["iap"] = true
+		iap := map[string]interface{}{}
+		if v, ok := i["enabled"]; ok {
+			iap["enabled"] = v
+		}
+		if v, ok, _ := unstructured.NestedString(i, "oauthclientCredentials", "secretName"); ok {
+			iap["oauth2ClientSecret"] = map[string]interface{}{"name": v}
+		}
+		def["iap"] = iap
+	}
+	if len(def) > 0 {
+		policy := newPolicy("GCPBackendPolicy", svc.Namespace, svc.Name, targetRef)
+		_ = unstructured.SetNestedMap(policy.Object, def, "spec", "default")
+		c.objects = append(c.objects, policy)
+	}
+
+	var unhandled []string
+	for field := range spec {
+		if !handled[field] {
+			unhandled = append(unhandled, field)
+		}
+	}
+	if len(unhandled) > 0 {
+		sort.Strings(unhandled)
+		c.notes = append(c.notes, notifications.NewWarning("BackendConfig %s/%s fields %s have no GKE Gateway policy equivalent and were not converted", bc.GetNamespace(), bc.GetName(), strings.Join(unhandled, ", ")))
+	}
+}
+
+func healthCheckField(hcType string) string {
+	switch hcType {
+	case "HTTPS":
+		return "httpsHealthCheck"
+	case "HTTP2":
+		return "http2HealthCheck"
+	case "GRPC":
+		return "grpcHealthCheck"
+	case "TCP":
+		return "tcpHealthCheck"
+	default:
+		return "httpHealthCheck"
+	}
+}
+
+func (c *converter) convertFrontendConfigs(result ir.IR) {
+	annotationsByIngress := map[types.NamespacedName]map[string]string{}
+	for _, ingress := range result.Ingresses {
+		annotationsByIngress[types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}] = ingress.Annotations
+	}
+
+	for _, gw := range result.Gateways {
+		var names []string
+		for _, source := range gw.Ingresses {
+			annotations := annotationsByIngress[source]
+			if name := annotations[frontendConfigAnnotation]; name != "" && !contains(names, name) {
+				names = append(names, name)
+			}
+			if ip := annotations[staticIPAnnotation]; ip != "" {
+				c.notes = append(c.notes, notifications.NewInfo("Ingress %s uses the static IP address %s, set it as a NamedAddress in spec.addresses of Gateway %s/%s", source, ip, gw.Namespace, gw.Name))
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+		if len(names) > 1 {
+			c.notes = append(c.notes, notifications.NewWarning("Ingresses of Gateway %s/%s use FrontendConfigs %s, only %s is converted", gw.Namespace, gw.Name, strings.Join(names, ", "), names[0]))
+		}
+		fc, ok := c.frontendConfigs[types.NamespacedName{Namespace: gw.Namespace, Name: names[0]}]
+		if !ok {
+			c.notes = append(c.notes, notifications.NewWarning("FrontendConfig %s/%s was not found in the input, no GCPGatewayPolicy is generated for Gateway %s", gw.Namespace, names[0], gw.Name))
+			continue
+		}
+
+		if enabled, _, _ := unstructured.NestedBool(fc.Object, "spec", "redirectToHttps", "enabled"); enabled {
+			c.notes = append(c.notes, notifications.NewWarning("FrontendConfig %s/%s redirects HTTP to HTTPS, add an HTTPRoute with a RequestRedirect filter attached to the HTTP listeners of Gateway %s", fc.GetNamespace(), fc.GetName(), gw.Name))
+		}
+		sslPolicy, ok, _ := unstructured.NestedString(fc.Object, "spec", "sslPolicy")
+		if !ok {
+			continue
+		}
+		targetRef := map[string]interface{}{"group": "gateway.networking.k8s.io", "kind": "Gateway", "name": gw.Name}
+		policy := newPolicy("GCPGatewayPolicy", gw.Namespace, gw.Name, targetRef)
+		_ = unstructured.SetNestedField(policy.Object, sslPolicy, "spec", "default", "sslPolicy")
+		c.objects = append(c.objects, policy)
+	}
+}
+
+func newPolicy(kind, namespace, name string, targetRef map[string]interface{}) unstructured.Unstructured {
+	policy := unstructured.Unstructured{Object: map[string]interface{}{}}
+	policy.SetGroupVersionKind(schema.GroupVersionKind{Group: policyGroup, Version: policyVersion, Kind: kind})
+	policy.SetNamespace(namespace)
+	policy.SetName(name)
+	_ = unstructured.SetNestedMap(policy.Object, targetRef, "spec", "targetRef")
+	return policy
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package istio emits the Istio resources equivalent to the traffic policies
+// of Ingresses.
+package istio
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Name is the name of the target implementation.
+const Name = "istio"
+
+var destinationRuleGVK = schema.GroupVersionKind{
+	Group:   "networking.istio.io",
+	Version: "v1beta1",
+	Kind:    "DestinationRule",
+}
+
+// Emitter emits a DestinationRule for each backend Service of Ingresses with
+// session affinity, a load balancing algorithm or connect timeouts.
+type Emitter struct{}
+
+// NewEmitter returns the Istio emitter.
+func NewEmitter() *Emitter {
+	return &Emitter{}
+}
+
+func (e *Emitter) Name() string {
+	return Name
+}
+
+// trafficPolicy is the part of an ir.Policy converted to a DestinationRule.
+type trafficPolicy struct {
+	hash      *ir.ConsistentHash
+	algorithm ir.LoadBalanceAlgorithm
+	connect   time.Duration
+}
+
+func (e *Emitter) Emit(result ir.IR) ([]unstructured.Unstructured, []notifications.Notification, []error) {
+	var objects []unstructured.Unstructured
+	var notes []notifications.Notification
+
+	// Services are ordered by first use, the first Ingress using a Service
+	// configures its DestinationRule.
+	var services []types.NamespacedName
+	policies := map[types.NamespacedName]trafficPolicy{}
+	sources := map[types.NamespacedName]types.NamespacedName{}
+
+	for _, route := range result.HTTPRoutes {
+		for _, rule := range route.Rules {
+			for _, backend := range rule.Backends {
+				p, ok := route.Policies[backend.Source]
+				if !ok {
+					continue
+				}
+				policy := toTrafficPolicy(p)
+				if policy == (trafficPolicy{}) || backend.Kind != nil && *backend.Kind != "Service" {
+					continue
+				}
+
+				service := types.NamespacedName{Namespace: route.Namespace, Name: string(backend.Name)}
+				if backend.Namespace != nil {
+					service.Namespace = string(*backend.Namespace)
+				}
+				existing, ok := policies[service]
+				if !ok {
+					services = append(services, service)
+					policies[service] = policy
+					sources[service] = backend.Source
+					continue
+				}
+				if !reflect.DeepEqual(existing, policy) && sources[service] != backend.Source {
+					notes = append(notes, notifications.NewWarning("Ingresses %s and %s configure different load balancing or timeouts for Service %s, the DestinationRule uses the ones of %s", sources[service], backend.Source, service, sources[service]))
+				}
+			}
+		}
+	}
+
+	for _, service := range services {
+		objects = append(objects, newDestinationRule(service, policies[service]))
+	}
+	return objects, notes, nil
+}
+
+func toTrafficPolicy(p ir.Policy) trafficPolicy {
+	policy := trafficPolicy{hash: p.ConsistentHash, algorithm: p.LoadBalance}
+	if p.Timeouts != nil {
+		policy.connect = p.Timeouts.Connect
+	}
+	return policy
+}
+
+func newDestinationRule(service types.NamespacedName, policy trafficPolicy) unstructured.Unstructured {
+	spec := map[string]interface{}{}
+	if policy.hash != nil {
+		spec["loadBalancer"] = map[string]interface{}{"consistentHash": consistentHash(policy.hash)}
+	} else if simple, ok := simpleLoadBalancers[policy.algorithm]; ok {
+		spec["loadBalancer"] = map[string]interface{}{"simple": simple}
+	}
+	if policy.connect != 0 {
+		spec["connectionPool"] = map[string]interface{}{
+			"tcp": map[string]interface{}{"connectTimeout": formatDuration(policy.connect)},
+		}
+	}
+
+	rule := unstructured.Unstructured{Object: map[string]interface{}{}}
+	rule.SetGroupVersionKind(destinationRuleGVK)
+	rule.SetNamespace(service.Namespace)
+	rule.SetName(service.Name)
+	rule.Object["spec"] = map[string]interface{}{
+		"host":          fmt.Sprintf("%s.%s.svc.cluster.local", service.Name, service.Namespace),
+		"trafficPolicy": spec,
+	}
+	return rule
+}
+
+var simpleLoadBalancers = map[ir.LoadBalanceAlgorithm]string{
+	ir.LoadBalanceRoundRobin:   "ROUND_ROBIN",
+	ir.LoadBalanceLeastRequest: "LEAST_REQUEST",
+}
+
+func consistentHash(hash *ir.ConsistentHash) map[string]interface{} {
+	switch {
+	case hash.Cookie != nil:
+		cookie := map[string]interface{}{"name": hash.Cookie.Name, "ttl": formatDuration(hash.Cookie.TTL)}
+		if hash.Cookie.Path != "" {
+			cookie["path"] = hash.Cookie.Path
+		}
+		return map[string]interface{}{"httpCookie": cookie}
+	case hash.Header != "":
+		return map[string]interface{}{"httpHeaderName": hash.Header}
+	case hash.QueryParameter != "":
+		return map[string]interface{}{"httpQueryParameterName": hash.QueryParameter}
+	default:
+		return map[string]interface{}{"useSourceIp": true}
+	}
+}
+
+// formatDuration formats d as a protobuf Duration.
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%gs", d.Seconds())
+}
@@ -0,0 +1,333 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package nginxgatewayfabric emits the NGINX Gateway Fabric resources
+// carrying the NGINX configuration of Ingresses that Gateway API can't
+// express.
+package nginxgatewayfabric
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// Name is the name of the target implementation.
+const Name = "nginx-gateway-fabric"
+
+const (
+	filterGroup   = "gateway.nginx.org"
+	filterVersion = "v1alpha1"
+	filterKind    = "SnippetsFilter"
+
+	clientSettingsPolicyKind   = "ClientSettingsPolicy"
+	observabilityPolicyKind    = "ObservabilityPolicy"
+	observabilityPolicyVersion = "v1alpha2"
+
+	serverContext   = "http.server"
+	locationContext = "http.server.location"
+
+	defaultProxyBufferSize = "4k"
+)
+
+// Emitter emits a SnippetsFilter for each Ingress with NGINX snippets or
+// proxy buffer settings, and references it from the HTTPRoute rules
+// converted from the Ingress. Request body size limits and tracing are
+// converted to a ClientSettingsPolicy and an ObservabilityPolicy for each
+// HTTPRoute.
+type Emitter struct{}
+
+// NewEmitter returns the NGINX Gateway Fabric emitter.
+func NewEmitter() *Emitter {
+	return &Emitter{}
+}
+
+func (e *Emitter) Name() string {
+	return Name
+}
+
+// TransformIR adds an ExtensionRef filter referencing the SnippetsFilter of
+// each Ingress to the rules converted from it.
+func (e *Emitter) TransformIR(result *ir.IR) []notifications.Notification {
+	for i := range result.HTTPRoutes {
+		route := &result.HTTPRoutes[i]
+		for j := range route.Rules {
+			rule := &route.Rules[j]
+			for _, source := range ruleSources(*rule) {
+				if !hasSnippets(route.Policies[source]) {
+					continue
+				}
+				rule.Filters = append(rule.Filters, gatewayv1.HTTPRouteFilter{
+					Type: gatewayv1.HTTPRouteFilterExtensionRef,
+					ExtensionRef: &gatewayv1.LocalObjectReference{
+						Group: filterGroup,
+						Kind:  filterKind,
+						Name:  gatewayv1.ObjectName(filterName(source)),
+					},
+				})
+			}
+		}
+	}
+	return nil
+}
+
+func (e *Emitter) Emit(result ir.IR) ([]unstructured.Unstructured, []notifications.Notification, []error) {
+	var objects []unstructured.Unstructured
+	var notes []notifications.Notification
+
+	// An Ingress with several hosts is converted to several HTTPRoutes that
+	// share its SnippetsFilter.
+	emitted := map[types.NamespacedName]bool{}
+	for _, route := range result.HTTPRoutes {
+		var sources []types.NamespacedName
+		for source, policy := range route.Policies {
+			if hasSnippets(policy) && !emitted[source] {
+				sources = append(sources, source)
+			}
+		}
+		sort.Slice(sources, func(i, j int) bool { return sources[i].String() < sources[j].String() })
+
+		for _, source := range sources {
+			emitted[source] = true
+			policy := route.Policies[source]
+			objects = append(objects, newSnippetsFilter(source, policy))
+			if policy.Snippets != nil {
+				notes = append(notes, notifications.NewWarning("The NGINX snippets of Ingress %s are copied as is to SnippetsFilter %s/%s, check that they are valid for NGINX Gateway Fabric", source, source.Namespace, filterName(source)))
+			}
+		}
+	}
+
+	if len(objects) > 0 {
+		notes = append(notes, notifications.NewInfo("SnippetsFilters are only applied when snippets are enabled in NGINX Gateway Fabric"))
+	}
+
+	for _, route := range result.HTTPRoutes {
+		for _, convert := range []func(ir.HTTPRoute) (*unstructured.Unstructured, []notifications.Notification){clientSettingsPolicy, observabilityPolicy} {
+			policy, policyNotes := convert(route)
+			notes = append(notes, policyNotes...)
+			if policy != nil {
+				objects = append(objects, *policy)
+			}
+		}
+	}
+	return objects, notes, nil
+}
+
+// clientSettingsPolicy returns the ClientSettingsPolicy limiting the size of
+// the request bodies of the route and the time idle client connections are
+// kept open. Policies attach to whole routes, so the settings of the first
+// Ingress configuring them apply when they differ.
+func clientSettingsPolicy(route ir.HTTPRoute) (*unstructured.Unstructured, []notifications.Notification) {
+	var notes []notifications.Notification
+	spec := map[string]interface{}{}
+	if sources := policySources(route, func(p ir.Policy) bool { return p.MaxRequestBodySize != nil }); len(sources) > 0 {
+		size := *route.Policies[sources[0]].MaxRequestBodySize
+		for _, source := range sources[1:] {
+			if *route.Policies[source].MaxRequestBodySize != size {
+				notes = append(notes, notifications.NewWarning("Ingresses %s and %s of HTTPRoute %s/%s limit the size of request bodies differently, the limit of %s applies to the whole route", sources[0], source, route.Namespace, route.Name, sources[0]))
+			}
+		}
+		spec["body"] = map[string]interface{}{"maxSize": formatSize(size)}
+	}
+	if sources := policySources(route, func(p ir.Policy) bool { return p.KeepAliveTimeout != 0 }); len(sources) > 0 {
+		timeout := route.Policies[sources[0]].KeepAliveTimeout
+		for _, source := range sources[1:] {
+			if route.Policies[source].KeepAliveTimeout != timeout {
+				notes = append(notes, notifications.NewWarning("Ingresses %s and %s of HTTPRoute %s/%s keep idle client connections open differently, the timeout of %s applies to the whole route", sources[0], source, route.Namespace, route.Name, sources[0]))
+			}
+		}
+		spec["keepAlive"] = map[string]interface{}{"timeout": map[string]interface{}{"server": fmt.Sprintf("%ds", int64(timeout/time.Second))}}
+	}
+	if len(spec) == 0 {
+		return nil, notes
+	}
+
+	spec["targetRef"] = routeTargetRef(route)
+	policy := newRoutePolicy(clientSettingsPolicyKind, filterVersion, route, spec)
+	return &policy, notes
+}
+
+// observabilityPolicy returns the ObservabilityPolicy tracing the requests
+// of the route, when the first Ingress tracing them does. Requests are
+// traced when their parent span is sampled if the Ingress trusts incoming
+// spans, and all of them otherwise.
+func observabilityPolicy(route ir.HTTPRoute) (*unstructured.Unstructured, []notifications.Notification) {
+	sources := policySources(route, func(p ir.Policy) bool { return p.Tracing != nil })
+	if len(sources) == 0 {
+		return nil, nil
+	}
+
+	var notes []notifications.Notification
+	tracing := *route.Policies[sources[0]].Tracing
+	for _, source := range sources[1:] {
+		if *route.Policies[source].Tracing != tracing {
+			notes = append(notes, notifications.NewWarning("Ingresses %s and %s of HTTPRoute %s/%s trace requests differently, the tracing of %s applies to the whole route", sources[0], source, route.Namespace, route.Name, sources[0]))
+		}
+	}
+	var withoutTracing []string
+	for _, source := range ruleSourcesOf(route) {
+		if route.Policies[source].Tracing == nil {
+			withoutTracing = append(withoutTracing, source.String())
+		}
+	}
+	if len(withoutTracing) > 0 {
+		notes = append(notes, notifications.NewWarning("The tracing of Ingress %s applies to the whole HTTPRoute %s/%s, including the paths of Ingresses %s", sources[0], route.Namespace, route.Name, strings.Join(withoutTracing, ", ")))
+	}
+
+	strategy := map[string]interface{}{"strategy": "ratio"}
+	if tracing.ParentBased {
+		strategy = map[string]interface{}{"strategy": "parent"}
+	}
+	policy := newRoutePolicy(observabilityPolicyKind, observabilityPolicyVersion, route, map[string]interface{}{
+		"targetRefs": []interface{}{routeTargetRef(route)},
+		"tracing":    strategy,
+	})
+	return &policy, notes
+}
+
+// policySources returns the Ingresses of the route whose policy has, sorted.
+func policySources(route ir.HTTPRoute, has func(ir.Policy) bool) []types.NamespacedName {
+	var sources []types.NamespacedName
+	for source, policy := range route.Policies {
+		if has(policy) {
+			sources = append(sources, source)
+		}
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].String() < sources[j].String() })
+	return sources
+}
+
+// ruleSourcesOf returns the Ingresses the rules of the route were converted
+// from, sorted.
+func ruleSourcesOf(route ir.HTTPRoute) []types.NamespacedName {
+	seen := map[types.NamespacedName]bool{}
+	var sources []types.NamespacedName
+	for _, rule := range route.Rules {
+		for _, source := range ruleSources(rule) {
+			if !seen[source] {
+				seen[source] = true
+				sources = append(sources, source)
+			}
+		}
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].String() < sources[j].String() })
+	return sources
+}
+
+func routeTargetRef(route ir.HTTPRoute) map[string]interface{} {
+	return map[string]interface{}{"group": gatewayv1.GroupName, "kind": "HTTPRoute", "name": route.Name}
+}
+
+func newRoutePolicy(kind, version string, route ir.HTTPRoute, spec map[string]interface{}) unstructured.Unstructured {
+	policy := unstructured.Unstructured{Object: map[string]interface{}{}}
+	policy.SetGroupVersionKind(schema.GroupVersionKind{Group: filterGroup, Version: version, Kind: kind})
+	policy.SetNamespace(route.Namespace)
+	policy.SetName(route.Name)
+	policy.Object["spec"] = spec
+	return policy
+}
+
+// formatSize formats a number of bytes as an NGINX size.
+func formatSize(bytes int64) string {
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"g", 1 << 30}, {"m", 1 << 20}, {"k", 1 << 10}} {
+		if bytes != 0 && bytes%unit.size == 0 {
+			return fmt.Sprintf("%d%s", bytes/unit.size, unit.suffix)
+		}
+	}
+	return fmt.Sprintf("%d", bytes)
+}
+
+func newSnippetsFilter(source types.NamespacedName, policy ir.Policy) unstructured.Unstructured {
+	var snippets []interface{}
+	var location []string
+	if policy.Snippets != nil {
+		if policy.Snippets.Server != "" {
+			snippets = append(snippets, map[string]interface{}{"context": serverContext, "value": policy.Snippets.Server})
+		}
+		if policy.Snippets.Location != "" {
+			location = append(location, policy.Snippets.Location)
+		}
+	}
+	location = append(location, proxyBufferDirectives(policy.ProxyBuffers)...)
+	if len(location) > 0 {
+		snippets = append(snippets, map[string]interface{}{"context": locationContext, "value": strings.Join(location, "\n")})
+	}
+
+	filter := unstructured.Unstructured{Object: map[string]interface{}{}}
+	filter.SetGroupVersionKind(schema.GroupVersionKind{Group: filterGroup, Version: filterVersion, Kind: filterKind})
+	filter.SetNamespace(source.Namespace)
+	filter.SetName(filterName(source))
+	filter.Object["spec"] = map[string]interface{}{"snippets": snippets}
+	return filter
+}
+
+func proxyBufferDirectives(buffers *ir.ProxyBuffers) []string {
+	if buffers == nil {
+		return nil
+	}
+	var directives []string
+	if buffers.Buffering != nil {
+		value := "off"
+		if *buffers.Buffering {
+			value = "on"
+		}
+		directives = append(directives, fmt.Sprintf("proxy_buffering %s;", value))
+	}
+	if buffers.Size != "" {
+		directives = append(directives, fmt.Sprintf("proxy_buffer_size %s;", buffers.Size))
+	}
+	if buffers.Number != 0 {
+		size := buffers.Size
+		if size == "" {
+			size = defaultProxyBufferSize
+		}
+		directives = append(directives, fmt.Sprintf("proxy_buffers %d %s;", buffers.Number, size))
+	}
+	return directives
+}
+
+func hasSnippets(policy ir.Policy) bool {
+	return policy.Snippets != nil || policy.ProxyBuffers != nil
+}
+
+// ruleSources returns the Ingresses a rule was converted from, in the order
+// of its backends.
+func ruleSources(rule ir.HTTPRouteRule) []types.NamespacedName {
+	var sources []types.NamespacedName
+	seen := map[types.NamespacedName]bool{}
+	for _, backend := range rule.Backends {
+		if !seen[backend.Source] {
+			seen[backend.Source] = true
+			sources = append(sources, backend.Source)
+		}
+	}
+	return sources
+}
+
+func filterName(source types.NamespacedName) string {
+	return source.Name + "-snippets"
+}
@@ -0,0 +1,92 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"sigs.k8s.io/yaml"
+)
+
+// TemplateData is what templates are executed with.
+type TemplateData struct {
+	Resources Resources
+	Report    Report
+	// Objects are the resources in the order they are written, as
+	// unstructured content without status and the metadata populated by
+	// the API server, ready to be embedded in other formats.
+	Objects []map[string]interface{}
+}
+
+// templateFuncs are the functions templates can call in addition to the
+// text/template builtins.
+var templateFuncs = template.FuncMap{
+	"toYAML": func(v interface{}) (string, error) {
+		out, err := yaml.Marshal(v)
+		return string(out), err
+	},
+	"toJSON": func(v interface{}) (string, error) {
+		out, err := json.Marshal(v)
+		return string(out), err
+	},
+	// indent prefixes the non-empty lines of s with spaces.
+	"indent": func(spaces int, s string) string {
+		lines := strings.Split(s, "\n")
+		for i, line := range lines {
+			if line != "" {
+				lines[i] = strings.Repeat(" ", spaces) + line
+			}
+		}
+		return strings.Join(lines, "\n")
+	},
+}
+
+// ParseTemplateFile parses the text/template in path, with the toYAML,
+// toJSON and indent functions.
+func ParseTemplateFile(path string) (*template.Template, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// WriteTemplate executes tmpl with the resources and report of a
+// conversion, so that users can render them in their own formats.
+func WriteTemplate(w io.Writer, tmpl *template.Template, resources Resources, report Report) error {
+	data := TemplateData{Resources: resources, Report: report}
+	for _, obj := range resourceObjects(resources) {
+		u, err := cleanObject(obj)
+		if err != nil {
+			return fmt.Errorf("failed to convert %s %s: %w", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName(), err)
+		}
+		data.Objects = append(data.Objects, u.Object)
+	}
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+	return nil
+}
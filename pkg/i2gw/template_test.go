@@ -0,0 +1,76 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_WriteTemplate(t *testing.T) {
+	route := gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example-com"},
+		Spec:       gatewayv1.HTTPRouteSpec{Hostnames: []gatewayv1.Hostname{"example.com"}},
+	}
+	route.SetGroupVersionKind(httpRouteGVK)
+	resources := Resources{HTTPRoutes: []gatewayv1.HTTPRoute{route}}
+	report := Report{Notifications: []Notification{notifications.NewWarning("check the routes")}}
+
+	testCases := []struct {
+		name     string
+		template string
+		expected string
+	}{{
+		name:     "typed resources and report",
+		template: `{{ range .Resources.HTTPRoutes }}{{ .Name }} {{ index .Spec.Hostnames 0 }}{{ end }}{{ range .Report.Notifications }} {{ .Type }}: {{ .Message }}{{ end }}`,
+		expected: "example-com example.com WARNING: check the routes",
+	}, {
+		name:     "objects as JSON",
+		template: `{{ range .Objects }}{{ toJSON . }}{{ end }}`,
+		expected: `{"apiVersion":"gateway.networking.k8s.io/v1","kind":"HTTPRoute","metadata":{"name":"example-com","namespace":"default"},"spec":{"hostnames":["example.com"]}}`,
+	}, {
+		name:     "objects as indented YAML",
+		template: "objects:\n{{ range .Objects }}{{ toYAML .metadata | indent 2 }}{{ end }}",
+		expected: "objects:\n  name: example-com\n  namespace: default\n",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "output.tmpl")
+			if err := os.WriteFile(path, []byte(tc.template), 0o600); err != nil {
+				t.Fatalf("Failed to write template: %v", err)
+			}
+			tmpl, err := ParseTemplateFile(path)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var buf bytes.Buffer
+			if err := WriteTemplate(&buf, tmpl, resources, report); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, buf.String()); diff != "" {
+				t.Errorf("Unexpected output, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// toHTTPRouteTimeouts converts the read and send timeouts of an Ingress.
+// They bound each read from and write to the backend connection, so the
+// longest of them, once connected, bounds a backend request. The request
+// timeout is set to the same value since implementations may default it to
+// less than the timeouts of the Ingress.
+func toHTTPRouteTimeouts(features *ir.IngressFeatures) *gatewayv1.HTTPRouteTimeouts {
+	if features == nil || features.Policy == nil || features.Policy.Timeouts == nil {
+		return nil
+	}
+	t := features.Policy.Timeouts
+	longest := t.Read
+	if t.Send > longest {
+		longest = t.Send
+	}
+	if longest == 0 {
+		return nil
+	}
+	timeout := formatDuration(t.Connect + longest)
+	return &gatewayv1.HTTPRouteTimeouts{Request: &timeout, BackendRequest: &timeout}
+}
+
+// formatDuration formats d as a Gateway API Duration, which allows at most
+// 5 digits per unit.
+func formatDuration(d time.Duration) gatewayv1.Duration {
+	switch {
+	case d%time.Second != 0 && d < 100000*time.Millisecond:
+		return gatewayv1.Duration(fmt.Sprintf("%dms", d.Milliseconds()))
+	case d < 100000*time.Second:
+		return gatewayv1.Duration(fmt.Sprintf("%ds", (d+time.Second-1)/time.Second))
+	default:
+		return gatewayv1.Duration(fmt.Sprintf("%dm", (d+time.Minute-1)/time.Minute))
+	}
+}
+
+// setHTTPRouteRetries sets the experimental retry field of the rules
+// converted from Ingresses that retry requests.
+func setHTTPRouteRetries(result *ir.IR) {
+	for i := range result.HTTPRoutes {
+		route := &result.HTTPRoutes[i]
+		for j := range route.Rules {
+			rule := &route.Rules[j]
+			retry := ruleRetry(*route, *rule)
+			if retry == nil {
+				continue
+			}
+			rule.Retry = &gatewayv1.HTTPRouteRetry{}
+			if retry.Attempts != 0 {
+				attempts := retry.Attempts
+				rule.Retry.Attempts = &attempts
+			}
+			for _, code := range retry.StatusCodes {
+				rule.Retry.Codes = append(rule.Retry.Codes, gatewayv1.HTTPRouteRetryStatusCode(code))
+			}
+		}
+	}
+}
+
+// extendRequestTimeouts leaves time for the retries of requests in the
+// request timeout of rules, up to the retry timeout.
+func extendRequestTimeouts(result *ir.IR) {
+	for i := range result.HTTPRoutes {
+		route := &result.HTTPRoutes[i]
+		for j := range route.Rules {
+			rule := &route.Rules[j]
+			retry := ruleRetry(*route, *rule)
+			if retry == nil || retry.Attempts == 0 || rule.Timeouts == nil || rule.Timeouts.BackendRequest == nil {
+				continue
+			}
+			backendRequest, err := time.ParseDuration(string(*rule.Timeouts.BackendRequest))
+			if err != nil {
+				continue
+			}
+			request := backendRequest * time.Duration(retry.Attempts+1)
+			if retry.Timeout != 0 && retry.Timeout < request {
+				request = retry.Timeout
+			}
+			if request > backendRequest {
+				value := formatDuration(request)
+				rule.Timeouts.Request = &value
+			}
+		}
+	}
+}
+
+// ruleRetry returns the retry policy of the first Ingress of the rule with a
+// policy.
+func ruleRetry(route ir.HTTPRoute, rule ir.HTTPRouteRule) *ir.Retry {
+	for _, backend := range rule.Backends {
+		if policy, ok := route.Policies[backend.Source]; ok {
+			return policy.Retry
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_toHTTPRouteTimeouts(t *testing.T) {
+	testCases := []struct {
+		name     string
+		timeouts *ir.Timeouts
+		expected gatewayv1.Duration
+	}{{
+		name:     "no timeouts",
+		timeouts: nil,
+	}, {
+		name:     "connect timeout only",
+		timeouts: &ir.Timeouts{Connect: 5 * time.Second},
+	}, {
+		name:     "read timeout",
+		timeouts: &ir.Timeouts{Read: 60 * time.Second},
+		expected: "60s",
+	}, {
+		name:     "longest of read and send timeouts plus connect timeout",
+		timeouts: &ir.Timeouts{Connect: 5 * time.Second, Read: 30 * time.Second, Send: 120 * time.Second},
+		expected: "125s",
+	}, {
+		name:     "timeout too long for seconds",
+		timeouts: &ir.Timeouts{Read: 100000 * time.Second},
+		expected: "1667m",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			features := &ir.IngressFeatures{Policy: &ir.Policy{Timeouts: tc.timeouts}}
+			var expected *gatewayv1.HTTPRouteTimeouts
+			if tc.expected != "" {
+				expected = &gatewayv1.HTTPRouteTimeouts{Request: &tc.expected, BackendRequest: &tc.expected}
+			}
+			if diff := cmp.Diff(expected, toHTTPRouteTimeouts(features)); diff != "" {
+				t.Errorf("Unexpected timeouts, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func Test_setHTTPRouteRetries(t *testing.T) {
+	source := types.NamespacedName{Namespace: "default", Name: "web"}
+	backendRequest := gatewayv1.Duration("30s")
+	newIR := func(retry *ir.Retry) ir.IR {
+		timeout := backendRequest
+		return ir.IR{HTTPRoutes: []ir.HTTPRoute{{
+			Namespace: "default",
+			Name:      "example-com",
+			Rules: []ir.HTTPRouteRule{{
+				Timeouts: &gatewayv1.HTTPRouteTimeouts{Request: &timeout, BackendRequest: &timeout},
+				Backends: []ir.Backend{{Source: source}},
+			}},
+			Policies: map[types.NamespacedName]ir.Policy{source: {Retry: retry}},
+		}}}
+	}
+
+	testCases := []struct {
+		name            string
+		retry           *ir.Retry
+		expectedRetry   *gatewayv1.HTTPRouteRetry
+		expectedRequest gatewayv1.Duration
+	}{{
+		name:            "no retries",
+		expectedRequest: "30s",
+	}, {
+		name:            "retries with status codes",
+		retry:           &ir.Retry{Attempts: 2, StatusCodes: []int{502, 503}},
+		expectedRetry:   &gatewayv1.HTTPRouteRetry{Attempts: intPtr(2), Codes: []gatewayv1.HTTPRouteRetryStatusCode{502, 503}},
+		expectedRequest: "90s",
+	}, {
+		name:            "request timeout capped by retry timeout",
+		retry:           &ir.Retry{Attempts: 2, Timeout: time.Minute},
+		expectedRetry:   &gatewayv1.HTTPRouteRetry{Attempts: intPtr(2)},
+		expectedRequest: "60s",
+	}, {
+		name:            "default number of retries",
+		retry:           &ir.Retry{},
+		expectedRetry:   &gatewayv1.HTTPRouteRetry{},
+		expectedRequest: "30s",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := newIR(tc.retry)
+			setHTTPRouteRetries(&result)
+			extendRequestTimeouts(&result)
+
+			rule := result.HTTPRoutes[0].Rules[0]
+			if diff := cmp.Diff(tc.expectedRetry, rule.Retry); diff != "" {
+				t.Errorf("Unexpected retry, diff (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedRequest, *rule.Timeouts.Request); diff != "" {
+				t.Errorf("Unexpected request timeout, diff (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(backendRequest, *rule.Timeouts.BackendRequest); diff != "" {
+				t.Errorf("Unexpected backend request timeout, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,143 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	apimachineryvalidation "k8s.io/apimachinery/pkg/util/validation"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// validateTLSMode checks a TLS mode of the listeners of a host.
+func validateTLSMode(mode gatewayv1.TLSModeType) error {
+	switch mode {
+	case gatewayv1.TLSModeTerminate, gatewayv1.TLSModePassthrough:
+		return nil
+	}
+	return fmt.Errorf("unknown TLS mode %q, supported ones are: %s, %s", mode, gatewayv1.TLSModeTerminate, gatewayv1.TLSModePassthrough)
+}
+
+// validateTLSModes checks the TLS modes of the hosts, by hostname or
+// wildcard hostname.
+func validateTLSModes(modes map[string]gatewayv1.TLSModeType) error {
+	for host, mode := range modes {
+		var msgs []string
+		if strings.HasPrefix(host, "*.") {
+			msgs = apimachineryvalidation.IsWildcardDNS1123Subdomain(host)
+		} else {
+			msgs = apimachineryvalidation.IsDNS1123Subdomain(host)
+		}
+		if len(msgs) > 0 {
+			return fmt.Errorf("invalid host %q for the TLS mode: %s", host, strings.Join(msgs, ", "))
+		}
+		if err := validateTLSMode(mode); err != nil {
+			return fmt.Errorf("%w for host %s", err, host)
+		}
+	}
+	return nil
+}
+
+// tlsModeFor returns the TLS mode of a host set by --tls-mode, its hostname
+// taking precedence over a wildcard hostname.
+func (a *ingressAggregator) tlsModeFor(host string) (gatewayv1.TLSModeType, bool) {
+	if mode, ok := a.tlsModes[host]; ok {
+		return mode, true
+	}
+	if i := strings.Index(host, "."); i > 0 {
+		mode, ok := a.tlsModes["*"+host[i:]]
+		return mode, ok
+	}
+	return "", false
+}
+
+// addTLSModes applies the TLS modes of the hosts of an Ingress, set by its
+// tls-mode annotation, then --tls-mode, then its provider forwarding their
+// TLS connections. The rules of the Passthrough hosts are recorded as a
+// stream, and the Ingress is returned with the rules to convert to
+// HTTPRoutes, unless there are none left.
+func (a *ingressAggregator) addTLSModes(ingress networkingv1.Ingress, ingressClass, gateway string, features *ir.IngressFeatures, annotationMode gatewayv1.TLSModeType) (networkingv1.Ingress, *ir.IngressFeatures, bool) {
+	passthrough := features.Stream != nil && features.Stream.Protocol == streamProtocolTLS
+	if (features.Stream != nil && !passthrough) || (annotationMode == "" && len(a.tlsModes) == 0) {
+		return ingress, features, a.addStream(ingress, ingressClass, gateway, features)
+	}
+	source := fmt.Sprintf("%s/%s", ingress.Namespace, ingress.Name)
+	modes := map[string]gatewayv1.TLSModeType{}
+	modeFor := func(host string) gatewayv1.TLSModeType {
+		if mode, ok := modes[host]; ok {
+			return mode
+		}
+		mode, ok := annotationMode, annotationMode != ""
+		if !ok {
+			mode, ok = a.tlsModeFor(host)
+		}
+		switch {
+		case !ok && passthrough:
+			mode = gatewayv1.TLSModePassthrough
+		case !ok:
+			mode = gatewayv1.TLSModeTerminate
+		case mode == gatewayv1.TLSModePassthrough && !passthrough:
+			a.notifications = append(a.notifications, notifications.NewInfo("The TLS connections to host %q of Ingress %s are passed through to its backends, as its TLS mode is %s", host, source, mode))
+		case mode == gatewayv1.TLSModeTerminate && passthrough:
+			a.notifications = append(a.notifications, notifications.NewInfo("The TLS connections to host %q of Ingress %s are terminated by the Gateway and its requests converted to HTTPRoutes, as its TLS mode is %s", host, source, mode))
+		}
+		modes[host] = mode
+		return mode
+	}
+
+	var streamRules, httpRules []networkingv1.IngressRule
+	for _, rule := range ingress.Spec.Rules {
+		if modeFor(rule.Host) == gatewayv1.TLSModePassthrough {
+			streamRules = append(streamRules, rule)
+		} else {
+			httpRules = append(httpRules, rule)
+		}
+	}
+	if len(ingress.Spec.Rules) == 0 && ingress.Spec.DefaultBackend != nil && modeFor("") == gatewayv1.TLSModePassthrough {
+		return ingress, features, a.addStream(ingress, ingressClass, gateway, withStream(features, &ir.Stream{Protocol: streamProtocolTLS}))
+	}
+
+	httpFeatures := withStream(features, nil)
+	if len(streamRules) == 0 {
+		return ingress, httpFeatures, false
+	}
+	stream := features.Stream
+	if stream == nil {
+		stream = &ir.Stream{Protocol: streamProtocolTLS}
+	}
+	streamIngress := ingress
+	streamIngress.Spec.Rules = streamRules
+	if !a.addStream(streamIngress, ingressClass, gateway, withStream(features, stream)) {
+		return ingress, httpFeatures, false
+	}
+	if len(httpRules) == 0 {
+		return ingress, features, true
+	}
+	httpIngress := ingress
+	httpIngress.Spec.Rules = httpRules
+	return httpIngress, httpFeatures, false
+}
+
+// withStream returns a copy of features forwarding connections as stream.
+func withStream(features *ir.IngressFeatures, stream *ir.Stream) *ir.IngressFeatures {
+	f := *features
+	f.Stream = stream
+	return &f
+}
@@ -0,0 +1,139 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_tlsModes(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	ingress := func(annotations map[string]string) networkingv1.Ingress {
+		ingress := ingressWithPath("multi", "/", &iPrefix, serviceBackend("web", 443), annotations)
+		api := *ingress.Spec.Rules[0].DeepCopy()
+		api.Host = "api.example.com"
+		ingress.Spec.Rules = append(ingress.Spec.Rules, api)
+		return ingress
+	}
+	sslPassthrough := map[string]string{"nginx.ingress.kubernetes.io/ssl-passthrough": "true"}
+
+	testCases := []struct {
+		name           string
+		ingress        networkingv1.Ingress
+		tlsModes       map[string]gatewayv1.TLSModeType
+		wantTLSRoutes  []string
+		wantHTTPRoutes []string
+	}{{
+		name:           "terminated by default",
+		ingress:        ingress(nil),
+		wantHTTPRoutes: []string{"test/api-example-com", "test/example-com"},
+	}, {
+		name:           "passthrough host",
+		ingress:        ingress(nil),
+		tlsModes:       map[string]gatewayv1.TLSModeType{"api.example.com": gatewayv1.TLSModePassthrough},
+		wantTLSRoutes:  []string{"test/api-example-com"},
+		wantHTTPRoutes: []string{"test/example-com"},
+	}, {
+		name:    "hostname takes precedence over wildcard",
+		ingress: ingress(nil),
+		tlsModes: map[string]gatewayv1.TLSModeType{
+			"*.example.com":   gatewayv1.TLSModePassthrough,
+			"api.example.com": gatewayv1.TLSModeTerminate,
+		},
+		wantHTTPRoutes: []string{"test/api-example-com", "test/example-com"},
+	}, {
+		name:          "passthrough annotation",
+		ingress:       ingress(map[string]string{TLSModeAnnotation: "Passthrough"}),
+		tlsModes:      map[string]gatewayv1.TLSModeType{"api.example.com": gatewayv1.TLSModeTerminate},
+		wantTLSRoutes: []string{"test/example-com", "test/api-example-com"},
+	}, {
+		name:           "terminated host of ssl-passthrough",
+		ingress:        ingress(sslPassthrough),
+		tlsModes:       map[string]gatewayv1.TLSModeType{"example.com": gatewayv1.TLSModeTerminate},
+		wantTLSRoutes:  []string{"test/api-example-com"},
+		wantHTTPRoutes: []string{"test/example-com"},
+	}, {
+		name:           "terminate annotation with ssl-passthrough",
+		ingress:        ingress(map[string]string{"nginx.ingress.kubernetes.io/ssl-passthrough": "true", TLSModeAnnotation: "Terminate"}),
+		wantHTTPRoutes: []string{"test/api-example-com", "test/example-com"},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			input := inputResources{ingresses: []networkingv1.Ingress{tc.ingress}}
+			resources, report := convertInput(input, ConvertOptions{ExperimentalFeatures: []string{ExperimentalTLSRoute}, TLSModes: tc.tlsModes})
+			if len(report.Errors) > 0 {
+				t.Fatalf("unexpected errors: %v", report.Errors)
+			}
+			var gotTLSRoutes, gotHTTPRoutes []string
+			for _, route := range resources.TLSRoutes {
+				gotTLSRoutes = append(gotTLSRoutes, route.Namespace+"/"+route.Name)
+			}
+			for _, route := range resources.HTTPRoutes {
+				gotHTTPRoutes = append(gotHTTPRoutes, route.Namespace+"/"+route.Name)
+			}
+			if diff := cmp.Diff(tc.wantTLSRoutes, gotTLSRoutes); diff != "" {
+				t.Errorf("unexpected TLSRoutes (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantHTTPRoutes, gotHTTPRoutes); diff != "" {
+				t.Errorf("unexpected HTTPRoutes (-want +got):\n%s", diff)
+			}
+			for _, gw := range resources.Gateways {
+				for _, l := range gw.Spec.Listeners {
+					if l.Protocol == gatewayv1.TLSProtocolType && (l.TLS == nil || l.TLS.Mode == nil || *l.TLS.Mode != gatewayv1.TLSModePassthrough) {
+						t.Errorf("expected listener %s to pass TLS through, got %v", l.Name, l.TLS)
+					}
+				}
+			}
+		})
+	}
+}
+
+func Test_validateTLSModes(t *testing.T) {
+	testCases := []struct {
+		name        string
+		modes       map[string]gatewayv1.TLSModeType
+		expectError string
+	}{{
+		name:  "valid",
+		modes: map[string]gatewayv1.TLSModeType{"example.com": gatewayv1.TLSModePassthrough, "*.example.com": gatewayv1.TLSModeTerminate},
+	}, {
+		name:        "unknown mode",
+		modes:       map[string]gatewayv1.TLSModeType{"example.com": "passthrough"},
+		expectError: `unknown TLS mode "passthrough", supported ones are: Terminate, Passthrough for host example.com`,
+	}, {
+		name:        "invalid host",
+		modes:       map[string]gatewayv1.TLSModeType{"Example.com": gatewayv1.TLSModePassthrough},
+		expectError: `invalid host "Example.com" for the TLS mode: a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')`,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateTLSModes(tc.modes)
+			var got string
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tc.expectError {
+				t.Errorf("expected error %q, got %q", tc.expectError, got)
+			}
+		})
+	}
+}
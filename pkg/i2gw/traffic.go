@@ -0,0 +1,516 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// maxTrafficRedirects is the number of redirects of the HTTPRoutes followed
+// when routing a request, such as from HTTP to HTTPS.
+const maxTrafficRedirects = 3
+
+// TrafficRequest is a sample request routed by VerifyTraffic.
+type TrafficRequest struct {
+	Method  string
+	URL     *url.URL
+	Headers http.Header
+}
+
+func (r TrafficRequest) String() string {
+	s := r.Method + " " + r.URL.String()
+	var names []string
+	for name := range r.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range r.Headers[name] {
+			s += " " + name + ":" + value
+		}
+	}
+	return s
+}
+
+// NewTrafficRequest returns a request of the method, GET if empty, to the
+// absolute http or https URL with the headers, as Name:Value.
+func NewTrafficRequest(method, rawURL string, headers []string) (TrafficRequest, error) {
+	request := TrafficRequest{Method: http.MethodGet, Headers: http.Header{}}
+	if method != "" {
+		request.Method = strings.ToUpper(method)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return request, fmt.Errorf("invalid URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return request, fmt.Errorf("%q is not an absolute http or https URL", rawURL)
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	request.URL = u
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return request, fmt.Errorf("invalid header %q, it must be Name:Value", header)
+		}
+		request.Headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	return request, nil
+}
+
+// ParseTrafficRequests reads one request per line, as an optional method,
+// GET by default, an absolute http or https URL, and Name:Value headers,
+// separated by spaces. Empty lines and lines starting with # are ignored.
+func ParseTrafficRequests(r io.Reader) ([]TrafficRequest, error) {
+	var requests []TrafficRequest
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		method := ""
+		if !strings.Contains(fields[0], "://") {
+			method = fields[0]
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("line %d: missing URL", line)
+		}
+		request, err := NewTrafficRequest(method, fields[0], fields[1:])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		requests = append(requests, request)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read requests: %w", err)
+	}
+	return requests, nil
+}
+
+// TrafficBackend is a backend a request is routed to.
+type TrafficBackend struct {
+	Kind      string
+	Namespace string
+	Name      string
+	// Port is the number or the name of the port of Services.
+	Port string
+}
+
+func (b TrafficBackend) String() string {
+	s := b.Kind + " " + b.Namespace + "/" + b.Name
+	if b.Port != "" {
+		s += ":" + b.Port
+	}
+	return s
+}
+
+// TrafficDecision is where a request is routed to.
+type TrafficDecision struct {
+	// Backends are the backends the request is sent to, empty if it
+	// isn't routed. Requests routed to several backends are split between
+	// them, or for Ingresses, sent to one of the Ingresses routing the same
+	// host and path, such as canaries.
+	Backends []TrafficBackend
+	// Redirect is the URL the request is redirected to, if any.
+	Redirect string
+	// Route is what routed the request, such as "Ingress default/web".
+	Route string
+}
+
+func (d TrafficDecision) String() string {
+	var s string
+	switch {
+	case d.Redirect != "":
+		s = "redirect to " + d.Redirect
+	case len(d.Backends) > 0:
+		var backends []string
+		for _, b := range d.Backends {
+			backends = append(backends, b.String())
+		}
+		s = strings.Join(backends, ", ")
+	default:
+		s = "not routed"
+	}
+	if d.Route != "" {
+		s += " (" + d.Route + ")"
+	}
+	return s
+}
+
+// TrafficResult compares the routing of a request by the Ingresses and by
+// the generated Gateways and HTTPRoutes.
+type TrafficResult struct {
+	Request   TrafficRequest
+	Ingress   TrafficDecision
+	HTTPRoute TrafficDecision
+	// Redirects are the redirects of the HTTPRoutes followed before the
+	// HTTPRoute decision, such as from HTTP to HTTPS.
+	Redirects []string
+	Match     bool
+}
+
+// TrafficOptions configures VerifyTraffic.
+type TrafficOptions struct {
+	// ConvertOptions select the Ingresses and how they are converted.
+	ConvertOptions ConvertOptions
+	// ManifestFile, if set, is the path of a manifest whose Gateways and
+	// HTTPRoutes are compared with the Ingresses instead of their
+	// conversion.
+	ManifestFile string
+	Requests     []TrafficRequest
+}
+
+// VerifyTraffic routes every request with the Ingresses, as specified by
+// the Ingress API, and with the Gateways and HTTPRoutes they are converted
+// to, as specified by Gateway API, and tells whether they route it to the
+// same backends. The redirects of the HTTPRoutes are followed, since
+// Ingresses are commonly redirected to HTTPS by their controller. The
+// implementation-specific annotations of Ingresses aren't simulated, so
+// HTTPRoutes routing a request to some of the backends of the Ingresses
+// routing the same host and path, such as a canary split, match.
+func VerifyTraffic(ctx context.Context, opts TrafficOptions) ([]TrafficResult, error) {
+	target, err := lookupTargetImplementation(opts.ConvertOptions.TargetImplementation)
+	if err != nil {
+		return nil, err
+	}
+	input, _, err := readConvertInput(ctx, opts.ConvertOptions, target)
+	if err != nil {
+		return nil, err
+	}
+
+	gateways, httpRoutes, err := routingResources(ctx, opts.ConvertOptions, input, opts.ManifestFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []TrafficResult
+	for _, request := range opts.Requests {
+		result := TrafficResult{Request: request, Ingress: routeIngressRequest(input.ingresses, request)}
+		for current := request; ; {
+			result.HTTPRoute = routeGatewayRequest(gateways, httpRoutes, current)
+			if result.HTTPRoute.Redirect == "" || len(result.Redirects) == maxTrafficRedirects {
+				break
+			}
+			next, err := url.Parse(result.HTTPRoute.Redirect)
+			if err != nil {
+				break
+			}
+			result.Redirects = append(result.Redirects, result.HTTPRoute.String())
+			current = TrafficRequest{Method: current.Method, URL: next, Headers: current.Headers}
+		}
+		result.Match = trafficDecisionsMatch(result.Ingress, result.HTTPRoute)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// routingResources returns the Gateways and HTTPRoutes of the manifest
+// file, or else the ones the input is converted to with opts.
+func routingResources(ctx context.Context, opts ConvertOptions, input inputResources, manifestFile string) ([]gatewayv1.Gateway, []gatewayv1.HTTPRoute, error) {
+	if manifestFile != "" {
+		f, err := os.Open(manifestFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open manifest file: %w", err)
+		}
+		defer f.Close()
+		gateways, httpRoutes, err := decodeGatewayInput(f)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read manifest from %s: %w", manifestFile, err)
+		}
+		return gateways, httpRoutes, nil
+	}
+
+	convertOpts := opts
+	convertOpts.InputFile, convertOpts.Input, convertOpts.Client = "", nil, nil
+	convertOpts.Ingresses, convertOpts.IngressClasses, convertOpts.Objects = input.ingresses, input.ingressClasses, input.objects
+	resources, _, err := Convert(ctx, convertOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resources.Gateways, resources.HTTPRoutes, nil
+}
+
+// trafficDecisionsMatch tells whether the HTTPRoute decision routes the
+// request to some of the backends of the Ingress decision, and only to them.
+func trafficDecisionsMatch(ingress, httpRoute TrafficDecision) bool {
+	if httpRoute.Redirect != "" || len(ingress.Backends) == 0 || len(httpRoute.Backends) == 0 {
+		return httpRoute.Redirect == "" && len(ingress.Backends) == 0 && len(httpRoute.Backends) == 0
+	}
+	if len(ingress.Backends) == 1 {
+		return len(httpRoute.Backends) == 1 && ingress.Backends[0] == httpRoute.Backends[0]
+	}
+	for _, b := range httpRoute.Backends {
+		if !containsTrafficBackend(ingress.Backends, b) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsTrafficBackend(backends []TrafficBackend, backend TrafficBackend) bool {
+	for _, b := range backends {
+		if b == backend {
+			return true
+		}
+	}
+	return false
+}
+
+// trafficHostScore ranks how specifically a hostname matches host: 0 if it
+// doesn't, 1 if it's empty, and more for longer wildcards and exact
+// hostnames. Wildcards of Ingresses match a single label, the ones of
+// Gateway API any number of labels.
+func trafficHostScore(hostname, host string, singleLabel bool) int {
+	switch {
+	case hostname == "":
+		return 1
+	case hostname == host:
+		return 2 + 2*len(hostname)
+	case strings.HasPrefix(hostname, "*."):
+		suffix := hostname[1:]
+		prefix, ok := strings.CutSuffix(host, suffix)
+		if !ok || prefix == "" || (singleLabel && strings.Contains(prefix, ".")) {
+			return 0
+		}
+		return 1 + 2*len(hostname)
+	default:
+		return 0
+	}
+}
+
+// prefixPathMatches tells whether the path matches the prefix element-wise,
+// the trailing slash of the prefix being ignored.
+func prefixPathMatches(prefix, path string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// ingressPathCandidate is an Ingress path matching a request.
+type ingressPathCandidate struct {
+	hostScore int
+	exact     bool
+	length    int
+	backend   networkingv1.IngressBackend
+	ingress   types.NamespacedName
+	path      string
+}
+
+// routeIngressRequest routes the request as specified by the Ingress API:
+// the rules of the most specific host matching, then the longest path
+// matching, exact paths first. ImplementationSpecific paths match as
+// string prefixes, as ingress-nginx does. Requests no path matches are
+// sent to the default backends of the Ingresses with a rule for their host,
+// or else to the default backends of every Ingress.
+func routeIngressRequest(ingresses []networkingv1.Ingress, request TrafficRequest) TrafficDecision {
+	host := request.URL.Hostname()
+	path := request.URL.Path
+	var best []ingressPathCandidate
+	var hostDefaults, defaults []ingressPathCandidate
+	for _, ingress := range ingresses {
+		name := types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}
+		hostRule := false
+		for _, rule := range ingress.Spec.Rules {
+			hostScore := trafficHostScore(rule.Host, host, true)
+			if hostScore == 0 {
+				continue
+			}
+			hostRule = hostRule || rule.Host != ""
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, p := range rule.HTTP.Paths {
+				pathType := networkingv1.PathTypeImplementationSpecific
+				if p.PathType != nil {
+					pathType = *p.PathType
+				}
+				value := p.Path
+				if value == "" {
+					value = "/"
+				}
+				var matches bool
+				switch pathType {
+				case networkingv1.PathTypeExact:
+					matches = path == value
+				case networkingv1.PathTypePrefix:
+					matches = prefixPathMatches(value, path)
+				default:
+					matches = strings.HasPrefix(path, value)
+				}
+				if !matches {
+					continue
+				}
+				candidate := ingressPathCandidate{
+					hostScore: hostScore,
+					exact:     pathType == networkingv1.PathTypeExact,
+					length:    len(value),
+					backend:   p.Backend,
+					ingress:   name,
+					path:      value,
+				}
+				if len(best) > 0 && compareIngressCandidates(candidate, best[0]) < 0 {
+					continue
+				}
+				if len(best) > 0 && compareIngressCandidates(candidate, best[0]) > 0 {
+					best = nil
+				}
+				best = append(best, candidate)
+			}
+		}
+		if ingress.Spec.DefaultBackend != nil {
+			candidate := ingressPathCandidate{backend: *ingress.Spec.DefaultBackend, ingress: name}
+			defaults = append(defaults, candidate)
+			if hostRule {
+				hostDefaults = append(hostDefaults, candidate)
+			}
+		}
+	}
+
+	if len(best) == 0 {
+		best = hostDefaults
+		if len(best) == 0 {
+			best = defaults
+		}
+	}
+	var decision TrafficDecision
+	var sources []string
+	for _, candidate := range best {
+		backend := TrafficBackend{Namespace: candidate.ingress.Namespace}
+		if candidate.backend.Service != nil {
+			backend.Kind = "Service"
+			backend.Name = candidate.backend.Service.Name
+			backend.Port = candidate.backend.Service.Port.Name
+			if candidate.backend.Service.Port.Number != 0 {
+				backend.Port = strconv.Itoa(int(candidate.backend.Service.Port.Number))
+			}
+		} else if candidate.backend.Resource != nil {
+			backend.Kind = candidate.backend.Resource.Kind
+			backend.Name = candidate.backend.Resource.Name
+		}
+		if !containsTrafficBackend(decision.Backends, backend) {
+			decision.Backends = append(decision.Backends, backend)
+		}
+		source := "Ingress " + candidate.ingress.String() + " default backend"
+		if candidate.path != "" {
+			source = "Ingress " + candidate.ingress.String() + " path " + candidate.path
+		}
+		sources = append(sources, source)
+	}
+	decision.Route = strings.Join(sources, ", ")
+	return decision
+}
+
+// compareIngressCandidates orders the paths of Ingresses by precedence.
+func compareIngressCandidates(a, b ingressPathCandidate) int {
+	switch {
+	case a.hostScore != b.hostScore:
+		return a.hostScore - b.hostScore
+	case a.length != b.length:
+		return a.length - b.length
+	case a.exact != b.exact:
+		if a.exact {
+			return 1
+		}
+		return -1
+	default:
+		return 0
+	}
+}
+
+// WriteTrafficResults writes the results, the requests the Ingresses and
+// HTTPRoutes route differently first, followed by the number of requests
+// routed the same.
+func WriteTrafficResults(w io.Writer, results []TrafficResult) {
+	matched := 0
+	for _, match := range []bool{false, true} {
+		for _, result := range results {
+			if result.Match != match {
+				continue
+			}
+			status := "MISMATCH"
+			if match {
+				status = "MATCH"
+				matched++
+			}
+			fmt.Fprintf(w, "%s %s\n", status, result.Request)
+			fmt.Fprintf(w, "  Ingress:   %s\n", result.Ingress)
+			for _, redirect := range result.Redirects {
+				fmt.Fprintf(w, "  HTTPRoute: %s\n", redirect)
+			}
+			fmt.Fprintf(w, "  HTTPRoute: %s\n", result.HTTPRoute)
+		}
+	}
+	fmt.Fprintf(w, "%d of %d requests are routed the same by the Ingresses and the HTTPRoutes\n", matched, len(results))
+}
+
+// RunVerifyTraffic routes the requests of requestsFile with the Ingresses
+// and their conversion as the verify-traffic command does, exiting with an
+// error if any is routed differently. The Ingresses are read from the
+// cluster of the current kubeconfig if opts has no input file.
+func RunVerifyTraffic(opts TrafficOptions, requestsFile string) {
+	f, err := os.Open(requestsFile)
+	if err != nil {
+		fmt.Printf("failed to open requests file: %v\n", err)
+		os.Exit(1)
+	}
+	requests, err := ParseTrafficRequests(f)
+	f.Close()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	opts.Requests = requests
+
+	if opts.ConvertOptions.InputFile == "" {
+		cl, err := newClient("", client.Options{})
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		opts.ConvertOptions.Client = cl
+	}
+
+	ctx, cancel := runContext(0)
+	defer cancel()
+	results, err := VerifyTraffic(ctx, opts)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	WriteTrafficResults(os.Stdout, results)
+	for _, result := range results {
+		if !result.Match {
+			os.Exit(1)
+		}
+	}
+}
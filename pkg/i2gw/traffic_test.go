@@ -0,0 +1,184 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+func Test_ParseTrafficRequests(t *testing.T) {
+	testCases := []struct {
+		name        string
+		input       string
+		want        []string
+		expectError string
+	}{
+		{
+			name: "methods, headers and comments",
+			input: `# Sample requests
+https://example.com/app
+
+post http://example.com:8080/api?v=1 X-Canary:always Accept:text/html
+https://example.com`,
+			want: []string{
+				"GET https://example.com/app",
+				"POST http://example.com:8080/api?v=1 Accept:text/html X-Canary:always",
+				"GET https://example.com/",
+			},
+		},
+		{
+			name:        "relative URL",
+			input:       "GET /app",
+			expectError: `line 1: "/app" is not an absolute http or https URL`,
+		},
+		{
+			name:        "missing URL",
+			input:       "\nGET",
+			expectError: "line 2: missing URL",
+		},
+		{
+			name:        "invalid header",
+			input:       "https://example.com/ X-Canary",
+			expectError: `line 1: invalid header "X-Canary", it must be Name:Value`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requests, err := ParseTrafficRequests(strings.NewReader(tc.input))
+			if tc.expectError != "" {
+				if err == nil || err.Error() != tc.expectError {
+					t.Fatalf("Expected error %q, got %v", tc.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var got []string
+			for _, request := range requests {
+				got = append(got, request.String())
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected requests, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func Test_VerifyTraffic(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	iExact := networkingv1.PathTypeExact
+	iImplementationSpecific := networkingv1.PathTypeImplementationSpecific
+	web := ingressWithPath("web", "/app", &iPrefix, serviceBackend("web", 80), nil)
+	web.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "tls"}}
+	paths := &web.Spec.Rules[0].HTTP.Paths
+	*paths = append(*paths,
+		networkingv1.HTTPIngressPath{Path: "/app/login", PathType: &iExact, Backend: serviceBackend("login", 80)},
+		networkingv1.HTTPIngressPath{Path: "/static", PathType: &iImplementationSpecific, Backend: serviceBackend("static", 80)},
+	)
+	canary := ingressWithPath("web-canary", "/app", &iPrefix, serviceBackend("web-v2", 80), map[string]string{
+		"nginx.ingress.kubernetes.io/canary":        "true",
+		"nginx.ingress.kubernetes.io/canary-weight": "20",
+	})
+
+	testCases := []struct {
+		request       string
+		wantMatch     bool
+		wantIngress   string
+		wantHTTPRoute string
+		wantRedirects int
+	}{
+		{
+			request:       "https://example.com/app/login",
+			wantMatch:     true,
+			wantIngress:   "Service test/login:80 (Ingress test/web path /app/login)",
+			wantHTTPRoute: "Service test/login:80 (HTTPRoute test/example-com rule 0)",
+		},
+		{
+			request:       "https://example.com/app/cart",
+			wantMatch:     true,
+			wantIngress:   "Service test/web:80, Service test/web-v2:80 (Ingress test/web path /app, Ingress test/web-canary path /app)",
+			wantHTTPRoute: "Service test/web:80, Service test/web-v2:80 (HTTPRoute test/example-com rule 1)",
+		},
+		{
+			request:       "http://example.com/app/login",
+			wantMatch:     true,
+			wantIngress:   "Service test/login:80 (Ingress test/web path /app/login)",
+			wantHTTPRoute: "Service test/login:80 (HTTPRoute test/example-com rule 0)",
+			wantRedirects: 1,
+		},
+		{
+			// ImplementationSpecific paths are string prefixes for
+			// ingress-nginx, but converted to PathPrefix matches.
+			request:       "https://example.com/staticfiles",
+			wantMatch:     false,
+			wantIngress:   "Service test/static:80 (Ingress test/web path /static)",
+			wantHTTPRoute: "not routed",
+		},
+		{
+			request:       "https://other.example.com/app",
+			wantMatch:     true,
+			wantIngress:   "not routed",
+			wantHTTPRoute: "not routed",
+		},
+	}
+
+	var requests []TrafficRequest
+	for _, tc := range testCases {
+		parsed, err := ParseTrafficRequests(strings.NewReader(tc.request))
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", tc.request, err)
+		}
+		requests = append(requests, parsed...)
+	}
+	results, err := VerifyTraffic(context.Background(), TrafficOptions{
+		ConvertOptions: ConvertOptions{
+			Ingresses: []networkingv1.Ingress{web, canary},
+			HTTPSOnly: HTTPSOnlyRedirect,
+		},
+		Requests: requests,
+	})
+	if err != nil {
+		t.Fatalf("Failed to verify traffic: %v", err)
+	}
+	if len(results) != len(testCases) {
+		t.Fatalf("Expected %d results, got %d", len(testCases), len(results))
+	}
+
+	for i, tc := range testCases {
+		t.Run(tc.request, func(t *testing.T) {
+			result := results[i]
+			if result.Match != tc.wantMatch {
+				t.Errorf("Expected match %t, got %t", tc.wantMatch, result.Match)
+			}
+			if diff := cmp.Diff(tc.wantIngress, result.Ingress.String()); diff != "" {
+				t.Errorf("Unexpected Ingress decision, diff (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(tc.wantHTTPRoute, result.HTTPRoute.String()); diff != "" {
+				t.Errorf("Unexpected HTTPRoute decision, diff (-want +got): %s", diff)
+			}
+			if len(result.Redirects) != tc.wantRedirects {
+				t.Errorf("Expected %d redirects, got %v", tc.wantRedirects, result.Redirects)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	apimachineryvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// Limits and patterns enforced by the Gateway API CRD schemas and their CEL
+// validation rules, which are checked here since Gateway API doesn't provide
+// validation functions.
+const (
+	maxGatewayListeners    = 64
+	maxHTTPRouteHostnames  = 16
+	maxHTTPRouteParentRefs = 32
+	maxHTTPRouteRules      = 16
+	maxHTTPRouteMatches    = 8
+	maxHTTPRouteBackends   = 16
+	maxHTTPRouteFilters    = 16
+)
+
+var (
+	hostnameRegexp    = regexp.MustCompile(`^(\*\.)?[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	sectionNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+	invalidPathSequences = []string{"//", "/./", "/../", "%2f", "%2F", "#"}
+	invalidPathSuffixes  = []string{"/..", "/."}
+)
+
+// validateResources runs every generated object through the Gateway API
+// validation rules and returns one error per violation found.
+func validateResources(httpRoutes []gatewayv1.HTTPRoute, gateways []gatewayv1.Gateway) []error {
+	var errors []error
+	for i := range gateways {
+		gw := &gateways[i]
+		for _, err := range validateGateway(gw) {
+			errors = append(errors, fmt.Errorf("Gateway %s/%s is invalid: %v", gw.Namespace, gw.Name, err))
+		}
+	}
+	for i := range httpRoutes {
+		route := &httpRoutes[i]
+		for _, err := range validateHTTPRoute(route) {
+			errors = append(errors, fmt.Errorf("HTTPRoute %s/%s is invalid: %v", route.Namespace, route.Name, err))
+		}
+	}
+	return errors
+}
+
+func validateGateway(gw *gatewayv1.Gateway) field.ErrorList {
+	errs := validateObjectName(gw.Name, field.NewPath("metadata", "name"))
+
+	listenersPath := field.NewPath("spec", "listeners")
+	if len(gw.Spec.Listeners) > maxGatewayListeners {
+		errs = append(errs, field.TooMany(listenersPath, len(gw.Spec.Listeners), maxGatewayListeners))
+	}
+	names := map[gatewayv1.SectionName]struct{}{}
+	protocols := map[gatewayv1.PortNumber]gatewayv1.ProtocolType{}
+	for i, listener := range gw.Spec.Listeners {
+		path := listenersPath.Index(i)
+		if len(listener.Name) > 253 || !sectionNameRegexp.MatchString(string(listener.Name)) {
+			errs = append(errs, field.Invalid(path.Child("name"), listener.Name, "must be a valid DNS subdomain"))
+		}
+		if _, ok := names[listener.Name]; ok {
+			errs = append(errs, field.Duplicate(path.Child("name"), listener.Name))
+		}
+		names[listener.Name] = struct{}{}
+		if listener.Hostname != nil {
+			errs = append(errs, validateHostname(*listener.Hostname, path.Child("hostname"))...)
+		}
+		if listener.Port < 1 || listener.Port > 65535 {
+			errs = append(errs, field.Invalid(path.Child("port"), listener.Port, "must be between 1 and 65535"))
+		} else if protocol, ok := protocols[listener.Port]; ok && !compatibleProtocols(protocol, listener.Protocol) {
+			errs = append(errs, field.Invalid(path.Child("port"), listener.Port, fmt.Sprintf("is already used by %s listeners", protocol)))
+		} else {
+			protocols[listener.Port] = listener.Protocol
+		}
+		if listener.Protocol == gatewayv1.HTTPProtocolType && listener.TLS != nil {
+			errs = append(errs, field.Forbidden(path.Child("tls"), "must be empty for protocol HTTP"))
+		}
+	}
+	return errs
+}
+
+// compatibleProtocols returns whether listeners of two protocols can share a
+// port: HTTPS and TLS listeners both select the listener by SNI.
+func compatibleProtocols(a, b gatewayv1.ProtocolType) bool {
+	secure := func(p gatewayv1.ProtocolType) bool {
+		return p == gatewayv1.HTTPSProtocolType || p == gatewayv1.TLSProtocolType
+	}
+	return a == b || secure(a) && secure(b)
+}
+
+func validateHTTPRoute(route *gatewayv1.HTTPRoute) field.ErrorList {
+	errs := validateObjectName(route.Name, field.NewPath("metadata", "name"))
+
+	specPath := field.NewPath("spec")
+	if len(route.Spec.ParentRefs) > maxHTTPRouteParentRefs {
+		errs = append(errs, field.TooMany(specPath.Child("parentRefs"), len(route.Spec.ParentRefs), maxHTTPRouteParentRefs))
+	}
+	if len(route.Spec.Hostnames) > maxHTTPRouteHostnames {
+		errs = append(errs, field.TooMany(specPath.Child("hostnames"), len(route.Spec.Hostnames), maxHTTPRouteHostnames))
+	}
+	for i, hostname := range route.Spec.Hostnames {
+		errs = append(errs, validateHostname(hostname, specPath.Child("hostnames").Index(i))...)
+	}
+	if len(route.Spec.Rules) > maxHTTPRouteRules {
+		errs = append(errs, field.TooMany(specPath.Child("rules"), len(route.Spec.Rules), maxHTTPRouteRules))
+	}
+	for i, rule := range route.Spec.Rules {
+		rulePath := specPath.Child("rules").Index(i)
+		if len(rule.Matches) > maxHTTPRouteMatches {
+			errs = append(errs, field.TooMany(rulePath.Child("matches"), len(rule.Matches), maxHTTPRouteMatches))
+		}
+		if len(rule.BackendRefs) > maxHTTPRouteBackends {
+			errs = append(errs, field.TooMany(rulePath.Child("backendRefs"), len(rule.BackendRefs), maxHTTPRouteBackends))
+		}
+		if len(rule.Filters) > maxHTTPRouteFilters {
+			errs = append(errs, field.TooMany(rulePath.Child("filters"), len(rule.Filters), maxHTTPRouteFilters))
+		}
+		errs = append(errs, validateHTTPRouteFilters(rule.Filters, rule.Matches, rulePath.Child("filters"))...)
+		if len(rule.BackendRefs) > 0 && hasFilter(rule.Filters, gatewayv1.HTTPRouteFilterRequestRedirect) {
+			errs = append(errs, field.Forbidden(rulePath.Child("backendRefs"), "must be empty when a RequestRedirect filter is used"))
+		}
+		for j, match := range rule.Matches {
+			errs = append(errs, validateHTTPRouteMatch(match, rulePath.Child("matches").Index(j))...)
+		}
+		for j, backendRef := range rule.BackendRefs {
+			isService := (backendRef.Group == nil || *backendRef.Group == "") && (backendRef.Kind == nil || *backendRef.Kind == "Service")
+			if isService && backendRef.Port == nil {
+				errs = append(errs, field.Required(rulePath.Child("backendRefs").Index(j).Child("port"), "must be specified for Service references"))
+			}
+		}
+	}
+	return errs
+}
+
+// validateHTTPRouteFilters checks that only RequestMirror and ExtensionRef
+// filters are repeated, that redirects and rewrites are not combined, and
+// that prefix replacements apply to a single PathPrefix match.
+func validateHTTPRouteFilters(filters []gatewayv1.HTTPRouteFilter, matches []gatewayv1.HTTPRouteMatch, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	counts := map[gatewayv1.HTTPRouteFilterType]int{}
+	for i, filter := range filters {
+		if filter.Type != gatewayv1.HTTPRouteFilterExtensionRef && filter.Type != gatewayv1.HTTPRouteFilterRequestMirror {
+			counts[filter.Type]++
+			if counts[filter.Type] == 2 {
+				errs = append(errs, field.Invalid(path.Index(i).Child("type"), filter.Type, "cannot be used multiple times in the same rule"))
+			}
+		}
+		for _, modifier := range []*gatewayv1.HTTPPathModifier{redirectPath(filter), rewritePath(filter)} {
+			if modifier != nil && modifier.Type == gatewayv1.PrefixMatchHTTPPathModifier && !hasSinglePrefixMatch(matches) {
+				errs = append(errs, field.Invalid(path.Index(i), filter.Type, "exactly one PathPrefix match must be specified to replace the prefix match"))
+			}
+		}
+	}
+	if counts[gatewayv1.HTTPRouteFilterRequestRedirect] > 0 && counts[gatewayv1.HTTPRouteFilterURLRewrite] > 0 {
+		errs = append(errs, field.Invalid(path, gatewayv1.HTTPRouteFilterRequestRedirect, "RequestRedirect and URLRewrite filters cannot be used in the same rule"))
+	}
+	return errs
+}
+
+func redirectPath(filter gatewayv1.HTTPRouteFilter) *gatewayv1.HTTPPathModifier {
+	if filter.RequestRedirect == nil {
+		return nil
+	}
+	return filter.RequestRedirect.Path
+}
+
+func rewritePath(filter gatewayv1.HTTPRouteFilter) *gatewayv1.HTTPPathModifier {
+	if filter.URLRewrite == nil {
+		return nil
+	}
+	return filter.URLRewrite.Path
+}
+
+func hasSinglePrefixMatch(matches []gatewayv1.HTTPRouteMatch) bool {
+	return len(matches) == 1 && matches[0].Path != nil && matches[0].Path.Type != nil && *matches[0].Path.Type == gatewayv1.PathMatchPathPrefix
+}
+
+// validateHTTPRouteMatch checks that paths are absolute and normalized, that
+// regular expression paths are valid RE2 expressions, and that headers and
+// query parameters are matched at most once.
+func validateHTTPRouteMatch(match gatewayv1.HTTPRouteMatch, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if match.Path != nil && match.Path.Value != nil && match.Path.Type != nil && *match.Path.Type != gatewayv1.PathMatchRegularExpression {
+		value := *match.Path.Value
+		valuePath := path.Child("path", "value")
+		if !strings.HasPrefix(value, "/") {
+			errs = append(errs, field.Invalid(valuePath, value, "must be an absolute path"))
+		}
+		for _, seq := range invalidPathSequences {
+			if strings.Contains(value, seq) {
+				errs = append(errs, field.Invalid(valuePath, value, fmt.Sprintf("must not contain %q", seq)))
+			}
+		}
+		for _, suffix := range invalidPathSuffixes {
+			if strings.HasSuffix(value, suffix) {
+				errs = append(errs, field.Invalid(valuePath, value, fmt.Sprintf("must not end with %q", suffix)))
+			}
+		}
+	}
+	if match.Path != nil && match.Path.Value != nil && match.Path.Type != nil && *match.Path.Type == gatewayv1.PathMatchRegularExpression {
+		if _, err := regexp.Compile(*match.Path.Value); err != nil {
+			errs = append(errs, field.Invalid(path.Child("path", "value"), *match.Path.Value, fmt.Sprintf("must be a valid RE2 regular expression: %v", err)))
+		}
+	}
+
+	headers := map[string]bool{}
+	for i, header := range match.Headers {
+		// Header names are case-insensitive.
+		name := strings.ToLower(string(header.Name))
+		if headers[name] {
+			errs = append(errs, field.Duplicate(path.Child("headers").Index(i).Child("name"), header.Name))
+		}
+		headers[name] = true
+	}
+	queryParams := map[string]bool{}
+	for i, param := range match.QueryParams {
+		if queryParams[string(param.Name)] {
+			errs = append(errs, field.Duplicate(path.Child("queryParams").Index(i).Child("name"), param.Name))
+		}
+		queryParams[string(param.Name)] = true
+	}
+	return errs
+}
+
+func validateObjectName(name string, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for _, msg := range apimachineryvalidation.IsDNS1123Subdomain(name) {
+		errs = append(errs, field.Invalid(path, name, msg))
+	}
+	return errs
+}
+
+func validateHostname(hostname gatewayv1.Hostname, path *field.Path) field.ErrorList {
+	if len(hostname) > 253 || !hostnameRegexp.MatchString(string(hostname)) {
+		return field.ErrorList{field.Invalid(path, hostname, "must be a valid hostname, optionally prefixed with a wildcard label")}
+	}
+	return nil
+}
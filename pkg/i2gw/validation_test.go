@@ -0,0 +1,162 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_validateResources(t *testing.T) {
+	testCases := []struct {
+		name         string
+		httpRoutes   []gatewayv1.HTTPRoute
+		gateways     []gatewayv1.Gateway
+		expectErrors []string
+	}{{
+		name: "valid resources",
+		httpRoutes: []gatewayv1.HTTPRoute{{
+			ObjectMeta: metav1.ObjectMeta{Name: "example-com", Namespace: "test"},
+			Spec: gatewayv1.HTTPRouteSpec{
+				Hostnames: []gatewayv1.Hostname{"example.com"},
+			},
+		}},
+		gateways: []gatewayv1.Gateway{{
+			ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test"},
+			Spec: gatewayv1.GatewaySpec{
+				Listeners: []gatewayv1.Listener{{
+					Name:     "example-com-http",
+					Hostname: gatewayHostnamePtr("*.example.com"),
+					Port:     80,
+					Protocol: gatewayv1.HTTPProtocolType,
+				}},
+			},
+		}},
+	}, {
+		name: "invalid listener names and hostnames",
+		gateways: []gatewayv1.Gateway{{
+			ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test"},
+			Spec: gatewayv1.GatewaySpec{
+				Listeners: []gatewayv1.Listener{{
+					Name:     "Example-http",
+					Hostname: gatewayHostnamePtr("Example.com"),
+					Port:     80,
+					Protocol: gatewayv1.HTTPProtocolType,
+				}, {
+					Name:     "Example-http",
+					Port:     80,
+					Protocol: gatewayv1.HTTPProtocolType,
+				}},
+			},
+		}},
+		expectErrors: []string{
+			`Gateway test/example is invalid: spec.listeners[0].name: Invalid value: "Example-http": must be a valid DNS subdomain`,
+			`Gateway test/example is invalid: spec.listeners[0].hostname: Invalid value: "Example.com": must be a valid hostname, optionally prefixed with a wildcard label`,
+			`Gateway test/example is invalid: spec.listeners[1].name: Invalid value: "Example-http": must be a valid DNS subdomain`,
+			`Gateway test/example is invalid: spec.listeners[1].name: Duplicate value: "Example-http"`,
+		},
+	}, {
+		name: "invalid listener ports",
+		gateways: []gatewayv1.Gateway{{
+			ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test"},
+			Spec: gatewayv1.GatewaySpec{
+				Listeners: []gatewayv1.Listener{{
+					Name:     "http",
+					Port:     8080,
+					Protocol: gatewayv1.HTTPProtocolType,
+				}, {
+					Name:     "https",
+					Port:     8080,
+					Protocol: gatewayv1.HTTPSProtocolType,
+				}, {
+					Name:     "other-http",
+					Port:     0,
+					Protocol: gatewayv1.HTTPProtocolType,
+				}},
+			},
+		}},
+		expectErrors: []string{
+			`Gateway test/example is invalid: spec.listeners[1].port: Invalid value: 8080: is already used by HTTP listeners`,
+			`Gateway test/example is invalid: spec.listeners[2].port: Invalid value: 0: must be between 1 and 65535`,
+		},
+	}, {
+		name: "too many rules",
+		httpRoutes: []gatewayv1.HTTPRoute{{
+			ObjectMeta: metav1.ObjectMeta{Name: "example-com", Namespace: "test"},
+			Spec: gatewayv1.HTTPRouteSpec{
+				Rules: make([]gatewayv1.HTTPRouteRule, maxHTTPRouteRules+1),
+			},
+		}},
+		expectErrors: []string{
+			`HTTPRoute test/example-com is invalid: spec.rules: Too many: 17: must have at most 16 items`,
+		},
+	}, {
+		name: "redirect with backends",
+		httpRoutes: []gatewayv1.HTTPRoute{{
+			ObjectMeta: metav1.ObjectMeta{Name: "example-com", Namespace: "test"},
+			Spec: gatewayv1.HTTPRouteSpec{
+				Rules: []gatewayv1.HTTPRouteRule{{
+					Filters: []gatewayv1.HTTPRouteFilter{{
+						Type:            gatewayv1.HTTPRouteFilterRequestRedirect,
+						RequestRedirect: &gatewayv1.HTTPRequestRedirectFilter{},
+					}, {
+						Type:          gatewayv1.HTTPRouteFilterRequestMirror,
+						RequestMirror: &gatewayv1.HTTPRequestMirrorFilter{},
+					}, {
+						Type:          gatewayv1.HTTPRouteFilterRequestMirror,
+						RequestMirror: &gatewayv1.HTTPRequestMirrorFilter{},
+					}},
+					BackendRefs: []gatewayv1.HTTPBackendRef{{
+						BackendRef: gatewayv1.BackendRef{BackendObjectReference: gatewayv1.BackendObjectReference{Name: "svc", Port: portNumberPtr(80)}},
+					}},
+				}},
+			},
+		}},
+		expectErrors: []string{
+			`HTTPRoute test/example-com is invalid: spec.rules[0].backendRefs: Forbidden: must be empty when a RequestRedirect filter is used`,
+		},
+	}, {
+		name: "invalid regular expression path",
+		httpRoutes: []gatewayv1.HTTPRoute{{
+			ObjectMeta: metav1.ObjectMeta{Name: "example-com", Namespace: "test"},
+			Spec: gatewayv1.HTTPRouteSpec{
+				Rules: []gatewayv1.HTTPRouteRule{{
+					Matches: []gatewayv1.HTTPRouteMatch{{
+						Path: &gatewayv1.HTTPPathMatch{Type: ptrTo(gatewayv1.PathMatchRegularExpression), Value: ptrTo("/(?=api)")},
+					}},
+				}},
+			},
+		}},
+		expectErrors: []string{
+			"HTTPRoute test/example-com is invalid: spec.rules[0].matches[0].path.value: Invalid value: \"/(?=api)\": must be a valid RE2 regular expression: error parsing regexp: invalid or unsupported Perl syntax: `(?=`",
+		},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotErrors []string
+			for _, err := range validateResources(tc.httpRoutes, tc.gateways) {
+				gotErrors = append(gotErrors, err.Error())
+			}
+			if diff := cmp.Diff(tc.expectErrors, gotErrors); diff != "" {
+				t.Errorf("Unexpected errors, diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
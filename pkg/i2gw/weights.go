@@ -0,0 +1,133 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/ir"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// WeightSplit is how an HTTPRoute rule splits the requests it matches
+// between several backends, or sends them to weighted ones.
+type WeightSplit struct {
+	HTTPRoute types.NamespacedName
+	// Matches describe the matches of the rule, "any request" for rules
+	// without matches.
+	Matches  []string
+	Backends []WeightedBackend
+}
+
+// WeightedBackend is a backend of a WeightSplit.
+type WeightedBackend struct {
+	Backend TrafficBackend
+	// Ingress is the Ingress the backend was converted from.
+	Ingress types.NamespacedName
+	// Weight is the weight of the backendRef, 1 if it has none like
+	// Gateway API defaults it.
+	Weight int32
+	// Percent is the share of the requests of the rule sent to the
+	// backend, 0 for every backend when their weights add up to 0.
+	Percent float64
+	// Canary is set for the backends of canary Ingresses, the share of
+	// the requests their annotations configure being Configured.
+	Canary     bool
+	Configured float64
+}
+
+// Preserved tells whether the backend gets the share of the requests
+// configured by its canary Ingress, down to the hundredth of a percent.
+// Other backends are always preserved.
+func (b WeightedBackend) Preserved() bool {
+	return !b.Canary || math.Abs(b.Percent-b.Configured) < 0.005
+}
+
+// weightSplits returns the splits of the rules of the routes with several
+// backends or weighted ones. canaries are the canary settings of the
+// canary Ingresses.
+func weightSplits(result ir.IR, canaries map[types.NamespacedName]*ir.Canary) []WeightSplit {
+	var splits []WeightSplit
+	for _, route := range result.HTTPRoutes {
+		for _, rule := range route.Rules {
+			weighted := len(rule.Backends) > 1
+			for _, backend := range rule.Backends {
+				weighted = weighted || backend.Weight != nil
+			}
+			if !weighted {
+				continue
+			}
+
+			split := WeightSplit{HTTPRoute: types.NamespacedName{Namespace: route.Namespace, Name: route.Name}}
+			for _, match := range rule.Matches {
+				split.Matches = append(split.Matches, describeMatch(match))
+			}
+			if len(split.Matches) == 0 {
+				split.Matches = []string{"any request"}
+			}
+			var total int64
+			for _, backend := range rule.Backends {
+				weight := int32(1)
+				if backend.Weight != nil {
+					weight = *backend.Weight
+				}
+				total += int64(weight)
+				split.Backends = append(split.Backends, WeightedBackend{
+					Backend: backendRefTrafficBackend(route.Namespace, backend.BackendObjectReference),
+					Ingress: backend.Source,
+					Weight:  weight,
+				})
+			}
+			for i := range split.Backends {
+				b := &split.Backends[i]
+				if total > 0 {
+					b.Percent = float64(b.Weight) * 100 / float64(total)
+				}
+				if c, ok := canaries[b.Ingress]; ok {
+					b.Canary = true
+					b.Configured = float64(min(c.Weight, canaryWeightTotal(c))) * 100 / float64(canaryWeightTotal(c))
+				}
+			}
+			splits = append(splits, split)
+		}
+	}
+	return splits
+}
+
+// WriteWeightSplits writes the share of the requests of every backend of
+// the splits, flagging the canaries whose configured share isn't
+// preserved.
+func WriteWeightSplits(w io.Writer, splits []WeightSplit) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, split := range splits {
+		fmt.Fprintf(tw, "HTTPRoute %s, %s:\n", split.HTTPRoute, strings.Join(split.Matches, ", "))
+		for _, b := range split.Backends {
+			fmt.Fprintf(tw, "  %.2f%%\t%s\tIngress %s", b.Percent, b.Backend, b.Ingress)
+			if b.Canary {
+				fmt.Fprintf(tw, "\tcanary, configured %.2f%%", b.Configured)
+				if !b.Preserved() {
+					fmt.Fprint(tw, ", not preserved")
+				}
+			}
+			fmt.Fprintln(tw)
+		}
+	}
+	tw.Flush()
+}
@@ -0,0 +1,121 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package i2gw
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func Test_weightSplits(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	web := ingressWithPath("web", "/", &iPrefix, serviceBackend("web", 80), nil)
+	canary := ingressWithPath("web-canary", "/", &iPrefix, serviceBackend("web-canary", 80), map[string]string{
+		"nginx.ingress.kubernetes.io/canary":              "true",
+		"nginx.ingress.kubernetes.io/canary-weight":       "1",
+		"nginx.ingress.kubernetes.io/canary-weight-total": "3",
+	})
+	webBackend := WeightedBackend{
+		Backend: TrafficBackend{Kind: "Service", Namespace: "test", Name: "web", Port: "80"},
+		Ingress: types.NamespacedName{Namespace: "test", Name: "web"},
+	}
+	canaryBackend := WeightedBackend{
+		Backend:    TrafficBackend{Kind: "Service", Namespace: "test", Name: "web-canary", Port: "80"},
+		Ingress:    types.NamespacedName{Namespace: "test", Name: "web-canary"},
+		Canary:     true,
+		Configured: 100.0 / 3,
+	}
+	route := types.NamespacedName{Namespace: "test", Name: "example-com"}
+
+	testCases := []struct {
+		name             string
+		ingresses        []networkingv1.Ingress
+		normalizeWeights int32
+		want             []WeightSplit
+		wantPreserved    bool
+		wantOutput       string
+	}{
+		{
+			name:      "single backend",
+			ingresses: []networkingv1.Ingress{web},
+		},
+		{
+			name:      "canary",
+			ingresses: []networkingv1.Ingress{web, canary},
+			want: []WeightSplit{{
+				HTTPRoute: route,
+				Matches:   []string{"PathPrefix /"},
+				Backends: []WeightedBackend{
+					withWeight(webBackend, 2, 200.0/3),
+					withWeight(canaryBackend, 1, 100.0/3),
+				},
+			}},
+			wantPreserved: true,
+			wantOutput: `HTTPRoute test/example-com, PathPrefix /:
+  66.67%  Service test/web:80         Ingress test/web
+  33.33%  Service test/web-canary:80  Ingress test/web-canary  canary, configured 33.33%
+`,
+		},
+		{
+			name:             "canary with normalized weights",
+			ingresses:        []networkingv1.Ingress{web, canary},
+			normalizeWeights: 10,
+			want: []WeightSplit{{
+				HTTPRoute: route,
+				Matches:   []string{"PathPrefix /"},
+				Backends: []WeightedBackend{
+					withWeight(webBackend, 7, 70),
+					withWeight(canaryBackend, 3, 30),
+				},
+			}},
+			wantOutput: `HTTPRoute test/example-com, PathPrefix /:
+  70.00%  Service test/web:80         Ingress test/web
+  30.00%  Service test/web-canary:80  Ingress test/web-canary  canary, configured 33.33%, not preserved
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, report := convertInput(inputResources{ingresses: tc.ingresses}, ConvertOptions{NormalizeWeights: tc.normalizeWeights})
+			if diff := cmp.Diff(tc.want, report.WeightSplits); diff != "" {
+				t.Errorf("unexpected weight splits (-want +got):\n%s", diff)
+			}
+			for _, split := range report.WeightSplits {
+				for _, b := range split.Backends {
+					if b.Canary && b.Preserved() != tc.wantPreserved {
+						t.Errorf("canary backend %s preserved = %v, want %v", b.Backend, b.Preserved(), tc.wantPreserved)
+					}
+				}
+			}
+			var buf bytes.Buffer
+			WriteWeightSplits(&buf, report.WeightSplits)
+			if diff := cmp.Diff(tc.wantOutput, buf.String()); diff != "" {
+				t.Errorf("unexpected output (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func withWeight(b WeightedBackend, weight int32, percent float64) WeightedBackend {
+	b.Weight = weight
+	b.Percent = percent
+	return b
+}
@@ -0,0 +1,400 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package operator runs the conversion as an operator reconciling
+// IngressMigrations: the Ingresses in the scope of every IngressMigration are
+// converted, again whenever they change, and the generated resources are
+// applied with Server-Side Apply.
+package operator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	migrationv1alpha1 "github.com/kubernetes-sigs/ingress2gateway/pkg/apis/migration/v1alpha1"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	networkingv1 "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// MigrationLabel is set on the applied resources to the name of the
+// IngressMigration they were generated for. The resources labeled with it
+// that are not generated anymore are deleted.
+const MigrationLabel = "ingress2gateway.kubernetes.io/migration"
+
+// maxConditionMessage is the longest message of a condition accepted by the
+// API server.
+const maxConditionMessage = 32768
+
+// Options configures the operator.
+type Options struct {
+	// MetricsBindAddress is the address the Prometheus metrics are served
+	// on. Set it to "0" to disable the metrics endpoint.
+	MetricsBindAddress string
+}
+
+// Run reconciles the IngressMigrations of the cluster of the current
+// kubeconfig until ctx is done.
+func Run(ctx context.Context, opts Options) error {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return err
+	}
+	if err := migrationv1alpha1.AddToScheme(scheme); err != nil {
+		return err
+	}
+	cfg, err := i2gw.RESTConfig("")
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme:  scheme,
+		Metrics: metricsserver.Options{BindAddress: opts.MetricsBindAddress},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create manager: %w", err)
+	}
+	r := &Reconciler{Client: mgr.GetClient()}
+	// Any change to an Ingress or IngressClass may change the conversion of
+	// every IngressMigration.
+	enqueueAll := handler.EnqueueRequestsFromMapFunc(r.allMigrations)
+	err = ctrl.NewControllerManagedBy(mgr).
+		// The status updates of the reconciler don't change the generation.
+		For(&migrationv1alpha1.IngressMigration{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&networkingv1.Ingress{}, enqueueAll).
+		Watches(&networkingv1.IngressClass{}, enqueueAll).
+		Complete(r)
+	if err != nil {
+		return fmt.Errorf("failed to create controller: %w", err)
+	}
+	return mgr.Start(ctx)
+}
+
+// Reconciler converts the Ingresses of an IngressMigration and applies the
+// generated resources.
+type Reconciler struct {
+	Client client.Client
+}
+
+func (r *Reconciler) allMigrations(ctx context.Context, _ client.Object) []reconcile.Request {
+	var migrations migrationv1alpha1.IngressMigrationList
+	if err := r.Client.List(ctx, &migrations); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to list IngressMigrations")
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, m := range migrations.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: m.Name}})
+	}
+	return requests
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	var migration migrationv1alpha1.IngressMigration
+	if err := r.Client.Get(ctx, req.NamespacedName, &migration); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+
+	status, applyErr := r.convert(ctx, &migration)
+	status.ObservedGeneration = migration.Generation
+	migration.Status = status
+	if err := r.Client.Status().Update(ctx, &migration); err != nil {
+		if apierrors.IsConflict(err) {
+			return reconcile.Result{Requeue: true}, nil
+		}
+		return reconcile.Result{}, fmt.Errorf("failed to update the status of IngressMigration %s: %w", migration.Name, err)
+	}
+	return reconcile.Result{}, applyErr
+}
+
+// convert converts the Ingresses of the migration and applies the
+// generated resources, returning the new status of the migration and the
+// error applying them, which is retried.
+func (r *Reconciler) convert(ctx context.Context, migration *migrationv1alpha1.IngressMigration) (migrationv1alpha1.IngressMigrationStatus, error) {
+	status := migrationv1alpha1.IngressMigrationStatus{Conditions: migration.Status.Conditions}
+	setReady := func(ready metav1.ConditionStatus, reason, message string) {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               migrationv1alpha1.ConditionReady,
+			Status:             ready,
+			Reason:             reason,
+			Message:            truncate(message),
+			ObservedGeneration: migration.Generation,
+		})
+	}
+
+	ingresses, ingressClasses, err := r.scope(ctx, migration.Spec)
+	if err != nil {
+		setReady(metav1.ConditionFalse, migrationv1alpha1.ReasonConversionFailed, err.Error())
+		return status, err
+	}
+	resources, report, err := i2gw.Convert(ctx, i2gw.ConvertOptions{
+		Ingresses:        ingresses,
+		IngressClasses:   ingressClasses,
+		GatewayNamespace: migration.Spec.GatewayNamespace,
+		SingleGateway:    migration.Spec.SingleGateway,
+		ListenerStrategy: i2gw.ListenerStrategy(migration.Spec.ListenerStrategy),
+	})
+	if err != nil {
+		// The spec is invalid, converting it again won't help.
+		setReady(metav1.ConditionFalse, migrationv1alpha1.ReasonConversionFailed, err.Error())
+		return status, nil
+	}
+	status.Ingresses = ingressStatuses(ingresses, report, migration.Status.Ingresses, migration.Generation)
+	for _, obj := range resources.Objects() {
+		gvk := obj.GetObjectKind().GroupVersionKind()
+		status.Resources = append(status.Resources, migrationv1alpha1.ResourceRef{
+			APIVersion: gvk.GroupVersion().String(),
+			Kind:       gvk.Kind,
+			Namespace:  obj.GetNamespace(),
+			Name:       obj.GetName(),
+		})
+	}
+
+	if migration.Spec.DryRun {
+		setReady(metav1.ConditionTrue, migrationv1alpha1.ReasonConverted, fmt.Sprintf("Converted %d Ingresses to %d resources, which are not applied in dry run", len(ingresses), len(status.Resources)))
+		return status, nil
+	}
+	objects, err := ownedObjects(resources, migration)
+	if err != nil {
+		setReady(metav1.ConditionFalse, migrationv1alpha1.ReasonConversionFailed, err.Error())
+		return status, nil
+	}
+	if _, err := i2gw.Apply(ctx, i2gw.ApplyOptions{Client: r.Client, Objects: objects}); err != nil {
+		setReady(metav1.ConditionFalse, migrationv1alpha1.ReasonApplyFailed, err.Error())
+		return status, err
+	}
+	if err := r.prune(ctx, migration.Name, objects, migration.Status.Resources); err != nil {
+		setReady(metav1.ConditionFalse, migrationv1alpha1.ReasonApplyFailed, err.Error())
+		return status, err
+	}
+	setReady(metav1.ConditionTrue, migrationv1alpha1.ReasonConverted, fmt.Sprintf("Converted %d Ingresses to %d resources", len(ingresses), len(status.Resources)))
+	return status, nil
+}
+
+// scope returns the Ingresses in the scope of the migration, and the
+// IngressClasses of the cluster.
+func (r *Reconciler) scope(ctx context.Context, spec migrationv1alpha1.IngressMigrationSpec) ([]networkingv1.Ingress, []networkingv1.IngressClass, error) {
+	var ingressClasses networkingv1.IngressClassList
+	if err := r.Client.List(ctx, &ingressClasses); err != nil {
+		return nil, nil, fmt.Errorf("failed to list IngressClasses: %w", err)
+	}
+
+	var ingresses []networkingv1.Ingress
+	namespaces := spec.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+	for _, namespace := range namespaces {
+		var list networkingv1.IngressList
+		if err := r.Client.List(ctx, &list, client.InNamespace(namespace)); err != nil {
+			return nil, nil, fmt.Errorf("failed to list Ingresses: %w", err)
+		}
+		for _, ingress := range list.Items {
+			if inClasses(ingress, spec.IngressClasses) {
+				ingresses = append(ingresses, ingress)
+			}
+		}
+	}
+	return ingresses, ingressClasses.Items, nil
+}
+
+func inClasses(ingress networkingv1.Ingress, classes []string) bool {
+	if len(classes) == 0 {
+		return true
+	}
+	class := ingress.Annotations[networkingv1beta1.AnnotationIngressClass]
+	if ingress.Spec.IngressClassName != nil {
+		class = *ingress.Spec.IngressClassName
+	}
+	for _, c := range classes {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// ingressStatuses returns the Converted condition of every Ingress, from
+// the findings of the conversion referring to it. The conditions of the
+// previous statuses keep their transition time while they don't change.
+func ingressStatuses(ingresses []networkingv1.Ingress, report i2gw.Report, previous []migrationv1alpha1.IngressStatus, generation int64) []migrationv1alpha1.IngressStatus {
+	previousConditions := map[types.NamespacedName][]metav1.Condition{}
+	for _, s := range previous {
+		previousConditions[types.NamespacedName{Namespace: s.Namespace, Name: s.Name}] = s.Conditions
+	}
+	findings := map[types.NamespacedName][]i2gw.Finding{}
+	for _, f := range i2gw.Findings(report) {
+		for _, obj := range f.Objects {
+			if obj.Kind == "Ingress" {
+				name := types.NamespacedName{Namespace: obj.Namespace, Name: obj.Name}
+				findings[name] = append(findings[name], f)
+			}
+		}
+	}
+
+	var statuses []migrationv1alpha1.IngressStatus
+	for _, ingress := range ingresses {
+		name := types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}
+		condition := metav1.Condition{
+			Type:               migrationv1alpha1.ConditionConverted,
+			Status:             metav1.ConditionTrue,
+			Reason:             migrationv1alpha1.ReasonConverted,
+			Message:            "The Ingress is fully converted",
+			ObservedGeneration: generation,
+		}
+		var messages []string
+		for _, f := range findings[name] {
+			if f.Severity == string(i2gw.InfoNotification) {
+				continue
+			}
+			messages = append(messages, fmt.Sprintf("%s: %s", f.Severity, f.Message))
+			switch {
+			case f.RuleID == i2gw.RuleSkippedIngress:
+				condition.Status, condition.Reason = metav1.ConditionFalse, migrationv1alpha1.ReasonSkipped
+			case f.Severity == i2gw.FindingSeverityError || f.Severity == string(i2gw.BlockingNotification):
+				if condition.Reason != migrationv1alpha1.ReasonSkipped {
+					condition.Status, condition.Reason = metav1.ConditionFalse, migrationv1alpha1.ReasonBlocked
+				}
+			case condition.Status == metav1.ConditionTrue:
+				condition.Reason = migrationv1alpha1.ReasonConvertedWithWarnings
+			}
+		}
+		if len(messages) > 0 {
+			condition.Message = truncate(strings.Join(messages, "\n"))
+		}
+		conditions := append([]metav1.Condition(nil), previousConditions[name]...)
+		meta.SetStatusCondition(&conditions, condition)
+		statuses = append(statuses, migrationv1alpha1.IngressStatus{
+			Namespace:  ingress.Namespace,
+			Name:       ingress.Name,
+			Conditions: conditions,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Namespace != statuses[j].Namespace {
+			return statuses[i].Namespace < statuses[j].Namespace
+		}
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// ownedObjects returns the resources as unstructured objects labeled with
+// the name of the migration and owned by it, so that they are garbage
+// collected with the migration.
+func ownedObjects(resources i2gw.Resources, migration *migrationv1alpha1.IngressMigration) ([]unstructured.Unstructured, error) {
+	owner := metav1.OwnerReference{
+		APIVersion: migrationv1alpha1.GroupVersion.String(),
+		Kind:       "IngressMigration",
+		Name:       migration.Name,
+		UID:        migration.UID,
+		Controller: ptr.To(true),
+	}
+	var objects []unstructured.Unstructured
+	for _, obj := range resources.Objects() {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s %s/%s: %w", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetNamespace(), obj.GetName(), err)
+		}
+		u := unstructured.Unstructured{Object: content}
+		labels := u.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[MigrationLabel] = migration.Name
+		u.SetLabels(labels)
+		u.SetOwnerReferences([]metav1.OwnerReference{owner})
+		objects = append(objects, u)
+	}
+	return objects, nil
+}
+
+// prune deletes the resources labeled with the migration that are not
+// generated anymore. The kinds of the resources generated now and by the
+// previous conversion are looked up.
+func (r *Reconciler) prune(ctx context.Context, migration string, objects []unstructured.Unstructured, previous []migrationv1alpha1.ResourceRef) error {
+	// The version of generated kinds may change between conversions, the
+	// resources are the same.
+	type resourceKey struct {
+		group, kind, namespace, name string
+	}
+	generated := map[resourceKey]bool{}
+	var gvks []schema.GroupVersionKind
+	seen := map[schema.GroupVersionKind]bool{}
+	addGVK := func(gvk schema.GroupVersionKind) {
+		if !seen[gvk] {
+			seen[gvk] = true
+			gvks = append(gvks, gvk)
+		}
+	}
+	for _, obj := range objects {
+		gvk := obj.GroupVersionKind()
+		generated[resourceKey{gvk.Group, gvk.Kind, obj.GetNamespace(), obj.GetName()}] = true
+		addGVK(gvk)
+	}
+	for _, ref := range previous {
+		// Resources recorded without their version can't be looked up.
+		if ref.APIVersion != "" {
+			addGVK(schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind))
+		}
+	}
+
+	for _, gvk := range gvks {
+		var list unstructured.UnstructuredList
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := r.Client.List(ctx, &list, client.MatchingLabels{MigrationLabel: migration}); err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return fmt.Errorf("failed to list %s resources: %w", gvk.Kind, err)
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			if generated[resourceKey{gvk.Group, gvk.Kind, obj.GetNamespace(), obj.GetName()}] {
+				continue
+			}
+			if err := r.Client.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to delete %s %s/%s: %w", gvk.Kind, obj.GetNamespace(), obj.GetName(), err)
+			}
+		}
+	}
+	return nil
+}
+
+func truncate(message string) string {
+	if len(message) <= maxConditionMessage {
+		return message
+	}
+	return message[:maxConditionMessage-3] + "..."
+}
@@ -0,0 +1,236 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package operator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	migrationv1alpha1 "github.com/kubernetes-sigs/ingress2gateway/pkg/apis/migration/v1alpha1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+func testIngress(namespace, name, class string, annotations map[string]string) *networkingv1.Ingress {
+	pathType := networkingv1.PathTypePrefix
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Annotations: annotations},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: &class,
+			Rules: []networkingv1.IngressRule{{
+				Host: name + ".example.com",
+				IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{{
+						Path:     "/",
+						PathType: &pathType,
+						Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+							Name: name,
+							Port: networkingv1.ServiceBackendPort{Number: 80},
+						}},
+					}},
+				}},
+			}},
+		},
+	}
+}
+
+type ingressCondition struct {
+	Ingress string
+	Status  metav1.ConditionStatus
+	Reason  string
+}
+
+func Test_Reconciler(t *testing.T) {
+	testCases := []struct {
+		name               string
+		spec               migrationv1alpha1.IngressMigrationSpec
+		expectedReady      metav1.ConditionStatus
+		expectedReason     string
+		expectedIngresses  []ingressCondition
+		expectedResources  []migrationv1alpha1.ResourceRef
+		expectedApplyCount int
+		expectedDeleted    []string
+	}{{
+		name:           "every Ingress",
+		expectedReady:  metav1.ConditionTrue,
+		expectedReason: migrationv1alpha1.ReasonConverted,
+		expectedIngresses: []ingressCondition{
+			{Ingress: "prod/api", Status: metav1.ConditionTrue, Reason: migrationv1alpha1.ReasonConvertedWithWarnings},
+			{Ingress: "prod/internal", Status: metav1.ConditionTrue, Reason: migrationv1alpha1.ReasonConverted},
+			{Ingress: "test/web", Status: metav1.ConditionTrue, Reason: migrationv1alpha1.ReasonConverted},
+		},
+		expectedResources: []migrationv1alpha1.ResourceRef{
+			{APIVersion: "gateway.networking.k8s.io/v1", Kind: "Gateway", Namespace: "prod", Name: "internal"},
+			{APIVersion: "gateway.networking.k8s.io/v1", Kind: "Gateway", Namespace: "prod", Name: "nginx"},
+			{APIVersion: "gateway.networking.k8s.io/v1", Kind: "Gateway", Namespace: "test", Name: "nginx"},
+			{APIVersion: "gateway.networking.k8s.io/v1", Kind: "HTTPRoute", Namespace: "prod", Name: "internal-example-com"},
+			{APIVersion: "gateway.networking.k8s.io/v1", Kind: "HTTPRoute", Namespace: "prod", Name: "api-example-com"},
+			{APIVersion: "gateway.networking.k8s.io/v1", Kind: "HTTPRoute", Namespace: "test", Name: "web-example-com"},
+		},
+		expectedApplyCount: 6,
+		expectedDeleted:    []string{"HTTPRoute prod/old", "TLSRoute prod/legacy"},
+	}, {
+		name:           "namespaces and IngressClasses",
+		spec:           migrationv1alpha1.IngressMigrationSpec{Namespaces: []string{"prod"}, IngressClasses: []string{"nginx"}},
+		expectedReady:  metav1.ConditionTrue,
+		expectedReason: migrationv1alpha1.ReasonConverted,
+		expectedIngresses: []ingressCondition{
+			{Ingress: "prod/api", Status: metav1.ConditionTrue, Reason: migrationv1alpha1.ReasonConvertedWithWarnings},
+		},
+		expectedResources: []migrationv1alpha1.ResourceRef{
+			{APIVersion: "gateway.networking.k8s.io/v1", Kind: "Gateway", Namespace: "prod", Name: "nginx"},
+			{APIVersion: "gateway.networking.k8s.io/v1", Kind: "HTTPRoute", Namespace: "prod", Name: "api-example-com"},
+		},
+		expectedApplyCount: 2,
+		expectedDeleted:    []string{"HTTPRoute prod/old", "TLSRoute prod/legacy"},
+	}, {
+		name:           "dry run",
+		spec:           migrationv1alpha1.IngressMigrationSpec{Namespaces: []string{"test"}, DryRun: true},
+		expectedReady:  metav1.ConditionTrue,
+		expectedReason: migrationv1alpha1.ReasonConverted,
+		expectedIngresses: []ingressCondition{
+			{Ingress: "test/web", Status: metav1.ConditionTrue, Reason: migrationv1alpha1.ReasonConverted},
+		},
+		expectedResources: []migrationv1alpha1.ResourceRef{
+			{APIVersion: "gateway.networking.k8s.io/v1", Kind: "Gateway", Namespace: "test", Name: "nginx"},
+			{APIVersion: "gateway.networking.k8s.io/v1", Kind: "HTTPRoute", Namespace: "test", Name: "web-example-com"},
+		},
+	}, {
+		name:           "invalid spec",
+		spec:           migrationv1alpha1.IngressMigrationSpec{SingleGateway: "shared"},
+		expectedReady:  metav1.ConditionFalse,
+		expectedReason: migrationv1alpha1.ReasonConversionFailed,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := clientgoscheme.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			if err := migrationv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			if err := gatewayv1.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			if err := gatewayv1alpha2.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			migration := &migrationv1alpha1.IngressMigration{
+				ObjectMeta: metav1.ObjectMeta{Name: "migration", Generation: 2, UID: "migration-uid"},
+				Spec:       tc.spec,
+				Status: migrationv1alpha1.IngressMigrationStatus{Resources: []migrationv1alpha1.ResourceRef{
+					{APIVersion: "gateway.networking.k8s.io/v1alpha2", Kind: "TLSRoute", Namespace: "prod", Name: "legacy"},
+				}},
+			}
+			labeled := map[string]string{MigrationLabel: "migration"}
+			existing := []client.Object{
+				&gatewayv1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "old", Labels: labeled}},
+				&gatewayv1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "api-example-com", Labels: labeled}},
+				&gatewayv1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "other", Labels: map[string]string{MigrationLabel: "other"}}},
+				&gatewayv1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "manual"}},
+				&gatewayv1alpha2.TLSRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "legacy", Labels: labeled}},
+			}
+			var applied []*unstructured.Unstructured
+			cl := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(migration).
+				WithObjects(
+					migration,
+					&networkingv1.IngressClass{ObjectMeta: metav1.ObjectMeta{Name: "nginx"}, Spec: networkingv1.IngressClassSpec{Controller: "k8s.io/ingress-nginx"}},
+					testIngress("test", "web", "nginx", nil),
+					testIngress("prod", "api", "nginx", map[string]string{"nginx.ingress.kubernetes.io/unknown": "true"}),
+					testIngress("prod", "internal", "internal", nil),
+				).
+				WithObjects(existing...).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Patch: func(_ context.Context, _ client.WithWatch, obj client.Object, _ client.Patch, _ ...client.PatchOption) error {
+						applied = append(applied, obj.(*unstructured.Unstructured))
+						return nil
+					},
+				}).
+				Build()
+
+			r := &Reconciler{Client: cl}
+			if _, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "migration"}}); err != nil {
+				t.Fatalf("Reconcile() failed: %v", err)
+			}
+
+			var got migrationv1alpha1.IngressMigration
+			if err := cl.Get(context.Background(), types.NamespacedName{Name: "migration"}, &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Status.ObservedGeneration != 2 {
+				t.Errorf("observedGeneration = %d, expected 2", got.Status.ObservedGeneration)
+			}
+			if len(got.Status.Conditions) != 1 || got.Status.Conditions[0].Status != tc.expectedReady || got.Status.Conditions[0].Reason != tc.expectedReason {
+				t.Errorf("conditions = %+v, expected Ready %s with reason %s", got.Status.Conditions, tc.expectedReady, tc.expectedReason)
+			}
+			var ingresses []ingressCondition
+			for _, s := range got.Status.Ingresses {
+				for _, c := range s.Conditions {
+					ingresses = append(ingresses, ingressCondition{Ingress: s.Namespace + "/" + s.Name, Status: c.Status, Reason: c.Reason})
+				}
+			}
+			if diff := cmp.Diff(tc.expectedIngresses, ingresses); diff != "" {
+				t.Errorf("Ingress conditions mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedResources, got.Status.Resources); diff != "" {
+				t.Errorf("resources mismatch (-want +got):\n%s", diff)
+			}
+			if len(applied) != tc.expectedApplyCount {
+				t.Errorf("applied %d objects, expected %d", len(applied), tc.expectedApplyCount)
+			}
+			for _, obj := range applied {
+				if obj.GetLabels()[MigrationLabel] != "migration" {
+					t.Errorf("%s %s/%s is not labeled with the migration", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+				}
+				if owners := obj.GetOwnerReferences(); len(owners) != 1 || owners[0].Kind != "IngressMigration" || owners[0].UID != "migration-uid" {
+					t.Errorf("%s %s/%s is not owned by the migration: %+v", obj.GetKind(), obj.GetNamespace(), obj.GetName(), owners)
+				}
+			}
+			var deleted []string
+			for _, obj := range existing {
+				gvk, err := cl.GroupVersionKindFor(obj)
+				if err != nil {
+					t.Fatal(err)
+				}
+				err = cl.Get(context.Background(), client.ObjectKeyFromObject(obj), obj)
+				if apierrors.IsNotFound(err) {
+					deleted = append(deleted, gvk.Kind+" "+obj.GetNamespace()+"/"+obj.GetName())
+				} else if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if diff := cmp.Diff(tc.expectedDeleted, deleted); diff != "" {
+				t.Errorf("deleted resources mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
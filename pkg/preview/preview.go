@@ -0,0 +1,165 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package preview runs the conversion as a validating admission webhook that
+// never rejects Ingresses, so that teams can preview the Gateway API
+// resources their Ingresses convert to whenever they create or update them.
+package preview
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	admissionv1 "k8s.io/api/admission/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// Path is the path the webhook is served on, referenced by the
+// ValidatingWebhookConfiguration.
+const Path = "/validate-ingress"
+
+// warningPrefix precedes the warnings returned to the clients, which kubectl
+// shows after "Warning:".
+const warningPrefix = "ingress2gateway: "
+
+// Options configures the preview webhook.
+type Options struct {
+	// Port is the port the webhook is served on with TLS.
+	Port int
+	// CertDir is the directory holding the tls.crt and tls.key files of the
+	// serving certificate.
+	CertDir string
+	// Output is where the resources previewed for every admitted Ingress are
+	// logged. It defaults to stdout.
+	Output io.Writer
+	// ConvertOptions are the options of every conversion.
+	ConvertOptions i2gw.ConvertOptions
+}
+
+// Run serves the webhook until ctx is done, reading the IngressClasses of the
+// cluster of the current kubeconfig.
+func Run(ctx context.Context, opts Options) error {
+	cfg, err := i2gw.RESTConfig("")
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Metrics:       metricsserver.Options{BindAddress: "0"},
+		WebhookServer: webhook.NewServer(webhook.Options{Port: opts.Port, CertDir: opts.CertDir}),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create manager: %w", err)
+	}
+	mgr.GetWebhookServer().Register(Path, &admission.Webhook{Handler: NewHandler(mgr.GetClient(), opts)})
+	return mgr.Start(ctx)
+}
+
+// NewHandler returns the admission handler previewing the conversion of the
+// Ingresses of the requests, with the IngressClasses read from c.
+func NewHandler(c client.Reader, opts Options) admission.Handler {
+	if opts.Output == nil {
+		opts.Output = os.Stdout
+	}
+	return &handler{client: c, opts: opts}
+}
+
+type handler struct {
+	client client.Reader
+	opts   Options
+	// mu serializes the writes to the output, which requests are handled
+	// concurrently for.
+	mu sync.Mutex
+}
+
+// Handle allows every request. The Ingress of the request is converted on its
+// own, and what it converts to is returned as warnings and logged to the
+// output.
+func (h *handler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+	var ingress networkingv1.Ingress
+	if err := json.Unmarshal(req.Object.Raw, &ingress); err != nil {
+		return admission.Allowed("").WithWarnings(warningPrefix + fmt.Sprintf("failed to decode the Ingress, no preview is available: %v", err))
+	}
+	// The object of CREATE requests using generateName has no name yet.
+	if ingress.Name == "" {
+		ingress.Name = req.Name
+	}
+	if ingress.Namespace == "" {
+		ingress.Namespace = req.Namespace
+	}
+
+	var ingressClasses networkingv1.IngressClassList
+	if err := h.client.List(ctx, &ingressClasses); err != nil {
+		return admission.Allowed("").WithWarnings(warningPrefix + fmt.Sprintf("failed to list IngressClasses, no preview is available: %v", err))
+	}
+	opts := h.opts.ConvertOptions
+	opts.IngressClasses = ingressClasses.Items
+	resources, report, err := i2gw.ConvertIngress(ingress, opts)
+	if err != nil {
+		return admission.Allowed("").WithWarnings(warningPrefix + fmt.Sprintf("failed to convert the Ingress, no preview is available: %v", err))
+	}
+
+	h.log(ingress, req.Operation, resources, report)
+	return admission.Allowed("").WithWarnings(previewWarnings(resources, report)...)
+}
+
+func (h *handler) log(ingress networkingv1.Ingress, operation admissionv1.Operation, resources i2gw.Resources, report i2gw.Report) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Preview of the %s of Ingress %s/%s\n", operation, ingress.Namespace, ingress.Name)
+	i2gw.WriteResult(&buf, resources, report)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.opts.Output.Write(buf.Bytes())
+}
+
+// previewWarnings lists the resources the Ingress converts to and what the
+// user should review: the errors, and the notifications that aren't
+// informational.
+func previewWarnings(resources i2gw.Resources, report i2gw.Report) []string {
+	var names []string
+	for _, obj := range resources.Objects() {
+		names = append(names, fmt.Sprintf("%s %s/%s", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetNamespace(), obj.GetName()))
+	}
+	var warnings []string
+	if len(names) == 0 {
+		warnings = append(warnings, warningPrefix+"the Ingress converts to no Gateway API resource")
+	} else {
+		warnings = append(warnings, warningPrefix+"the Ingress converts to "+strings.Join(names, ", "))
+	}
+	for _, err := range report.Errors {
+		warnings = append(warnings, warningPrefix+"ERROR: "+err.Error())
+	}
+	for _, n := range report.Notifications {
+		if n.Type != i2gw.InfoNotification {
+			warnings = append(warnings, warningPrefix+n.String())
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,120 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package preview
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	admissionv1 "k8s.io/api/admission/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func Test_handler(t *testing.T) {
+	pathType := networkingv1.PathTypePrefix
+	className := "nginx"
+	ingress := networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "example",
+			Namespace:   "test",
+			Annotations: map[string]string{"nginx.ingress.kubernetes.io/unknown": "true"},
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: &className,
+			Rules: []networkingv1.IngressRule{{
+				Host: "example.com",
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     "/",
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: "example",
+									Port: networkingv1.ServiceBackendPort{Number: 80},
+								},
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+	raw, err := json.Marshal(ingress)
+	if err != nil {
+		t.Fatalf("failed to encode Ingress: %v", err)
+	}
+	ingressClass := &networkingv1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx"},
+		Spec:       networkingv1.IngressClassSpec{Controller: "k8s.io/ingress-nginx"},
+	}
+
+	testCases := []struct {
+		name             string
+		operation        admissionv1.Operation
+		raw              []byte
+		expectedWarnings []string
+		expectedOutput   string
+	}{{
+		name:      "create",
+		operation: admissionv1.Create,
+		raw:       raw,
+		expectedWarnings: []string{
+			"ingress2gateway: the Ingress converts to Gateway test/nginx, HTTPRoute test/example-com",
+			"ingress2gateway: WARNING: Ingress test/example uses annotations that are not converted: nginx.ingress.kubernetes.io/unknown",
+		},
+		expectedOutput: "# Preview of the CREATE of Ingress test/example\n",
+	}, {
+		name:      "delete",
+		operation: admissionv1.Delete,
+	}, {
+		name:             "invalid object",
+		operation:        admissionv1.Update,
+		raw:              []byte("{"),
+		expectedWarnings: []string{"ingress2gateway: failed to decode the Ingress, no preview is available: unexpected end of JSON input"},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var output bytes.Buffer
+			h := NewHandler(fake.NewClientBuilder().WithObjects(ingressClass).Build(), Options{Output: &output})
+			resp := h.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: tc.operation,
+				Namespace: "test",
+				Name:      "example",
+				Object:    runtime.RawExtension{Raw: tc.raw},
+			}})
+
+			if !resp.Allowed {
+				t.Errorf("Handle() rejected the request: %v", resp.Result)
+			}
+			if diff := cmp.Diff(tc.expectedWarnings, resp.Warnings); diff != "" {
+				t.Errorf("Handle() warnings mismatch (-want +got):\n%s", diff)
+			}
+			if !strings.HasPrefix(output.String(), tc.expectedOutput) {
+				t.Errorf("Handle() output = %q, expected it to start with %q", output.String(), tc.expectedOutput)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package serve exposes the conversion as an HTTP API, for developer portals
+// and migration tooling to convert manifests without running the command
+// line.
+package serve
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultMaxRequestBytes is the largest request body accepted by default.
+const DefaultMaxRequestBytes = 10 << 20
+
+// Options configures the conversion server.
+type Options struct {
+	// Address is the address the API is served on.
+	Address string
+	// MaxRequestBytes is the largest request body accepted. It defaults to
+	// DefaultMaxRequestBytes.
+	MaxRequestBytes int64
+	// ConvertOptions are the options of every conversion, which requests
+	// may override with query parameters.
+	ConvertOptions i2gw.ConvertOptions
+}
+
+// ConvertResponse is the JSON response of the convert endpoint.
+type ConvertResponse struct {
+	// Resources are the generated resources, in the order of the YAML
+	// output.
+	Resources []client.Object `json:"resources"`
+	// Findings are the errors, notifications, unsupported annotations and
+	// skipped Ingresses of the conversion.
+	Findings []i2gw.Finding `json:"findings"`
+}
+
+// errorResponse is the JSON response of failed requests.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Run serves the API until ctx is done, then shuts the server down once the
+// requests in flight are answered.
+func Run(ctx context.Context, opts Options) error {
+	server := &http.Server{
+		Addr:              opts.Address,
+		Handler:           NewHandler(opts),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+	select {
+	case err := <-errs:
+		return fmt.Errorf("failed to serve: %w", err)
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
+
+// NewHandler returns the handler of the API:
+//
+//   - POST /v1/convert converts the YAML or JSON manifests of the request
+//     body, answering with a ConvertResponse, or with the YAML output of the
+//     command line if the Accept header asks for YAML.
+//   - GET /healthz answers ok.
+func NewHandler(opts Options) http.Handler {
+	if opts.MaxRequestBytes <= 0 {
+		opts.MaxRequestBytes = DefaultMaxRequestBytes
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/convert", func(w http.ResponseWriter, r *http.Request) {
+		handleConvert(w, r, opts)
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	return mux
+}
+
+func handleConvert(w http.ResponseWriter, r *http.Request, opts Options) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed, use POST", r.Method))
+		return
+	}
+	convertOpts, err := requestOptions(opts.ConvertOptions, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var maxBytesErr *http.MaxBytesError
+	body := http.MaxBytesReader(w, r.Body, opts.MaxRequestBytes)
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(body); errors.As(err, &maxBytesErr) {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("request body is larger than %d bytes", opts.MaxRequestBytes))
+		return
+	} else if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("failed to read request body: %w", err))
+		return
+	}
+	convertOpts.Input = &buf
+
+	resources, report, err := i2gw.Convert(r.Context(), convertOpts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if strings.Contains(r.Header.Get("Accept"), "yaml") {
+		w.Header().Set("Content-Type", "application/yaml")
+		i2gw.WriteResult(w, resources, report)
+		return
+	}
+	response := ConvertResponse{Resources: resources.Objects(), Findings: i2gw.Findings(report)}
+	if response.Resources == nil {
+		response.Resources = []client.Object{}
+	}
+	if response.Findings == nil {
+		response.Findings = []i2gw.Finding{}
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// requestOptions applies the query parameters of the request to the options
+// of the server.
+func requestOptions(opts i2gw.ConvertOptions, r *http.Request) (i2gw.ConvertOptions, error) {
+	query := r.URL.Query()
+	if v := query.Get("targetImplementation"); v != "" {
+		opts.TargetImplementation = v
+	}
+	if v := query.Get("gatewayNamespace"); v != "" {
+		opts.GatewayNamespace = v
+	}
+	if v := query.Get("mode"); v != "" {
+		opts.Mode = i2gw.ConversionMode(v)
+	}
+	if v := query.Get("routeNaming"); v != "" {
+		opts.RouteNaming = i2gw.RouteNaming(v)
+	}
+	if v := query.Get("experimental"); v != "" {
+		experimental, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid experimental query parameter %q: %w", v, err)
+		}
+		opts.Experimental = experimental
+	}
+	// The server only converts the manifests it receives.
+	opts.Client = nil
+	opts.InputFile = ""
+	return opts, nil
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	// The status is already sent, a failure can only be seen by the client
+	// as a truncated response.
+	_ = encoder.Encode(v)
+}
@@ -0,0 +1,166 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package serve
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const ingressManifest = `apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: example
+  namespace: test
+  annotations:
+    nginx.ingress.kubernetes.io/unknown: "true"
+spec:
+  ingressClassName: nginx
+  rules:
+  - host: example.com
+    http:
+      paths:
+      - path: /
+        pathType: Prefix
+        backend:
+          service:
+            name: example
+            port:
+              number: 80
+`
+
+func Test_handler(t *testing.T) {
+	testCases := []struct {
+		name           string
+		method         string
+		target         string
+		body           string
+		accept         string
+		expectedStatus int
+		// expectedKinds are the kinds of the resources of JSON responses.
+		expectedKinds []string
+		// expectedRules are the rule IDs of the findings of JSON responses.
+		expectedRules []string
+		// expectedBody is contained in the response body.
+		expectedBody string
+	}{{
+		name:           "json response",
+		method:         http.MethodPost,
+		target:         "/v1/convert",
+		body:           ingressManifest,
+		expectedStatus: http.StatusOK,
+		expectedKinds:  []string{"Gateway", "HTTPRoute"},
+		expectedRules:  []string{"unsupported-annotation"},
+	}, {
+		name:           "yaml response",
+		method:         http.MethodPost,
+		target:         "/v1/convert",
+		body:           ingressManifest,
+		accept:         "application/yaml",
+		expectedStatus: http.StatusOK,
+		expectedBody:   "kind: HTTPRoute",
+	}, {
+		name:           "query parameters",
+		method:         http.MethodPost,
+		target:         "/v1/convert?gatewayNamespace=infra",
+		body:           ingressManifest,
+		accept:         "application/yaml",
+		expectedStatus: http.StatusOK,
+		expectedBody:   "namespace: infra",
+	}, {
+		name:           "invalid query parameter",
+		method:         http.MethodPost,
+		target:         "/v1/convert?experimental=maybe",
+		body:           ingressManifest,
+		expectedStatus: http.StatusBadRequest,
+		expectedBody:   "invalid experimental query parameter",
+	}, {
+		name:           "invalid manifest",
+		method:         http.MethodPost,
+		target:         "/v1/convert",
+		body:           "apiVersion: [",
+		expectedStatus: http.StatusBadRequest,
+		expectedBody:   "failed to decode input",
+	}, {
+		name:           "request too large",
+		method:         http.MethodPost,
+		target:         "/v1/convert",
+		body:           ingressManifest + strings.Repeat("#", 1024),
+		expectedStatus: http.StatusRequestEntityTooLarge,
+	}, {
+		name:           "wrong method",
+		method:         http.MethodGet,
+		target:         "/v1/convert",
+		expectedStatus: http.StatusMethodNotAllowed,
+	}, {
+		name:           "health",
+		method:         http.MethodGet,
+		target:         "/healthz",
+		expectedStatus: http.StatusOK,
+		expectedBody:   "ok",
+	}}
+
+	handler := NewHandler(Options{MaxRequestBytes: int64(len(ingressManifest))})
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tc.expectedStatus {
+				t.Fatalf("status = %d, expected %d: %s", rec.Code, tc.expectedStatus, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), tc.expectedBody) {
+				t.Errorf("body doesn't contain %q:\n%s", tc.expectedBody, rec.Body)
+			}
+			if tc.expectedKinds == nil && tc.expectedRules == nil {
+				return
+			}
+			var response struct {
+				Resources []struct {
+					Kind string `json:"kind"`
+				} `json:"resources"`
+				Findings []struct {
+					RuleID string `json:"ruleId"`
+				} `json:"findings"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			var kinds, rules []string
+			for _, r := range response.Resources {
+				kinds = append(kinds, r.Kind)
+			}
+			for _, f := range response.Findings {
+				rules = append(rules, f.RuleID)
+			}
+			if diff := cmp.Diff(tc.expectedKinds, kinds); diff != "" {
+				t.Errorf("resource kinds mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedRules, rules); diff != "" {
+				t.Errorf("finding rules mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package watch
+
+import (
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const metricsNamespace = "ingress2gateway"
+
+var (
+	conversionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "conversions_total",
+		Help:      "Number of conversions performed, by result.",
+	}, []string{"result"})
+
+	syncDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "sync_duration_seconds",
+		Help:      "Time taken to convert the Ingresses of the cluster and write the output.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+	})
+
+	conversionErrors = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "conversion_errors",
+		Help:      "Number of errors reported by the last conversion.",
+	})
+
+	notificationsByType = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "notifications",
+		Help:      "Number of notifications reported by the last conversion, by type.",
+	}, []string{"type"})
+
+	unsupportedAnnotations = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "unsupported_annotations",
+		Help:      "Number of Ingresses using an annotation that is not converted, by annotation.",
+	}, []string{"annotation"})
+
+	generatedResources = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "generated_resources",
+		Help:      "Number of resources generated by the last conversion, by kind.",
+	}, []string{"kind"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		conversionsTotal,
+		syncDuration,
+		conversionErrors,
+		notificationsByType,
+		unsupportedAnnotations,
+		generatedResources,
+	)
+}
+
+// recordConversion updates the gauges to reflect the last conversion.
+func recordConversion(resources i2gw.Resources, report i2gw.Report) {
+	conversionErrors.Set(float64(len(report.Errors)))
+
+	notificationsByType.Reset()
+	for _, t := range []i2gw.NotificationType{i2gw.InfoNotification, i2gw.WarningNotification, i2gw.BlockingNotification} {
+		notificationsByType.WithLabelValues(string(t)).Set(0)
+	}
+	for _, n := range report.Notifications {
+		notificationsByType.WithLabelValues(string(n.Type)).Inc()
+	}
+
+	unsupportedAnnotations.Reset()
+	for _, u := range report.UnsupportedAnnotations {
+		unsupportedAnnotations.WithLabelValues(u.Annotation).Inc()
+	}
+
+	generatedResources.WithLabelValues("Gateway").Set(float64(len(resources.Gateways)))
+	generatedResources.WithLabelValues("HTTPRoute").Set(float64(len(resources.HTTPRoutes)))
+	generatedResources.WithLabelValues("TLSRoute").Set(float64(len(resources.TLSRoutes)))
+	generatedResources.WithLabelValues("TCPRoute").Set(float64(len(resources.TCPRoutes)))
+	generatedResources.WithLabelValues("ReferenceGrant").Set(float64(len(resources.ReferenceGrants)))
+	generatedResources.WithLabelValues("BackendLBPolicy").Set(float64(len(resources.BackendLBPolicies)))
+	generatedResources.WithLabelValues("BackendTLSPolicy").Set(float64(len(resources.BackendTLSPolicies)))
+}
@@ -0,0 +1,151 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package watch runs the conversion as a long-lived controller that converts
+// the Ingresses of a cluster again whenever they change.
+package watch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// Options configures the watch mode.
+type Options struct {
+	// OutputFile is the path the output of every conversion is written to.
+	// If empty, the output is written to stdout.
+	OutputFile string
+	// MetricsBindAddress is the address the Prometheus metrics are served
+	// on. Set it to "0" to disable the metrics endpoint.
+	MetricsBindAddress string
+}
+
+// syncRequest is the single request every change is mapped to, since the
+// Ingresses of the cluster are always converted together.
+var syncRequest = reconcile.Request{NamespacedName: types.NamespacedName{Name: "ingresses"}}
+
+// Run watches the Ingresses and IngressClasses of the cluster of the current
+// kubeconfig until ctx is done, converting them again on every change.
+func Run(ctx context.Context, opts Options) error {
+	cfg, err := i2gw.RESTConfig("")
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Metrics: metricsserver.Options{BindAddress: opts.MetricsBindAddress},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create manager: %w", err)
+	}
+
+	r := &reconciler{client: mgr.GetClient(), outputFile: opts.OutputFile, cache: i2gw.NewConversionCache()}
+	c, err := controller.New("ingress2gateway", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return fmt.Errorf("failed to create controller: %w", err)
+	}
+	enqueueSync := handler.EnqueueRequestsFromMapFunc(func(context.Context, client.Object) []reconcile.Request {
+		return []reconcile.Request{syncRequest}
+	})
+	for _, obj := range []client.Object{&networkingv1.Ingress{}, &networkingv1.IngressClass{}} {
+		if err := c.Watch(source.Kind(mgr.GetCache(), obj, enqueueSync)); err != nil {
+			return fmt.Errorf("failed to watch %T: %w", obj, err)
+		}
+	}
+
+	return mgr.Start(ctx)
+}
+
+type reconciler struct {
+	client     client.Client
+	outputFile string
+	// cache keeps the conversions of the namespaces whose Ingresses didn't
+	// change.
+	cache *i2gw.ConversionCache
+	// lastOutput is the output last written, which isn't written again
+	// while the conversion doesn't change.
+	lastOutput []byte
+}
+
+func (r *reconciler) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	start := time.Now()
+	defer func() {
+		syncDuration.Observe(time.Since(start).Seconds())
+	}()
+
+	resources, report, err := i2gw.Convert(ctx, i2gw.ConvertOptions{Client: r.client, Cache: r.cache})
+	if err != nil {
+		conversionsTotal.WithLabelValues("error").Inc()
+		return reconcile.Result{}, err
+	}
+	recordConversion(resources, report)
+
+	if err := r.write(resources, report); err != nil {
+		conversionsTotal.WithLabelValues("error").Inc()
+		return reconcile.Result{}, err
+	}
+	conversionsTotal.WithLabelValues("success").Inc()
+	return reconcile.Result{}, nil
+}
+
+// write writes the output unless it is unchanged, replacing the output file
+// atomically so that readers never see a partial conversion.
+func (r *reconciler) write(resources i2gw.Resources, report i2gw.Report) error {
+	var buf bytes.Buffer
+	i2gw.WriteResult(&buf, resources, report)
+	if r.lastOutput != nil && bytes.Equal(buf.Bytes(), r.lastOutput) {
+		return nil
+	}
+	if r.outputFile == "" {
+		if _, err := os.Stdout.Write(buf.Bytes()); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+		r.lastOutput = buf.Bytes()
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(r.outputFile), filepath.Base(r.outputFile)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), r.outputFile); err != nil {
+		return fmt.Errorf("failed to replace output file: %w", err)
+	}
+	r.lastOutput = buf.Bytes()
+	return nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_reconciler(t *testing.T) {
+	pathType := networkingv1.PathTypePrefix
+	className := "nginx"
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "example",
+			Namespace:   "test",
+			Annotations: map[string]string{"nginx.ingress.kubernetes.io/app-root": "/app"},
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: &className,
+			Rules: []networkingv1.IngressRule{{
+				Host: "example.com",
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     "/",
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: "example",
+									Port: networkingv1.ServiceBackendPort{Number: 80},
+								},
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+
+	outputFile := filepath.Join(t.TempDir(), "output.yaml")
+	r := &reconciler{
+		client:     fake.NewClientBuilder().WithObjects(ingress).Build(),
+		outputFile: outputFile,
+		cache:      i2gw.NewConversionCache(),
+	}
+	successes := testutil.ToFloat64(conversionsTotal.WithLabelValues("success"))
+
+	if _, err := r.Reconcile(context.Background(), syncRequest); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	output, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if !strings.Contains(string(output), "kind: HTTPRoute") {
+		t.Errorf("Expected an HTTPRoute in the output, got:\n%s", output)
+	}
+
+	if got := testutil.ToFloat64(conversionsTotal.WithLabelValues("success")) - successes; got != 1 {
+		t.Errorf("Expected 1 successful conversion, got %v", got)
+	}
+	if got := testutil.ToFloat64(unsupportedAnnotations.WithLabelValues("nginx.ingress.kubernetes.io/app-root")); got != 1 {
+		t.Errorf("Expected 1 Ingress with an unsupported annotation, got %v", got)
+	}
+	if got := testutil.ToFloat64(notificationsByType.WithLabelValues("WARNING")); got != 1 {
+		t.Errorf("Expected 1 warning, got %v", got)
+	}
+	if got := testutil.ToFloat64(generatedResources.WithLabelValues("HTTPRoute")); got != 1 {
+		t.Errorf("Expected 1 generated HTTPRoute, got %v", got)
+	}
+
+	// An unchanged conversion isn't written again.
+	if err := os.Remove(outputFile); err != nil {
+		t.Fatalf("Failed to remove output: %v", err)
+	}
+	if _, err := r.Reconcile(context.Background(), syncRequest); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := os.Stat(outputFile); !os.IsNotExist(err) {
+		t.Errorf("Expected the unchanged output not to be written again, got %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+//go:build conformance
+
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+)
+
+// clientPod is the pod sending the sample requests from inside the
+// cluster, to the cluster IPs of the Ingress controller and the Gateways.
+const clientPod = "client"
+
+// cluster is a kind cluster.
+type cluster struct {
+	name       string
+	kubeconfig string
+}
+
+// createCluster creates a kind cluster, writing its kubeconfig to dir.
+func createCluster(ctx context.Context, name, dir string) (*cluster, error) {
+	c := &cluster{name: name, kubeconfig: filepath.Join(dir, "kubeconfig")}
+	if _, err := run(ctx, nil, "kind", "create", "cluster", "--name", name, "--kubeconfig", c.kubeconfig, "--wait", "5m"); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *cluster) delete(ctx context.Context) error {
+	_, err := run(ctx, nil, "kind", "delete", "cluster", "--name", c.name)
+	return err
+}
+
+func (c *cluster) kubectl(ctx context.Context, stdin io.Reader, args ...string) ([]byte, error) {
+	return run(ctx, stdin, "kubectl", append([]string{"--kubeconfig", c.kubeconfig}, args...)...)
+}
+
+// apply applies a manifest, a path or a URL, server-side so that the large
+// CRDs of Gateway implementations fit.
+func (c *cluster) apply(ctx context.Context, manifest string) error {
+	_, err := c.kubectl(ctx, nil, "apply", "--server-side", "--force-conflicts", "-f", manifest)
+	return err
+}
+
+func (c *cluster) applyManifest(ctx context.Context, manifest []byte) error {
+	_, err := c.kubectl(ctx, bytes.NewReader(manifest), "apply", "--server-side", "--force-conflicts", "-f", "-")
+	return err
+}
+
+func (c *cluster) deleteManifest(ctx context.Context, manifest []byte) error {
+	_, err := c.kubectl(ctx, bytes.NewReader(manifest), "delete", "--ignore-not-found", "--wait", "-f", "-")
+	return err
+}
+
+// clusterIP returns the cluster IP of the first Service of the namespace
+// matching the kubectl selectors, such as --selector or --field-selector.
+func (c *cluster) clusterIP(ctx context.Context, namespace string, selectors ...string) (string, error) {
+	args := append([]string{"get", "services", "--namespace", namespace}, selectors...)
+	out, err := c.kubectl(ctx, nil, append(args, "--output", "jsonpath={.items[0].spec.clusterIP}")...)
+	if err != nil {
+		return "", err
+	}
+	ip := strings.TrimSpace(string(out))
+	if ip == "" {
+		return "", fmt.Errorf("no Service in namespace %s matches %s", namespace, strings.Join(selectors, " "))
+	}
+	return ip, nil
+}
+
+// response is what a request got back from the echo backends.
+type response struct {
+	Status   int
+	Location string
+	// Service, Namespace and Path are the Service and namespace of the
+	// backend that served the request and the path it received.
+	Service   string
+	Namespace string
+	Path      string
+}
+
+func (r response) String() string {
+	if r.Location != "" {
+		return fmt.Sprintf("%d to %s", r.Status, r.Location)
+	}
+	if r.Service == "" {
+		return strconv.Itoa(r.Status)
+	}
+	return fmt.Sprintf("%d from Service %s/%s for path %s", r.Status, r.Namespace, r.Service, r.Path)
+}
+
+// send sends a request to the address from the client pod, without
+// following redirects.
+func (c *cluster) send(ctx context.Context, namespace, address string, req i2gw.TrafficRequest) (response, error) {
+	port := req.URL.Port()
+	if port == "" {
+		port = "80"
+		if req.URL.Scheme == "https" {
+			port = "443"
+		}
+	}
+	args := []string{"exec", "--namespace", namespace, clientPod, "--", "curl", "--silent", "--insecure",
+		"--request", req.Method,
+		"--resolve", net.JoinHostPort(req.URL.Hostname(), port) + ":" + address,
+		"--write-out", "\n%{http_code}\n%{redirect_url}"}
+	for name, values := range req.Headers {
+		for _, value := range values {
+			args = append(args, "--header", name+": "+value)
+		}
+	}
+	out, err := c.kubectl(ctx, nil, append(args, req.URL.String())...)
+	if err != nil {
+		return response{}, err
+	}
+	lines := strings.Split(string(out), "\n")
+	if len(lines) < 3 {
+		return response{}, fmt.Errorf("unexpected curl output %q", out)
+	}
+	var resp response
+	if resp.Status, err = strconv.Atoi(lines[len(lines)-2]); err != nil {
+		return response{}, fmt.Errorf("unexpected curl status %q", lines[len(lines)-2])
+	}
+	resp.Location = lines[len(lines)-1]
+	// The echo backends describe the request they received as JSON, other
+	// bodies, such as the error pages of the proxies, are ignored.
+	var echo struct {
+		Path      string `json:"path"`
+		Namespace string `json:"namespace"`
+		Service   string `json:"service"`
+	}
+	if json.Unmarshal([]byte(strings.Join(lines[:len(lines)-2], "\n")), &echo) == nil {
+		resp.Service, resp.Namespace, resp.Path = echo.Service, echo.Namespace, echo.Path
+	}
+	return resp, nil
+}
+
+func run(ctx context.Context, stdin io.Reader, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = stdin
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return out, fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
@@ -0,0 +1,167 @@
+//go:build conformance
+
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+)
+
+// requestInterval is the interval between the attempts of a request, until
+// the Ingress controller and the Gateway route it the same.
+const requestInterval = 2 * time.Second
+
+func TestConformance(t *testing.T) {
+	ctx := context.Background()
+	for _, s := range suites {
+		t.Run(s.controller.provider+"/"+s.implementation.target, func(t *testing.T) {
+			c, err := createCluster(ctx, "i2gw-"+s.controller.provider, t.TempDir())
+			if err != nil {
+				t.Fatalf("Failed to create the cluster: %v", err)
+			}
+			if os.Getenv("I2GW_CONFORMANCE_KEEP_CLUSTER") != "true" {
+				t.Cleanup(func() {
+					if err := c.delete(ctx); err != nil {
+						t.Errorf("Failed to delete the cluster: %v", err)
+					}
+				})
+			}
+			if err := s.install(ctx, c); err != nil {
+				t.Fatalf("Failed to install the controllers: %v", err)
+			}
+
+			fixtures, err := filepath.Glob(filepath.Join("testdata", s.controller.provider, "*.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, fixture := range fixtures {
+				t.Run(strings.TrimSuffix(filepath.Base(fixture), ".yaml"), func(t *testing.T) {
+					s.runFixture(ctx, t, c, fixture)
+				})
+			}
+		})
+	}
+}
+
+// runFixture applies the Ingresses of the fixture and their conversion, and
+// checks that the Gateways route the requests of the fixture like the
+// Ingress controller does.
+func (s suite) runFixture(ctx context.Context, t *testing.T, c *cluster, fixture string) {
+	ingresses, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(strings.TrimSuffix(fixture, ".yaml") + ".requests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	requests, err := i2gw.ParseTrafficRequests(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resources, report, err := i2gw.Convert(ctx, i2gw.ConvertOptions{
+		InputFile:              fixture,
+		TargetImplementation:   s.implementation.target,
+		GatewayClassController: s.implementation.controllerName,
+	})
+	if err != nil {
+		t.Fatalf("Failed to convert the Ingresses: %v", err)
+	}
+	if len(report.Errors) > 0 {
+		t.Fatalf("Failed to convert the Ingresses: %v", report.Errors)
+	}
+	var converted bytes.Buffer
+	i2gw.WriteResult(&converted, resources, report)
+
+	for _, manifest := range [][]byte{ingresses, converted.Bytes()} {
+		if err := c.applyManifest(ctx, manifest); err != nil {
+			t.Fatalf("Failed to apply the manifest: %v", err)
+		}
+		t.Cleanup(func() {
+			if err := c.deleteManifest(ctx, manifest); err != nil {
+				t.Errorf("Failed to delete the manifest: %v", err)
+			}
+		})
+	}
+
+	controllerIP, err := c.clusterIP(ctx, s.controller.namespace, "--field-selector", "metadata.name="+s.controller.service)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gatewayIPs := map[string]string{}
+	for _, gw := range resources.Gateways {
+		if _, err := c.kubectl(ctx, nil, "wait", "--namespace", gw.Namespace, "--for=condition=Programmed", "gateway/"+gw.Name, "--timeout="+readyTimeout.String()); err != nil {
+			t.Fatalf("Gateway %s/%s isn't programmed: %v", gw.Namespace, gw.Name, err)
+		}
+		ip, err := c.clusterIP(ctx, s.implementation.namespace, "--selector", s.implementation.gatewaySelector(gw.Namespace, gw.Name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		gatewayIPs[gw.Name] = ip
+	}
+
+	for _, req := range requests {
+		for name, gatewayIP := range gatewayIPs {
+			if err := s.compare(ctx, c, req, controllerIP, gatewayIP); err != nil {
+				t.Errorf("Gateway %s routes %s differently: %v", name, req, err)
+			}
+		}
+	}
+}
+
+// compare sends the request to the Ingress controller and to the Gateway
+// until they route it the same, as the controllers apply the changes
+// eventually.
+func (s suite) compare(ctx context.Context, c *cluster, req i2gw.TrafficRequest, controllerIP, gatewayIP string) error {
+	deadline := time.Now().Add(readyTimeout)
+	for {
+		ingressResp, err := c.send(ctx, namespace, controllerIP, req)
+		if err != nil {
+			return err
+		}
+		gatewayResp, err := c.send(ctx, namespace, gatewayIP, req)
+		if err != nil {
+			return err
+		}
+		if ingressResp == gatewayResp {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return &routingError{ingress: ingressResp, gateway: gatewayResp}
+		}
+		time.Sleep(requestInterval)
+	}
+}
+
+type routingError struct {
+	ingress, gateway response
+}
+
+func (e *routingError) Error() string {
+	return "the Ingress controller responds " + e.ingress.String() + " and the Gateway " + e.gateway.String()
+}
@@ -0,0 +1,24 @@
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Package conformance verifies conversions end to end. For every provider,
+// it creates a kind cluster running the provider's Ingress controller and a
+// Gateway API implementation, applies the fixture Ingresses of the provider
+// and their conversion, and compares how the Ingress controller and the
+// Gateway route the sample requests of every fixture.
+//
+// The tests have the conformance build tag and need docker, kind and
+// kubectl, run them with make conformance.
+package conformance
@@ -0,0 +1,136 @@
+//go:build conformance
+
+/*
+Copyright © 2022 Kubernetes Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conformance
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/targets/envoygateway"
+)
+
+// namespace is the namespace of the echo backends, of the fixture
+// Ingresses and of their conversion.
+const namespace = "conformance"
+
+// readyTimeout is how long the controllers, Gateways and routes are waited
+// for.
+const readyTimeout = 5 * time.Minute
+
+// ingressController is the Ingress controller of a provider.
+type ingressController struct {
+	// provider is the name of the provider, and of the directory of its
+	// fixtures in testdata.
+	provider string
+	// manifest installs the controller, it can be overridden by the
+	// manifestEnv environment variable.
+	manifest    string
+	manifestEnv string
+	// namespace and selector select the pods of the controller, service
+	// is the Service routing to them.
+	namespace string
+	selector  string
+	service   string
+}
+
+// gatewayImplementation is a Gateway API implementation the conversions
+// target.
+type gatewayImplementation struct {
+	// target is the --target-implementation of the conversions.
+	target string
+	// manifest installs the implementation and the Gateway API CRDs, it
+	// can be overridden by the manifestEnv environment variable.
+	manifest    string
+	manifestEnv string
+	// controllerName is the controller of the GatewayClasses generated for
+	// the Gateways of the conversions.
+	controllerName string
+	// namespace and deployment are the ones of the controller.
+	namespace  string
+	deployment string
+	// gatewaySelector returns the label selector of the Service of a
+	// Gateway, in the namespace of the controller.
+	gatewaySelector func(gatewayNamespace, gatewayName string) string
+}
+
+// suite runs the fixtures of an Ingress controller against a Gateway
+// implementation.
+type suite struct {
+	controller     ingressController
+	implementation gatewayImplementation
+}
+
+var ingressNginx = ingressController{
+	provider:    "ingress-nginx",
+	manifest:    "https://raw.githubusercontent.com/kubernetes/ingress-nginx/controller-v1.11.2/deploy/static/provider/kind/deploy.yaml",
+	manifestEnv: "I2GW_CONFORMANCE_INGRESS_NGINX_MANIFEST",
+	namespace:   "ingress-nginx",
+	selector:    "app.kubernetes.io/component=controller",
+	service:     "ingress-nginx-controller",
+}
+
+var envoyGateway = gatewayImplementation{
+	target:         envoygateway.Name,
+	manifest:       "https://github.com/envoyproxy/gateway/releases/download/v1.1.2/install.yaml",
+	manifestEnv:    "I2GW_CONFORMANCE_ENVOY_GATEWAY_MANIFEST",
+	controllerName: "gateway.envoyproxy.io/gatewayclass-controller",
+	namespace:      "envoy-gateway-system",
+	deployment:     "envoy-gateway",
+	gatewaySelector: func(gatewayNamespace, gatewayName string) string {
+		return "gateway.envoyproxy.io/owning-gateway-namespace=" + gatewayNamespace + ",gateway.envoyproxy.io/owning-gateway-name=" + gatewayName
+	},
+}
+
+var suites = []suite{
+	{controller: ingressNginx, implementation: envoyGateway},
+}
+
+func manifest(url, env string) string {
+	if v := os.Getenv(env); v != "" {
+		return v
+	}
+	return url
+}
+
+// install installs the Ingress controller, the Gateway implementation, the
+// echo backends and the client pod, and waits for them to be ready.
+func (s suite) install(ctx context.Context, c *cluster) error {
+	if err := c.apply(ctx, manifest(s.controller.manifest, s.controller.manifestEnv)); err != nil {
+		return err
+	}
+	if err := c.apply(ctx, manifest(s.implementation.manifest, s.implementation.manifestEnv)); err != nil {
+		return err
+	}
+	if err := c.apply(ctx, "testdata/backends.yaml"); err != nil {
+		return err
+	}
+	timeout := "--timeout=" + readyTimeout.String()
+	if _, err := c.kubectl(ctx, nil, "wait", "--namespace", s.controller.namespace, "--for=condition=Ready", "pod", "--selector", s.controller.selector, timeout); err != nil {
+		return err
+	}
+	if _, err := c.kubectl(ctx, nil, "wait", "--namespace", s.implementation.namespace, "--for=condition=Available", "deployment/"+s.implementation.deployment, timeout); err != nil {
+		return err
+	}
+	if _, err := c.kubectl(ctx, nil, "wait", "--namespace", namespace, "--for=condition=Available", "deployment", "--all", timeout); err != nil {
+		return err
+	}
+	_, err := c.kubectl(ctx, nil, "wait", "--namespace", namespace, "--for=condition=Ready", "pod/"+clientPod, timeout)
+	return err
+}